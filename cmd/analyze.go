@@ -2,9 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	"github.com/Sabique-Islam/catalyst/internal/headers"
 	"github.com/spf13/cobra"
 )
 
@@ -101,8 +105,8 @@ func runAnalyze() error {
 					fmt.Printf("  pkg-config: %s\n", lib.PkgConfig)
 				}
 				fmt.Println("  Platform Packages:")
-				for platform, pkg := range lib.Platforms {
-					if pkg.PackageName != "" {
+				for _, platform := range slices.Sorted(maps.Keys(lib.Platforms)) {
+					if pkg := lib.Platforms[platform]; pkg.PackageName != "" {
 						fmt.Printf("    %s: %s\n", platform, pkg.PackageName)
 					}
 				}
@@ -164,5 +168,40 @@ func runAnalyze() error {
 		fmt.Println("   → smart-init will include these in build")
 	}
 
+	if shims := scanner.DetectPosixShims(); len(shims) > 0 {
+		fmt.Println()
+		fmt.Printf(" %d POSIX header group(s) need a shim on Windows\n", len(shims))
+		printShimAdvice(shims)
+		fmt.Println("   → Use 'catalyst smart-init --windows-shims' to add them")
+	}
+
+	if uses := scanner.DetectWindowsLibraries(); len(uses) > 0 {
+		fmt.Println()
+		fmt.Printf(" %d Windows SDK header(s) need system libraries (no package to install)\n", len(uses))
+		for _, use := range uses {
+			fmt.Printf("   • %s\n", use.Header)
+			fmt.Printf("     MinGW: %s   MSVC: %s\n",
+				strings.Join(headers.WindowsLinkFlags(use.Libraries, false), " "),
+				strings.Join(headers.WindowsLinkFlags(use.Libraries, true), " "))
+			fmt.Printf("     Used in: %s\n", strings.Join(use.Files, ", "))
+		}
+		fmt.Println("   → smart-init adds them to the Windows platform flags")
+	}
+
 	return nil
 }
+
+// printShimAdvice prints POSIX-on-Windows shim suggestions
+func printShimAdvice(shims []analyzer.ShimAdvice) {
+	for _, advice := range shims {
+		fmt.Printf("   • %s (%s)\n", advice.Shim.Name, strings.Join(advice.Shim.Headers, ", "))
+		fmt.Printf("     %s\n", advice.Shim.Advice)
+		if advice.Shim.Package != "" {
+			fmt.Printf("     Windows package: %s\n", advice.Shim.Package)
+		}
+		if flags := append(advice.Shim.Defines, advice.Shim.LinkerFlags...); len(flags) > 0 {
+			fmt.Printf("     Windows flags: %s\n", strings.Join(flags, " "))
+		}
+		fmt.Printf("     Used in: %s\n", strings.Join(advice.Files, ", "))
+	}
+}
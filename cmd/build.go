@@ -1,10 +1,22 @@
 package cmd
 
 import (
+	"fmt"
+
 	compile "github.com/Sabique-Islam/catalyst/internal/compile"
 	"github.com/spf13/cobra"
 )
 
+var (
+	buildSandbox bool
+	buildJobs    int
+	buildTarget  string
+	buildProfile string
+
+	buildFeatures          []string
+	buildNoDefaultFeatures bool
+)
+
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Install dependencies and compile C/C++ sources",
@@ -12,14 +24,48 @@ var buildCmd = &cobra.Command{
 
 If no catalyst.yml exists, you can pass source files manually.
 
+Options:
+  --sandbox  Only allow the compiler and code generators to write to the
+             build directory (uses bwrap/firejail on Linux, sandbox-exec on macOS)
+  -j, --jobs Compile this many source files at once, then link them
+             (defaults to jobs: in catalyst.yml, otherwise one compiler call)
+  --target   Cross-compile for a target triple from the targets: section of
+             catalyst.yml; the binary is written to build/<triple>/
+  --profile  Add the flags of a profile from the profiles: section of
+             catalyst.yml (debug and release are built in); objects and
+             the binary are written to build/<profile>/, or
+             build/<profile>/<triple>/ with --target
+  --features Enable features from the features: section of catalyst.yml,
+             adding their sources, defines and dependencies
+  --no-default-features
+             Don't enable the features listed in default_features:
+
 Examples:
   catalyst build                        # Build from catalyst.yml
-  catalyst build src/main.c src/utils.c # Build specific files`,
+  catalyst build src/main.c src/utils.c # Build specific files
+  catalyst build --sandbox              # Build untrusted code in a sandbox
+  catalyst build -j 8                   # Compile up to 8 files in parallel
+  catalyst build --target aarch64-linux-gnu  # Cross-compile for 64-bit ARM Linux
+  catalyst build --profile release      # Optimized build in build/release/
+  catalyst build --features tls,metrics # Build with optional features`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return compile.BuildProject(args)
+		if buildJobs < 0 {
+			return fmt.Errorf("--jobs must be a positive number")
+		}
+		return withProject(func() error {
+			return compile.BuildProjectWithOptions(sourceArgsFromStartDir(args), compile.CompileOptions{Sandbox: buildSandbox, Jobs: buildJobs, Target: buildTarget, Profile: buildProfile,
+				Features: buildFeatures, NoDefaultFeatures: buildNoDefaultFeatures})
+		})
 	},
 }
 
 func init() {
+	buildCmd.Flags().BoolVar(&buildSandbox, "sandbox", false, "Restrict compiler and generator writes to the build directory")
+	buildCmd.Flags().IntVarP(&buildJobs, "jobs", "j", 0, "Number of source files to compile in parallel")
+	buildCmd.Flags().StringVar(&buildTarget, "target", "", "Target triple to cross-compile for (defined under targets: in catalyst.yml)")
+	buildCmd.Flags().StringVar(&buildProfile, "profile", "", "Build profile to use (e.g. debug, release)")
+	buildCmd.Flags().StringSliceVar(&buildFeatures, "features", nil, "Comma-separated features to enable (defined under features: in catalyst.yml)")
+	buildCmd.Flags().BoolVar(&buildNoDefaultFeatures, "no-default-features", false, "Don't enable the default_features of catalyst.yml")
+	addProjectFileFlag(buildCmd)
 	rootCmd.AddCommand(buildCmd)
 }
@@ -5,6 +5,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var cleanYes bool
+
 // cleanCmd represents the clean command
 var cleanCmd = &cobra.Command{
 	Use:   "clean",
@@ -12,17 +14,24 @@ var cleanCmd = &cobra.Command{
 	Long: `Clean build artifacts including compiled binaries and temporary files.
 
 This command removes:
-- bin/ directory and all its contents
+- build/ and the legacy bin/ directory with all their contents
 - Any compiled executables
 - Temporary build files
 
-Example:
-  catalyst clean`,
+What would be removed is listed first and removed only once confirmed.
+Without a terminal to ask on (scripts, CI) it is removed without asking.
+Removals are recorded in .catalyst/changes.log.
+
+Examples:
+  catalyst clean        # List the artifacts and ask before removing them
+  catalyst clean --yes  # Remove them without asking`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return compile.CleanProject()
+		guardChanges(cleanYes)
+		return withProject(compile.CleanProject)
 	},
 }
 
 func init() {
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Remove without asking")
 	rootCmd.AddCommand(cleanCmd)
 }
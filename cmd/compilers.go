@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/spf13/cobra"
+)
+
+// compilersCmd groups commands for the C compilers catalyst builds with
+var compilersCmd = &cobra.Command{
+	Use:   "compilers",
+	Short: "Show the C compilers catalyst can build with",
+	Long: `Show the C compilers catalyst can build with and which one it picks.
+
+Builds use the first compiler of the platform's priority order found in
+PATH: clang, gcc on macOS; gcc, clang, cl, clang-cl on Windows; gcc, clang
+elsewhere. Change the order per platform with compiler_priority in
+~/.catalyst.yaml:
+
+  compiler_priority:
+    linux: [clang, gcc]
+    windows: [gcc, cl]    # Prefer MinGW over MSVC
+
+A target's compiler: in catalyst.yml always takes precedence.`,
+}
+
+// compilersListCmd lists the detected compilers
+var compilersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the detected compilers with their versions and paths",
+	Long: `List every C compiler found in PATH with its version and path, including
+versioned (gcc-13) and cross (aarch64-linux-gnu-gcc) compilers. The
+compiler builds use is marked with *.
+
+Examples:
+  catalyst compilers list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCompilersList()
+	},
+}
+
+func init() {
+	compilersCmd.AddCommand(compilersListCmd)
+	rootCmd.AddCommand(compilersCmd)
+}
+
+func runCompilersList() error {
+	order := compile.CompilerOrder(runtime.GOOS)
+	fmt.Printf("Priority on %s: %s\n\n", runtime.GOOS, strings.Join(order, ", "))
+
+	detected := compile.DetectCompilers()
+	if len(detected) == 0 {
+		fmt.Println("No C compilers found in PATH.")
+		return nil
+	}
+
+	width, versionWidth := len("Compiler"), len("(unknown)")
+	for _, c := range detected {
+		width = max(width, len(c.Name))
+		versionWidth = max(versionWidth, len(c.Version))
+	}
+	fmt.Printf("  %-*s  %-*s  %s\n", width, "Compiler", versionWidth, "Version", "Path")
+	for i, c := range detected {
+		marker := " "
+		if i == 0 && c.Rank >= 0 {
+			marker = "*"
+		}
+		version := c.Version
+		if version == "" {
+			version = "(unknown)"
+		}
+		fmt.Printf("%s %-*s  %-*s  %s\n", marker, width, c.Name, versionWidth, version, c.Path)
+	}
+	return nil
+}
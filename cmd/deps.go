@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	install "github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/spf13/cobra"
+)
+
+var depsTreeDepth int
+
+// depsCmd groups commands that inspect project dependencies
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Inspect project dependencies",
+	Long:  `Inspect the system dependencies declared in catalyst.yml.`,
+}
+
+// depsTreeCmd shows the transitive runtime dependencies of the project's packages
+var depsTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show the transitive dependencies pulled in by each package",
+	Long: `Ask the package manager for the runtime dependencies of every package
+in catalyst.yml for this OS and show them as a tree, so you can see what
+actually gets installed on your system.
+
+Uses apt-cache depends, dnf repoquery, pacman -Qi, brew deps or
+vcpkg depend-info. Packages marked (*) were already expanded above.
+
+Examples:
+  catalyst deps tree            # Full dependency tree
+  catalyst deps tree --depth 1  # Direct dependencies only`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := enterProject(); err != nil {
+			return err
+		}
+		return runDepsTree()
+	},
+}
+
+func init() {
+	depsTreeCmd.Flags().IntVar(&depsTreeDepth, "depth", 0, "Maximum depth to expand (0 for unlimited)")
+	depsCmd.AddCommand(depsTreeCmd)
+	rootCmd.AddCommand(depsCmd)
+}
+
+func runDepsTree() error {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	deps := cfg.GetDependencies()
+	if len(deps) == 0 {
+		fmt.Println("No system dependencies for this OS.")
+		return nil
+	}
+
+	resolver := install.NewDependencyResolver(depsTreeDepth)
+	resolver.VcpkgTriplet = cfg.VcpkgTriplet
+	fmt.Printf("Resolving dependencies for %s with %s...\n", runtime.GOOS, resolver.PackageManager)
+	fmt.Println()
+
+	roots, err := resolver.Tree(deps)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(cfg.ProjectName)
+	printDependencyNodes(roots, "")
+
+	closure := install.Closure(roots)
+	fmt.Println()
+	fmt.Printf("%d declared, %d packages in total\n", len(deps), len(closure))
+	return nil
+}
+
+// printDependencyNodes prints dependency nodes with tree connectors
+func printDependencyNodes(nodes []*install.DependencyNode, prefix string) {
+	for i, node := range nodes {
+		connector, childPrefix := "├── ", prefix+"│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", prefix+"    "
+		}
+
+		label := node.Name
+		if node.System {
+			label += " (system library)"
+		} else if node.Repeated {
+			label += " (*)"
+		}
+		fmt.Println(prefix + connector + label)
+
+		printDependencyNodes(node.Dependencies, childPrefix)
+	}
+}
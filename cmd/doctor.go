@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/Sabique-Islam/catalyst/internal/compile"
+	config "github.com/Sabique-Islam/catalyst/internal/config"
 	"github.com/Sabique-Islam/catalyst/internal/fetch"
 	"github.com/Sabique-Islam/catalyst/internal/install"
 	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
@@ -15,13 +20,19 @@ var (
 	doctorInstall bool
 	doctorDryRun  bool
 	doctorVerbose bool
+	doctorObjects bool
 )
 
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Diagnose project issues and missing dependencies",
 	Long: `Analyze your C project for missing symbols, undefined references, and suggest solutions.
-Optionally install suggested dependencies automatically.`,
+Optionally install suggested dependencies automatically.
+
+Missing symbols are found by linking the project's sources. With --objects
+each source is compiled on its own and the undefined symbols of the objects
+are read with nm (dumpbin with MSVC), so projects that don't link yet can be
+analyzed too, and each symbol is shown with the file using it.`,
 	RunE: runDoctor,
 }
 
@@ -29,6 +40,7 @@ func init() {
 	doctorCmd.Flags().BoolVar(&doctorInstall, "install", false, "Automatically install suggested dependencies")
 	doctorCmd.Flags().BoolVar(&doctorDryRun, "dry-run", false, "Show what would be installed without actually installing")
 	doctorCmd.Flags().BoolVarP(&doctorVerbose, "verbose", "v", false, "Verbose output")
+	doctorCmd.Flags().BoolVar(&doctorObjects, "objects", false, "Find missing symbols in compiled objects with nm instead of linking")
 	rootCmd.AddCommand(doctorCmd)
 }
 
@@ -46,7 +58,11 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	pkgManager, err := platform.DetectPackageManager(osName)
 	if err != nil {
 		fmt.Printf("Warning: Could not detect package manager: %v\n", err)
-		fmt.Printf("Setup advice:\n%s\n", platform.GetPackageManagerSetupAdvice())
+		if support := platform.DetectSupport(); !support.MissingPackageManager() {
+			fmt.Print(support.Report(nil))
+		} else {
+			fmt.Printf("Setup advice:\n%s\nThen run catalyst setup to finish preparing this machine.\n", platform.GetPackageManagerSetupAdvice())
+		}
 	} else {
 		fmt.Printf("Platform: %s (%s)\n", osName, pkgManager)
 	}
@@ -60,16 +76,50 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to scan dependencies: %w", err)
 	}
 
+	// Resolve dependencies with the pipeline configured in catalyst.yml, if any
+	var resolution config.Resolution
+	var provenance []config.DependencySource
+	var overrides config.PackageOverrides
+	var project *config.Config
+	root, name, hasProject := config.FindProject(projectPath)
+	if hasProject {
+		if cfg, err := config.LoadConfig(filepath.Join(root, name)); err == nil {
+			resolution = cfg.Resolution
+			provenance = cfg.Provenance
+			overrides = cfg.PackageOverrides
+			project = cfg
+		}
+	}
+	resolver, err := pkgdb.NewResolver(pkgManager, resolution)
+	if err != nil {
+		return err
+	}
+	resolver.Overrides = overrides
+
 	if len(headerDeps) == 0 {
 		fmt.Println("No header dependencies found.")
 	} else {
 		fmt.Printf("Found %d unique dependencies: %v\n", len(headerDeps), headerDeps)
 
-		// Resolve header dependencies
+		// Resolve header dependencies, skipping the project's own headers
+		localHeaders := fetch.LocalHeaderNames(projectPath)
 		var packageSuggestions []string
 		for _, dep := range headerDeps {
-			if pkg, found := pkgdb.Translate(dep, pkgManager); found && pkg != "" {
-				packageSuggestions = append(packageSuggestions, pkg)
+			if localHeaders[dep] {
+				continue
+			}
+			res, err := resolver.Resolve(dep)
+			if err != nil {
+				return err
+			}
+			if res.Satisfied && doctorVerbose {
+				fmt.Printf("  %s -> provided by the compiler (%s)\n", dep, res.Path)
+			}
+			if res.Package != "" {
+				packageSuggestions = append(packageSuggestions, res.Package)
+				if doctorVerbose {
+					fmt.Printf("  %s -> %s (%s, %d%%)\n", dep, res.Package, res.Strategy, res.Confidence)
+				}
 			}
 		}
 
@@ -78,11 +128,28 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Re-verify dependency mappings that were guesses when they were recorded
+	verifyProvenance(provenance, osName, resolver)
+
+	if project != nil {
+		checkTools(project, pkgManager)
+	}
+
 	// Scan for missing symbols
 	fmt.Println("\nSymbol Linkage Analysis:")
 	fmt.Println("------------------------")
 
-	missing, err := fetch.ScanMissingSymbols(projectPath)
+	scan := fetch.ScanMissingSymbols
+	if doctorObjects {
+		var flagsFor func(string) []string
+		if project != nil {
+			flagsFor = projectCompileFlags(root, name)
+		}
+		scan = func(path string) ([]fetch.MissingDependency, error) {
+			return fetch.ScanObjectSymbols(path, flagsFor)
+		}
+	}
+	missing, err := scan(projectPath)
 	if err != nil {
 		fmt.Printf("Could not analyze symbols: %v\n", err)
 	} else if len(missing) == 0 {
@@ -94,9 +161,12 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 
 		for i, group := range missing {
 			fmt.Printf("%d. Missing symbols (%s):\n", i+1, group.Category)
-			symbolNames := fetch.ExtractSymbolNames(group.Symbols)
-			for _, symbol := range symbolNames {
-				fmt.Printf("   - %s\n", symbol)
+			for _, symbol := range group.Symbols {
+				if symbol.File != "" {
+					fmt.Printf("   - %s (used in %s)\n", symbol.Symbol, symbol.File)
+				} else {
+					fmt.Printf("   - %s\n", symbol.Symbol)
+				}
 			}
 
 			if len(group.SuggestedFiles) > 0 {
@@ -108,10 +178,12 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 
 				// Resolve library suggestions to actual packages
 				for _, lib := range group.SuggestedLibs {
-					if pkg, found := pkgdb.Translate(lib, pkgManager); found && pkg != "" {
-						allSuggestedPackages = append(allSuggestedPackages, pkg)
-					} else if pkg, found := pkgdb.TranslateWithSearch(lib, pkgManager); found {
-						allSuggestedPackages = append(allSuggestedPackages, pkg)
+					res, err := resolver.Resolve(lib)
+					if err != nil {
+						return err
+					}
+					if res.Package != "" {
+						allSuggestedPackages = append(allSuggestedPackages, res.Package)
 					}
 				}
 			}
@@ -169,6 +241,79 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// checkTools reports which tools of the project are installed, and the
+// tools it runs that aren't listed under tools:
+func checkTools(cfg *config.Config, pkgManager string) {
+	var commands []string
+	for _, gen := range cfg.Generators {
+		commands = append(commands, gen.Command)
+	}
+	var unlisted []string
+	for _, tool := range pkgdb.DetectTools(cfg.Sources, commands) {
+		if !slices.Contains(cfg.Tools, tool) {
+			unlisted = append(unlisted, tool)
+		}
+	}
+	if len(cfg.Tools) == 0 && len(unlisted) == 0 {
+		return
+	}
+
+	fmt.Println("\nBuild Tool Analysis:")
+	fmt.Println("--------------------")
+	missing := false
+	for _, tool := range cfg.Tools {
+		if path := pkgdb.FindTool(tool); path != "" {
+			fmt.Printf("  %s: %s\n", tool, path)
+		} else {
+			fmt.Printf("  %s: missing (package %s)\n", tool, pkgdb.ToolPackage(tool, pkgManager))
+			missing = true
+		}
+	}
+	if missing {
+		fmt.Println("Install missing tools with 'catalyst install --only tools'")
+	}
+	if len(unlisted) > 0 {
+		fmt.Printf("Used by the project but not listed under tools: %s\n", strings.Join(unlisted, ", "))
+	}
+}
+
+// lowConfidence is the confidence below which recorded mappings are re-verified
+const lowConfidence = pkgdb.ReviewConfidence
+
+// verifyProvenance re-resolves low-confidence and user-chosen dependency
+// mappings for this platform and reports mappings that no longer agree
+func verifyProvenance(provenance []config.DependencySource, osName string, resolver *pkgdb.Resolver) {
+	var guesses []config.DependencySource
+	for _, src := range provenance {
+		if src.Platform == osName && src.Header != "" && (src.Confidence < lowConfidence || src.Source == "user") {
+			guesses = append(guesses, src)
+		}
+	}
+	if len(guesses) == 0 {
+		return
+	}
+
+	fmt.Println("\nResolution Provenance:")
+	fmt.Println("----------------------")
+	for _, src := range guesses {
+		fmt.Printf("%s -> %s (%s, %d%%)", src.Header, src.Package, src.Source, src.Confidence)
+
+		res, err := resolver.Resolve(src.Header)
+		switch {
+		case err != nil:
+			fmt.Printf(": could not re-verify (%v)\n", err)
+		case !res.Found:
+			fmt.Println(": no longer resolves - check this dependency by hand")
+		case res.Satisfied:
+			fmt.Printf(": the compiler already provides it (%s) - the package may not be needed\n", res.Path)
+		case res.Package != src.Package:
+			fmt.Printf(": now resolves to %s (%s, %d%%)\n", res.Package, res.Strategy, res.Confidence)
+		default:
+			fmt.Printf(": confirmed by %s\n", res.Strategy)
+		}
+	}
+}
+
 // removeDuplicates removes duplicate strings from a slice
 func removeDuplicates(input []string) []string {
 	keys := make(map[string]bool)
@@ -184,3 +329,30 @@ func removeDuplicates(input []string) []string {
 
 	return result
 }
+
+// projectCompileFlags returns the compile flags of the sources of the
+// project in root, so its sources compile on their own with its include
+// directories and defines; nil if they can't be resolved
+func projectCompileFlags(root, name string) func(src string) []string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	projectFile := config.ProjectFile
+	defer func() {
+		os.Chdir(cwd)
+		config.ProjectFile = projectFile
+	}()
+	if err := config.UseProjectFile(filepath.Join(root, name)); err != nil {
+		return nil
+	}
+	resolved, err := compile.ProjectFlags(false)
+	if err != nil {
+		fmt.Printf("Compiling the sources without the project's flags: %v\n", err)
+		return nil
+	}
+	return func(src string) []string {
+		flags, _ := resolved.CompileFlags(src)
+		return flags
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	compile "github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	envShell             string
+	envOutput            string
+	envProfile           string
+	envFeatures          []string
+	envNoDefaultFeatures bool
+)
+
+// envCmd prints the environment to compile the project by hand
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Print the environment to run the project's compiler by hand",
+	Long: `Print the commands that set up a shell to compile the project by hand with
+the settings catalyst builds with:
+
+  PATH              The bin directories of the isolated prefix, the vcpkg
+                    installed tree and (on Windows) the MSYS2 environment
+  CC                The compiler catalyst picks, after compiler_launcher:
+  CFLAGS            Flags of catalyst.yml, the features and profile, and the
+                    include flags of the installed dependencies
+  LDFLAGS, LDLIBS   Library directories and -l flags of the dependencies
+  PKG_CONFIG_PATH   The Conan packages, when dependencies come from Conan
+  env:              The variables catalyst.yml sets
+
+Nothing is installed: run catalyst install first so the dependencies'
+flags can be found. The shell is detected from $SHELL (PowerShell on
+Windows). Make's built-in rules use these variables, so after evaluating
+them "make main" builds main.c like catalyst would.
+
+Options:
+  --shell             bash, zsh, fish or powershell (default: detected)
+  -o, --output        Write the script to a file instead of printing it
+  --profile           Add the flags of a build profile
+  --features          Comma-separated features to enable
+  --no-default-features
+                      Don't enable the default_features of catalyst.yml
+
+Examples:
+  eval "$(catalyst env)"
+  catalyst env --shell fish | source
+  catalyst env --shell powershell | Out-String | Invoke-Expression
+  catalyst env --profile debug -o .catalyst/env.sh`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell := envShell
+		if shell == "" {
+			shell = detectShell()
+		}
+		// env only reads the project, so it doesn't wait for builds
+		if err := enterProject(); err != nil {
+			return err
+		}
+		env, err := compile.Environment(compile.CompileOptions{
+			Profile:           envProfile,
+			Features:          envFeatures,
+			NoDefaultFeatures: envNoDefaultFeatures,
+		})
+		if err != nil {
+			return err
+		}
+		script, err := env.Script(shell)
+		if err != nil {
+			return err
+		}
+		if envOutput == "" {
+			fmt.Print(script)
+			return nil
+		}
+		output := fromStartDir(envOutput)
+		if dir := filepath.Dir(output); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+		if err := util.WriteFileAtomic(output, []byte(script), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", envOutput, err)
+		}
+		log.Infof("Wrote %s\n", envOutput)
+		return nil
+	},
+}
+
+func init() {
+	envCmd.Flags().StringVar(&envShell, "shell", "", "Shell to print commands for: "+strings.Join(compile.Shells, ", "))
+	envCmd.Flags().StringVarP(&envOutput, "output", "o", "", "Write the script to a file instead of printing it")
+	envCmd.Flags().StringVar(&envProfile, "profile", "", "Add the flags of a build profile (e.g. debug, release)")
+	envCmd.Flags().StringSliceVar(&envFeatures, "features", nil, "Comma-separated features to enable (defined under features: in catalyst.yml)")
+	envCmd.Flags().BoolVar(&envNoDefaultFeatures, "no-default-features", false, "Don't enable the default_features of catalyst.yml")
+	rootCmd.AddCommand(envCmd)
+}
+
+// detectShell returns the shell catalyst env writes for by default: the
+// login shell named by $SHELL, or PowerShell on Windows
+func detectShell() string {
+	if shell := filepath.Base(os.Getenv("SHELL")); slices.Contains(compile.Shells, shell) {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		return "powershell"
+	}
+	return "bash"
+}
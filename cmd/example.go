@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	compile "github.com/Sabique-Islam/catalyst/internal/compile"
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// exampleCmd groups commands for the project's demo programs
+var exampleCmd = &cobra.Command{
+	Use:   "example",
+	Short: "List, build and run the project's examples",
+	Long: `List, build and run the demo programs of the project.
+
+Examples are listed in the examples: section of catalyst.yml:
+
+  examples:
+    - name: basic
+      sources: [examples/basic.c]
+    - name: server
+      sources: [examples/server/main.c, examples/server/http.c]
+      libs: [pthread]
+      args: ["--port", "8080"]
+    - name: gui
+      dir: examples/gui        # A project with its own catalyst.yml
+
+Without an examples: section every C file in examples/ is an example, and
+so is every directory: built from its C files, or as a project of its own
+when it has a catalyst.yml.
+
+Examples of a library project are linked against the library, which is
+built first. Examples of other projects are built with the project's flags
+and dependencies.`,
+}
+
+// exampleListCmd lists the examples
+var exampleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the project's examples",
+	Long: `List the examples of the project with their sources.
+
+Examples:
+  catalyst example list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Listing writes nothing, so it doesn't wait for builds
+		if err := enterProject(); err != nil {
+			return err
+		}
+		return runExampleList()
+	},
+}
+
+// exampleRunCmd builds and runs one example
+var exampleRunCmd = &cobra.Command{
+	Use:   "run <name> [-- args...]",
+	Short: "Build an example and run it",
+	Long: `Build an example into build/examples/ and run it. Arguments after -- are
+passed to the example, after the args: of catalyst.yml.
+
+Examples:
+  catalyst example run basic
+  catalyst example run server -- --verbose`,
+	Args: cobra.MinimumNArgs(1),
+	// A failing example is not a usage error
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Only the build holds the project lock, so other commands can run
+		// while the example does
+		if err := enterProject(); err != nil {
+			return err
+		}
+		return compile.RunExample(args[0], args[1:], compile.CompileOptions{Lock: lockProject})
+	},
+}
+
+func init() {
+	exampleCmd.AddCommand(exampleListCmd)
+	exampleCmd.AddCommand(exampleRunCmd)
+	rootCmd.AddCommand(exampleCmd)
+}
+
+func runExampleList() error {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load catalyst.yml: %w", err)
+	}
+	examples, err := compile.Examples(cfg)
+	if err != nil {
+		return err
+	}
+	if len(examples) == 0 {
+		fmt.Println("No examples found (add an examples: section to catalyst.yml or files to examples/).")
+		return nil
+	}
+
+	width := 0
+	for _, example := range examples {
+		width = max(width, len(example.Name))
+	}
+	for _, example := range examples {
+		what := strings.Join(example.Sources, " ")
+		if example.Dir != "" {
+			what = example.Dir + " (project)"
+		}
+		fmt.Printf("  %-*s  %s\n", width, example.Name, what)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	compile "github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagsJSON            bool
+	flagsCompileCommands bool
+	flagsClangd          bool
+	flagsForce           bool
+)
+
+// flagsCmd keeps the resolved flags of the project's sources for editors
+var flagsCmd = &cobra.Command{
+	Use:   "flags [source]",
+	Short: "Show the resolved compile flags of the project's sources",
+	Long: `Resolve the compile flags of every source of the project (its own, its
+features', its generators' and its tests') the way a host build does, and
+keep them in .catalyst/flags.json. For each source the file lists the
+include directories, the defines, the language standard and every compile
+flag. Builds update it too, and it is only resolved again when
+catalyst.yml, catalyst.lock, the installed packages or the compiler
+change, so editor tooling can run catalyst flags as often as it likes.
+Nothing is installed: run catalyst install first.
+
+With a source, its flags are printed; otherwise where the file is.
+
+Options:
+  --json              Print .catalyst/flags.json
+  --compile-commands  Write build/compile_commands.json from it
+  --clangd            Also write a .clangd pointing clangd at build/
+  --force             Resolve the flags even if nothing changed
+
+Examples:
+  catalyst flags
+  catalyst flags src/main.c
+  catalyst flags --clangd
+  catalyst flags --json | jq '.files["src/main.c"].includes'`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withProject(func() error {
+			resolved, err := compile.ProjectFlags(flagsForce)
+			if err != nil {
+				return err
+			}
+
+			if flagsCompileCommands || flagsClangd {
+				path, err := compile.WriteCompileCommandsFromFlags(resolved)
+				if err != nil {
+					return err
+				}
+				log.Infof("Wrote %s\n", path)
+			}
+			if flagsClangd {
+				if err := compile.WriteClangdConfig(); err != nil {
+					return err
+				}
+				log.Info("Wrote .clangd")
+			}
+
+			switch {
+			case len(args) == 1:
+				source, ok := resolved.Files[filepath.ToSlash(filepath.Clean(args[0]))]
+				if !ok {
+					return fmt.Errorf("%s is not a source of the project (see catalyst.yml)", args[0])
+				}
+				for _, flag := range source.Flags {
+					fmt.Println(flag)
+				}
+			case flagsJSON:
+				data, err := json.MarshalIndent(resolved, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+			case !flagsCompileCommands && !flagsClangd:
+				log.Infof("Flags of the project's sources are in %s\n", compile.FlagsFile())
+			}
+			return nil
+		})
+	},
+}
+
+func init() {
+	flagsCmd.Flags().BoolVar(&flagsJSON, "json", false, "Print .catalyst/flags.json")
+	flagsCmd.Flags().BoolVar(&flagsCompileCommands, "compile-commands", false, "Write build/compile_commands.json from the resolved flags")
+	flagsCmd.Flags().BoolVar(&flagsClangd, "clangd", false, "Also write a .clangd pointing clangd at build/")
+	flagsCmd.Flags().BoolVar(&flagsForce, "force", false, "Resolve the flags even if nothing changed")
+	rootCmd.AddCommand(flagsCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat string
+	graphOutput string
+)
+
+// graphCmd prints the project's include graph
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Output the project's include graph (Graphviz or Mermaid)",
+	Long: `Scan the project and output a graph of which files include which,
+including vendored libraries (grouped together) and the external libraries
+the project uses. Standard headers are left out.
+
+Options:
+  --format      dot (Graphviz, default) or mermaid
+  -o, --output  Write the graph to a file instead of stdout
+
+Examples:
+  catalyst graph | dot -Tsvg -o graph.svg   # Render with Graphviz
+  catalyst graph --format mermaid            # Paste into Markdown
+  catalyst graph -o includes.dot             # Save the DOT file`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGraph()
+	},
+}
+
+func init() {
+	graphCmd.Flags().StringVar(&graphFormat, "format", "dot", "Output format: dot or mermaid")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "Write the graph to this file")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph() error {
+	if graphFormat != "dot" && graphFormat != "mermaid" {
+		return fmt.Errorf("unknown format %q (use dot or mermaid)", graphFormat)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	scanner := analyzer.NewProjectScanner(cwd)
+	if err := scanner.ScanProject(); err != nil {
+		return fmt.Errorf("failed to scan project: %w", err)
+	}
+
+	graph := scanner.BuildIncludeGraph()
+	if len(graph.Files)+len(graph.Vendored) == 0 {
+		return fmt.Errorf("no C/C++ source or header files found")
+	}
+
+	out := graph.DOT()
+	if graphFormat == "mermaid" {
+		out = graph.Mermaid()
+	}
+
+	if graphOutput == "" {
+		fmt.Print(out)
+		return nil
+	}
+	if err := os.WriteFile(graphOutput, []byte(out), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", graphOutput, err)
+	}
+	fmt.Printf("Wrote include graph to %s\n", graphOutput)
+	return nil
+}
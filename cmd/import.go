@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/importer"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	importDryRun bool
+	importYes    bool
+	importNoMake bool
+)
+
+// importCmd groups commands converting other build systems' files
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Generate catalyst.yml from another build system",
+	Long: `Generate catalyst.yml from the build files of another build system, to
+move an existing project to catalyst.`,
+}
+
+// importCMakeCmd converts a CMakeLists.txt
+var importCMakeCmd = &cobra.Command{
+	Use:   "cmake [dir]",
+	Short: "Generate catalyst.yml from CMakeLists.txt",
+	Long: `Generate catalyst.yml from the CMakeLists.txt in dir (default: the current
+directory) and the directories it adds with add_subdirectory.
+
+Carried over:
+  - The first executable target, or else the first library, becomes the
+    project; libraries of the project it links are built into it
+  - Other executables become examples, add_test() targets become tests
+  - Sources, including file(GLOB) patterns, include directories,
+    definitions, compile options and CMAKE_C_STANDARD
+  - find_package() and pkg_check_modules() libraries catalyst knows become
+    dependencies with their linker flags; other link libraries become -l flags
+
+Conditions are not evaluated, so commands in every if() branch are
+imported. What couldn't be carried over is listed after the import.
+
+Options:
+  --dry-run           Print the generated catalyst.yml instead of writing it
+  --yes               Overwrite an existing catalyst.yml without asking
+
+Examples:
+  catalyst import cmake
+  catalyst import cmake ../legacy --dry-run`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		guardChanges(importYes)
+		return withProjectLockIn(dir, func() error {
+			return runImport(dir, importer.ImportCMake)
+		})
+	},
+}
+
+// importMakeCmd converts a Makefile
+var importMakeCmd = &cobra.Command{
+	Use:   "make [dir]",
+	Short: "Generate catalyst.yml from a Makefile",
+	Long: `Generate catalyst.yml from the Makefile in dir (default: the current
+directory).
+
+The commands the Makefile runs are listed with make -n -B, and the
+compiler, linker and ar commands among them are converted:
+  - The first program linked becomes the project, or the first archive
+    (ar) when no program is; programs named test* or *_test become tests
+    and the other programs examples
+  - Compile flags (-I, -D, -std, warnings, ...) become the project flags;
+    -O and -g are left to the build profiles
+  - Libraries catalyst knows (-lcurl, -lssl, ...) become dependencies;
+    other link flags are kept
+
+make -n doesn't run the build's commands, but it still runs $(shell ...)
+functions, lines starting with + and recursive $(MAKE) calls. Pass
+--no-make for Makefiles you don't trust.
+
+With --no-make, without make, or when make -n fails, the variables of a
+simple Makefile are read instead: sources from SRC, SRCS or SOURCES, flags
+from CFLAGS, CPPFLAGS, LDFLAGS and LDLIBS, and the output from TARGET or
+PROG. What couldn't be carried over is listed after the import.
+
+Options:
+  --dry-run           Print the generated catalyst.yml instead of writing it
+  --yes               Overwrite an existing catalyst.yml without asking
+  --no-make           Only read the Makefile's variables; don't run make -n
+
+Examples:
+  catalyst import make
+  catalyst import make ../legacy --dry-run
+  catalyst import make ../untrusted --no-make`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		guardChanges(importYes)
+		if !importNoMake {
+			log.Warn("Running make -n -B, which still runs the Makefile's $(shell ...) functions, + lines and recursive $(MAKE) calls (pass --no-make to only read its variables)")
+		}
+		return withProjectLockIn(dir, func() error {
+			return runImport(dir, func(dir string) (*config.Config, []string, error) {
+				return importer.ImportMake(dir, !importNoMake)
+			})
+		})
+	},
+}
+
+func init() {
+	importCMakeCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Print the generated catalyst.yml instead of writing it")
+	importCMakeCmd.Flags().BoolVarP(&importYes, "yes", "y", false, "Overwrite an existing catalyst.yml without asking")
+	importMakeCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Print the generated catalyst.yml instead of writing it")
+	importMakeCmd.Flags().BoolVarP(&importYes, "yes", "y", false, "Overwrite an existing catalyst.yml without asking")
+	importMakeCmd.Flags().BoolVar(&importNoMake, "no-make", false, "Only read the Makefile's variables; don't run make -n")
+	importCmd.AddCommand(importCMakeCmd)
+	importCmd.AddCommand(importMakeCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+// runImport writes, or prints with --dry-run, the catalyst.yml an importer
+// generates for dir
+func runImport(dir string, importFn func(dir string) (*config.Config, []string, error)) error {
+	cfg, notes, err := importFn(dir)
+	if err != nil {
+		return err
+	}
+
+	if importDryRun {
+		data, err := yaml.Marshal(cfg)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+	} else {
+		path := filepath.Join(dir, config.ProjectFile)
+		if err := config.SaveConfig(cfg, path); err != nil {
+			return err
+		}
+		log.Infof("Created %s for %s (%d sources, %d tests, %d examples)\n", path, cfg.Output, len(cfg.Sources), len(cfg.Tests), len(cfg.Examples))
+	}
+
+	for _, note := range notes {
+		log.Warn(note)
+	}
+	return nil
+}
@@ -1,13 +1,26 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/Sabique-Islam/catalyst/internal/project"
+	"github.com/Sabique-Islam/catalyst/internal/tui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	withAnalysis bool
-	installDeps  bool
+	withAnalysis    bool
+	installDeps     bool
+	answersFile     string
+	answersTemplate bool
+	initName        string
+	initSources     []string
+	initOutput      string
+	initDeps        string
+	initYes         bool
 )
 
 // initCmd represents the init command
@@ -17,22 +30,111 @@ var initCmd = &cobra.Command{
 	Long: `Initialize a new Catalyst project with interactive setup.
 
 This command will guide you through setting up a new project configuration
-including project name, author, license, and dependencies.
+including project name, author, license, and dependencies. The C/C++ files
+found in the project are offered as a checklist of sources, with the files
+that define main() listed first as entry points.
 
 Options:
-  --with-analysis  Include missing symbol analysis
-  --install        Automatically install detected dependencies
+  --with-analysis     Include missing symbol analysis
+  --install           Automatically install detected dependencies
+  --answers <file>    Replay the wizard from an answers file, without prompts
+  --answers-template  Print an answers file covering every wizard question
+  --name <name>       Project name (defaults to the directory name with --yes)
+  --sources <files>   Source files, comma-separated (default: scan the project)
+  --output <name>     Output binary name (default: the project name)
+  --deps <method>     Dependency handling: auto (default), database or manual
+  --yes               Answer every question not given by a flag with its default,
+                      and overwrite an existing catalyst.yml without asking
+
+Any of --name, --sources, --output, --deps or --yes skips the wizard and
+writes the catalyst.yml the wizard would for those answers, so init can
+run in scripts and CI.
+
+An existing catalyst.yml is only replaced after its diff against the new
+one is shown and confirmed (or with --yes, which --answers also accepts).
+Replacements are recorded in .catalyst/changes.log.
 
 Example:
   catalyst init
-  catalyst init --with-analysis --install`,
+  catalyst init --with-analysis --install
+  catalyst init --answers-template > answers.yml
+  catalyst init --answers answers.yml
+  catalyst init --answers answers.yml --yes
+  catalyst init --yes
+  catalyst init --name server --sources src/main.c,src/net.c --deps database`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return project.InitializeProjectWithOptions(withAnalysis, installDeps)
+		if answersTemplate {
+			fmt.Print(tui.AnswersTemplate)
+			return nil
+		}
+		unattended := false
+		for _, name := range []string{"name", "sources", "output", "deps"} {
+			unattended = unattended || cmd.Flags().Changed(name)
+		}
+		if unattended && answersFile != "" {
+			return errors.New("--answers cannot be combined with --name, --sources, --output or --deps")
+		}
+		unattended = unattended || (initYes && answersFile == "")
+		guardChanges(initYes)
+
+		return withProjectLock(func() error {
+			if answersFile != "" {
+				return project.InitializeProjectFromAnswers(answersFile, withAnalysis, installDeps)
+			}
+			if unattended {
+				answers, err := answersFromFlags()
+				if err != nil {
+					return err
+				}
+				return project.InitializeProjectNonInteractive(answers, withAnalysis, installDeps)
+			}
+			return project.InitializeProjectWithOptions(withAnalysis, installDeps)
+		})
 	},
 }
 
 func init() {
 	initCmd.Flags().BoolVar(&withAnalysis, "with-analysis", false, "Include missing symbol analysis")
 	initCmd.Flags().BoolVar(&installDeps, "install", false, "Automatically install detected dependencies")
+	initCmd.Flags().StringVar(&answersFile, "answers", "", "Answers file to replay the wizard from")
+	initCmd.Flags().BoolVar(&answersTemplate, "answers-template", false, "Print an answers file template")
+	initCmd.Flags().StringVar(&initName, "name", "", "Project name (skips the wizard)")
+	initCmd.Flags().StringSliceVar(&initSources, "sources", nil, "Source files (skips the wizard; default: scan the project)")
+	initCmd.Flags().StringVar(&initOutput, "output", "", "Output binary name (skips the wizard)")
+	initCmd.Flags().StringVar(&initDeps, "deps", tui.ResolutionAuto, "Dependency handling: auto, database or manual (skips the wizard)")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "Use defaults for every question not given by a flag (skips the wizard)")
 	rootCmd.AddCommand(initCmd)
 }
+
+// answersFromFlags converts the init flags to wizard answers. Dependency
+// review and installation keep their wizard defaults (no); use --install to
+// install.
+func answersFromFlags() (*tui.Answers, error) {
+	answers := &tui.Answers{
+		ProjectName: initName,
+		Sources:     initSources,
+		Output:      initOutput,
+		Automate:    true,
+	}
+
+	if answers.ProjectName == "" {
+		if !initYes {
+			return nil, errors.New("--name is required unless --yes is given")
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
+		}
+		answers.ProjectName = filepath.Base(cwd)
+	}
+
+	switch initDeps {
+	case "manual":
+		answers.Automate = false
+	case tui.ResolutionInteractive:
+		return nil, errors.New("--deps interactive needs prompts; run 'catalyst init' without flags instead")
+	default:
+		answers.Resolution = initDeps
+	}
+	return answers, nil
+}
@@ -10,6 +10,13 @@ import (
 var (
 	resourcesOnly bool
 	depsOnly      bool
+	isolated      bool
+	frozen        bool
+	installDryRun bool
+	installOnly   []string
+	downloadJobs  int
+	refresh       bool
+	retries       int
 )
 
 var installCmd = &cobra.Command{
@@ -20,28 +27,108 @@ var installCmd = &cobra.Command{
 Examples:
   catalyst install                     # Install both dependencies and resources
   catalyst install --deps-only         # Install only system dependencies
-  catalyst install --resources-only    # Download only external resources`,
+  catalyst install --resources-only    # Download only external resources
+  catalyst install --isolated          # Install dependencies into .catalyst/prefix
+  catalyst install --frozen            # Fail if packages differ from catalyst.lock (CI)
+  catalyst install --dry-run           # Print the package manager commands without running them
+  catalyst install --only build,tools  # Skip runtime_dependencies and dev_dependencies (CI)
+  catalyst install --only build,dev    # Build dependencies and developer tools
+  catalyst install --download-jobs 8   # Download up to 8 resources at once
+  catalyst install --refresh           # Re-download resources that changed upstream
+
+Dependencies come in four categories: build (dependencies:), tools
+(tools:), runtime (runtime_dependencies:) and dev (dev_dependencies:). All
+are installed unless --only selects some of them. Tools are programs the
+build runs, such as cmake or bison; those already in PATH are skipped.
+
+With provider: conan in catalyst.yml, or a conanfile.txt/conanfile.py in
+the project, dependencies are installed with conan install instead of the
+system package manager.
+
+Installed packages and their versions are recorded in catalyst.lock, and
+later installs pin those versions where the package manager allows it (apt,
+dnf/yum, zypper, choco, winget, scoop, and brew through versioned formulae
+such as openssl@3). If a locked version is no longer available, the failed
+install lists the versions that are.
+
+Resources are downloaded in parallel (4 at a time unless download_jobs is
+set in catalyst.yml or --download-jobs is passed). A failed download doesn't
+stop the others; every failure is reported at the end.
+
+Resources that already exist are not downloaded again. With --refresh they
+are re-checked with conditional requests (using the ETag and Last-Modified
+saved when they were downloaded) and replaced only if the server has a newer
+version; catalyst.lock is updated with their new checksums.
+
+When a package fails to install, common causes (a locked package database,
+out-of-date package lists, held packages, libraries in Ubuntu's universe or
+in EPEL, Homebrew shallow clones) are explained with steps to fix them. For
+safe fixes such as apt-get update or enabling universe/EPEL, catalyst asks
+whether to run them and retry.
+
+Some packages live outside the package manager's default repositories:
+EPEL on RHEL-compatible systems, Ubuntu PPAs, Homebrew taps, Scoop buckets
+and Chocolatey sources. catalyst asks before adding them, and stops with the
+commands to add them yourself if you decline or stdin isn't a terminal.
+
+Downloads that fail with network errors or server errors (HTTP 408, 425,
+429 and 5xx), and package manager commands that fail because the network
+or the package database is temporarily unavailable, are retried with
+exponential backoff (1s, 2s, 4s, ... up to 30s). --retries sets how many
+times (default 2, 0 disables retrying).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if resourcesOnly && depsOnly {
 			return errors.New("cannot use both --resources-only and --deps-only flags together")
 		}
-
-		if resourcesOnly {
-			return install.InstallExternalResourcesOnly()
+		if len(installOnly) > 0 && resourcesOnly {
+			return errors.New("--only selects dependency categories and cannot be used with --resources-only")
 		}
-
-		if depsOnly {
-			// Create a version that only installs system dependencies
-			return install.InstallSystemDependenciesOnly()
+		if downloadJobs < 0 {
+			return errors.New("--download-jobs must be a positive number")
+		}
+		install.DownloadJobs = downloadJobs
+		if refresh && (depsOnly || installDryRun) {
+			return errors.New("--refresh only applies to external resources and cannot be used with --deps-only or --dry-run")
+		}
+		install.RefreshResources = refresh
+		if retries < 0 {
+			return errors.New("--retries must not be negative")
 		}
+		install.Retry.Attempts = retries + 1
+		if installDryRun && resourcesOnly {
+			return errors.New("--dry-run only applies to system dependencies and cannot be used with --resources-only")
+		}
+
+		return withProject(func() error {
+			if resourcesOnly {
+				return install.InstallExternalResourcesOnly()
+			}
+
+			opts := install.InstallOptions{Isolated: isolated, Frozen: frozen, DryRun: installDryRun, Only: installOnly}
+
+			// Resources are downloaded rather than installed, so a dry run
+			// skips them
+			if depsOnly || installDryRun {
+				// Create a version that only installs system dependencies
+				return install.InstallSystemDependenciesOnlyWithOptions(opts)
+			}
 
-		// Default: install both
-		return install.InstallDependencies()
+			// Default: install both
+			return install.InstallDependenciesWithOptions(opts)
+		})
 	},
 }
 
 func init() {
 	installCmd.Flags().BoolVar(&resourcesOnly, "resources-only", false, "Download only external resources (skip system dependencies)")
 	installCmd.Flags().BoolVar(&depsOnly, "deps-only", false, "Install only system dependencies (skip external resources)")
+	installCmd.Flags().BoolVar(&isolated, "isolated", false, "Install dependencies into the project-local .catalyst/prefix instead of system-wide")
+	installCmd.Flags().BoolVar(&frozen, "frozen", false, "Fail if dependencies or installed versions differ from catalyst.lock")
+	installCmd.Flags().StringSliceVar(&installOnly, "only", nil, "Install only these dependency categories: build, tools, runtime, dev (default all)")
+	installCmd.Flags().IntVar(&downloadJobs, "download-jobs", 0, "Number of resources to download in parallel (default 4)")
+	installCmd.Flags().BoolVar(&refresh, "refresh", false, "Re-download existing resources that changed on the server")
+	installCmd.Flags().IntVar(&retries, "retries", 2, "Times to retry downloads and package installs that fail transiently")
+	installCmd.Flags().BoolVar(&installDryRun, "dry-run", false, "Print the commands that would install system dependencies without running them")
+	addProjectFileFlag(installCmd)
 	rootCmd.AddCommand(installCmd)
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"strings"
+
+	compile "github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintBaseline     bool
+	lintBaselineFile string
+	lintTool         string
+	lintWarnings     []string
+	lintChecks       string
+	lintJobs         int
+)
+
+// lintCmd reports the warnings that aren't in the baseline
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Report warnings that aren't in the warnings baseline",
+	Long: `Compile the project's sources with extra warning flags (-Wall -Wextra by
+default) and report the warnings. The objects go to build/lint/ and
+nothing is linked.
+
+With --tool clang-tidy or --tool cppcheck the sources are checked by that
+static analyzer instead. Both read build/lint/compile_commands.json, which
+lint writes with the flags catalyst.yml builds with (includes, defines,
+dependencies), so they see the code as the compiler does. --checks picks
+what they look for: clang-tidy's --checks (a .clang-tidy file is also
+read) or cppcheck's --enable (default: warning,style,performance,
+portability). A summary of the findings by check follows the report.
+Error-severity findings always fail lint and are never baselined.
+
+catalyst lint --baseline snapshots the current warnings into
+catalyst-warnings.yml (catalyst-warnings.<tool>.yml for the analyzers,
+each tool keeping its own). Commit it: from then on catalyst lint only reports
+warnings the baseline doesn't list, and exits non-zero if there are any.
+This lets a legacy codebase adopt stricter warnings one fix at a time:
+new code must be clean while the existing warnings are paid down. Line
+numbers aren't recorded, so editing other parts of a file doesn't
+resurface its warnings. Rerun with --baseline to drop warnings that were
+fixed.
+
+Options:
+  --baseline          Record the current warnings in the baseline file
+  --baseline-file     Baseline file (default: catalyst-warnings.yml, or
+                      catalyst-warnings.<tool>.yml)
+  --tool              compiler (default), clang-tidy or cppcheck
+  --warnings          Warning flags to lint with (default: -Wall,-Wextra)
+  --checks            Checks for clang-tidy or cppcheck to run
+  -j, --jobs          Number of source files to compile in parallel
+
+Examples:
+  catalyst lint --baseline                        # Snapshot today's warnings
+  catalyst lint                                   # Report only new warnings
+  catalyst lint --warnings=-Wall,-Wextra,-Wconversion --baseline
+  catalyst lint --tool clang-tidy --checks='bugprone-*,cert-*'
+  catalyst lint --tool cppcheck --checks=all`,
+	Args: cobra.NoArgs,
+	// New warnings are not a usage error
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withProject(func() error {
+			lint := compile.LintOptions{
+				Tool:     lintTool,
+				Warnings: lintWarnings,
+				Checks:   lintChecks,
+				Baseline: lintBaselineFile,
+				Update:   lintBaseline,
+			}
+			return compile.Lint(lint, compile.CompileOptions{Jobs: lintJobs})
+		})
+	},
+}
+
+func init() {
+	lintCmd.Flags().BoolVar(&lintBaseline, "baseline", false, "Record the current warnings in the baseline file")
+	lintCmd.Flags().StringVar(&lintBaselineFile, "baseline-file", "", "Baseline file (default: catalyst-warnings.yml, or catalyst-warnings.<tool>.yml)")
+	lintCmd.Flags().StringVar(&lintTool, "tool", compile.LintCompiler, "Tool to lint with: "+strings.Join(compile.LintTools, ", "))
+	lintCmd.Flags().StringSliceVar(&lintWarnings, "warnings", []string{"-Wall", "-Wextra"}, "Warning flags to lint with")
+	lintCmd.Flags().StringVar(&lintChecks, "checks", "", "Checks for clang-tidy or cppcheck to run")
+	lintCmd.Flags().IntVarP(&lintJobs, "jobs", "j", 0, "Number of source files to compile in parallel")
+	rootCmd.AddCommand(lintCmd)
+}
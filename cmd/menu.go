@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/tui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// menuCommands are the menu actions that run a command, asking for its
+// options first
+var menuCommands = map[string]*cobra.Command{
+	tui.MenuSetup:     setupCmd,
+	tui.MenuSmartInit: smartInitCmd,
+	tui.MenuAnalyze:   analyzeCmd,
+	tui.MenuInit:      initCmd,
+	tui.MenuScan:      scanCmd,
+	tui.MenuInstall:   installCmd,
+	tui.MenuBuild:     buildCmd,
+	tui.MenuRun:       runCmd,
+	tui.MenuTest:      testCmd,
+	tui.MenuClean:     cleanCmd,
+}
+
+// runMenuCommand asks for the flags and arguments of cmd and runs it the
+// way the command line does: flags are parsed by cmd, arguments and
+// required flags are validated and --help prints its help. Flags start
+// from their defaults every time, not from the previous run's values.
+// Global flags keep the values catalyst was started with.
+func runMenuCommand(cmd *cobra.Command) error {
+	words, err := tui.AskArguments(cmd.UseLine())
+	if err != nil {
+		return err
+	}
+
+	resetFlags(cmd.NonInheritedFlags())
+	cmd.InitDefaultHelpFlag()
+	if err := cmd.ParseFlags(words); err != nil {
+		return err
+	}
+	if help, _ := cmd.Flags().GetBool("help"); help {
+		return cmd.Help()
+	}
+	args := cmd.Flags().Args()
+	if err := cmd.ValidateArgs(args); err != nil {
+		return err
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return err
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return err
+	}
+	return cmd.RunE(cmd, args)
+}
+
+// resetFlags sets every flag back to its default
+func resetFlags(flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if def := strings.Trim(f.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			slice.Replace(values)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	modulesVerbose  bool
+	modulesMinFanIn int
+)
+
+// modulesCmd represents the modules command
+var modulesCmd = &cobra.Command{
+	Use:   "modules",
+	Short: "Show dependencies between in-project modules",
+	Long: `Analyze which project modules depend on which, based on the project
+headers each .c file includes. A module is a source file together with its
+matching header (e.g. utils.c + utils.h).
+
+For every module this shows:
+  • Fan-in:  how many modules include its header
+  • Fan-out: how many other modules it includes
+
+Modules with a high fan-in and no main() are good candidates to be split
+out into a separate library.
+
+Examples:
+  catalyst modules                 # Module table and library suggestions
+  catalyst modules --verbose       # Also list module edges
+  catalyst modules --min-fan-in 3  # Stricter library suggestions`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModules()
+	},
+}
+
+func init() {
+	modulesCmd.Flags().BoolVarP(&modulesVerbose, "verbose", "v", false, "Show which modules each module depends on")
+	modulesCmd.Flags().IntVar(&modulesMinFanIn, "min-fan-in", 2, "Minimum fan-in for a module to be suggested as a library")
+	rootCmd.AddCommand(modulesCmd)
+}
+
+func runModules() error {
+	fmt.Println("🔍 Analyzing module dependencies...")
+	fmt.Println()
+
+	// Get current directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// Create and run scanner
+	scanner := analyzer.NewProjectScanner(cwd)
+	if err := scanner.ScanProject(); err != nil {
+		return fmt.Errorf("failed to scan project: %w", err)
+	}
+
+	modules := scanner.BuildModuleGraph()
+	if len(modules) == 0 {
+		fmt.Println("No C/C++ source or header files found.")
+		return nil
+	}
+
+	// Column width based on the longest module name
+	width := len("Module")
+	for _, mod := range modules {
+		if len(mod.Name) > width {
+			width = len(mod.Name)
+		}
+	}
+
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("  Module Dependencies")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println()
+	fmt.Printf("  %-*s  %6s  %7s\n", width, "Module", "Fan-in", "Fan-out")
+	for _, mod := range modules {
+		fmt.Printf("  %-*s  %6d  %7d\n", width, mod.Name, mod.FanIn(), mod.FanOut())
+	}
+	fmt.Println()
+
+	if modulesVerbose {
+		for _, mod := range modules {
+			if mod.FanOut() == 0 {
+				continue
+			}
+			fmt.Printf("%s → %s\n", mod.Name, strings.Join(mod.DependsOn, ", "))
+		}
+		fmt.Println()
+	}
+
+	// Show recommendations
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println("  Recommendations")
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Println()
+
+	candidates := scanner.SuggestLibraryModules(modules, modulesMinFanIn)
+	if len(candidates) == 0 {
+		fmt.Println("  No library candidates found")
+		fmt.Printf("   → No module without main() is used by %d or more modules\n", modulesMinFanIn)
+		return nil
+	}
+
+	fmt.Printf(" %d module(s) could be split into a library:\n", len(candidates))
+	for _, mod := range candidates {
+		fmt.Printf("   • %s (used by %d modules)\n", mod.Name, mod.FanIn())
+		for _, src := range mod.SourceFiles {
+			fmt.Printf("       %s\n", src)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var pkgdbURL string
+
+// pkgdbCmd groups commands for the package translation database
+var pkgdbCmd = &cobra.Command{
+	Use:   "pkgdb",
+	Short: "Manage the package translation database",
+	Long: `Manage the database that maps header and library names to the package
+names of each package manager (e.g. openssl -> libssl-dev on apt).`,
+}
+
+// pkgdbUpdateCmd downloads the curated translation database
+var pkgdbUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Download the latest package translation database",
+	Long: `Download the curated package translation database and cache it in
+~/.catalyst/pkgdb.json. Dependency resolution consults it before the
+database built into catalyst, which only changes with new releases.
+
+Besides package names, the database records packages that live outside a
+package manager's default repositories (EPEL, PPAs, Homebrew taps, Scoop
+buckets, Chocolatey sources) under repos:, by package manager and package:
+
+  repos:
+    dnf:
+      glfw-devel: {name: epel}
+    scoop:
+      sdl2: {name: extras}
+
+The URL can also be set with pkgdb_url in ~/.catalyst.yaml.
+
+Options:
+  --url  Download from this URL instead of the default (JSON or YAML)
+
+Examples:
+  catalyst pkgdb update
+  catalyst pkgdb update --url https://example.com/catalyst-pkgdb.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPkgdbUpdate()
+	},
+}
+
+func init() {
+	pkgdbUpdateCmd.Flags().StringVar(&pkgdbURL, "url", "", "URL of the database to download")
+	pkgdbCmd.AddCommand(pkgdbUpdateCmd)
+	rootCmd.AddCommand(pkgdbCmd)
+}
+
+func runPkgdbUpdate() error {
+	url := pkgdbURL
+	if url == "" {
+		url = viper.GetString("pkgdb_url")
+	}
+	if url == "" {
+		url = pkgdb.DefaultRemoteURL
+	}
+
+	previous := pkgdb.LoadRemoteDB()
+
+	fmt.Printf("Downloading package database from %s...\n", url)
+	db, err := pkgdb.UpdateRemoteDB(url)
+	if err != nil {
+		return err
+	}
+
+	path, _ := pkgdb.RemoteDBPath()
+	fmt.Printf("✓ Package database version %d", db.Version)
+	if db.Updated != "" {
+		fmt.Printf(" (updated %s)", db.Updated)
+	}
+	fmt.Printf(": %d packages, saved to %s\n", len(db.Packages), path)
+	if previous != nil && previous.Version > db.Version {
+		fmt.Printf("Note: replaced a newer database (version %d)\n", previous.Version)
+	}
+	return nil
+}
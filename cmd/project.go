@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	projectFile string // -f/--config on build, run and install
+	startDir    string // Directory catalyst was started in, before enterProject
+)
+
+// addProjectFileFlag adds -f/--config to a command that loads catalyst.yml
+func addProjectFileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&projectFile, "config", "f", "", "Project config file (default catalyst.yml or catalyst.yaml here or in a parent directory)")
+}
+
+// enterProject finds the project config, from -f/--config or by searching
+// the current directory and then its parents, and changes into the project
+// root. Without a config nothing changes; commands report that themselves.
+func enterProject() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	startDir = cwd
+
+	if projectFile != "" {
+		return config.UseProjectFile(projectFile)
+	}
+	root, name, ok := config.FindProject(cwd)
+	if !ok {
+		return nil
+	}
+	if root != cwd {
+		log.Infof("Using %s\n", filepath.Join(root, name))
+	}
+	return config.UseProjectFile(filepath.Join(root, name))
+}
+
+// withProject runs fn from the project root while holding the project lock
+func withProject(fn func() error) error {
+	if err := enterProject(); err != nil {
+		return err
+	}
+	return withProjectLock(fn)
+}
+
+// fromStartDir rewrites a relative path given on the command line, which is
+// relative to where catalyst was started, to be relative to the project root
+func fromStartDir(path string) string {
+	if startDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(cwd, filepath.Join(startDir, path))
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// sourceArgsFromStartDir applies fromStartDir to the source files among
+// args, leaving compiler flags alone
+func sourceArgsFromStartDir(args []string) []string {
+	rebased := make([]string, len(args))
+	for i, arg := range args {
+		if len(arg) > 0 && arg[0] == '-' {
+			rebased[i] = arg
+		} else {
+			rebased[i] = fromStartDir(arg)
+		}
+	}
+	return rebased
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/fetch"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneApply bool
+	pruneYes   bool
+)
+
+// pruneCmd flags dependencies whose headers are no longer included
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Find dependencies that are no longer used",
+	Long: `Cross-check the dependencies in catalyst.yml against the headers the
+project currently includes, and report packages whose headers are no longer
+included anywhere.
+
+Packages with no known header (build tools, system libraries) cannot be
+checked and are listed separately; they are never removed.
+
+With --apply the change to catalyst.yml is shown as a diff and made only
+once confirmed, and recorded in .catalyst/changes.log.
+
+Examples:
+  catalyst prune                # Report unused dependencies
+  catalyst prune --apply        # Remove them from catalyst.yml after confirming
+  catalyst prune --apply --yes  # Remove them without asking`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		guardChanges(pruneYes)
+		return withProject(runPrune)
+	},
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneApply, "apply", false, "Remove unused dependencies from catalyst.yml")
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "Apply without asking")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func runPrune() error {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	includes, err := fetch.ScanDependencies(".")
+	if err != nil {
+		return fmt.Errorf("failed to scan dependencies: %w", err)
+	}
+
+	unused, unknown := pkgdb.FindUnusedDependencies(cfg, includes)
+
+	if len(unused) == 0 {
+		fmt.Println("✅ All checked dependencies are still used.")
+	} else {
+		fmt.Println("Unused dependencies:")
+		for _, c := range unused {
+			fmt.Printf("  • %s (%s) - provides %s\n", c.Package, c.Platform, strings.Join(c.Headers, ", "))
+		}
+	}
+
+	if len(unknown) > 0 {
+		fmt.Println()
+		fmt.Printf("Not checked (no known headers): %s\n", strings.Join(unknown, ", "))
+	}
+
+	if len(unused) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	if !pruneApply {
+		fmt.Println("Run 'catalyst prune --apply' to remove them from catalyst.yml")
+		return nil
+	}
+
+	remove := make(map[string][]string)
+	for _, c := range unused {
+		remove[c.Platform] = append(remove[c.Platform], c.Package)
+	}
+	if err := config.RemoveDependencies(config.ProjectFile, remove); err != nil {
+		return fmt.Errorf("failed to update catalyst.yml: %w", err)
+	}
+
+	fmt.Printf("Removed %d dependencies from catalyst.yml\n", len(unused))
+	return nil
+}
@@ -6,13 +6,36 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 
+	"github.com/Sabique-Islam/catalyst/internal/compile"
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/events"
+	"github.com/Sabique-Islam/catalyst/internal/guard"
+	"github.com/Sabique-Islam/catalyst/internal/lock"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
 	"github.com/Sabique-Islam/catalyst/internal/tui"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
+var (
+	cfgFile           string
+	explainResolution bool
+	waitForLock       bool
+	recordPath        string
+	quiet             bool
+	verbose           bool
+	noColor           bool
+	logFile           string
+	eventsPath        string
+	buildRoot         string
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -28,80 +51,128 @@ Features:
   • Simple build and run commands
 
 Run 'catalyst' without arguments to launch the interactive menu,
-or use one of the available commands.`,
+or use one of the available commands. The menu only offers what fits the
+current directory: creating a config, working on the project, or opening
+one of the projects of a workspace (subdirectories with their own
+catalyst.yml, as smart-init writes for multi-target projects). Each action
+asks for the options and arguments of its command, such as --dry-run for
+smart-init or test names for test; Enter runs it with the defaults. Until
+'catalyst setup' has prepared the machine, the menu offers it first.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// If no subcommand is provided, show the interactive menu
 		return runInteractiveMenu()
 	},
 }
 
-// runInteractiveMenu displays the main menu and executes the selected command
+// runInteractiveMenu displays the actions that apply to the current
+// directory and runs the selected command with the options asked for, so
+// every flag of the command line is available from the menu too
 func runInteractiveMenu() error {
+	workspaceRoot := ""
 	for {
-		choice, err := tui.RunMainMenu()
+		ctx, err := menuContext(workspaceRoot)
+		if err != nil {
+			return err
+		}
+		choice, err := tui.RunMainMenu(ctx)
 		if err != nil {
 			return err
 		}
 
-		switch choice {
-		case "Smart Init (Auto-detect & generate config)":
-			if err := smartInitCmd.RunE(smartInitCmd, []string{}); err != nil {
-				fmt.Printf("Error: Smart Init failed: %v\n\n", err)
-			}
-		case "Analyze (Show project structure)":
-			if err := analyzeCmd.RunE(analyzeCmd, []string{}); err != nil {
-				fmt.Printf("Error: Analyze failed: %v\n\n", err)
-			}
-		case "Init (Create catalyst.yml)":
-			if err := initCmd.RunE(initCmd, []string{}); err != nil {
-				fmt.Printf("Error: Init failed: %v\n\n", err)
-			}
-		case "Scan (Find dependencies)":
-			if err := scanCmd.RunE(scanCmd, []string{}); err != nil {
-				fmt.Printf("Error: Scan failed: %v\n\n", err)
-			}
-		case "Install (Install dependencies)":
-			if err := installCmd.RunE(installCmd, []string{}); err != nil {
-				fmt.Printf("Error: Install failed: %v\n\n", err)
-			}
-		case "Build":
-			if err := buildCmd.RunE(buildCmd, []string{}); err != nil {
-				fmt.Printf("Error: Build failed: %v\n\n", err)
+		var name string
+		switch cmd, ok := menuCommands[choice]; {
+		case ok:
+			name, err = cmd.Name(), runMenuCommand(cmd)
+		case choice == tui.MenuAddDependency:
+			name, err = "Add Dependency", addDependencyFromMenu()
+		case choice == tui.MenuOpenMember:
+			var member string
+			if member, err = tui.SelectMember(ctx.Members); err == nil {
+				if workspaceRoot, err = os.Getwd(); err == nil {
+					err = os.Chdir(member)
+				}
 			}
-		case "Run":
-			if err := runCmd.RunE(runCmd, []string{}); err != nil {
-				fmt.Printf("Error: Run failed: %v\n\n", err)
-			}
-		case "Clean":
-			if err := cleanCmd.RunE(cleanCmd, []string{}); err != nil {
-				fmt.Printf("Error: Clean failed: %v\n\n", err)
-			}
-		case "Exit":
+			name = "Open Member Project"
+		case choice == tui.MenuBackToRoot:
+			name, err = "Back to Workspace", os.Chdir(workspaceRoot)
+			workspaceRoot = ""
+		case choice == tui.MenuExit:
 			fmt.Println("Goodbye!")
 			return nil
 		default:
 			fmt.Printf("Unknown option: %s\n", choice)
 		}
+		if err != nil {
+			fmt.Printf("Error: %s failed: %v\n\n", name, err)
+		}
 	}
 }
 
+// menuContext describes the current directory for the interactive menu
+func menuContext(workspaceRoot string) (tui.MenuContext, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return tui.MenuContext{}, err
+	}
+	ctx := tui.MenuContext{InWorkspace: workspaceRoot != "", NeedsSetup: !setupDone()}
+	root, _, ok := config.FindProject(cwd)
+	ctx.HasConfig = ok && root == cwd
+	if !ctx.HasConfig {
+		ctx.Members = config.FindMembers(cwd)
+	}
+	return ctx, nil
+}
+
+// addDependencyFromMenu asks for a package and adds it to the dependencies
+// of this OS in the project config
+func addDependencyFromMenu() error {
+	pkg, err := tui.NewPrompter().Input(fmt.Sprintf("Package to add for %s", runtime.GOOS), "", nil)
+	if err != nil {
+		return err
+	}
+	pkg = strings.TrimSpace(pkg)
+	if pkg == "" {
+		return nil
+	}
+	return withProject(func() error {
+		edit := config.ListEdit{Path: []string{"dependencies", runtime.GOOS}, Add: []string{pkg}}
+		if err := config.EditLists(config.ProjectFile, []config.ListEdit{edit}); err != nil {
+			return err
+		}
+		fmt.Printf("Added %s to %s. Run Install to install it.\n\n", pkg, config.ProjectFile)
+		return nil
+	})
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	// os.Exit skips deferred calls
+	util.CleanupTemp()
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initConfig, initLogging, initBuildRoot, initResolutionTrace, initPackageManagerPreference, initCompilerPriority, initRecording, initEvents)
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.catalyst.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&waitForLock, "wait", false, "Wait for other catalyst processes in this project to finish instead of failing")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print warnings and errors")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print detailed output such as compiler and linker commands")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also disabled by NO_COLOR)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append all output, including --verbose details, to this file")
+	rootCmd.PersistentFlags().StringVar(&eventsPath, "events-json", "", "Write progress events (resolution, installs, compiled files, links) to this file as JSON lines")
+	rootCmd.PersistentFlags().StringVar(&recordPath, "record", "", "Append every external command catalyst runs to this file (attach it to bug reports)")
+	rootCmd.PersistentFlags().StringVar(&buildRoot, "build-root", "", "Write build output, caches and other generated state to this directory instead of the project (default $CATALYST_BUILD_ROOT)")
+	rootCmd.PersistentFlags().BoolVar(&compile.Redetect, "redetect", false, "Detect compilers and installed packages again instead of using the caches in ~/.catalyst")
+	rootCmd.PersistentFlags().BoolVar(&explainResolution, "explain-resolution", false, "Show every package candidate considered for each header and why it was accepted or rejected")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -131,3 +202,138 @@ func initConfig() {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
+
+// initPackageManagerPreference applies macos_package_manager and
+// msys2_environment from ~/.catalyst.yaml or CATALYST_MACOS_PACKAGE_MANAGER,
+// and --redetect to the installed packages cache
+func initPackageManagerPreference() {
+	viper.BindEnv("macos_package_manager", "CATALYST_MACOS_PACKAGE_MANAGER")
+	platform.PreferredMacOSManager = viper.GetString("macos_package_manager")
+	platform.PreferredMSYS2Environment = viper.GetString("msys2_environment")
+	platform.Redetect = compile.Redetect
+}
+
+// initCompilerPriority applies compiler_priority from ~/.catalyst.yaml, a list
+// of compilers to look for per platform
+func initCompilerPriority() {
+	compile.CompilerPriority = viper.GetStringMapStringSlice("compiler_priority")
+}
+
+// withProjectLock runs fn while holding the project lock, so concurrent
+// catalyst processes don't corrupt build/ or race on catalyst.yml writes
+func withProjectLock(fn func() error) error {
+	projectLock, err := lock.AcquireProject(waitForLock)
+	if err != nil {
+		return err
+	}
+	defer projectLock.Release()
+	return fn()
+}
+
+// lockProject takes the project lock for one build of run or watch, which
+// don't hold it while the program runs or while waiting for changes
+func lockProject() (func(), error) {
+	projectLock, err := lock.AcquireProject(waitForLock)
+	if err != nil {
+		return nil, err
+	}
+	return projectLock.Release, nil
+}
+
+// withProjectLockIn runs fn while holding the project lock of dir, for
+// commands that write a project other than the current directory's
+func withProjectLockIn(dir string, fn func() error) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	// The lock is found from the working directory; fn runs from the
+	// original one, which dir may be relative to
+	projectLock, err := lock.AcquireProject(waitForLock)
+	if chdirErr := os.Chdir(cwd); chdirErr != nil && err == nil {
+		projectLock.Release()
+		err = chdirErr
+	}
+	if err != nil {
+		return err
+	}
+	defer projectLock.Release()
+	return fn()
+}
+
+// guardChanges makes overwrites of catalyst.yml show the diff and ask
+// first; yes (--yes) confirms them and removals without asking
+func guardChanges(yes bool) {
+	guard.AssumeYes = yes
+	config.ConfirmWrite = guard.ConfirmReplace
+	config.RecordWrite = guard.RecordReplace
+}
+
+// initLogging applies --quiet, --verbose, --no-color and --log-file
+func initLogging() {
+	if quiet && verbose {
+		cobra.CheckErr(fmt.Errorf("--quiet and --verbose cannot be used together"))
+	}
+	switch {
+	case quiet:
+		log.SetLevel(log.LevelWarn)
+	case verbose:
+		log.SetLevel(log.LevelDebug)
+	}
+	log.SetColor(!noColor && log.ColorSupported())
+	if logFile != "" {
+		// The file is closed when the process exits
+		_, err := log.SetFile(logFile)
+		cobra.CheckErr(err)
+	}
+}
+
+// initBuildRoot applies --build-root or CATALYST_BUILD_ROOT. A relative
+// path is taken from where catalyst was started, before it changes to the
+// project root.
+func initBuildRoot() {
+	if buildRoot == "" {
+		buildRoot = os.Getenv("CATALYST_BUILD_ROOT")
+	}
+	if buildRoot == "" {
+		return
+	}
+	abs, err := filepath.Abs(buildRoot)
+	cobra.CheckErr(err)
+	cobra.CheckErr(os.MkdirAll(abs, 0755))
+	util.BuildRoot = abs
+}
+
+// initResolutionTrace makes dependency resolvers explain their decisions
+func initResolutionTrace() {
+	if explainResolution {
+		pkgdb.ExplainOutput = os.Stdout
+	}
+}
+
+// initEvents renders progress events on the console and writes them to the
+// --events-json file
+func initEvents() {
+	events.Subscribe(events.Console)
+	if eventsPath == "" {
+		return
+	}
+	// The file is closed when the process exits
+	f, err := os.Create(eventsPath)
+	cobra.CheckErr(err)
+	events.Subscribe(events.JSONLines(f))
+}
+
+// initRecording logs every external command to the --record file
+func initRecording() {
+	if recordPath == "" {
+		return
+	}
+	f, err := os.OpenFile(recordPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	cobra.CheckErr(err)
+	fmt.Fprintf(f, "# catalyst %s\n", strings.Join(os.Args[1:], " "))
+	util.Exec = util.NewRecordingExecutor(f, util.Exec)
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var runProfile string
+
 // runCmd represents the run command
 var runCmd = &cobra.Command{
 	Use:   "run",
@@ -17,15 +19,27 @@ var runCmd = &cobra.Command{
 If source files are provided, it will build them first and then run the resulting binary.
 If no source files are provided, it will try to run the existing binary at bin/project.
 
+Options:
+  --profile  Run the binary of a build profile, from build/<profile>/,
+             building it with that profile first if it doesn't exist
+
 Examples:
   catalyst run src/main.c              # Build and run
   catalyst run src/main.c src/utils.c  # Build multiple files and run
-  catalyst run                         # Run existing binary`,
+  catalyst run                         # Run existing binary
+  catalyst run --profile release       # Run the release build`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return compile.RunProject(args)
+		// Only the build holds the project lock, so other commands can run
+		// while the program does
+		if err := enterProject(); err != nil {
+			return err
+		}
+		return compile.RunProject(sourceArgsFromStartDir(args), compile.CompileOptions{Profile: runProfile, Lock: lockProject})
 	},
 }
 
 func init() {
+	runCmd.Flags().StringVar(&runProfile, "profile", "", "Build profile whose binary to run (e.g. debug, release)")
+	addProjectFileFlag(runCmd)
 	rootCmd.AddCommand(runCmd)
 }
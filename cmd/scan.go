@@ -37,6 +37,7 @@ func runScan() error {
 	if err != nil {
 		return fmt.Errorf("scan failed: %w", err)
 	}
+	deps = fetch.ExternalDependencies(deps)
 
 	if len(deps) == 0 {
 		fmt.Println("No external dependencies found (only standard library headers)")
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/selftest"
+	"github.com/spf13/cobra"
+)
+
+var selftestKeep bool
+
+// selftestCmd checks the environment end-to-end with generated projects
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Verify catalyst and the C toolchain end-to-end",
+	Long: `Generate tiny C projects in a temporary directory and run scan, init,
+build, run and install against them, to check that catalyst works with
+this machine's compiler. Installs go to a fake package manager, so nothing
+is installed on the system.
+
+Options:
+  --keep  Keep the generated projects and print where they are
+
+Examples:
+  catalyst selftest         # Run all checks
+  catalyst selftest --keep  # Keep the fixtures for inspection`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSelftest()
+	},
+}
+
+func init() {
+	selftestCmd.Flags().BoolVar(&selftestKeep, "keep", false, "Keep the generated fixture projects")
+	rootCmd.AddCommand(selftestCmd)
+}
+
+func runSelftest() error {
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot find the catalyst executable: %w", err)
+	}
+
+	workDir := ""
+	if selftestKeep {
+		workDir, err = os.MkdirTemp("", "catalyst-selftest-")
+		if err != nil {
+			return fmt.Errorf("cannot create fixture directory: %w", err)
+		}
+	}
+
+	fmt.Println("Running catalyst self-test...")
+	fmt.Println()
+	results, err := selftest.Run(selftest.Options{Binary: binary, WorkDir: workDir, Keep: selftestKeep})
+	if err != nil {
+		return err
+	}
+
+	var failed *selftest.Result
+	for i, r := range results {
+		if r.Err != nil {
+			fmt.Printf("  ✗ %s\n", r.Name)
+			failed = &results[i]
+			continue
+		}
+		fmt.Printf("  ✓ %s (%s)\n", r.Name, r.Duration.Round(time.Millisecond))
+	}
+	fmt.Println()
+
+	if selftestKeep {
+		fmt.Printf("Fixtures kept in %s\n", workDir)
+	}
+	if failed != nil {
+		return fmt.Errorf("self-test failed at %q: %w", failed.Name, failed.Err)
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
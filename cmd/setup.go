@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/compile"
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/guard"
+	"github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var setupYes bool
+
+// setupCmd prepares the machine for catalyst
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Check this machine and install what catalyst needs",
+	Long: `Prepare this machine for catalyst; run it once after installing catalyst,
+and again whenever builds complain about the environment.
+
+Setup:
+  - Detects the OS, the package manager and the C compilers in PATH
+  - Offers to install a compiler when there is none
+  - Offers to install the programs catalyst works better with: pkg-config,
+    and apt-file on apt systems (its database is downloaded too)
+  - Records in ~/.catalyst.yaml that setup has run, and the MSYS2 environment
+    of the shell it runs in when that isn't the default; other defaults are
+    detected again on each run, so they aren't written there
+  - Prints a summary of what is ready and what is still missing
+
+Options:
+  --yes               Install the missing programs without asking
+
+Examples:
+  catalyst setup
+  catalyst setup --yes`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		guard.AssumeYes = setupYes
+		return runSetup()
+	},
+}
+
+func init() {
+	setupCmd.Flags().BoolVarP(&setupYes, "yes", "y", false, "Install the missing programs without asking")
+	rootCmd.AddCommand(setupCmd)
+}
+
+// setupDone reports whether catalyst setup has run on this machine
+func setupDone() bool {
+	return viper.IsSet("setup_completed")
+}
+
+// globalConfigPath returns the global config file: --config, the file
+// read at startup, or ~/.catalyst.yaml
+func globalConfigPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	if used := viper.ConfigFileUsed(); used != "" {
+		return used, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".catalyst.yaml"), nil
+}
+
+func runSetup() error {
+	fmt.Println("Catalyst Setup")
+	fmt.Println("==============")
+
+	// Platform and package manager
+	support := platform.DetectSupport()
+	fmt.Printf("Platform: %s, %s\n", support.Host(), platform.DetectArch())
+	if support.FullySupported() {
+		fmt.Printf("Package manager: %s\n", support.PackageManager)
+	} else if support.NativeManager != "" {
+		fmt.Print(support.Report(nil))
+	} else {
+		fmt.Println("Package manager: none found")
+		fmt.Print(platform.GetPackageManagerSetupAdvice())
+	}
+
+	// Compilers
+	fmt.Println("\nCompilers:")
+	compilers := compile.DetectCompilers()
+	if !hasHostCompiler(compilers) {
+		if _, err := install.OfferCompiler(); err != nil {
+			log.Warn(err)
+		}
+		compilers = compile.DetectCompilers()
+	}
+	others := 0
+	for _, c := range compilers {
+		if c.Rank < 0 {
+			others++
+			continue
+		}
+		version := c.Version
+		if version == "" {
+			version = "(unknown version)"
+		}
+		fmt.Printf("  %s: %s\n", c.Name, version)
+	}
+	if others > 0 {
+		fmt.Printf("  and %d versioned or cross compilers (catalyst compilers list shows them)\n", others)
+	}
+
+	// Programs catalyst works better with
+	var missing []string
+	essentials := install.Essentials()
+	if len(essentials) > 0 {
+		fmt.Println("\nTools:")
+	}
+	for _, e := range essentials {
+		if e.Present {
+			fmt.Printf("  %s: installed\n", e.Name)
+			continue
+		}
+		fmt.Printf("  %s: missing (%s)\n", e.Name, e.Why)
+		if !guard.Confirm(fmt.Sprintf("Install %s?", e.Name)) {
+			missing = append(missing, e.Name)
+			continue
+		}
+		if err := e.Install(); err != nil {
+			log.Warn(err)
+			missing = append(missing, e.Name)
+		}
+	}
+
+	// Global settings
+	path, err := globalConfigPath()
+	if err != nil {
+		return err
+	}
+	set, err := config.SetTopLevel(path, setupDefaults(), "setup_completed")
+	if err != nil {
+		return err
+	}
+	log.Debugf("Set %s in %s\n", strings.Join(set, ", "), path)
+
+	// Summary
+	fmt.Println("\nSummary:")
+	ready := true
+	if !support.FullySupported() {
+		fmt.Println("  ✗ No supported package manager: install dependencies yourself")
+		ready = false
+	}
+	if !hasHostCompiler(compilers) {
+		fmt.Println("  ✗ No C compiler: run catalyst setup again after installing one")
+		ready = false
+	} else {
+		fmt.Printf("  ✓ Builds use %s\n", compilers[0].Name)
+	}
+	if len(missing) > 0 {
+		fmt.Printf("  ✗ Not installed: %s\n", strings.Join(missing, ", "))
+	}
+	fmt.Printf("  ✓ Settings written to %s\n", path)
+	if ready {
+		fmt.Println("\nThis machine is ready. Run catalyst smart-init in a project to get started.")
+	}
+	return nil
+}
+
+// hasHostCompiler reports whether builds for this machine have a compiler;
+// cross compilers are only listed after it
+func hasHostCompiler(compilers []compile.DetectedCompiler) bool {
+	return len(compilers) > 0 && compilers[0].Rank >= 0
+}
+
+// setupDefaults returns the global settings to write. Only settings that
+// differ from what catalyst would use without them are written, so a later
+// catalyst's defaults aren't frozen in ~/.catalyst.yaml.
+func setupDefaults() map[string]any {
+	values := map[string]any{
+		"setup_completed": time.Now().Format(time.RFC3339),
+	}
+	// The environment of the MSYS2 shell setup runs in, e.g. MSYSTEM=CLANG64
+	if runtime.GOOS == "windows" && platform.MSYS2Root() != "" {
+		if env := platform.MSYS2Environment(); env != platform.DefaultMSYS2Environment() {
+			values["msys2_environment"] = env
+		}
+	}
+	return values
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/Sabique-Islam/catalyst/internal/analyzer"
 	core "github.com/Sabique-Islam/catalyst/internal/config"
@@ -17,6 +20,8 @@ var (
 	analyzeReport bool
 	dryRun        bool
 	interactive   bool
+	windowsShims  bool
+	smartInitYes  bool
 )
 
 // smartInitCmd represents the smart-init command
@@ -38,6 +43,12 @@ Modes:
   --interactive   Interactive mode with suggestions (default)
   --dry-run       Show what would be generated without creating files
   --analyze       Show analysis report only
+  --windows-shims Add POSIX-on-Windows shims to the Windows configuration
+  --yes           Overwrite existing catalyst.yml files without asking
+
+Existing catalyst.yml files are only replaced after their diff against the
+generated config is shown and confirmed; --auto skips them unless --yes is
+given. Replacements are recorded in .catalyst/changes.log.
 
 Examples:
   catalyst smart-init                    # Interactive mode
@@ -45,7 +56,8 @@ Examples:
   catalyst smart-init --dry-run          # Preview changes
   catalyst smart-init --analyze          # Analysis report only`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSmartInit()
+		guardChanges(smartInitYes)
+		return withProjectLock(runSmartInit)
 	},
 }
 
@@ -55,6 +67,8 @@ func init() {
 	smartInitCmd.Flags().BoolVar(&analyzeReport, "analyze", false, "Show analysis report only")
 	smartInitCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be generated without creating files")
 	smartInitCmd.Flags().BoolVar(&interactive, "interactive", true, "Interactive mode with suggestions")
+	smartInitCmd.Flags().BoolVarP(&smartInitYes, "yes", "y", false, "Overwrite existing catalyst.yml files without asking")
+	smartInitCmd.Flags().BoolVar(&windowsShims, "windows-shims", false, "Add POSIX-on-Windows shims (pthreads-win32, winsock, getopt) to the Windows configuration")
 	rootCmd.AddCommand(smartInitCmd)
 }
 
@@ -89,8 +103,19 @@ func runSmartInit() error {
 		return nil
 	}
 
+	// Suggest shims for POSIX headers that won't build on Windows as-is
+	if shims := scanner.DetectPosixShims(); len(shims) > 0 {
+		fmt.Println("🪟 Windows portability:")
+		printShimAdvice(shims)
+		if !windowsShims {
+			fmt.Println("   → Re-run with --windows-shims to add them to the Windows configuration")
+		}
+		fmt.Println()
+	}
+
 	// Generate configurations
 	generator := analyzer.NewConfigGenerator(scanner, cwd)
+	generator.WindowsShims = windowsShims
 	configs, err := generator.GenerateConfigs()
 	if err != nil {
 		return fmt.Errorf("failed to generate configs: %w", err)
@@ -106,7 +131,8 @@ func runSmartInit() error {
 	fmt.Println()
 
 	// Display or create configs
-	for configPath, config := range configs {
+	for _, configPath := range slices.Sorted(maps.Keys(configs)) {
+		config := configs[configPath]
 		fullPath := filepath.Join(cwd, configPath)
 
 		if dryRun {
@@ -117,24 +143,17 @@ func runSmartInit() error {
 			fmt.Println(string(yamlData))
 			fmt.Println()
 		} else {
-			// Check if file already exists
-			if _, err := os.Stat(fullPath); err == nil {
-				if !autoMode {
-					fmt.Printf("%s already exists. Overwrite? (y/N): ", configPath)
-					var response string
-					fmt.Scanln(&response)
-					if response != "y" && response != "Y" {
-						fmt.Printf("   Skipping %s\n", configPath)
-						continue
-					}
-				} else {
-					fmt.Printf("%s already exists, skipping...\n", configPath)
-					continue
-				}
+			// Automatic mode doesn't ask, so it only replaces files with --yes
+			if _, err := os.Stat(fullPath); err == nil && autoMode && !smartInitYes {
+				fmt.Printf("%s already exists, skipping (pass --yes to overwrite)...\n", configPath)
+				continue
 			}
 
-			// Create the config file
-			if err := writeConfig(fullPath, config); err != nil {
+			// Create the config file, confirming the diff of an existing one
+			if err := writeConfig(fullPath, config); errors.Is(err, core.ErrWriteDeclined) {
+				fmt.Printf("   Skipping %s\n", configPath)
+				continue
+			} else if err != nil {
 				fmt.Printf("Failed to create %s: %v\n", configPath, err)
 				continue
 			}
@@ -153,7 +172,7 @@ func runSmartInit() error {
 			fmt.Println("  catalyst run      # Build and run")
 		} else {
 			fmt.Println("  cd <target-dir> && catalyst build")
-			for configPath := range configs {
+			for _, configPath := range slices.Sorted(maps.Keys(configs)) {
 				dir := filepath.Dir(configPath)
 				if dir != "." {
 					fmt.Printf("  cd %s && catalyst build\n", dir)
@@ -172,16 +191,5 @@ func writeConfig(path string, config *core.Config) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Marshal config to YAML
-	data, err := yaml.Marshal(config)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
+	return core.SaveConfig(config, path)
 }
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	core "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/fetch"
+	"github.com/Sabique-Islam/catalyst/internal/guard"
+	"github.com/Sabique-Islam/catalyst/internal/headers"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncYes    bool
+	syncDryRun bool
+)
+
+// syncCmd updates catalyst.yml from a fresh scan without regenerating it
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Update catalyst.yml from a fresh project scan",
+	Long: `Re-scan the project, resolve its dependencies again and show what would
+change in catalyst.yml: new or removed sources, new or removed dependencies
+and new flags. Each change is applied only after you approve it, and the
+rest of the file (comments, ordering, hand-written settings) is kept.
+Applied changes are recorded in .catalyst/changes.log.
+
+Examples:
+  catalyst sync            # Review and approve each change
+  catalyst sync --dry-run  # Only show the changes
+  catalyst sync --yes      # Apply all changes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withProject(runSync)
+	},
+}
+
+func init() {
+	syncCmd.Flags().BoolVarP(&syncYes, "yes", "y", false, "Apply all changes without asking")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show the changes without applying them")
+	rootCmd.AddCommand(syncCmd)
+}
+
+// syncChange is one proposed change to catalyst.yml
+type syncChange struct {
+	Description string
+	Edit        core.ListEdit
+}
+
+func runSync() error {
+	current, err := core.LoadConfig(core.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	fmt.Println("🔍 Re-scanning project...")
+	scanner := analyzer.NewProjectScanner(cwd)
+	if err := scanner.ScanProject(); err != nil {
+		return fmt.Errorf("failed to scan project: %w", err)
+	}
+
+	generator := analyzer.NewConfigGenerator(scanner, cwd)
+	configs, err := generator.GenerateConfigs()
+	if err != nil {
+		return fmt.Errorf("failed to generate configs: %w", err)
+	}
+
+	fresh := pickSyncTarget(configs, current)
+	if fresh == nil {
+		return fmt.Errorf("no build target in this directory matches catalyst.yml (project %s)", current.ProjectName)
+	}
+
+	// Packages resolved for the headers, as init found them, so they aren't
+	// proposed for removal
+	if err := addResolvedDependencies(fresh, current, cwd); err != nil {
+		return err
+	}
+
+	// Forced package names survive a re-scan
+	fresh.ApplyPackageOverrides(current.PackageOverrides)
+
+	changes := diffConfigs(current, fresh)
+	fmt.Println()
+	if len(changes) == 0 {
+		fmt.Println("✅ catalyst.yml is up to date.")
+		return nil
+	}
+
+	fmt.Printf("%d change(s) to catalyst.yml:\n", len(changes))
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change.Description)
+	}
+	fmt.Println()
+
+	if syncDryRun {
+		return nil
+	}
+
+	var approved []core.ListEdit
+	var applied []string
+	for _, change := range changes {
+		if !syncYes {
+			fmt.Printf("Apply %s? (y/N): ", change.Description)
+			var response string
+			fmt.Scanln(&response)
+			if response != "y" && response != "Y" {
+				continue
+			}
+		}
+		approved = append(approved, change.Edit)
+		applied = append(applied, change.Description)
+	}
+
+	if len(approved) == 0 {
+		fmt.Println("No changes applied.")
+		return nil
+	}
+
+	if err := core.EditLists(core.ProjectFile, approved); err != nil {
+		return fmt.Errorf("failed to update catalyst.yml: %w", err)
+	}
+	guard.Record("updated %s\n  %s", core.ProjectFile, strings.Join(applied, "\n  "))
+
+	fmt.Printf("Applied %d change(s) to catalyst.yml\n", len(approved))
+	return nil
+}
+
+// pickSyncTarget selects the generated config for ./catalyst.yml, falling back
+// to the target with the same project name
+func pickSyncTarget(configs map[string]*core.Config, current *core.Config) *core.Config {
+	if cfg, ok := configs["catalyst.yml"]; ok {
+		return cfg
+	}
+	for path, cfg := range configs {
+		if cfg.ProjectName == current.ProjectName && filepath.Dir(path) == "." {
+			return cfg
+		}
+	}
+	return nil
+}
+
+// syncPlatforms are the platforms dependencies are resolved for, with the
+// package manager whose names are used when it isn't the host's
+var syncPlatforms = []struct{ os, pkgManager string }{{"darwin", "brew"}, {"linux", "apt"}, {"windows", "vcpkg"}}
+
+// addResolvedDependencies resolves the headers of the project in dir with
+// the resolution settings of current and adds the packages to fresh, which
+// only has the dependencies of known libraries
+func addResolvedDependencies(fresh, current *core.Config, dir string) error {
+	pkgManager, err := platform.DetectPackageManager(platform.DetectOS())
+	if err != nil {
+		return fmt.Errorf("could not detect package manager: %w", err)
+	}
+	resolver, err := pkgdb.NewResolver(pkgManager, current.Resolution)
+	if err != nil {
+		return err
+	}
+	resolver.Overrides = current.PackageOverrides
+
+	names, err := fetch.ScanDependencies(dir)
+	if err != nil {
+		return fmt.Errorf("failed to scan dependencies: %w", err)
+	}
+	localHeaders := fetch.LocalHeaderNames(dir)
+	if fresh.Dependencies == nil {
+		fresh.Dependencies = make(map[string][]string)
+	}
+	for _, name := range names {
+		// Windows SDK headers need no package, only their import libraries
+		if localHeaders[name] || len(headers.WindowsLibraries(name)) > 0 {
+			continue
+		}
+		res, err := resolver.Resolve(name)
+		if err != nil {
+			return err
+		}
+		if !res.Found || res.Standard {
+			continue
+		}
+		for _, target := range syncPlatforms {
+			// Use the host's own package manager for its platform (e.g. MacPorts)
+			if platform.PackageManagerOS(pkgManager) == target.os {
+				target.pkgManager = pkgManager
+			}
+			osRes := res
+			if target.pkgManager != pkgManager {
+				osRes, _ = resolver.ResolveFor(name, target.pkgManager)
+			}
+			if pkg := osRes.Package; pkg != "" && !slices.Contains(fresh.Dependencies[target.os], pkg) {
+				fresh.Dependencies[target.os] = append(fresh.Dependencies[target.os], pkg)
+			}
+		}
+	}
+	return nil
+}
+
+// diffConfigs lists the changes needed to bring current in line with fresh.
+// Flags are only ever added, since hand-written flags can't be told apart
+// from stale ones.
+func diffConfigs(current, fresh *core.Config) []syncChange {
+	var changes []syncChange
+
+	added, removed := diffLists(current.Sources, fresh.Sources)
+	for _, src := range added {
+		changes = append(changes, syncChange{"+ source " + src, core.ListEdit{Path: []string{"sources"}, Add: []string{src}}})
+	}
+	for _, src := range removed {
+		changes = append(changes, syncChange{"- source " + src, core.ListEdit{Path: []string{"sources"}, Remove: []string{src}}})
+	}
+
+	platforms := make(map[string]bool)
+	for platform := range current.Dependencies {
+		platforms[platform] = true
+	}
+	for platform := range fresh.Dependencies {
+		platforms[platform] = true
+	}
+	var platformNames []string
+	for platform := range platforms {
+		platformNames = append(platformNames, platform)
+	}
+	sort.Strings(platformNames)
+
+	for _, platform := range platformNames {
+		path := []string{"dependencies", platform}
+		added, removed := diffLists(current.Dependencies[platform], fresh.Dependencies[platform])
+		for _, dep := range added {
+			changes = append(changes, syncChange{fmt.Sprintf("+ %s dependency %s", platform, dep), core.ListEdit{Path: path, Add: []string{dep}}})
+		}
+		for _, dep := range removed {
+			changes = append(changes, syncChange{fmt.Sprintf("- %s dependency %s", platform, dep), core.ListEdit{Path: path, Remove: []string{dep}}})
+		}
+	}
+
+	added, _ = diffLists(current.Flags, fresh.Flags)
+	if len(added) > 0 {
+		changes = append(changes, syncChange{"+ flags " + strings.Join(added, " "), core.ListEdit{Path: []string{"flags"}, Add: added}})
+	}
+
+	return changes
+}
+
+// diffLists returns the items only in fresh (added) and only in current (removed)
+func diffLists(current, fresh []string) ([]string, []string) {
+	inCurrent := make(map[string]bool)
+	for _, item := range current {
+		inCurrent[item] = true
+	}
+	inFresh := make(map[string]bool)
+	for _, item := range fresh {
+		inFresh[item] = true
+	}
+
+	var added, removed []string
+	for _, item := range fresh {
+		if !inCurrent[item] {
+			added = append(added, item)
+		}
+	}
+	for _, item := range current {
+		if !inFresh[item] {
+			removed = append(removed, item)
+		}
+	}
+	return added, removed
+}
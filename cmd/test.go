@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	compile "github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	testSandbox  bool
+	testCoverage string
+)
+
+// testCmd builds and runs the test targets from catalyst.yml
+var testCmd = &cobra.Command{
+	Use:   "test [test names...]",
+	Short: "Build and run the tests defined in catalyst.yml",
+	Long: `Compile each test in the tests: section of catalyst.yml into build/tests/,
+run it and report which tests passed. A test passes when its binary exits
+with status 0. The command exits non-zero if any test fails to build or run.
+
+Tests are compiled with the project's flags and dependencies, plus their own
+flags and libs.
+
+With --coverage the tests are built with coverage instrumentation into
+build/coverage/ instead, and after they run a report of the lines they
+executed is written there: build/coverage/coverage.info (lcov) and, unless
+--coverage=lcov, an HTML report in build/coverage/html/. GCC builds are
+measured with gcov through lcov and genhtml, Clang builds with
+llvm-profdata and llvm-cov; the tools are installed if they're missing.
+
+Options:
+  --coverage[=html|lcov]  Measure coverage and write a report (default: html)
+  --sandbox               Restrict compiler and generator writes to the
+                          build directory
+
+Examples:
+  catalyst test                   # Run all tests
+  catalyst test unit parser       # Run only the named tests
+  catalyst test --coverage        # Open build/coverage/html/index.html
+  catalyst test --coverage=lcov   # For Codecov, Coveralls or an editor`,
+	// A failing test is not a usage error
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withProject(func() error {
+			opts := compile.CompileOptions{Sandbox: testSandbox}
+			if testCoverage != "" {
+				return compile.RunTestsWithCoverage(args, testCoverage, opts)
+			}
+			return compile.RunTests(args, opts)
+		})
+	},
+}
+
+func init() {
+	testCmd.Flags().BoolVar(&testSandbox, "sandbox", false, "Restrict compiler and generator writes to the build directory")
+	testCmd.Flags().StringVar(&testCoverage, "coverage", "", "Measure coverage and write an html or lcov report to build/coverage/")
+	testCmd.Flags().Lookup("coverage").NoOptDefVal = compile.CoverageHTML
+	rootCmd.AddCommand(testCmd)
+}
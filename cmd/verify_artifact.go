@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	compile "github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/spf13/cobra"
+)
+
+var verifyMetadata string
+
+// verifyArtifactCmd checks a built binary against its build metadata
+var verifyArtifactCmd = &cobra.Command{
+	Use:   "verify-artifact <binary>",
+	Short: "Check a built binary against its build metadata",
+	Long: `Re-verify a binary built earlier against the metadata catalyst build
+wrote next to it (<binary>.json) before shipping it:
+
+  - the binary and every source file still match their recorded sha256
+  - the recorded dependencies are installed at the recorded versions
+  - the shared libraries the binary links against can be found (ldd on
+    Linux, otool -L on macOS)
+
+Any drift is reported and the command fails.
+
+Options:
+  --metadata  Read the metadata from this file instead of <binary>.json
+
+Examples:
+  catalyst verify-artifact build/myapp
+  catalyst verify-artifact build/release/myapp
+  catalyst verify-artifact dist/myapp --metadata dist/myapp.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Recorded source paths are relative to the project root
+		if err := enterProject(); err != nil {
+			return err
+		}
+		metadata := verifyMetadata
+		if metadata != "" {
+			metadata = fromStartDir(metadata)
+		}
+		checks, err := compile.VerifyArtifact(fromStartDir(args[0]), metadata)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, c := range checks {
+			icon := "✅"
+			switch c.Status {
+			case compile.CheckFailed:
+				icon = "❌"
+				failed++
+			case compile.CheckSkipped:
+				icon = "⚠️ "
+			}
+			fmt.Printf("%s %s: %s\n", icon, c.Name, c.Detail)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d checks failed: %s has drifted from its build", failed, len(checks), args[0])
+		}
+		fmt.Println("\nArtifact verified.")
+		return nil
+	},
+}
+
+func init() {
+	verifyArtifactCmd.Flags().StringVar(&verifyMetadata, "metadata", "", "Build metadata file (default <binary>.json)")
+	rootCmd.AddCommand(verifyArtifactCmd)
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	compile "github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchRun     bool
+	watchSandbox bool
+	watchJobs    int
+	watchProfile string
+)
+
+// watchCmd rebuilds the project whenever its files change
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Rebuild the project whenever sources change",
+	Long: `Build the project from catalyst.yml, then watch its sources, headers and
+catalyst.yml and rebuild whenever one of them changes. Stop with Ctrl+C.
+
+Examples:
+  catalyst watch        # Rebuild on every change
+  catalyst watch --run  # Rebuild and restart the program on every change
+  catalyst watch --run --profile release`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Each rebuild takes the project lock, so other commands can run
+		// between them
+		if err := enterProject(); err != nil {
+			return err
+		}
+		return compile.Watch(compile.CompileOptions{Sandbox: watchSandbox, Jobs: watchJobs, Profile: watchProfile, Lock: lockProject}, watchRun)
+	},
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchRun, "run", false, "Run the binary after each successful build")
+	watchCmd.Flags().BoolVar(&watchSandbox, "sandbox", false, "Restrict compiler and generator writes to the build directory")
+	watchCmd.Flags().IntVarP(&watchJobs, "jobs", "j", 0, "Number of source files to compile in parallel")
+	watchCmd.Flags().StringVar(&watchProfile, "profile", "", "Build profile to use (e.g. debug, release)")
+	rootCmd.AddCommand(watchCmd)
+}
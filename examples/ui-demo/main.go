@@ -10,7 +10,8 @@ import (
 )
 
 func main() {
-	choice, err := tui.RunMainMenu()
+	// Show the menu of a directory without a catalyst.yml
+	choice, err := tui.RunMainMenu(tui.MenuContext{})
 	if err != nil {
 		log.Fatalf("Main menu error: %v", err)
 	}
@@ -18,21 +19,23 @@ func main() {
 	fmt.Printf("\nYou selected: %s\n\n", choice)
 
 	// Test 2: If user selected "Init", run the wizard
-	if choice == "Init (Create catalyst.yml)" {
+	if choice == tui.MenuInit {
 		fmt.Println("Running Init Wizard...")
 		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 		fmt.Println()
 
-		config, automate, err := tui.RunInitWizard()
+		wizard, err := tui.RunInitWizard()
 		if err != nil {
 			log.Fatalf("Init wizard error: %v", err)
 		}
+		config := wizard.Config
 
 		// Handle automation preference
 		fmt.Println()
-		if automate {
+		if wizard.Automate {
 			fmt.Println("Automation Mode Selected")
 			fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+			fmt.Printf("Resolution method: %s\n", wizard.ResolutionMode)
 			fmt.Println("Scanning for dependencies...")
 		} else {
 			fmt.Println("Manual Mode Selected")
@@ -62,7 +65,7 @@ func main() {
 		} else {
 			fmt.Println("Configuration not saved.")
 		}
-	} else if choice == "Exit" {
+	} else if choice == tui.MenuExit {
 		fmt.Println("Goodbye!")
 	} else {
 		fmt.Printf("In a real application, this would execute: %s\n", choice)
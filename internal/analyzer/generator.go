@@ -2,18 +2,25 @@ package analyzer
 
 import (
 	"fmt"
+	"maps"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"time"
 
 	core "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/headers"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 )
 
 // ConfigGenerator generates catalyst.yml configurations from scan results
 type ConfigGenerator struct {
-	Scanner    *ProjectScanner
-	ProjectDir string
+	Scanner      *ProjectScanner
+	ProjectDir   string
+	WindowsShims bool // Add POSIX-on-Windows shims to the Windows configuration
 }
 
 // NewConfigGenerator creates a new config generator
@@ -113,31 +120,42 @@ func (cg *ConfigGenerator) generateConfigForTarget(target BuildTarget) *core.Con
 
 	for _, lib := range externalLibs {
 		// Add platform-specific dependencies
-		for platform, pkg := range lib.Platforms {
+		for _, platform := range slices.Sorted(maps.Keys(lib.Platforms)) {
+			pkg := lib.Platforms[platform]
 			if pkg.PackageName != "" {
 				config.Dependencies[platform] = append(config.Dependencies[platform], pkg.PackageName)
+				config.AddProvenance(core.DependencySource{
+					Package:    pkg.PackageName,
+					Platform:   platform,
+					Header:     lib.HeaderName,
+					Source:     "library",
+					Confidence: 95,
+				})
 			}
 		}
 
-		// Add linker flags
-		if lib.LinkerFlag != "" {
-			flags := strings.Fields(lib.LinkerFlag)
-			config.Flags = append(config.Flags, flags...)
-		}
-
-		// Add platform-specific include/lib paths (for macOS)
-		if runtime.GOOS == "darwin" {
-			if pkg, ok := lib.Platforms["darwin"]; ok {
-				if pkg.IncludePath != "" {
-					config.Flags = append(config.Flags, "-I"+pkg.IncludePath)
-				}
-				if pkg.LibPath != "" {
-					config.Flags = append(config.Flags, "-L"+pkg.LibPath)
-				}
+		// Add each platform's linker flags under platforms:, preferring
+		// pkg-config on this one when it knows the library. Homebrew paths
+		// are written as ${HOMEBREW_PREFIX} so the config works on Macs with
+		// another prefix.
+		hostFlags, ok := pkgConfigFlags(lib.PkgConfig)
+		for _, osName := range slices.Sorted(maps.Keys(config.Dependencies)) {
+			flags := hostFlags
+			if !ok || osName != runtime.GOOS {
+				flags = lib.LinkerFlagsFor(osName)
 			}
+			addPlatformFlags(config, osName, flags)
 		}
 	}
 
+	// Windows SDK headers need no package, only their import libraries
+	cg.addWindowsLibraries(config, target)
+
+	// Add POSIX-on-Windows shims if requested
+	if cg.WindowsShims {
+		cg.addWindowsShims(config, target)
+	}
+
 	// Add math library if needed
 	if !contains(config.Flags, "-lm") {
 		config.Flags = append(config.Flags, "-lm")
@@ -149,6 +167,97 @@ func (cg *ConfigGenerator) generateConfigForTarget(target BuildTarget) *core.Con
 	return config
 }
 
+// addPlatformFlags appends flags to the flags of a platform, skipping
+// those it already has; "-framework Name" is kept together as one flag
+func addPlatformFlags(config *core.Config, osName string, flags []string) {
+	if len(flags) == 0 {
+		return
+	}
+	if config.Platforms == nil {
+		config.Platforms = make(map[string]core.PlatformConfig)
+	}
+	settings := config.Platforms[osName]
+	for i := 0; i < len(flags); i++ {
+		flag := []string{platform.AbstractBrewPrefix(flags[i])}
+		if flags[i] == "-framework" && i+1 < len(flags) {
+			flag = append(flag, flags[i+1])
+			i++
+		}
+		if !containsSequence(settings.Flags, flag) {
+			settings.Flags = append(settings.Flags, flag...)
+		}
+	}
+	config.Platforms[osName] = settings
+}
+
+// containsSequence reports whether items appear in slice one after another
+func containsSequence(slice, items []string) bool {
+	for i := 0; i+len(items) <= len(slice); i++ {
+		if slices.Equal(slice[i:i+len(items)], items) {
+			return true
+		}
+	}
+	return false
+}
+
+// addWindowsLibraries links the import libraries of the Windows SDK headers
+// the target uses in the Windows platform flags (MinGW -l form)
+func (cg *ConfigGenerator) addWindowsLibraries(config *core.Config, target BuildTarget) {
+	uses := windowsLibrariesInFiles(cg.Scanner.IncludeMap, target.SourceFiles)
+	if len(uses) == 0 {
+		return
+	}
+
+	if config.Platforms == nil {
+		config.Platforms = make(map[string]core.PlatformConfig)
+	}
+	windows := config.Platforms["windows"]
+
+	for _, use := range uses {
+		for _, flag := range headers.WindowsLinkFlags(use.Libraries, false) {
+			if !contains(windows.Flags, flag) {
+				windows.Flags = append(windows.Flags, flag)
+			}
+		}
+	}
+
+	config.Platforms["windows"] = windows
+}
+
+// addWindowsShims adds ports and flags for POSIX headers the target uses
+// to the Windows dependency list and platform flags
+func (cg *ConfigGenerator) addWindowsShims(config *core.Config, target BuildTarget) {
+	advice := detectShimsInFiles(cg.Scanner.IncludeMap, target.SourceFiles)
+	if len(advice) == 0 {
+		return
+	}
+
+	if config.Platforms == nil {
+		config.Platforms = make(map[string]core.PlatformConfig)
+	}
+	windows := config.Platforms["windows"]
+
+	for _, a := range advice {
+		if a.Shim.Package != "" && !contains(config.Dependencies["windows"], a.Shim.Package) {
+			config.Dependencies["windows"] = append(config.Dependencies["windows"], a.Shim.Package)
+			config.AddProvenance(core.DependencySource{
+				Package:    a.Shim.Package,
+				Platform:   "windows",
+				Header:     a.Shim.Headers[0],
+				Source:     "shim",
+				Confidence: 100,
+			})
+		}
+		for _, flag := range append(a.Shim.Defines, a.Shim.LinkerFlags...) {
+			if !contains(windows.Flags, flag) {
+				windows.Flags = append(windows.Flags, flag)
+			}
+		}
+	}
+
+	config.Platforms["windows"] = windows
+}
+
 // collectIncludePaths collects include directory paths for a target
 func (cg *ConfigGenerator) collectIncludePaths(target BuildTarget) []string {
 	paths := make(map[string]bool)
@@ -186,11 +295,7 @@ func (cg *ConfigGenerator) collectIncludePaths(target BuildTarget) []string {
 		}
 	}
 
-	result := []string{}
-	for p := range paths {
-		result = append(result, p)
-	}
-	return result
+	return slices.Sorted(maps.Keys(paths))
 }
 
 // isLibraryUsedByTarget checks if a vendored library is used by the target
@@ -212,7 +317,7 @@ func (cg *ConfigGenerator) isLibraryUsedByTarget(target BuildTarget, lib Vendore
 
 // getExternalLibsForTarget gets external libraries used by a target
 func (cg *ConfigGenerator) getExternalLibsForTarget(target BuildTarget) []ExternalLibrary {
-	libMap := make(map[string]ExternalLibrary)
+	used := make(map[string]bool)
 
 	// Collect all includes from target sources
 	for _, srcFile := range target.SourceFiles {
@@ -221,17 +326,20 @@ func (cg *ConfigGenerator) getExternalLibsForTarget(target BuildTarget) []Extern
 				// Check against external libraries
 				for _, extLib := range cg.Scanner.ExternalLibs {
 					if inc == extLib.HeaderName || strings.Contains(inc, extLib.HeaderName) {
-						libMap[extLib.Name] = extLib
+						used[extLib.Name] = true
 					}
 				}
 			}
 		}
 	}
 
-	// Convert map to slice
+	// Keep the scanner's order so the generated config is stable
 	result := []ExternalLibrary{}
-	for _, lib := range libMap {
-		result = append(result, lib)
+	for _, lib := range cg.Scanner.ExternalLibs {
+		if used[lib.Name] {
+			result = append(result, lib)
+			delete(used, lib.Name)
+		}
 	}
 	return result
 }
@@ -253,7 +361,7 @@ func (cg *ConfigGenerator) collectAllIncludes(target BuildTarget) []string {
 	projectIncs := []string{}
 	externalIncs := []string{}
 
-	for inc := range includeMap {
+	for _, inc := range slices.Sorted(maps.Keys(includeMap)) {
 		if isStandardHeader(inc) {
 			standardIncs = append(standardIncs, inc)
 		} else if cg.Scanner.isProjectHeader(inc) {
@@ -294,6 +402,26 @@ func (cg *ConfigGenerator) makeRelativeToTarget(path, targetDir string) string {
 	return rel
 }
 
+// pkgConfigFlags queries pkg-config for the compiler and linker flags of a package.
+// Returns false if the package has no pkg-config name, pkg-config is not
+// installed, or the package is unknown to it.
+func pkgConfigFlags(name string) ([]string, bool) {
+	if name == "" || runtime.GOOS == "windows" {
+		return nil, false
+	}
+	if _, err := exec.LookPath("pkg-config"); err != nil {
+		return nil, false
+	}
+
+	output, err := util.Command("pkg-config", "--cflags", "--libs", name).Output()
+	if err != nil {
+		return nil, false
+	}
+
+	flags := strings.Fields(string(output))
+	return flags, len(flags) > 0
+}
+
 // Helper functions
 
 func contains(slice []string, item string) bool {
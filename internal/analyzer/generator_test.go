@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestGenerateConfigPlatformFlags(t *testing.T) {
+	// Without pkg-config every platform gets the library's own flags
+	t.Setenv("PATH", t.TempDir())
+
+	scanner := &ProjectScanner{IncludeMap: map[string][]string{"main.c": {"GL/glew.h", "GL/gl.h", "curl/curl.h"}}}
+	for _, name := range []string{"OpenGL", "GLEW", "libcurl"} {
+		lib, ok := LookupLibrary(name)
+		if !ok {
+			t.Fatalf("%s is not a known library", name)
+		}
+		scanner.ExternalLibs = append(scanner.ExternalLibs, lib)
+	}
+	cg := NewConfigGenerator(scanner, ".")
+	config := cg.generateConfigForTarget(BuildTarget{Name: "app", SourceFiles: []string{"main.c"}})
+
+	for _, flag := range config.Flags {
+		if flag == "-lGL" || flag == "-lcurl" || flag == "-framework" {
+			t.Errorf("global flags %q have the link flags of %s", config.Flags, runtime.GOOS)
+		}
+	}
+	want := map[string][]string{
+		"darwin":  {"-framework", "OpenGL", "-lGLEW", "-lcurl"},
+		"linux":   {"-lGL", "-lGLEW", "-lcurl"},
+		"windows": {"-lopengl32", "-lgdi32", "-lglew32", "-lcurl"},
+	}
+	for osName, flags := range want {
+		if got := config.Platforms[osName].Flags; !slices.Equal(got, flags) {
+			t.Errorf("platforms.%s.flags = %q, want %q", osName, got, flags)
+		}
+	}
+}
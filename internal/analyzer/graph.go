@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IncludeGraph is the file-level include graph of a project
+type IncludeGraph struct {
+	Files     []string            // Project files, sorted
+	Vendored  map[string][]string // Vendored library name -> its files
+	Externals []string            // External libraries and unknown system headers, sorted
+	Edges     []IncludeEdge
+}
+
+// IncludeEdge is one #include between two graph nodes
+type IncludeEdge struct {
+	From     string
+	To       string // Project file, or external library name
+	External bool
+}
+
+// BuildIncludeGraph turns the include map into a graph of project files,
+// vendored libraries and the external libraries they include. Standard
+// headers are left out. ScanProject must have been called first.
+func (ps *ProjectScanner) BuildIncludeGraph() IncludeGraph {
+	graph := IncludeGraph{Vendored: make(map[string][]string)}
+
+	files := append(append([]string{}, ps.SourceFiles...), ps.HeaderFiles...)
+	sort.Strings(files)
+	for _, file := range files {
+		if lib := ps.vendoredLibraryOf(file); lib != "" {
+			graph.Vendored[lib] = append(graph.Vendored[lib], file)
+		} else {
+			graph.Files = append(graph.Files, file)
+		}
+	}
+
+	externals := make(map[string]bool)
+	seen := make(map[IncludeEdge]bool)
+	for _, file := range files {
+		for _, inc := range ps.IncludeMap[file] {
+			edge := IncludeEdge{From: file}
+			if header := ps.resolveProjectHeader(file, inc); header != "" {
+				if header == file {
+					continue
+				}
+				edge.To = header
+			} else if isStandardHeader(inc) {
+				continue
+			} else {
+				edge.To = ps.externalLibraryName(inc)
+				edge.External = true
+				externals[edge.To] = true
+			}
+
+			if !seen[edge] {
+				seen[edge] = true
+				graph.Edges = append(graph.Edges, edge)
+			}
+		}
+	}
+
+	for name := range externals {
+		graph.Externals = append(graph.Externals, name)
+	}
+	sort.Strings(graph.Externals)
+	return graph
+}
+
+// vendoredLibraryOf returns the vendored library a file belongs to, if any
+func (ps *ProjectScanner) vendoredLibraryOf(file string) string {
+	for _, lib := range ps.VendoredLibs {
+		if strings.HasPrefix(file, lib.Path+string(filepath.Separator)) {
+			return lib.Name
+		}
+	}
+	return ""
+}
+
+// externalLibraryName names the external library a header belongs to, or
+// returns the header itself when no detected library provides it
+func (ps *ProjectScanner) externalLibraryName(include string) string {
+	for _, lib := range ps.ExternalLibs {
+		if include == lib.HeaderName || strings.Contains(include, lib.HeaderName) {
+			return lib.Name
+		}
+	}
+	return include
+}
+
+// DOT renders the graph in Graphviz format, with vendored libraries as
+// clusters and external libraries as boxes
+func (g IncludeGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph includes {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=ellipse, fontname=\"Helvetica\"];\n")
+
+	for _, file := range g.Files {
+		fmt.Fprintf(&sb, "  %q;\n", filepath.ToSlash(file))
+	}
+
+	for i, name := range sortedKeys(g.Vendored) {
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "    label=%q;\n", name+" (vendored)")
+		sb.WriteString("    style=dashed;\n")
+		for _, file := range g.Vendored[name] {
+			fmt.Fprintf(&sb, "    %q;\n", filepath.ToSlash(file))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	for _, name := range g.Externals {
+		fmt.Fprintf(&sb, "  %q [shape=box, style=filled, fillcolor=lightgrey];\n", name)
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  %q -> %q;\n", filepath.ToSlash(edge.From), filepath.ToSlash(edge.To))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g IncludeGraph) Mermaid() string {
+	ids := make(map[string]string)
+	id := func(node string) string {
+		if _, ok := ids[node]; !ok {
+			ids[node] = fmt.Sprintf("n%d", len(ids))
+		}
+		return ids[node]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	for _, file := range g.Files {
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", id(file), filepath.ToSlash(file))
+	}
+
+	for i, name := range sortedKeys(g.Vendored) {
+		fmt.Fprintf(&sb, "  subgraph vendored%d[\"%s (vendored)\"]\n", i, name)
+		for _, file := range g.Vendored[name] {
+			fmt.Fprintf(&sb, "    %s[\"%s\"]\n", id(file), filepath.ToSlash(file))
+		}
+		sb.WriteString("  end\n")
+	}
+
+	for _, name := range g.Externals {
+		fmt.Fprintf(&sb, "  %s[(\"%s\")]\n", id(name), name)
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  %s --> %s\n", id(edge.From), id(edge.To))
+	}
+	return sb.String()
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,5 +1,10 @@
 package analyzer
 
+import (
+	"slices"
+	"strings"
+)
+
 // getKnownLibraries returns a database of known external libraries
 func getKnownLibraries() []ExternalLibrary {
 	return []ExternalLibrary{
@@ -11,13 +16,9 @@ func getKnownLibraries() []ExternalLibrary {
 			Platforms: map[string]PlatformPackage{
 				"darwin": {
 					PackageName: "libmicrohttpd",
-					IncludePath: "/opt/homebrew/opt/libmicrohttpd/include",
-					LibPath:     "/opt/homebrew/opt/libmicrohttpd/lib",
 				},
 				"linux": {
 					PackageName: "libmicrohttpd-dev",
-					IncludePath: "/usr/include",
-					LibPath:     "/usr/lib",
 				},
 				"windows": {
 					PackageName: "libmicrohttpd",
@@ -32,13 +33,9 @@ func getKnownLibraries() []ExternalLibrary {
 			Platforms: map[string]PlatformPackage{
 				"darwin": {
 					PackageName: "curl",
-					IncludePath: "/opt/homebrew/opt/curl/include",
-					LibPath:     "/opt/homebrew/opt/curl/lib",
 				},
 				"linux": {
 					PackageName: "libcurl4-openssl-dev",
-					IncludePath: "/usr/include",
-					LibPath:     "/usr/lib",
 				},
 				"windows": {
 					PackageName: "curl",
@@ -53,13 +50,9 @@ func getKnownLibraries() []ExternalLibrary {
 			Platforms: map[string]PlatformPackage{
 				"darwin": {
 					PackageName: "sqlite",
-					IncludePath: "/opt/homebrew/opt/sqlite/include",
-					LibPath:     "/opt/homebrew/opt/sqlite/lib",
 				},
 				"linux": {
 					PackageName: "libsqlite3-dev",
-					IncludePath: "/usr/include",
-					LibPath:     "/usr/lib",
 				},
 				"windows": {
 					PackageName: "sqlite",
@@ -74,13 +67,9 @@ func getKnownLibraries() []ExternalLibrary {
 			Platforms: map[string]PlatformPackage{
 				"darwin": {
 					PackageName: "openssl",
-					IncludePath: "/opt/homebrew/opt/openssl/include",
-					LibPath:     "/opt/homebrew/opt/openssl/lib",
 				},
 				"linux": {
 					PackageName: "libssl-dev",
-					IncludePath: "/usr/include",
-					LibPath:     "/usr/lib",
 				},
 				"windows": {
 					PackageName: "openssl",
@@ -196,8 +185,162 @@ func getKnownLibraries() []ExternalLibrary {
 				},
 				"windows": {
 					PackageName: "sdl2",
+					LinkerFlag:  "-lmingw32 -lSDL2main -lSDL2",
 				},
 			},
 		},
+		{
+			Name:       "GLFW",
+			HeaderName: "GLFW/glfw3.h",
+			LinkerFlag: "-lglfw",
+			PkgConfig:  "glfw3",
+			Platforms: map[string]PlatformPackage{
+				"darwin": {
+					PackageName: "glfw",
+					LinkerFlag:  "-lglfw -framework Cocoa -framework IOKit -framework CoreVideo",
+				},
+				"linux": {
+					PackageName: "libglfw3-dev",
+					LinkerFlag:  "-lglfw -lGL -lX11 -lpthread -ldl -lm",
+				},
+				"windows": {
+					PackageName: "glfw3",
+					LinkerFlag:  "-lglfw3 -lgdi32 -lopengl32",
+				},
+			},
+		},
+		{
+			// Matches both <GL/gl.h> and macOS <OpenGL/gl.h>
+			Name:       "OpenGL",
+			HeaderName: "GL/gl.h",
+			LinkerFlag: "-lGL",
+			PkgConfig:  "gl",
+			Platforms: map[string]PlatformPackage{
+				"darwin": {
+					PackageName: "", // Provided by the OpenGL framework
+					LinkerFlag:  "-framework OpenGL",
+				},
+				"linux": {
+					PackageName: "libgl1-mesa-dev",
+				},
+				"windows": {
+					PackageName: "", // opengl32 ships with Windows
+					LinkerFlag:  "-lopengl32 -lgdi32",
+				},
+			},
+		},
+		{
+			Name:       "GLEW",
+			HeaderName: "GL/glew.h",
+			LinkerFlag: "-lGLEW",
+			PkgConfig:  "glew",
+			Platforms: map[string]PlatformPackage{
+				"darwin": {
+					PackageName: "glew",
+					LinkerFlag:  "-lGLEW -framework OpenGL",
+				},
+				"linux": {
+					PackageName: "libglew-dev",
+					LinkerFlag:  "-lGLEW -lGL",
+				},
+				"windows": {
+					PackageName: "glew",
+					LinkerFlag:  "-lglew32 -lopengl32",
+				},
+			},
+		},
+		{
+			Name:       "Vulkan",
+			HeaderName: "vulkan/vulkan.h",
+			LinkerFlag: "-lvulkan",
+			PkgConfig:  "vulkan",
+			Platforms: map[string]PlatformPackage{
+				"darwin": {
+					PackageName: "vulkan-loader",
+				},
+				"linux": {
+					PackageName: "libvulkan-dev",
+				},
+				"windows": {
+					PackageName: "vulkan",
+					LinkerFlag:  "-lvulkan-1",
+				},
+			},
+		},
+	}
+}
+
+// LookupLibrary finds a known library by the first component of its header
+// (e.g. "curl" for curl/curl.h, "microhttpd" for microhttpd.h) or by name
+func LookupLibrary(name string) (ExternalLibrary, bool) {
+	name = strings.ToLower(strings.TrimSuffix(name, ".h"))
+	for _, lib := range getKnownLibraries() {
+		header := strings.ToLower(lib.HeaderName)
+		if idx := strings.IndexAny(header, "/."); idx >= 0 {
+			header = header[:idx]
+		}
+		if name == header || name == strings.ToLower(lib.Name) {
+			return lib, true
+		}
+	}
+	return ExternalLibrary{}, false
+}
+
+// HeadersForPackage returns the headers of known libraries and POSIX shims
+// that are provided by a package on any platform
+func HeadersForPackage(pkg string) []string {
+	var headers []string
+	for _, lib := range getKnownLibraries() {
+		for _, platformPkg := range lib.Platforms {
+			if platformPkg.PackageName == pkg {
+				headers = append(headers, lib.HeaderName)
+				break
+			}
+		}
+	}
+	for _, shim := range getPosixShims() {
+		if shim.Package == pkg {
+			headers = append(headers, shim.Headers...)
+		}
+	}
+	return headers
+}
+
+// PkgConfigForPackage returns the pkg-config module of the known library that
+// a package provides on any platform, or "" if none is known
+func PkgConfigForPackage(pkg string) string {
+	pkg = strings.ToLower(pkg)
+	for _, lib := range getKnownLibraries() {
+		if strings.ToLower(lib.Name) == pkg {
+			return lib.PkgConfig
+		}
+		for _, platformPkg := range lib.Platforms {
+			if strings.ToLower(platformPkg.PackageName) == pkg {
+				return lib.PkgConfig
+			}
+		}
+	}
+	return ""
+}
+
+// LookupPkgConfig finds a known library by its pkg-config module
+func LookupPkgConfig(module string) (ExternalLibrary, bool) {
+	for _, lib := range getKnownLibraries() {
+		if lib.PkgConfig != "" && strings.EqualFold(lib.PkgConfig, module) {
+			return lib, true
+		}
+	}
+	return ExternalLibrary{}, false
+}
+
+// LookupLinkerFlag finds a known library linked with flag (e.g. -lcurl, or
+// -lssl for OpenSSL's "-lssl -lcrypto"). Platform overrides aren't
+// searched, as they list system libraries such as -lm alongside.
+func LookupLinkerFlag(flag string) (ExternalLibrary, bool) {
+	for _, lib := range getKnownLibraries() {
+		if slices.Contains(strings.Fields(lib.LinkerFlag), flag) {
+			return lib, true
+		}
 	}
+	return ExternalLibrary{}, false
 }
@@ -0,0 +1,198 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Module represents a group of project files sharing a base name
+// (e.g. utils.c + utils.h) and its relationships to other modules
+type Module struct {
+	Name        string
+	Directory   string
+	SourceFiles []string
+	HeaderFiles []string
+	DependsOn   []string // Modules whose headers this module includes (fan-out)
+	DependedBy  []string // Modules that include this module's headers (fan-in)
+}
+
+// FanIn returns the number of modules depending on this module
+func (m Module) FanIn() int {
+	return len(m.DependedBy)
+}
+
+// FanOut returns the number of modules this module depends on
+func (m Module) FanOut() int {
+	return len(m.DependsOn)
+}
+
+// HasEntryPoint checks if any of the module's sources is a build target entry point
+func (m Module) HasEntryPoint(targets []BuildTarget) bool {
+	for _, target := range targets {
+		for _, src := range m.SourceFiles {
+			if src == target.EntryPoint {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BuildModuleGraph groups project files into modules and computes
+// module-level dependencies from the include map.
+// ScanProject must have been called first.
+func (ps *ProjectScanner) BuildModuleGraph() []Module {
+	modules := make(map[string]*Module)
+	fileToModule := make(map[string]string)
+
+	addFile := func(file string, isHeader bool) {
+		key := moduleKey(file)
+		mod, ok := modules[key]
+		if !ok {
+			mod = &Module{
+				Name:      filepath.ToSlash(key),
+				Directory: filepath.Dir(file),
+			}
+			modules[key] = mod
+		}
+		if isHeader {
+			mod.HeaderFiles = append(mod.HeaderFiles, file)
+		} else {
+			mod.SourceFiles = append(mod.SourceFiles, file)
+		}
+		fileToModule[file] = key
+	}
+
+	for _, src := range ps.SourceFiles {
+		addFile(src, false)
+	}
+	for _, hdr := range ps.HeaderFiles {
+		addFile(hdr, true)
+	}
+
+	// Headers are frequently kept in include/ while sources live in src/,
+	// so merge header-only modules into a source module with the same name
+	mergeSplitModules(modules, fileToModule)
+
+	// Resolve includes to modules
+	edges := make(map[string]map[string]bool)
+	for file, includes := range ps.IncludeMap {
+		fromKey, ok := fileToModule[file]
+		if !ok {
+			continue
+		}
+		for _, inc := range includes {
+			header := ps.resolveProjectHeader(file, inc)
+			if header == "" {
+				continue
+			}
+			toKey, ok := fileToModule[header]
+			if !ok || toKey == fromKey {
+				continue
+			}
+			if edges[fromKey] == nil {
+				edges[fromKey] = make(map[string]bool)
+			}
+			edges[fromKey][toKey] = true
+		}
+	}
+
+	for fromKey, targets := range edges {
+		for toKey := range targets {
+			modules[fromKey].DependsOn = append(modules[fromKey].DependsOn, modules[toKey].Name)
+			modules[toKey].DependedBy = append(modules[toKey].DependedBy, modules[fromKey].Name)
+		}
+	}
+
+	result := make([]Module, 0, len(modules))
+	for _, mod := range modules {
+		sort.Strings(mod.DependsOn)
+		sort.Strings(mod.DependedBy)
+		result = append(result, *mod)
+	}
+
+	// Most depended-upon modules first, then by name for stable output
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FanIn() != result[j].FanIn() {
+			return result[i].FanIn() > result[j].FanIn()
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// SuggestLibraryModules returns modules that are good candidates for being
+// split out into a separately built library: they are used by at least
+// minFanIn other modules and do not contain a main() entry point
+func (ps *ProjectScanner) SuggestLibraryModules(modules []Module, minFanIn int) []Module {
+	var candidates []Module
+	for _, mod := range modules {
+		if len(mod.SourceFiles) == 0 {
+			continue // Header-only modules have nothing to compile
+		}
+		if mod.FanIn() < minFanIn {
+			continue
+		}
+		if mod.HasEntryPoint(ps.BuildTargets) {
+			continue
+		}
+		candidates = append(candidates, mod)
+	}
+	return candidates
+}
+
+// resolveProjectHeader maps an include directive to a project header file path.
+// Returns an empty string if the include does not refer to a project header.
+func (ps *ProjectScanner) resolveProjectHeader(fromFile, include string) string {
+	// Try relative to the including file first
+	candidate := filepath.Clean(filepath.Join(filepath.Dir(fromFile), include))
+	for _, header := range ps.HeaderFiles {
+		if header == candidate || header == include {
+			return header
+		}
+	}
+
+	// Fall back to matching by base name, as isProjectHeader does
+	for _, header := range ps.HeaderFiles {
+		if filepath.Base(header) == filepath.Base(include) {
+			return header
+		}
+	}
+
+	return ""
+}
+
+// moduleKey returns the key identifying the module a file belongs to
+func moduleKey(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file))
+}
+
+// mergeSplitModules folds header-only modules into the source module
+// with the same base name when exactly one such source module exists
+func mergeSplitModules(modules map[string]*Module, fileToModule map[string]string) {
+	byName := make(map[string][]string)
+	for key, mod := range modules {
+		if len(mod.SourceFiles) > 0 {
+			name := filepath.Base(key)
+			byName[name] = append(byName[name], key)
+		}
+	}
+
+	for key, mod := range modules {
+		if len(mod.SourceFiles) > 0 {
+			continue
+		}
+		sourceKeys := byName[filepath.Base(key)]
+		if len(sourceKeys) != 1 {
+			continue
+		}
+		target := modules[sourceKeys[0]]
+		target.HeaderFiles = append(target.HeaderFiles, mod.HeaderFiles...)
+		for _, hdr := range mod.HeaderFiles {
+			fileToModule[hdr] = sourceKeys[0]
+		}
+		delete(modules, key)
+	}
+}
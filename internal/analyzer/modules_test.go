@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeProjectFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", rel, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", rel, err)
+	}
+}
+
+func TestBuildModuleGraph(t *testing.T) {
+	root := t.TempDir()
+	writeProjectFile(t, root, "src/main.c", "#include \"util.h\"\n#include \"log.h\"\nint main() { return 0; }\n")
+	writeProjectFile(t, root, "src/util.c", "#include \"log.h\"\nvoid util(void) {}\n")
+	writeProjectFile(t, root, "src/log.c", "void log_msg(void) {}\n")
+	writeProjectFile(t, root, "include/util.h", "void util(void);\n")
+	writeProjectFile(t, root, "include/log.h", "void log_msg(void);\n")
+
+	scanner := NewProjectScanner(root)
+	if err := scanner.ScanProject(); err != nil {
+		t.Fatalf("Failed to scan project: %v", err)
+	}
+
+	modules := scanner.BuildModuleGraph()
+	if len(modules) != 3 {
+		t.Fatalf("Expected 3 modules (headers merged into sources), got %d: %+v", len(modules), modules)
+	}
+
+	byName := make(map[string]Module)
+	for _, mod := range modules {
+		byName[mod.Name] = mod
+	}
+
+	log := byName["src/log"]
+	if log.FanIn() != 2 || log.FanOut() != 0 {
+		t.Errorf("src/log: expected fan-in 2, fan-out 0, got %d, %d", log.FanIn(), log.FanOut())
+	}
+	if len(log.HeaderFiles) != 1 {
+		t.Errorf("src/log: expected include/log.h to be merged, got headers %v", log.HeaderFiles)
+	}
+
+	main := byName["src/main"]
+	if main.FanIn() != 0 || main.FanOut() != 2 {
+		t.Errorf("src/main: expected fan-in 0, fan-out 2, got %d, %d", main.FanIn(), main.FanOut())
+	}
+
+	candidates := scanner.SuggestLibraryModules(modules, 2)
+	if len(candidates) != 1 || candidates[0].Name != "src/log" {
+		t.Errorf("Expected src/log as the only library candidate, got %+v", candidates)
+	}
+}
+
+func TestBuildIncludeGraph(t *testing.T) {
+	root := t.TempDir()
+	writeProjectFile(t, root, "src/main.c", "#include \"util.h\"\n#include <stdio.h>\n#include <curl/curl.h>\nint main() { return 0; }\n")
+	writeProjectFile(t, root, "src/util.c", "#include \"util.h\"\n#include \"cJSON.h\"\n")
+	writeProjectFile(t, root, "include/util.h", "void util(void);\n")
+	writeProjectFile(t, root, "vendor/cjson/cJSON.c", "#include \"cJSON.h\"\n")
+	writeProjectFile(t, root, "vendor/cjson/cJSON.h", "")
+
+	scanner := NewProjectScanner(root)
+	if err := scanner.ScanProject(); err != nil {
+		t.Fatalf("Failed to scan project: %v", err)
+	}
+	graph := scanner.BuildIncludeGraph()
+
+	if len(graph.Vendored["cjson"]) != 2 {
+		t.Errorf("Expected the cjson files in a vendored group, got %v", graph.Vendored)
+	}
+	if len(graph.Externals) != 1 || graph.Externals[0] != "libcurl" {
+		t.Errorf("Expected libcurl as the only external node, got %v", graph.Externals)
+	}
+
+	want := map[IncludeEdge]bool{
+		{From: filepath.Join("src", "main.c"), To: filepath.Join("include", "util.h")}:                       true,
+		{From: filepath.Join("src", "main.c"), To: "libcurl", External: true}:                                true,
+		{From: filepath.Join("src", "util.c"), To: filepath.Join("include", "util.h")}:                       true,
+		{From: filepath.Join("src", "util.c"), To: filepath.Join("vendor", "cjson", "cJSON.h")}:              true,
+		{From: filepath.Join("vendor", "cjson", "cJSON.c"), To: filepath.Join("vendor", "cjson", "cJSON.h")}: true,
+	}
+	if len(graph.Edges) != len(want) {
+		t.Fatalf("Expected %d edges (stdio.h left out), got %v", len(want), graph.Edges)
+	}
+	for _, edge := range graph.Edges {
+		if !want[edge] {
+			t.Errorf("Unexpected edge %+v", edge)
+		}
+	}
+}
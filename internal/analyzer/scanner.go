@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/headers"
 )
 
 // ProjectScanner scans and analyzes a C/C++ project
@@ -43,8 +45,15 @@ type ExternalLibrary struct {
 // PlatformPackage contains platform-specific package info
 type PlatformPackage struct {
 	PackageName string
-	IncludePath string
-	LibPath     string
+	LinkerFlag  string // Overrides ExternalLibrary.LinkerFlag on this platform
+}
+
+// LinkerFlagsFor returns the linker flags for the library on the given platform
+func (lib ExternalLibrary) LinkerFlagsFor(osName string) []string {
+	if pkg, ok := lib.Platforms[osName]; ok && pkg.LinkerFlag != "" {
+		return strings.Fields(pkg.LinkerFlag)
+	}
+	return strings.Fields(lib.LinkerFlag)
 }
 
 // VendoredLibrary represents a vendored/bundled library
@@ -115,8 +124,8 @@ func (ps *ProjectScanner) scanFiles() error {
 		ext := filepath.Ext(path)
 		relPath, _ := filepath.Rel(ps.RootPath, path)
 
-		// Collect source files
-		if ext == ".c" || ext == ".cpp" || ext == ".cc" || ext == ".cxx" {
+		// Collect source files (flex/bison grammars are compiled via build/gen)
+		if ext == ".c" || ext == ".cpp" || ext == ".cc" || ext == ".cxx" || ext == ".l" || ext == ".y" {
 			ps.SourceFiles = append(ps.SourceFiles, relPath)
 		}
 
@@ -161,10 +170,39 @@ func (ps *ProjectScanner) parseIncludes() error {
 	return nil
 }
 
+// mainRegex matches the definition of main()
+var mainRegex = regexp.MustCompile(`\bint\s+main\s*\(`)
+
+// SourceFile is a C/C++ source found by ScanSources
+type SourceFile struct {
+	Path       string // Relative to the scanned directory
+	EntryPoint bool   // Defines main()
+}
+
+// ScanSources returns the C and C++ sources below root, entry points first
+func ScanSources(root string) ([]SourceFile, error) {
+	ps := NewProjectScanner(root)
+	if err := ps.scanFiles(); err != nil {
+		return nil, err
+	}
+
+	var entryPoints, others []SourceFile
+	for _, path := range ps.SourceFiles {
+		if ext := filepath.Ext(path); ext == ".l" || ext == ".y" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(root, path))
+		if err == nil && mainRegex.Match(content) {
+			entryPoints = append(entryPoints, SourceFile{Path: path, EntryPoint: true})
+		} else {
+			others = append(others, SourceFile{Path: path})
+		}
+	}
+	return append(entryPoints, others...), nil
+}
+
 // detectBuildTargets finds files with main() functions
 func (ps *ProjectScanner) detectBuildTargets() error {
-	mainRegex := regexp.MustCompile(`\bint\s+main\s*\(`)
-
 	for _, sourceFile := range ps.SourceFiles {
 		fullPath := filepath.Join(ps.RootPath, sourceFile)
 		content, err := os.ReadFile(fullPath)
@@ -309,7 +347,8 @@ func (ps *ProjectScanner) detectSelfContainedLibraries() {
 		dirFiles[dir] = append(dirFiles[dir], src)
 	}
 
-	for dir, files := range dirFiles {
+	for _, dir := range sortedKeys(dirFiles) {
+		files := dirFiles[dir]
 		if dir == "." || dir == "src" || strings.HasPrefix(dir, "src/") {
 			continue // Skip main source directories
 		}
@@ -364,18 +403,24 @@ func (ps *ProjectScanner) looksLikeVendoredLib(dirName string, files []string) b
 
 // detectExternalLibraries detects system library dependencies
 func (ps *ProjectScanner) detectExternalLibraries() error {
-	// Collect all includes
-	allIncludes := make(map[string]bool)
-	for _, includes := range ps.IncludeMap {
-		for _, inc := range includes {
-			allIncludes[inc] = true
+	// Collect all includes, in the order the files were scanned so the
+	// libraries come out the same way every run
+	var allIncludes []string
+	seen := make(map[string]bool)
+	for _, file := range append(ps.SourceFiles, ps.HeaderFiles...) {
+		for _, inc := range ps.IncludeMap[file] {
+			if !seen[inc] {
+				seen[inc] = true
+				allIncludes = append(allIncludes, inc)
+			}
 		}
 	}
 
 	// Check against known external libraries
 	knownLibs := getKnownLibraries()
+	found := make(map[string]bool)
 
-	for include := range allIncludes {
+	for _, include := range allIncludes {
 		// Skip standard library headers
 		if isStandardHeader(include) {
 			continue
@@ -389,7 +434,10 @@ func (ps *ProjectScanner) detectExternalLibraries() error {
 		// Check if it matches a known external library
 		for _, lib := range knownLibs {
 			if include == lib.HeaderName || strings.Contains(include, lib.HeaderName) {
-				ps.ExternalLibs = append(ps.ExternalLibs, lib)
+				if !found[lib.Name] {
+					found[lib.Name] = true
+					ps.ExternalLibs = append(ps.ExternalLibs, lib)
+				}
 				break
 			}
 		}
@@ -409,25 +457,9 @@ func (ps *ProjectScanner) isProjectHeader(include string) bool {
 	return false
 }
 
-// isStandardHeader checks if a header is a standard C/C++ library header
+// isStandardHeader checks if a header ships with the compiler, C/C++ standard library or OS SDK
 func isStandardHeader(header string) bool {
-	standardHeaders := []string{
-		"stdio.h", "stdlib.h", "string.h", "math.h", "time.h",
-		"ctype.h", "errno.h", "assert.h", "stddef.h", "stdint.h",
-		"stdbool.h", "limits.h", "float.h", "signal.h", "setjmp.h",
-		"stdarg.h", "locale.h", "wchar.h", "wctype.h", "unistd.h",
-		"pthread.h", "sys/types.h", "sys/stat.h", "fcntl.h",
-		// C++ headers
-		"iostream", "vector", "string", "map", "algorithm",
-		"memory", "functional", "thread", "mutex", "atomic",
-	}
-
-	for _, std := range standardHeaders {
-		if header == std {
-			return true
-		}
-	}
-	return false
+	return headers.IsStandard(header)
 }
 
 // GetSummary returns a summary of the scan results
@@ -0,0 +1,111 @@
+package analyzer
+
+import (
+	"sort"
+)
+
+// PosixShim describes how to make a POSIX header usable on Windows
+type PosixShim struct {
+	Name        string
+	Headers     []string // POSIX headers that trigger this shim
+	Package     string   // vcpkg package providing a port (empty if none)
+	LinkerFlags []string
+	Defines     []string
+	Advice      string
+}
+
+// ShimAdvice is a shim needed by the project together with the files that need it
+type ShimAdvice struct {
+	Shim  PosixShim
+	Files []string
+}
+
+// getPosixShims returns the database of known POSIX-on-Windows shims
+func getPosixShims() []PosixShim {
+	return []PosixShim{
+		{
+			Name:        "pthreads-win32",
+			Headers:     []string{"pthread.h"},
+			Package:     "pthreads",
+			LinkerFlags: []string{"-lpthread"},
+			Advice:      "Windows has no native pthreads; use pthreads-win32 (or MinGW-w64 winpthreads)",
+		},
+		{
+			Name:        "winsock",
+			Headers:     []string{"sys/socket.h", "netinet/in.h", "arpa/inet.h", "netdb.h"},
+			LinkerFlags: []string{"-lws2_32"},
+			Defines:     []string{"-D_WIN32_WINNT=0x0601"},
+			Advice:      "Sockets come from Winsock on Windows: include <winsock2.h>/<ws2tcpip.h> under #ifdef _WIN32 and call WSAStartup() first",
+		},
+		{
+			Name:    "getopt",
+			Headers: []string{"getopt.h"},
+			Package: "getopt",
+			Advice:  "Windows has no getopt(); use the getopt port",
+		},
+		{
+			Name:    "dirent",
+			Headers: []string{"dirent.h"},
+			Package: "dirent",
+			Advice:  "MSVC has no dirent.h; use the dirent port (MinGW ships its own)",
+		},
+		{
+			Name:    "mman",
+			Headers: []string{"sys/mman.h"},
+			Package: "mman",
+			Advice:  "Windows has no mmap(); use the mman port or CreateFileMapping()",
+		},
+		{
+			Name:    "unistd",
+			Headers: []string{"unistd.h"},
+			Defines: []string{"-D_CRT_NONSTDC_NO_WARNINGS"},
+			Advice:  "MinGW ships a partial unistd.h and MSVC none; guard POSIX-only calls with #ifdef _WIN32 and use <io.h>, <direct.h> or <process.h>",
+		},
+	}
+}
+
+// DetectPosixShims finds POSIX headers used by the project that need a shim
+// or port to build on Windows. ScanProject must have been called first.
+func (ps *ProjectScanner) DetectPosixShims() []ShimAdvice {
+	return detectShimsInFiles(ps.IncludeMap, nil)
+}
+
+// detectShimsInFiles finds shims needed by the given files.
+// If files is nil, all files in the include map are considered.
+func detectShimsInFiles(includeMap map[string][]string, files []string) []ShimAdvice {
+	headerToShim := make(map[string]int)
+	shims := getPosixShims()
+	for i, shim := range shims {
+		for _, header := range shim.Headers {
+			headerToShim[header] = i
+		}
+	}
+
+	if files == nil {
+		for file := range includeMap {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	usedBy := make(map[int][]string)
+	for _, file := range files {
+		seen := make(map[int]bool)
+		for _, inc := range includeMap[file] {
+			idx, ok := headerToShim[inc]
+			if !ok || seen[idx] {
+				continue
+			}
+			seen[idx] = true
+			usedBy[idx] = append(usedBy[idx], file)
+		}
+	}
+
+	var advice []ShimAdvice
+	for i, shim := range shims {
+		if files, ok := usedBy[i]; ok {
+			advice = append(advice, ShimAdvice{Shim: shim, Files: files})
+		}
+	}
+	return advice
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"sort"
+
+	"github.com/Sabique-Islam/catalyst/internal/headers"
+)
+
+// WindowsLibraryUse is a Windows SDK header the project includes, the import
+// libraries it needs at link time and the files that include it
+type WindowsLibraryUse struct {
+	Header    string
+	Libraries []string
+	Files     []string
+}
+
+// DetectWindowsLibraries finds Windows SDK headers that need import libraries
+// (winsock2.h needs ws2_32). ScanProject must have been called first.
+func (ps *ProjectScanner) DetectWindowsLibraries() []WindowsLibraryUse {
+	return windowsLibrariesInFiles(ps.IncludeMap, nil)
+}
+
+// windowsLibrariesInFiles finds Windows SDK library uses in the given files.
+// If files is nil, all files in the include map are considered.
+func windowsLibrariesInFiles(includeMap map[string][]string, files []string) []WindowsLibraryUse {
+	if files == nil {
+		for file := range includeMap {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	uses := make(map[string]*WindowsLibraryUse)
+	for _, file := range files {
+		for _, inc := range includeMap[file] {
+			libs := headers.WindowsLibraries(inc)
+			if len(libs) == 0 {
+				continue
+			}
+			use, ok := uses[inc]
+			if !ok {
+				use = &WindowsLibraryUse{Header: inc, Libraries: libs}
+				uses[inc] = use
+			}
+			if !contains(use.Files, file) {
+				use.Files = append(use.Files, file)
+			}
+		}
+	}
+
+	var result []WindowsLibraryUse
+	for _, use := range uses {
+		result = append(result, *use)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Header < result[j].Header })
+	return result
+}
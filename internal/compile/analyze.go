@@ -0,0 +1,248 @@
+package compile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Tools catalyst lint checks the sources with
+const (
+	LintCompiler  = "compiler" // The build's compiler with extra warning flags
+	LintClangTidy = "clang-tidy"
+	LintCppcheck  = "cppcheck"
+)
+
+// LintTools lists the tools catalyst lint accepts
+var LintTools = []string{LintCompiler, LintClangTidy, LintCppcheck}
+
+// BaselineFor returns the default baseline of a lint tool. The tools find
+// different things, so each keeps its own.
+func BaselineFor(tool string) string {
+	if tool == "" || tool == LintCompiler {
+		return DefaultBaseline
+	}
+	return strings.TrimSuffix(DefaultBaseline, ".yml") + "." + tool + ".yml"
+}
+
+// compileCommand is an entry of a compile_commands.json compilation database
+type compileCommand struct {
+	Directory string   `json:"directory"`
+	File      string   `json:"file"`
+	Arguments []string `json:"arguments"`
+	Output    string   `json:"output,omitempty"`
+}
+
+// WriteCompileCommands writes the compilation database clang-tidy and
+// cppcheck read to build/lint/compile_commands.json: the command the build
+// compiles each source with. Editors read build/compile_commands.json, which
+// catalyst flags --compile-commands writes.
+func WriteCompileCommands(compiler Compiler, sources []string, flags []string, opts CompileOptions) (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	outDir := util.BuildDir("lint")
+	var commands []compileCommand
+	for _, src := range sources {
+		object := filepath.Join(outDir, objectName(src, compiler.ObjectExt()))
+		args := append([]string{compiler.Command()}, compiler.CompileObject(src, object, sourceCompileFlags(src, flags, opts))...)
+		commands = append(commands, compileCommand{Directory: dir, File: src, Arguments: args, Output: object})
+	}
+	return writeCompileCommands(filepath.Join(outDir, "compile_commands.json"), commands)
+}
+
+// writeCompileCommands writes a compilation database to path and returns it
+func writeCompileCommands(path string, commands []compileCommand) (string, error) {
+	data, err := json.MarshalIndent(commands, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal compile_commands.json: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create build directory: %w", err)
+	}
+	if err := util.WriteFileAtomic(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write compile_commands.json: %w", err)
+	}
+	return path, nil
+}
+
+// findLintTool returns the path of clang-tidy or cppcheck
+func findLintTool(tool string) (string, error) {
+	path, err := lookPath(tool)
+	if err != nil {
+		return "", fmt.Errorf("%s not found in PATH; add it to dev_dependencies: in catalyst.yml and run catalyst install", tool)
+	}
+	return path, nil
+}
+
+// analyzeSources runs clang-tidy or cppcheck (at tool) over the sources
+// described by the compilation database and returns their findings
+func analyzeSources(tool string, lint LintOptions, compiler Compiler, sources []string, flags []string, opts CompileOptions) ([]Diagnostic, error) {
+	database, err := WriteCompileCommands(compiler, sources, flags, opts)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Wrote %s\n", database)
+
+	var findings []Diagnostic
+	switch lint.Tool {
+	case LintClangTidy:
+		log.Infof("Checking %d source files with clang-tidy\n", len(sources))
+		args := []string{"-p", filepath.Dir(database), "--quiet"}
+		if lint.Checks != "" {
+			args = append(args, "--checks="+lint.Checks)
+		}
+		// clang-tidy checks one file at a time however many it's given, so
+		// the sources are shared out like a build's
+		outputs, errs := forEachSource(sources, opts.Jobs, func(src string) ([]byte, error) {
+			return util.Command(tool, slices.Concat(args, []string{src})...).CombinedOutput()
+		})
+		for i, src := range sources {
+			found := ParseDiagnostics(string(outputs[i]))
+			// A non-zero exit with findings is clang-tidy reporting them
+			if errs[i] != nil && len(found) == 0 {
+				reportCompilerOutput(outputs[i])
+				return nil, fmt.Errorf("clang-tidy failed on %s: %w", src, errs[i])
+			}
+			findings = append(findings, found...)
+		}
+	case LintCppcheck:
+		log.Infof("Checking %d source files with cppcheck\n", len(sources))
+		checks := lint.Checks
+		if checks == "" {
+			checks = "warning,style,performance,portability"
+		}
+		args := []string{
+			"--project=" + database,
+			"--enable=" + checks,
+			"--template={file}:{line}:{column}: {severity}: {message} [{id}]",
+			"--inline-suppr",
+			"--quiet",
+		}
+		if opts.Jobs > 1 {
+			args = append(args, "-j", strconv.Itoa(opts.Jobs))
+		}
+		out, err := util.Command(tool, args...).CombinedOutput()
+		findings = parseCppcheck(string(out))
+		if err != nil && len(findings) == 0 {
+			reportCompilerOutput(out)
+			return nil, fmt.Errorf("cppcheck failed: %w", err)
+		}
+	}
+
+	// The tools name files by their absolute paths; the baseline is
+	// committed, so it records them relative to the project
+	root, _ := os.Getwd()
+	var relevant []Diagnostic
+	seen := make(map[Diagnostic]bool)
+	for _, d := range findings {
+		if d.Severity == "note" {
+			continue
+		}
+		if rel, err := filepath.Rel(root, d.File); err == nil && filepath.IsAbs(d.File) && !strings.HasPrefix(rel, "..") {
+			d.File = rel
+		}
+		if !seen[d] {
+			seen[d] = true
+			relevant = append(relevant, d)
+		}
+	}
+	return relevant, nil
+}
+
+// forEachSource runs fn for each source with a pool of jobs workers and
+// returns the outputs and errors in the order of sources
+func forEachSource(sources []string, jobs int, fn func(src string) ([]byte, error)) ([][]byte, []error) {
+	outputs := make([][]byte, len(sources))
+	errs := make([]error, len(sources))
+	var wg sync.WaitGroup
+	work := make(chan int)
+	for w := 0; w < max(jobs, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				outputs[i], errs[i] = fn(sources[i])
+			}
+		}()
+	}
+	for i := range sources {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return outputs, errs
+}
+
+// cppcheckFinding is a line of cppcheck output in the template
+// analyzeSources asks for: "main.c:5:10: style: message [id]"
+var cppcheckFinding = regexp.MustCompile(`^(.+?):(\d+):(\d+): (error|warning|style|performance|portability|information): (.*)$`)
+
+// parseCppcheck returns the findings in cppcheck output. Its style,
+// performance and portability findings are warnings; information about
+// cppcheck itself (e.g. a header it couldn't find) is a note.
+func parseCppcheck(output string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		m := cppcheckFinding.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		severity := "warning"
+		switch m[4] {
+		case "error":
+			severity = "error"
+		case "information":
+			severity = "note"
+		}
+		diags = append(diags, newDiagnostic(m[1], m[2], m[3], severity, "", m[5]))
+	}
+	return diags
+}
+
+// findingCheck is the check that reported a finding: "[bugprone-...]" at the
+// end of a clang-tidy or cppcheck message, or a compiler's "[-Wunused]"
+var findingCheck = regexp.MustCompile(`\[([\w.,=+-]+)\]$`)
+
+// summarizeFindings prints how many findings each check reported, most
+// first
+func summarizeFindings(findings []Diagnostic) {
+	if len(findings) == 0 {
+		return
+	}
+	counts := make(map[string]int)
+	for _, d := range findings {
+		check := "other"
+		if d.Code != "" {
+			check = d.Code
+		} else if m := findingCheck.FindStringSubmatch(d.Message); m != nil {
+			check = m[1]
+		}
+		counts[check]++
+	}
+	checks := make([]string, 0, len(counts))
+	for check := range counts {
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		if counts[checks[i]] != counts[checks[j]] {
+			return counts[checks[i]] > counts[checks[j]]
+		}
+		return checks[i] < checks[j]
+	})
+	log.Info("Findings by check:")
+	for _, check := range checks {
+		log.Infof("  %4d  %s\n", counts[check], check)
+	}
+}
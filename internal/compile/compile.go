@@ -6,13 +6,65 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
+	"time"
 
 	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/events"
+	"github.com/Sabique-Islam/catalyst/internal/guard"
 	install "github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 )
 
+// CompileOptions controls how compiler and generator commands are executed
+type CompileOptions struct {
+	Launcher string // Command prefixed to compiler invocations (e.g. "distcc")
+	Sandbox  bool   // Restrict writes to the build directory (bwrap/firejail/sandbox-exec)
+	Jobs     int    // Number of sources compiled concurrently; 0 or 1 compiles in a single invocation
+	Target   string // Target triple from the targets: section of catalyst.yml; empty builds for the host
+	Compiler string // Compiler used instead of the host default (set from the target's toolchain)
+	Profile  string // Build profile whose flags are added; its output goes to build/<profile>/
+
+	FileFlags []config.FileFlags // Compile flags added to some sources (file_flags: in catalyst.yml)
+
+	Features          []string // Features from the features: section of catalyst.yml to enable
+	NoDefaultFeatures bool     // Don't enable the default_features of catalyst.yml
+
+	// Lock takes the project lock for each build of RunProject, RunExample
+	// and Watch, which release it while the program runs or changes are
+	// awaited
+	Lock func() (release func(), err error)
+}
+
+// withBuildLock runs a build step while holding opts.Lock, if set
+func withBuildLock(opts CompileOptions, build func() error) error {
+	if opts.Lock != nil {
+		release, err := opts.Lock()
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+	return build()
+}
+
+// OutputDir returns the directory a build with opts writes its objects and
+// output to: build/, or build/<profile>/ and build/[<profile>/]<target>/ so
+// switching profiles or targets never clobbers another build
+func OutputDir(opts CompileOptions) string {
+	return util.BuildDir(opts.Profile, opts.Target)
+}
+
 // CompileC compiles a C/C++ source file or project into a binary
 func CompileC(sourceFiles []string, output string, flags []string) error {
+	return CompileCWithOptions(sourceFiles, output, flags, CompileOptions{})
+}
+
+// CompileCWithOptions compiles like CompileC, optionally prefixing the compiler
+// invocation with a launcher and running it inside a filesystem sandbox
+func CompileCWithOptions(sourceFiles []string, output string, flags []string, opts CompileOptions) error {
 	if len(sourceFiles) == 0 {
 		return fmt.Errorf("no source files provided for compilation")
 	}
@@ -23,68 +75,208 @@ func CompileC(sourceFiles []string, output string, flags []string) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Determine compiler
-	compiler := "gcc" // default for C
-	if runtime.GOOS == "darwin" {
-		// On macOS, prefer clang over gcc
-		if _, err := exec.LookPath("clang"); err == nil {
-			compiler = "clang"
-		} else if _, err := exec.LookPath("gcc"); err != nil {
-			return fmt.Errorf("no C compiler found (clang or gcc required)")
-		}
-	} else if runtime.GOOS == "windows" {
-		if _, err := exec.LookPath("gcc"); err != nil {
-			return fmt.Errorf("gcc not found in PATH")
-		}
-	} else {
-		if _, err := exec.LookPath("gcc"); err != nil {
-			return fmt.Errorf("gcc not found, install it using your package manager")
-		}
+	compiler, err := findCompiler(opts)
+	if err != nil {
+		return err
 	}
 
-	// Build command arguments
-	args := append([]string{"-o", output}, sourceFiles...)
-	args = append(args, flags...)
+	// Compile independent sources concurrently, then link the objects.
+	// Sources with flags of their own need an invocation of their own.
+	if (opts.Jobs > 1 && len(sourceFiles) > 1) || hasFileFlags(sourceFiles, opts) {
+		return compileParallel(compiler, sourceFiles, output, flags, opts)
+	}
 
-	cmd := exec.Command(compiler, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Build command arguments
+	args := compiler.Link(sourceFiles, output, flags)
 
-	fmt.Printf("Compiling with: %s %s\n", compiler, args)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("compilation failed: %w", err)
+	command, args, err := wrapCompilerCommand(compiler.Command(), args, outDir, opts)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Compilation successful: %s\n", output)
+	log.Debugf("Compiling with: %s %s\n", command, args)
+	start := time.Now()
+	if err := runCompiler(command, args); err != nil {
+		err = fmt.Errorf("compilation failed: %w", err)
+		linkFinished(output, start, err)
+		return err
+	}
+	linkFinished(output, start, nil)
 	return nil
 }
 
+// linkFinished reports that output was linked, or failed to, since start
+func linkFinished(output string, start time.Time, err error) {
+	events.Emit(events.Event{Kind: events.LinkFinished, Output: output, Duration: time.Since(start), Error: events.Err(err)})
+}
+
+// CompilerPriority overrides the order compilers are looked for in, by GOOS.
+// It is set from compiler_priority in ~/.catalyst.yaml.
+var CompilerPriority map[string][]string
+
+// defaultCompilerPriority is the order compilers are looked for in on each
+// platform. MSYS2's CLANGARM64 environment (Windows on ARM) only ships clang,
+// and a Visual Studio developer prompt has cl or clang-cl instead.
+var defaultCompilerPriority = map[string][]string{
+	"darwin":  {"clang", "gcc"},
+	"windows": {"gcc", "clang", "cl", "clang-cl"},
+}
+
+// CompilerOrder returns the compilers looked for on goos, most preferred first
+func CompilerOrder(goos string) []string {
+	if order := CompilerPriority[goos]; len(order) > 0 {
+		return order
+	}
+	if order, ok := defaultCompilerPriority[goos]; ok {
+		return order
+	}
+	return []string{"gcc", "clang"}
+}
+
+// findCompiler returns the C compiler to use on this platform, or the
+// cross compiler set in opts
+func findCompiler(opts CompileOptions) (Compiler, error) {
+	command, err := findCompilerCommand(opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompiler(command), nil
+}
+
+// HostCompiler returns the compiler builds for the host use
+func HostCompiler() (Compiler, error) {
+	return findCompiler(CompileOptions{})
+}
+
+// findCompilerCommand returns the executable findCompiler drives
+func findCompilerCommand(opts CompileOptions) (string, error) {
+	if opts.Compiler != "" {
+		if _, err := lookPath(opts.Compiler); err != nil {
+			return "", fmt.Errorf("compiler %s not found in PATH (install the cross toolchain or set compiler: for the target)", opts.Compiler)
+		}
+		return opts.Compiler, nil
+	}
+
+	order := CompilerOrder(runtime.GOOS)
+	for _, name := range order {
+		if _, err := lookPath(name); err == nil {
+			return name, nil
+		}
+	}
+
+	installed, err := install.OfferCompiler()
+	if err != nil {
+		return "", err
+	}
+	if installed {
+		// PATH changed, so the compilers are looked up again
+		forgetDetection()
+		for _, name := range order {
+			if _, err := lookPath(name); err == nil {
+				return name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no C compiler found (tried %s); install one with your package manager", strings.Join(order, ", "))
+}
+
+// wrapCompilerCommand prefixes a compiler invocation with the launcher, if any,
+// and runs it in a sandbox that can only write to outDir when requested
+func wrapCompilerCommand(compiler string, args []string, outDir string, opts CompileOptions) (string, []string, error) {
+	command := compiler
+	if launcherArgs := strings.Fields(opts.Launcher); len(launcherArgs) > 0 {
+		if _, err := exec.LookPath(launcherArgs[0]); err != nil {
+			return "", nil, fmt.Errorf("compiler launcher %s not found in PATH", launcherArgs[0])
+		}
+		command = launcherArgs[0]
+		args = append(append(launcherArgs[1:], compiler), args...)
+	}
+
+	if opts.Sandbox {
+		return sandboxCommand(command, args, []string{outDir})
+	}
+	return command, args, nil
+}
+
 // BuildProject handles the complete build process including dependency installation and compilation
 func BuildProject(args []string) error {
+	return BuildProjectWithOptions(args, CompileOptions{})
+}
+
+// BuildProjectWithOptions builds the project like BuildProject. Options set in
+// catalyst.yml are combined with the given options, which take precedence.
+func BuildProjectWithOptions(args []string, opts CompileOptions) error {
+	_, err := Build(args, opts)
+	return err
+}
+
+// Build builds the project in the current directory like
+// BuildProjectWithOptions and returns the metadata of what it built
+func Build(args []string, opts CompileOptions) (*BuildMetadata, error) {
+	leave, err := enterBuild()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	resetDiagnostics()
+	defer summarizeDiagnostics()
+
 	var sourceFiles []string
 	var flags []string
 	var output string
+	var meta BuildMetadata
+	projectType := config.TypeExecutable
+
+	// Cross builds use the target's platform section of catalyst.yml
+	osKey := runtime.GOOS
+	if opts.Target != "" {
+		osKey = targetOS(opts.Target)
+	}
 
 	// Check if catalyst.yml exists
-	if _, err := os.Stat("catalyst.yml"); err == nil {
+	if _, err := os.Stat(config.ProjectFile); err == nil {
 		// Load configuration from catalyst.yml
-		cfg, err := config.LoadConfig("catalyst.yml")
+		cfg, err := config.LoadConfig(config.ProjectFile)
 		if err != nil {
-			return fmt.Errorf("failed to load catalyst.yml: %w", err)
+			return nil, fmt.Errorf("failed to load catalyst.yml: %w", err)
+		}
+		meta.Project = cfg.ProjectName
+		switch cfg.Type {
+		case "":
+		case config.TypeExecutable, config.TypeLibrary, config.TypeSharedLibrary:
+			projectType = cfg.Type
+		default:
+			return nil, fmt.Errorf("unknown project type %q (use %s, %s or %s)", cfg.Type, config.TypeExecutable, config.TypeLibrary, config.TypeSharedLibrary)
+		}
+
+		var targetFlags []string
+		if opts.Target != "" {
+			opts.Compiler, targetFlags, err = crossToolchain(cfg, opts.Target)
+			if err != nil {
+				return nil, err
+			}
+			log.Infof("Cross-compiling for %s with %s\n", opts.Target, opts.Compiler)
 		}
 
 		// Use sources from config if no args provided
 		if len(args) == 0 {
 			if len(cfg.Sources) == 0 {
-				return fmt.Errorf("no source files specified in catalyst.yml or command line")
+				return nil, fmt.Errorf("no source files specified in catalyst.yml or command line")
 			}
 			sourceFiles = cfg.Sources
-			fmt.Printf("Building from catalyst.yml: %s\n", cfg.ProjectName)
-			fmt.Printf("Source files: %v\n", sourceFiles)
+			log.Infof("Building from %s: %s\n", config.ProjectFile, cfg.ProjectName)
+			log.Debugf("Source files: %v\n", sourceFiles)
 
 			// Use flags from config
-			if len(cfg.Flags) > 0 {
-				flags = append(flags, cfg.Flags...)
+			flags = append(flags, cfg.FlagsFor(osKey)...)
+
+			// Target older macOS releases / a specific SDK if configured
+			if opts.Target == "" {
+				macFlags, err := macOSFlags(cfg)
+				if err != nil {
+					return nil, err
+				}
+				flags = append(flags, macFlags...)
 			}
 
 			// Use output name from config
@@ -104,20 +296,113 @@ func BuildProject(args []string) error {
 			}
 		}
 
-		// Install dependencies and get linker flags
-		fmt.Println()
-		fmt.Println("Installing dependencies...")
-		linkerFlags, err := install.InstallDependenciesAndGetLinkerFlags()
+		features, err := cfg.EnabledFeatures(opts.Features, opts.NoDefaultFeatures)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		meta.Features = features
+		if len(features) > 0 {
+			log.Infof("Enabled features: %s\n", strings.Join(features, ", "))
+			// Feature sources are only added when building from catalyst.yml
+			if len(args) == 0 {
+				for _, src := range cfg.FeatureSources(features) {
+					if !containsString(sourceFiles, src) {
+						sourceFiles = append(sourceFiles, src)
+					}
+				}
+			}
+			flags = append(flags, cfg.FeatureFlags(features)...)
+		}
+		featureDeps := cfg.FeatureDependenciesFor(features, osKey)
+
+		// Profile and target flags come last so they override the project's
+		if opts.Profile != "" {
+			profFlags, err := profileFlags(cfg, opts.Profile)
+			if err != nil {
+				return nil, err
+			}
+			log.Infof("Using %s profile: %s\n", opts.Profile, strings.Join(profFlags, " "))
+			flags = append(flags, profFlags...)
+		}
+		flags = append(flags, targetFlags...)
+
+		if opts.Launcher == "" {
+			opts.Launcher = cfg.CompilerLauncher
+		}
+		opts.Sandbox = opts.Sandbox || cfg.Sandbox
+		if opts.Jobs == 0 {
+			opts.Jobs = cfg.Jobs
+		}
+		opts.FileFlags = cfg.FileFlags
+
+		// Run code generators before compilation
+		if len(cfg.Generators) > 0 {
+			log.Info()
+			log.Info("Running code generators...")
+			genSources, genIncludes, err := RunGenerators(cfg.Generators, opts)
+			if err != nil {
+				return nil, fmt.Errorf("code generation failed: %w", err)
+			}
+
+			// Generated sources are only added when building from catalyst.yml
+			if len(args) == 0 {
+				for _, src := range genSources {
+					if !containsString(sourceFiles, src) {
+						sourceFiles = append(sourceFiles, src)
+					}
+				}
+			}
+			flags = append(flags, genIncludes...)
+		}
+
+		if opts.Target != "" {
+			// Host packages can't be linked into a cross build
+			log.Info()
+			log.Infof("Skipping dependency installation: %s libraries must be in the target sysroot\n", opts.Target)
+			deps := slices.Concat(cfg.DependenciesFor(osKey), featureDeps, cfg.GroupDependencies(cfg.Targets[opts.Target].Groups, osKey))
+			flags = append(flags, install.LinkingFlags(deps)...)
+		} else {
+			// Install dependencies and get linker flags
+			log.Info()
+			log.Info("Installing dependencies...")
+			linkerFlags, err := install.InstallDependenciesWithExtras(featureDeps)
+			if err != nil {
+				return nil, err
+			}
+
+			// Add linker flags to compilation flags
+			flags = append(flags, linkerFlags...)
 		}
 
-		// Add linker flags to compilation flags
-		flags = append(flags, linkerFlags...)
+		// Libraries of the workspace are built first, then linked in
+		if len(cfg.Uses) > 0 {
+			usesFlags, err := buildUsedLibraries(cfg.Uses, opts)
+			if err != nil {
+				return nil, err
+			}
+			flags = append(flags, usesFlags...)
+		}
+
+		if projectType == config.TypeSharedLibrary {
+			sharedFlags, err := sharedLibraryFlags(cfg, osKey)
+			if err != nil {
+				return nil, err
+			}
+			flags = append(flags, sharedFlags...)
+		} else if cfg.ExportHeader != "" {
+			log.Warnf("Ignoring export_header: it only applies to type: %s\n", config.TypeSharedLibrary)
+		}
 	} else {
+		if opts.Target != "" {
+			return nil, fmt.Errorf("--target needs a catalyst.yml with a targets: section")
+		}
+		if len(opts.Features) > 0 {
+			return nil, fmt.Errorf("--features needs a catalyst.yml with a features: section")
+		}
+
 		// No catalyst.yml, require command-line args
 		if len(args) == 0 {
-			return fmt.Errorf("no catalyst.yml found and no source files provided\n\nUsage:\n  catalyst build <source files>\n  or create catalyst.yml with 'catalyst init'")
+			return nil, fmt.Errorf("no catalyst.yml found and no source files provided\n\nUsage:\n  catalyst build <source files>\n  or create catalyst.yml with 'catalyst init'")
 		}
 
 		// Separate source files from compiler flags
@@ -128,38 +413,112 @@ func BuildProject(args []string) error {
 				sourceFiles = append(sourceFiles, arg)
 			}
 		}
+
+		// Only the built-in profiles are available without catalyst.yml
+		if opts.Profile != "" {
+			profFlags, err := profileFlags(&config.Config{}, opts.Profile)
+			if err != nil {
+				return nil, err
+			}
+			flags = append(flags, profFlags...)
+		}
+	}
+
+	// Generate C sources from flex (.l) and bison (.y) files
+	sourceFiles, grammarFlags, err := expandGrammarSources(sourceFiles, opts)
+	if err != nil {
+		return nil, err
 	}
+	flags = append(flags, grammarFlags...)
+
+	// Flags come from every section above, often more than once
+	flags = orderFlags(flags)
 
-	// Determine output binary path (always in build/ directory)
+	// Determine output binary path (always in build/ directory; profiles and
+	// cross builds get their own subdirectories so they don't clobber each other)
 	if output == "" {
 		output = "project"
 	}
-	outputPath := filepath.Join("build", output)
-	if runtime.GOOS == "windows" {
-		outputPath += ".exe"
+	outDir := OutputDir(opts)
+	var outputPath string
+	switch projectType {
+	case config.TypeLibrary:
+		compiler, err := findCompiler(opts)
+		if err != nil {
+			return nil, err
+		}
+		outputPath = filepath.Join(outDir, compiler.ArchiveName(output))
+
+		log.Info()
+		log.Info("Compiling library...")
+		if err := ArchiveWithOptions(sourceFiles, outputPath, flags, opts); err != nil {
+			return nil, err
+		}
+	case config.TypeSharedLibrary:
+		outputPath = filepath.Join(outDir, sharedLibraryName(output, osKey))
+		// Programs record the library's name rather than the path they were
+		// linked with, and find it through the rpath uses: adds. Kept out of
+		// the metadata so programs don't link with it.
+		linkFlags := slices.Clone(flags)
+		switch osKey {
+		case "windows":
+		case "darwin":
+			linkFlags = append(linkFlags, "-Wl,-install_name,@rpath/"+filepath.Base(outputPath))
+		default:
+			linkFlags = append(linkFlags, "-Wl,-soname,"+filepath.Base(outputPath))
+		}
+
+		log.Info()
+		log.Info("Compiling shared library...")
+		if err := CompileCWithOptions(sourceFiles, outputPath, linkFlags, opts); err != nil {
+			return nil, err
+		}
+	default:
+		outputPath = filepath.Join(outDir, output)
+		if osKey == "windows" {
+			outputPath += ".exe"
+		}
+
+		// Compile the C/C++ sources with linker flags
+		log.Info()
+		log.Info("Compiling project...")
+		if err := CompileCWithOptions(sourceFiles, outputPath, flags, opts); err != nil {
+			return nil, err
+		}
 	}
 
-	// Compile the C/C++ sources with linker flags
-	fmt.Println()
-	fmt.Println("Compiling project...")
-	if err := CompileC(sourceFiles, outputPath, flags); err != nil {
-		return err
+	meta.Output = outputPath
+	meta.Flags = flags
+	if err := writeBuildMetadata(meta, sourceFiles, osKey, opts); err != nil {
+		return nil, fmt.Errorf("failed to write build metadata: %w", err)
+	}
+	// Editor tooling follows the host build of the project
+	if meta.Project != "" && opts.Target == "" {
+		RefreshProjectFlags()
 	}
 
-	fmt.Println()
-	fmt.Println("Build complete!")
-	fmt.Printf("Binary: %s\n", outputPath)
-	return nil
+	log.Info()
+	log.Info("Build complete!")
+	if projectType != config.TypeExecutable {
+		log.Infof("Library: %s\n", outputPath)
+	} else {
+		log.Infof("Binary: %s\n", outputPath)
+	}
+	return &meta, nil
 }
 
-// RunProject executes the compiled binary, building it first if necessary
-func RunProject(args []string) error {
+// RunProject executes the compiled binary of the profile in opts, building
+// it first if necessary
+func RunProject(args []string, opts CompileOptions) error {
 	// Determine the binary path from config or default
 	output := "project"
 
 	// Try to load config to get output name
-	if _, err := os.Stat("catalyst.yml"); err == nil {
-		cfg, err := config.LoadConfig("catalyst.yml")
+	if _, err := os.Stat(config.ProjectFile); err == nil {
+		cfg, err := config.LoadConfig(config.ProjectFile)
+		if err == nil && cfg.IsLibrary() {
+			return fmt.Errorf("%s is a library; run a project that uses it instead", cfg.ProjectName)
+		}
 		if err == nil {
 			if cfg.Output != "" {
 				output = cfg.Output
@@ -169,36 +528,39 @@ func RunProject(args []string) error {
 		}
 	}
 
-	outputPath := filepath.Join("build", output)
+	outputPath := filepath.Join(OutputDir(opts), output)
 	if runtime.GOOS == "windows" {
 		outputPath += ".exe"
 	}
 
 	// Build the project first if binary doesn't exist or sources are provided
-	if len(args) > 0 {
-		if err := BuildProject(args); err != nil {
-			return err
+	err := withBuildLock(opts, func() error {
+		if len(args) > 0 {
+			return BuildProjectWithOptions(args, opts)
 		}
-	} else {
 		// Check if binary exists
 		if _, err := os.Stat(outputPath); os.IsNotExist(err) {
 			// Try to build from catalyst.yml
-			fmt.Println("Binary not found, building from catalyst.yml...")
-			if err := BuildProject([]string{}); err != nil {
+			log.Info("Binary not found, building from catalyst.yml...")
+			if err := BuildProjectWithOptions(nil, opts); err != nil {
 				return fmt.Errorf("build failed: %w", err)
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// Execute the binary
-	fmt.Println()
-	fmt.Println("Running project...")
-	fmt.Println("==============================================")
-	fmt.Println()
-
-	cmd := exec.Command("./" + outputPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	log.Info()
+	log.Info("Running project...")
+	log.Info("==============================================")
+	log.Info()
+
+	cmd := util.Command(util.Runnable(outputPath))
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
 	cmd.Stdin = os.Stdin
 
 	if err := cmd.Run(); err != nil {
@@ -208,47 +570,44 @@ func RunProject(args []string) error {
 	return nil
 }
 
-// CleanProject removes build artifacts and compiled binaries
+// CleanProject removes build artifacts and compiled binaries, after
+// listing them and asking for confirmation
 func CleanProject() error {
-	fmt.Println("Cleaning build artifacts...")
-
-	// Remove build directory
-	buildDir := "build"
-	if _, err := os.Stat(buildDir); err == nil {
-		if err := os.RemoveAll(buildDir); err != nil {
-			return fmt.Errorf("failed to remove build directory: %w", err)
-		}
-		fmt.Println("Removed build/ directory")
+	log.Info("Cleaning build artifacts...")
+
+	// bin/ is the legacy output directory; the executables are common
+	// default output names
+	paths := []string{"build", "bin", "project", "project.exe", "a.out", "a.exe"}
+	if util.BuildRoot != "" {
+		// Only the project's build/ in the build root: its .catalyst/
+		// holds the project lock and state, and the rest of the build
+		// root isn't catalyst's
+		paths = append(paths, util.BuildDir())
 	}
-
-	// Remove bin directory (legacy)
-	binDir := "bin"
-	if _, err := os.Stat(binDir); err == nil {
-		if err := os.RemoveAll(binDir); err != nil {
-			return fmt.Errorf("failed to remove bin directory: %w", err)
+	var found []string
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err == nil {
+			found = append(found, path)
 		}
-		fmt.Println("Removed bin/ directory")
 	}
-
-	// Remove common executable names
-	commonExecs := []string{"project", "project.exe", "a.out", "a.exe"}
-	removed := 0
-	for _, exec := range commonExecs {
-		if _, err := os.Stat(exec); err == nil {
-			if err := os.Remove(exec); err != nil {
-				fmt.Printf("Warning: Failed to remove %s: %v\n", exec, err)
-			} else {
-				fmt.Printf("Removed %s\n", exec)
-				removed++
-			}
-		}
+	if len(found) == 0 {
+		log.Info("Clean complete - no build artifacts found")
+		return nil
 	}
 
-	if removed == 0 && buildDir != "" {
-		fmt.Println("Clean complete - no additional artifacts found")
-	} else {
-		fmt.Printf("Cleaned %d build artifact(s)\n", removed)
+	if err := guard.Remove(found); err != nil {
+		return err
 	}
-
+	log.Infof("Cleaned %d build artifact(s)\n", len(found))
 	return nil
 }
+
+// containsString checks if a slice contains the given string
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package compile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// DetectedCompiler is a C compiler found in PATH
+type DetectedCompiler struct {
+	Name    string // As looked up, e.g. "gcc-13" or "aarch64-linux-gnu-gcc"
+	Path    string
+	Kind    string // The toolchain driving it: gcc, clang, cl, emcc, ...
+	Version string
+	Rank    int // Position in CompilerOrder; -1 if it's only used when set explicitly
+}
+
+// DetectCompilers lists the C compilers in PATH: those in the platform's
+// priority order first, in that order, then the others (versioned and cross
+// compilers) by name. The first is the one builds use, unless its Rank is
+// -1.
+func DetectCompilers() []DetectedCompiler {
+	var detected []DetectedCompiler
+	seen := make(map[string]bool)
+
+	for rank, name := range CompilerOrder(runtime.GOOS) {
+		path, err := lookPath(name)
+		if err != nil || seen[name] {
+			continue
+		}
+		seen[name] = true
+		detected = append(detected, newDetectedCompiler(name, path, rank))
+	}
+
+	var others []DetectedCompiler
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if runtime.GOOS == "windows" {
+				if !strings.HasSuffix(strings.ToLower(name), ".exe") {
+					continue
+				}
+				name = name[:len(name)-len(".exe")]
+			}
+			// Earlier PATH entries shadow later ones
+			if seen[name] {
+				continue
+			}
+			// C++ drivers build C too, but aren't listed as C compilers
+			if kind, ok := knownCompiler(name); !ok || strings.HasSuffix(kind, "++") {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			others = append(others, newDetectedCompiler(name, path, -1))
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].Name < others[j].Name })
+	return append(detected, others...)
+}
+
+func newDetectedCompiler(name, path string, rank int) DetectedCompiler {
+	return DetectedCompiler{
+		Name:    name,
+		Path:    path,
+		Kind:    compilerKind(name),
+		Version: compilerVersion(NewCompiler(name)),
+		Rank:    rank,
+	}
+}
+
+// isExecutable reports whether path is a file that can be run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
@@ -0,0 +1,239 @@
+package compile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	install "github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Report formats of catalyst test --coverage. The lcov tracefile is always
+// written; html adds a browsable report made from it.
+const (
+	CoverageHTML = "html"
+	CoverageLcov = "lcov"
+)
+
+// CoverageDir is where coverage builds of the tests and their reports go
+func CoverageDir() string {
+	return util.BuildDir("coverage")
+}
+
+// coverage instruments test builds and turns what their runs recorded into
+// a report. GCC builds use --coverage, whose .gcda files lcov reads with
+// gcov; Clang builds use source-based coverage, which llvm-profdata and
+// llvm-cov read.
+type coverage struct {
+	format   string
+	llvm     bool
+	tools    map[string]string // Path of each tool the report needs
+	gcov     string            // gcov matching a versioned GCC (gcov-13 for gcc-13)
+	binaries []string          // Test binaries that were built
+}
+
+// newCoverage checks that the compiler can measure coverage and installs
+// the tools the report needs, before anything is built
+func newCoverage(compiler Compiler, format string) (*coverage, error) {
+	c := &coverage{format: format, tools: make(map[string]string)}
+	kind := compilerKind(compiler.Command())
+	switch kind {
+	case "gcc", "g++", "cc", "c++", "clang", "clang++":
+	default:
+		return nil, fmt.Errorf("coverage needs GCC or Clang, not %s", compiler.Command())
+	}
+	// cc is Clang on macOS and the BSDs
+	c.llvm = strings.HasPrefix(kind, "clang") || strings.Contains(strings.ToLower(compiler.Version()), "clang")
+	version := versionSuffix.FindString(strings.TrimSuffix(filepath.Base(compiler.Command()), ".exe"))
+
+	var needed []string
+	if c.llvm {
+		needed = []string{"llvm-profdata", "llvm-cov"}
+	} else {
+		needed = []string{"lcov"}
+		if format == CoverageHTML {
+			needed = append(needed, "genhtml")
+		}
+		c.gcov = "gcov" + version
+	}
+	for _, name := range needed {
+		path, err := findCoverageTool(name, version)
+		if err != nil {
+			return nil, err
+		}
+		c.tools[name] = path
+	}
+
+	// Counters of an earlier run would be added to this one's
+	if err := os.RemoveAll(CoverageDir()); err != nil {
+		return nil, fmt.Errorf("failed to clear %s: %w", CoverageDir(), err)
+	}
+	return c, nil
+}
+
+// findCoverageTool returns the path of a coverage tool: the one matching a
+// versioned compiler (llvm-cov-17 for clang-17), the one in PATH, Xcode's
+// on macOS, or one installed with the package manager
+func findCoverageTool(name, version string) (string, error) {
+	if version != "" && strings.HasPrefix(name, "llvm-") {
+		if path, err := lookPath(name + version); err == nil {
+			return path, nil
+		}
+	}
+	if path, err := lookPath(name); err == nil {
+		return path, nil
+	}
+	if runtime.GOOS == "darwin" && strings.HasPrefix(name, "llvm-") {
+		if out, err := util.ParsedCommand("xcrun", "--find", name).Output(); err == nil {
+			return strings.TrimSpace(string(out)), nil
+		}
+	}
+	return install.EnsureTool(name)
+}
+
+// flags returns the flags that instrument a build
+func (c *coverage) flags() []string {
+	if c.llvm {
+		return []string{"-fprofile-instr-generate", "-fcoverage-mapping"}
+	}
+	return []string{"--coverage"}
+}
+
+// output returns where a test's coverage build goes. Each test gets its own
+// directory so tests sharing sources don't overwrite each other's notes.
+func (c *coverage) output(test string) string {
+	output := filepath.Join(CoverageDir(), "tests", test, test)
+	if runtime.GOOS == "windows" {
+		output += ".exe"
+	}
+	return output
+}
+
+// env returns the environment a test runs with, which tells Clang's runtime
+// where to write the profile
+func (c *coverage) env(test string) []string {
+	if !c.llvm {
+		return nil
+	}
+	profile := filepath.Join(CoverageDir(), "profiles", test+"-%p.profraw")
+	return append(os.Environ(), "LLVM_PROFILE_FILE="+profile)
+}
+
+// report turns the recorded counters into build/coverage/coverage.info and,
+// for html, build/coverage/html/, and prints the line coverage
+func (c *coverage) report() error {
+	if len(c.binaries) == 0 {
+		return fmt.Errorf("no test was built, so there is no coverage to report")
+	}
+	dir := CoverageDir()
+	tracefile := filepath.Join(dir, "coverage.info")
+	html := filepath.Join(dir, "html")
+	log.Info()
+	log.Info("Generating coverage report...")
+
+	if c.llvm {
+		profiles, _ := filepath.Glob(filepath.Join(dir, "profiles", "*.profraw"))
+		if len(profiles) == 0 {
+			return fmt.Errorf("the tests didn't write any coverage profiles")
+		}
+		profdata := filepath.Join(dir, "coverage.profdata")
+		merge := append([]string{"merge", "-sparse", "-o", profdata}, profiles...)
+		if err := runCoverageTool(c.tools["llvm-profdata"], merge...); err != nil {
+			return err
+		}
+		// llvm-cov reads the first binary as is and the rest after -object
+		objects := []string{"-instr-profile=" + profdata, c.binaries[0]}
+		for _, binary := range c.binaries[1:] {
+			objects = append(objects, "-object", binary)
+		}
+		out, err := util.Command(c.tools["llvm-cov"], append([]string{"export", "-format=lcov"}, objects...)...).Output()
+		if err != nil {
+			return fmt.Errorf("llvm-cov export failed: %w", err)
+		}
+		if err := os.WriteFile(tracefile, out, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", tracefile, err)
+		}
+		if c.format == CoverageHTML {
+			show := append([]string{"show", "-format=html", "-output-dir=" + html}, objects...)
+			if err := runCoverageTool(c.tools["llvm-cov"], show...); err != nil {
+				return err
+			}
+		}
+	} else {
+		capture := []string{"--capture", "--quiet", "--directory", dir, "--output-file", tracefile}
+		if c.gcov != "gcov" {
+			capture = append(capture, "--gcov-tool", c.gcov)
+		}
+		if err := runCoverageTool(c.tools["lcov"], capture...); err != nil {
+			return err
+		}
+		// Leave out system headers and anything else outside the project
+		if root, err := os.Getwd(); err == nil {
+			extract := []string{"--extract", tracefile, filepath.Join(root, "*"), "--quiet", "--output-file", tracefile}
+			if err := runCoverageTool(c.tools["lcov"], extract...); err != nil {
+				return err
+			}
+		}
+		if c.format == CoverageHTML {
+			if err := runCoverageTool(c.tools["genhtml"], tracefile, "--quiet", "--output-directory", html); err != nil {
+				return err
+			}
+		}
+	}
+
+	found, hit, err := lcovTotals(tracefile)
+	if err != nil {
+		return err
+	}
+	if found > 0 {
+		log.Infof("Line coverage: %.1f%% (%d of %d lines)\n", float64(hit)*100/float64(found), hit, found)
+	}
+	if c.format == CoverageHTML {
+		log.Infof("Report: %s\n", filepath.Join(html, "index.html"))
+	} else {
+		log.Infof("Report: %s\n", tracefile)
+	}
+	return nil
+}
+
+// runCoverageTool runs a coverage tool, printing its output if it fails
+func runCoverageTool(tool string, args ...string) error {
+	log.Debugf("Running %s %s\n", tool, strings.Join(args, " "))
+	out, err := util.Command(tool, args...).CombinedOutput()
+	if err != nil {
+		reportCompilerOutput(out)
+		return fmt.Errorf("%s failed: %w", filepath.Base(tool), err)
+	}
+	return nil
+}
+
+// lcovTotals returns the instrumented and executed lines of a tracefile,
+// summed from the LF: and LH: records of its files
+func lcovTotals(tracefile string) (found, hit int, err error) {
+	f, err := os.Open(tracefile)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read %s: %w", tracefile, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		record, value, _ := strings.Cut(scanner.Text(), ":")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		switch record {
+		case "LF":
+			found += n
+		case "LH":
+			hit += n
+		}
+	}
+	return found, hit, scanner.Err()
+}
@@ -0,0 +1,109 @@
+package compile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestLcovTotals(t *testing.T) {
+	tests := []struct {
+		name       string
+		tracefile  string
+		found, hit int
+	}{
+		{"empty", "", 0, 0},
+		{
+			"two files",
+			"TN:\nSF:/src/a.c\nDA:1,1\nLF:10\nLH:7\nend_of_record\nSF:/src/b.c\nLF:5\nLH:0\nend_of_record\n",
+			15, 7,
+		},
+		{"malformed counts and CRLF", "SF:/src/a.c\nLF:x\nLH:\nLF: 4\r\nLH:3\r\nend_of_record\n", 4, 3},
+		{"function and branch records", "FNF:3\nFNH:2\nBRF:8\nBRH:4\nLF:2\nLH:2\n", 2, 2},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "coverage.info")
+		if err := os.WriteFile(path, []byte(tt.tracefile), 0644); err != nil {
+			t.Fatal(err)
+		}
+		found, hit, err := lcovTotals(path)
+		if err != nil || found != tt.found || hit != tt.hit {
+			t.Errorf("%s: lcovTotals() = %d, %d, %v, want %d, %d", tt.name, found, hit, err, tt.found, tt.hit)
+		}
+	}
+	if _, _, err := lcovTotals(filepath.Join(t.TempDir(), "missing.info")); err == nil {
+		t.Error("lcovTotals() of a missing tracefile succeeded")
+	}
+}
+
+func TestNewCoverage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as the compilers and tools")
+	}
+	// Tools are looked up in a PATH of fakes only, with a detection cache
+	// of their own
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("HOME", t.TempDir())
+	forgetDetection()
+	defer forgetDetection()
+	t.Chdir(t.TempDir())
+
+	scripts := map[string]string{
+		"gcc":           "gcc (GCC) 14.1.0",
+		"gcc-13":        "gcc-13 (Ubuntu 13.2.0) 13.2.0",
+		"clang-17":      "Ubuntu clang version 17.0.6",
+		"cc":            "Apple clang version 15.0.0 (clang-1500.3.9.4)",
+		"lcov":          "",
+		"genhtml":       "",
+		"llvm-profdata": "",
+		"llvm-cov":      "",
+		"llvm-cov-17":   "",
+	}
+	for name, banner := range scripts {
+		script := "#!/bin/sh\necho '" + banner + "'\n"
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		compiler, format string
+		llvm             bool
+		gcov             string
+		tools            map[string]string
+		flags            []string
+	}{
+		{"gcc", CoverageLcov, false, "gcov", map[string]string{"lcov": "lcov"}, []string{"--coverage"}},
+		{"gcc", CoverageHTML, false, "gcov", map[string]string{"lcov": "lcov", "genhtml": "genhtml"}, []string{"--coverage"}},
+		// gcov must match the GCC that wrote the notes
+		{"gcc-13", CoverageLcov, false, "gcov-13", map[string]string{"lcov": "lcov"}, []string{"--coverage"}},
+		// So must llvm-cov, where a versioned one is installed
+		{"clang-17", CoverageHTML, true, "", map[string]string{"llvm-profdata": "llvm-profdata", "llvm-cov": "llvm-cov-17"},
+			[]string{"-fprofile-instr-generate", "-fcoverage-mapping"}},
+		// cc is Clang on macOS
+		{"cc", CoverageLcov, true, "", map[string]string{"llvm-profdata": "llvm-profdata", "llvm-cov": "llvm-cov"},
+			[]string{"-fprofile-instr-generate", "-fcoverage-mapping"}},
+	}
+	for _, tt := range tests {
+		c, err := newCoverage(NewCompiler(tt.compiler), tt.format)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.compiler, tt.format, err)
+			continue
+		}
+		tools := make(map[string]string)
+		for name, path := range c.tools {
+			tools[name] = filepath.Base(path)
+		}
+		if c.llvm != tt.llvm || c.gcov != tt.gcov || !reflect.DeepEqual(tools, tt.tools) || !reflect.DeepEqual(c.flags(), tt.flags) {
+			t.Errorf("%s %s: llvm %v, gcov %q, tools %v, flags %q; want %v, %q, %v, %q",
+				tt.compiler, tt.format, c.llvm, c.gcov, tools, c.flags(), tt.llvm, tt.gcov, tt.tools, tt.flags)
+		}
+	}
+
+	if _, err := newCoverage(NewCompiler("cl"), CoverageLcov); err == nil {
+		t.Error("newCoverage() accepted MSVC")
+	}
+}
@@ -0,0 +1,52 @@
+package compile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+)
+
+// crossToolchain returns the compiler and extra flags for a target triple
+// defined in the targets: section of catalyst.yml
+func crossToolchain(cfg *config.Config, triple string) (string, []string, error) {
+	target, ok := cfg.Targets[triple]
+	if !ok {
+		var known []string
+		for name := range cfg.Targets {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		if len(known) == 0 {
+			return "", nil, fmt.Errorf("no target %s in catalyst.yml (add it to a targets: section)", triple)
+		}
+		return "", nil, fmt.Errorf("no target %s in catalyst.yml (defined: %s)", triple, strings.Join(known, ", "))
+	}
+
+	compiler := target.Compiler
+	if compiler == "" {
+		compiler = triple + "-gcc"
+	}
+
+	var flags []string
+	if target.Sysroot != "" {
+		flags = append(flags, "--sysroot="+target.Sysroot)
+	}
+	flags = append(flags, target.Flags...)
+	return compiler, flags, nil
+}
+
+// targetOS maps a target triple to the platform key used in catalyst.yml
+// (linux, darwin or windows)
+func targetOS(triple string) string {
+	triple = strings.ToLower(triple)
+	switch {
+	case strings.Contains(triple, "mingw") || strings.Contains(triple, "windows"):
+		return "windows"
+	case strings.Contains(triple, "darwin") || strings.Contains(triple, "apple"):
+		return "darwin"
+	default:
+		return "linux"
+	}
+}
@@ -0,0 +1,181 @@
+package compile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Redetect ignores the cached compiler detection and detects compilers again
+var Redetect bool
+
+// detectionCache remembers where compilers were found and their versions,
+// so commands don't search PATH and start compilers on every run. It is only
+// valid for the PATH it was built with: the hash covers PATH and the
+// modification times of its directories, which change when a compiler is
+// installed or removed.
+type detectionCache struct {
+	PathHash  string                    `json:"path_hash"`
+	Compilers map[string]cachedCompiler `json:"compilers"`
+}
+
+// cachedCompiler is the lookup of one compiler name
+type cachedCompiler struct {
+	Path    string    `json:"path,omitempty"` // Empty if it isn't in PATH
+	ModTime time.Time `json:"mod_time,omitempty"`
+	Version string    `json:"version,omitempty"`
+}
+
+var (
+	cacheMu    sync.Mutex
+	cacheState *detectionCache
+)
+
+// detectionCachePath returns where the cache is kept (~/.catalyst/compilers.json)
+func detectionCachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".catalyst", "compilers.json"), nil
+}
+
+// pathHash identifies the PATH compilers are looked up in
+func pathHash() string {
+	h := sha256.New()
+	h.Write([]byte(runtime.GOOS + "\n" + os.Getenv("PATH") + "\n"))
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if info, err := os.Stat(dir); err == nil {
+			h.Write([]byte(dir + " " + info.ModTime().UTC().Format(time.RFC3339Nano) + "\n"))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadDetectionCache returns the cache for the current PATH, starting an
+// empty one if there is none, it was built for another PATH or --redetect
+// was given. Callers hold cacheMu.
+func loadDetectionCache() *detectionCache {
+	if cacheState != nil {
+		return cacheState
+	}
+	hash := pathHash()
+	cacheState = &detectionCache{PathHash: hash, Compilers: make(map[string]cachedCompiler)}
+	if Redetect {
+		return cacheState
+	}
+
+	path, err := detectionCachePath()
+	if err != nil {
+		return cacheState
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cacheState
+	}
+	var cached detectionCache
+	if err := json.Unmarshal(data, &cached); err != nil || cached.PathHash != hash || cached.Compilers == nil {
+		log.Debugf("Compiler detection cache is stale, detecting compilers again\n")
+		return cacheState
+	}
+	cacheState = &cached
+	return cacheState
+}
+
+// forgetDetection drops the cache loaded for this run, so compilers are
+// looked up in PATH as it is now
+func forgetDetection() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheState = nil
+}
+
+// saveDetectionCache writes the cache; failing to is not an error, the next
+// run just detects again. Callers hold cacheMu.
+func saveDetectionCache(cache *detectionCache) {
+	path, err := detectionCachePath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := util.WriteFileAtomic(path, data, 0644); err != nil {
+		log.Debugf("Failed to save the compiler detection cache: %v\n", err)
+	}
+}
+
+// lookPath is exec.LookPath for compilers, answered from the detection cache
+// when possible
+func lookPath(name string) (string, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cache := loadDetectionCache()
+
+	if entry, ok := cache.Compilers[name]; ok && entry.current() {
+		if entry.Path == "" {
+			return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+		}
+		return entry.Path, nil
+	}
+
+	path, err := exec.LookPath(name)
+	entry := cachedCompiler{Path: path}
+	if err == nil {
+		if info, statErr := os.Stat(path); statErr == nil {
+			entry.ModTime = info.ModTime()
+		}
+	}
+	cache.Compilers[name] = entry
+	saveDetectionCache(cache)
+	return path, err
+}
+
+// current reports whether the compiler found is still the same file
+func (c cachedCompiler) current() bool {
+	if c.Path == "" {
+		return true
+	}
+	info, err := os.Stat(c.Path)
+	return err == nil && info.ModTime().Equal(c.ModTime)
+}
+
+// compilerVersion returns c.Version(), which starts the compiler, from the
+// detection cache when the executable hasn't changed since
+func compilerVersion(c Compiler) string {
+	path, err := lookPath(c.Command())
+	if err != nil {
+		return c.Version()
+	}
+
+	cacheMu.Lock()
+	entry := loadDetectionCache().Compilers[c.Command()]
+	cacheMu.Unlock()
+	if entry.Version != "" && entry.Path == path {
+		return entry.Version
+	}
+
+	version := c.Version()
+	if version != "" {
+		cacheMu.Lock()
+		cache := loadDetectionCache()
+		entry.Version = version
+		cache.Compilers[c.Command()] = entry
+		saveDetectionCache(cache)
+		cacheMu.Unlock()
+	}
+	return version
+}
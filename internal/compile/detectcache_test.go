@@ -0,0 +1,100 @@
+package compile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectionCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as the compilers")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("HOME", t.TempDir())
+	forgetDetection()
+	defer forgetDetection()
+
+	gcc := filepath.Join(bin, "gcc")
+	writeCompiler := func(banner string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(gcc, []byte("#!/bin/sh\necho '"+banner+"'\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(gcc, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	built := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeCompiler("gcc (GCC) 14.1.0", built)
+
+	// A miss looks the compiler up and starts it, and saves what it found
+	if version := compilerVersion(NewCompiler("gcc")); !strings.Contains(version, "14.1.0") {
+		t.Fatalf("compilerVersion() = %q, want 14.1.0", version)
+	}
+	if _, err := lookPath("clang"); err == nil {
+		t.Fatal("lookPath(clang) found a compiler that isn't installed")
+	}
+	path, _ := detectionCachePath()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("the cache wasn't saved: %v", err)
+	}
+
+	// A hit in the next run answers from the cache without starting the
+	// compiler: one replaced in place with the same time still reports the
+	// cached version
+	forgetDetection()
+	writeCompiler("gcc (GCC) 15.0.0", built)
+	if version := compilerVersion(NewCompiler("gcc")); !strings.Contains(version, "14.1.0") {
+		t.Errorf("compilerVersion() on a hit = %q, want the cached 14.1.0", version)
+	}
+
+	// A compiler that changed since is started again
+	forgetDetection()
+	writeCompiler("gcc (GCC) 15.1.0", built.Add(time.Minute))
+	if version := compilerVersion(NewCompiler("gcc")); !strings.Contains(version, "15.1.0") {
+		t.Errorf("compilerVersion() of an updated compiler = %q, want 15.1.0", version)
+	}
+
+	// Installing a compiler changes its PATH directory, which invalidates
+	// the cache, including compilers it had not found
+	forgetDetection()
+	clang := filepath.Join(bin, "clang")
+	if err := os.WriteFile(clang, []byte("#!/bin/sh\necho 'clang version 18.1.3'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(bin, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := lookPath("clang"); err != nil || found != clang {
+		t.Errorf("lookPath(clang) after installing it = %q, %v", found, err)
+	}
+
+	// So does another PATH
+	forgetDetection()
+	other := t.TempDir()
+	t.Setenv("PATH", other+string(os.PathListSeparator)+bin)
+	if err := os.WriteFile(filepath.Join(other, "gcc"), []byte("#!/bin/sh\necho 'gcc (GCC) 13.2.0'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := lookPath("gcc"); found != filepath.Join(other, "gcc") {
+		t.Errorf("lookPath(gcc) with another PATH = %q, want the gcc first in it", found)
+	}
+
+	// --redetect ignores the cache, even where it would be current
+	forgetDetection()
+	t.Setenv("PATH", bin)
+	compilerVersion(NewCompiler("gcc"))
+	forgetDetection()
+	writeCompiler("gcc (GCC) 16.0.0", built.Add(time.Minute))
+	Redetect = true
+	defer func() { Redetect = false }()
+	if version := compilerVersion(NewCompiler("gcc")); !strings.Contains(version, "16.0.0") {
+		t.Errorf("compilerVersion() with --redetect = %q, want 16.0.0", version)
+	}
+}
@@ -0,0 +1,294 @@
+package compile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Diagnostic is an error, warning or note a compiler or linker reported
+type Diagnostic struct {
+	File     string // Empty for messages about the whole invocation, e.g. from the linker
+	Line     int    // 1-based; 0 if unknown
+	Column   int    // 1-based; 0 if unknown
+	Severity string // "error", "warning" or "note"
+	Code     string // MSVC's diagnostic code, e.g. "C2065"
+	Message  string
+}
+
+var (
+	// GCC and Clang: "main.c:5:10: error: 'y' undeclared"
+	gnuDiagnostic = regexp.MustCompile(`^(.+?):(\d+):(?:(\d+):)? (fatal error|error|warning|note): (.*)$`)
+	// MSVC: "main.c(5,10): error C2065: 'y': undeclared identifier"
+	msvcDiagnostic = regexp.MustCompile(`^(.+?)\((\d+)(?:,(\d+))?\)\s*: (fatal error|error|warning|note)(?: ([A-Z]+\d+))?: (.*)$`)
+	// Tools without a location: "collect2: error: ld returned 1 exit
+	// status", "LINK : fatal error LNK1104: cannot open file 'ssl.lib'"
+	toolDiagnostic = regexp.MustCompile(`^([\w.+-]+)\s?: (fatal error|error|warning)(?: ([A-Z]+\d+))?: (.*)$`)
+
+	// GCC's and newer Clang's source context: "    5 |   int x = y;"
+	contextLine = regexp.MustCompile(`^\s*\d*\s*\|`)
+	// The caret under the source context: "          ^~~"
+	caretLine = regexp.MustCompile(`^\s*[~^]*\^[~^]*\s*$`)
+)
+
+// parseDiagnostic parses one line of compiler output
+func parseDiagnostic(line string) (Diagnostic, bool) {
+	line = strings.TrimRight(line, "\r")
+	if m := gnuDiagnostic.FindStringSubmatch(line); m != nil {
+		return newDiagnostic(m[1], m[2], m[3], m[4], "", m[5]), true
+	}
+	if m := msvcDiagnostic.FindStringSubmatch(line); m != nil {
+		return newDiagnostic(m[1], m[2], m[3], m[4], m[5], m[6]), true
+	}
+	if m := toolDiagnostic.FindStringSubmatch(line); m != nil {
+		return newDiagnostic("", "", "", m[2], m[3], m[1]+": "+m[4]), true
+	}
+	return Diagnostic{}, false
+}
+
+func newDiagnostic(file, line, column, severity, code, message string) Diagnostic {
+	d := Diagnostic{File: file, Severity: severity, Code: code, Message: message}
+	d.Line, _ = strconv.Atoi(line)
+	d.Column, _ = strconv.Atoi(column)
+	if severity == "fatal error" {
+		d.Severity = "error"
+	}
+	return d
+}
+
+// ParseDiagnostics returns the diagnostics in the output of a GCC, Clang or
+// MSVC compiler or linker
+func ParseDiagnostics(output string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		if d, ok := parseDiagnostic(line); ok {
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// diagnosticCounts counts the errors and warnings reported during a build
+var diagnosticCounts struct {
+	sync.Mutex
+	errors, warnings int
+}
+
+// resetDiagnostics starts counting the diagnostics of a new build
+func resetDiagnostics() {
+	diagnosticCounts.Lock()
+	defer diagnosticCounts.Unlock()
+	diagnosticCounts.errors, diagnosticCounts.warnings = 0, 0
+}
+
+// summarizeDiagnostics prints how many errors and warnings the compiler
+// reported during the build, if any
+func summarizeDiagnostics() {
+	diagnosticCounts.Lock()
+	errors, warnings := diagnosticCounts.errors, diagnosticCounts.warnings
+	diagnosticCounts.Unlock()
+	if errors == 0 && warnings == 0 {
+		return
+	}
+	var counts []string
+	if errors > 0 {
+		counts = append(counts, plural(errors, "error"))
+	}
+	if warnings > 0 {
+		counts = append(counts, plural(warnings, "warning"))
+	}
+	summary := "Compiler reported " + strings.Join(counts, " and ")
+	if errors > 0 {
+		log.Error(summary)
+	} else {
+		log.Info(summary)
+	}
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// runCompiler runs a compiler or linker command and prints its output with
+// reportCompilerOutput
+func runCompiler(command string, args []string) error {
+	out, err := util.Command(command, args...).CombinedOutput()
+	reportCompilerOutput(out)
+	return err
+}
+
+// reportCompilerOutput prints compiler output to the console with each
+// diagnostic colored, and counts the errors and warnings. GCC and Clang show
+// the source line of a diagnostic themselves; when the compiler doesn't,
+// as MSVC doesn't, it is added. Other lines, such as "In function 'main':",
+// are printed as they are.
+func reportCompilerOutput(output []byte) {
+	if len(output) == 0 {
+		return
+	}
+	r := diagnosticReport{colored: log.Colored(), sources: make(map[string][]string)}
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		r.line(strings.TrimRight(line, "\r"))
+	}
+	r.finish()
+	io.WriteString(log.Stderr(), r.b.String())
+}
+
+// diagnosticReport formats compiler output line by line
+type diagnosticReport struct {
+	b       strings.Builder
+	colored bool
+	sources map[string][]string // Lines of the source files, by path
+
+	last       *Diagnostic // The diagnostic whose context may follow
+	hadContext bool        // The compiler showed the source line of last
+	// A line after last that is its source line if a caret line follows,
+	// as older Clang prints it without a gutter
+	pending string
+}
+
+func (r *diagnosticReport) line(line string) {
+	if d, ok := parseDiagnostic(line); ok {
+		r.finish()
+		countDiagnostic(d)
+		r.writeDiagnostic(d)
+		r.last = &d
+		return
+	}
+	if r.last != nil {
+		switch {
+		case caretLine.MatchString(line) || contextLine.MatchString(line) && caretLine.MatchString(strings.SplitN(line, "|", 2)[1]):
+			r.flushPending()
+			r.hadContext = true
+			r.b.WriteString(r.paintCaret(line) + "\n")
+			return
+		case contextLine.MatchString(line):
+			r.flushPending()
+			r.hadContext = true
+			r.b.WriteString(line + "\n")
+			return
+		case r.pending == "" && !r.hadContext:
+			r.pending = line
+			return
+		}
+	}
+	r.finish()
+	r.b.WriteString(line + "\n")
+}
+
+// flushPending writes the held line, which the caret after it shows to be
+// source context
+func (r *diagnosticReport) flushPending() {
+	if r.pending != "" {
+		r.b.WriteString(r.pending + "\n")
+		r.pending = ""
+	}
+}
+
+// finish ends the context of the last diagnostic, adding its source line if
+// the compiler didn't show it
+func (r *diagnosticReport) finish() {
+	// Notes without context (GCC's "each undeclared identifier is reported
+	// only once") point back at the diagnostic above them
+	if r.last != nil && !r.hadContext && r.last.Severity != "note" {
+		r.writeSource(*r.last)
+	}
+	if r.pending != "" {
+		r.b.WriteString(r.pending + "\n")
+		r.pending = ""
+	}
+	r.last, r.hadContext = nil, false
+}
+
+func (r *diagnosticReport) paint(text, code string) string {
+	if !r.colored || text == "" {
+		return text
+	}
+	return code + text + "\033[0m"
+}
+
+// paintCaret colors the caret and underline of a context line
+func (r *diagnosticReport) paintCaret(line string) string {
+	start := strings.IndexAny(line, "^~")
+	end := strings.LastIndexAny(line, "^~") + 1
+	code := severityColors[r.last.Severity]
+	return line[:start] + r.paint(line[start:end], code) + line[end:]
+}
+
+// writeDiagnostic writes a diagnostic's location, severity and message
+func (r *diagnosticReport) writeDiagnostic(d Diagnostic) {
+	location := d.File
+	if d.Line > 0 {
+		location += ":" + strconv.Itoa(d.Line)
+		if d.Column > 0 {
+			location += ":" + strconv.Itoa(d.Column)
+		}
+	}
+	severity := d.Severity
+	if d.Code != "" {
+		severity += " " + d.Code
+	}
+	if location != "" {
+		r.b.WriteString(r.paint(location+":", "\033[1m") + " ")
+	}
+	fmt.Fprintf(&r.b, "%s %s\n", r.paint(severity+":", severityColors[d.Severity]), d.Message)
+}
+
+// writeSource writes the source line a diagnostic points at, with a caret
+// under its column
+func (r *diagnosticReport) writeSource(d Diagnostic) {
+	if d.File == "" || d.Line == 0 {
+		return
+	}
+	lines, ok := r.sources[d.File]
+	if !ok {
+		if data, err := os.ReadFile(d.File); err == nil {
+			lines = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		}
+		r.sources[d.File] = lines
+	}
+	if d.Line > len(lines) {
+		return
+	}
+	source := lines[d.Line-1]
+	gutter := strconv.Itoa(d.Line)
+	fmt.Fprintf(&r.b, " %s | %s\n", gutter, source)
+	if d.Column > 0 && d.Column <= len(source)+1 {
+		// Tabs are kept so the caret lines up however they're displayed
+		indent := strings.Map(func(c rune) rune {
+			if c == '\t' {
+				return c
+			}
+			return ' '
+		}, source[:d.Column-1])
+		fmt.Fprintf(&r.b, " %s | %s%s\n", strings.Repeat(" ", len(gutter)), indent, r.paint("^", severityColors[d.Severity]))
+	}
+}
+
+func countDiagnostic(d Diagnostic) {
+	diagnosticCounts.Lock()
+	defer diagnosticCounts.Unlock()
+	switch d.Severity {
+	case "error":
+		diagnosticCounts.errors++
+	case "warning":
+		diagnosticCounts.warnings++
+	}
+}
+
+// severityColors are the ANSI colors of diagnostic severities
+var severityColors = map[string]string{
+	"error":   "\033[1;31m",
+	"warning": "\033[1;35m",
+	"note":    "\033[1;36m",
+}
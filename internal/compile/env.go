@@ -0,0 +1,185 @@
+package compile
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	install "github.com/Sabique-Islam/catalyst/internal/install"
+)
+
+// Shells catalyst env writes scripts for
+var Shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// EnvVar is a variable of a ToolchainEnv
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// ToolchainEnv is the environment that runs the project's compiler by hand
+// with the settings catalyst builds with
+type ToolchainEnv struct {
+	Path []string // Directories put first in PATH
+	Vars []EnvVar
+}
+
+// Environment returns the toolchain environment of the project for a build
+// with opts: CC, CFLAGS, LDFLAGS and LDLIBS from catalyst.yml and the
+// installed dependencies, and the directories of their programs and DLLs.
+// Nothing is installed; dependencies that aren't installed get the flags
+// of the link map only.
+func Environment(opts CompileOptions) (*ToolchainEnv, error) {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalyst.yml: %w", err)
+	}
+	compiler, flags, err := resolveFlags(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	cc := compiler.Command()
+	if launcher := cmp.Or(opts.Launcher, cfg.CompilerLauncher); launcher != "" {
+		cc = launcher + " " + cc
+	}
+	// make links with $(CC) $(CFLAGS) $(LDFLAGS) ... $(LDLIBS), so flags
+	// for both steps are only in CFLAGS
+	compileFlags, linkFlags := splitFlags(flags)
+	var ldflags, ldlibs []string
+	for _, flag := range linkFlags {
+		switch {
+		case slices.Contains(compileFlags, flag):
+		case strings.HasPrefix(flag, "-l"):
+			ldlibs = append(ldlibs, flag)
+		default:
+			ldflags = append(ldflags, flag)
+		}
+	}
+
+	env := &ToolchainEnv{
+		Path: install.ToolchainPath(cfg),
+		Vars: []EnvVar{
+			{"CC", cc},
+			{"CFLAGS", joinFlags(translateFlags(compiler, compileFlags))},
+			{"LDFLAGS", joinFlags(ldflags)},
+			{"LDLIBS", joinFlags(ldlibs)},
+		},
+	}
+	if useConan, _ := install.UsesConan(cfg); useConan {
+		if dir, err := filepath.Abs(install.ConanDir()); err == nil {
+			env.Vars = append(env.Vars, EnvVar{"PKG_CONFIG_PATH", dir})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Env)) {
+		env.Vars = append(env.Vars, EnvVar{name, cfg.Env[name]})
+	}
+	return env, nil
+}
+
+// resolveFlags returns the compiler and the flags a host build with opts
+// uses, from catalyst.yml and the dependencies as installed now. Nothing is
+// installed or generated.
+func resolveFlags(cfg *config.Config, opts CompileOptions) (Compiler, []string, error) {
+	osKey := runtime.GOOS
+	flags := cfg.FlagsFor(osKey)
+	macFlags, err := macOSFlags(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	flags = append(flags, macFlags...)
+	features, err := cfg.EnabledFeatures(opts.Features, opts.NoDefaultFeatures)
+	if err != nil {
+		return nil, nil, err
+	}
+	flags = append(flags, cfg.FeatureFlags(features)...)
+	if opts.Profile != "" {
+		profFlags, err := profileFlags(cfg, opts.Profile)
+		if err != nil {
+			return nil, nil, err
+		}
+		flags = append(flags, profFlags...)
+	}
+	_, genIncludes := generatedOutputs(cfg.Generators)
+	flags = append(flags, genIncludes...)
+	depFlags, err := install.DependencyFlags(cfg.FeatureDependenciesFor(features, osKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	flags = append(flags, depFlags...)
+
+	compiler, err := findCompiler(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return compiler, flags, nil
+}
+
+// joinFlags joins flags into one variable, quoting those with spaces
+func joinFlags(flags []string) string {
+	quoted := make([]string, len(flags))
+	for i, flag := range flags {
+		if strings.ContainsAny(flag, " \t") {
+			flag = strconv.Quote(flag)
+		}
+		quoted[i] = flag
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Script returns the environment as commands for shell, which set it when
+// evaluated
+func (e *ToolchainEnv) Script(shell string) (string, error) {
+	var b strings.Builder
+	switch shell {
+	case "bash", "zsh":
+		b.WriteString("# Set with: eval \"$(catalyst env)\"\n")
+		if len(e.Path) > 0 {
+			fmt.Fprintf(&b, "export PATH=%s\"%c$PATH\"\n", posixQuote(strings.Join(e.Path, string(os.PathListSeparator))), os.PathListSeparator)
+		}
+		for _, v := range e.Vars {
+			fmt.Fprintf(&b, "export %s=%s\n", v.Name, posixQuote(v.Value))
+		}
+	case "fish":
+		b.WriteString("# Set with: catalyst env --shell fish | source\n")
+		if len(e.Path) > 0 {
+			quoted := make([]string, len(e.Path))
+			for i, dir := range e.Path {
+				quoted[i] = posixQuote(dir)
+			}
+			fmt.Fprintf(&b, "set -gx PATH %s $PATH\n", strings.Join(quoted, " "))
+		}
+		for _, v := range e.Vars {
+			fmt.Fprintf(&b, "set -gx %s %s\n", v.Name, posixQuote(v.Value))
+		}
+	case "powershell":
+		b.WriteString("# Set with: catalyst env --shell powershell | Out-String | Invoke-Expression\n")
+		if len(e.Path) > 0 {
+			dirs := strings.Join(e.Path, string(os.PathListSeparator)) + string(os.PathListSeparator)
+			fmt.Fprintf(&b, "$env:PATH = %s + $env:PATH\n", powershellQuote(dirs))
+		}
+		for _, v := range e.Vars {
+			fmt.Fprintf(&b, "$env:%s = %s\n", v.Name, powershellQuote(v.Value))
+		}
+	default:
+		return "", fmt.Errorf("unknown shell %q (use %s)", shell, strings.Join(Shells, ", "))
+	}
+	return b.String(), nil
+}
+
+// posixQuote quotes s for sh and fish, where nothing in single quotes is
+// special but the quote itself
+func posixQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powershellQuote quotes s for PowerShell, which doubles single quotes
+func powershellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,216 @@
+package compile
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	install "github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// examplesDir is where examples are found when catalyst.yml lists none
+const examplesDir = "examples"
+
+// Examples returns the examples of the project: those in the examples:
+// section of catalyst.yml, or else one for each C file and directory in
+// examples/. Directories with a catalyst.yml are projects of their own.
+func Examples(cfg *config.Config) ([]config.ExampleTarget, error) {
+	if len(cfg.Examples) > 0 {
+		for _, example := range cfg.Examples {
+			if example.Name == "" || len(example.Sources) == 0 && example.Dir == "" {
+				return nil, fmt.Errorf("every example in catalyst.yml needs a name and sources or a dir")
+			}
+		}
+		return cfg.Examples, nil
+	}
+
+	entries, err := os.ReadDir(examplesDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var examples []config.ExampleTarget
+	for _, entry := range entries {
+		path := filepath.Join(examplesDir, entry.Name())
+		if !entry.IsDir() {
+			if filepath.Ext(entry.Name()) == ".c" {
+				examples = append(examples, config.ExampleTarget{Name: strings.TrimSuffix(entry.Name(), ".c"), Sources: []string{path}})
+			}
+			continue
+		}
+		if hasProjectFile(path) {
+			examples = append(examples, config.ExampleTarget{Name: entry.Name(), Dir: path})
+			continue
+		}
+		if sources := cSources(path); len(sources) > 0 {
+			examples = append(examples, config.ExampleTarget{Name: entry.Name(), Sources: sources})
+		}
+	}
+	return examples, nil
+}
+
+// hasProjectFile reports whether dir itself, not a parent, has a catalyst.yml
+func hasProjectFile(dir string) bool {
+	root, _, ok := config.FindProject(dir)
+	if !ok {
+		return false
+	}
+	abs, err := filepath.Abs(dir)
+	return err == nil && root == abs
+}
+
+// cSources returns the C files under dir
+func cSources(dir string) []string {
+	var sources []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && filepath.Ext(path) == ".c" {
+			sources = append(sources, path)
+		}
+		return nil
+	})
+	return sources
+}
+
+// RunExample builds the named example into build/examples/ and runs it
+// with args after the ones in catalyst.yml. Examples are linked against the
+// project's library when it builds one, and otherwise built with the
+// project's flags and dependencies like tests.
+func RunExample(name string, args []string, opts CompileOptions) error {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load catalyst.yml: %w", err)
+	}
+	examples, err := Examples(cfg)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(examples, func(example config.ExampleTarget) bool { return example.Name == name })
+	if i < 0 {
+		return fmt.Errorf("no example named %s (list them with: catalyst example list)", name)
+	}
+	example := examples[i]
+	args = append(slices.Clone(example.Args), args...)
+
+	if example.Dir != "" {
+		return runExampleProject(example, args, opts)
+	}
+
+	output := util.BuildDir("examples", example.Name)
+	if runtime.GOOS == "windows" {
+		output += ".exe"
+	}
+	err = withBuildLock(opts, func() error {
+		flags, err := exampleFlags(cfg, opts)
+		if err != nil {
+			return err
+		}
+		flags = append(flags, example.Flags...)
+		opts.FileFlags = cfg.FileFlags
+		for _, lib := range example.Libs {
+			flags = append(flags, "-l"+lib)
+		}
+		log.Infof("Building example %s...\n", example.Name)
+		return CompileCWithOptions(example.Sources, output, flags, opts)
+	})
+	if err != nil {
+		return err
+	}
+	return runExampleBinary(output, "", args)
+}
+
+// exampleFlags returns the flags examples are built with: the flags using
+// the project's library, or the project's own flags and dependencies
+func exampleFlags(cfg *config.Config, opts CompileOptions) ([]string, error) {
+	if cfg.IsLibrary() {
+		meta, err := Build(nil, opts)
+		if err != nil {
+			return nil, err
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		return libraryFlags(cwd, meta)
+	}
+
+	flags := cfg.GetFlags()
+	macFlags, err := macOSFlags(cfg)
+	if err != nil {
+		return nil, err
+	}
+	flags = append(flags, macFlags...)
+	linkerFlags, err := install.InstallDependenciesAndGetLinkerFlags()
+	if err != nil {
+		return nil, err
+	}
+	return append(flags, linkerFlags...), nil
+}
+
+// runExampleProject builds an example that is a project of its own, in its
+// directory, and runs what it built
+func runExampleProject(example config.ExampleTarget, args []string, opts CompileOptions) error {
+	root, file, ok := config.FindProject(example.Dir)
+	if !ok || !hasProjectFile(example.Dir) {
+		return fmt.Errorf("example %s: %s has no catalyst.yml", example.Name, example.Dir)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	projectFile := config.ProjectFile
+	if err := os.Chdir(root); err != nil {
+		return fmt.Errorf("cannot change to %s: %w", root, err)
+	}
+	config.ProjectFile = file
+	defer func() {
+		os.Chdir(cwd)
+		config.ProjectFile = projectFile
+	}()
+
+	cfg, err := config.LoadConfig(file)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", filepath.Join(example.Dir, file), err)
+	}
+	if cfg.IsLibrary() {
+		return fmt.Errorf("example %s builds a library, not a program", example.Name)
+	}
+
+	log.Infof("Building example %s in %s\n", example.Name, example.Dir)
+	var meta *BuildMetadata
+	err = withBuildLock(opts, func() (err error) {
+		meta, err = Build(nil, opts)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("building example %s failed: %w", example.Name, err)
+	}
+	return runExampleBinary(meta.Output, root, args)
+}
+
+// runExampleBinary runs a built example on the terminal, in dir if set
+func runExampleBinary(output, dir string, args []string) error {
+	log.Info()
+	log.Infof("Running %s...\n", output)
+	log.Info("==============================================")
+	log.Info()
+
+	cmd := util.Command(util.Runnable(output), args...)
+	cmd.Dir = dir
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("example failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package compile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	install "github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/lock"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// FlagsFile is where the resolved flags of the project's sources are kept
+// for editor tooling (.catalyst/flags.json)
+func FlagsFile() string {
+	return util.StateDir("flags.json")
+}
+
+// SourceFlags are the resolved compile flags of one source file
+type SourceFlags struct {
+	Includes []string `json:"includes,omitempty"` // Include directories, absolute
+	Defines  []string `json:"defines,omitempty"`  // NAME or NAME=VALUE
+	Std      string   `json:"std,omitempty"`      // Language standard, e.g. c11
+	Flags    []string `json:"flags"`              // Every compile flag, GCC-style
+}
+
+// ResolvedFlags is the content of .catalyst/flags.json: the compile flags of
+// every source of the project, its features' and generators' and its tests',
+// as a host build resolves them. It's kept until catalyst.yml, catalyst.lock,
+// the installed packages or the compiler change, so tools can read it
+// instead of resolving dependencies themselves.
+type ResolvedFlags struct {
+	Fingerprint string                 `json:"fingerprint"` // What the flags were resolved from
+	Compiler    string                 `json:"compiler"`
+	Directory   string                 `json:"directory"` // The project, which paths are relative to
+	Files       map[string]SourceFlags `json:"files"`
+}
+
+// ProjectFlags returns the resolved flags of the project's sources, from
+// .catalyst/flags.json if nothing they depend on changed since it was
+// written, and resolves and saves them otherwise (or with force). Nothing
+// is installed: run catalyst install first.
+func ProjectFlags(force bool) (*ResolvedFlags, error) {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load catalyst.yml: %w", err)
+	}
+	compiler, err := findCompiler(CompileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := flagsFingerprint(cfg, compiler)
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		if data, err := os.ReadFile(FlagsFile()); err == nil {
+			var cached ResolvedFlags
+			if json.Unmarshal(data, &cached) == nil && cached.Fingerprint == fingerprint {
+				return &cached, nil
+			}
+		}
+	}
+
+	log.Debugf("Resolving the flags of the project's sources\n")
+	resolved, err := resolveProjectFlags(cfg, compiler)
+	if err != nil {
+		return nil, err
+	}
+	resolved.Fingerprint = fingerprint
+	data, err := json.MarshalIndent(resolved, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s: %w", FlagsFile(), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(FlagsFile()), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(FlagsFile()), err)
+	}
+	if err := util.WriteFileAtomic(FlagsFile(), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", FlagsFile(), err)
+	}
+	return resolved, nil
+}
+
+// RefreshProjectFlags brings .catalyst/flags.json up to date after a build.
+// Failing to is only worth a debug message: the build itself succeeded.
+func RefreshProjectFlags() {
+	if _, err := ProjectFlags(false); err != nil {
+		log.Debugf("Failed to update %s: %v\n", FlagsFile(), err)
+	}
+}
+
+// flagsFingerprint identifies what the resolved flags depend on:
+// catalyst.yml with its variables replaced, catalyst.lock, the installed
+// packages (the package database, the isolated prefix and the Conan
+// packages) and the compiler
+func flagsFingerprint(cfg *config.Config, compiler Compiler) (string, error) {
+	h := sha256.New()
+	project := *cfg
+	project.CreatedAt = "" // Set to the current time when catalyst.yml has none
+	data, err := json.Marshal(project)
+	if err != nil {
+		return "", err
+	}
+	h.Write(data)
+	h.Write([]byte{0})
+	data, err = os.ReadFile(lock.DefaultPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	h.Write(data)
+	h.Write([]byte{0})
+	for _, dir := range []string{install.PrefixDir(), install.ConanDir()} {
+		if info, err := os.Stat(dir); err == nil {
+			fmt.Fprintf(h, "%s %d\n", dir, info.ModTime().UnixNano())
+		}
+	}
+	fmt.Fprintf(h, "%s %s %s %s\n", runtime.GOOS, compiler.Command(), os.Getenv("PKG_CONFIG_PATH"),
+		platform.PackagesFingerprint(platform.DetectSupport().PackageManager))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resolveProjectFlags resolves the flags of every source of the project
+func resolveProjectFlags(cfg *config.Config, compiler Compiler) (*ResolvedFlags, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	_, flags, err := resolveFlags(cfg, CompileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	resolved := &ResolvedFlags{Compiler: compiler.Command(), Directory: dir, Files: make(map[string]SourceFlags)}
+
+	features, err := cfg.EnabledFeatures(nil, false)
+	if err != nil {
+		return nil, err
+	}
+	genSources, _ := generatedOutputs(cfg.Generators)
+	for _, src := range slices.Concat(cfg.Sources, cfg.FeatureSources(features), genSources) {
+		resolved.Files[filepath.ToSlash(src)] = sourceFlags(dir, slices.Concat(flags, config.FlagsForFile(cfg.FileFlags, src)))
+	}
+	// Test sources are compiled with the test's own flags added
+	for _, test := range cfg.Tests {
+		for _, src := range test.Sources {
+			if _, ok := resolved.Files[filepath.ToSlash(src)]; !ok {
+				resolved.Files[filepath.ToSlash(src)] = sourceFlags(dir, slices.Concat(flags, test.Flags, config.FlagsForFile(cfg.FileFlags, src)))
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// sourceFlags sorts the compile flags among flags into a SourceFlags
+func sourceFlags(dir string, flags []string) SourceFlags {
+	compileFlags, _ := splitFlags(flags)
+	sf := SourceFlags{Flags: compileFlags}
+	for _, flag := range joinPairFlags(compileFlags) {
+		name, value, pair := cutPairFlag(flag)
+		switch {
+		case pair && name == "-isystem":
+			sf.Includes = append(sf.Includes, absolutePath(dir, value))
+		case strings.HasPrefix(flag, "-I"):
+			sf.Includes = append(sf.Includes, absolutePath(dir, flag[2:]))
+		case strings.HasPrefix(flag, "-D"):
+			sf.Defines = append(sf.Defines, flag[2:])
+		case strings.HasPrefix(flag, "-std="):
+			sf.Std = flag[len("-std="):]
+		}
+	}
+	if sf.Flags == nil {
+		sf.Flags = []string{}
+	}
+	return sf
+}
+
+// CompileFlags returns the flags the project compiles src with, with include
+// directories made absolute so they work from any directory. src is absolute
+// or relative to the project; ok is false if it isn't one of its sources.
+func (r *ResolvedFlags) CompileFlags(src string) (flags []string, ok bool) {
+	if filepath.IsAbs(src) {
+		rel, err := filepath.Rel(r.Directory, src)
+		if err != nil {
+			return nil, false
+		}
+		src = rel
+	}
+	sf, ok := r.Files[filepath.ToSlash(filepath.Clean(src))]
+	if !ok {
+		return nil, false
+	}
+	for _, flag := range joinPairFlags(sf.Flags) {
+		switch name, value, pair := cutPairFlag(flag); {
+		case pair && name == "-isystem":
+			flags = append(flags, name, absolutePath(r.Directory, value))
+		case pair:
+			flags = append(flags, name, value)
+		case strings.HasPrefix(flag, "-I"):
+			flags = append(flags, "-I"+absolutePath(r.Directory, flag[2:]))
+		default:
+			flags = append(flags, flag)
+		}
+	}
+	return flags, true
+}
+
+// absolutePath resolves path against dir unless it's absolute already
+func absolutePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// WriteCompileCommandsFromFlags writes build/compile_commands.json from
+// resolved flags
+func WriteCompileCommandsFromFlags(resolved *ResolvedFlags) (string, error) {
+	compiler := NewCompiler(resolved.Compiler)
+	outDir := util.BuildDir("obj")
+	var commands []compileCommand
+	for _, src := range slices.Sorted(maps.Keys(resolved.Files)) {
+		object := filepath.Join(outDir, objectName(src, compiler.ObjectExt()))
+		args := append([]string{compiler.Command()}, compiler.CompileObject(src, object, resolved.Files[src].Flags)...)
+		commands = append(commands, compileCommand{Directory: resolved.Directory, File: src, Arguments: args, Output: object})
+	}
+	return writeCompileCommands(util.BuildDir("compile_commands.json"), commands)
+}
+
+// clangdHeader marks a .clangd written by catalyst, which it may rewrite
+const clangdHeader = "# Generated by catalyst flags --clangd"
+
+// WriteClangdConfig writes a .clangd that points clangd at the
+// compile_commands.json in build/. A .clangd the project wrote itself is
+// left alone.
+func WriteClangdConfig() error {
+	if data, err := os.ReadFile(".clangd"); err == nil && !strings.HasPrefix(string(data), clangdHeader) {
+		return fmt.Errorf(".clangd exists and wasn't written by catalyst; add CompilationDatabase: %s under CompileFlags: yourself", filepath.ToSlash(util.BuildDir()))
+	}
+	content := clangdHeader + "; edit freely after removing this line\n" +
+		"CompileFlags:\n" +
+		"  CompilationDatabase: " + filepath.ToSlash(util.BuildDir()) + "\n"
+	if err := util.WriteFileAtomic(".clangd", []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write .clangd: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package compile
+
+import (
+	"slices"
+	"strings"
+)
+
+// flagKind is where a GCC-style flag belongs on the command line. Flags
+// gather from catalyst.yml, features, profiles, generators, pkg-config and
+// the command line, so the same flag often arrives more than once and in
+// an order single-pass linkers reject.
+type flagKind int
+
+const (
+	includeFlag flagKind = iota // -I and -isystem, searched in order so the first wins
+	defineFlag                  // -D and -U; later definitions override earlier ones
+	compileFlag                 // Other options, used when compiling and linking; later ones override
+	linkFlag                    // Library paths and linker options, only used when linking
+	libraryFlag                 // Libraries and object files, which must follow the objects using them
+)
+
+// isLinkerOption reports whether a flag passes an option to the linker,
+// with pair flags joined to their value as joinPairFlags does
+func isLinkerOption(flag string) bool {
+	return strings.HasPrefix(flag, "-Wl,") || strings.HasPrefix(flag, "-Xlinker ")
+}
+
+// positionalLinkerFlags apply to the libraries after them on the command
+// line, so they stay among the libraries and are never dropped
+var positionalLinkerFlags = []string{
+	"--whole-archive", "--no-whole-archive", "--start-group", "--end-group",
+	"-Bstatic", "-Bdynamic", "--as-needed", "--no-as-needed",
+}
+
+// classifyFlag returns the kind of a flag, with pair flags joined to their
+// value as joinPairFlags does
+func classifyFlag(flag string) flagKind {
+	if name, _, ok := cutPairFlag(flag); ok {
+		switch name {
+		case "-isystem":
+			return includeFlag
+		case "-framework":
+			return libraryFlag
+		case "-Xlinker":
+			return linkFlag
+		}
+		return compileFlag // -include
+	}
+	switch {
+	case strings.HasPrefix(flag, "-I"):
+		return includeFlag
+	case strings.HasPrefix(flag, "-D"), strings.HasPrefix(flag, "-U"):
+		return defineFlag
+	case strings.HasPrefix(flag, "-l"), !strings.HasPrefix(flag, "-"), isPositionalLinkerFlag(flag):
+		return libraryFlag
+	case strings.HasPrefix(flag, "-L"), strings.HasPrefix(flag, "-Wl,"):
+		return linkFlag
+	}
+	return compileFlag
+}
+
+func isPositionalLinkerFlag(flag string) bool {
+	option, ok := strings.CutPrefix(flag, "-Wl,")
+	return ok && slices.Contains(positionalLinkerFlags, option)
+}
+
+// orderFlags groups flags into include paths, defines, other options,
+// linker options and libraries, keeping their relative order within each
+// group, and drops duplicates. Include and library paths keep their first
+// occurrence, where they take effect; defines and options keep their last,
+// so overrides still win. Options passed to the linker (-Wl, and -Xlinker)
+// and libraries are kept as written: an option's values follow it as
+// separate options (-Wl,-rpath -Wl,/a), and a library repeated after
+// another resolves a circular dependency between static libraries.
+func orderFlags(flags []string) []string {
+	units := joinPairFlags(flags)
+	groups := make([][]string, libraryFlag+1)
+	for i, flag := range units {
+		kind := classifyFlag(flag)
+		switch {
+		case kind == libraryFlag || isLinkerOption(flag):
+		case kind == includeFlag || kind == linkFlag:
+			if slices.Contains(units[:i], flag) {
+				continue
+			}
+		default:
+			if slices.Contains(units[i+1:], flag) {
+				continue
+			}
+		}
+		groups[kind] = append(groups[kind], flag)
+	}
+
+	ordered := make([]string, 0, len(flags))
+	for _, group := range groups {
+		for _, flag := range group {
+			if name, value, ok := cutPairFlag(flag); ok {
+				ordered = append(ordered, name, value)
+			} else {
+				ordered = append(ordered, flag)
+			}
+		}
+	}
+	return ordered
+}
+
+// splitFlags orders flags and separates those only used when compiling
+// (include paths, defines) from those only used when linking (libraries,
+// linker options). Other flags such as -pthread or -fopenmp are passed to both.
+func splitFlags(flags []string) (compileFlags, linkFlags []string) {
+	for _, flag := range joinPairFlags(orderFlags(flags)) {
+		unit := []string{flag}
+		if name, value, ok := cutPairFlag(flag); ok {
+			unit = []string{name, value}
+		}
+		switch classifyFlag(flag) {
+		case includeFlag, defineFlag:
+			compileFlags = append(compileFlags, unit...)
+		case compileFlag:
+			compileFlags = append(compileFlags, unit...)
+			linkFlags = append(linkFlags, unit...)
+		default:
+			linkFlags = append(linkFlags, unit...)
+		}
+	}
+	return compileFlags, linkFlags
+}
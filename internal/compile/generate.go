@@ -0,0 +1,140 @@
+package compile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// RunGenerators runs the code generators defined in catalyst.yml.
+// A generator is only re-run when one of its outputs is missing or one of its
+// inputs is newer than its oldest output.
+// Returns the generated source files to compile and include flags for
+// directories containing generated headers. With opts.Sandbox set, generator
+// commands can only write to the directories of their declared outputs.
+func RunGenerators(generators []config.Generator, opts CompileOptions) ([]string, []string, error) {
+	for i, gen := range generators {
+		name := gen.Name
+		if name == "" {
+			name = fmt.Sprintf("generator %d", i+1)
+		}
+
+		if gen.Command == "" {
+			return nil, nil, fmt.Errorf("%s: no command specified", name)
+		}
+		if len(gen.Outputs) == 0 {
+			return nil, nil, fmt.Errorf("%s: no outputs declared", name)
+		}
+
+		stale, reason, err := needsRegeneration(gen.Inputs, gen.Outputs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		if stale {
+			log.Infof("Generating %s (%s): %s\n", name, reason, gen.Command)
+			if err := runGeneratorCommand(gen.Command, gen.Outputs, opts.Sandbox); err != nil {
+				return nil, nil, fmt.Errorf("%s failed: %w", name, err)
+			}
+
+			// Make sure the command produced what it declared
+			for _, out := range gen.Outputs {
+				if _, err := os.Stat(out); err != nil {
+					return nil, nil, fmt.Errorf("%s did not produce declared output %s", name, out)
+				}
+			}
+		} else {
+			log.Infof("Skipping %s (outputs up to date)\n", name)
+		}
+	}
+
+	sources, includeFlags := generatedOutputs(generators)
+	return sources, includeFlags, nil
+}
+
+// generatedOutputs returns the sources the generators declare and include
+// flags for the directories of the headers they declare
+func generatedOutputs(generators []config.Generator) (sources, includeFlags []string) {
+	seenIncludes := make(map[string]bool)
+	for _, gen := range generators {
+		for _, out := range gen.Outputs {
+			switch strings.ToLower(filepath.Ext(out)) {
+			case ".c", ".cpp", ".cc", ".cxx":
+				sources = append(sources, out)
+			case ".h", ".hpp", ".hh", ".hxx":
+				flag := "-I" + filepath.Dir(out)
+				if !seenIncludes[flag] {
+					seenIncludes[flag] = true
+					includeFlags = append(includeFlags, flag)
+				}
+			}
+		}
+	}
+	return sources, includeFlags
+}
+
+// needsRegeneration checks whether outputs are missing or older than any input.
+// Returns whether regeneration is needed along with a short reason.
+func needsRegeneration(inputs, outputs []string) (bool, string, error) {
+	var oldestOutput int64
+	for _, out := range outputs {
+		info, err := os.Stat(out)
+		if err != nil {
+			return true, "missing " + out, nil
+		}
+		modTime := info.ModTime().UnixNano()
+		if oldestOutput == 0 || modTime < oldestOutput {
+			oldestOutput = modTime
+		}
+	}
+
+	for _, in := range inputs {
+		info, err := os.Stat(in)
+		if err != nil {
+			return false, "", fmt.Errorf("input %s not found", in)
+		}
+		if info.ModTime().UnixNano() > oldestOutput {
+			return true, in + " changed", nil
+		}
+	}
+
+	return false, "", nil
+}
+
+// runGeneratorCommand runs a generator command through the platform shell
+func runGeneratorCommand(command string, outputs []string, sandbox bool) error {
+	// Ensure output directories exist before the generator writes to them
+	var outputDirs []string
+	for _, out := range outputs {
+		dir := filepath.Dir(out)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		outputDirs = append(outputDirs, dir)
+	}
+
+	name, args := "sh", []string{"-c", command}
+	if runtime.GOOS == "windows" {
+		name, args = "cmd", []string{"/C", command}
+	}
+
+	if sandbox {
+		var err error
+		name, args, err = sandboxCommand(name, args, outputDirs)
+		if err != nil {
+			return err
+		}
+	}
+
+	cmd := util.Command(name, args...)
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
+
+	return cmd.Run()
+}
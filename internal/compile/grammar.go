@@ -0,0 +1,104 @@
+package compile
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	install "github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// generatedDir returns where sources generated from grammar files are written
+func generatedDir() string {
+	return util.BuildDir("gen")
+}
+
+// isGrammarFile checks if a file is a flex (.l) or bison (.y) source
+func isGrammarFile(path string) bool {
+	_, ok := pkgdb.GrammarTools[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// expandGrammarSources replaces flex and bison files in the source list with
+// the C files generated from them in build/gen. Generation only happens when
+// a grammar file has changed since the last build.
+// Returns the new source list and include flags needed by the generated code.
+func expandGrammarSources(sourceFiles []string, opts CompileOptions) ([]string, []string, error) {
+	var sources []string
+	var generators []config.Generator
+	var flags []string
+	seenFlags := make(map[string]bool)
+	addFlag := func(flag string) {
+		if !seenFlags[flag] {
+			seenFlags[flag] = true
+			flags = append(flags, flag)
+		}
+	}
+
+	for _, src := range sourceFiles {
+		if !isGrammarFile(src) {
+			sources = append(sources, src)
+			continue
+		}
+
+		gen, err := grammarGenerator(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		generators = append(generators, gen)
+
+		// Grammar prologues usually include headers relative to the grammar file
+		addFlag("-I" + filepath.Dir(src))
+	}
+
+	if len(generators) == 0 {
+		return sourceFiles, nil, nil
+	}
+
+	genSources, genIncludes, err := RunGenerators(generators, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("grammar generation failed: %w", err)
+	}
+
+	// Lexers include the parser header generated into build/gen
+	addFlag("-I" + generatedDir())
+	for _, flag := range genIncludes {
+		addFlag(flag)
+	}
+
+	return append(sources, genSources...), flags, nil
+}
+
+// grammarGenerator builds the generator that turns a grammar file into C sources
+func grammarGenerator(src string) (config.Generator, error) {
+	ext := strings.ToLower(filepath.Ext(src))
+	tool := pkgdb.GrammarTools[ext]
+
+	executable, err := install.EnsureTool(tool)
+	if err != nil {
+		return config.Generator{}, err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))
+	gen := config.Generator{
+		Name:   filepath.Base(src),
+		Inputs: []string{src},
+	}
+
+	switch ext {
+	case ".l":
+		out := filepath.Join(generatedDir(), base+".yy.c")
+		gen.Command = fmt.Sprintf("\"%s\" -o \"%s\" \"%s\"", executable, out, src)
+		gen.Outputs = []string{out}
+	case ".y":
+		out := filepath.Join(generatedDir(), base+".tab.c")
+		header := filepath.Join(generatedDir(), base+".tab.h")
+		gen.Command = fmt.Sprintf("\"%s\" -d -o \"%s\" \"%s\"", executable, out, src)
+		gen.Outputs = []string{out, header}
+	}
+
+	return gen, nil
+}
@@ -0,0 +1,270 @@
+package compile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// maxWorkspaceDepth is how many parent directories are searched for the
+// workspace holding the libraries a project uses
+const maxWorkspaceDepth = 3
+
+var (
+	// buildStack holds the directories of the projects being built, the
+	// outermost first, to report libraries that use each other
+	buildStack []string
+	// libraryBuilds has the libraries already built during this build,
+	// so a library several projects use is built once
+	libraryBuilds map[string]*BuildMetadata
+)
+
+// ArchiveWithOptions compiles sourceFiles to objects and bundles them into
+// the static library output
+func ArchiveWithOptions(sourceFiles []string, output string, flags []string, opts CompileOptions) error {
+	if len(sourceFiles) == 0 {
+		return fmt.Errorf("no source files provided for compilation")
+	}
+	outDir := filepath.Dir(output)
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	compiler, err := findCompiler(opts)
+	if err != nil {
+		return err
+	}
+	compileFlags, _ := splitFlags(flags)
+	objects, err := compileObjects(compiler, sourceFiles, outDir, compileFlags, opts)
+	if err != nil {
+		return err
+	}
+
+	// ar adds to an existing archive, which would keep objects of removed sources
+	if err := os.Remove(output); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old library: %w", err)
+	}
+	args := compiler.Archive(objects, output)
+	cmd := util.Command(args[0], args[1:]...)
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
+
+	log.Debugf("Archiving with: %s\n", strings.Join(args, " "))
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		err = fmt.Errorf("archiving failed: %w", err)
+		linkFinished(output, start, err)
+		return err
+	}
+	linkFinished(output, start, nil)
+	return nil
+}
+
+// enterBuild records that the project in the current directory is being
+// built and returns the function that ends it
+func enterBuild() (func(), error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if len(buildStack) == 0 {
+		libraryBuilds = make(map[string]*BuildMetadata)
+	}
+	buildStack = append(buildStack, cwd)
+	return func() { buildStack = buildStack[:len(buildStack)-1] }, nil
+}
+
+// buildUsedLibraries builds the workspace libraries named by uses and
+// returns the flags that compile against and link them: their directories
+// and include paths, the archives, and the libraries they link themselves
+func buildUsedLibraries(uses []string, opts CompileOptions) ([]string, error) {
+	var flags []string
+	for _, name := range uses {
+		dir, file, err := findLibrary(name)
+		if err != nil {
+			return nil, err
+		}
+		meta, err := buildLibrary(dir, file, opts)
+		if err != nil {
+			return nil, err
+		}
+		libFlags, err := libraryFlags(dir, meta)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, libFlags...)
+	}
+	return flags, nil
+}
+
+// findLibrary finds the project a uses: entry names: a path to it, or the
+// directory or project name of a member of the workspace around the current
+// project. It returns the project's directory and config file name.
+func findLibrary(name string) (dir, file string, err error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+
+	if strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		root, file, ok := config.FindProject(filepath.Join(cwd, name))
+		if ok && root == filepath.Clean(filepath.Join(cwd, name)) {
+			return root, file, nil
+		}
+		return "", "", fmt.Errorf("uses: %s has no %s", name, strings.Join(config.ConfigNames, " or "))
+	}
+
+	// The nearest workspace wins, so a project's own subdirectories come first
+	workspace := cwd
+	for depth := 0; depth <= maxWorkspaceDepth; depth++ {
+		for _, member := range config.FindMembers(workspace) {
+			memberDir := filepath.Join(workspace, member)
+			if memberDir == cwd {
+				continue
+			}
+			root, file, ok := config.FindProject(memberDir)
+			if !ok || root != memberDir {
+				continue
+			}
+			if filepath.Base(member) == name || filepath.ToSlash(member) == name {
+				return root, file, nil
+			}
+			if cfg, err := config.LoadConfig(filepath.Join(root, file)); err == nil && cfg.ProjectName == name {
+				return root, file, nil
+			}
+		}
+		parent := filepath.Dir(workspace)
+		if parent == workspace {
+			break
+		}
+		workspace = parent
+	}
+	return "", "", fmt.Errorf("uses: no library %s found in the workspace (name a project by its project_name or directory)", name)
+}
+
+// buildLibrary builds the library project in dir, unless it was already
+// built during this build, and returns its build metadata
+func buildLibrary(dir, file string, opts CompileOptions) (*BuildMetadata, error) {
+	if meta, ok := libraryBuilds[dir]; ok {
+		return meta, nil
+	}
+	for i, building := range buildStack {
+		if building == dir {
+			cycle := append(append([]string{}, buildStack[i:]...), dir)
+			for j := range cycle {
+				cycle[j] = filepath.Base(cycle[j])
+			}
+			return nil, fmt.Errorf("libraries use each other: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	cfg, err := config.LoadConfig(filepath.Join(dir, file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filepath.Join(dir, file), err)
+	}
+	if !cfg.IsLibrary() {
+		return nil, fmt.Errorf("%s is not a library; set type: %s in its %s", cfg.ProjectName, config.TypeLibrary, file)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	projectFile := config.ProjectFile
+	if err := os.Chdir(dir); err != nil {
+		return nil, fmt.Errorf("cannot change to %s: %w", dir, err)
+	}
+	config.ProjectFile = file
+	defer func() {
+		os.Chdir(cwd)
+		config.ProjectFile = projectFile
+	}()
+
+	log.Info()
+	log.Infof("Building library %s in %s\n", cfg.ProjectName, dir)
+
+	// Features and the cross compiler belong to the project using the library
+	libOpts := opts
+	libOpts.Features, libOpts.NoDefaultFeatures, libOpts.Compiler = nil, false, ""
+	meta, err := Build(nil, libOpts)
+	if err != nil {
+		return nil, fmt.Errorf("building library %s failed: %w", cfg.ProjectName, err)
+	}
+	libraryBuilds[dir] = meta
+	return meta, nil
+}
+
+// libraryFlags returns the flags a project uses the library built in dir
+// with. Paths in the library's flags are relative to dir and are rebased
+// onto the current directory; its defines and other compile options stay
+// private to it.
+func libraryFlags(dir string, meta *BuildMetadata) ([]string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	rebase := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		path = filepath.Join(dir, path)
+		if rel, err := filepath.Rel(cwd, path); err == nil {
+			return rel
+		}
+		return path
+	}
+
+	flags := []string{"-I" + rebase(".")}
+	var links []string
+	for i := 0; i < len(meta.Flags); i++ {
+		flag := meta.Flags[i]
+		switch {
+		case strings.HasPrefix(flag, "-I") && len(flag) > 2:
+			flags = append(flags, "-I"+rebase(flag[2:]))
+		case flag == "-isystem" && i+1 < len(meta.Flags):
+			flags = append(flags, flag, rebase(meta.Flags[i+1]))
+			i++
+		case strings.HasPrefix(flag, "-L") && len(flag) > 2:
+			links = append(links, "-L"+rebase(flag[2:]))
+		case (flag == "-include" || flag == "-Xlinker") && i+1 < len(meta.Flags):
+			i++
+		case flag == "-framework" && i+1 < len(meta.Flags):
+			links = append(links, flag, meta.Flags[i+1])
+			i++
+		case strings.HasPrefix(flag, "-l"), strings.HasPrefix(flag, "-Wl,"), flag == "-pthread", flag == "-fopenmp":
+			links = append(links, flag)
+		case !strings.HasPrefix(flag, "-"):
+			// Archives of the libraries this one uses
+			links = append(links, rebase(flag))
+		}
+	}
+
+	// The library comes before the libraries it needs
+	output := rebase(meta.Output)
+	switch filepath.Ext(output) {
+	case ".so", ".dylib":
+		// Let the program find the shared library where it was built
+		abs, err := filepath.Abs(output)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, output, "-Wl,-rpath,"+filepath.Dir(abs))
+	case ".dll":
+		// Link the import library when the toolchain wrote one; MinGW
+		// links the DLL itself
+		importLib := strings.TrimSuffix(output, ".dll") + ".lib"
+		if _, err := os.Stat(importLib); err == nil {
+			output = importLib
+		}
+		flags = append(flags, output)
+	default:
+		flags = append(flags, output)
+	}
+	return append(flags, links...), nil
+}
@@ -0,0 +1,306 @@
+package compile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	install "github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBaseline is the warnings baseline location relative to the project
+// root. It is meant to be committed so the whole team lints against it.
+const DefaultBaseline = "catalyst-warnings.yml"
+
+// LintOptions configure Lint
+type LintOptions struct {
+	Tool     string   // LintCompiler (the default), LintClangTidy or LintCppcheck
+	Warnings []string // Warning flags added to the project's, e.g. -Wall -Wconversion
+	Checks   string   // clang-tidy --checks or cppcheck --enable; the tool's own defaults if empty
+	Baseline string   // Path of the baseline file; BaselineFor(Tool) if empty
+	Update   bool     // Snapshot the current warnings into the baseline instead of checking them
+}
+
+// BaselineWarning is a warning the baseline accepts. Line numbers aren't
+// recorded, so editing other parts of a file doesn't resurface its warnings.
+type BaselineWarning struct {
+	File    string `yaml:"file"`
+	Code    string `yaml:"code,omitempty"` // MSVC's diagnostic code
+	Message string `yaml:"message"`
+	Count   int    `yaml:"count,omitempty"` // Occurrences in the file; omitted when 1
+}
+
+// WarningBaseline is the snapshot of a project's warnings that lint reports
+// new warnings against
+type WarningBaseline struct {
+	Warnings []BaselineWarning `yaml:"warnings"`
+}
+
+// warningKey identifies a warning independent of where in its file it is
+func warningKey(d Diagnostic) BaselineWarning {
+	return BaselineWarning{File: filepath.ToSlash(filepath.Clean(d.File)), Code: d.Code, Message: d.Message}
+}
+
+// LoadBaseline reads a warnings baseline. A missing baseline is not an error
+// and yields an empty one.
+func LoadBaseline(path string) (*WarningBaseline, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &WarningBaseline{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read warnings baseline: %w", err)
+	}
+	var b WarningBaseline
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid warnings baseline %s: %w", path, err)
+	}
+	return &b, nil
+}
+
+// Save writes the baseline, sorted by file so it diffs well
+func (b *WarningBaseline) Save(path string) error {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to marshal warnings baseline: %w", err)
+	}
+	header := "# Generated by catalyst lint --baseline. Warnings listed here aren't reported.\n"
+	if err := util.WriteFileAtomic(path, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write warnings baseline: %w", err)
+	}
+	return nil
+}
+
+// NewBaseline returns the baseline accepting warnings
+func NewBaseline(warnings []Diagnostic) *WarningBaseline {
+	counts := make(map[BaselineWarning]int)
+	var keys []BaselineWarning
+	for _, d := range warnings {
+		key := warningKey(d)
+		if counts[key] == 0 {
+			keys = append(keys, key)
+		}
+		counts[key]++
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].File < keys[j].File })
+
+	b := &WarningBaseline{}
+	for _, key := range keys {
+		if n := counts[key]; n > 1 {
+			key.Count = n
+		}
+		b.Warnings = append(b.Warnings, key)
+	}
+	return b
+}
+
+// NewWarnings returns the warnings the baseline doesn't account for, and
+// how many baselined warnings no longer occur. When a file has more
+// occurrences of a warning than the baseline, the ones after the
+// baselined count are new.
+func (b *WarningBaseline) NewWarnings(warnings []Diagnostic) (fresh []Diagnostic, fixed int) {
+	allowed := make(map[BaselineWarning]int)
+	for _, w := range b.Warnings {
+		count := max(w.Count, 1)
+		w.Count = 0
+		allowed[w] += count
+	}
+	for _, d := range warnings {
+		key := warningKey(d)
+		if allowed[key] > 0 {
+			allowed[key]--
+			continue
+		}
+		fresh = append(fresh, d)
+	}
+	for _, n := range allowed {
+		fixed += n
+	}
+	return fresh, fixed
+}
+
+// Lint compiles the project's sources with the warning flags in lint added,
+// or checks them with clang-tidy or cppcheck, and reports the findings.
+// With lint.Update the warnings are written to the baseline; otherwise only
+// warnings the baseline doesn't list are reported. An error is returned if
+// there are any, or any error-severity findings, which are never baselined.
+func Lint(lint LintOptions, opts CompileOptions) error {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load catalyst.yml: %w", err)
+	}
+	if len(cfg.Sources) == 0 {
+		return fmt.Errorf("no source files specified in catalyst.yml")
+	}
+	if lint.Tool == "" {
+		lint.Tool = LintCompiler
+	}
+	if !slices.Contains(LintTools, lint.Tool) {
+		return fmt.Errorf("unknown lint tool %q (use %s)", lint.Tool, strings.Join(LintTools, ", "))
+	}
+	if lint.Baseline == "" {
+		lint.Baseline = BaselineFor(lint.Tool)
+	}
+	var tool string
+	if lint.Tool != LintCompiler {
+		if tool, err = findLintTool(lint.Tool); err != nil {
+			return err
+		}
+	}
+
+	// Sources are compiled as the build compiles them, so the warnings are
+	// the ones the build shows
+	flags := cfg.GetFlags()
+	macFlags, err := macOSFlags(cfg)
+	if err != nil {
+		return err
+	}
+	flags = append(flags, macFlags...)
+	if opts.Launcher == "" {
+		opts.Launcher = cfg.CompilerLauncher
+	}
+	if opts.Jobs == 0 {
+		opts.Jobs = cfg.Jobs
+	}
+	opts.FileFlags = cfg.FileFlags
+
+	sources := cfg.Sources
+	if len(cfg.Generators) > 0 {
+		log.Info("Running code generators...")
+		genSources, genIncludes, err := RunGenerators(cfg.Generators, opts)
+		if err != nil {
+			return fmt.Errorf("code generation failed: %w", err)
+		}
+		sources = append(append([]string(nil), sources...), genSources...)
+		flags = append(flags, genIncludes...)
+	}
+
+	log.Info("Installing dependencies...")
+	linkerFlags, err := install.InstallDependenciesAndGetLinkerFlags()
+	if err != nil {
+		return err
+	}
+	flags = append(flags, linkerFlags...)
+	if lint.Tool == LintCompiler {
+		flags = append(flags, lint.Warnings...)
+	}
+
+	compiler, err := findCompiler(opts)
+	if err != nil {
+		return err
+	}
+	compileFlags, _ := splitFlags(orderFlags(flags))
+	var findings []Diagnostic
+	if lint.Tool == LintCompiler {
+		findings, err = lintSources(compiler, sources, compileFlags, opts)
+	} else {
+		findings, err = analyzeSources(tool, lint, compiler, sources, compileFlags, opts)
+	}
+	if err != nil {
+		return err
+	}
+	var errors, warnings []Diagnostic
+	for _, d := range findings {
+		if d.Severity == "error" {
+			errors = append(errors, d)
+		} else {
+			warnings = append(warnings, d)
+		}
+	}
+
+	if lint.Update {
+		if err := NewBaseline(warnings).Save(lint.Baseline); err != nil {
+			return err
+		}
+		log.Infof("Recorded %s in %s\n", plural(len(warnings), "warning"), lint.Baseline)
+		if len(errors) > 0 {
+			reportWarnings(errors)
+			return fmt.Errorf("%s, which the baseline doesn't accept", plural(len(errors), "error"))
+		}
+		return nil
+	}
+
+	baseline, err := LoadBaseline(lint.Baseline)
+	if err != nil {
+		return err
+	}
+	fresh, fixed := baseline.NewWarnings(warnings)
+	reported := append(errors, fresh...)
+	reportWarnings(reported)
+	if lint.Tool != LintCompiler {
+		summarizeFindings(reported)
+	}
+	if fixed > 0 {
+		log.Infof("%s in %s no longer occur; run catalyst lint --baseline to drop them\n", plural(fixed, "baselined warning"), lint.Baseline)
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("%s and %s (%d in the baseline)", plural(len(errors), "error"), plural(len(fresh), "new warning"), len(warnings)-len(fresh))
+	}
+	if len(fresh) > 0 {
+		return fmt.Errorf("%s (%d in the baseline)", plural(len(fresh), "new warning"), len(warnings)-len(fresh))
+	}
+	log.Infof("No new warnings (%d in the baseline)\n", len(warnings))
+	return nil
+}
+
+// lintSources compiles each source into build/lint/ with a pool of
+// opts.Jobs workers and returns the warnings, each reported once even if
+// a header shows it to several sources
+func lintSources(compiler Compiler, sources []string, flags []string, opts CompileOptions) ([]Diagnostic, error) {
+	outDir := util.BuildDir("lint")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lint directory: %w", err)
+	}
+	log.Infof("Linting %d source files with %s\n", len(sources), compiler.Command())
+
+	outputs, errs := forEachSource(sources, opts.Jobs, func(src string) ([]byte, error) {
+		object := filepath.Join(outDir, objectName(src, compiler.ObjectExt()))
+		args := compiler.CompileObject(src, object, sourceCompileFlags(src, flags, opts))
+		command, args, err := wrapCompilerCommand(compiler.Command(), args, outDir, opts)
+		if err != nil {
+			return nil, err
+		}
+		return util.Command(command, args...).CombinedOutput()
+	})
+
+	var failed []string
+	var warnings []Diagnostic
+	seen := make(map[Diagnostic]bool)
+	for i, src := range sources {
+		if errs[i] != nil {
+			reportCompilerOutput(outputs[i])
+			failed = append(failed, src)
+			continue
+		}
+		for _, d := range ParseDiagnostics(string(outputs[i])) {
+			if d.Severity == "warning" && !seen[d] {
+				seen[d] = true
+				warnings = append(warnings, d)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("compilation failed: %s", strings.Join(failed, ", "))
+	}
+	return warnings, nil
+}
+
+// reportWarnings prints findings with their source lines
+func reportWarnings(warnings []Diagnostic) {
+	if len(warnings) == 0 {
+		return
+	}
+	r := diagnosticReport{colored: log.Colored(), sources: make(map[string][]string)}
+	for _, d := range warnings {
+		r.writeDiagnostic(d)
+		r.writeSource(d)
+	}
+	fmt.Fprint(log.Stderr(), r.b.String())
+}
@@ -0,0 +1,103 @@
+package compile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestWarningBaseline(t *testing.T) {
+	old := []Diagnostic{
+		{File: "src/main.c", Line: 4, Severity: "warning", Message: "unused variable 'x' [-Wunused-variable]"},
+		{File: "src/main.c", Line: 9, Severity: "warning", Message: "unused variable 'x' [-Wunused-variable]"},
+		{File: "./src/util.c", Line: 2, Severity: "warning", Message: "unused parameter 'n' [-Wunused-parameter]"},
+	}
+	baseline := NewBaseline(old)
+	if len(baseline.Warnings) != 2 || baseline.Warnings[0].Count != 2 || baseline.Warnings[1].File != "src/util.c" {
+		t.Fatalf("NewBaseline() = %+v", baseline.Warnings)
+	}
+
+	path := filepath.Join(t.TempDir(), DefaultBaseline)
+	if err := baseline.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The warnings moved, one of main.c's was fixed and a third appeared in
+	// util.c alongside a new one
+	current := []Diagnostic{
+		{File: "src/main.c", Line: 12, Severity: "warning", Message: "unused variable 'x' [-Wunused-variable]"},
+		{File: "src/util.c", Line: 5, Severity: "warning", Message: "unused parameter 'n' [-Wunused-parameter]"},
+		{File: "src/util.c", Line: 8, Severity: "warning", Message: "unused parameter 'n' [-Wunused-parameter]"},
+		{File: "src/util.c", Line: 9, Severity: "warning", Message: "comparison of integers of different signs [-Wsign-compare]"},
+	}
+	fresh, fixed := loaded.NewWarnings(current)
+	if fixed != 1 {
+		t.Errorf("fixed = %d, want 1", fixed)
+	}
+	if len(fresh) != 2 || fresh[0].Line != 8 || fresh[1].Line != 9 {
+		t.Errorf("NewWarnings() = %+v, want util.c:8 and util.c:9", fresh)
+	}
+
+	missing, err := LoadBaseline(filepath.Join(t.TempDir(), "none.yml"))
+	if err != nil || len(missing.Warnings) != 0 {
+		t.Errorf("LoadBaseline(missing) = %+v, %v", missing, err)
+	}
+}
+
+func TestParseCppcheck(t *testing.T) {
+	output := `src/main.c:4:3: error: Null pointer dereference: p [nullPointer]
+src/util.c:2:0: style: The function 'f' is never used. [unusedFunction]
+nofile:0:0: information: Active checkers: 106/836 [checkersReport]
+Checking src/util.c ...`
+	got := parseCppcheck(output)
+	want := []Diagnostic{
+		{File: "src/main.c", Line: 4, Column: 3, Severity: "error", Message: "Null pointer dereference: p [nullPointer]"},
+		{File: "src/util.c", Line: 2, Severity: "warning", Message: "The function 'f' is never used. [unusedFunction]"},
+		{File: "nofile", Severity: "note", Message: "Active checkers: 106/836 [checkersReport]"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCppcheck() = %+v, want %+v", got, want)
+	}
+	if b := BaselineFor(LintClangTidy); b != "catalyst-warnings.clang-tidy.yml" {
+		t.Errorf("BaselineFor(clang-tidy) = %q", b)
+	}
+}
+
+func TestAnalyzeSourcesClangTidy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as clang-tidy")
+	}
+	t.Chdir(t.TempDir())
+	// A clang-tidy that reports a finding in the last file it's given
+	tool := filepath.Join(t.TempDir(), "clang-tidy")
+	script := "#!/bin/sh\nfor f; do last=$f; done\necho \"$last:1:1: warning: found [misc-test]\"\n"
+	if err := os.WriteFile(tool, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	sources := []string{"a.c", "b.c", "c.c", "d.c"}
+	lint := LintOptions{Tool: LintClangTidy, Checks: "misc-*"}
+	got, err := analyzeSources(tool, lint, NewCompiler("gcc"), sources, []string{"-Iinclude"}, CompileOptions{Jobs: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, d := range got {
+		files = append(files, d.File)
+	}
+	if !reflect.DeepEqual(files, sources) {
+		t.Errorf("findings in %v, want %v", files, sources)
+	}
+	// The editor's database is left alone
+	if _, err := os.Stat(filepath.Join("build", "lint", "compile_commands.json")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join("build", "compile_commands.json")); !os.IsNotExist(err) {
+		t.Errorf("lint wrote build/compile_commands.json")
+	}
+}
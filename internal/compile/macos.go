@@ -0,0 +1,61 @@
+package compile
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// macOSVersionRegex matches deployment targets such as "11", "10.15" or "13.0.1"
+var macOSVersionRegex = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
+// macOSFlags returns the deployment target and SDK flags configured in catalyst.yml.
+// Returns no flags when not building on macOS.
+func macOSFlags(cfg *config.Config) ([]string, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, nil
+	}
+
+	var flags []string
+
+	if target := cfg.MacOSDeploymentTarget; target != "" {
+		if !macOSVersionRegex.MatchString(target) {
+			return nil, fmt.Errorf("invalid macos_deployment_target %q (expected a version like 11.0)", target)
+		}
+		flags = append(flags, "-mmacosx-version-min="+target)
+	}
+
+	if sdk := cfg.MacOSSDK; sdk != "" {
+		sdkPath, err := resolveMacOSSDK(sdk)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, "-isysroot", sdkPath)
+	}
+
+	return flags, nil
+}
+
+// resolveMacOSSDK returns the path of an SDK, using xcrun for SDK names
+// (e.g. "macosx", "macosx13.3") and accepting absolute paths as-is
+func resolveMacOSSDK(sdk string) (string, error) {
+	if strings.HasPrefix(sdk, "/") {
+		return sdk, nil
+	}
+
+	if _, err := exec.LookPath("xcrun"); err != nil {
+		return "", fmt.Errorf("xcrun not found - install the Xcode Command Line Tools with: xcode-select --install")
+	}
+
+	output, err := util.Command("xcrun", "--sdk", sdk, "--show-sdk-path").Output()
+	if err != nil {
+		return "", fmt.Errorf("SDK %q not found (list installed SDKs with: xcodebuild -showsdks): %w", sdk, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
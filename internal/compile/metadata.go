@@ -0,0 +1,119 @@
+package compile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/lock"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// BuildMetadata records how a binary was produced. It is written next to the
+// binary as <output>.json after every build so release tooling can read the
+// exact inputs instead of recomputing them.
+type BuildMetadata struct {
+	BuildNumber     int            `json:"build_number"` // Increases by one with every build of this output
+	Project         string         `json:"project,omitempty"`
+	Output          string         `json:"output"`
+	SHA256          string         `json:"sha256"` // Checksum of the binary
+	Target          string         `json:"target,omitempty"`
+	Profile         string         `json:"profile,omitempty"`
+	Features        []string       `json:"features,omitempty"`
+	Compiler        string         `json:"compiler"`
+	CompilerVersion string         `json:"compiler_version,omitempty"` // First line of `<compiler> --version`
+	Flags           []string       `json:"flags"`
+	Inputs          []BuildInput   `json:"inputs"`
+	Dependencies    []BuildPackage `json:"dependencies,omitempty"` // Installed versions from catalyst.lock
+	BuiltAt         time.Time      `json:"built_at"`
+}
+
+// BuildInput is a source file compiled into the binary
+type BuildInput struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// BuildPackage is a system package the binary was built against
+type BuildPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// MetadataPath returns where the metadata of the binary at output is written
+func MetadataPath(output string) string {
+	return strings.TrimSuffix(output, ".exe") + ".json"
+}
+
+// ReadBuildMetadata reads the metadata written for the binary at output
+func ReadBuildMetadata(output string) (*BuildMetadata, error) {
+	return LoadBuildMetadata(MetadataPath(output))
+}
+
+// LoadBuildMetadata reads a build metadata file
+func LoadBuildMetadata(path string) (*BuildMetadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var meta BuildMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("invalid build metadata %s: %w", path, err)
+	}
+	return &meta, nil
+}
+
+// writeBuildMetadata records the build of output from sourceFiles with flags
+func writeBuildMetadata(meta BuildMetadata, sourceFiles []string, osKey string, opts CompileOptions) error {
+	meta.BuildNumber = 1
+	if prev, err := ReadBuildMetadata(meta.Output); err == nil {
+		meta.BuildNumber = prev.BuildNumber + 1
+	}
+	meta.Target = opts.Target
+	meta.Profile = opts.Profile
+	meta.BuiltAt = time.Now().UTC()
+
+	sum, err := util.FileSHA256(meta.Output)
+	if err != nil {
+		return err
+	}
+	meta.SHA256 = sum
+
+	compiler, err := findCompiler(opts)
+	if err != nil {
+		return err
+	}
+	meta.Compiler = compiler.Command()
+	meta.CompilerVersion = compilerVersion(compiler)
+
+	meta.Inputs = []BuildInput{}
+	for _, src := range sourceFiles {
+		sum, err := util.FileSHA256(src)
+		if err != nil {
+			return err
+		}
+		meta.Inputs = append(meta.Inputs, BuildInput{Path: filepath.ToSlash(src), SHA256: sum})
+	}
+
+	// Cross builds link the target sysroot's libraries, which aren't locked
+	if opts.Target == "" {
+		lf, err := lock.Load(lock.DefaultPath)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range lf.Packages {
+			if pkg.Platform == osKey {
+				meta.Dependencies = append(meta.Dependencies, BuildPackage{Name: pkg.Name, Version: pkg.Version})
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal build metadata: %w", err)
+	}
+	return util.WriteFileAtomic(MetadataPath(meta.Output), append(data, '\n'), 0644)
+}
@@ -0,0 +1,142 @@
+package compile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/events"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// compileParallel compiles each source to an object file using a pool of
+// opts.Jobs workers, then links the objects into output
+func compileParallel(compiler Compiler, sourceFiles []string, output string, flags []string, opts CompileOptions) error {
+	outDir := filepath.Dir(output)
+	compileFlags, linkFlags := splitFlags(flags)
+
+	objects, err := compileObjects(compiler, sourceFiles, outDir, compileFlags, opts)
+	if err != nil {
+		return err
+	}
+
+	// Link the objects
+	args := compiler.Link(objects, output, linkFlags)
+
+	command, args, err := wrapCompilerCommand(compiler.Command(), args, outDir, opts)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Linking with: %s %s\n", command, args)
+	start := time.Now()
+	if err := runCompiler(command, args); err != nil {
+		err = fmt.Errorf("linking failed: %w", err)
+		linkFinished(output, start, err)
+		return err
+	}
+	linkFinished(output, start, nil)
+	return nil
+}
+
+// compileObjects compiles each source to an object file in outDir/obj using
+// a pool of opts.Jobs workers and returns the objects. Output of each
+// compiler invocation is printed as a whole so messages don't interleave.
+func compileObjects(compiler Compiler, sourceFiles []string, outDir string, compileFlags []string, opts CompileOptions) ([]string, error) {
+	objDir := filepath.Join(outDir, "obj")
+	if err := os.MkdirAll(objDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create object directory: %w", err)
+	}
+	jobs := max(opts.Jobs, 1)
+
+	objects := make([]string, len(sourceFiles))
+	for i, src := range sourceFiles {
+		objects[i] = filepath.Join(objDir, objectName(src, compiler.ObjectExt()))
+	}
+
+	log.Infof("Compiling %d source files with %d jobs\n", len(sourceFiles), jobs)
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed []string
+	)
+	work := make(chan int)
+
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				args := compiler.CompileObject(sourceFiles[i], objects[i], sourceCompileFlags(sourceFiles[i], compileFlags, opts))
+				command, args, err := wrapCompilerCommand(compiler.Command(), args, outDir, opts)
+
+				var out []byte
+				start := time.Now()
+				if err == nil {
+					out, err = util.Command(command, args...).CombinedOutput()
+				}
+
+				mu.Lock()
+				events.Emit(events.Event{Kind: events.FileCompiled, Name: sourceFiles[i], Output: objects[i],
+					Duration: time.Since(start), Error: events.Err(err)})
+				reportCompilerOutput(out)
+				if err != nil {
+					failed = append(failed, sourceFiles[i])
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range sourceFiles {
+		mu.Lock()
+		stop := len(failed) > 0
+		mu.Unlock()
+		// Don't start new files once one has failed
+		if stop {
+			break
+		}
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("compilation failed: %s", strings.Join(failed, ", "))
+	}
+	return objects, nil
+}
+
+// sourceCompileFlags returns the flags src is compiled with: compileFlags,
+// then the compile flags file_flags: adds to it, which win over the
+// project's like those of a profile do
+func sourceCompileFlags(src string, compileFlags []string, opts CompileOptions) []string {
+	extra := config.FlagsForFile(opts.FileFlags, src)
+	if len(extra) == 0 {
+		return compileFlags
+	}
+	flags, _ := splitFlags(slices.Concat(compileFlags, extra))
+	return flags
+}
+
+// hasFileFlags reports whether file_flags: adds flags to any of sourceFiles
+func hasFileFlags(sourceFiles []string, opts CompileOptions) bool {
+	return slices.ContainsFunc(sourceFiles, func(src string) bool {
+		return len(config.FlagsForFile(opts.FileFlags, src)) > 0
+	})
+}
+
+// objectName returns a unique object file name with extension ext for a
+// source path
+func objectName(src, ext string) string {
+	name := filepath.ToSlash(filepath.Clean(src))
+	name = strings.NewReplacer("../", "__/", "/", "_", ":", "_").Replace(name)
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ext
+}
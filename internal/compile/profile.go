@@ -0,0 +1,36 @@
+package compile
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+)
+
+// defaultProfiles are used when catalyst.yml doesn't define a profile by that name
+var defaultProfiles = map[string]config.BuildProfile{
+	"debug":   {Flags: []string{"-g", "-O0"}},
+	"release": {Flags: []string{"-O2", "-DNDEBUG"}},
+}
+
+// profileFlags returns the flags of a build profile defined in the profiles:
+// section of catalyst.yml, falling back to the built-in debug and release profiles
+func profileFlags(cfg *config.Config, name string) ([]string, error) {
+	if profile, ok := cfg.Profiles[name]; ok {
+		return profile.Flags, nil
+	}
+	if profile, ok := defaultProfiles[name]; ok {
+		return profile.Flags, nil
+	}
+
+	known := slices.Sorted(maps.Keys(cfg.Profiles))
+	for builtin := range defaultProfiles {
+		if !slices.Contains(known, builtin) {
+			known = append(known, builtin)
+		}
+	}
+	slices.Sort(known)
+	return nil, fmt.Errorf("no profile %s in catalyst.yml (available: %s)", name, strings.Join(known, ", "))
+}
@@ -0,0 +1,83 @@
+package compile
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// sandboxCommand wraps a command so that it can only write to the given
+// directories (plus temporary directories the compiler needs).
+// Uses bwrap or firejail on Linux and sandbox-exec on macOS.
+func sandboxCommand(name string, args []string, writableDirs []string) (string, []string, error) {
+	var absDirs []string
+	for _, dir := range writableDirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to resolve sandbox path %s: %w", dir, err)
+		}
+		absDirs = append(absDirs, abs)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if _, err := exec.LookPath("bwrap"); err == nil {
+			return "bwrap", bwrapArgs(name, args, absDirs), nil
+		}
+		if _, err := exec.LookPath("firejail"); err == nil {
+			return "firejail", firejailArgs(name, args, absDirs), nil
+		}
+		return "", nil, fmt.Errorf("sandbox requires bwrap or firejail - install bubblewrap with your package manager")
+
+	case "darwin":
+		if _, err := exec.LookPath("sandbox-exec"); err != nil {
+			return "", nil, fmt.Errorf("sandbox-exec not found")
+		}
+		sandboxArgs := append([]string{"-p", sandboxExecProfile(absDirs), name}, args...)
+		return "sandbox-exec", sandboxArgs, nil
+
+	default:
+		return "", nil, fmt.Errorf("sandboxed builds are not supported on %s", runtime.GOOS)
+	}
+}
+
+// bwrapArgs mounts the filesystem read-only and binds the writable directories on top
+func bwrapArgs(name string, args []string, writableDirs []string) []string {
+	bwrap := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	}
+	for _, dir := range writableDirs {
+		bwrap = append(bwrap, "--bind", dir, dir)
+	}
+	bwrap = append(bwrap, "--", name)
+	return append(bwrap, args...)
+}
+
+// firejailArgs makes the project read-only except for the writable directories
+func firejailArgs(name string, args []string, writableDirs []string) []string {
+	firejail := []string{"--quiet", "--noprofile", "--private-tmp", "--read-only=/"}
+	for _, dir := range writableDirs {
+		firejail = append(firejail, "--read-write="+dir)
+	}
+	firejail = append(firejail, "--", name)
+	return append(firejail, args...)
+}
+
+// sandboxExecProfile builds a macOS sandbox profile denying writes outside the writable directories
+func sandboxExecProfile(writableDirs []string) string {
+	var sb strings.Builder
+	sb.WriteString("(version 1)(allow default)(deny file-write*)")
+	sb.WriteString("(allow file-write* (literal \"/dev/null\") (subpath \"/private/tmp\") (subpath \"/private/var/folders\")")
+	for _, dir := range writableDirs {
+		// Escape characters that are special in sandbox profile strings
+		escaped := strings.ReplaceAll(strings.ReplaceAll(dir, `\`, `\\`), `"`, `\"`)
+		sb.WriteString(fmt.Sprintf(" (subpath \"%s\")", escaped))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+package compile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// sharedLibraryName returns the file name of the shared library called name
+// on osKey
+func sharedLibraryName(name, osKey string) string {
+	switch osKey {
+	case "windows":
+		return name + ".dll"
+	case "darwin":
+		return "lib" + name + ".dylib"
+	default:
+		return "lib" + name + ".so"
+	}
+}
+
+// sharedLibraryFlags returns the flags that build cfg as a shared library,
+// generating its export header first if it has one. With an export header
+// only symbols marked with the export macro are exported.
+func sharedLibraryFlags(cfg *config.Config, osKey string) ([]string, error) {
+	flags := []string{"-shared"}
+	if osKey != "windows" {
+		flags = append(flags, "-fPIC")
+	}
+	if cfg.ExportHeader == "" {
+		return flags, nil
+	}
+
+	prefix := exportPrefix(cfg.ProjectName)
+	if err := writeExportHeader(cfg.ExportHeader, prefix); err != nil {
+		return nil, err
+	}
+	flags = append(flags, "-D"+prefix+"_BUILDING", "-I"+filepath.Dir(cfg.ExportHeader))
+	// Windows DLLs only export what is marked dllexport anyway
+	if osKey != "windows" {
+		flags = append(flags, "-fvisibility=hidden")
+	}
+	return flags, nil
+}
+
+// exportPrefix returns the macro prefix for a project: MY_LIB for my-lib
+func exportPrefix(project string) string {
+	prefix := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, project)
+	if prefix == "" || prefix[0] >= '0' && prefix[0] <= '9' {
+		prefix = "LIB_" + prefix
+	}
+	return prefix
+}
+
+var exportHeaderTemplate = template.Must(template.New("export").Parse(`/* Generated by catalyst from export_header in catalyst.yml; do not edit.
+ *
+ * Mark the library's public functions and variables with {{.}}_API.
+ * Programs linking the static library instead define {{.}}_STATIC.
+ */
+#ifndef {{.}}_EXPORT_H
+#define {{.}}_EXPORT_H
+
+#if defined({{.}}_STATIC)
+#  define {{.}}_API
+#elif defined(_WIN32) || defined(__CYGWIN__)
+#  if defined({{.}}_BUILDING)
+#    define {{.}}_API __declspec(dllexport)
+#  else
+#    define {{.}}_API __declspec(dllimport)
+#  endif
+#elif defined(__GNUC__) && __GNUC__ >= 4
+#  define {{.}}_API __attribute__((visibility("default")))
+#else
+#  define {{.}}_API
+#endif
+
+#endif /* {{.}}_EXPORT_H */
+`))
+
+// writeExportHeader writes the export header to path, leaving it untouched
+// when it is current so it doesn't trigger rebuilds
+func writeExportHeader(path, prefix string) error {
+	var b bytes.Buffer
+	if err := exportHeaderTemplate.Execute(&b, prefix); err != nil {
+		return err
+	}
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, b.Bytes()) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := util.WriteFileAtomic(path, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write export header: %w", err)
+	}
+	log.Infof("Generated %s (%s_API)\n", path, prefix)
+	return nil
+}
@@ -0,0 +1,214 @@
+package compile
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	install "github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// TestResult is the outcome of building and running one test target
+type TestResult struct {
+	Name     string
+	Passed   bool
+	Stage    string // "build" or "run" when the test failed
+	Duration time.Duration
+	Err      error
+}
+
+// RunTests builds each test target from catalyst.yml into build/tests/, runs
+// it and prints a summary. Only the named tests run when names is non-empty.
+// An error is returned if any test fails to build or exits non-zero.
+func RunTests(names []string, opts CompileOptions) error {
+	return runTests(names, "", opts)
+}
+
+// RunTestsWithCoverage runs the tests like RunTests, built with coverage
+// instrumentation into build/coverage/, then writes a coverage report in
+// format (CoverageHTML or CoverageLcov). The report covers the tests that
+// failed too.
+func RunTestsWithCoverage(names []string, format string, opts CompileOptions) error {
+	return runTests(names, format, opts)
+}
+
+// runTests runs the tests, measuring coverage when coverageFormat is set
+func runTests(names []string, coverageFormat string, opts CompileOptions) error {
+	if coverageFormat != "" && coverageFormat != CoverageHTML && coverageFormat != CoverageLcov {
+		return fmt.Errorf("unknown coverage format %q (use %s or %s)", coverageFormat, CoverageHTML, CoverageLcov)
+	}
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load catalyst.yml: %w", err)
+	}
+
+	tests, err := selectTests(cfg.Tests, names)
+	if err != nil {
+		return err
+	}
+	if len(tests) == 0 {
+		return fmt.Errorf("no tests defined in catalyst.yml (add a tests: section)")
+	}
+
+	// Tests are built with the project's flags and dependencies
+	flags := cfg.GetFlags()
+	macFlags, err := macOSFlags(cfg)
+	if err != nil {
+		return err
+	}
+	flags = append(flags, macFlags...)
+
+	if opts.Launcher == "" {
+		opts.Launcher = cfg.CompilerLauncher
+	}
+	opts.Sandbox = opts.Sandbox || cfg.Sandbox
+	if opts.Jobs == 0 {
+		opts.Jobs = cfg.Jobs
+	}
+	opts.FileFlags = cfg.FileFlags
+
+	if len(cfg.Generators) > 0 {
+		log.Info("Running code generators...")
+		_, genIncludes, err := RunGenerators(cfg.Generators, opts)
+		if err != nil {
+			return fmt.Errorf("code generation failed: %w", err)
+		}
+		flags = append(flags, genIncludes...)
+	}
+
+	log.Info("Installing dependencies...")
+	linkerFlags, err := install.InstallDependenciesAndGetLinkerFlags()
+	if err != nil {
+		return err
+	}
+	flags = append(flags, linkerFlags...)
+
+	var cov *coverage
+	if coverageFormat != "" {
+		compiler, err := findCompiler(opts)
+		if err != nil {
+			return err
+		}
+		if cov, err = newCoverage(compiler, coverageFormat); err != nil {
+			return err
+		}
+		flags = append(flags, cov.flags()...)
+	}
+
+	var results []TestResult
+	for _, test := range tests {
+		log.Info()
+		log.Infof("━━━ %s ━━━\n", test.Name)
+		results = append(results, runTest(test, flags, opts, cov))
+	}
+
+	err = printTestSummary(results)
+	if cov != nil {
+		if reportErr := cov.report(); reportErr != nil && err == nil {
+			return reportErr
+		} else if reportErr != nil {
+			log.Warn(reportErr)
+		}
+	}
+	return err
+}
+
+// selectTests returns the tests with the given names, or all tests
+func selectTests(tests []config.TestTarget, names []string) ([]config.TestTarget, error) {
+	for _, test := range tests {
+		if test.Name == "" || len(test.Sources) == 0 {
+			return nil, fmt.Errorf("every test in catalyst.yml needs a name and sources")
+		}
+	}
+	if len(names) == 0 {
+		return tests, nil
+	}
+
+	var selected []config.TestTarget
+	for _, name := range names {
+		found := false
+		for _, test := range tests {
+			if test.Name == name {
+				selected = append(selected, test)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no test named %s in catalyst.yml", name)
+		}
+	}
+	return selected, nil
+}
+
+// runTest compiles and runs a single test binary, built for cov if it's
+// not nil
+func runTest(test config.TestTarget, flags []string, opts CompileOptions, cov *coverage) TestResult {
+	start := time.Now()
+	result := TestResult{Name: test.Name}
+
+	output := util.BuildDir("tests", test.Name)
+	if runtime.GOOS == "windows" {
+		output += ".exe"
+	}
+	if cov != nil {
+		output = cov.output(test.Name)
+	}
+
+	testFlags := append(append([]string{}, flags...), test.Flags...)
+	for _, lib := range test.Libs {
+		testFlags = append(testFlags, "-l"+lib)
+	}
+
+	if err := CompileCWithOptions(test.Sources, output, testFlags, opts); err != nil {
+		result.Stage, result.Err = "build", err
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	if cov != nil {
+		cov.binaries = append(cov.binaries, output)
+	}
+
+	cmd := util.Command(util.Runnable(output), test.Args...)
+	if cov != nil {
+		cmd.Env = cov.env(test.Name)
+	}
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
+	if err := cmd.Run(); err != nil {
+		result.Stage, result.Err = "run", err
+	} else {
+		result.Passed = true
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// printTestSummary reports each result and returns an error if any test failed
+func printTestSummary(results []TestResult) error {
+	log.Info()
+	log.Info("Test Summary")
+	log.Info("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+
+	failed := 0
+	for _, r := range results {
+		duration := r.Duration.Round(time.Millisecond)
+		if r.Passed {
+			log.Infof("  ✅ PASS  %s (%s)\n", r.Name, duration)
+			continue
+		}
+		failed++
+		log.Errorf("  ❌ FAIL  %s (%s failed: %v)\n", r.Name, r.Stage, r.Err)
+	}
+
+	log.Info()
+	log.Infof("%d passed, %d failed, %d total\n", len(results)-failed, failed, len(results))
+	if failed > 0 {
+		return fmt.Errorf("%d test(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,434 @@
+package compile
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Compiler drives one toolchain. Flags from catalyst.yml, profiles and
+// pkg-config are GCC-style; each toolchain translates them to its own
+// syntax. Methods return arguments rather than running anything, so every
+// invocation goes through the launcher and sandbox the same way.
+type Compiler interface {
+	// Command is the executable, e.g. "gcc", "aarch64-linux-gnu-gcc" or "cl"
+	Command() string
+	// Version is the first line of the compiler's version banner, or "" if
+	// it can't be run
+	Version() string
+	// ObjectExt is the extension of object files (".o" or ".obj")
+	ObjectExt() string
+	// CompileObject returns the arguments that compile src into object
+	CompileObject(src, object string, flags []string) []string
+	// Link returns the arguments that build output from inputs, which may
+	// be sources (compiled and linked in one invocation) or objects
+	Link(inputs []string, output string, flags []string) []string
+	// ArchiveName is the file name of the static library called name
+	// (libname.a or name.lib)
+	ArchiveName(name string) string
+	// Archive returns the command, archiver first, that bundles objects
+	// into the static library output
+	Archive(objects []string, output string) []string
+	// TranslateFlag returns the toolchain's equivalent of a GCC-style flag,
+	// or nothing if it has none. Flags taking a separate value (-include,
+	// -isystem, -framework) are passed with it as "-include file".
+	TranslateFlag(flag string) []string
+	// ProbeFeature reports whether the compiler accepts a GCC-style flag
+	ProbeFeature(flag string) bool
+}
+
+// compilerKinds maps compiler names, without a cross prefix, version suffix
+// or .exe, to the toolchain that drives them. Compilers not listed are
+// assumed to be GCC-compatible (tcc, icx, ...).
+var compilerKinds = map[string]func(command string) Compiler{
+	"gcc":      newGNUCompiler,
+	"g++":      newGNUCompiler,
+	"cc":       newGNUCompiler,
+	"c++":      newGNUCompiler,
+	"clang":    newGNUCompiler,
+	"clang++":  newGNUCompiler,
+	"emcc":     newEmscriptenCompiler,
+	"em++":     newEmscriptenCompiler,
+	"cl":       newMSVCCompiler,
+	"clang-cl": newClangCLCompiler,
+}
+
+// versionSuffix matches the version of versioned compilers (gcc-13, clang-17)
+var versionSuffix = regexp.MustCompile(`-\d+(\.\d+)*$`)
+
+// NewCompiler returns the toolchain for a compiler command
+func NewCompiler(command string) Compiler {
+	return compilerKinds[compilerKind(command)](command)
+}
+
+// compilerKind returns the compilerKinds entry for a command, which is gcc
+// for compilers catalyst doesn't know
+func compilerKind(command string) string {
+	if kind, ok := knownCompiler(command); ok {
+		return kind
+	}
+	return "gcc"
+}
+
+// knownCompiler returns the compilerKinds entry for a command: its name, or
+// the name after a cross prefix (aarch64-linux-gnu-gcc is gcc)
+func knownCompiler(command string) (string, bool) {
+	// Windows paths can appear in catalyst.yml on any host
+	name := strings.ToLower(filepath.Base(strings.ReplaceAll(command, `\`, "/")))
+	name = strings.TrimSuffix(name, ".exe")
+	name = versionSuffix.ReplaceAllString(name, "")
+	if _, ok := compilerKinds[name]; ok {
+		return name, true
+	}
+	if i := strings.LastIndex(name, "-"); i >= 0 {
+		if _, ok := compilerKinds[name[i+1:]]; ok {
+			return name[i+1:], true
+		}
+	}
+	return "", false
+}
+
+// pairFlags take their value as the next argument
+var pairFlags = []string{
+	"-include", "-isystem", "-framework", "-Xlinker",
+	"-isysroot", "-arch", "-target", "-Xpreprocessor", "-Xclang", "-Xassembler",
+}
+
+// joinPairFlags joins flags taking a separate value with it, the form
+// TranslateFlag expects
+func joinPairFlags(flags []string) []string {
+	joined := make([]string, 0, len(flags))
+	for i := 0; i < len(flags); i++ {
+		if isPairFlag(flags[i]) && i+1 < len(flags) {
+			joined = append(joined, flags[i]+" "+flags[i+1])
+			i++
+			continue
+		}
+		joined = append(joined, flags[i])
+	}
+	return joined
+}
+
+func isPairFlag(flag string) bool {
+	for _, pair := range pairFlags {
+		if flag == pair {
+			return true
+		}
+	}
+	return false
+}
+
+// cutPairFlag splits a flag joined by joinPairFlags
+func cutPairFlag(flag string) (name, value string, ok bool) {
+	name, value, ok = strings.Cut(flag, " ")
+	if !ok || !isPairFlag(name) {
+		return "", "", false
+	}
+	return name, value, true
+}
+
+// translateFlags orders and deduplicates GCC-style flags and translates
+// them for c
+func translateFlags(c Compiler, flags []string) []string {
+	var translated []string
+	for _, flag := range joinPairFlags(orderFlags(flags)) {
+		translated = append(translated, c.TranslateFlag(flag)...)
+	}
+	return translated
+}
+
+var (
+	probeMu    sync.Mutex
+	probeCache = make(map[string]bool)
+)
+
+// probeCompile compiles an empty program with args (which name the source
+// and object placeholders "{src}" and "{obj}") and reports whether it
+// succeeded and accept says the output is clean. Results are cached per
+// command line.
+func probeCompile(command string, args []string, accept func(output string) bool) bool {
+	key := command + " " + strings.Join(args, " ")
+	probeMu.Lock()
+	defer probeMu.Unlock()
+	if ok, cached := probeCache[key]; cached {
+		return ok
+	}
+
+	ok := false
+	if src, err := util.TempPath("probe.c"); err == nil {
+		if err := os.WriteFile(src, []byte("int main(void) { return 0; }\n"), 0644); err == nil {
+			obj := strings.TrimSuffix(src, ".c") + ".obj"
+			expanded := make([]string, len(args))
+			for i, arg := range args {
+				expanded[i] = strings.NewReplacer("{src}", src, "{obj}", obj).Replace(arg)
+			}
+			out, err := util.Command(command, expanded...).CombinedOutput()
+			ok = err == nil && (accept == nil || accept(string(out)))
+		}
+		os.RemoveAll(filepath.Dir(src))
+	}
+	probeCache[key] = ok
+	return ok
+}
+
+// firstLine returns the first line of a command's combined output, or ""
+func firstLine(command string, args ...string) string {
+	out, err := util.ParsedCommand(command, args...).CombinedOutput()
+	if err != nil && len(out) == 0 {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line)
+}
+
+// gnuCompiler drives GCC, Clang and compilers with the same command line,
+// including cross compilers such as aarch64-linux-gnu-gcc
+type gnuCompiler struct {
+	command string
+}
+
+func newGNUCompiler(command string) Compiler {
+	return gnuCompiler{command: command}
+}
+
+func (c gnuCompiler) Command() string   { return c.command }
+func (c gnuCompiler) ObjectExt() string { return ".o" }
+
+func (c gnuCompiler) Version() string {
+	return firstLine(c.command, "--version")
+}
+
+func (c gnuCompiler) CompileObject(src, object string, flags []string) []string {
+	return append([]string{"-c", src, "-o", object}, translateFlags(c, flags)...)
+}
+
+func (c gnuCompiler) Link(inputs []string, output string, flags []string) []string {
+	args := append([]string{"-o", output}, inputs...)
+	return append(args, translateFlags(c, flags)...)
+}
+
+func (c gnuCompiler) ArchiveName(name string) string { return "lib" + name + ".a" }
+
+func (c gnuCompiler) Archive(objects []string, output string) []string {
+	return append([]string{c.archiver(), "rcs", output}, objects...)
+}
+
+// archiver returns the ar matching the compiler: cross compilers come with
+// their own (aarch64-linux-gnu-gcc uses aarch64-linux-gnu-ar)
+func (c gnuCompiler) archiver() string {
+	dir := ""
+	if strings.ContainsAny(c.command, `/\`) {
+		dir = filepath.Dir(c.command)
+	}
+	name := strings.TrimSuffix(filepath.Base(c.command), ".exe")
+	name = versionSuffix.ReplaceAllString(name, "")
+	kind, _ := knownCompiler(name)
+	if prefix, ok := strings.CutSuffix(name, kind); ok && kind != "" && prefix != "" {
+		return filepath.Join(dir, prefix+"ar")
+	}
+	return "ar"
+}
+
+func (c gnuCompiler) TranslateFlag(flag string) []string {
+	if name, value, ok := cutPairFlag(flag); ok {
+		return []string{name, value}
+	}
+	return []string{flag}
+}
+
+func (c gnuCompiler) ProbeFeature(flag string) bool {
+	// -Werror turns Clang's warnings about unknown -W options into errors
+	args := append([]string{"-Werror"}, c.TranslateFlag(flag)...)
+	return probeCompile(c.command, append(args, "-c", "{src}", "-o", "{obj}"), nil)
+}
+
+// emscriptenCompiler drives emcc, which takes GCC flags but builds
+// WebAssembly, so host-specific flags are dropped
+type emscriptenCompiler struct {
+	gnuCompiler
+}
+
+func newEmscriptenCompiler(command string) Compiler {
+	return emscriptenCompiler{gnuCompiler{command: command}}
+}
+
+func (c emscriptenCompiler) CompileObject(src, object string, flags []string) []string {
+	return append([]string{"-c", src, "-o", object}, translateFlags(c, flags)...)
+}
+
+func (c emscriptenCompiler) Link(inputs []string, output string, flags []string) []string {
+	args := append([]string{"-o", output}, inputs...)
+	return append(args, translateFlags(c, flags)...)
+}
+
+func (c emscriptenCompiler) Archive(objects []string, output string) []string {
+	return append([]string{"emar", "rcs", output}, objects...)
+}
+
+func (c emscriptenCompiler) TranslateFlag(flag string) []string {
+	switch {
+	case flag == "-fopenmp", flag == "-rdynamic", strings.HasPrefix(flag, "-march="), strings.HasPrefix(flag, "-mtune="):
+		log.Debugf("Dropping %s, which Emscripten doesn't support\n", flag)
+		return nil
+	}
+	return c.gnuCompiler.TranslateFlag(flag)
+}
+
+func (c emscriptenCompiler) ProbeFeature(flag string) bool {
+	if c.TranslateFlag(flag) == nil {
+		return false
+	}
+	return c.gnuCompiler.ProbeFeature(flag)
+}
+
+// msvcCompiler drives MSVC's cl and clang-cl, which take /-style options and
+// pass linker options after /link
+type msvcCompiler struct {
+	command string
+	clang   bool // clang-cl, which also accepts most GCC-style -f and -m flags
+}
+
+func newMSVCCompiler(command string) Compiler {
+	return msvcCompiler{command: command}
+}
+
+func newClangCLCompiler(command string) Compiler {
+	return msvcCompiler{command: command, clang: true}
+}
+
+func (c msvcCompiler) Command() string   { return c.command }
+func (c msvcCompiler) ObjectExt() string { return ".obj" }
+
+func (c msvcCompiler) Version() string {
+	if c.clang {
+		return firstLine(c.command, "--version")
+	}
+	// cl prints its banner when run without arguments
+	return firstLine(c.command)
+}
+
+func (c msvcCompiler) ArchiveName(name string) string { return name + ".lib" }
+
+func (c msvcCompiler) Archive(objects []string, output string) []string {
+	archiver := "lib"
+	if c.clang {
+		archiver = "llvm-lib"
+	}
+	return append([]string{archiver, "/nologo", "/OUT:" + output}, objects...)
+}
+
+func (c msvcCompiler) CompileObject(src, object string, flags []string) []string {
+	compileFlags, _ := c.splitTranslated(flags)
+	return append([]string{"/nologo", "/c", src, "/Fo" + object}, compileFlags...)
+}
+
+func (c msvcCompiler) Link(inputs []string, output string, flags []string) []string {
+	compileFlags, linkFlags := c.splitTranslated(flags)
+	args := append([]string{"/nologo"}, inputs...)
+	args = append(args, "/Fe"+output)
+	args = append(args, compileFlags...)
+	if len(linkFlags) > 0 {
+		args = append(append(args, "/link"), linkFlags...)
+	}
+	return args
+}
+
+// splitTranslated translates flags and separates the compiler's options
+// from the linker's, which go after /link: library paths and libraries
+func (c msvcCompiler) splitTranslated(flags []string) (compileFlags, linkFlags []string) {
+	for _, flag := range translateFlags(c, flags) {
+		if strings.HasPrefix(flag, "/LIBPATH:") || !strings.HasPrefix(flag, "/") && !strings.HasPrefix(flag, "-") {
+			linkFlags = append(linkFlags, flag)
+		} else {
+			compileFlags = append(compileFlags, flag)
+		}
+	}
+	return compileFlags, linkFlags
+}
+
+// msvcFlags are GCC flags with a direct MSVC equivalent
+var msvcFlags = map[string]string{
+	"-O0":      "/Od",
+	"-O1":      "/O1",
+	"-O2":      "/O2",
+	"-O3":      "/O2",
+	"-Os":      "/O1",
+	"-g":       "/Zi",
+	"-Wall":    "/W4",
+	"-Werror":  "/WX",
+	"-w":       "/w",
+	"-fopenmp": "/openmp",
+	"-shared":  "/LD",
+}
+
+// msvcIgnoredFlags have no MSVC equivalent and nothing to translate to:
+// threads, position-independent code, symbol visibility (DLLs only export
+// what is marked dllexport) and GCC-only warnings
+var msvcIgnoredFlags = map[string]bool{
+	"-pthread": true, "-fPIC": true, "-fpic": true, "-rdynamic": true, "-fvisibility=hidden": true,
+	"-pedantic": true, "-Wextra": true, "-Wpedantic": true,
+}
+
+func (c msvcCompiler) TranslateFlag(flag string) []string {
+	if name, value, ok := cutPairFlag(flag); ok {
+		switch name {
+		case "-include":
+			return []string{"/FI" + value}
+		case "-isystem":
+			return []string{"/I" + value}
+		}
+		log.Debugf("Dropping %s, which has no MSVC equivalent\n", flag)
+		return nil
+	}
+	if translated, ok := msvcFlags[flag]; ok {
+		return []string{translated}
+	}
+
+	switch {
+	case strings.HasPrefix(flag, "/") || !strings.HasPrefix(flag, "-"):
+		// Already an MSVC option, or a source, object or library file
+		return []string{flag}
+	case msvcIgnoredFlags[flag]:
+		return nil
+	case strings.HasPrefix(flag, "-I"), strings.HasPrefix(flag, "-D"), strings.HasPrefix(flag, "-U"):
+		return []string{"/" + flag[1:]}
+	case strings.HasPrefix(flag, "-L"):
+		return []string{"/LIBPATH:" + flag[2:]}
+	case strings.HasPrefix(flag, "-l"):
+		lib := flag[2:]
+		if !strings.HasSuffix(strings.ToLower(lib), ".lib") {
+			lib += ".lib"
+		}
+		return []string{lib}
+	case strings.HasPrefix(flag, "-std="):
+		// cl only knows the standards it implements; C99 and the GNU
+		// dialects are the default behavior
+		std := strings.TrimPrefix(flag, "-std=")
+		switch std {
+		case "c11", "c17", "clatest", "c++14", "c++17", "c++20", "c++latest":
+			return []string{"/std:" + std}
+		}
+		return nil
+	case c.clang && (strings.HasPrefix(flag, "-f") || strings.HasPrefix(flag, "-m") || strings.HasPrefix(flag, "-W")):
+		return []string{flag}
+	}
+	log.Debugf("Dropping %s, which has no MSVC equivalent\n", flag)
+	return nil
+}
+
+func (c msvcCompiler) ProbeFeature(flag string) bool {
+	translated := c.TranslateFlag(flag)
+	if translated == nil {
+		return false
+	}
+	args := append([]string{"/nologo", "/WX", "/c", "{src}", "/Fo{obj}"}, translated...)
+	// cl only warns about unknown options (D9002), even with /WX
+	return probeCompile(c.command, args, func(output string) bool {
+		return !strings.Contains(output, "D9002")
+	})
+}
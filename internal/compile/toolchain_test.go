@@ -0,0 +1,396 @@
+package compile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/guard"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+func TestNewCompiler(t *testing.T) {
+	tests := map[string]string{
+		"gcc":                        "gcc",
+		"clang-17":                   "clang",
+		"aarch64-linux-gnu-gcc":      "gcc",
+		"x86_64-w64-mingw32-gcc-13":  "gcc",
+		`C:\LLVM\bin\clang-cl.exe`:   "clang-cl",
+		"CL.EXE":                     "cl",
+		"emcc":                       "emcc",
+		"tcc":                        "gcc",
+		"/opt/cross/bin/arm-none-cc": "cc",
+	}
+	for command, want := range tests {
+		if got := compilerKind(command); got != want {
+			t.Errorf("compilerKind(%q) = %q, want %q", command, got, want)
+		}
+	}
+
+	if ext := NewCompiler("cl").ObjectExt(); ext != ".obj" {
+		t.Errorf("cl object extension = %q, want .obj", ext)
+	}
+	if ext := NewCompiler("aarch64-linux-gnu-gcc").ObjectExt(); ext != ".o" {
+		t.Errorf("gcc object extension = %q, want .o", ext)
+	}
+}
+
+func TestMSVCCompiler(t *testing.T) {
+	cl := NewCompiler("cl")
+	flags := []string{"-Iinclude", "-DNDEBUG", "-O2", "-include", "config.h", "-pthread", "-Llib", "-lssl", "-Wl,--as-needed"}
+
+	got := cl.CompileObject("src/main.c", "obj/main.obj", flags)
+	want := []string{"/nologo", "/c", "src/main.c", "/Foobj/main.obj", "/Iinclude", "/DNDEBUG", "/O2", "/FIconfig.h"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompileObject() = %q, want %q", got, want)
+	}
+
+	got = cl.Link([]string{"main.obj"}, "app.exe", flags)
+	want = []string{"/nologo", "main.obj", "/Feapp.exe", "/Iinclude", "/DNDEBUG", "/O2", "/FIconfig.h", "/link", "/LIBPATH:lib", "ssl.lib"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Link() = %q, want %q", got, want)
+	}
+
+	if got := NewCompiler("clang-cl").TranslateFlag("-fsanitize=address"); !reflect.DeepEqual(got, []string{"-fsanitize=address"}) {
+		t.Errorf("clang-cl dropped -fsanitize=address: %q", got)
+	}
+}
+
+func TestGNUCompilerArgs(t *testing.T) {
+	gcc := NewCompiler("gcc")
+	flags := []string{"-Iinclude", "-include", "config.h", "-lm"}
+	got := gcc.Link([]string{"main.c"}, "app", flags)
+	want := []string{"-o", "app", "main.c", "-Iinclude", "-include", "config.h", "-lm"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Link() = %q, want %q", got, want)
+	}
+}
+
+func TestOrderFlags(t *testing.T) {
+	flags := []string{
+		"-lm", "-Iinclude", "-O2", "-DDEBUG=0", "build/libutil.a", "-Wl,--whole-archive", "-lplugins", "-Wl,--no-whole-archive",
+		"-Llib", "-include", "config.h", "-lpng", "-Ivendor", "-Iinclude", "-DDEBUG=0", "-O0", "-lz", "-lm",
+		"-Llib", "-framework", "Cocoa", "-pthread", "-Xlinker", "-rpath", "-pthread",
+	}
+	want := []string{
+		"-Iinclude", "-Ivendor",
+		"-DDEBUG=0",
+		"-O2", "-include", "config.h", "-O0", "-pthread",
+		"-Llib", "-Xlinker", "-rpath",
+		"-lm", "build/libutil.a", "-Wl,--whole-archive", "-lplugins", "-Wl,--no-whole-archive", "-lpng", "-lz", "-lm", "-framework", "Cocoa",
+	}
+	if got := orderFlags(flags); !reflect.DeepEqual(got, want) {
+		t.Errorf("orderFlags() =\n%q\nwant\n%q", got, want)
+	}
+
+	compileFlags, linkFlags := splitFlags([]string{"-lm", "-Iinclude", "-fopenmp", "-Xlinker", "-rpath", "-lm"})
+	if want := []string{"-Iinclude", "-fopenmp"}; !reflect.DeepEqual(compileFlags, want) {
+		t.Errorf("compile flags = %q, want %q", compileFlags, want)
+	}
+	if want := []string{"-fopenmp", "-Xlinker", "-rpath", "-lm", "-lm"}; !reflect.DeepEqual(linkFlags, want) {
+		t.Errorf("link flags = %q, want %q", linkFlags, want)
+	}
+
+	tests := map[string]struct {
+		flags, want []string
+	}{
+		"-Xlinker values": {
+			[]string{"-Xlinker", "-rpath", "-Xlinker", "/a", "-Xlinker", "-rpath", "-Xlinker", "/b"},
+			[]string{"-Xlinker", "-rpath", "-Xlinker", "/a", "-Xlinker", "-rpath", "-Xlinker", "/b"},
+		},
+		"-Wl, values": {
+			[]string{"-Wl,-rpath", "-Wl,/a", "-Wl,-rpath", "-Wl,/b"},
+			[]string{"-Wl,-rpath", "-Wl,/a", "-Wl,-rpath", "-Wl,/b"},
+		},
+		"circular libraries": {
+			[]string{"-lfoo", "-lbar", "-lfoo"},
+			[]string{"-lfoo", "-lbar", "-lfoo"},
+		},
+		"repeated library paths": {
+			[]string{"-Llib", "-lfoo", "-Llib"},
+			[]string{"-Llib", "-lfoo"},
+		},
+		"-Xpreprocessor": {
+			[]string{"-Xpreprocessor", "-fopenmp", "-O2"},
+			[]string{"-Xpreprocessor", "-fopenmp", "-O2"},
+		},
+		"-isysroot": {
+			[]string{"-isysroot", "/sdk", "-Iinclude"},
+			[]string{"-Iinclude", "-isysroot", "/sdk"},
+		},
+		"-arch": {
+			[]string{"-arch", "x86_64", "-arch", "arm64", "-lz"},
+			[]string{"-arch", "x86_64", "-arch", "arm64", "-lz"},
+		},
+		"-target": {
+			[]string{"-lz", "-target", "aarch64-linux-gnu", "-O2"},
+			[]string{"-target", "aarch64-linux-gnu", "-O2", "-lz"},
+		},
+	}
+	for name, tt := range tests {
+		if got := orderFlags(tt.flags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: orderFlags(%q) = %q, want %q", name, tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	output := "main.c: In function 'main':\n" +
+		"main.c:5:16: error: 'y' undeclared (first use in this function)\n" +
+		"    5 |         return y;\n" +
+		"      |                ^\n" +
+		`C:\src\util.c:12:3: warning: implicit declaration of function 'foo'` + "\n" +
+		"util.c(7,10): error C2065: 'y': undeclared identifier\r\n" +
+		"collect2: error: ld returned 1 exit status\n" +
+		"LINK : fatal error LNK1104: cannot open file 'ssl.lib'\n"
+	want := []Diagnostic{
+		{File: "main.c", Line: 5, Column: 16, Severity: "error", Message: "'y' undeclared (first use in this function)"},
+		{File: `C:\src\util.c`, Line: 12, Column: 3, Severity: "warning", Message: "implicit declaration of function 'foo'"},
+		{File: "util.c", Line: 7, Column: 10, Severity: "error", Code: "C2065", Message: "'y': undeclared identifier"},
+		{Severity: "error", Message: "collect2: ld returned 1 exit status"},
+		{Severity: "error", Code: "LNK1104", Message: "LINK: cannot open file 'ssl.lib'"},
+	}
+	if got := ParseDiagnostics(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseDiagnostics() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestReportCompilerOutput(t *testing.T) {
+	t.Chdir(t.TempDir())
+	os.WriteFile("util.c", []byte("int f(void) {\n\treturn y;\n}\n"), 0644)
+	var out strings.Builder
+	log.SetOutput(&out, &out)
+	defer log.SetOutput(nil, nil)
+	resetDiagnostics()
+
+	reportCompilerOutput([]byte("main.c:5:16: warning: unused variable 'x'\n" +
+		"    5 |         int x;\n" +
+		"      |             ^\n" +
+		"util.c\n" +
+		"util.c(2,9): error C2065: 'y': undeclared identifier\n"))
+	want := "main.c:5:16: warning: unused variable 'x'\n" +
+		"    5 |         int x;\n" +
+		"      |             ^\n" +
+		"util.c\n" +
+		"util.c:2:9: error C2065: 'y': undeclared identifier\n" +
+		" 2 | \treturn y;\n" +
+		"   | \t       ^\n"
+	if out.String() != want {
+		t.Errorf("reported output =\n%s\nwant\n%s", out.String(), want)
+	}
+	if diagnosticCounts.errors != 1 || diagnosticCounts.warnings != 1 {
+		t.Errorf("counted %d errors and %d warnings, want 1 and 1", diagnosticCounts.errors, diagnosticCounts.warnings)
+	}
+}
+
+func TestLibraryFlags(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	meta := &BuildMetadata{
+		Output: "build/libmathlib.a",
+		Flags:  []string{"-Iinclude", "-DMATHLIB_INTERNAL", "-include", "config.h", "../util/build/libutil.a", "-L/opt/lib", "-lm"},
+	}
+	got, err := libraryFlags(filepath.Join(root, "mathlib"), meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-Imathlib", "-Imathlib/include", "mathlib/build/libmathlib.a", "util/build/libutil.a", "-L/opt/lib", "-lm"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("libraryFlags() = %q, want %q", got, want)
+	}
+}
+
+func TestSharedLibraryFlags(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := &config.Config{ProjectName: "my-lib", ExportHeader: "include/my_lib_export.h"}
+
+	got, err := sharedLibraryFlags(cfg, "linux")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-shared", "-fPIC", "-DMY_LIB_BUILDING", "-Iinclude", "-fvisibility=hidden"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sharedLibraryFlags() = %q, want %q", got, want)
+	}
+	header, err := os.ReadFile(cfg.ExportHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(header), "#    define MY_LIB_API __declspec(dllexport)") {
+		t.Errorf("export header doesn't define MY_LIB_API:\n%s", header)
+	}
+
+	if name := sharedLibraryName("my-lib", "windows"); name != "my-lib.dll" {
+		t.Errorf("windows shared library name = %q", name)
+	}
+	if name := sharedLibraryName("my-lib", "darwin"); name != "libmy-lib.dylib" {
+		t.Errorf("darwin shared library name = %q", name)
+	}
+}
+
+func TestExamples(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"examples/basic.c":          "int main(void) { return 0; }",
+		"examples/multi/main.c":     "int main(void) { return 0; }",
+		"examples/multi/util/x.c":   "int x;",
+		"examples/app/catalyst.yml": "project_name: app\n",
+		"examples/README.md":        "",
+		"examples/empty/notes.txt":  "",
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Dir(name), 0755)
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	examples, err := Examples(&config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range examples {
+		got = append(got, e.Name+":"+e.Dir+":"+filepath.ToSlash(strings.Join(e.Sources, ",")))
+	}
+	want := []string{"app:" + filepath.Join("examples", "app") + ":", "basic::examples/basic.c", "multi::examples/multi/main.c,examples/multi/util/x.c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Examples = %q, want %q", got, want)
+	}
+
+	// catalyst.yml takes precedence over examples/
+	listed := []config.ExampleTarget{{Name: "demo", Sources: []string{"demo.c"}}}
+	if examples, err := Examples(&config.Config{Examples: listed}); err != nil || !reflect.DeepEqual(examples, listed) {
+		t.Errorf("Examples with examples: = %v, %v", examples, err)
+	}
+	if _, err := Examples(&config.Config{Examples: []config.ExampleTarget{{Name: "demo"}}}); err == nil {
+		t.Error("an example without sources or dir was accepted")
+	}
+}
+
+func TestOutputDir(t *testing.T) {
+	tests := []struct {
+		opts CompileOptions
+		want string
+	}{
+		{CompileOptions{}, "build"},
+		{CompileOptions{Profile: "release"}, filepath.Join("build", "release")},
+		{CompileOptions{Target: "aarch64-linux-gnu"}, filepath.Join("build", "aarch64-linux-gnu")},
+		{CompileOptions{Profile: "debug", Target: "aarch64-linux-gnu"}, filepath.Join("build", "debug", "aarch64-linux-gnu")},
+	}
+	for _, tt := range tests {
+		if got := OutputDir(tt.opts); got != tt.want {
+			t.Errorf("OutputDir(%+v) = %s, want %s", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestToolchainEnvScript(t *testing.T) {
+	env := &ToolchainEnv{Vars: []EnvVar{{"CFLAGS", joinFlags([]string{`-DNAME="a b"`, "-O2"})}, {"MODE", "it's"}}}
+	tests := map[string]string{
+		"bash":       `export CFLAGS='"-DNAME=\"a b\"" -O2'` + "\nexport MODE='it'\\''s'\n",
+		"fish":       `set -gx CFLAGS '"-DNAME=\"a b\"" -O2'` + "\nset -gx MODE 'it'\\''s'\n",
+		"powershell": `$env:CFLAGS = '"-DNAME=\"a b\"" -O2'` + "\n$env:MODE = 'it''s'\n",
+	}
+	for shell, want := range tests {
+		script, err := env.Script(shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The first line says how to use the script
+		if _, got, _ := strings.Cut(script, "\n"); got != want {
+			t.Errorf("Script(%s) = %q, want %q", shell, got, want)
+		}
+	}
+	if _, err := env.Script("tcsh"); err == nil {
+		t.Error("Script(tcsh) succeeded")
+	}
+}
+
+func TestSourceFlags(t *testing.T) {
+	dir := filepath.FromSlash("/work/app")
+	sys := filepath.Join(dir, "third_party")
+	got := sourceFlags(dir, []string{"-Iinclude", "-std=c11", "-DDEBUG", "-isystem", sys, "-lm", "-Wall"})
+	want := SourceFlags{
+		Includes: []string{filepath.Join(dir, "include"), sys},
+		Defines:  []string{"DEBUG"},
+		Std:      "c11",
+		Flags:    []string{"-Iinclude", "-isystem", sys, "-DDEBUG", "-std=c11", "-Wall"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sourceFlags() = %+v, want %+v", got, want)
+	}
+
+	resolved := &ResolvedFlags{Directory: dir, Files: map[string]SourceFlags{"src/main.c": got}}
+	wantFlags := []string{"-I" + filepath.Join(dir, "include"), "-isystem", sys, "-DDEBUG", "-std=c11", "-Wall"}
+	for _, src := range []string{"src/main.c", filepath.Join(dir, "src", "main.c")} {
+		if flags, ok := resolved.CompileFlags(src); !ok || !reflect.DeepEqual(flags, wantFlags) {
+			t.Errorf("CompileFlags(%s) = %q, %v, want %q", src, flags, ok, wantFlags)
+		}
+	}
+	if _, ok := resolved.CompileFlags("other.c"); ok {
+		t.Error("CompileFlags() found a source that isn't the project's")
+	}
+}
+
+func TestSourceCompileFlags(t *testing.T) {
+	opts := CompileOptions{FileFlags: []config.FileFlags{
+		{Files: []string{"legacy/*.c"}, Flags: []string{"-Wno-deprecated", "-std=c89"}},
+		{Files: []string{"vendor/"}, Flags: []string{"-w"}},
+		{Files: []string{"*.cpp"}, Flags: []string{"-fno-exceptions"}},
+	}}
+	project := []string{"-Iinclude", "-Wall", "-std=c11"}
+	tests := map[string][]string{
+		"src/main.c":              project,
+		"legacy/old.c":            {"-Iinclude", "-Wall", "-std=c11", "-Wno-deprecated", "-std=c89"},
+		"legacy/sub/old.c":        project,
+		"./vendor/zlib/inflate.c": {"-Iinclude", "-Wall", "-std=c11", "-w"},
+		"vendor":                  project,
+		"src/gui/window.cpp":      {"-Iinclude", "-Wall", "-std=c11", "-fno-exceptions"},
+	}
+	for src, want := range tests {
+		if got := sourceCompileFlags(src, project, opts); !reflect.DeepEqual(got, want) {
+			t.Errorf("sourceCompileFlags(%q) = %q, want %q", src, got, want)
+		}
+	}
+	if !hasFileFlags([]string{"src/main.c", "legacy/old.c"}, opts) || hasFileFlags([]string{"src/main.c"}, opts) {
+		t.Error("hasFileFlags doesn't match the sources file_flags adds flags to")
+	}
+}
+
+func TestCleanProjectBuildRoot(t *testing.T) {
+	root := t.TempDir()
+	util.BuildRoot = root
+	guard.AssumeYes = true
+	defer func() { util.BuildRoot, guard.AssumeYes = "", false }()
+	t.Chdir(t.TempDir())
+
+	// A build root can be the project itself or shared with other projects
+	source := filepath.Join(root, "main.c")
+	other := filepath.Join(root, "other-project", "build", "app")
+	output := util.BuildDir("app")
+	for _, path := range []string{source, other, output, util.StateDir("lock")} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := CleanProject(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(util.BuildDir()); !os.IsNotExist(err) {
+		t.Errorf("clean kept %s", util.BuildDir())
+	}
+	for _, path := range []string{source, other, util.StateDir("lock")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("clean removed %s", path)
+		}
+	}
+}
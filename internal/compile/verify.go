@@ -0,0 +1,158 @@
+package compile
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// CheckStatus is the outcome of one artifact check
+type CheckStatus int
+
+const (
+	CheckPassed CheckStatus = iota
+	CheckFailed
+	CheckSkipped
+)
+
+// ArtifactCheck is one result of VerifyArtifact
+type ArtifactCheck struct {
+	Name   string // What was checked, e.g. "source main.c"
+	Status CheckStatus
+	Detail string
+}
+
+// VerifyArtifact checks a built binary against its build metadata: the
+// binary and source checksums, the installed versions of the recorded
+// dependencies, and whether the shared libraries it needs can be found.
+// An error means the metadata itself couldn't be read.
+func VerifyArtifact(binary, metadataPath string) ([]ArtifactCheck, error) {
+	if metadataPath == "" {
+		metadataPath = MetadataPath(binary)
+	}
+	meta, err := LoadBuildMetadata(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read build metadata: %w", err)
+	}
+
+	checks := []ArtifactCheck{checkChecksum("binary "+binary, binary, meta.SHA256)}
+	for _, input := range meta.Inputs {
+		checks = append(checks, checkChecksum("source "+input.Path, input.Path, input.SHA256))
+	}
+
+	// Binaries cross-compiled for another platform can't be checked here
+	if meta.Target != "" {
+		return append(checks, ArtifactCheck{Name: "dependencies and shared libraries", Status: CheckSkipped,
+			Detail: "cross-compiled for " + meta.Target}), nil
+	}
+	checks = append(checks, checkDependencies(meta.Dependencies)...)
+	return append(checks, checkSharedLibraries(binary)), nil
+}
+
+// checkChecksum compares the sha256 of path with the recorded one
+func checkChecksum(name, path, recorded string) ArtifactCheck {
+	sum, err := util.FileSHA256(path)
+	switch {
+	case os.IsNotExist(err):
+		return ArtifactCheck{Name: name, Status: CheckFailed, Detail: "missing"}
+	case err != nil:
+		return ArtifactCheck{Name: name, Status: CheckFailed, Detail: err.Error()}
+	case sum != recorded:
+		return ArtifactCheck{Name: name, Status: CheckFailed, Detail: fmt.Sprintf("changed since the build (recorded %.12s, now %.12s)", recorded, sum)}
+	}
+	return ArtifactCheck{Name: name, Status: CheckPassed, Detail: "unchanged"}
+}
+
+// checkDependencies checks that each recorded package is installed at the
+// recorded version
+func checkDependencies(deps []BuildPackage) []ArtifactCheck {
+	if len(deps) == 0 {
+		return nil
+	}
+	pkgManager, err := platform.DetectPackageManager(runtime.GOOS)
+	if err != nil {
+		return []ArtifactCheck{{Name: "dependencies", Status: CheckSkipped, Detail: err.Error()}}
+	}
+
+	var checks []ArtifactCheck
+	for _, dep := range deps {
+		name := "package " + dep.Name
+		installed, ok := platform.InstalledVersion(dep.Name, pkgManager)
+		switch {
+		case dep.Version == "":
+			checks = append(checks, ArtifactCheck{Name: name, Status: CheckSkipped, Detail: "no version recorded"})
+		case !ok:
+			checks = append(checks, ArtifactCheck{Name: name, Status: CheckFailed, Detail: "not installed (built with " + dep.Version + ")"})
+		case installed != dep.Version:
+			checks = append(checks, ArtifactCheck{Name: name, Status: CheckFailed, Detail: fmt.Sprintf("installed %s, built with %s", installed, dep.Version)})
+		default:
+			checks = append(checks, ArtifactCheck{Name: name, Status: CheckPassed, Detail: installed})
+		}
+	}
+	return checks
+}
+
+// checkSharedLibraries asks the dynamic loader tools whether every shared
+// library the binary links against can be found
+func checkSharedLibraries(binary string) ArtifactCheck {
+	const name = "shared libraries"
+	var missing []string
+	switch runtime.GOOS {
+	case "linux":
+		out, err := util.ParsedCommand("ldd", binary).CombinedOutput()
+		if err != nil {
+			// ldd fails on static binaries, which need no libraries
+			if strings.Contains(string(out), "not a dynamic executable") {
+				return ArtifactCheck{Name: name, Status: CheckPassed, Detail: "statically linked"}
+			}
+			return ArtifactCheck{Name: name, Status: CheckSkipped, Detail: "ldd failed: " + err.Error()}
+		}
+		missing = parseLddMissing(string(out))
+	case "darwin":
+		out, err := util.ParsedCommand("otool", "-L", binary).Output()
+		if err != nil {
+			return ArtifactCheck{Name: name, Status: CheckSkipped, Detail: "otool failed: " + err.Error()}
+		}
+		missing = parseOtoolMissing(string(out))
+	default:
+		return ArtifactCheck{Name: name, Status: CheckSkipped, Detail: "not supported on " + runtime.GOOS}
+	}
+
+	if len(missing) > 0 {
+		return ArtifactCheck{Name: name, Status: CheckFailed, Detail: "not found: " + strings.Join(missing, ", ")}
+	}
+	return ArtifactCheck{Name: name, Status: CheckPassed, Detail: "all found"}
+}
+
+// parseLddMissing returns the libraries ldd reports as "not found"
+func parseLddMissing(out string) []string {
+	var missing []string
+	for _, line := range strings.Split(out, "\n") {
+		if lib, rest, ok := strings.Cut(strings.TrimSpace(line), " => "); ok && strings.TrimSpace(rest) == "not found" {
+			missing = append(missing, lib)
+		}
+	}
+	return missing
+}
+
+// parseOtoolMissing returns the absolute library paths from otool -L that
+// don't exist. System libraries live in the dyld shared cache rather than on
+// disk, and @rpath-style names depend on the loader, so neither is checked.
+func parseOtoolMissing(out string) []string {
+	var missing []string
+	lines := strings.Split(out, "\n")
+	for _, line := range lines[1:] { // The first line names the binary
+		lib, _, _ := strings.Cut(strings.TrimSpace(line), " (")
+		if !strings.HasPrefix(lib, "/") || strings.HasPrefix(lib, "/usr/lib/") || strings.HasPrefix(lib, "/System/") {
+			continue
+		}
+		if _, err := os.Stat(lib); err != nil {
+			missing = append(missing, lib)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,204 @@
+package compile
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the bursts of events editors produce when saving
+const watchDebounce = 300 * time.Millisecond
+
+// watchedExtensions are the file types that trigger a rebuild
+var watchedExtensions = map[string]bool{
+	".c": true, ".cc": true, ".cpp": true, ".cxx": true,
+	".h": true, ".hh": true, ".hpp": true, ".hxx": true,
+	".l": true, ".y": true,
+}
+
+// Watch builds the project from catalyst.yml and rebuilds it whenever a
+// source, header or catalyst.yml changes. With run set, the binary is
+// restarted after every successful build. Stops on Ctrl+C.
+func Watch(opts CompileOptions, run bool) error {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load catalyst.yml: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := addWatchDirs(watcher, cfg); err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	var child *util.Cmd
+	defer func() { stopChild(child) }()
+
+	rebuild := func() {
+		stopChild(child)
+		child = nil
+
+		log.Info()
+		log.Infof("━━━ Building (%s) ━━━\n", time.Now().Format("15:04:05"))
+		if err := withBuildLock(opts, func() error { return BuildProjectWithOptions(nil, opts) }); err != nil {
+			log.Errorf("❌ Build failed: %v\n", err)
+		} else if run {
+			child = startBinary(cfg, opts)
+		}
+		log.Info()
+		log.Info("👀 Watching for changes (Ctrl+C to stop)...")
+	}
+
+	rebuild()
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !isWatchedChange(event) {
+				continue
+			}
+			// catalyst.yml may list new sources in new directories
+			if filepath.Base(event.Name) == config.ProjectFile {
+				if newCfg, err := config.LoadConfig(config.ProjectFile); err == nil {
+					cfg = newCfg
+					if err := addWatchDirs(watcher, cfg); err != nil {
+						log.Warnf("%v\n", err)
+					}
+				}
+			}
+			log.Infof("Changed: %s\n", event.Name)
+			pending = time.After(watchDebounce)
+
+		case <-pending:
+			pending = nil
+			rebuild()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warnf("watcher error: %v\n", err)
+
+		case <-interrupt:
+			log.Info()
+			log.Info("Stopped watching.")
+			return nil
+		}
+	}
+}
+
+// addWatchDirs watches the project root, the directories of the configured
+// sources and generator inputs, and every project directory with headers
+func addWatchDirs(watcher *fsnotify.Watcher, cfg *config.Config) error {
+	dirs := map[string]bool{".": true}
+	for _, src := range cfg.Sources {
+		dirs[filepath.Dir(src)] = true
+	}
+	for _, gen := range cfg.Generators {
+		for _, input := range gen.Inputs {
+			dirs[filepath.Dir(input)] = true
+		}
+	}
+	for _, test := range cfg.Tests {
+		for _, src := range test.Sources {
+			dirs[filepath.Dir(src)] = true
+		}
+	}
+
+	filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != "." && (strings.HasPrefix(name, ".") || name == "build") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".h" || ext == ".hpp" || ext == ".hh" || ext == ".hxx" {
+			dirs[filepath.Dir(path)] = true
+		}
+		return nil
+	})
+
+	for dir := range dirs {
+		// Generated output directories may not exist yet
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// isWatchedChange reports whether an event should trigger a rebuild
+func isWatchedChange(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+	if filepath.Base(event.Name) == config.ProjectFile {
+		return true
+	}
+	return watchedExtensions[strings.ToLower(filepath.Ext(event.Name))]
+}
+
+// startBinary runs the project binary built with opts in the background
+func startBinary(cfg *config.Config, opts CompileOptions) *util.Cmd {
+	output := cfg.Output
+	if output == "" {
+		output = cfg.ProjectName
+	}
+	binary := filepath.Join(OutputDir(opts), output)
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	log.Info()
+	log.Infof("▶ Running %s\n", binary)
+	cmd := util.Command(util.Runnable(binary))
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
+	if err := cmd.Start(); err != nil {
+		log.Errorf("❌ Failed to start %s: %v\n", binary, err)
+		return nil
+	}
+
+	// Reap the process so a finished program doesn't linger
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			log.Infof("%s exited: %v\n", binary, err)
+		}
+	}()
+	return cmd
+}
+
+// stopChild kills a running binary started by startBinary
+func stopChild(cmd *util.Cmd) {
+	if cmd != nil && cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
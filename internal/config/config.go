@@ -1,11 +1,18 @@
 package core
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,6 +20,25 @@ import (
 type Resource struct {
 	URL  string `yaml:"url"`
 	Path string `yaml:"path"`
+	// Optional integrity checks
+	SHA256    string `yaml:"sha256,omitempty"`
+	Signature string `yaml:"signature,omitempty"`  // URL of a .minisig (minisign) or .sig/.asc (GPG) signature
+	PublicKey string `yaml:"public_key,omitempty"` // minisign public key; GPG uses the local keyring
+	// Unpack a .zip, .tar.gz, .tar.xz or .tar.bz2 download into Path,
+	// dropping StripComponents leading directories of every entry
+	Extract         bool `yaml:"extract,omitempty"`
+	StripComponents int  `yaml:"strip_components,omitempty"`
+}
+
+// DownloadPath is where the downloaded file is kept: Path itself, or for
+// extracted resources a hidden archive next to it that checksums,
+// signatures and refreshes apply to
+func (r Resource) DownloadPath() string {
+	path := filepath.Clean(r.Path)
+	if !r.Extract {
+		return path
+	}
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".archive")
 }
 
 // Config is the main project configuration
@@ -24,18 +50,193 @@ type Config struct {
 	Dependencies map[string][]string `yaml:"dependencies"`
 	Includes     []string            `yaml:"includes,omitempty"`
 	Resources    []Resource          `yaml:"resources,omitempty"`
+	Generators   []Generator         `yaml:"generators,omitempty"`
+	Tests        []TestTarget        `yaml:"tests,omitempty"`
+	Resolution   Resolution          `yaml:"resolution,omitempty"`
+	Groups       []string            `yaml:"groups,omitempty"` // Dependency groups added to dependencies on every OS
+	// Demo programs run with catalyst example run; found in examples/ when empty
+	Examples []ExampleTarget `yaml:"examples,omitempty"`
+	// Shared libraries the built program needs at run time, by OS
+	RuntimeDependencies map[string][]string `yaml:"runtime_dependencies,omitempty"`
+	// Developer tools such as clang-format or valgrind, by OS
+	DevDependencies map[string][]string `yaml:"dev_dependencies,omitempty"`
+	// Named sets of packages by OS (e.g. gui: SDL2 and its add-ons), used
+	// together by name from groups: of the project, features and targets
+	DependencyGroups map[string]map[string][]string `yaml:"dependency_groups,omitempty"`
+	// Programs the build runs but doesn't link, such as cmake, pkg-config,
+	// bison, windres or ninja, by tool name rather than package
+	Tools []string `yaml:"tools,omitempty"`
+	// Packages forced for dependency names, taking precedence over resolution
+	PackageOverrides PackageOverrides `yaml:"package_overrides,omitempty"`
+	// Where each dependency mapping came from, so low-confidence guesses can be re-verified
+	Provenance []DependencySource `yaml:"provenance,omitempty"`
+	// Named flag sets selected with catalyst build --profile (e.g. debug, release)
+	Profiles map[string]BuildProfile `yaml:"profiles,omitempty"`
+	// Compile flags added to the sources matching some files, e.g.
+	// -Wno-deprecated for legacy/*.c
+	FileFlags []FileFlags `yaml:"file_flags,omitempty"`
+	// Cross-compilation toolchains keyed by target triple (catalyst build --target)
+	Targets map[string]CrossTarget `yaml:"targets,omitempty"`
+	// What the project builds: an "executable" (the default), or a static
+	// "library" or "shared_library" the other projects of a workspace link
+	// with uses:
+	Type string `yaml:"type,omitempty"`
+	// Shared libraries only: header to generate that defines the
+	// <PROJECT>_API macro marking exported symbols; all others are hidden
+	ExportHeader string `yaml:"export_header,omitempty"`
+	// Library projects of the workspace to link against, by project name or
+	// directory
+	Uses []string `yaml:"uses,omitempty"`
+	// Command prefixed to every compiler invocation (e.g. "distcc", "icecc")
+	CompilerLauncher string `yaml:"compiler_launcher,omitempty"`
+	// Install dependencies into .catalyst/prefix instead of system-wide
+	Isolated bool `yaml:"isolated,omitempty"`
+	// Where dependencies are installed from: "system" package managers (the
+	// default) or "conan"
+	Provider string `yaml:"provider,omitempty"`
+	// vcpkg triplet to install and link against (e.g. x64-windows-static), or
+	// "static"/"dynamic" for the host architecture with that linkage
+	VcpkgTriplet string `yaml:"vcpkg_triplet,omitempty"`
+	// Optional parts of the project selected with catalyst build --features
+	Features        map[string]Feature `yaml:"features,omitempty"`
+	DefaultFeatures []string           `yaml:"default_features,omitempty"`
+	// Number of source files compiled concurrently (catalyst build -j overrides it)
+	Jobs int `yaml:"jobs,omitempty"`
+	// Number of resources downloaded concurrently (catalyst install --download-jobs overrides it)
+	DownloadJobs int `yaml:"download_jobs,omitempty"`
+	// Run compilers and generators in a sandbox that can only write to build/
+	Sandbox bool `yaml:"sandbox,omitempty"`
+	// macOS only: minimum OS version and SDK (xcrun --sdk) to build against
+	MacOSDeploymentTarget string `yaml:"macos_deployment_target,omitempty"`
+	MacOSSDK              string `yaml:"macos_sdk,omitempty"`
 	// Optional stuff to add
 	Author      string                    `yaml:"author,omitempty"`
 	Description string                    `yaml:"description,omitempty"`
 	Env         map[string]string         `yaml:"env,omitempty"`
 	Platforms   map[string]PlatformConfig `yaml:"platforms,omitempty"`
 	CreatedAt   string                    `yaml:"created_at,omitempty"`
+
+	raw map[string]rawValue // Values with ${...} as written, by their path in catalyst.yml
+}
+
+// Generator defines a command that produces sources or headers before compilation
+type Generator struct {
+	Name    string   `yaml:"name,omitempty"`
+	Command string   `yaml:"command"`
+	Inputs  []string `yaml:"inputs,omitempty"`
+	Outputs []string `yaml:"outputs"`
+}
+
+// TestTarget defines a test binary built and run by catalyst test
+type TestTarget struct {
+	Name    string   `yaml:"name"`
+	Sources []string `yaml:"sources"`
+	Flags   []string `yaml:"flags,omitempty"` // Added to the project flags
+	Libs    []string `yaml:"libs,omitempty"`  // Libraries linked as -l<lib>
+	Args    []string `yaml:"args,omitempty"`  // Arguments passed to the test binary
+}
+
+// ExampleTarget defines a demo program built and run by catalyst example
+type ExampleTarget struct {
+	Name    string   `yaml:"name"`
+	Sources []string `yaml:"sources,omitempty"`
+	// A directory with a catalyst.yml of its own, built as that project
+	// instead of from sources
+	Dir   string   `yaml:"dir,omitempty"`
+	Flags []string `yaml:"flags,omitempty"` // Added to the project flags
+	Libs  []string `yaml:"libs,omitempty"`  // Libraries linked as -l<lib>
+	Args  []string `yaml:"args,omitempty"`  // Arguments passed to the example
+}
+
+// BuildProfile is a named set of flags added to the project flags
+type BuildProfile struct {
+	Flags []string `yaml:"flags"`
+}
+
+// CrossTarget configures the toolchain used to build for a target triple
+type CrossTarget struct {
+	Compiler string   `yaml:"compiler,omitempty"` // Defaults to <triple>-gcc
+	Sysroot  string   `yaml:"sysroot,omitempty"`  // Passed as --sysroot
+	Flags    []string `yaml:"flags,omitempty"`    // Appended to the project flags
+	Groups   []string `yaml:"groups,omitempty"`   // Dependency groups linked for the target
+}
+
+// PackageOverrides maps dependency names (headers such as "libpq-fe") to the
+// package to use, keyed by package manager (apt, brew, ...) or platform
+// (linux, darwin, windows)
+type PackageOverrides map[string]map[string]string
+
+// Lookup returns the package forced for a dependency. An entry for the
+// package manager wins over one for its platform.
+func (o PackageOverrides) Lookup(name, pkgManager, platform string) (string, bool) {
+	for _, key := range []string{name, strings.TrimSuffix(name, ".h"), name + ".h"} {
+		byTarget, ok := o[key]
+		if !ok {
+			continue
+		}
+		if pkg, ok := byTarget[pkgManager]; ok {
+			return pkg, true
+		}
+		if pkg, ok := byTarget[platform]; ok {
+			return pkg, true
+		}
+	}
+	return "", false
+}
+
+// ApplyPackageOverrides replaces the dependencies recorded in the provenance
+// with the packages the overrides force for their headers, by platform
+func (c *Config) ApplyPackageOverrides(overrides PackageOverrides) {
+	for i, src := range c.Provenance {
+		pkg, ok := overrides.Lookup(src.Header, "", src.Platform)
+		if src.Header == "" || !ok || pkg == src.Package {
+			continue
+		}
+
+		var deps []string
+		for _, dep := range c.Dependencies[src.Platform] {
+			if dep != src.Package {
+				deps = append(deps, dep)
+			} else if pkg != "" {
+				deps = append(deps, pkg)
+			}
+		}
+		c.Dependencies[src.Platform] = deps
+		c.Provenance[i].Package, c.Provenance[i].Source, c.Provenance[i].Confidence = pkg, "override", 100
+	}
+}
+
+// Resolution configures how header dependencies are resolved to packages
+type Resolution struct {
+	Mode       string         `yaml:"mode,omitempty"`       // auto (default), interactive or strict
+	Strategies []string       `yaml:"strategies,omitempty"` // Resolution steps to run, in order
+	Thresholds map[string]int `yaml:"thresholds,omitempty"` // Minimum confidence (0-100) per step
+}
+
+// DependencySource records how a platform dependency was resolved from a header
+type DependencySource struct {
+	Package    string `yaml:"package"`
+	Platform   string `yaml:"platform"`
+	Header     string `yaml:"header,omitempty"`
+	Source     string `yaml:"source"` // static, library, pkg-config, file-search, name-search, user, shim or override
+	Confidence int    `yaml:"confidence"`
+}
+
+// AddProvenance records the source of a dependency unless one is already recorded
+func (c *Config) AddProvenance(src DependencySource) {
+	for _, existing := range c.Provenance {
+		if existing.Package == src.Package && existing.Platform == src.Platform {
+			return
+		}
+	}
+	c.Provenance = append(c.Provenance, src)
 }
 
 // PlatformConfig allows OS-specific overrides for dependencies or resources
 type PlatformConfig struct {
 	Dependencies []string   `yaml:"dependencies,omitempty"`
 	Resources    []Resource `yaml:"resources,omitempty"`
+	Flags        []string   `yaml:"flags,omitempty"` // Appended to the global flags
 }
 
 // LoadConfig reads and parses a YAML configuration file into Config
@@ -49,6 +250,15 @@ func LoadConfig(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("invalid YAML syntax: %w", err)
 	}
+	if err := cfg.interpolate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateGroups(); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateFileFlags(); err != nil {
+		return nil, err
+	}
 
 	// Fill missing metadata dynamically
 	if cfg.CreatedAt == "" {
@@ -58,9 +268,72 @@ func LoadConfig(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// SaveConfig writes cfg to the YAML file at path. Values LoadConfig expanded
+// ${...} in are written as they were in the file it read.
+func SaveConfig(cfg *Config, path string) error {
+	var node yaml.Node
+	if err := node.Encode(cfg); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	restoreRaw(&node, "", cfg.raw)
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return writeConfigFile(path, data)
+}
+
+// ConfirmWrite, if set, is asked before a config file is overwritten with
+// different contents. Writes it declines fail with ErrWriteDeclined.
+var ConfirmWrite func(path string, old, new []byte) bool
+
+// RecordWrite, if set, is told about the overwrites ConfirmWrite confirmed
+// once they have been written
+var RecordWrite func(path string, old, new []byte)
+
+// ErrWriteDeclined is returned for config writes ConfirmWrite declined
+var ErrWriteDeclined = errors.New("overwrite not confirmed (pass --yes to skip confirmation)")
+
+// writeConfigFile writes a config file, asking ConfirmWrite before
+// replacing an existing one
+func writeConfigFile(path string, data []byte) error {
+	var replaced []byte
+	if ConfirmWrite != nil {
+		if old, err := os.ReadFile(path); err == nil && !bytes.Equal(old, data) {
+			if !ConfirmWrite(path, old, data) {
+				return fmt.Errorf("%s: %w", path, ErrWriteDeclined)
+			}
+			replaced = old
+		}
+	}
+	if err := util.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if replaced != nil && RecordWrite != nil {
+		RecordWrite(path, replaced, data)
+	}
+	return nil
+}
+
 // GetDependencies returns the dependency list for the current OS
 func (c *Config) GetDependencies() []string {
-	osKey := runtime.GOOS
+	return c.DependenciesFor(runtime.GOOS)
+}
+
+// DependenciesFor returns the dependency list for the given OS, followed by
+// the packages of the project's dependency groups
+func (c *Config) DependenciesFor(osKey string) []string {
+	deps := c.listedDependenciesFor(osKey)
+	for _, dep := range c.GroupDependencies(c.Groups, osKey) {
+		if !slices.Contains(deps, dep) {
+			deps = append(slices.Clip(deps), dep)
+		}
+	}
+	return deps
+}
+
+// listedDependenciesFor returns the dependencies listed for the given OS
+func (c *Config) listedDependenciesFor(osKey string) []string {
 
 	// 1. OS-specific overrides
 	if platform, ok := c.Platforms[osKey]; ok && len(platform.Dependencies) > 0 {
@@ -83,6 +356,82 @@ func (c *Config) GetDependencies() []string {
 	return []string{}
 }
 
+// Project types
+const (
+	TypeExecutable    = "executable"
+	TypeLibrary       = "library"
+	TypeSharedLibrary = "shared_library"
+)
+
+// IsLibrary reports whether the project builds a static or shared library
+func (c *Config) IsLibrary() bool {
+	return c.Type == TypeLibrary || c.Type == TypeSharedLibrary
+}
+
+// Dependency providers
+const (
+	ProviderSystem = "system"
+	ProviderConan  = "conan"
+)
+
+// Dependency categories: build dependencies (headers and libraries, under
+// dependencies:) and tools are needed to compile, runtime dependencies to
+// run the built program and dev dependencies only for working on the project
+const (
+	CategoryBuild   = "build"
+	CategoryTools   = "tools"
+	CategoryRuntime = "runtime"
+	CategoryDev     = "dev"
+)
+
+// DependencyCategories lists the categories in install order
+var DependencyCategories = []string{CategoryBuild, CategoryTools, CategoryRuntime, CategoryDev}
+
+// ValidateCategories checks that every name is a dependency category
+func ValidateCategories(categories []string) error {
+	for _, category := range categories {
+		if !slices.Contains(DependencyCategories, category) {
+			return fmt.Errorf("unknown dependency category %q (use %s)", category, strings.Join(DependencyCategories, ", "))
+		}
+	}
+	return nil
+}
+
+// DependenciesIn returns the dependencies of one category for the given OS.
+// Tools are named rather than packaged, so the installer maps them to
+// packages itself and they aren't returned here.
+func (c *Config) DependenciesIn(category, osKey string) []string {
+	switch category {
+	case CategoryBuild:
+		return c.DependenciesFor(osKey)
+	case CategoryRuntime:
+		return c.RuntimeDependencies[osKey]
+	case CategoryDev:
+		return c.DevDependencies[osKey]
+	}
+	return nil
+}
+
+// DependenciesInCategories returns the dependencies of the given categories
+// for the OS, without duplicates. No categories means all of them.
+func (c *Config) DependenciesInCategories(osKey string, categories []string) []string {
+	if len(categories) == 0 {
+		categories = DependencyCategories
+	}
+	deps := []string{}
+	for _, category := range DependencyCategories {
+		if !slices.Contains(categories, category) {
+			continue
+		}
+		for _, dep := range c.DependenciesIn(category, osKey) {
+			if !slices.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
+
 // GetResources returns the resource list for the current OS
 func (c *Config) GetResources() []Resource {
 	osKey := runtime.GOOS
@@ -95,3 +444,24 @@ func (c *Config) GetResources() []Resource {
 	// 2. Global resources fallback
 	return c.Resources
 }
+
+// GetFlags returns the compiler flags for the current OS
+func (c *Config) GetFlags() []string {
+	return c.FlagsFor(runtime.GOOS)
+}
+
+// FlagsFor returns the compiler flags for the given OS, with
+// ${HOMEBREW_PREFIX} replaced by this machine's Homebrew prefix
+func (c *Config) FlagsFor(osKey string) []string {
+	flags := append([]string{}, c.Flags...)
+
+	// Platform-specific flags are added after the global ones
+	if platform, ok := c.Platforms[osKey]; ok {
+		flags = append(flags, platform.Flags...)
+	}
+
+	for i, flag := range flags {
+		flags[i] = platform.ExpandBrewPrefix(flag)
+	}
+	return flags
+}
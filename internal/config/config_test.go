@@ -0,0 +1,54 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSaveConfigRecordsWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalyst.yml")
+	var recorded []string
+	answer := true
+	ConfirmWrite = func(string, []byte, []byte) bool { return answer }
+	RecordWrite = func(path string, old, new []byte) { recorded = append(recorded, path) }
+	defer func() { ConfirmWrite, RecordWrite = nil, nil }()
+
+	if err := SaveConfig(&Config{ProjectName: "app"}, path); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 0 {
+		t.Errorf("creating %s was recorded as an overwrite", path)
+	}
+
+	answer = false
+	if err := SaveConfig(&Config{ProjectName: "other"}, path); err == nil {
+		t.Fatal("a declined overwrite succeeded")
+	}
+	if len(recorded) != 0 {
+		t.Error("a declined overwrite was recorded")
+	}
+
+	answer = true
+	if err := SaveConfig(&Config{ProjectName: "other"}, path); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 1 {
+		t.Errorf("recorded %q, want the overwrite", recorded)
+	}
+
+	// A write that fails isn't recorded. Root and Windows ignore the
+	// directory's permissions.
+	if runtime.GOOS == "windows" || os.Getuid() == 0 {
+		return
+	}
+	os.Chmod(filepath.Dir(path), 0555)
+	defer os.Chmod(filepath.Dir(path), 0755)
+	if err := SaveConfig(&Config{ProjectName: "third"}, path); err == nil {
+		t.Fatal("writing into a read-only directory succeeded")
+	}
+	if len(recorded) != 1 {
+		t.Error("a failed write was recorded")
+	}
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigNames are the file names of a project config, in order of preference
+var ConfigNames = []string{"catalyst.yml", "catalyst.yaml"}
+
+// ProjectFile is the project config commands load, relative to the project
+// root. It is set by UseProjectFile once the project has been found.
+var ProjectFile = "catalyst.yml"
+
+// FindProject looks for a project config in dir and then in each parent
+// directory, like git does for .git. It returns the directory holding the
+// config and the config's file name, or ok=false if there is none.
+func FindProject(dir string) (root, name string, ok bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", false
+	}
+	for {
+		for _, name := range ConfigNames {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+				return dir, name, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+// UseProjectFile makes path the project config and changes into its
+// directory, so sources, build/ and catalyst.lock resolve from the project
+// root
+func UseProjectFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot use config file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot use config file: %s is a directory", path)
+	}
+	if err := os.Chdir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("cannot change to the project directory: %w", err)
+	}
+	ProjectFile = filepath.Base(path)
+	return nil
+}
+
+// FindMembers returns the directories below dir, relative to it, that hold
+// their own project config, as smart-init writes for projects with several
+// build targets. Hidden directories and build/ are skipped.
+func FindMembers(dir string) []string {
+	var members []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || path == dir {
+			return nil
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || name == "build" {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		if strings.Count(rel, string(filepath.Separator)) >= maxMemberDepth {
+			return filepath.SkipDir
+		}
+		for _, configName := range ConfigNames {
+			if _, err := os.Stat(filepath.Join(path, configName)); err == nil {
+				members = append(members, rel)
+				break
+			}
+		}
+		return nil
+	})
+	return members
+}
+
+// maxMemberDepth is how many directories deep FindMembers looks
+const maxMemberDepth = 3
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ListEdit adds and removes items of the list at Path (e.g. ["dependencies", "linux"])
+type ListEdit struct {
+	Path   []string
+	Add    []string
+	Remove []string
+}
+
+// EditLists applies list edits to a config file. The file is edited in place
+// so comments and ordering are kept; missing lists are created.
+func EditLists(path string, edits []ListEdit) error {
+	doc, err := loadNode(path)
+	if err != nil {
+		return err
+	}
+	root := doc.Content[0]
+
+	for _, edit := range edits {
+		list := ensurePath(root, edit.Path)
+		filterSequence(list, func(item *yaml.Node) bool {
+			return !containsValue(edit.Remove, item.Value)
+		})
+		// Lists written inline as [] become block lists once they have items
+		if len(list.Content) == 0 {
+			list.Style = 0
+		}
+		for _, value := range edit.Add {
+			exists := false
+			for _, item := range list.Content {
+				if item.Value == value {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+			}
+		}
+	}
+
+	return saveNode(path, doc)
+}
+
+// RemoveDependencies removes packages (keyed by platform) from the dependency
+// lists, platform overrides and provenance of a config file. The file is
+// edited in place so comments and ordering are kept.
+func RemoveDependencies(path string, remove map[string][]string) error {
+	doc, err := loadNode(path)
+	if err != nil {
+		return err
+	}
+	root := doc.Content[0]
+
+	// dependencies: {platform: [pkg, ...]}
+	if deps := mappingValue(root, "dependencies"); deps != nil {
+		for i := 0; i+1 < len(deps.Content); i += 2 {
+			filterSequence(deps.Content[i+1], func(item *yaml.Node) bool {
+				return !containsValue(remove[deps.Content[i].Value], item.Value)
+			})
+		}
+	}
+
+	// platforms: {platform: {dependencies: [pkg, ...]}}
+	if platforms := mappingValue(root, "platforms"); platforms != nil {
+		for i := 0; i+1 < len(platforms.Content); i += 2 {
+			if deps := mappingValue(platforms.Content[i+1], "dependencies"); deps != nil {
+				filterSequence(deps, func(item *yaml.Node) bool {
+					return !containsValue(remove[platforms.Content[i].Value], item.Value)
+				})
+			}
+		}
+	}
+
+	// provenance: [{package: pkg, platform: platform, ...}]
+	if provenance := mappingValue(root, "provenance"); provenance != nil {
+		filterSequence(provenance, func(item *yaml.Node) bool {
+			pkg, platform := mappingValue(item, "package"), mappingValue(item, "platform")
+			return pkg == nil || platform == nil || !containsValue(remove[platform.Value], pkg.Value)
+		})
+		if len(provenance.Content) == 0 {
+			removeMappingKey(root, "provenance")
+		}
+	}
+
+	return saveNode(path, doc)
+}
+
+// SetTopLevel sets top-level keys of a YAML file, such as ~/.catalyst.yaml,
+// creating the file if it doesn't exist. Keys the file already has keep
+// their values unless replace names them. The file is edited in place so
+// comments and ordering are kept. The keys set are returned, sorted.
+func SetTopLevel(path string, values map[string]any, replace ...string) ([]string, error) {
+	doc, err := loadNode(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	} else if err != nil {
+		return nil, err
+	}
+	root := doc.Content[0]
+
+	var set []string
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		existing := mappingValue(root, key)
+		if existing != nil && !slices.Contains(replace, key) {
+			continue
+		}
+		var value yaml.Node
+		if err := value.Encode(values[key]); err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", key, err)
+		}
+		if existing != nil {
+			*existing = value
+		} else {
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &value)
+		}
+		set = append(set, key)
+	}
+	return set, saveNode(path, doc)
+}
+
+// loadNode parses a config file into a YAML node tree with a mapping at its root
+func loadNode(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML syntax: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a YAML mapping", path)
+	}
+	return &doc, nil
+}
+
+// saveNode writes a YAML node tree back to a config file
+func saveNode(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return writeConfigFile(path, buf.Bytes())
+}
+
+// ensurePath returns the sequence node at path, creating mappings and the sequence as needed
+func ensurePath(node *yaml.Node, path []string) *yaml.Node {
+	for i, key := range path {
+		value := mappingValue(node, key)
+		if value == nil {
+			kind := yaml.MappingNode
+			if i == len(path)-1 {
+				kind = yaml.SequenceNode
+			}
+			value = &yaml.Node{Kind: kind}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+		}
+		node = value
+	}
+	return node
+}
+
+// removeMappingKey deletes a key and its value from a mapping node
+func removeMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// mappingValue returns the value node for key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// filterSequence keeps the items of a sequence node for which keep returns true
+func filterSequence(node *yaml.Node, keep func(*yaml.Node) bool) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+	kept := node.Content[:0]
+	for _, item := range node.Content {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+	node.Content = kept
+}
+
+// containsValue checks if a string slice contains value
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetTopLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".catalyst.yaml")
+
+	// A missing file is created
+	set, err := SetTopLevel(path, map[string]any{"setup_completed": "2026-01-01", "verbose": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"setup_completed", "verbose"}; !reflect.DeepEqual(set, want) {
+		t.Errorf("SetTopLevel() set %q, want %q", set, want)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "setup_completed: \"2026-01-01\"\nverbose: true\n" {
+		t.Errorf("created file:\n%s", data)
+	}
+
+	// Comments and order are kept, existing keys keep their values unless
+	// they are replaced, and new keys are added at the end
+	existing := `# Written by hand
+verbose: false # quiet please
+compiler_priority:
+  linux: [clang, gcc] # clang first
+setup_completed: "2026-01-01"
+`
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	set, err = SetTopLevel(path, map[string]any{
+		"verbose":           true,
+		"compiler_priority": map[string][]string{"linux": {"gcc"}},
+		"setup_completed":   "2026-02-01",
+		"msys2_environment": "clang64",
+	}, "setup_completed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"msys2_environment", "setup_completed"}; !reflect.DeepEqual(set, want) {
+		t.Errorf("SetTopLevel() set %q, want %q", set, want)
+	}
+	want := `# Written by hand
+verbose: false # quiet please
+compiler_priority:
+  linux: [clang, gcc] # clang first
+setup_completed: "2026-02-01"
+msys2_environment: clang64
+`
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("edited file:\n%s\nwant:\n%s", data, want)
+	}
+
+	// Files that aren't a mapping are left alone
+	if err := os.WriteFile(path, []byte("- a\n- b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SetTopLevel(path, map[string]any{"verbose": true}); err == nil {
+		t.Error("SetTopLevel() edited a YAML list")
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Feature is an optional part of the project, enabled with
+// catalyst build --features or by default_features
+type Feature struct {
+	Sources      []string            `yaml:"sources,omitempty"`      // Compiled only when the feature is enabled
+	Defines      []string            `yaml:"defines,omitempty"`      // Passed as -D<define>
+	Flags        []string            `yaml:"flags,omitempty"`        // Appended to the project flags
+	Dependencies map[string][]string `yaml:"dependencies,omitempty"` // Build dependencies by OS
+	Groups       []string            `yaml:"groups,omitempty"`       // Dependency groups added with the feature
+	Requires     []string            `yaml:"requires,omitempty"`     // Other features this one enables
+}
+
+// EnabledFeatures returns the requested features, the default features
+// unless noDefaults is set, and every feature they require, sorted
+func (c *Config) EnabledFeatures(requested []string, noDefaults bool) ([]string, error) {
+	pending := append([]string{}, requested...)
+	if !noDefaults {
+		pending = append(pending, c.DefaultFeatures...)
+	}
+
+	enabled := make(map[string]bool)
+	for len(pending) > 0 {
+		name := strings.TrimSpace(pending[0])
+		pending = pending[1:]
+		if name == "" || enabled[name] {
+			continue
+		}
+		feature, ok := c.Features[name]
+		if !ok {
+			return nil, c.unknownFeature(name)
+		}
+		enabled[name] = true
+		pending = append(pending, feature.Requires...)
+	}
+	return slices.Sorted(maps.Keys(enabled)), nil
+}
+
+// unknownFeature lists the features catalyst.yml defines
+func (c *Config) unknownFeature(name string) error {
+	if len(c.Features) == 0 {
+		return fmt.Errorf("unknown feature %q: catalyst.yml has no features: section", name)
+	}
+	known := slices.Sorted(maps.Keys(c.Features))
+	return fmt.Errorf("unknown feature %q (available: %s)", name, strings.Join(known, ", "))
+}
+
+// FeatureSources returns the extra sources of the enabled features
+func (c *Config) FeatureSources(names []string) []string {
+	var sources []string
+	for _, name := range names {
+		for _, src := range c.Features[name].Sources {
+			if !slices.Contains(sources, src) {
+				sources = append(sources, src)
+			}
+		}
+	}
+	return sources
+}
+
+// FeatureFlags returns the defines and flags of the enabled features
+func (c *Config) FeatureFlags(names []string) []string {
+	var flags []string
+	for _, name := range names {
+		feature := c.Features[name]
+		for _, define := range feature.Defines {
+			flags = append(flags, "-D"+define)
+		}
+		flags = append(flags, feature.Flags...)
+	}
+	return flags
+}
+
+// FeatureDependenciesFor returns the dependencies the enabled features add
+// on the given OS, their groups' included
+func (c *Config) FeatureDependenciesFor(names []string, osKey string) []string {
+	var deps []string
+	for _, name := range names {
+		feature := c.Features[name]
+		for _, dep := range slices.Concat(feature.Dependencies[osKey], c.GroupDependencies(feature.Groups, osKey)) {
+			if !slices.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
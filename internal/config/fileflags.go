@@ -0,0 +1,105 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+)
+
+// FileFlags adds compile flags to some of the project's sources, after the
+// project's own so they can override them (e.g. -Wno-deprecated for
+// legacy/*.c)
+type FileFlags struct {
+	// Paths, globs (legacy/*.c) or directories (legacy/) relative to the
+	// project; like in .gitignore, a pattern without a slash matches file
+	// and directory names at any depth (*.cpp)
+	Files []string `yaml:"files"`
+	Flags []string `yaml:"flags"`
+}
+
+// Matches reports whether src is one of the files of f
+func (f FileFlags) Matches(src string) bool {
+	src = path.Clean(filepath.ToSlash(src))
+	for _, pattern := range f.Files {
+		if matchFile(pattern, src) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFile reports whether the cleaned, slash-separated src is matched by
+// pattern, which like in .gitignore matches a name at any depth unless it
+// has a slash, and matches the files under a directory it names
+func matchFile(pattern, src string) bool {
+	pattern = filepath.ToSlash(pattern)
+	dirOnly := strings.HasSuffix(pattern, "/") || strings.HasSuffix(pattern, "/**")
+	pattern = path.Clean(strings.TrimSuffix(pattern, "**"))
+	if pattern == "." {
+		return true
+	}
+	// src, then the directories it's in
+	for p, isFile := src, true; p != "." && p != "/"; p, isFile = path.Dir(p), false {
+		if isFile && dirOnly {
+			continue
+		}
+		name := p
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(p)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FlagsForFile returns the flags file_flags: adds to src, in the order of
+// catalyst.yml, with ${HOMEBREW_PREFIX} replaced like FlagsFor does
+func FlagsForFile(fileFlags []FileFlags, src string) []string {
+	var flags []string
+	for _, f := range fileFlags {
+		if f.Matches(src) {
+			for _, flag := range f.Flags {
+				flags = append(flags, platform.ExpandBrewPrefix(flag))
+			}
+		}
+	}
+	return flags
+}
+
+// validateFileFlags checks that every entry of file_flags: names files,
+// that its globs are well-formed and that it has no link flags, which
+// builds would otherwise drop
+func (c *Config) validateFileFlags() error {
+	for i, f := range c.FileFlags {
+		if len(f.Files) == 0 {
+			return fmt.Errorf("file_flags[%d] has no files", i)
+		}
+		for _, pattern := range f.Files {
+			if _, err := path.Match(filepath.ToSlash(pattern), ""); err != nil {
+				return fmt.Errorf("invalid pattern %q in file_flags[%d]: %w", pattern, i, err)
+			}
+		}
+		for _, flag := range f.Flags {
+			if isLinkOnlyFlag(flag) {
+				return fmt.Errorf("file_flags[%d] has the link flag %q: file_flags only adds compile flags, put libraries and linker options in flags", i, flag)
+			}
+		}
+	}
+	return nil
+}
+
+// isLinkOnlyFlag reports whether flag is a library, a library path or a
+// linker option, which are only used when linking
+func isLinkOnlyFlag(flag string) bool {
+	for _, prefix := range []string{"-l", "-L", "-Wl,", "-Xlinker", "-framework"} {
+		if strings.HasPrefix(flag, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileFlagsRejectsLinkFlags(t *testing.T) {
+	cfg, err := loadYAML(t, "project_name: app\nfile_flags:\n  - files: [\"*.cpp\"]\n    flags: [\"-fno-exceptions\", \"-Wno-deprecated\"]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FlagsForFile(cfg.FileFlags, "src/gui/window.cpp"); len(got) != 2 {
+		t.Errorf("FlagsForFile() = %q, want the compile flags", got)
+	}
+
+	for _, flag := range []string{"-lstdc++", "-L/opt/lib", "-Wl,--as-needed", "-framework"} {
+		yml := "project_name: app\nfile_flags:\n  - files: [\"*.cpp\"]\n    flags: [\"-fno-exceptions\", \"" + flag + "\"]\n"
+		if _, err := loadYAML(t, yml); err == nil || !strings.Contains(err.Error(), `file_flags[0] has the link flag "`+flag+`"`) {
+			t.Errorf("LoadConfig() with %s in file_flags = %v, want an error", flag, err)
+		}
+	}
+}
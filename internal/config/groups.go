@@ -0,0 +1,55 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// GroupDependencies returns the packages of the named dependency groups on
+// the given OS, without duplicates
+func (c *Config) GroupDependencies(groups []string, osKey string) []string {
+	var deps []string
+	for _, name := range groups {
+		for _, dep := range c.DependencyGroups[name][osKey] {
+			if !slices.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
+
+// validateGroups checks that the project, its features and its targets only
+// name groups defined under dependency_groups
+func (c *Config) validateGroups() error {
+	check := func(where string, groups []string) error {
+		for _, name := range groups {
+			if _, ok := c.DependencyGroups[name]; ok {
+				continue
+			}
+			if len(c.DependencyGroups) == 0 {
+				return fmt.Errorf("unknown dependency group %q in %s: catalyst.yml has no dependency_groups: section", name, where)
+			}
+			known := slices.Sorted(maps.Keys(c.DependencyGroups))
+			return fmt.Errorf("unknown dependency group %q in %s (defined: %s)", name, where, strings.Join(known, ", "))
+		}
+		return nil
+	}
+
+	if err := check("groups", c.Groups); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Features)) {
+		if err := check("features."+name, c.Features[name].Groups); err != nil {
+			return err
+		}
+	}
+	for _, triple := range slices.Sorted(maps.Keys(c.Targets)) {
+		if err := check("targets."+triple, c.Targets[triple].Groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// loadYAML loads a catalyst.yml with the given content
+func loadYAML(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "catalyst.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadConfig(path)
+}
+
+func TestDependencyGroups(t *testing.T) {
+	cfg, err := loadYAML(t, `project_name: app
+dependencies:
+  linux: [libssl-dev, zlib1g-dev]
+dependency_groups:
+  compression:
+    linux: [zlib1g-dev, libzstd-dev]
+    darwin: [zstd]
+  gui:
+    linux: [libgtk-3-dev]
+groups: [compression]
+platforms:
+  darwin:
+    dependencies: [openssl]
+features:
+  tls:
+    dependencies:
+      linux: [libssl-dev, libnghttp2-dev]
+  desktop:
+    groups: [gui, compression]
+    dependencies:
+      linux: [libnotify-dev]
+    requires: [tls]
+default_features: [tls]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Groups follow the listed dependencies, without repeating them
+	tests := map[string][]string{
+		"linux":   {"libssl-dev", "zlib1g-dev", "libzstd-dev"},
+		"darwin":  {"openssl", "zstd"},
+		"windows": {},
+	}
+	for osKey, want := range tests {
+		if got := cfg.DependenciesFor(osKey); !reflect.DeepEqual(got, want) {
+			t.Errorf("DependenciesFor(%s) = %q, want %q", osKey, got, want)
+		}
+	}
+	if got, want := cfg.GroupDependencies([]string{"gui", "compression", "gui"}, "linux"), []string{"libgtk-3-dev", "zlib1g-dev", "libzstd-dev"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupDependencies() = %q, want %q", got, want)
+	}
+	if got := cfg.GroupDependencies([]string{"gui"}, "darwin"); len(got) != 0 {
+		t.Errorf("GroupDependencies() of a group without the OS = %q", got)
+	}
+
+	// Features add their dependencies and their groups', and those of the
+	// features they require
+	features, err := cfg.EnabledFeatures([]string{"desktop"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"desktop", "tls"}; !reflect.DeepEqual(features, want) {
+		t.Errorf("EnabledFeatures(desktop) = %q, want %q", features, want)
+	}
+	want := []string{"libnotify-dev", "libgtk-3-dev", "zlib1g-dev", "libzstd-dev", "libssl-dev", "libnghttp2-dev"}
+	if got := cfg.FeatureDependenciesFor(features, "linux"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FeatureDependenciesFor(%q, linux) = %q, want %q", features, got, want)
+	}
+	if got := cfg.FeatureDependenciesFor([]string{"tls"}, "darwin"); len(got) != 0 {
+		t.Errorf("FeatureDependenciesFor(tls, darwin) = %q, want none", got)
+	}
+	if features, err := cfg.EnabledFeatures(nil, false); err != nil || !reflect.DeepEqual(features, []string{"tls"}) {
+		t.Errorf("EnabledFeatures() with the defaults = %q, %v, want [tls]", features, err)
+	}
+	if _, err := cfg.EnabledFeatures([]string{"gpu"}, false); err == nil || !strings.Contains(err.Error(), "available: desktop, tls") {
+		t.Errorf("EnabledFeatures(gpu) = %v, want an error listing the features", err)
+	}
+}
+
+func TestUnknownDependencyGroup(t *testing.T) {
+	tests := []struct {
+		name, yml, want string
+	}{
+		{
+			"project",
+			"project_name: app\ngroups: [gui]\ndependency_groups:\n  net:\n    linux: [libcurl4-openssl-dev]\n  audio:\n    linux: [libasound2-dev]\n",
+			`unknown dependency group "gui" in groups (defined: audio, net)`,
+		},
+		{
+			"feature",
+			"project_name: app\nfeatures:\n  ui:\n    groups: [gui]\n",
+			`unknown dependency group "gui" in features.ui: catalyst.yml has no dependency_groups: section`,
+		},
+		{
+			"target",
+			"project_name: app\ndependency_groups:\n  net: {}\ntargets:\n  aarch64-linux-gnu:\n    groups: [gui]\n",
+			`unknown dependency group "gui" in targets.aarch64-linux-gnu (defined: net)`,
+		},
+	}
+	for _, tt := range tests {
+		if _, err := loadYAML(t, tt.yml); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: LoadConfig() = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
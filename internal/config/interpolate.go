@@ -0,0 +1,211 @@
+package core
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"gopkg.in/yaml.v3"
+)
+
+// variableName matches the names ${...} can refer to
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// linkerVariables are replaced by the dynamic linker when a library loads
+// (-Wl,-rpath,${ORIGIN}/../lib), so catalyst leaves them as written
+var linkerVariables = []string{"ORIGIN", "LIB", "PLATFORM"}
+
+// interpolation replaces ${...} in the values of catalyst.yml and keeps the
+// first error
+type interpolation struct {
+	builtins map[string]string
+	raw      map[string]rawValue // Values as written, by their path in catalyst.yml
+	err      error
+}
+
+// rawValue is a value with ${...} as written and as LoadConfig expanded it
+type rawValue struct {
+	written, expanded string
+}
+
+// nodePath joins the keys and indexes leading to a value of catalyst.yml
+func nodePath(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// interpolate replaces the variables in the sources, flags, resources and
+// output of c: ${os}, ${arch} and ${project_name} with the machine's GOOS
+// and GOARCH and the project name, and any other ${NAME} with the
+// environment variable, or the default of ${NAME:-default} when it's unset
+// or empty. $${ is a literal ${, ${HOMEBREW_PREFIX} is left for FlagsFor and
+// ${ORIGIN}, ${LIB} and ${PLATFORM} for the dynamic linker. The values as
+// written are kept for SaveConfig.
+func (c *Config) interpolate() error {
+	in := &interpolation{builtins: map[string]string{
+		"os":           runtime.GOOS,
+		"arch":         runtime.GOARCH,
+		"project_name": c.ProjectName,
+	}, raw: make(map[string]rawValue)}
+
+	c.Sources = in.list("sources", nodePath("sources"), c.Sources)
+	c.Output = in.expand("output", nodePath("output"), c.Output)
+	c.Flags = in.list("flags", nodePath("flags"), c.Flags)
+	c.Resources = in.resources("resources", nodePath("resources"), c.Resources)
+	for i := range c.FileFlags {
+		at := nodePath("file_flags", strconv.Itoa(i))
+		c.FileFlags[i].Files = in.list("file_flags", nodePath(at, "files"), c.FileFlags[i].Files)
+		c.FileFlags[i].Flags = in.list("file_flags", nodePath(at, "flags"), c.FileFlags[i].Flags)
+	}
+	for i := range c.Tests {
+		where, at := "tests."+c.Tests[i].Name, nodePath("tests", strconv.Itoa(i))
+		c.Tests[i].Sources = in.list(where, nodePath(at, "sources"), c.Tests[i].Sources)
+		c.Tests[i].Flags = in.list(where, nodePath(at, "flags"), c.Tests[i].Flags)
+	}
+	for i := range c.Examples {
+		where, at := "examples."+c.Examples[i].Name, nodePath("examples", strconv.Itoa(i))
+		c.Examples[i].Sources = in.list(where, nodePath(at, "sources"), c.Examples[i].Sources)
+		c.Examples[i].Flags = in.list(where, nodePath(at, "flags"), c.Examples[i].Flags)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Features)) {
+		feature, at := c.Features[name], nodePath("features", name)
+		feature.Sources = in.list("features."+name, nodePath(at, "sources"), feature.Sources)
+		feature.Flags = in.list("features."+name, nodePath(at, "flags"), feature.Flags)
+		c.Features[name] = feature
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		profile := c.Profiles[name]
+		profile.Flags = in.list("profiles."+name, nodePath("profiles", name, "flags"), profile.Flags)
+		c.Profiles[name] = profile
+	}
+	for _, triple := range slices.Sorted(maps.Keys(c.Targets)) {
+		target := c.Targets[triple]
+		target.Flags = in.list("targets."+triple, nodePath("targets", triple, "flags"), target.Flags)
+		c.Targets[triple] = target
+	}
+	for _, osName := range slices.Sorted(maps.Keys(c.Platforms)) {
+		p, at := c.Platforms[osName], nodePath("platforms", osName)
+		p.Flags = in.list("platforms."+osName, nodePath(at, "flags"), p.Flags)
+		p.Resources = in.resources("platforms."+osName, nodePath(at, "resources"), p.Resources)
+		c.Platforms[osName] = p
+	}
+	c.raw = in.raw
+	return in.err
+}
+
+// list expands every value of a list
+func (in *interpolation) list(where, at string, values []string) []string {
+	for i, value := range values {
+		values[i] = in.expand(where, nodePath(at, strconv.Itoa(i)), value)
+	}
+	return values
+}
+
+// resources expands the URLs and paths of resources
+func (in *interpolation) resources(where, at string, resources []Resource) []Resource {
+	for i := range resources {
+		r := nodePath(at, strconv.Itoa(i))
+		resources[i].URL = in.expand(where, nodePath(r, "url"), resources[i].URL)
+		resources[i].Path = in.expand(where, nodePath(r, "path"), resources[i].Path)
+		resources[i].Signature = in.expand(where, nodePath(r, "signature"), resources[i].Signature)
+	}
+	return resources
+}
+
+// expand replaces the variables of one value and remembers it as written
+// under its path at; where names its section of catalyst.yml in errors
+func (in *interpolation) expand(where, at, value string) string {
+	if in.err != nil || !strings.Contains(value, "${") {
+		return value
+	}
+	expanded := in.replace(where, value)
+	if expanded != value {
+		in.raw[at] = rawValue{written: value, expanded: expanded}
+	}
+	return expanded
+}
+
+// replace replaces the variables of one value
+func (in *interpolation) replace(where, value string) string {
+	var b strings.Builder
+	rest := value
+	for {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			b.WriteString(rest)
+			return b.String()
+		}
+		if start > 0 && rest[start-1] == '$' {
+			b.WriteString(rest[:start-1] + "${")
+			rest = rest[start+2:]
+			continue
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			in.err = fmt.Errorf("unterminated ${ in %s: %q", where, value)
+			return value
+		}
+		b.WriteString(rest[:start])
+		expr := rest[start+2 : start+end]
+		rest = rest[start+end+1:]
+
+		name, fallback, hasFallback := strings.Cut(expr, ":-")
+		if !variableName.MatchString(name) {
+			in.err = fmt.Errorf("invalid variable ${%s} in %s", expr, where)
+			return value
+		}
+		switch builtin, isBuiltin := in.builtins[name]; {
+		case isBuiltin:
+			b.WriteString(builtin)
+		case "${"+name+"}" == platform.BrewPrefixVariable, slices.Contains(linkerVariables, name):
+			b.WriteString("${" + expr + "}")
+		case envSet(name, hasFallback):
+			b.WriteString(os.Getenv(name))
+		case hasFallback:
+			b.WriteString(fallback)
+		default:
+			in.err = fmt.Errorf("${%s} in %s: %s is not set (use ${%s:-default} to fall back to a value)", name, where, name, name)
+			return value
+		}
+	}
+}
+
+// envSet reports whether the environment variable name gives a value: an
+// empty one only does without a fallback, like in the shell
+func envSet(name string, hasFallback bool) bool {
+	value, ok := os.LookupEnv(name)
+	return ok && (value != "" || !hasFallback)
+}
+
+// restoreRaw puts back the values of a marshaled config as they were
+// written before LoadConfig expanded them: those still at the same path
+// with the same expansion, so values changed since are written as they are
+func restoreRaw(node *yaml.Node, at string, raw map[string]rawValue) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			restoreRaw(child, at, raw)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if at != "" {
+				key = nodePath(at, key)
+			}
+			restoreRaw(node.Content[i+1], key, raw)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			restoreRaw(child, nodePath(at, strconv.Itoa(i)), raw)
+		}
+	case yaml.ScalarNode:
+		if r, ok := raw[at]; ok && node.Value == r.expanded {
+			node.Value = r.written
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "catalyst.yml")
+	t.Setenv("SDK_ROOT", "/opt/sdk")
+	t.Setenv("EMPTY", "")
+	yml := `project_name: demo
+sources: ["src/${os}/main.c"]
+output: "${project_name}-${arch}"
+flags: ["-I${SDK_ROOT}/include", "-DLEVEL=${LEVEL:-3}", "-DTAG=${EMPTY:-none}", "-DLITERAL=$${os}", "-I${HOMEBREW_PREFIX}/include", "-Wl,-rpath,${ORIGIN}/../lib"]
+resources:
+  - url: "https://example.com/${os}/data.zip"
+    path: "vendor/${os}.zip"
+dependencies: {}
+`
+	if err := os.WriteFile(path, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goos, arch := runtime.GOOS, runtime.GOARCH
+	if want := []string{"src/" + goos + "/main.c"}; !reflect.DeepEqual(cfg.Sources, want) {
+		t.Errorf("sources = %q, want %q", cfg.Sources, want)
+	}
+	if want := "demo-" + arch; cfg.Output != want {
+		t.Errorf("output = %q, want %q", cfg.Output, want)
+	}
+	want := []string{"-I/opt/sdk/include", "-DLEVEL=3", "-DTAG=none", "-DLITERAL=${os}", "-I${HOMEBREW_PREFIX}/include", "-Wl,-rpath,${ORIGIN}/../lib"}
+	if !reflect.DeepEqual(cfg.Flags, want) {
+		t.Errorf("flags = %q, want %q", cfg.Flags, want)
+	}
+	if r := cfg.Resources[0]; r.URL != "https://example.com/"+goos+"/data.zip" || r.Path != "vendor/"+goos+".zip" {
+		t.Errorf("resource = %+v, want the %s URL and path", r, goos)
+	}
+
+	// Saving writes the values as they were written
+	saved := filepath.Join(dir, "saved.yml")
+	if err := SaveConfig(cfg, saved); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, raw := range []string{"src/${os}/main.c", "${project_name}-${arch}", "-I${SDK_ROOT}/include", "-DLITERAL=$${os}", "vendor/${os}.zip"} {
+		if !strings.Contains(string(data), raw) {
+			t.Errorf("SaveConfig() wrote %q expanded:\n%s", raw, data)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("project_name: demo\nflags: [\"-I${CATALYST_UNSET_VAR}\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), "CATALYST_UNSET_VAR is not set") {
+		t.Errorf("LoadConfig() with an unset variable = %v, want an error naming it", err)
+	}
+}
+
+func TestSaveConfigRestoresOnlyExpandedValues(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("EMPTY", "")
+	yml := `project_name: app
+output: ${project_name}
+flags: ["-DNAME=app", "${EMPTY:-}", "-O2"]
+platforms:
+  linux:
+    flags: ["app"]
+features:
+  gui:
+    sources: ["gui/${os}.c"]
+`
+	cfg, err := loadYAML(t, yml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A value changed since loading is written as it is now
+	cfg.Features["gui"].Sources[0] = "gui/window.c"
+
+	saved := filepath.Join(dir, "saved.yml")
+	if err := SaveConfig(cfg, saved); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := LoadConfig(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.ProjectName != "app" || reloaded.Output != "app" {
+		t.Errorf("project_name, output = %q, %q after a round trip, want app, app", reloaded.ProjectName, reloaded.Output)
+	}
+	if want := []string{"-DNAME=app", "", "-O2"}; !reflect.DeepEqual(reloaded.Flags, want) {
+		t.Errorf("flags = %q after a round trip, want %q", reloaded.Flags, want)
+	}
+	if want := []string{"app"}; !reflect.DeepEqual(reloaded.Platforms["linux"].Flags, want) {
+		t.Errorf("platforms.linux.flags = %q after a round trip, want %q", reloaded.Platforms["linux"].Flags, want)
+	}
+	if want := []string{"gui/window.c"}; !reflect.DeepEqual(reloaded.Features["gui"].Sources, want) {
+		t.Errorf("features.gui.sources = %q after a round trip, want %q", reloaded.Features["gui"].Sources, want)
+	}
+	data, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"project_name: app\n", "output: ${project_name}\n", `- "${EMPTY:-}"`} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("SaveConfig() didn't write %q:\n%s", line, data)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package events is catalyst's progress model. Resolution, installs and
+// builds emit events as they go, and every frontend subscribes to the same
+// stream: the console renderer, the --events-json file and programs
+// embedding catalyst.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+)
+
+// Kind is what happened
+type Kind string
+
+const (
+	ResolutionStarted      Kind = "resolution_started"       // Name is the header being resolved
+	ResolutionFinished     Kind = "resolution_finished"      // Package is what it resolved to, empty if unresolved
+	PackageInstallStarted  Kind = "package_install_started"  // Name is the package
+	PackageInstallFinished Kind = "package_install_finished" // Error is set if it failed
+	FileCompiled           Kind = "file_compiled"            // Name is the source, Output its object file
+	LinkFinished           Kind = "link_finished"            // Output is the binary or library
+)
+
+// Event is one step of an operation
+type Event struct {
+	Kind     Kind          `json:"kind"`
+	Time     time.Time     `json:"time"`
+	Name     string        `json:"name,omitempty"`
+	Package  string        `json:"package,omitempty"`
+	Strategy string        `json:"strategy,omitempty"` // Resolution step that found the package
+	Output   string        `json:"output,omitempty"`
+	Duration time.Duration `json:"duration_ns,omitempty"` // Of the step the event finishes
+	Error    string        `json:"error,omitempty"`
+}
+
+// Err returns the error message of err, empty for nil, for Event.Error
+func Err(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+type subscriber struct{ fn func(Event) }
+
+var (
+	mu          sync.Mutex
+	subscribers []*subscriber
+)
+
+// Subscribe calls fn with every event emitted until the returned function is
+// called. Events are delivered in order on the goroutine emitting them.
+func Subscribe(fn func(Event)) (unsubscribe func()) {
+	s := &subscriber{fn}
+	mu.Lock()
+	subscribers = append(subscribers, s)
+	mu.Unlock()
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		subscribers = slices.DeleteFunc(subscribers, func(other *subscriber) bool { return other == s })
+	}
+}
+
+// Emit sends e to the subscribers, stamping it with the current time
+func Emit(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	mu.Lock()
+	current := slices.Clone(subscribers)
+	mu.Unlock()
+	for _, s := range current {
+		s.fn(e)
+	}
+}
+
+// Console renders events as catalyst's console output
+func Console(e Event) {
+	switch e.Kind {
+	case ResolutionFinished:
+		if e.Package != "" {
+			log.Debugf("Resolved %s to %s (%s)\n", e.Name, e.Package, e.Strategy)
+		}
+	case PackageInstallFinished:
+		if e.Error == "" {
+			log.Debugf("Installed %s in %s\n", e.Name, e.Duration.Round(time.Millisecond))
+		}
+	case FileCompiled:
+		log.Infof("  %s\n", e.Name)
+	case LinkFinished:
+		if e.Error == "" {
+			log.Infof("Compilation successful: %s\n", e.Output)
+		}
+	}
+}
+
+// JSONLines returns a subscriber writing each event to w as a line of JSON
+func JSONLines(w io.Writer) func(Event) {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(e)
+	}
+}
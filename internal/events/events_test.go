@@ -0,0 +1,41 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	var got []Kind
+	unsubscribe := Subscribe(func(e Event) {
+		if e.Time.IsZero() {
+			t.Errorf("%s event has no time", e.Kind)
+		}
+		got = append(got, e.Kind)
+	})
+	Emit(Event{Kind: ResolutionStarted, Name: "zlib.h"})
+	Emit(Event{Kind: ResolutionFinished, Name: "zlib.h", Package: "zlib1g-dev"})
+	unsubscribe()
+	Emit(Event{Kind: FileCompiled, Name: "main.c"})
+
+	if len(got) != 2 || got[0] != ResolutionStarted || got[1] != ResolutionFinished {
+		t.Errorf("got events %q, want resolution started and finished", got)
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	var b bytes.Buffer
+	write := JSONLines(&b)
+	write(Event{Kind: LinkFinished, Output: "build/app", Duration: 2 * time.Second, Error: Err(errors.New("ld failed"))})
+
+	var e map[string]any
+	if err := json.Unmarshal(b.Bytes(), &e); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", b.String(), err)
+	}
+	if e["kind"] != "link_finished" || e["output"] != "build/app" || e["duration_ns"] != float64(2e9) || e["error"] != "ld failed" {
+		t.Errorf("unexpected event %v", e)
+	}
+}
@@ -1 +1,110 @@
 package fetch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestUndefinedSymbols(t *testing.T) {
+	tests := map[string]struct {
+		output string
+		want   []string
+	}{
+		"gnu ld": {
+			"/usr/bin/ld: /tmp/ccX.o: in function `main':\nmain.c:(.text+0x9): undefined reference to `curl_easy_init'\ncollect2: error: ld returned 1 exit status\n",
+			[]string{"curl_easy_init"},
+		},
+		"lld": {
+			"ld.lld: error: undefined symbol: sqlite3_open\n>>> referenced by main.c\n",
+			[]string{"sqlite3_open"},
+		},
+		"msvc": {
+			"main.obj : error LNK2019: unresolved external symbol curl_easy_init referenced in function main\n" +
+				"util.obj : error LNK2001: unresolved external symbol _hash_insert\n" +
+				"main.obj : error LNK2019: unresolved external symbol \"int __cdecl parse(char const *)\" (?parse@@YAHPEBD@Z) referenced in function main\n" +
+				"app.exe : fatal error LNK1120: 3 unresolved externals\n",
+			[]string{"curl_easy_init", "hash_insert", "int __cdecl parse(char const *)"},
+		},
+		"apple ld": {
+			"Undefined symbols for architecture arm64:\n  \"_curl_easy_init\", referenced from:\n      _main in main-5d1f.o\nld: symbol(s) not found for architecture arm64\n",
+			[]string{"curl_easy_init"},
+		},
+	}
+	for name, tt := range tests {
+		if got := undefinedSymbols(tt.output); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: undefinedSymbols() = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestParseObjectSymbols(t *testing.T) {
+	gnu := "0000000000000000 T main\n                 U curl_easy_init\n                 w __gmon_start__\n0000000000000004 D counter\n"
+	got := parseNMSymbols(gnu, false)
+	if want := (objectSymbols{defined: []string{"main", "counter"}, undefined: []string{"curl_easy_init"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNMSymbols(gnu) = %+v, want %+v", got, want)
+	}
+
+	bsd := "0000000000000000 T _main\n                 U _curl_easy_init\n"
+	got = parseNMSymbols(bsd, true)
+	if want := (objectSymbols{defined: []string{"main"}, undefined: []string{"curl_easy_init"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNMSymbols(bsd) = %+v, want %+v", got, want)
+	}
+
+	dumpbin := "COFF SYMBOL TABLE\r\n" +
+		"000 01047A3E ABS    notype       Static       | @comp.id\r\n" +
+		"008 00000000 SECT3  notype ()    External     | main\r\n" +
+		"009 00000000 UNDEF  notype ()    External     | curl_easy_init\r\n"
+	got = parseDumpbinSymbols(dumpbin)
+	if want := (objectSymbols{defined: []string{"main"}, undefined: []string{"curl_easy_init"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDumpbinSymbols = %+v, want %+v", got, want)
+	}
+}
+
+func TestGroupMissingSymbols(t *testing.T) {
+	groups := groupMissingSymbols([]SymbolInfo{
+		{Symbol: "curl_easy_init", File: "net.c"},
+		{Symbol: "sqrtf"},
+		{Symbol: "curl_easy_cleanup"},
+		{Symbol: "hashmap_get"},
+	})
+	var got []string
+	for _, g := range groups {
+		got = append(got, g.Category+":"+strings.Join(ExtractSymbolNames(g.Symbols), ",")+":"+strings.Join(g.SuggestedLibs, ","))
+	}
+	want := []string{"curl:curl_easy_init,curl_easy_cleanup:curl", "m:sqrtf:m", "hashmap:hashmap_get:glib-2.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupMissingSymbols = %q, want %q", got, want)
+	}
+}
+
+func TestScanDependenciesSorted(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.c":   "#include <zlib.h>\n#include <curl/curl.h>\n#include \"util.h\"\n",
+		"util.c":   "#include <sqlite3.h>\n#include <curl/curl.h>\n",
+		"util.h":   "#include <stdio.h>\n",
+		"notes.md": "#include <ignored.h>\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := ScanDependencies(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sort.StringsAreSorted(got) {
+		t.Errorf("ScanDependencies = %q, want sorted", got)
+	}
+	for _, dep := range []string{"curl", "sqlite3", "zlib"} {
+		if !slices.Contains(got, dep) {
+			t.Errorf("ScanDependencies = %q, missing %s", got, dep)
+		}
+	}
+}
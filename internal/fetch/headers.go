@@ -7,7 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/headers"
 )
 
 // systemIncludeRegex matches system includes and extracts the package name
@@ -65,11 +68,13 @@ func ScanDependencies(rootDir string) ([]string, error) {
 		return nil, fmt.Errorf("failed to walk directory tree: %w", err)
 	}
 
-	// Convert map to slice
+	// Convert map to slice, sorted so generated configs don't change
+	// from run to run
 	result := make([]string, 0, len(uniqueDeps))
 	for dep := range uniqueDeps {
 		result = append(result, dep)
 	}
+	sort.Strings(result)
 
 	return result, nil
 }
@@ -125,3 +130,133 @@ func extractDependenciesFromFile(filePath string) ([]string, error) {
 
 	return deps, nil
 }
+
+// LocalHeaderNames returns the names (without extension) of the header files
+// in a project, so project headers found by ScanDependencies can be told
+// apart from system ones
+func LocalHeaderNames(rootDir string) map[string]bool {
+	names := make(map[string]bool)
+	filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != rootDir && (strings.HasPrefix(name, ".") || name == "build") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext == ".h" || ext == ".hpp" {
+			names[strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))] = true
+		}
+		return nil
+	})
+	return names
+}
+
+// ScanSourceDependencies extracts header dependencies from the given source
+// files and the local headers they include, directly or transitively, so
+// unrelated code elsewhere in rootDir is not picked up. Local includes are
+// looked up next to the including file, then in includeDirs and rootDir.
+func ScanSourceDependencies(rootDir string, sources, includeDirs []string) ([]string, error) {
+	uniqueDeps := make(map[string]bool)
+	visited := make(map[string]bool)
+
+	var queue []string
+	for _, src := range sources {
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(rootDir, src)
+		}
+		queue = append(queue, filepath.Clean(src))
+	}
+
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		if visited[path] {
+			continue
+		}
+		visited[path] = true
+
+		deps, err := extractDependenciesFromFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to process %s: %v\n", path, err)
+			continue
+		}
+		for _, dep := range deps {
+			uniqueDeps[dep] = true
+		}
+
+		includes, err := localIncludes(path)
+		if err != nil {
+			continue
+		}
+		for _, include := range includes {
+			if resolved := resolveLocalInclude(include, filepath.Dir(path), rootDir, includeDirs); resolved != "" {
+				queue = append(queue, resolved)
+			}
+		}
+	}
+
+	result := make([]string, 0, len(uniqueDeps))
+	for dep := range uniqueDeps {
+		result = append(result, dep)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// localIncludes returns the paths named by #include "..." in a file
+func localIncludes(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	var includes []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if matches := localIncludeRegex.FindStringSubmatch(line); len(matches) >= 2 {
+			includes = append(includes, matches[1])
+		}
+	}
+
+	return includes, scanner.Err()
+}
+
+// resolveLocalInclude finds the file for a local include, returning "" if it
+// is not part of the project
+func resolveLocalInclude(include, fromDir, rootDir string, includeDirs []string) string {
+	dirs := []string{fromDir}
+	for _, dir := range includeDirs {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(rootDir, dir)
+		}
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, rootDir)
+
+	for _, dir := range dirs {
+		candidate := filepath.Clean(filepath.Join(dir, include))
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// ExternalDependencies drops the standard, POSIX, Windows SDK and compiler
+// headers from the names returned by ScanDependencies
+func ExternalDependencies(deps []string) []string {
+	var external []string
+	for _, dep := range deps {
+		if !headers.IsStandard(dep) {
+			external = append(external, dep)
+		}
+	}
+	return external
+}
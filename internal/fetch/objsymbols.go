@@ -0,0 +1,225 @@
+package fetch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// objectSymbols are the external symbols of an object file
+type objectSymbols struct {
+	defined, undefined []string
+}
+
+// cIdentifier matches the symbols the probe program can declare
+var cIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ScanObjectSymbols finds missing symbols like ScanMissingSymbols, but
+// compiles each source file on its own and reads the undefined symbols of
+// the objects with nm (dumpbin with MSVC) instead of linking the project.
+// Projects that don't link yet, because of a duplicate main or a file that
+// doesn't compile, can still be analyzed, and each symbol is traced to the
+// file using it. flagsFor returns the compile flags of a source, by its
+// absolute path (the project's include directories and defines); nil
+// compiles every source without flags.
+func ScanObjectSymbols(projectPath string, flagsFor func(src string) []string) ([]MissingDependency, error) {
+	sourceFiles, err := findSourceFiles(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find source files: %w", err)
+	}
+	if len(sourceFiles) == 0 {
+		return nil, nil
+	}
+
+	compiler, err := compile.HostCompiler()
+	if err != nil {
+		return nil, err
+	}
+	msvc := compiler.ObjectExt() == ".obj"
+	tool := "nm"
+	if msvc {
+		tool = "dumpbin"
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("%s not found in PATH", tool)
+	}
+
+	dir, err := util.MkdirTemp("symbols")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	defined := make(map[string]bool)
+	usedBy := make(map[string]string) // Undefined symbol -> first source using it
+	var undefined []string
+	for i, src := range sourceFiles {
+		object := filepath.Join(dir, strconv.Itoa(i)+"-"+strings.TrimSuffix(filepath.Base(src), filepath.Ext(src))+compiler.ObjectExt())
+		var flags []string
+		if flagsFor != nil {
+			if abs, err := filepath.Abs(filepath.Join(projectPath, src)); err == nil {
+				flags = flagsFor(abs)
+			}
+		}
+		cmd := util.Command(compiler.Command(), compiler.CompileObject(src, object, flags)...)
+		cmd.Dir = projectPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			log.Warnf("Skipping %s, which doesn't compile: %s\n", src, firstLine(string(output)))
+			continue
+		}
+
+		symbols, err := readObjectSymbols(object, msvc)
+		if err != nil {
+			return nil, err
+		}
+		for _, symbol := range symbols.defined {
+			defined[symbol] = true
+		}
+		for _, symbol := range symbols.undefined {
+			if _, ok := usedBy[symbol]; !ok {
+				usedBy[symbol] = src
+				undefined = append(undefined, symbol)
+			}
+		}
+	}
+
+	// C++ symbols can't be told apart from the C++ runtime's without linking
+	// it, so they're left to the link scan
+	candidates := slices.DeleteFunc(undefined, func(symbol string) bool {
+		return defined[symbol] || !cIdentifier.MatchString(symbol) || strings.HasPrefix(symbol, "_Z")
+	})
+	missing, err := unresolvedSymbols(compiler, dir, candidates)
+	if err != nil || len(missing) == 0 {
+		return nil, err
+	}
+
+	var infos []SymbolInfo
+	for _, symbol := range missing {
+		infos = append(infos, SymbolInfo{Symbol: symbol, File: usedBy[symbol], Type: "function"})
+	}
+	return groupMissingSymbols(infos), nil
+}
+
+// readObjectSymbols lists the external symbols of an object file
+func readObjectSymbols(object string, msvc bool) (objectSymbols, error) {
+	if msvc {
+		output, err := util.ParsedCommand("dumpbin", "/nologo", "/symbols", object).Output()
+		if err != nil {
+			return objectSymbols{}, fmt.Errorf("dumpbin failed on %s: %w", object, err)
+		}
+		return parseDumpbinSymbols(string(output)), nil
+	}
+	output, err := util.ParsedCommand("nm", "-g", object).Output()
+	if err != nil {
+		return objectSymbols{}, fmt.Errorf("nm failed on %s: %w", object, err)
+	}
+	return parseNMSymbols(string(output), runtime.GOOS == "darwin"), nil
+}
+
+// parseNMSymbols parses nm -g output, GNU or BSD: "0000000000000000 T main"
+// for defined symbols and "                 U curl_easy_init" for undefined
+// ones. Mach-O names carry an underscore, removed when underscored is set.
+func parseNMSymbols(output string, underscored bool) objectSymbols {
+	var symbols objectSymbols
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		kind, name := fields[len(fields)-2], fields[len(fields)-1]
+		if underscored {
+			name = strings.TrimPrefix(name, "_")
+		}
+		switch {
+		case kind == "U" && len(fields) == 2:
+			symbols.undefined = append(symbols.undefined, name)
+		case len(fields) == 3 && kind != "U" && kind != "w" && kind != "v":
+			symbols.defined = append(symbols.defined, name)
+		}
+	}
+	return symbols
+}
+
+// dumpbinSymbol matches an external symbol in dumpbin /symbols output:
+// "00A 00000000 UNDEF  notype ()    External     | curl_easy_init"
+var dumpbinSymbol = regexp.MustCompile(`^[0-9A-F]+ [0-9A-F]+ (\S+)\s.*\bExternal\s+\| (\S+)`)
+
+// parseDumpbinSymbols parses dumpbin /symbols output
+func parseDumpbinSymbols(output string) objectSymbols {
+	var symbols objectSymbols
+	for _, line := range strings.Split(output, "\n") {
+		m := dumpbinSymbol.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		if m[1] == "UNDEF" {
+			symbols.undefined = append(symbols.undefined, m[2])
+		} else {
+			symbols.defined = append(symbols.defined, m[2])
+		}
+	}
+	return symbols
+}
+
+// unresolvedSymbols returns the symbols the C library and the compiler's
+// default libraries don't define. A probe program referencing them all is
+// linked, and the linker names the ones it can't find.
+func unresolvedSymbols(compiler compile.Compiler, dir string, symbols []string) ([]string, error) {
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+
+	var probe strings.Builder
+	for _, symbol := range symbols {
+		fmt.Fprintf(&probe, "extern char %s[];\n", symbol)
+	}
+	probe.WriteString("void *catalyst_probe_refs[] = {\n")
+	for _, symbol := range symbols {
+		fmt.Fprintf(&probe, "\t%s,\n", symbol)
+	}
+	probe.WriteString("};\nint main(void) { return catalyst_probe_refs[0] == 0; }\n")
+
+	src := filepath.Join(dir, "probe.c")
+	if err := os.WriteFile(src, []byte(probe.String()), 0644); err != nil {
+		return nil, err
+	}
+	flags := []string{"-w"}
+	if compiler.ObjectExt() == ".obj" {
+		flags = append(flags, "/Fo"+dir+string(filepath.Separator))
+	}
+	output, err := util.Command(compiler.Command(), compiler.Link([]string{src}, filepath.Join(dir, "probe"), flags)...).CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+
+	var missing []string
+	for _, symbol := range removeDuplicateStrings(undefinedSymbols(string(output))) {
+		if slices.Contains(symbols, symbol) {
+			missing = append(missing, symbol)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, fmt.Errorf("failed to link the symbol probe: %s", firstLine(string(output)))
+	}
+	return missing, nil
+}
+
+// firstLine returns the first non-empty line of compiler output
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
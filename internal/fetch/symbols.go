@@ -3,10 +3,13 @@ package fetch
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/compile"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 )
 
 // SymbolInfo represents information about undefined symbols
@@ -37,15 +40,28 @@ func ScanMissingSymbols(projectPath string) ([]MissingDependency, error) {
 		return nil, nil // No source files to analyze
 	}
 
+	compiler, err := compile.HostCompiler()
+	if err != nil {
+		return nil, err
+	}
+
 	// Try linking directly to catch undefined symbols
-	linkArgs := append(sourceFiles, "-o", "/tmp/catalyst_test_link")
-	cmd := exec.Command("gcc", linkArgs...)
+	binary, err := util.TempPath("link-test")
+	if err != nil {
+		return nil, err
+	}
+	var flags []string
+	if compiler.ObjectExt() == ".obj" {
+		// cl writes objects to the working directory, the project, otherwise
+		flags = append(flags, "/Fo"+filepath.Dir(binary)+string(filepath.Separator))
+	}
+	cmd := util.Command(compiler.Command(), compiler.Link(sourceFiles, binary, flags)...)
 	cmd.Dir = projectPath
 
 	output, err := cmd.CombinedOutput()
 
 	// Always clean up test files
-	os.Remove("/tmp/catalyst_test_link")
+	os.RemoveAll(filepath.Dir(binary))
 
 	if err == nil {
 		return nil, nil // No missing symbols
@@ -90,45 +106,121 @@ func findSourceFiles(projectPath string) ([]string, error) {
 	return sources, err
 }
 
-// parseLinkErrors parses compiler/linker output for undefined symbols
-func parseLinkErrors(output string) ([]MissingDependency, error) {
-	var dependencies []MissingDependency
+var (
+	// GNU ld: "undefined reference to `curl_easy_init'"; lld: "undefined
+	// symbol: curl_easy_init"
+	gnuUndefined = regexp.MustCompile("(?:undefined reference to `([^']+)'|undefined symbol: (\\S+))")
+	// MSVC: "main.obj : error LNK2019: unresolved external symbol
+	// curl_easy_init referenced in function main"; C++ symbols are quoted
+	// before their decorated name
+	msvcUndefined = regexp.MustCompile(`unresolved external symbol (?:"([^"]+)"|(\S+))`)
+	// Apple ld lists each symbol after "Undefined symbols for architecture
+	// arm64:" as `  "_curl_easy_init", referenced from:`
+	appleUndefined = regexp.MustCompile(`(?m)^\s*"(.+)", referenced from:`)
+)
 
-	// Regex to match: "undefined reference to `function_name'"
-	re := regexp.MustCompile(`undefined reference to \x60([^']+)'`)
-	matches := re.FindAllStringSubmatch(output, -1)
+// undefinedSymbols returns the symbols GNU ld, lld, MSVC's link or Apple's
+// ld reported missing, as they're named in C
+func undefinedSymbols(output string) []string {
+	var symbols []string
+	for _, m := range gnuUndefined.FindAllStringSubmatch(output, -1) {
+		symbols = append(symbols, m[1]+m[2])
+	}
+	for _, m := range msvcUndefined.FindAllStringSubmatch(output, -1) {
+		symbol := m[1]
+		if symbol == "" {
+			// 32-bit C symbols carry an underscore
+			symbol = strings.TrimPrefix(m[2], "_")
+		}
+		symbols = append(symbols, symbol)
+	}
+	if strings.Contains(output, "Undefined symbols for architecture") {
+		for _, m := range appleUndefined.FindAllStringSubmatch(output, -1) {
+			// Mach-O prefixes C symbols with an underscore
+			symbols = append(symbols, strings.TrimPrefix(m[1], "_"))
+		}
+	}
+	return symbols
+}
 
+// parseLinkErrors parses compiler/linker output for undefined symbols
+func parseLinkErrors(output string) ([]MissingDependency, error) {
+	matches := undefinedSymbols(output)
 	if len(matches) == 0 {
 		return nil, nil
 	}
+	return groupMissingSymbols(convertToSymbolInfo(removeDuplicateStrings(matches))), nil
+}
 
-	// Group symbols by category
-	symbols := make(map[string][]string)
-	for _, match := range matches {
-		if len(match) > 1 {
-			symbol := match[1]
-			category := categorizeSymbol(symbol)
-			symbols[category] = append(symbols[category], symbol)
+// groupMissingSymbols groups missing symbols by the library known to define
+// them, or else by category, and suggests fixes for each group
+func groupMissingSymbols(missing []SymbolInfo) []MissingDependency {
+	var dependencies []MissingDependency
+	groups := make(map[string]int)
+	for _, symbol := range missing {
+		lib := libraryForSymbol(symbol.Symbol)
+		category := lib
+		if category == "" {
+			category = categorizeSymbol(symbol.Symbol)
 		}
-	}
 
-	// Remove duplicates and generate suggestions
-	for category, symbolList := range symbols {
-		// Remove duplicates
-		uniqueSymbols := removeDuplicateStrings(symbolList)
-
-		dep := MissingDependency{
-			Symbols:  convertToSymbolInfo(uniqueSymbols),
-			Category: category,
+		i, ok := groups[category]
+		if !ok {
+			dep := MissingDependency{Category: category}
+			if lib != "" {
+				dep.SuggestedLibs = []string{lib}
+				dep.PossibleCauses = []string{fmt.Sprintf("Need to link the %s library (-l%s)", lib, lib)}
+			} else {
+				generateSuggestions(&dep, category)
+			}
+			i = len(dependencies)
+			groups[category] = i
+			dependencies = append(dependencies, dep)
 		}
+		dependencies[i].Symbols = append(dependencies[i].Symbols, symbol)
+	}
+	return dependencies
+}
 
-		// Generate suggestions based on symbol category
-		generateSuggestions(&dep, category)
+// symbolLibraries maps the prefixes of well-known libraries' symbols to the
+// library defining them
+var symbolLibraries = []struct{ prefix, lib string }{
+	{"curl_", "curl"},
+	{"sqlite3_", "sqlite3"},
+	{"SSL_", "ssl"},
+	{"EVP_", "crypto"},
+	{"pthread_", "pthread"},
+	{"json_", "jansson"},
+	{"cJSON_", "cjson"},
+	{"png_", "png"},
+	{"SDL_", "SDL2"},
+	{"glfw", "glfw"},
+	{"uv_", "uv"},
+	{"yaml_", "yaml"},
+	{"deflate", "z"},
+	{"inflate", "z"},
+	{"archive_", "archive"},
+}
 
-		dependencies = append(dependencies, dep)
-	}
+// mathFunctions are the libm functions glibc doesn't provide without -lm
+var mathFunctions = []string{
+	"sqrt", "pow", "exp", "log", "log10", "log2", "sin", "cos", "tan",
+	"asin", "acos", "atan", "atan2", "sinh", "cosh", "tanh", "floor", "ceil",
+	"fmod", "round", "trunc", "hypot", "cbrt", "fabs",
+}
 
-	return dependencies, nil
+// libraryForSymbol returns the library known to define a symbol, or ""
+func libraryForSymbol(symbol string) string {
+	name := strings.TrimSuffix(symbol, "f") // sqrtf, powf, ...
+	if slices.Contains(mathFunctions, symbol) || slices.Contains(mathFunctions, name) {
+		return "m"
+	}
+	for _, known := range symbolLibraries {
+		if strings.HasPrefix(symbol, known.prefix) {
+			return known.lib
+		}
+	}
+	return ""
 }
 
 // categorizeSymbol determines the category of a missing symbol
@@ -0,0 +1,226 @@
+// Package guard confirms operations that overwrite or delete the user's
+// files before they run, and records what they changed in the project's
+// .catalyst/changes.log so it can be reviewed afterwards.
+package guard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// LogPath returns the change log: .catalyst/changes.log in the project or
+// the build root
+func LogPath() string {
+	return util.StateDir("changes.log")
+}
+
+// ErrDeclined is returned when a destructive operation wasn't confirmed
+var ErrDeclined = errors.New("not confirmed (pass --yes to skip confirmation)")
+
+// AssumeYes confirms every operation without asking (--yes)
+var AssumeYes bool
+
+// Ask asks a yes/no question; nil declines everything. By default it asks
+// on the terminal and declines when stdin isn't one.
+var Ask = askOnTerminal
+
+// Interactive reports whether there is a terminal to ask on
+var Interactive = stdinIsTerminal
+
+// Confirm asks whether to go ahead with an operation, unless AssumeYes is set
+func Confirm(question string) bool {
+	return AssumeYes || (Ask != nil && Ask(question))
+}
+
+// confirmChange asks whether to change the user's files like Confirm, but
+// goes ahead without a terminal to ask on, as catalyst did before it asked,
+// so scripts and CI keep working; the change is still recorded
+func confirmChange(question string) bool {
+	if !AssumeYes && !Interactive() {
+		log.Infof("%s Yes: not asking without a terminal.\n", question)
+		return true
+	}
+	return Confirm(question)
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func askOnTerminal(question string) bool {
+	if !Interactive() {
+		log.Warnf("%s Not asking without a terminal; pass --yes to confirm.\n", question)
+		return false
+	}
+	fmt.Printf("%s (y/N): ", question)
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	response = strings.TrimSpace(response)
+	return response == "y" || response == "Y"
+}
+
+// ConfirmReplace shows how a file would change and asks whether to
+// overwrite it
+func ConfirmReplace(path string, old, new []byte) bool {
+	log.Infof("%s would change:\n%s", path, Diff(string(old), string(new)))
+	return confirmChange(fmt.Sprintf("Overwrite %s?", path))
+}
+
+// RecordReplace records that a file was overwritten, once it has been
+func RecordReplace(path string, old, new []byte) {
+	Record("overwrote %s\n%s", path, Diff(string(old), string(new)))
+}
+
+// Remove deletes the paths after listing them and asking, recording each
+// removal. Paths that don't exist are skipped.
+func Remove(paths []string) error {
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+
+	log.Info("This removes:")
+	descriptions := make([]string, len(existing))
+	for i, path := range existing {
+		descriptions[i] = describe(path)
+		log.Infof("  %s\n", descriptions[i])
+	}
+	if !confirmChange(fmt.Sprintf("Remove %d path(s)?", len(existing))) {
+		return ErrDeclined
+	}
+	for i, path := range existing {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		log.Infof("Removed %s\n", path)
+		Record("removed %s", descriptions[i])
+	}
+	return nil
+}
+
+// describe names a path for confirmations and the log, with its size for
+// directories
+func describe(path string) string {
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return path
+	}
+	files := 0
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files++
+		}
+		return nil
+	})
+	return fmt.Sprintf("%s/ (%d files)", filepath.ToSlash(path), files)
+}
+
+// Record appends a timestamped entry to the change log. Failing to write
+// the log only warns, since the change itself has already been made.
+func Record(format string, args ...any) {
+	entry := fmt.Sprintf(format, args...)
+	path := LogPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		var f *os.File
+		if f, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+			_, err = fmt.Fprintf(f, "%s %s %s\n", time.Now().Format(time.RFC3339), commandLine(), strings.TrimRight(entry, "\n"))
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err == nil {
+			return
+		}
+	}
+	log.Warnf("Could not record the change in %s\n", path)
+}
+
+// commandLine is the catalyst command making the change, e.g. [catalyst clean]
+func commandLine() string {
+	return "[" + strings.TrimSpace("catalyst "+strings.Join(os.Args[1:], " ")) + "]"
+}
+
+// Diff returns a line diff of old and new, with "-" and "+" marking removed
+// and added lines and two lines of unchanged context around each change
+func Diff(old, new string) string {
+	a, b := splitLines(old), splitLines(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, line{' ', a[i]})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', b[j]})
+			j++
+		}
+	}
+
+	const context = 2
+	var out strings.Builder
+	lastShown := -1
+	for k, l := range lines {
+		near := false
+		for d := max(0, k-context); d <= min(len(lines)-1, k+context); d++ {
+			if lines[d].op != ' ' {
+				near = true
+				break
+			}
+		}
+		if !near {
+			continue
+		}
+		if lastShown >= 0 && k > lastShown+1 {
+			out.WriteString("  ...\n")
+		}
+		fmt.Fprintf(&out, "%c %s\n", l.op, l.text)
+		lastShown = k
+	}
+	return out.String()
+}
+
+// splitLines splits text into lines without their line endings
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
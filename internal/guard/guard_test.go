@@ -0,0 +1,80 @@
+package guard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := "project_name: app\nsources:\n  - main.c\n  - util.c\nflags:\n  - -Wall\n  - -O2\n  - -g\n  - -pedantic\n  - -std=c99\n"
+	new := "project_name: app\nsources:\n  - main.c\nflags:\n  - -Wall\n  - -O2\n  - -g\n  - -pedantic\n  - -std=c11\n"
+	want := "  sources:\n    - main.c\n-   - util.c\n  flags:\n    - -Wall\n  ...\n    - -g\n    - -pedantic\n-   - -std=c99\n+   - -std=c11\n"
+	if got := Diff(old, new); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Diff("a\n", "a\r\n"); got != "" {
+		t.Errorf("Diff() of the same lines = %q, want none", got)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := t.TempDir()
+	cwd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(cwd)
+	os.MkdirAll(filepath.Join("build", "obj"), 0755)
+	os.WriteFile(filepath.Join("build", "obj", "main.o"), nil, 0644)
+	os.WriteFile("a.out", nil, 0755)
+
+	var asked []string
+	answer := false
+	Ask = func(question string) bool {
+		asked = append(asked, question)
+		return answer
+	}
+	Interactive = func() bool { return true }
+	defer func() { Ask, Interactive = askOnTerminal, stdinIsTerminal }()
+
+	if err := Remove([]string{"build", "a.out", "missing"}); err != ErrDeclined {
+		t.Fatalf("Remove() declined = %v, want ErrDeclined", err)
+	}
+	if _, err := os.Stat("build"); err != nil {
+		t.Error("declined Remove() deleted build/")
+	}
+	if len(asked) != 1 || asked[0] != "Remove 2 path(s)?" {
+		t.Errorf("asked %q", asked)
+	}
+
+	answer = true
+	if err := Remove([]string{"build", "a.out"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("build"); !os.IsNotExist(err) {
+		t.Error("build/ wasn't removed")
+	}
+	changes, err := os.ReadFile(LogPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"removed build/ (1 files)", "removed a.out"} {
+		if !strings.Contains(string(changes), want) {
+			t.Errorf("change log is missing %q:\n%s", want, changes)
+		}
+	}
+
+	// Scripts and CI have no terminal to answer on
+	answer = false
+	Interactive = func() bool { return false }
+	os.WriteFile("a.out", nil, 0755)
+	if err := Remove([]string{"a.out"}); err != nil {
+		t.Fatalf("Remove() without a terminal = %v", err)
+	}
+	if _, err := os.Stat("a.out"); !os.IsNotExist(err) {
+		t.Error("Remove() without a terminal didn't remove a.out")
+	}
+	if len(asked) != 2 {
+		t.Errorf("asked %q without a terminal", asked[2:])
+	}
+}
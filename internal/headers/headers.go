@@ -0,0 +1,128 @@
+// Package headers classifies the headers that ship with the C/C++ standard
+// libraries, POSIX systems, the Windows SDK and compilers, so they are never
+// treated as external dependencies.
+//
+// The lists live in standard_headers.json; refresh that file to add headers.
+package headers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+)
+
+// Category is the kind of standard header
+type Category string
+
+const (
+	C       Category = "c"       // C standard library
+	CXX     Category = "cxx"     // C++ standard library
+	POSIX   Category = "posix"   // POSIX and common libc extensions
+	Windows Category = "windows" // Windows SDK
+	Builtin Category = "builtin" // Shipped with the compiler (intrinsics, unwind)
+)
+
+//go:embed standard_headers.json
+var standardHeadersJSON []byte
+
+// headerData is the layout of standard_headers.json
+type headerData struct {
+	C           []string `json:"c"`
+	CXX         []string `json:"cxx"`
+	POSIX       []string `json:"posix"`
+	Windows     []string `json:"windows"`
+	Builtin     []string `json:"builtin"`
+	Directories []string `json:"directories"` // Include directories whose headers are all standard
+	// Import libraries (without extension) that Windows SDK headers need at link time
+	WindowsLibraries map[string][]string `json:"windows_libraries"`
+}
+
+var (
+	byHeader    = make(map[string]Category) // "sys/socket.h" and "sys/socket"
+	byDir       = make(map[string]Category) // "sys"
+	windowsLibs = make(map[string][]string) // "winsock2.h" and "winsock2"
+	windowsLib  = make(map[string]bool)     // "ws2_32"
+)
+
+func init() {
+	var data headerData
+	if err := json.Unmarshal(standardHeadersJSON, &data); err != nil {
+		panic("invalid standard_headers.json: " + err.Error())
+	}
+
+	for category, list := range map[Category][]string{C: data.C, CXX: data.CXX, POSIX: data.POSIX, Windows: data.Windows, Builtin: data.Builtin} {
+		for _, header := range list {
+			// C++ headers have no extension and must not shadow the C ones (string vs string.h)
+			if _, exists := byHeader[header]; !exists {
+				byHeader[header] = category
+			}
+			if name := strings.TrimSuffix(header, ".h"); name != header {
+				if _, exists := byHeader[name]; !exists {
+					byHeader[name] = category
+				}
+			}
+		}
+	}
+	for _, dir := range data.Directories {
+		byDir[dir] = POSIX
+	}
+	for header, libs := range data.WindowsLibraries {
+		windowsLibs[header] = libs
+		windowsLibs[strings.TrimSuffix(header, ".h")] = libs
+		for _, lib := range libs {
+			windowsLib[lib] = true
+		}
+	}
+}
+
+// Classify returns the category of a standard header. It accepts include
+// paths ("sys/socket.h", "iostream"), names without extension ("stdio") and
+// the top-level directory names dependency scanning reports ("sys").
+func Classify(header string) (Category, bool) {
+	header = strings.ToLower(strings.Trim(header, "<>\" "))
+	if category, ok := byHeader[header]; ok {
+		return category, true
+	}
+	if category, ok := byDir[header]; ok {
+		return category, true
+	}
+	if dir, _, found := strings.Cut(header, "/"); found {
+		if category, ok := byDir[dir]; ok {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// WindowsLibraries returns the import libraries, without extension, that a
+// Windows SDK header needs at link time (winsock2.h needs ws2_32)
+func WindowsLibraries(header string) []string {
+	return windowsLibs[strings.ToLower(strings.Trim(header, "<>\" "))]
+}
+
+// IsWindowsLibrary reports whether name ("ws2_32" or "ws2_32.lib") is an
+// import library of a known Windows SDK header
+func IsWindowsLibrary(name string) bool {
+	return windowsLib[strings.TrimSuffix(strings.ToLower(name), ".lib")]
+}
+
+// WindowsLinkFlags returns the flags that link Windows import libraries:
+// "ws2_32.lib" for MSVC and "-lws2_32" for MinGW
+func WindowsLinkFlags(libs []string, msvc bool) []string {
+	flags := make([]string, 0, len(libs))
+	for _, lib := range libs {
+		lib = strings.TrimSuffix(strings.ToLower(lib), ".lib")
+		if msvc {
+			flags = append(flags, lib+".lib")
+		} else {
+			flags = append(flags, "-l"+lib)
+		}
+	}
+	return flags
+}
+
+// IsStandard reports whether a header needs no package to be installed
+func IsStandard(header string) bool {
+	_, ok := Classify(header)
+	return ok
+}
@@ -0,0 +1,54 @@
+package headers
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		header   string
+		category Category
+		ok       bool
+	}{
+		{"stdio.h", C, true},
+		{"stdio", C, true},
+		{"string", C, true},
+		{"iostream", CXX, true},
+		{"sys/socket.h", POSIX, true},
+		{"sys/unlisted.h", POSIX, true},
+		{"sys", POSIX, true},
+		{"netinet", POSIX, true},
+		{"dirent", POSIX, true},
+		{"Windows.h", Windows, true},
+		{"immintrin.h", Builtin, true},
+		{"curl/curl.h", "", false},
+		{"jansson", "", false},
+	}
+
+	for _, tt := range tests {
+		category, ok := Classify(tt.header)
+		if ok != tt.ok || category != tt.category {
+			t.Errorf("Classify(%q) = %q, %v, want %q, %v", tt.header, category, ok, tt.category, tt.ok)
+		}
+	}
+}
+
+func TestWindowsLibraries(t *testing.T) {
+	if libs := WindowsLibraries("WinSock2.h"); len(libs) != 1 || libs[0] != "ws2_32" {
+		t.Errorf("WindowsLibraries(WinSock2.h) = %v, want [ws2_32]", libs)
+	}
+	if libs := WindowsLibraries("stdio.h"); len(libs) != 0 {
+		t.Errorf("WindowsLibraries(stdio.h) = %v, want none", libs)
+	}
+
+	// Every header with libraries must also be classified as a Windows header
+	for header := range windowsLibs {
+		if category, _ := Classify(header); category != Windows {
+			t.Errorf("%s has Windows libraries but is classified as %q", header, category)
+		}
+	}
+
+	mingw := WindowsLinkFlags([]string{"ws2_32", "Shell32.lib"}, false)
+	msvc := WindowsLinkFlags([]string{"ws2_32", "Shell32.lib"}, true)
+	if mingw[0] != "-lws2_32" || mingw[1] != "-lshell32" || msvc[0] != "ws2_32.lib" || msvc[1] != "shell32.lib" {
+		t.Errorf("WindowsLinkFlags = %v (MinGW), %v (MSVC)", mingw, msvc)
+	}
+}
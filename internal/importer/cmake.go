@@ -0,0 +1,771 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	core "github.com/Sabique-Islam/catalyst/internal/config"
+)
+
+// cmakeArg is one argument of a CMake command
+type cmakeArg struct {
+	value  string
+	quoted bool // Quoted and bracket arguments aren't split into lists
+}
+
+// cmakeCommand is one command invocation in a CMakeLists.txt
+type cmakeCommand struct {
+	name string // Lowercase; CMake command names are case-insensitive
+	args []cmakeArg
+	line int
+}
+
+// parseCMake splits a CMakeLists.txt into its commands. Parentheses nested
+// in the arguments, as in if() conditions, are dropped.
+func parseCMake(src string) ([]cmakeCommand, error) {
+	var commands []cmakeCommand
+	line := 1
+	i := 0
+	advance := func(n int) {
+		line += strings.Count(src[i:i+n], "\n")
+		i += n
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			advance(1)
+			continue
+		case c == '#':
+			advance(commentLength(src[i:]))
+			continue
+		case !isIdentStart(c):
+			return nil, fmt.Errorf("line %d: unexpected %q", line, c)
+		}
+
+		start := i
+		for i < len(src) && isIdentChar(src[i]) {
+			i++
+		}
+		cmd := cmakeCommand{name: strings.ToLower(src[start:i]), line: line}
+		for i < len(src) && (src[i] == ' ' || src[i] == '\t') {
+			i++
+		}
+		if i >= len(src) || src[i] != '(' {
+			return nil, fmt.Errorf("line %d: expected ( after %s", line, cmd.name)
+		}
+		advance(1)
+
+		depth := 1
+		for depth > 0 {
+			if i >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated %s(", cmd.line, cmd.name)
+			}
+			switch c := src[i]; {
+			case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+				advance(1)
+			case c == '#':
+				advance(commentLength(src[i:]))
+			case c == '(':
+				depth++
+				advance(1)
+			case c == ')':
+				depth--
+				advance(1)
+			case c == '"':
+				value, n, err := quotedArgument(src[i:])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				cmd.args = append(cmd.args, cmakeArg{value: value, quoted: true})
+				advance(n)
+			case c == '[' && bracketLength(src[i:]) != 0:
+				n := bracketLength(src[i:])
+				if n < 0 {
+					return nil, fmt.Errorf("line %d: unterminated bracket argument", line)
+				}
+				open := strings.Index(src[i+1:], "[") + 2
+				value := strings.TrimPrefix(src[i+open:i+n-open], "\n")
+				cmd.args = append(cmd.args, cmakeArg{value: value, quoted: true})
+				advance(n)
+			default:
+				value, n := unquotedArgument(src[i:])
+				cmd.args = append(cmd.args, cmakeArg{value: value})
+				advance(n)
+			}
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || '0' <= c && c <= '9'
+}
+
+// commentLength returns the length of the comment at the start of s: a
+// bracket comment (#[[ ... ]]) or the rest of the line
+func commentLength(s string) int {
+	switch n := bracketLength(s[1:]); {
+	case n > 0:
+		return n + 1
+	case n < 0:
+		return len(s)
+	}
+	if end := strings.IndexByte(s, '\n'); end >= 0 {
+		return end
+	}
+	return len(s)
+}
+
+// bracketLength returns the length of the bracket argument ([[...]] or
+// [=[...]=]) at the start of s, 0 if there is none, or -1 if it is never
+// closed
+func bracketLength(s string) int {
+	if !strings.HasPrefix(s, "[") {
+		return 0
+	}
+	level := 0
+	for 1+level < len(s) && s[1+level] == '=' {
+		level++
+	}
+	if 1+level >= len(s) || s[1+level] != '[' {
+		return 0
+	}
+	closing := "]" + strings.Repeat("=", level) + "]"
+	end := strings.Index(s[2+level:], closing)
+	if end < 0 {
+		return -1
+	}
+	return 2 + level + end + len(closing)
+}
+
+// quotedArgument returns the value of the quoted argument at the start of
+// s and its length
+func quotedArgument(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case '\n':
+					// Line continuation
+				default:
+					b.WriteByte(s[i])
+				}
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted argument")
+}
+
+// unquotedArgument returns the unquoted argument at the start of s and its
+// length. ${...} references may contain characters that end an argument.
+func unquotedArgument(s string) (string, int) {
+	var b strings.Builder
+	depth := 0
+	i := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		if depth == 0 && (c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '(' || c == ')' || c == '"' || c == '#') {
+			break
+		}
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+			continue
+		case (c == '{' || c == '<') && i > 0 && s[i-1] == '$':
+			depth++
+		case (c == '}' || c == '>') && depth > 0:
+			depth--
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), i
+}
+
+// cmakeTarget is a target defined with add_executable or add_library
+type cmakeTarget struct {
+	name     string
+	kind     string // "executable", "static", "shared", "object" or "interface"
+	sources  []string
+	includes []string
+	defines  []string
+	options  []string
+	links    []string
+}
+
+// cmakeProject is what importing a CMake project collected
+type cmakeProject struct {
+	root    string
+	name    string
+	vars    map[string]string
+	targets []*cmakeTarget
+	// Directory-wide settings (include_directories, add_definitions, ...)
+	includes, defines, options []string
+	standard                   string              // CMAKE_C_STANDARD
+	pkgModules                 map[string][]string // pkg_check_modules prefix -> modules
+	tests                      []core.TestTarget
+	testTargets                map[string]bool
+	notes                      []string
+}
+
+// ImportCMake reads the CMakeLists.txt in dir, and those of the
+// directories it adds with add_subdirectory, and returns the equivalent
+// catalyst.yml. Conditions are not evaluated: commands in every branch of
+// if() blocks are imported. The returned notes list what couldn't be
+// carried over.
+func ImportCMake(dir string) (*core.Config, []string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &cmakeProject{
+		root:        root,
+		vars:        map[string]string{"CMAKE_SOURCE_DIR": root, "PROJECT_SOURCE_DIR": root, "CMAKE_BINARY_DIR": filepath.Join(root, "build")},
+		pkgModules:  make(map[string][]string),
+		testTargets: make(map[string]bool),
+	}
+	if err := p.readDir(root); err != nil {
+		return nil, nil, err
+	}
+	cfg, err := p.config()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, p.notes, nil
+}
+
+// readDir reads the CMakeLists.txt of one directory
+func (p *cmakeProject) readDir(dir string) error {
+	path := filepath.Join(dir, "CMakeLists.txt")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	commands, err := parseCMake(string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.rel(path), err)
+	}
+
+	p.vars["CMAKE_CURRENT_SOURCE_DIR"] = dir
+	p.vars["CMAKE_CURRENT_LIST_DIR"] = dir
+	for _, cmd := range commands {
+		if err := p.run(dir, cmd); err != nil {
+			return fmt.Errorf("%s:%d: %w", p.rel(path), cmd.line, err)
+		}
+	}
+	return nil
+}
+
+// expand substitutes variable references and splits unquoted arguments
+// into list items
+func (p *cmakeProject) expand(args []cmakeArg) []string {
+	var values []string
+	for _, arg := range args {
+		value := p.substitute(arg.value)
+		if arg.quoted {
+			values = append(values, value)
+			continue
+		}
+		for _, item := range strings.Split(value, ";") {
+			if item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// substitute replaces ${VAR} and $ENV{VAR}, innermost first
+func (p *cmakeProject) substitute(value string) string {
+	for range 32 {
+		end := strings.IndexByte(value, '}')
+		if end < 0 {
+			return value
+		}
+		start := strings.LastIndex(value[:end], "{")
+		if start < 1 {
+			return value
+		}
+		switch {
+		case value[start-1] == '$':
+			value = value[:start-1] + p.vars[value[start+1:end]] + value[end+1:]
+		case start >= 4 && value[start-4:start] == "$ENV":
+			value = value[:start-4] + os.Getenv(value[start+1:end]) + value[end+1:]
+		default:
+			return value
+		}
+	}
+	return value
+}
+
+// path resolves a path argument relative to dir and returns it relative to
+// the project root
+func (p *cmakeProject) path(dir, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return p.rel(path)
+}
+
+func (p *cmakeProject) rel(path string) string {
+	if rel, err := filepath.Rel(p.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+func (p *cmakeProject) note(format string, args ...any) {
+	note := fmt.Sprintf(format, args...)
+	if !slices.Contains(p.notes, note) {
+		p.notes = append(p.notes, note)
+	}
+}
+
+func (p *cmakeProject) target(name string) *cmakeTarget {
+	for _, t := range p.targets {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// scopeKeywords qualify the arguments of target_* commands
+var scopeKeywords = []string{"PRIVATE", "PUBLIC", "INTERFACE", "SYSTEM", "BEFORE", "AFTER"}
+
+// withoutKeywords drops keywords and resolves generator expressions:
+// $<BUILD_INTERFACE:x> is x, others are dropped with a note
+func (p *cmakeProject) withoutKeywords(args []string, keywords ...string) []string {
+	var values []string
+	for _, arg := range args {
+		if slices.Contains(keywords, arg) {
+			continue
+		}
+		if strings.HasPrefix(arg, "$<") {
+			inner, ok := strings.CutPrefix(arg, "$<BUILD_INTERFACE:")
+			if !ok {
+				if !strings.HasPrefix(arg, "$<INSTALL_INTERFACE:") {
+					p.note("Generator expression %s was skipped", arg)
+				}
+				continue
+			}
+			arg = strings.TrimSuffix(inner, ">")
+		}
+		values = append(values, arg)
+	}
+	return values
+}
+
+// run applies one command
+func (p *cmakeProject) run(dir string, cmd cmakeCommand) error {
+	args := p.expand(cmd.args)
+	switch cmd.name {
+	case "project":
+		if len(args) > 0 {
+			p.vars["PROJECT_NAME"] = args[0]
+			if p.name == "" {
+				p.name = args[0]
+			}
+		}
+
+	case "set":
+		if len(args) == 0 {
+			return nil
+		}
+		values := args[1:]
+		if i := slices.IndexFunc(values, func(v string) bool { return v == "CACHE" || v == "PARENT_SCOPE" }); i >= 0 {
+			values = values[:i]
+		}
+		p.vars[args[0]] = strings.Join(values, ";")
+		if args[0] == "CMAKE_C_STANDARD" && len(values) > 0 {
+			p.standard = values[0]
+		}
+
+	case "list":
+		if len(args) >= 2 && args[0] == "APPEND" {
+			items := append(strings.Split(p.vars[args[1]], ";"), args[2:]...)
+			p.vars[args[1]] = strings.Trim(strings.Join(items, ";"), ";")
+		}
+
+	case "file":
+		if len(args) >= 3 && (args[0] == "GLOB" || args[0] == "GLOB_RECURSE") {
+			var files []string
+			for _, pattern := range p.withoutKeywords(args[2:], "CONFIGURE_DEPENDS", "LIST_DIRECTORIES", "true", "false") {
+				files = append(files, globFiles(dir, pattern, args[0] == "GLOB_RECURSE")...)
+			}
+			p.vars[args[1]] = strings.Join(files, ";")
+		}
+
+	case "add_subdirectory":
+		if len(args) > 0 {
+			sub := args[0]
+			if !filepath.IsAbs(sub) {
+				sub = filepath.Join(dir, sub)
+			}
+			saved := map[string]string{"CMAKE_CURRENT_SOURCE_DIR": dir, "CMAKE_CURRENT_LIST_DIR": dir}
+			if err := p.readDir(sub); err != nil {
+				return err
+			}
+			for k, v := range saved {
+				p.vars[k] = v
+			}
+		}
+
+	case "add_executable", "add_library":
+		if len(args) == 0 || slices.Contains(args, "IMPORTED") || slices.Contains(args, "ALIAS") {
+			return nil
+		}
+		t := &cmakeTarget{name: args[0], kind: "executable"}
+		if cmd.name == "add_library" {
+			t.kind = "static"
+			if p.vars["BUILD_SHARED_LIBS"] == "ON" {
+				t.kind = "shared"
+			}
+		}
+		for _, arg := range args[1:] {
+			switch arg {
+			case "STATIC":
+				t.kind = "static"
+			case "SHARED", "MODULE":
+				t.kind = "shared"
+			case "OBJECT":
+				t.kind = "object"
+			case "INTERFACE":
+				t.kind = "interface"
+			case "WIN32", "MACOSX_BUNDLE", "EXCLUDE_FROM_ALL":
+			default:
+				t.sources = append(t.sources, p.path(dir, arg))
+			}
+		}
+		p.targets = append(p.targets, t)
+
+	case "target_sources", "target_include_directories", "target_compile_definitions", "target_compile_options", "target_link_libraries":
+		if len(args) == 0 {
+			return nil
+		}
+		t := p.target(args[0])
+		if t == nil {
+			p.note("%s for unknown target %s was skipped", cmd.name, args[0])
+			return nil
+		}
+		values := p.withoutKeywords(args[1:], scopeKeywords...)
+		switch cmd.name {
+		case "target_sources":
+			for _, src := range values {
+				t.sources = append(t.sources, p.path(dir, src))
+			}
+		case "target_include_directories":
+			for _, inc := range values {
+				t.includes = append(t.includes, p.path(dir, inc))
+			}
+		case "target_compile_definitions":
+			t.defines = append(t.defines, values...)
+		case "target_compile_options":
+			t.options = append(t.options, values...)
+		case "target_link_libraries":
+			t.links = append(t.links, p.withoutKeywords(values, "debug", "optimized", "general")...)
+		}
+
+	case "include_directories":
+		for _, inc := range p.withoutKeywords(args, "SYSTEM", "BEFORE", "AFTER") {
+			p.includes = append(p.includes, p.path(dir, inc))
+		}
+	case "add_definitions", "add_compile_definitions":
+		for _, def := range p.withoutKeywords(args) {
+			p.defines = append(p.defines, strings.TrimPrefix(def, "-D"))
+		}
+	case "add_compile_options":
+		p.options = append(p.options, p.withoutKeywords(args)...)
+	case "link_libraries":
+		p.note("link_libraries(%s) applies to every target; add it to the flags yourself", strings.Join(args, " "))
+
+	case "find_package":
+		if len(args) > 0 {
+			name := args[0]
+			// Old-style variables point at the package's imported target
+			for _, prefix := range []string{name, strings.ToUpper(name)} {
+				p.vars[prefix+"_LIBRARIES"] = name + "::" + name
+				p.vars[prefix+"_LIBRARY"] = name + "::" + name
+			}
+		}
+	case "pkg_check_modules", "pkg_search_module":
+		if len(args) >= 2 {
+			prefix := args[0]
+			modules := p.withoutKeywords(args[1:], "REQUIRED", "QUIET", "IMPORTED_TARGET", "GLOBAL", "NO_CMAKE_PATH", "NO_CMAKE_ENVIRONMENT_PATH")
+			var names []string
+			for _, module := range modules {
+				// A version requirement follows the name: glib-2.0>=2.56
+				if fields := strings.FieldsFunc(module, func(r rune) bool { return r == '<' || r == '>' || r == '=' }); len(fields) > 0 {
+					if name := strings.TrimSpace(fields[0]); name != "" {
+						names = append(names, name)
+					}
+				}
+			}
+			p.pkgModules[prefix] = names
+			p.vars[prefix+"_LIBRARIES"] = "PkgConfig::" + prefix
+			p.vars[prefix+"_LINK_LIBRARIES"] = "PkgConfig::" + prefix
+		}
+
+	case "add_test":
+		p.addTest(args)
+
+	case "if":
+		p.note("%s:%d: if(%s) was not evaluated; the commands in all its branches were imported", p.rel(filepath.Join(dir, "CMakeLists.txt")), cmd.line, strings.Join(args, " "))
+
+	case "fetchcontent_declare", "externalproject_add", "fetchcontent_makeavailable":
+		if len(args) > 0 {
+			p.note("%s(%s) downloads code at configure time; add it as a resource or dependency yourself", cmd.name, args[0])
+		}
+	}
+	return nil
+}
+
+// addTest records add_test(NAME name COMMAND target args...) or the old
+// add_test(name target args...)
+func (p *cmakeProject) addTest(args []string) {
+	var name string
+	var command []string
+	if len(args) >= 4 && args[0] == "NAME" {
+		name = args[1]
+		if i := slices.Index(args, "COMMAND"); i >= 0 {
+			command = args[i+1:]
+		}
+		if i := slices.IndexFunc(command, func(a string) bool {
+			return a == "WORKING_DIRECTORY" || a == "CONFIGURATIONS" || a == "COMMAND_EXPAND_LISTS"
+		}); i >= 0 {
+			command = command[:i]
+		}
+	} else if len(args) >= 2 {
+		name, command = args[0], args[1:]
+	}
+	if len(command) == 0 || p.target(command[0]) == nil {
+		p.note("Test %s doesn't run a target of the project and was skipped", name)
+		return
+	}
+	p.testTargets[command[0]] = true
+	p.tests = append(p.tests, core.TestTarget{Name: name, Sources: []string{command[0]}, Args: command[1:]})
+}
+
+// globFiles expands a file(GLOB) pattern relative to dir, returning paths
+// relative to dir as CMake does with RELATIVE-less globs made absolute
+func globFiles(dir, pattern string, recursive bool) []string {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+	if !recursive {
+		matches, _ := filepath.Glob(pattern)
+		return matches
+	}
+	base, name := filepath.Split(pattern)
+	var matches []string
+	filepath.Walk(filepath.Clean(base), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if ok, _ := filepath.Match(name, filepath.Base(path)); ok {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches
+}
+
+// cmakePackages maps find_package names and imported target namespaces,
+// lowercase, to the known libraries
+var cmakePackages = map[string]string{
+	"curl":       "libcurl",
+	"openssl":    "openssl",
+	"zlib":       "zlib",
+	"sqlite3":    "sqlite3",
+	"sqlite":     "sqlite3",
+	"threads":    "pthread",
+	"pthread":    "pthread",
+	"png":        "libpng",
+	"libxml2":    "libxml2",
+	"sdl2":       "SDL2",
+	"glfw3":      "GLFW",
+	"glfw":       "GLFW",
+	"opengl":     "OpenGL",
+	"glew":       "GLEW",
+	"vulkan":     "Vulkan",
+	"pcre":       "pcre",
+	"libuv":      "libuv",
+	"uv":         "libuv",
+	"microhttpd": "libmicrohttpd",
+}
+
+// librariesFor returns the known libraries a link item refers to, or false
+// if it names none
+func (p *cmakeProject) librariesFor(item string) ([]analyzer.ExternalLibrary, bool) {
+	namespace, _, imported := strings.Cut(item, "::")
+	if imported && namespace == "PkgConfig" {
+		var libs []analyzer.ExternalLibrary
+		for _, module := range p.pkgModules[strings.TrimPrefix(item, "PkgConfig::")] {
+			lib, ok := analyzer.LookupPkgConfig(module)
+			if !ok {
+				return nil, false
+			}
+			libs = append(libs, lib)
+		}
+		return libs, len(libs) > 0
+	}
+	if name, ok := cmakePackages[strings.ToLower(namespace)]; ok {
+		lib, ok := analyzer.LookupLibrary(name)
+		return []analyzer.ExternalLibrary{lib}, ok
+	}
+	return nil, false
+}
+
+// config builds catalyst.yml from the targets. The first executable that
+// isn't a test is the project; without one, the first library. Libraries of
+// the project it links are built into it, other executables become examples.
+func (p *cmakeProject) config() (*core.Config, error) {
+	var main *cmakeTarget
+	for _, t := range p.targets {
+		if t.kind == "executable" && !p.testTargets[t.name] {
+			main = t
+			break
+		}
+	}
+	if main == nil {
+		for _, t := range p.targets {
+			if t.kind == "static" || t.kind == "shared" {
+				main = t
+				break
+			}
+		}
+	}
+	if main == nil {
+		return nil, fmt.Errorf("no add_executable or add_library target found")
+	}
+
+	cfg := &core.Config{
+		ProjectName:  p.name,
+		Output:       main.name,
+		Dependencies: map[string][]string{"darwin": {}, "linux": {}, "windows": {}},
+	}
+	if cfg.ProjectName == "" {
+		cfg.ProjectName = main.name
+	}
+	switch main.kind {
+	case "static":
+		cfg.Type = core.TypeLibrary
+	case "shared":
+		cfg.Type = core.TypeSharedLibrary
+	}
+
+	var skip []string
+	if cfg.IsLibrary() {
+		// Examples and tests link the library instead of building it in
+		skip = []string{main.name}
+	}
+	sources, flags := p.resolve(main, cfg, nil)
+	cfg.Sources = sources
+	cfg.Flags = flags
+	if p.standard != "" {
+		cfg.Flags = append([]string{"-std=c" + p.standard}, cfg.Flags...)
+	}
+
+	for _, test := range p.tests {
+		t := p.target(test.Sources[0])
+		test.Sources, test.Flags = p.resolve(t, cfg, skip)
+		test.Flags = withoutFlags(test.Flags, cfg.Flags)
+		cfg.Tests = append(cfg.Tests, test)
+	}
+	for _, t := range p.targets {
+		if t == main || t.kind != "executable" || p.testTargets[t.name] {
+			continue
+		}
+		sources, flags := p.resolve(t, cfg, skip)
+		cfg.Examples = append(cfg.Examples, core.ExampleTarget{Name: t.name, Sources: sources, Flags: withoutFlags(flags, cfg.Flags)})
+	}
+	return cfg, nil
+}
+
+// resolve returns the sources and flags of a target with those of the
+// project libraries it links, except the skipped ones. Dependencies on
+// known libraries are added to cfg.
+func (p *cmakeProject) resolve(t *cmakeTarget, cfg *core.Config, skip []string) ([]string, []string) {
+	var sources, includes, defines, options, links []string
+	seen := map[string]bool{}
+	var visit func(t *cmakeTarget)
+	visit = func(t *cmakeTarget) {
+		if seen[t.name] {
+			return
+		}
+		seen[t.name] = true
+		for _, src := range t.sources {
+			if isSourceFile(src) && !slices.Contains(sources, src) {
+				sources = append(sources, src)
+			}
+		}
+		includes = appendNew(includes, t.includes...)
+		defines = appendNew(defines, t.defines...)
+		options = appendNew(options, t.options...)
+		for _, item := range t.links {
+			if dep := p.target(item); dep != nil {
+				if !slices.Contains(skip, dep.name) {
+					visit(dep)
+				}
+				continue
+			}
+			links = appendNew(links, p.linkFlags(item, cfg)...)
+		}
+	}
+	visit(t)
+
+	var flags []string
+	for _, inc := range appendNew(slices.Clone(p.includes), includes...) {
+		flags = append(flags, "-I"+inc)
+	}
+	for _, def := range appendNew(slices.Clone(p.defines), defines...) {
+		flags = append(flags, "-D"+def)
+	}
+	flags = append(flags, appendNew(slices.Clone(p.options), options...)...)
+	return sources, append(flags, links...)
+}
+
+// linkFlags returns the flags for one target_link_libraries item, adding
+// the packages of known libraries to cfg
+func (p *cmakeProject) linkFlags(item string, cfg *core.Config) []string {
+	if libs, ok := p.librariesFor(item); ok {
+		var flags []string
+		for _, lib := range libs {
+			flags = appendNew(flags, addLibrary(cfg, lib)...)
+		}
+		return flags
+	}
+
+	switch {
+	case strings.HasPrefix(item, "-"):
+		return []string{item}
+	case strings.Contains(item, "::"):
+		p.note("Imported target %s has no known package; add its dependency yourself", item)
+		return nil
+	case strings.ContainsAny(item, `/\`) || strings.Contains(item, ".a") || strings.Contains(item, ".so"):
+		return []string{item}
+	}
+	return []string{"-l" + item}
+}
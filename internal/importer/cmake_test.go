@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCMake(t *testing.T) {
+	commands, err := parseCMake(`# comment
+add_executable(app "src/main file.c" src/net.c) # trailing
+if(WIN32 AND (MSVC OR CLANG))
+TARGET_LINK_LIBRARIES(app $<$<C_COMPILER_ID:GNU>:m> [[raw;text]])
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+	if want := []string{"add_executable", "if", "target_link_libraries"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("commands = %q, want %q", names, want)
+	}
+	want := []cmakeArg{{value: "app"}, {value: "src/main file.c", quoted: true}, {value: "src/net.c"}}
+	if !reflect.DeepEqual(commands[0].args, want) {
+		t.Errorf("add_executable args = %+v, want %+v", commands[0].args, want)
+	}
+	want = []cmakeArg{{value: "app"}, {value: "$<$<C_COMPILER_ID:GNU>:m>"}, {value: "raw;text", quoted: true}}
+	if !reflect.DeepEqual(commands[2].args, want) {
+		t.Errorf("target_link_libraries args = %+v, want %+v", commands[2].args, want)
+	}
+	if commands[2].line != 4 {
+		t.Errorf("line = %d, want 4", commands[2].line)
+	}
+
+	if _, err := parseCMake(`project(app`); err == nil {
+		t.Error("an unterminated command was accepted")
+	}
+	for _, src := range []string{"foo([[", "project(app)\nfoo([=[x", "foo([=[x]])"} {
+		if _, err := parseCMake(src); err == nil || !strings.Contains(err.Error(), "unterminated bracket argument") {
+			t.Errorf("parseCMake(%q) = %v, want an unterminated bracket argument error", src, err)
+		}
+	}
+	if _, err := parseCMake("project(app)\n#[[ never closed"); err != nil {
+		t.Errorf("an unterminated bracket comment = %v, want the rest of the file commented out", err)
+	}
+}
+
+func TestImportCMake(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"CMakeLists.txt": `project(webapp C)
+set(CMAKE_C_STANDARD 99)
+find_package(CURL REQUIRED)
+add_subdirectory(lib)
+file(GLOB APP_SRC ${CMAKE_CURRENT_SOURCE_DIR}/src/*.c)
+add_executable(webapp ${APP_SRC})
+target_link_libraries(webapp PRIVATE core CURL::libcurl m)
+add_executable(demo examples/demo.c)
+target_link_libraries(demo core)
+add_executable(test_core tests/test_core.c)
+target_link_libraries(test_core core)
+add_test(NAME core COMMAND test_core --fast)
+`,
+		"lib/CMakeLists.txt": `add_library(core STATIC core.c)
+target_include_directories(core PUBLIC $<BUILD_INTERFACE:${CMAKE_CURRENT_SOURCE_DIR}/include>)
+target_compile_definitions(core PRIVATE CORE_BUILD=1)
+`,
+		"src/main.c":        "",
+		"src/net.c":         "",
+		"lib/core.c":        "",
+		"tests/test_core.c": "",
+		"examples/demo.c":   "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, notes, err := ImportCMake(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ProjectName != "webapp" || cfg.Output != "webapp" || cfg.Type != "" {
+		t.Errorf("project = %s/%s/%q", cfg.ProjectName, cfg.Output, cfg.Type)
+	}
+	if want := []string{"src/main.c", "src/net.c", "lib/core.c"}; !reflect.DeepEqual(cfg.Sources, want) {
+		t.Errorf("sources = %q, want %q", cfg.Sources, want)
+	}
+	if want := []string{"-std=c99", "-Ilib/include", "-DCORE_BUILD=1", "-lcurl", "-lm"}; !reflect.DeepEqual(cfg.Flags, want) {
+		t.Errorf("flags = %q, want %q", cfg.Flags, want)
+	}
+	if len(cfg.Dependencies["linux"]) != 1 {
+		t.Errorf("linux dependencies = %q, want the curl package", cfg.Dependencies["linux"])
+	}
+	if len(cfg.Tests) != 1 || cfg.Tests[0].Name != "core" || !slices.Equal(cfg.Tests[0].Args, []string{"--fast"}) ||
+		!slices.Equal(cfg.Tests[0].Sources, []string{"tests/test_core.c", "lib/core.c"}) || len(cfg.Tests[0].Flags) != 0 {
+		t.Errorf("tests = %+v", cfg.Tests)
+	}
+	if len(cfg.Examples) != 1 || cfg.Examples[0].Name != "demo" {
+		t.Errorf("examples = %+v", cfg.Examples)
+	}
+	if len(notes) != 0 {
+		t.Errorf("notes = %q", notes)
+	}
+
+	// A project of only a library builds it, and links it into its tests
+	os.WriteFile(filepath.Join(dir, "CMakeLists.txt"), []byte("add_subdirectory(lib)\nadd_executable(t tests/test_core.c)\ntarget_link_libraries(t core)\nadd_test(t t)\n"), 0644)
+	cfg, _, err = ImportCMake(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsLibrary() || cfg.Output != "core" || len(cfg.Tests) != 1 || !slices.Equal(cfg.Tests[0].Sources, []string{"tests/test_core.c"}) {
+		t.Errorf("library import = %+v", cfg)
+	}
+
+	// Empty modules and bare version requirements are skipped
+	os.WriteFile(filepath.Join(dir, "CMakeLists.txt"), []byte("pkg_check_modules(GLIB REQUIRED \"\" >= glib-2.0>=2.56)\nadd_executable(app src/main.c)\ntarget_link_libraries(app PkgConfig::GLIB)\n"), 0644)
+	if _, _, err = ImportCMake(dir); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package importer converts the build files of other build systems into
+// catalyst.yml
+package importer
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	core "github.com/Sabique-Islam/catalyst/internal/config"
+)
+
+// addLibrary adds the packages of a known library to the dependencies of
+// cfg and returns its linker flags. Flags that differ by platform, such as
+// -framework OpenGL on macOS, go into the platform sections of cfg instead.
+func addLibrary(cfg *core.Config, lib analyzer.ExternalLibrary) []string {
+	if cfg.Dependencies == nil {
+		cfg.Dependencies = make(map[string][]string)
+	}
+	for osName, pkg := range lib.Platforms {
+		if pkg.PackageName != "" && !slices.Contains(cfg.Dependencies[osName], pkg.PackageName) {
+			cfg.Dependencies[osName] = append(cfg.Dependencies[osName], pkg.PackageName)
+		}
+	}
+
+	perPlatform := false
+	for _, pkg := range lib.Platforms {
+		perPlatform = perPlatform || pkg.LinkerFlag != ""
+	}
+	if !perPlatform {
+		return strings.Fields(lib.LinkerFlag)
+	}
+	if cfg.Platforms == nil {
+		cfg.Platforms = make(map[string]core.PlatformConfig)
+	}
+	for _, osName := range []string{"darwin", "linux", "windows"} {
+		platform := cfg.Platforms[osName]
+		platform.Flags = appendNew(platform.Flags, lib.LinkerFlagsFor(osName)...)
+		cfg.Platforms[osName] = platform
+	}
+	return nil
+}
+
+func isSourceFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".c", ".cc", ".cpp", ".cxx":
+		return true
+	}
+	return false
+}
+
+// appendNew appends the values not in list yet
+func appendNew(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// withoutFlags returns flags without those the project already has, as
+// tests and examples get the project flags too
+func withoutFlags(flags, project []string) []string {
+	return slices.DeleteFunc(flags, func(flag string) bool { return slices.Contains(project, flag) })
+}
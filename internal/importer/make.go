@@ -0,0 +1,522 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	core "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// makeTarget is a program or archive the Makefile links
+type makeTarget struct {
+	output    string
+	library   bool // Archived with ar
+	sources   []string
+	linkFlags []string
+}
+
+// makeProject is what importing a Makefile collected
+type makeProject struct {
+	root    string
+	objects map[string]string // Object file -> the source compiled into it
+	flags   []string          // Compile flags, from every compile command
+	targets []*makeTarget
+	notes   []string
+}
+
+// makefileNames are the files make reads, in its order
+var makefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+// ImportMake reads the build of the Makefile in dir and returns the
+// equivalent catalyst.yml. With runMake the commands make would run are
+// listed with make -n -B and the compiler, linker and ar invocations among
+// them are converted. make -n still runs $(shell ...), lines starting with
+// + and recursive $(MAKE) calls, so only run it on trusted Makefiles.
+// Without runMake or make, or when it fails, the variables of a simple
+// Makefile (CFLAGS, LDLIBS, SRCS, TARGET, ...) are read instead. The
+// returned notes list what couldn't be carried over.
+func ImportMake(dir string, runMake bool) (*core.Config, []string, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	makefile := ""
+	for _, name := range makefileNames {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			makefile = name
+			break
+		}
+	}
+	if makefile == "" {
+		return nil, nil, fmt.Errorf("no Makefile found in %s", dir)
+	}
+
+	p := &makeProject{root: root, objects: make(map[string]string)}
+	var output []byte
+	if runMake {
+		cmd := util.ParsedCommand("make", "-n", "-B")
+		cmd.Dir = root
+		output, err = cmd.CombinedOutput()
+		if err == nil {
+			p.readCommands(string(output))
+		}
+	}
+	if len(p.targets) == 0 {
+		if err != nil {
+			p.note("make -n failed (%s), so only the variables of %s were read", firstLine(string(output), err), makefile)
+		}
+		data, err := os.ReadFile(filepath.Join(root, makefile))
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := p.readVariables(string(data)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cfg, err := p.config()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, p.notes, nil
+}
+
+// firstLine describes a failed command by the first line of its output
+func firstLine(output string, err error) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return err.Error()
+}
+
+func (p *makeProject) note(format string, args ...any) {
+	note := fmt.Sprintf(format, args...)
+	if !slices.Contains(p.notes, note) {
+		p.notes = append(p.notes, note)
+	}
+}
+
+// enteringDirectory matches the lines make prints when it recurses with -C
+var enteringDirectory = regexp.MustCompile(`^\S*make(\[\d+\])?: (Entering|Leaving) directory ['` + "`" + `](.*)'$`)
+
+// readCommands converts the commands make -n printed
+func (p *makeProject) readCommands(output string) {
+	output = strings.ReplaceAll(output, "\\\n", " ")
+	var dirs []string
+	for _, line := range strings.Split(output, "\n") {
+		if m := enteringDirectory.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			if m[2] == "Entering" {
+				dirs = append(dirs, m[3])
+			} else if len(dirs) > 0 {
+				dirs = dirs[:len(dirs)-1]
+			}
+			continue
+		}
+		dir := p.root
+		if len(dirs) > 0 {
+			dir = dirs[len(dirs)-1]
+		}
+		for _, command := range splitShellCommands(line) {
+			words, err := util.SplitArguments(command)
+			if err != nil || len(words) == 0 {
+				continue
+			}
+			if words[0] == "cd" && len(words) == 2 {
+				dir = p.abs(dir, words[1])
+				continue
+			}
+			p.readCommand(dir, words)
+		}
+	}
+}
+
+// splitShellCommands splits a line of a recipe at &&, || and ;
+func splitShellCommands(line string) []string {
+	var commands []string
+	for _, part := range strings.Split(line, "&&") {
+		for _, part := range strings.Split(part, "||") {
+			commands = append(commands, strings.Split(part, ";")...)
+		}
+	}
+	return commands
+}
+
+// isCompiler reports whether a command name is a C or C++ compiler driver:
+// cc, gcc-13, clang++, x86_64-w64-mingw32-gcc, ...
+func isCompiler(name string) bool {
+	name = strings.TrimSuffix(strings.ToLower(filepath.Base(name)), ".exe")
+	if i := strings.LastIndexByte(name, '-'); i >= 0 && strings.Trim(name[i+1:], "0123456789.") == "" {
+		name = name[:i] // Version suffix
+	}
+	if i := strings.LastIndexByte(name, '-'); i >= 0 {
+		name = name[i+1:] // Cross-compiler prefix
+	}
+	switch name {
+	case "cc", "gcc", "clang", "c99", "c11", "c++", "g++", "clang++", "tcc", "icx":
+		return true
+	}
+	return false
+}
+
+// readCommand converts one compiler or ar invocation run in dir
+func (p *makeProject) readCommand(dir string, words []string) {
+	for len(words) > 1 && (filepath.Base(words[0]) == "ccache" || strings.Contains(words[0], "=")) {
+		words = words[1:] // ccache gcc ..., CC=gcc ...
+	}
+	name := strings.TrimSuffix(filepath.Base(words[0]), ".exe")
+	switch {
+	case name == "ar" || strings.HasSuffix(name, "-ar") || name == "llvm-ar":
+		p.readArchive(dir, words[1:])
+	case isCompiler(words[0]):
+		p.readCompile(dir, words[1:])
+	}
+}
+
+// readArchive converts ar rcs libfoo.a a.o b.o
+func (p *makeProject) readArchive(dir string, args []string) {
+	if len(args) < 2 {
+		return
+	}
+	t := &makeTarget{output: p.rel(p.abs(dir, args[1])), library: true}
+	for _, object := range args[2:] {
+		t.sources = appendNew(t.sources, p.sourcesOf(dir, object)...)
+	}
+	p.targets = append(p.targets, t)
+}
+
+// sourcesOf returns the sources behind a linker input: the source compiled
+// into an object, the sources of an archive the Makefile built, or the file
+// itself when it's a source
+func (p *makeProject) sourcesOf(dir, input string) []string {
+	path := p.rel(p.abs(dir, input))
+	if src, ok := p.objects[path]; ok {
+		return []string{src}
+	}
+	for _, t := range p.targets {
+		if t.output == path {
+			return t.sources
+		}
+	}
+	if isSourceFile(path) {
+		return []string{path}
+	}
+	return nil
+}
+
+// valueFlags are compiler options whose value may be the next word
+var valueFlags = []string{"-o", "-I", "-D", "-U", "-L", "-l", "-include", "-isystem", "-iquote", "-MF", "-MT", "-MQ", "-x", "-framework", "-Xlinker"}
+
+// pathFlags are compile options naming a path, which is made relative to
+// the project root
+var pathFlags = []string{"-isystem", "-iquote", "-include", "-I"}
+
+// readCompile converts a compiler invocation: a compile (-c), a link, or
+// both at once
+func (p *makeProject) readCompile(dir string, args []string) {
+	var output string
+	var inputs, compileFlags, linkFlags []string
+	compileOnly := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if slices.Contains(valueFlags, arg) && i+1 < len(args) {
+			// Options are joined with their value: -I include is -Iinclude
+			i++
+			arg += args[i]
+		}
+		switch {
+		case arg == "-c":
+			compileOnly = true
+		case strings.HasPrefix(arg, "-o"):
+			output = arg[2:]
+		case strings.HasPrefix(arg, "-M"), arg == "-pipe":
+			// Dependency files are catalyst's business
+		case strings.HasPrefix(arg, "-O"), strings.HasPrefix(arg, "-g"):
+			p.note("Optimization and debug flags (-O, -g) were left to catalyst's build profiles (catalyst build --profile release)")
+		case strings.HasPrefix(arg, "-L"):
+			linkFlags = append(linkFlags, "-L"+p.rel(p.abs(dir, arg[2:])))
+		case strings.HasPrefix(arg, "-framework"):
+			linkFlags = append(linkFlags, "-Wl,-framework,"+strings.TrimPrefix(arg, "-framework"))
+		case strings.HasPrefix(arg, "-Xlinker"):
+			linkFlags = append(linkFlags, "-Wl,"+strings.TrimPrefix(arg, "-Xlinker"))
+		case strings.HasPrefix(arg, "-l"), strings.HasPrefix(arg, "-Wl,"), arg == "-static", arg == "-shared", arg == "-rdynamic":
+			linkFlags = append(linkFlags, arg)
+		case arg == "-pthread":
+			compileFlags = append(compileFlags, arg)
+			linkFlags = append(linkFlags, arg)
+		case strings.HasPrefix(arg, "-"):
+			if i := slices.IndexFunc(pathFlags, func(flag string) bool { return strings.HasPrefix(arg, flag) }); i >= 0 {
+				arg = pathFlags[i] + p.rel(p.abs(dir, strings.TrimPrefix(arg, pathFlags[i])))
+			}
+			compileFlags = append(compileFlags, arg)
+		default:
+			inputs = append(inputs, arg)
+		}
+	}
+
+	if compileOnly {
+		p.flags = appendNew(p.flags, compileFlags...)
+		for _, input := range inputs {
+			if !isSourceFile(input) {
+				continue
+			}
+			src := p.rel(p.abs(dir, input))
+			object := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)) + ".o"
+			if output != "" {
+				object = output
+			}
+			p.objects[p.rel(p.abs(dir, object))] = src
+		}
+		return
+	}
+
+	var sources []string
+	for _, input := range inputs {
+		if isSourceFile(input) {
+			p.flags = appendNew(p.flags, compileFlags...)
+		}
+		srcs := p.sourcesOf(dir, input)
+		if srcs == nil {
+			linkFlags = append(linkFlags, p.rel(p.abs(dir, input)))
+		}
+		sources = appendNew(sources, srcs...)
+	}
+	if len(sources) == 0 {
+		return
+	}
+	if output == "" {
+		output = "a.out"
+	}
+	p.targets = append(p.targets, &makeTarget{output: p.rel(p.abs(dir, output)), sources: sources, linkFlags: linkFlags})
+}
+
+func (p *makeProject) abs(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+func (p *makeProject) rel(path string) string {
+	if rel, err := filepath.Rel(p.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// makeAssignment matches a variable assignment: NAME = value, :=, ::=, +=, ?=
+var makeAssignment = regexp.MustCompile(`^(?:override\s+|export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*(\+=|:{1,2}=|\?=|=)\s*(.*)$`)
+
+// makeReference matches $(NAME) and ${NAME}
+var makeReference = regexp.MustCompile(`\$[({]([^(){}]+)[)}]`)
+
+// Variables that conventionally hold the parts of a build
+var (
+	sourceVariables = []string{"SRC", "SRCS", "SOURCES", "SOURCE", "C_SOURCES", "CSRC", "FILES"}
+	outputVariables = []string{"TARGET", "PROG", "PROGRAM", "BIN", "BINARY", "EXEC", "EXECUTABLE", "EXE", "OUT", "NAME", "APP"}
+	flagVariables   = []string{"CPPFLAGS", "CFLAGS", "INCLUDES", "INC", "DEFINES"}
+	linkVariables   = []string{"LDFLAGS", "LDLIBS", "LIBS", "LIBRARIES"}
+)
+
+// readVariables reads the build of a simple Makefile from its variables,
+// when make can't list the commands
+func (p *makeProject) readVariables(makefile string) error {
+	vars := make(map[string]string)
+	var expand func(value string, depth int) string
+	expand = func(value string, depth int) string {
+		if depth > 16 {
+			return value
+		}
+		return makeReference.ReplaceAllStringFunc(value, func(ref string) string {
+			inner := ref[2 : len(ref)-1]
+			if pattern, ok := strings.CutPrefix(inner, "wildcard "); ok {
+				var matches []string
+				for _, glob := range strings.Fields(expand(pattern, depth+1)) {
+					files, _ := filepath.Glob(filepath.Join(p.root, glob))
+					for _, file := range files {
+						matches = append(matches, p.rel(file))
+					}
+				}
+				return strings.Join(matches, " ")
+			}
+			if name, subst, ok := strings.Cut(inner, ":"); ok && strings.Contains(subst, "=") {
+				// $(SRCS:.c=.o)
+				from, to, _ := strings.Cut(subst, "=")
+				var words []string
+				for _, word := range strings.Fields(expand(vars[name], depth+1)) {
+					if before, ok := strings.CutSuffix(word, from); ok {
+						word = before + to
+					}
+					words = append(words, word)
+				}
+				return strings.Join(words, " ")
+			}
+			return expand(vars[inner], depth+1)
+		})
+	}
+
+	var firstRule string
+	makefile = strings.ReplaceAll(makefile, "\\\n", " ")
+	for _, line := range strings.Split(makefile, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			continue // Recipe
+		}
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if m := makeAssignment.FindStringSubmatch(line); m != nil {
+			name, op, value := m[1], m[2], m[3]
+			switch op {
+			case "+=":
+				vars[name] = strings.TrimSpace(vars[name] + " " + value)
+			case "?=":
+				if _, ok := vars[name]; !ok {
+					vars[name] = value
+				}
+			case ":=", "::=":
+				vars[name] = expand(value, 0)
+			default:
+				vars[name] = value
+			}
+			continue
+		}
+		if target, _, ok := strings.Cut(line, ":"); ok && firstRule == "" && !strings.HasPrefix(target, ".") {
+			if targets := strings.Fields(expand(target, 0)); len(targets) == 1 && !strings.ContainsAny(targets[0], "%$") {
+				firstRule = targets[0]
+			}
+		}
+	}
+
+	t := &makeTarget{}
+	for _, name := range sourceVariables {
+		for _, src := range strings.Fields(expand(vars[name], 0)) {
+			if isSourceFile(src) {
+				t.sources = appendNew(t.sources, src)
+			}
+		}
+	}
+	if len(t.sources) == 0 {
+		files, _ := filepath.Glob(filepath.Join(p.root, "*.c"))
+		for _, file := range files {
+			t.sources = append(t.sources, p.rel(file))
+		}
+		if len(t.sources) > 0 {
+			p.note("No source variable (%s) was found, so every C file in the project directory was taken", strings.Join(sourceVariables[:3], ", "))
+		}
+	}
+	for _, name := range outputVariables {
+		if value := strings.TrimSpace(expand(vars[name], 0)); value != "" && !strings.ContainsAny(value, " \t") {
+			t.output = value
+			break
+		}
+	}
+	if t.output == "" && firstRule != "" && firstRule != "all" {
+		t.output = firstRule
+	}
+	if t.output == "" {
+		t.output = filepath.Base(p.root)
+	}
+
+	var flags []string
+	for _, name := range append(slices.Clone(flagVariables), linkVariables...) {
+		// The shell would unquote the flags: -DVERSION=\"1.0\"
+		words, err := util.SplitArguments(expand(vars[name], 0))
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		flags = append(flags, words...)
+	}
+	if len(t.sources) == 0 {
+		return fmt.Errorf("no sources found in the Makefile")
+	}
+	// Parse the flags as one command line would be, for the same filtering
+	p.readCompile(p.root, append(append(flags, t.sources...), "-o", t.output))
+	return nil
+}
+
+// config builds catalyst.yml from the targets. The first program linked is
+// the project, or the first archive when no program is; programs named
+// like tests become tests and the others examples.
+func (p *makeProject) config() (*core.Config, error) {
+	isTest := func(t *makeTarget) bool {
+		name := strings.ToLower(filepath.Base(t.output))
+		return strings.HasPrefix(name, "test") || strings.HasSuffix(strings.TrimSuffix(name, ".exe"), "test") || strings.HasSuffix(strings.TrimSuffix(name, ".exe"), "tests")
+	}
+	var main *makeTarget
+	for _, t := range p.targets {
+		if !t.library && !isTest(t) {
+			main = t
+			break
+		}
+	}
+	if main == nil {
+		for _, t := range p.targets {
+			if t.library {
+				main = t
+				break
+			}
+		}
+	}
+	if main == nil {
+		return nil, fmt.Errorf("no program or library is built by the Makefile")
+	}
+
+	output := filepath.Base(main.output)
+	cfg := &core.Config{
+		ProjectName:  strings.TrimSuffix(output, ".exe"),
+		Output:       strings.TrimSuffix(output, ".exe"),
+		Sources:      main.sources,
+		Dependencies: map[string][]string{"darwin": {}, "linux": {}, "windows": {}},
+	}
+	if main.library {
+		cfg.Type = core.TypeLibrary
+		name := strings.TrimSuffix(strings.TrimPrefix(output, "lib"), filepath.Ext(output))
+		cfg.ProjectName, cfg.Output = name, name
+	}
+	cfg.Flags = appendNew(slices.Clone(p.flags), p.linkFlags(cfg, main.linkFlags)...)
+
+	for _, t := range p.targets {
+		if t == main || t.library {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(t.output), ".exe")
+		sources := t.sources
+		if main.library {
+			// Tests and examples link the library instead of building it in
+			sources = slices.DeleteFunc(slices.Clone(sources), func(src string) bool { return slices.Contains(main.sources, src) })
+		}
+		flags := withoutFlags(p.linkFlags(cfg, t.linkFlags), cfg.Flags)
+		if isTest(t) {
+			cfg.Tests = append(cfg.Tests, core.TestTarget{Name: name, Sources: sources, Flags: flags})
+		} else {
+			cfg.Examples = append(cfg.Examples, core.ExampleTarget{Name: name, Sources: sources, Flags: flags})
+		}
+	}
+	return cfg, nil
+}
+
+// linkFlags converts the link flags of a target, adding the packages of the
+// known libraries among them to cfg. Archives the Makefile built itself are
+// dropped, as their sources are built in.
+func (p *makeProject) linkFlags(cfg *core.Config, flags []string) []string {
+	var converted []string
+	for _, flag := range flags {
+		if lib, ok := analyzer.LookupLinkerFlag(flag); ok {
+			converted = appendNew(converted, addLibrary(cfg, lib)...)
+			continue
+		}
+		if slices.ContainsFunc(p.targets, func(t *makeTarget) bool { return t.library && t.output == flag }) {
+			continue
+		}
+		converted = appendNew(converted, flag)
+	}
+	return converted
+}
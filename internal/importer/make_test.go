@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadMakeCommands(t *testing.T) {
+	root := t.TempDir()
+	p := &makeProject{root: root, objects: make(map[string]string)}
+	p.readCommands(`gcc -Wall -O2 -Iinclude -DNAME=\"app\" -c -o src/main.o src/main.c
+gcc -Wall -O2 -Iinclude -c src/util.c -o src/util.o
+make[1]: Entering directory '` + filepath.Join(root, "lib") + `'
+ccache cc -Wall -I../include -c -o core.o core.c
+ar rcs libcore.a core.o
+make[1]: Leaving directory '` + filepath.Join(root, "lib") + `'
+gcc -o app src/main.o src/util.o lib/libcore.a -Llib -lcurl -lm \
+  -framework Cocoa
+cd tests && gcc -Wall -o test_core test_core.c ../lib/libcore.a
+echo done
+`)
+
+	cfg, err := p.config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Output != "app" || cfg.Type != "" {
+		t.Errorf("project = %s (%q)", cfg.Output, cfg.Type)
+	}
+	if want := []string{"src/main.c", "src/util.c", "lib/core.c"}; !reflect.DeepEqual(cfg.Sources, want) {
+		t.Errorf("sources = %q, want %q", cfg.Sources, want)
+	}
+	want := []string{"-Wall", "-Iinclude", `-DNAME="app"`, "-Llib", "-lcurl", "-lm", "-Wl,-framework,Cocoa"}
+	if !reflect.DeepEqual(cfg.Flags, want) {
+		t.Errorf("flags = %q, want %q", cfg.Flags, want)
+	}
+	if len(cfg.Dependencies["linux"]) != 1 {
+		t.Errorf("linux dependencies = %q, want the curl package", cfg.Dependencies["linux"])
+	}
+	if len(cfg.Tests) != 1 || cfg.Tests[0].Name != "test_core" || !reflect.DeepEqual(cfg.Tests[0].Sources, []string{"tests/test_core.c", "lib/core.c"}) {
+		t.Errorf("tests = %+v", cfg.Tests)
+	}
+	if len(p.notes) != 1 {
+		t.Errorf("notes = %q, want the one about -O", p.notes)
+	}
+}
+
+func TestReadMakeVariables(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"main.c", "net.c"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &makeProject{root: root, objects: make(map[string]string)}
+	err := p.readVariables(`# A simple Makefile
+CC = gcc
+CFLAGS := -Wall -std=c11 \
+	-DVERSION=\"1.0\"
+CFLAGS += -Iinclude
+LDLIBS = -lz
+SRCS = $(wildcard *.c)
+TARGET ?= server
+
+$(TARGET): $(SRCS:.c=.o)
+	$(CC) -o $@ $^ $(LDLIBS)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := p.config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Output != "server" || !reflect.DeepEqual(cfg.Sources, []string{"main.c", "net.c"}) {
+		t.Errorf("project = %s %q", cfg.Output, cfg.Sources)
+	}
+	if want := []string{"-Wall", "-std=c11", `-DVERSION="1.0"`, "-Iinclude", "-lz"}; !reflect.DeepEqual(cfg.Flags, want) {
+		t.Errorf("flags = %q, want %q", cfg.Flags, want)
+	}
+}
+
+func TestImportMakeWithoutMake(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.c"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	makefile := "RAN := $(shell touch ran)\nSRCS = main.c\nTARGET = app\n\napp: main.o\n\t+touch ran\n\t$(CC) -o $@ $^\n"
+	if err := os.WriteFile(filepath.Join(root, "Makefile"), []byte(makefile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := ImportMake(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Output != "app" || !reflect.DeepEqual(cfg.Sources, []string{"main.c"}) {
+		t.Errorf("project = %s %q", cfg.Output, cfg.Sources)
+	}
+	if _, err := os.Stat(filepath.Join(root, "ran")); !os.IsNotExist(err) {
+		t.Error("ImportMake ran the Makefile without runMake")
+	}
+}
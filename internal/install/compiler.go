@@ -0,0 +1,237 @@
+package install
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// CompilerOption is a C compiler catalyst can install when none is found
+type CompilerOption struct {
+	Name        string
+	Description string
+	install     func() error
+	// bin returns the directory the compiler was installed into, which is
+	// added to PATH so the build continues; nil if it's in PATH already
+	bin func() string
+	// Restart tells what to do before the compiler can be used, when this
+	// process can't use it; empty if it can
+	Restart string
+}
+
+// ChooseCompiler picks which of the options to install, returning false to
+// install none; nil never installs one. By default it asks on the terminal
+// and declines when stdin isn't one.
+var ChooseCompiler = chooseCompilerOnTerminal
+
+// compilerOptions returns the compilers that can be installed on this host
+var compilerOptions = hostCompilerOptions
+
+// compilerOffered keeps a build from asking again each time it looks for a
+// compiler
+var compilerOffered bool
+
+// OfferCompiler lists the C compilers that can be installed, installs the
+// one chosen and adds it to PATH. It reports whether a compiler is ready to
+// use without restarting catalyst.
+func OfferCompiler() (bool, error) {
+	if compilerOffered {
+		return false, nil
+	}
+	compilerOffered = true
+
+	options := compilerOptions()
+	if len(options) == 0 {
+		return false, nil
+	}
+	choice, ok := -1, false
+	if ChooseCompiler != nil {
+		choice, ok = ChooseCompiler(options)
+	}
+	if !ok || choice < 0 || choice >= len(options) {
+		log.Warn("No C compiler found. One of these can be installed:")
+		for _, option := range options {
+			log.Warnf("  %s: %s\n", option.Name, option.Description)
+		}
+		return false, nil
+	}
+
+	option := options[choice]
+	log.Infof("Installing %s...\n", option.Name)
+	if err := option.install(); err != nil {
+		return false, fmt.Errorf("failed to install %s: %w", option.Name, err)
+	}
+	if option.Restart != "" {
+		log.Warnf("%s is installed. %s\n", option.Name, option.Restart)
+		return false, nil
+	}
+	if option.bin != nil {
+		dir := option.bin()
+		if dir == "" {
+			return false, fmt.Errorf("%s was installed but its compiler could not be found", option.Name)
+		}
+		prependPath(dir)
+	}
+	log.Infof("%s installed\n", option.Name)
+	return true, nil
+}
+
+// prependPath puts a directory first in this process's PATH, so programs
+// installed there are found without restarting the terminal
+func prependPath(dir string) {
+	path := os.Getenv("PATH")
+	for _, existing := range filepath.SplitList(path) {
+		if existing == dir {
+			return
+		}
+	}
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	log.Debugf("Added %s to PATH\n", dir)
+}
+
+func chooseCompilerOnTerminal(options []CompilerOption) (int, bool) {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return 0, false
+	}
+	fmt.Printf("No C compiler found. catalyst can install one:\n\n")
+	for i, option := range options {
+		fmt.Printf("  %d. %s\n     %s\n\n", i+1, option.Name, option.Description)
+	}
+	fmt.Printf("  0. Don't install a compiler\n\n")
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Choose compiler (0-%d): ", len(options))
+		input, err := reader.ReadString('\n')
+		choice, convErr := strconv.Atoi(strings.TrimSpace(input))
+		if convErr == nil && choice >= 0 && choice <= len(options) {
+			return choice - 1, choice > 0
+		}
+		if err != nil {
+			return 0, false
+		}
+		fmt.Println("Please enter a valid number.")
+	}
+}
+
+// hostCompilerOptions returns the compilers catalyst knows how to install
+// with the package managers on this host
+func hostCompilerOptions() []CompilerOption {
+	switch runtime.GOOS {
+	case "windows":
+		return windowsCompilerOptions()
+	case "darwin":
+		return []CompilerOption{{
+			Name:        "Xcode Command Line Tools",
+			Description: "Apple's clang, installed with xcode-select --install",
+			install:     func() error { return runInstaller("xcode-select", "--install") },
+			Restart:     "Finish the installer that opened, then run catalyst again.",
+		}}
+	}
+
+	pm := getPackageManager()
+	if _, ok := platform.LookupPackageManager(pm); !ok {
+		return nil
+	}
+	pkg := "gcc"
+	if pm == "apt" {
+		pkg = "build-essential"
+	}
+	return []CompilerOption{{
+		Name:        "GCC",
+		Description: fmt.Sprintf("installed with %s (%s)", pm, pkg),
+		install:     func() error { return Install([]string{pkg}) },
+	}}
+}
+
+// windowsCompilerOptions lists the Windows compilers, most self-contained
+// first. winget installs all but w64devkit, which scoop has.
+func windowsCompilerOptions() []CompilerOption {
+	var options []CompilerOption
+	_, wingetErr := exec.LookPath("winget")
+	hasWinget := wingetErr == nil
+
+	if hasWinget || platform.MSYS2Root() != "" {
+		options = append(options, CompilerOption{
+			Name:        "MSYS2 GCC",
+			Description: "GCC for the MSYS2 " + strings.ToUpper(platform.MSYS2Environment()) + " environment, which also has most libraries",
+			install: func() error {
+				if platform.MSYS2Root() == "" {
+					if err := runWingetInstall("MSYS2.MSYS2", ""); err != nil {
+						return err
+					}
+				}
+				return installViaMSYS2Pacman([]string{"gcc"})
+			},
+			bin: func() string {
+				if prefix := platform.MSYS2Prefix(); prefix != "" {
+					return filepath.Join(prefix, "bin")
+				}
+				return ""
+			},
+		})
+	}
+	if hasWinget {
+		programFiles := os.Getenv("ProgramFiles")
+		options = append(options,
+			CompilerOption{
+				Name:        "LLVM",
+				Description: "clang and clang-cl, which use the libraries of MSYS2 or the Build Tools",
+				install:     func() error { return runWingetInstall("LLVM.LLVM", "") },
+				bin:         func() string { return existingDir(filepath.Join(programFiles, "LLVM", "bin")) },
+			},
+			CompilerOption{
+				Name:        "Visual Studio Build Tools",
+				Description: "MSVC (cl) and the Windows SDK",
+				install: func() error {
+					return runInstaller("winget", "install", "--id", "Microsoft.VisualStudio.2022.BuildTools",
+						"--accept-package-agreements", "--accept-source-agreements",
+						"--override", "--quiet --wait --add Microsoft.VisualStudio.Workload.VCTools --includeRecommended")
+				},
+				// cl needs the environment vcvarsall.bat sets up
+				Restart: "Run catalyst again from a Developer Command Prompt for VS 2022.",
+			},
+		)
+	}
+	if _, err := exec.LookPath("scoop"); err == nil {
+		options = append(options, CompilerOption{
+			Name:        "w64devkit",
+			Description: "a portable GCC toolchain, installed with scoop",
+			install:     func() error { return runInstaller("scoop", "install", "w64devkit") },
+			bin: func() string {
+				root := os.Getenv("SCOOP")
+				if root == "" {
+					home, _ := os.UserHomeDir()
+					root = filepath.Join(home, "scoop")
+				}
+				return existingDir(filepath.Join(root, "shims"))
+			},
+		})
+	}
+	return options
+}
+
+// runInstaller runs a command installing a compiler, showing its output
+func runInstaller(command string, args ...string) error {
+	cmd := util.SystemCommand(command, args...)
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
+	return cmd.Run()
+}
+
+// existingDir returns dir if it exists, and "" otherwise
+func existingDir(dir string) string {
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir
+	}
+	return ""
+}
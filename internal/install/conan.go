@@ -0,0 +1,158 @@
+package install
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// ConanDir returns where conan install writes the pkg-config files of the
+// packages it installs, next to the recipe generated from catalyst.yml
+func ConanDir() string {
+	return util.BuildDir("conan")
+}
+
+// conanfileNames are the recipes a project can provide itself, in the order
+// Conan prefers them
+var conanfileNames = []string{"conanfile.py", "conanfile.txt"}
+
+// projectConanfile returns the project's own Conan recipe, or "" if it has none
+func projectConanfile() string {
+	for _, name := range conanfileNames {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// UsesConan reports whether dependencies come from Conan: catalyst.yml sets
+// provider: conan, or sets no provider and the project has a conanfile
+func UsesConan(cfg *config.Config) (bool, error) {
+	switch cfg.Provider {
+	case config.ProviderConan:
+		return true, nil
+	case config.ProviderSystem:
+		return false, nil
+	case "":
+		return projectConanfile() != "", nil
+	}
+	return false, fmt.Errorf("unknown provider %q (use %s or %s)", cfg.Provider, config.ProviderSystem, config.ProviderConan)
+}
+
+// InstallConan runs conan install for the project and returns the compiler
+// and linker flags of the installed packages. The project's own conanfile is
+// used if it has one; otherwise a recipe requiring the Conan references of
+// deps is generated.
+func InstallConan(deps []string) ([]string, error) {
+	if _, err := exec.LookPath("conan"); err != nil {
+		return nil, errors.New("conan not found - install it with pip install conan (https://conan.io)")
+	}
+	if err := os.MkdirAll(ConanDir(), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", ConanDir(), err)
+	}
+
+	recipe := projectConanfile()
+	addGenerator := false
+	if recipe != "" {
+		log.Infof("Installing dependencies from %s with Conan\n", recipe)
+		// The flags are read from pkg-config files, so ask for them unless
+		// the recipe already does
+		data, _ := os.ReadFile(recipe)
+		addGenerator = !bytes.Contains(data, []byte("PkgConfigDeps"))
+	} else {
+		content, unmapped := conanfileFor(deps)
+		for _, dep := range unmapped {
+			log.Warnf("No Conan package is known for %s; install it another way or add it to a conanfile\n", dep)
+		}
+		recipe = filepath.Join(ConanDir(), "conanfile.txt")
+		if err := util.WriteFileAtomic(recipe, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", recipe, err)
+		}
+		log.Infof("Installing dependencies with Conan: %v\n", deps)
+	}
+
+	// Conan needs a profile describing the compiler; detect one unless it exists
+	if err := runCommandVerbose("conan", "profile", "detect", "--exist-ok"); err != nil {
+		return nil, fmt.Errorf("conan profile detect failed: %w", err)
+	}
+	args := []string{"install", recipe, "--output-folder=" + ConanDir(), "--build=missing"}
+	if addGenerator {
+		args = append(args, "--generator=PkgConfigDeps")
+	}
+	cmd := util.SystemCommand("conan", args...)
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("conan install failed: %w", err)
+	}
+
+	return conanFlags()
+}
+
+// conanfileFor returns a conanfile.txt requiring the Conan references of
+// deps, and the dependencies no reference is known for. System libraries
+// such as libm come with the compiler and are left out.
+func conanfileFor(deps []string) (content string, unmapped []string) {
+	var refs []string
+	for _, dep := range deps {
+		if isSystemLibrary(dep) {
+			continue
+		}
+		ref, ok := pkgdb.ConanReference(dep)
+		if !ok {
+			unmapped = append(unmapped, dep)
+			continue
+		}
+		if !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("# Generated by catalyst from catalyst.yml; add a conanfile.txt to the\n# project to manage Conan dependencies yourself\n")
+	b.WriteString("[requires]\n")
+	for _, ref := range refs {
+		b.WriteString(ref + "\n")
+	}
+	b.WriteString("\n[generators]\nPkgConfigDeps\n")
+	return b.String(), unmapped
+}
+
+// conanFlags asks pkg-config for the flags of every package conan install
+// wrote a .pc file for
+func conanFlags() ([]string, error) {
+	files, _ := filepath.Glob(filepath.Join(ConanDir(), "*.pc"))
+	if len(files) == 0 {
+		return nil, nil
+	}
+	if _, err := exec.LookPath("pkg-config"); err != nil {
+		return nil, errors.New("pkg-config is needed to link Conan packages - install pkg-config (or pkgconf)")
+	}
+	absDir, err := filepath.Abs(ConanDir())
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make([]string, len(files))
+	for i, file := range files {
+		modules[i] = strings.TrimSuffix(filepath.Base(file), ".pc")
+	}
+	cmd := util.Command("pkg-config", append([]string{"--cflags", "--libs"}, modules...)...)
+	cmd.Env = append(os.Environ(), "PKG_CONFIG_PATH="+absDir)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("pkg-config failed for the Conan packages: %w", err)
+	}
+	return appendLinkFlags(nil, strings.Fields(string(output))), nil
+}
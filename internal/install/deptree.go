@@ -0,0 +1,255 @@
+package install
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// DependencyNode is a package and the runtime dependencies it pulls in
+type DependencyNode struct {
+	Name         string
+	Dependencies []*DependencyNode
+	Repeated     bool // Already expanded elsewhere in the tree
+	System       bool // System library that is never installed (m, pthread, ...)
+}
+
+// DependencyResolver queries the package manager for the runtime dependencies of packages
+type DependencyResolver struct {
+	PackageManager string
+	MaxDepth       int    // 0 means unlimited
+	VcpkgTriplet   string // vcpkg_triplet of catalyst.yml, for vcpkg's dependencies
+	direct         map[string][]string
+}
+
+// NewDependencyResolver creates a resolver for the detected package manager.
+// vcpkg is used on Windows, where winget/choco/scoop have no dependency metadata.
+func NewDependencyResolver(maxDepth int) *DependencyResolver {
+	pkgMgr := getPackageManager()
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("vcpkg"); err == nil {
+			pkgMgr = "vcpkg"
+		}
+	}
+	return &DependencyResolver{
+		PackageManager: pkgMgr,
+		MaxDepth:       maxDepth,
+		direct:         make(map[string][]string),
+	}
+}
+
+// Tree builds the transitive dependency tree of the given packages.
+// Packages already expanded earlier in the tree are marked as Repeated.
+func (r *DependencyResolver) Tree(packages []string) ([]*DependencyNode, error) {
+	switch r.PackageManager {
+	case "apt", "dnf", "yum", "pacman", "brew", "vcpkg":
+	default:
+		return nil, fmt.Errorf("dependency trees are not supported with %s (supported: apt, dnf, yum, pacman, brew, vcpkg)", r.PackageManager)
+	}
+
+	expanded := make(map[string]bool)
+	var roots []*DependencyNode
+	for _, pkg := range packages {
+		if isSystemLibrary(pkg) {
+			roots = append(roots, &DependencyNode{Name: pkg, System: true})
+			continue
+		}
+		roots = append(roots, r.buildNode(r.packageName(pkg), 1, expanded, map[string]bool{}))
+	}
+	return roots, nil
+}
+
+// Closure returns every package in the trees, sorted by name
+func Closure(roots []*DependencyNode) []string {
+	seen := make(map[string]bool)
+	var walk func(nodes []*DependencyNode)
+	walk = func(nodes []*DependencyNode) {
+		for _, node := range nodes {
+			if node.System || seen[node.Name] {
+				continue
+			}
+			seen[node.Name] = true
+			walk(node.Dependencies)
+		}
+	}
+	walk(roots)
+
+	var names []string
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildNode expands a package; path guards against dependency cycles
+func (r *DependencyResolver) buildNode(pkg string, depth int, expanded, path map[string]bool) *DependencyNode {
+	node := &DependencyNode{Name: pkg}
+	if expanded[pkg] || path[pkg] {
+		node.Repeated = true
+		return node
+	}
+	if r.MaxDepth > 0 && depth > r.MaxDepth {
+		return node
+	}
+	expanded[pkg] = true
+
+	path[pkg] = true
+	defer delete(path, pkg)
+
+	for _, dep := range r.directDependencies(pkg) {
+		node.Dependencies = append(node.Dependencies, r.buildNode(dep, depth+1, expanded, path))
+	}
+	return node
+}
+
+// packageName maps a catalyst.yml dependency to the package manager's name
+func (r *DependencyResolver) packageName(pkg string) string {
+	switch r.PackageManager {
+	case "apt":
+		return mapToDebianPackage(pkg)
+	case "pacman":
+		return mapToArchPackage(pkg)
+	}
+	return pkg
+}
+
+// directDependencies returns the runtime dependencies of a single package
+func (r *DependencyResolver) directDependencies(pkg string) []string {
+	if deps, ok := r.direct[pkg]; ok {
+		return deps
+	}
+
+	var deps []string
+	switch r.PackageManager {
+	case "apt":
+		out := commandOutput("apt-cache", "depends", "--no-recommends", "--no-suggests", "--no-conflicts",
+			"--no-breaks", "--no-replaces", "--no-enhances", "--no-pre-depends", pkg)
+		deps = parseAptDepends(out)
+	case "dnf":
+		deps = splitLines(commandOutput("dnf", "repoquery", "-q", "--requires", "--resolve", "--qf", "%{name}", pkg))
+	case "yum":
+		// repoquery comes with yum-utils
+		deps = splitLines(commandOutput("repoquery", "--requires", "--resolve", "--qf", "%{name}", pkg))
+	case "pacman":
+		out := commandOutput("pacman", "-Qi", pkg)
+		if out == "" {
+			out = commandOutput("pacman", "-Si", pkg)
+		}
+		deps = parsePacmanDepends(out)
+	case "brew":
+		deps = splitLines(commandOutput("brew", "deps", "--direct", pkg))
+	case "vcpkg":
+		// depend-info prints the whole closure at once
+		for name, d := range parseVcpkgDependInfo(commandOutput("vcpkg", "depend-info", pkg, "--triplet", vcpkgTriplet(r.VcpkgTriplet))) {
+			r.direct[name] = d
+		}
+		deps = r.direct[pkg]
+	}
+
+	deps = uniqueSorted(deps, pkg)
+	r.direct[pkg] = deps
+	return deps
+}
+
+// parseAptDepends parses "Depends: pkg" lines from apt-cache depends
+func parseAptDepends(output string) []string {
+	var deps []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "|"))
+		if !strings.HasPrefix(line, "Depends:") {
+			continue
+		}
+		dep := strings.TrimSpace(strings.TrimPrefix(line, "Depends:"))
+		// Virtual packages are shown as <name>
+		dep = strings.Trim(dep, "<>")
+		if dep != "" {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+var pacmanVersionConstraint = regexp.MustCompile(`[<>=:].*$`)
+
+// parsePacmanDepends parses the "Depends On" field of pacman -Qi/-Si
+func parsePacmanDepends(output string) []string {
+	var deps []string
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) != "Depends On" {
+			continue
+		}
+		for _, dep := range strings.Fields(value) {
+			if dep == "None" {
+				continue
+			}
+			deps = append(deps, pacmanVersionConstraint.ReplaceAllString(dep, ""))
+		}
+	}
+	return deps
+}
+
+// parseVcpkgDependInfo parses "port[features]: dep1, dep2" lines from vcpkg depend-info
+func parseVcpkgDependInfo(output string) map[string][]string {
+	result := make(map[string][]string)
+	for _, line := range strings.Split(output, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.Contains(name, " ") {
+			continue
+		}
+		if idx := strings.Index(name, "["); idx >= 0 {
+			name = name[:idx]
+		}
+		var deps []string
+		for _, dep := range strings.Split(value, ",") {
+			if dep = strings.TrimSpace(dep); dep != "" {
+				deps = append(deps, dep)
+			}
+		}
+		result[strings.TrimSpace(name)] = deps
+	}
+	return result
+}
+
+// commandOutput runs a command and returns its stdout, or "" on failure
+func commandOutput(name string, args ...string) string {
+	if _, err := exec.LookPath(name); err != nil {
+		return ""
+	}
+	output, err := util.ParsedCommand(name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return string(output)
+}
+
+// splitLines returns the non-empty trimmed lines of output
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// uniqueSorted removes duplicates and the package itself from a dependency list
+func uniqueSorted(deps []string, self string) []string {
+	seen := map[string]bool{self: true}
+	var result []string
+	for _, dep := range deps {
+		if !seen[dep] {
+			seen[dep] = true
+			result = append(result, dep)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
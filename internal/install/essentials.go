@@ -0,0 +1,60 @@
+package install
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Essential is a program catalyst works better with, beside a compiler,
+// that catalyst setup offers to install
+type Essential struct {
+	Name    string
+	Why     string // What catalyst uses it for
+	Present bool   // Already installed
+	install func() error
+}
+
+// Install installs the program with the host package manager
+func (e Essential) Install() error {
+	log.Infof("Installing %s...\n", e.Name)
+	if err := e.install(); err != nil {
+		return fmt.Errorf("failed to install %s: %w", e.Name, err)
+	}
+	return nil
+}
+
+// Essentials returns the programs catalyst uses on this host when they're
+// installed. Without a package manager there are none it can install.
+func Essentials() []Essential {
+	pkgManager := getPackageManager()
+	if pkgManager == "unknown" {
+		return nil
+	}
+	essentials := []Essential{{
+		Name:    "pkg-config",
+		Why:     "finds the compile and link flags of installed libraries",
+		Present: pkgdb.FindTool("pkg-config") != "",
+		install: func() error { return Install([]string{pkgdb.ToolPackage("pkg-config", pkgManager)}) },
+	}}
+	if pkgManager == "apt" {
+		_, err := exec.LookPath("apt-file")
+		essentials = append(essentials, Essential{
+			Name:    "apt-file",
+			Why:     "finds the package providing a header no known package maps to",
+			Present: err == nil,
+			install: func() error {
+				if err := Install([]string{"apt-file"}); err != nil {
+					return err
+				}
+				// apt-file can't search until its database is downloaded
+				_, err := runPackageCommand(util.SystemCommand("sudo", "apt-file", "update"))
+				return err
+			},
+		})
+	}
+	return essentials
+}
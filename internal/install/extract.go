@@ -0,0 +1,210 @@
+package install
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// Magic numbers of the archive formats resources can be extracted from
+var (
+	zipMagic   = []byte("PK\x03\x04")
+	gzipMagic  = []byte{0x1f, 0x8b}
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	bzip2Magic = []byte("BZh")
+)
+
+// extractArchive unpacks the .zip, .tar.gz, .tar.xz or .tar.bz2 file at
+// archive into dir, dropping the first strip path components of every
+// entry. The archive is unpacked next to dir first and then replaces it, so
+// a failed extraction leaves the previous contents in place.
+func extractArchive(archive, dir string, strip int) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+".extract-*")
+	if err != nil {
+		return fmt.Errorf("cannot create extraction directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := unpack(archive, tmp, strip); err != nil {
+		return fmt.Errorf("failed to extract %s: %w", archive, err)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("cannot replace %s: %w", dir, err)
+	}
+	return os.Rename(tmp, dir)
+}
+
+// unpack detects the archive format from its first bytes and extracts it
+func unpack(archive, dir string, strip int) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	head, _ := r.Peek(6)
+	switch {
+	case bytes.HasPrefix(head, zipMagic):
+		f.Close()
+		return unpackZip(archive, dir, strip)
+	case bytes.HasPrefix(head, gzipMagic):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return unpackTar(gz, dir, strip)
+	case bytes.HasPrefix(head, xzMagic):
+		xzr, err := xz.NewReader(r)
+		if err != nil {
+			return err
+		}
+		return unpackTar(xzr, dir, strip)
+	case bytes.HasPrefix(head, bzip2Magic):
+		return unpackTar(bzip2.NewReader(r), dir, strip)
+	default:
+		return fmt.Errorf("not a .zip, .tar.gz, .tar.xz or .tar.bz2 archive")
+	}
+}
+
+func unpackTar(r io.Reader, dir string, strip int) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, ok := entryPath(dir, hdr.Name, strip)
+		if !ok {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeReg:
+			err = writeEntry(target, tr, hdr.FileInfo().Mode())
+		case tar.TypeSymlink:
+			err = writeSymlink(dir, target, hdr.Linkname)
+		case tar.TypeLink:
+			if source, ok := entryPath(dir, hdr.Linkname, strip); ok {
+				err = os.Link(source, target)
+			}
+		default:
+			// Devices, FIFOs and PAX metadata have no place in a source tree
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func unpackZip(archive, dir string, strip int) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		target, ok := entryPath(dir, file.Name, strip)
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch mode := file.Mode(); {
+		case mode.IsDir():
+			err = os.MkdirAll(target, 0755)
+		case mode&os.ModeSymlink != 0:
+			err = unzipSymlink(dir, target, file)
+		default:
+			err = unzipFile(target, file)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unzipFile(target string, file *zip.File) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return writeEntry(target, rc, file.Mode())
+}
+
+func unzipSymlink(dir, target string, file *zip.File) error {
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	link, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	return writeSymlink(dir, target, string(link))
+}
+
+// entryPath returns where an archive entry goes in dir after stripping strip
+// leading components; ok is false for entries stripped away entirely.
+// Cleaning the name as an absolute path keeps "../" entries inside dir.
+func entryPath(dir, name string, strip int) (target string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(path.Clean("/"+name), "/"), "/")
+	if parts[0] == "" || len(parts) <= strip {
+		return "", false
+	}
+	return filepath.Join(dir, filepath.FromSlash(strings.Join(parts[strip:], "/"))), true
+}
+
+// writeEntry writes a regular file, keeping its permission bits
+func writeEntry(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeSymlink creates a symlink, refusing links that point outside dir
+func writeSymlink(dir, target, link string) error {
+	resolved := filepath.Join(filepath.Dir(target), filepath.FromSlash(link))
+	if rel, err := filepath.Rel(dir, resolved); filepath.IsAbs(link) || err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("symlink %s -> %s points outside the extraction directory", target, link)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(link, target)
+}
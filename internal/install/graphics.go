@@ -0,0 +1,104 @@
+package install
+
+import "strings"
+
+// graphicsLinkMap maps graphics package names to the linker flags they need on
+// each platform. Graphics stacks pull in extra system libraries and frameworks
+// that a single -l flag does not cover.
+var graphicsLinkMap = map[string]map[string][]string{
+	"sdl2": {
+		"linux":   {"-lSDL2"},
+		"darwin":  {"-lSDL2"},
+		"windows": {"-lmingw32", "-lSDL2main", "-lSDL2"},
+	},
+	"glfw": {
+		"linux":   {"-lglfw", "-lGL", "-lX11", "-lpthread", "-ldl"},
+		"darwin":  {"-lglfw", "-framework", "Cocoa", "-framework", "IOKit", "-framework", "CoreVideo"},
+		"windows": {"-lglfw3", "-lgdi32", "-lopengl32"},
+	},
+	"opengl": {
+		"linux":   {"-lGL"},
+		"darwin":  {"-framework", "OpenGL"},
+		"windows": {"-lopengl32", "-lgdi32"},
+	},
+	"glew": {
+		"linux":   {"-lGLEW", "-lGL"},
+		"darwin":  {"-lGLEW", "-framework", "OpenGL"},
+		"windows": {"-lglew32", "-lopengl32"},
+	},
+	"vulkan": {
+		"linux":   {"-lvulkan"},
+		"darwin":  {"-lvulkan"},
+		"windows": {"-lvulkan-1"},
+	},
+}
+
+// graphicsPackageAliases maps distro/package manager names to graphicsLinkMap keys
+var graphicsPackageAliases = map[string]string{
+	"sdl2":                "sdl2",
+	"libsdl2-dev":         "sdl2",
+	"sdl2-devel":          "sdl2",
+	"glfw":                "glfw",
+	"glfw3":               "glfw",
+	"libglfw3-dev":        "glfw",
+	"glfw-devel":          "glfw",
+	"opengl":              "opengl",
+	"gl":                  "opengl",
+	"mesa":                "opengl",
+	"libgl1-mesa-dev":     "opengl",
+	"mesa-libgl-devel":    "opengl",
+	"glew":                "glew",
+	"libglew-dev":         "glew",
+	"glew-devel":          "glew",
+	"vulkan":              "vulkan",
+	"vulkan-loader":       "vulkan",
+	"vulkan-icd-loader":   "vulkan",
+	"libvulkan-dev":       "vulkan",
+	"vulkan-loader-devel": "vulkan",
+	"vulkan-sdk":          "vulkan",
+}
+
+// graphicsLinkFlags returns the linker flags for a graphics package on the given OS
+func graphicsLinkFlags(pkg, osName string) []string {
+	key, ok := graphicsPackageAliases[strings.ToLower(pkg)]
+	if !ok {
+		return nil
+	}
+	return graphicsLinkMap[key][osName]
+}
+
+// appendLinkFlags appends flags that are not already present, keeping
+// "-framework <Name>" pairs together when checking for duplicates
+func appendLinkFlags(existing []string, flags []string) []string {
+	for i := 0; i < len(flags); i++ {
+		if flags[i] == "-framework" && i+1 < len(flags) {
+			if !hasFramework(existing, flags[i+1]) {
+				existing = append(existing, flags[i], flags[i+1])
+			}
+			i++
+			continue
+		}
+
+		isDuplicate := false
+		for _, flag := range existing {
+			if flag == flags[i] {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			existing = append(existing, flags[i])
+		}
+	}
+	return existing
+}
+
+// hasFramework checks if a macOS framework is already being linked
+func hasFramework(flags []string, name string) bool {
+	for i := 0; i+1 < len(flags); i++ {
+		if flags[i] == "-framework" && flags[i+1] == name {
+			return true
+		}
+	}
+	return false
+}
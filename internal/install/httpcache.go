@@ -0,0 +1,68 @@
+package install
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// RefreshResources makes installs re-check resources that were already
+// downloaded, with conditional requests when their validators are known
+var RefreshResources bool
+
+// resourceValidators are the HTTP cache validators of a downloaded resource,
+// stored next to it so a refresh can ask the server whether it changed
+type resourceValidators struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// validatorsPath returns the hidden file next to path holding its validators
+func validatorsPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".http.json")
+}
+
+// loadValidators reads the validators saved for the resource at path,
+// ignoring them if they were saved for a different URL
+func loadValidators(path, url string) (resourceValidators, bool) {
+	var v resourceValidators
+	data, err := os.ReadFile(validatorsPath(path))
+	if err != nil || json.Unmarshal(data, &v) != nil || v.URL != url {
+		return resourceValidators{}, false
+	}
+	return v, v.ETag != "" || v.LastModified != ""
+}
+
+// saveValidators records the validators of a response for the resource at
+// path, removing stale ones if the server sent none
+func saveValidators(path, url string, header http.Header) error {
+	v := resourceValidators{URL: url, ETag: header.Get("ETag"), LastModified: header.Get("Last-Modified")}
+	if v.ETag == "" && v.LastModified == "" {
+		err := os.Remove(validatorsPath(path))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(validatorsPath(path), append(data, '\n'), 0644)
+}
+
+// setConditionalHeaders makes req return 304 Not Modified if the resource
+// still matches v
+func setConditionalHeaders(req *http.Request, v resourceValidators) {
+	if v.ETag != "" {
+		req.Header.Set("If-None-Match", v.ETag)
+	}
+	if v.LastModified != "" {
+		req.Header.Set("If-Modified-Since", v.LastModified)
+	}
+}
@@ -1,9 +1,10 @@
 package install
 
 import (
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,10 +12,18 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/events"
+	"github.com/Sabique-Islam/catalyst/internal/headers"
+	"github.com/Sabique-Islam/catalyst/internal/lock"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 )
 
 //go:embed windows_issues.json
@@ -58,7 +67,7 @@ func loadWindowsIssuesDB() (*WindowsIssuesDatabase, error) {
 func getWindowsPackageIssue(packageName string) (*WindowsPackageIssue, bool) {
 	db, err := loadWindowsIssuesDB()
 	if err != nil {
-		fmt.Printf("Warning: Failed to load Windows issues database: %v\n", err)
+		log.Warnf("Failed to load Windows issues database: %v\n", err)
 		return nil, false
 	}
 
@@ -71,210 +80,219 @@ func getWindowsPackageIssue(packageName string) (*WindowsPackageIssue, bool) {
 	return nil, false
 }
 
-// detectLinuxPackageManager tries to find a supported package manager on Linux.
-func detectLinuxPackageManager() (string, error) {
-	candidates := []string{"apt-get", "dnf", "yum", "pacman", "zypper"}
-	for _, c := range candidates {
-		if _, err := exec.LookPath(c); err == nil {
-			return c, nil
-		}
-	}
-	return "", errors.New("no supported linux package manager found (supported: apt-get, dnf, yum, pacman, zypper)")
-}
-
 // Install installs the given dependencies (already OS-specific)
 func Install(dependencies []string) error {
+	return installPackages(dependencies, "")
+}
+
+// installPackages installs like Install, with vcpkg for the triplet of the
+// vcpkg_triplet setting of catalyst.yml
+func installPackages(dependencies []string, vcpkgTripletSetting string) error {
 	if len(dependencies) == 0 {
-		fmt.Println("No dependencies to install.")
+		log.Info("No dependencies to install.")
 		return nil
 	}
 
-	osType := runtime.GOOS
-
-	switch osType {
-	case "linux":
-		pkgMgr, err := detectLinuxPackageManager()
-		if err != nil {
-			return err
-		}
-
-		var args []string
-		switch pkgMgr {
-		case "apt-get":
-			args = append([]string{"install", "-y"}, dependencies...)
-			fmt.Printf("Using package manager: %s\n", pkgMgr)
-			err = runCommand("sudo", append([]string{"apt-get"}, args...)...)
-		case "dnf", "yum":
-			args = append([]string{"install", "-y"}, dependencies...)
-			fmt.Printf("Using package manager: %s\n", pkgMgr)
-			err = runCommand("sudo", append([]string{pkgMgr}, args...)...)
-		case "pacman":
-			args = append([]string{"-S", "--noconfirm"}, dependencies...)
-			fmt.Printf("Using package manager: %s\n", pkgMgr)
-			err = runCommand("sudo", append([]string{"pacman"}, args...)...)
-		case "zypper":
-			args = append([]string{"install", "-y"}, dependencies...)
-			fmt.Printf("Using package manager: %s\n", pkgMgr)
-			err = runCommand("sudo", append([]string{"zypper"}, args...)...)
-		}
-
-		if err != nil {
-			return fmt.Errorf("failed installing with %s: %w", pkgMgr, err)
-		}
+	pkgMgr := getPackageManager()
+	pm, ok := platform.LookupPackageManager(pkgMgr)
+	if !ok {
+		return noPackageManagerError()
+	}
 
-	case "darwin":
-		if _, err := exec.LookPath("brew"); err != nil {
-			return errors.New("homebrew not found - install it from https://brew.sh/")
-		}
-		fmt.Println("Using package manager: brew")
-		args := append([]string{"install"}, dependencies...)
-		if err := runCommand("brew", args...); err != nil {
-			return fmt.Errorf("brew install failed: %w", err)
+	var err error
+	switch pkgMgr {
+	case "choco":
+		log.Infof("Using package manager: %s\n", pkgMgr)
+		// Locked versions need their own choco install
+		var unpinned []string
+		for _, dep := range dependencies {
+			name, version := splitPin(dep)
+			if version == "" {
+				unpinned = append(unpinned, dep)
+			} else if err = runCommand("choco", "install", name, "--version="+version, "-y"); err != nil {
+				break
+			}
 		}
-
-	case "windows":
-		pkgMgr := getPackageManager()
-		if pkgMgr == "unknown" {
-			return errors.New("no Windows package manager found. Please install winget, chocolatey (https://chocolatey.org/install), or scoop (https://scoop.sh)")
+		if err == nil && len(unpinned) > 0 {
+			err = runCommand("choco", append([]string{"install", "-y"}, unpinned...)...)
 		}
+	case "winget":
+		log.Infof("Using package manager: %s\n", pkgMgr)
+		log.Info()
+		var lastErr error
+		successCount := 0
+		hasMSYS2 := false
+		msys2Packages := []string{}
+
+		// First pass: install base packages via winget, collect MSYS2 packages
+		for _, spec := range dependencies {
+			dep, version := splitPin(spec)
+			winPkg := mapToWindowsPackage(dep, "winget")
+
+			// Check for Windows compatibility issues
+			checkWindowsPackageCompatibility(dep)
+
+			// Check if this is a package that should be installed via MSYS2 pacman
+			if shouldUseMSYS2Pacman(dep) {
+				msys2Packages = append(msys2Packages, dep)
+				continue
+			}
 
-		var args []string
-		var err error
-		switch pkgMgr {
-		case "choco":
-			args = append([]string{"install", "-y"}, dependencies...)
-			fmt.Printf("Using package manager: %s\n", pkgMgr)
-			err = runCommand("choco", args...)
-		case "winget":
-			fmt.Printf("Using package manager: %s\n", pkgMgr)
-			fmt.Println()
-			var lastErr error
-			successCount := 0
-			hasMSYS2 := false
-			msys2Packages := []string{}
-
-			// First pass: install base packages via winget, collect MSYS2 packages
-			for _, dep := range dependencies {
-				winPkg := mapToWindowsPackage(dep, "winget")
-
-				// Check for Windows compatibility issues
-				checkWindowsPackageCompatibility(dep)
-
-				// Check if this is a package that should be installed via MSYS2 pacman
-				if shouldUseMSYS2Pacman(dep) {
-					msys2Packages = append(msys2Packages, dep)
-					continue
-				}
+			log.Infof("Installing %s", dep)
+			if winPkg != dep {
+				log.Infof(" (package: %s)", winPkg)
+			}
+			log.Info("...")
 
-				fmt.Printf("Installing %s", dep)
-				if winPkg != dep {
-					fmt.Printf(" (package: %s)", winPkg)
-				}
-				fmt.Println("...")
+			if winPkg == "MSYS2.MSYS2" {
+				hasMSYS2 = true
+			}
 
-				if winPkg == "MSYS2.MSYS2" {
-					hasMSYS2 = true
-				}
+			if installed, ok := platform.InstalledVersion(winPkg, "winget"); ok && (version == "" || installed == version) {
+				log.Infof("  → Already installed (%s)\n\n", installed)
+				successCount++
+				continue
+			}
 
-				err = runWingetInstall(winPkg)
-				if err != nil {
-					// For winget, check if it's an "already installed" or "no applicable installer" error
-					if isWingetNonCriticalError(err) {
-						fmt.Printf("  → Skipped: Package may already be installed or installation was interrupted\n")
-						if winPkg == "MSYS2.MSYS2" {
-							hasMSYS2 = true // Still mark as available for pacman use
-							fmt.Printf("     MSYS2 appears to be already installed\n")
-						}
-						fmt.Println()
-						continue // Continue with other packages
+			err = runWingetInstall(winPkg, version)
+			if err != nil {
+				// For winget, check if it's an "already installed" or "no applicable installer" error
+				if isWingetNonCriticalError(err) {
+					log.Infof("  → Skipped: Package may already be installed or installation was interrupted\n")
+					if winPkg == "MSYS2.MSYS2" {
+						hasMSYS2 = true // Still mark as available for pacman use
+						log.Infof("     MSYS2 appears to be already installed\n")
 					}
-					fmt.Printf("  → Failed to install %s\n\n", dep)
-					lastErr = err
-					// Continue trying other packages instead of stopping
-					continue
+					log.Info()
+					continue // Continue with other packages
 				}
-				fmt.Printf("  → Successfully installed %s\n\n", dep)
-				successCount++
+				log.Errorf("  → Failed to install %s\n\n", dep)
+				lastErr = err
+				// Continue trying other packages instead of stopping
+				continue
 			}
+			log.Infof("  → Successfully installed %s\n\n", dep)
+			successCount++
+		}
 
-			// Second pass: install development libraries via MSYS2 pacman if available
-			if len(msys2Packages) > 0 {
-				if hasMSYS2 || isMSYS2Installed() {
-					fmt.Printf("\nInstalling development libraries via MSYS2 pacman: %v\n", msys2Packages)
-					if err := installViaMSYS2Pacman(msys2Packages); err != nil {
-						fmt.Printf("Warning: Failed to install some packages via MSYS2: %v\n", err)
-						fmt.Printf("You may need to manually install these packages:\n")
-						for _, pkg := range msys2Packages {
-							msys2Pkg := mapToMSYS2Package(pkg)
-							fmt.Printf("  pacman -S %s\n", msys2Pkg)
-						}
-					} else {
-						successCount += len(msys2Packages)
-					}
-				} else {
-					fmt.Printf("\nWarning: The following packages require MSYS2 but it's not installed: %v\n", msys2Packages)
-					fmt.Printf("Please install MSYS2 from https://www.msys2.org/ and then run:\n")
+		// Second pass: install development libraries via MSYS2 pacman if available
+		if len(msys2Packages) > 0 {
+			if hasMSYS2 || platform.MSYS2Root() != "" {
+				log.Infof("\nInstalling development libraries via MSYS2 pacman: %v\n", msys2Packages)
+				if err := installViaMSYS2Pacman(msys2Packages); err != nil {
+					log.Warnf("Failed to install some packages via MSYS2: %v\n", err)
+					log.Infof("You may need to manually install these packages:\n")
 					for _, pkg := range msys2Packages {
 						msys2Pkg := mapToMSYS2Package(pkg)
-						fmt.Printf("  pacman -S %s\n", msys2Pkg)
+						log.Infof("  pacman -S %s\n", msys2Pkg)
 					}
+				} else {
+					successCount += len(msys2Packages)
 				}
-			}
-
-			// Only return error if all packages failed and none were skipped
-			if successCount == 0 && lastErr != nil {
-				err = lastErr
 			} else {
-				err = nil
+				log.Warnf("\nThe following packages require MSYS2 but it's not installed: %v\n", msys2Packages)
+				log.Infof("Please install MSYS2 from https://www.msys2.org/ and then run:\n")
+				for _, pkg := range msys2Packages {
+					msys2Pkg := mapToMSYS2Package(pkg)
+					log.Infof("  pacman -S %s\n", msys2Pkg)
+				}
 			}
-		case "scoop":
-			args = append([]string{"install"}, dependencies...)
-			fmt.Printf("Using package manager: %s\n", pkgMgr)
-			err = runCommand("scoop", args...)
-		default:
-			return fmt.Errorf("unsupported Windows package manager: %s", pkgMgr)
 		}
 
-		if err != nil {
-			return fmt.Errorf("failed installing with %s: %w", pkgMgr, err)
+		// Only return error if all packages failed and none were skipped
+		if successCount == 0 && lastErr != nil {
+			err = lastErr
+		} else {
+			err = nil
 		}
-
+	case "msys2":
+		log.Infof("Using package manager: %s (%s)\n", pkgMgr, strings.ToUpper(platform.MSYS2Environment()))
+		err = installViaMSYS2Pacman(dependencies)
 	default:
-		return fmt.Errorf("unsupported OS: %s", osType)
+		log.Infof("Using package manager: %s\n", pkgMgr)
+		var cmd *util.Cmd
+		if cmd, err = pm.Install(dependencies...); err == nil {
+			cmd.Args = append(cmd.Args, tripletArgs(pkgMgr, vcpkgTripletSetting)...)
+			packages := strings.Join(dependencies, ", ")
+			var output []byte
+			output, err = runPackageCommand(cmd)
+			if err != nil && recoverInstall(pkgMgr, packages, string(output)) {
+				log.Infof("Retrying %s...\n", packages)
+				output, err = runPackageCommand(cmd)
+			}
+			if err != nil {
+				err = fmt.Errorf("%w\nOutput: %s", err, output)
+			}
+		}
 	}
 
+	if err != nil {
+		return fmt.Errorf("failed installing with %s: %w", pkgMgr, err)
+	}
 	return nil
 }
 
+// tripletArgs returns the arguments installing with vcpkg for the triplet
+// of the vcpkg_triplet setting; none for other package managers, or for
+// vcpkg's own default
+func tripletArgs(pkgMgr, vcpkgTripletSetting string) []string {
+	if pkgMgr != "vcpkg" || vcpkgTripletSetting == "" {
+		return nil
+	}
+	return []string{"--triplet", vcpkgTriplet(vcpkgTripletSetting)}
+}
+
+// noPackageManagerError explains that the host has no package manager
+// catalyst can install with
+func noPackageManagerError() error {
+	switch runtime.GOOS {
+	case "windows":
+		return fmt.Errorf("no Windows package manager found. Please install one of: winget (Windows Package Manager), chocolatey (https://chocolatey.org/install), or scoop (https://scoop.sh)")
+	case "darwin":
+		return fmt.Errorf("no macOS package manager found. Please install Homebrew (https://brew.sh/) or MacPorts (https://www.macports.org/)")
+	case "linux":
+		var names []string
+		for _, pm := range platform.PackageManagers("linux") {
+			names = append(names, pm.Name())
+		}
+		return fmt.Errorf("no supported Linux package manager found. Supported: %s", strings.Join(names, ", "))
+	default:
+		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+}
+
+// InstallOptions controls where dependencies are installed
+type InstallOptions struct {
+	Isolated bool // Install into the project-local PrefixDir instead of system-wide
+	Frozen   bool // Fail instead of updating catalyst.lock when resolution differs from it
+	DryRun   bool // Print package manager commands instead of running them
+	// Dependency categories to install (build, tools, runtime, dev); all if empty
+	Only []string
+}
+
 // InstallDependencies loads the config, gets OS-specific dependencies, and installs them
 // Also downloads external resources (files) specified in the config
 func InstallDependencies() error {
+	return InstallDependenciesWithOptions(InstallOptions{})
+}
+
+// InstallDependenciesWithOptions installs like InstallDependencies.
+// Dependencies are isolated if either opts or catalyst.yml requests it.
+func InstallDependenciesWithOptions(opts InstallOptions) error {
 	// Load catalyst.yml
-	cfg, err := config.LoadConfig("catalyst.yml")
+	cfg, err := config.LoadConfig(config.ProjectFile)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Install system dependencies
-	deps := cfg.GetDependencies() // returns []string
-	if len(deps) > 0 {
-		fmt.Printf("Installing system dependencies for %s: %v\n", runtime.GOOS, deps)
-		fmt.Println()
-
-		if err := Install(deps); err != nil {
-			return fmt.Errorf("system dependency installation failed: %w", err)
-		}
-
-		fmt.Println()
-		fmt.Println("System dependencies installed successfully!")
-		fmt.Println()
-	} else {
-		fmt.Println("No system dependencies to install for this OS.")
-		fmt.Println()
+	if err := installSystemDependencies(cfg, opts); err != nil {
+		return err
 	}
+	log.Info()
 
 	// Install external resources (download files)
-	if err := InstallResources(cfg); err != nil {
+	if err := InstallResourcesLocked(cfg, lock.DefaultPath); err != nil {
 		return fmt.Errorf("external resource installation failed: %w", err)
 	}
 
@@ -284,72 +302,300 @@ func InstallDependencies() error {
 // InstallExternalResourcesOnly downloads only external resources without installing system dependencies
 func InstallExternalResourcesOnly() error {
 	// Load catalyst.yml
-	cfg, err := config.LoadConfig("catalyst.yml")
+	cfg, err := config.LoadConfig(config.ProjectFile)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Install only external resources
-	return InstallResources(cfg)
+	return InstallResourcesLocked(cfg, lock.DefaultPath)
 }
 
 // InstallSystemDependenciesOnly installs only system dependencies without downloading external resources
 func InstallSystemDependenciesOnly() error {
+	return InstallSystemDependenciesOnlyWithOptions(InstallOptions{})
+}
+
+// InstallSystemDependenciesOnlyWithOptions installs like InstallSystemDependenciesOnly
+func InstallSystemDependenciesOnlyWithOptions(opts InstallOptions) error {
 	// Load catalyst.yml
-	cfg, err := config.LoadConfig("catalyst.yml")
+	cfg, err := config.LoadConfig(config.ProjectFile)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Install only system dependencies
-	deps := cfg.GetDependencies()
+	return installSystemDependencies(cfg, opts)
+}
+
+// installSystemDependencies installs the dependencies for the current OS,
+// either system-wide or into the project prefix, and records them in
+// catalyst.lock. Locked versions are installed where the package manager can
+// pin them.
+func installSystemDependencies(cfg *config.Config, opts InstallOptions) error {
+	isolated := opts.Isolated || cfg.Isolated
+	if opts.DryRun {
+		if isolated {
+			return fmt.Errorf("dry runs are not supported for isolated installs")
+		}
+		// Queries such as installed-package checks still run, so the
+		// printed commands are the ones a real install would run
+		previous := util.Exec
+		util.Exec = util.DryRunExecutor{Out: log.Stdout(), Next: previous}
+		defer func() { util.Exec = previous }()
+	}
+	if err := config.ValidateCategories(opts.Only); err != nil {
+		return err
+	}
+	deps := cfg.DependenciesInCategories(runtime.GOOS, opts.Only)
+	// catalyst.lock covers every category, whichever ones are installed
+	allDeps := cfg.DependenciesInCategories(runtime.GOOS, nil)
+
+	wantTools := len(opts.Only) == 0 || slices.Contains(opts.Only, config.CategoryTools)
+
+	// Conan keeps its own lockfiles and needs no system package manager
+	// for the libraries
+	useConan, err := UsesConan(cfg)
+	if err != nil {
+		return err
+	}
+	if useConan {
+		if isolated {
+			return fmt.Errorf("isolated installs don't apply to provider: %s, whose packages are already per-project", config.ProviderConan)
+		}
+		// Tools still come from the system package manager, as in builds
+		if wantTools && len(cfg.Tools) > 0 && platform.DetectSupport().FullySupported() {
+			if err := InstallTools(cfg.Tools); err != nil {
+				return err
+			}
+		}
+		if _, err := InstallConan(deps); err != nil {
+			return fmt.Errorf("system dependency installation failed: %w", err)
+		}
+		log.Info()
+		log.Info("Conan dependencies installed successfully!")
+		return nil
+	}
+
+	lf, err := lock.Load(lock.DefaultPath)
+	if err != nil {
+		return err
+	}
+	if opts.Frozen {
+		if err := checkFrozenPackages(allDeps, lf, runtime.GOOS); err != nil {
+			return err
+		}
+	}
+
+	// Tools already in PATH are left alone, whichever way they were installed
+	pkgManager := getPackageManager()
+	var tools []string
+	if wantTools {
+		tools = missingToolPackages(cfg.Tools, pkgManager)
+	}
+	if isolated && len(tools) > 0 {
+		// The prefix isn't in PATH, so tools are always installed system-wide
+		if err := InstallTools(cfg.Tools); err != nil {
+			return err
+		}
+		tools = nil
+	}
+	for _, pkg := range tools {
+		if !slices.Contains(deps, pkg) {
+			deps = append(deps, pkg)
+		}
+	}
+
 	if len(deps) == 0 {
-		fmt.Println("No system dependencies to install for this OS.")
+		log.Info("No system dependencies to install for this OS.")
+		return nil
+	}
+	if support := platform.DetectSupport(); !support.FullySupported() {
+		log.Warn(strings.TrimSuffix(support.Report(deps), "\n"))
 		return nil
 	}
 
-	fmt.Printf("Installing system dependencies for %s: %v\n", runtime.GOOS, deps)
-	fmt.Println()
+	if len(opts.Only) > 0 {
+		log.Infof("Installing %s dependencies for %s: %v\n", strings.Join(opts.Only, ", "), runtime.GOOS, deps)
+	} else {
+		log.Infof("Installing system dependencies for %s: %v\n", runtime.GOOS, deps)
+	}
+	log.Info()
 
-	if err := Install(deps); err != nil {
+	installFn := func(deps []string) error { return installPackages(deps, cfg.VcpkgTriplet) }
+	specs := deps
+	if isolated {
+		installFn = func(deps []string) error { return InstallToPrefix(deps, PrefixDir(), cfg.VcpkgTriplet) }
+	} else {
+		if err := ensureRepos(pkgManager, deps, opts.DryRun); err != nil {
+			return err
+		}
+		specs = pinnedPackageSpecs(deps, lf, runtime.GOOS, pkgManager)
+	}
+	for _, spec := range specs {
+		events.Emit(events.Event{Kind: events.PackageInstallStarted, Name: spec})
+	}
+	start := time.Now()
+	err = installFn(specs)
+	for _, spec := range specs {
+		events.Emit(events.Event{Kind: events.PackageInstallFinished, Name: spec, Duration: time.Since(start), Error: events.Err(err)})
+	}
+	if err != nil {
+		// A common cause in projects locked a while ago
+		if !isolated {
+			if missing := unavailablePins(deps, lf, runtime.GOOS, pkgManager); len(missing) > 0 {
+				return fmt.Errorf("system dependency installation failed: locked versions are no longer available from %s:\n  %s\nRemove their entries from %s and run catalyst install again to lock the current versions",
+					pkgManager, strings.Join(missing, "\n  "), lock.DefaultPath)
+			}
+		}
 		return fmt.Errorf("system dependency installation failed: %w", err)
 	}
 
-	fmt.Println()
-	fmt.Println("System dependencies installed successfully!")
+	if opts.DryRun {
+		log.Info()
+		log.Info("Dry run: nothing was installed and catalyst.lock was not changed.")
+		return nil
+	}
+
+	// Versions are only known for packages installed system-wide
+	if err := lockInstalledPackages(allDeps, lf, runtime.GOOS, pkgManager, !isolated, opts.Frozen); err != nil {
+		return err
+	}
+
+	log.Info()
+	log.Info("System dependencies installed successfully!")
 	return nil
 }
 
 // InstallDependenciesAndGetLinkerFlags installs dependencies and returns linker flags for them
 func InstallDependenciesAndGetLinkerFlags() ([]string, error) {
+	return InstallDependenciesWithExtras(nil)
+}
+
+// InstallDependenciesWithExtras is InstallDependenciesAndGetLinkerFlags with
+// extra packages, such as those of enabled features, added to catalyst.yml's
+func InstallDependenciesWithExtras(extra []string) ([]string, error) {
 	// Load catalyst.yml
-	cfg, err := config.LoadConfig("catalyst.yml")
+	cfg, err := config.LoadConfig(config.ProjectFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Get dependencies for current OS only
 	deps := cfg.GetDependencies() // returns []string
+	for _, dep := range extra {
+		if !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+
+	// Tools are needed whichever way the libraries are installed
+	if len(cfg.Tools) > 0 && platform.DetectSupport().FullySupported() {
+		if err := InstallTools(cfg.Tools); err != nil {
+			return nil, err
+		}
+	}
+
+	useConan, err := UsesConan(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if useConan {
+		libFlags, err := InstallConan(deps)
+		if err != nil {
+			return nil, err
+		}
+		return appendLinkFlags(libFlags, generateLinkingFlags(nil)), nil
+	}
 	if len(deps) == 0 {
-		fmt.Println("No dependencies to install for this OS.")
-		return []string{}, nil
+		log.Info("No dependencies to install for this OS.")
+		// Still link the libraries every C program may need (libm)
+		return generateLinkingFlags(nil), nil
 	}
 
-	fmt.Printf("Installing dependencies for %s: %v\n", runtime.GOOS, deps)
+	log.Infof("Installing dependencies for %s: %v\n", runtime.GOOS, deps)
 
-	// Install each package
-	for _, pkg := range deps {
-		if err := installPackage(pkg); err != nil {
-			return nil, fmt.Errorf("failed to install package %s: %w", pkg, err)
+	if cfg.Isolated {
+		if err := InstallToPrefix(deps, PrefixDir(), cfg.VcpkgTriplet); err != nil {
+			return nil, err
+		}
+	} else if support := platform.DetectSupport(); !support.FullySupported() {
+		// Compile anyway, against whatever the user installed themselves
+		log.Warn(strings.TrimSuffix(support.Report(deps), "\n"))
+	} else {
+		// Install each package
+		for _, pkg := range deps {
+			if err := installPackage(pkg, cfg.VcpkgTriplet); err != nil {
+				return nil, fmt.Errorf("failed to install package %s: %w", pkg, err)
+			}
 		}
 	}
+	return installedFlags(cfg, deps), nil
+}
 
-	// Generate comprehensive linking flags
-	libFlags := generateLinkingFlags(deps)
+// DependencyFlags returns the flags InstallDependenciesWithExtras would for
+// the packages installed now, without installing anything
+func DependencyFlags(extra []string) ([]string, error) {
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	deps := cfg.GetDependencies()
+	for _, dep := range extra {
+		if !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	useConan, err := UsesConan(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if useConan {
+		libFlags, err := conanFlags()
+		if err != nil {
+			return nil, err
+		}
+		return appendLinkFlags(libFlags, generateLinkingFlags(nil)), nil
+	}
+	if len(deps) == 0 {
+		return generateLinkingFlags(nil), nil
+	}
+	return installedFlags(cfg, deps), nil
+}
+
+// installedFlags returns the compiler and linker flags of installed deps
+func installedFlags(cfg *config.Config, deps []string) []string {
+	var libFlags []string
+	if cfg.Isolated {
+		libFlags = append(libFlags, PrefixFlags(PrefixDir())...)
+	} else if platform.DetectSupport().FullySupported() {
+		// Find where the packages put their headers and libraries
+		if pathFlags := DiscoverPackageFlags(deps, cfg.VcpkgTriplet); len(pathFlags) > 0 {
+			log.Debugf("Discovered package paths: %s\n", strings.Join(pathFlags, " "))
+			libFlags = append(libFlags, pathFlags...)
+		}
+	}
+
+	// Ask pkg-config first, then fall back to the link map for the rest.
+	// Packages in the isolated prefix have .pc files pointing at their
+	// original prefix, so only system installs use pkg-config.
+	linkDeps := deps
+	if !cfg.Isolated {
+		var pcFlags []string
+		pcFlags, linkDeps = pkgConfigFlags(deps)
+		libFlags = append(libFlags, pcFlags...)
+	}
+	libFlags = appendLinkFlags(libFlags, generateLinkingFlags(linkDeps))
 	if len(libFlags) > 0 {
-		fmt.Printf("Adding linking flags: %s\n", strings.Join(libFlags, " "))
+		log.Debugf("Adding linking flags: %s\n", strings.Join(libFlags, " "))
 	}
-	return libFlags, nil
+	return libFlags
+}
+
+// LinkingFlags returns the linker flags for dependencies without installing
+// them, for cross builds whose libraries come from the target sysroot
+func LinkingFlags(dependencies []string) []string {
+	return generateLinkingFlags(dependencies)
 }
 
 // generateLinkingFlags generates linking flags based on detected dependencies
@@ -426,19 +672,11 @@ func generateLinkingFlags(dependencies []string) []string {
 		// Add preprocessor flag for OpenMP
 		linkFlags = append(linkFlags, "-Xpreprocessor", "-fopenmp")
 
-		// Add include and library paths for Homebrew libomp
-		// Check both Apple Silicon (/opt/homebrew) and Intel (/usr/local) paths
-		homebrewPaths := []string{
-			"/opt/homebrew/opt/libomp",
-			"/usr/local/opt/libomp",
-		}
-
-		for _, path := range homebrewPaths {
-			if _, err := os.Stat(path); err == nil {
-				linkFlags = append(linkFlags, "-I"+filepath.Join(path, "include"))
-				linkFlags = append(linkFlags, "-L"+filepath.Join(path, "lib"))
-				break
-			}
+		// Add include and library paths for Homebrew libomp, wherever the
+		// prefix is (/opt/homebrew on Apple Silicon, /usr/local on Intel)
+		if prefix := platform.BrewFormulaPrefix("libomp"); prefix != "" {
+			linkFlags = append(linkFlags, "-I"+filepath.Join(prefix, "include"))
+			linkFlags = append(linkFlags, "-L"+filepath.Join(prefix, "lib"))
 		}
 
 		// Add the linker flag for libomp
@@ -458,6 +696,18 @@ func generateLinkingFlags(dependencies []string) []string {
 			continue
 		}
 
+		// Windows SDK import libraries ship with MinGW and link with -l
+		if strings.HasSuffix(depLower, ".lib") && isWindowsSystemLibrary(depLower) {
+			linkFlags = appendLinkFlags(linkFlags, headers.WindowsLinkFlags([]string{depLower}, false))
+			continue
+		}
+
+		// Graphics stacks need platform-specific system libraries and frameworks
+		if gfxFlags := graphicsLinkFlags(depLower, runtime.GOOS); len(gfxFlags) > 0 {
+			linkFlags = appendLinkFlags(linkFlags, gfxFlags)
+			continue
+		}
+
 		if linkLib, found := linkMap[depLower]; found {
 			linkFlag := "-l" + linkLib
 			// Avoid duplicates
@@ -478,151 +728,130 @@ func generateLinkingFlags(dependencies []string) []string {
 }
 
 func getPackageManager() string {
-	// Check for different package managers based on OS
-	osType := runtime.GOOS
-
-	switch osType {
-	case "windows":
-		// Priority order for Windows: winget > choco > scoop
-		if _, err := exec.LookPath("winget"); err == nil {
-			return "winget"
-		}
-		if _, err := exec.LookPath("choco"); err == nil {
-			return "choco"
-		}
-		if _, err := exec.LookPath("scoop"); err == nil {
-			return "scoop"
-		}
-	case "darwin":
-		if _, err := exec.LookPath("brew"); err == nil {
-			return "brew"
-		}
-	case "linux":
-		// Check for different Linux package managers
-		if _, err := exec.LookPath("pacman"); err == nil {
-			return "pacman"
-		}
-		if _, err := exec.LookPath("apt-get"); err == nil {
-			return "apt"
-		}
-		if _, err := exec.LookPath("dnf"); err == nil {
-			return "dnf"
-		}
-		if _, err := exec.LookPath("yum"); err == nil {
-			return "yum"
-		}
-		if _, err := exec.LookPath("zypper"); err == nil {
-			return "zypper"
-		}
+	if pkgManager := platform.HostPackageManager(); pkgManager != "" {
+		return pkgManager
 	}
-
 	return "unknown"
 }
 
-// installPackage installs a single package
-func installPackage(pkg string) error {
-	var cmd *exec.Cmd
-
-	// Skip system libraries that don't need installation
-	systemLibs := []string{"m", "pthread", "dl", "rt"}
-	windowsSystemLibs := []string{"ws2_32.lib", "user32.lib", "kernel32.lib", "advapi32.lib", "shell32.lib", "ole32.lib", "oleaut32.lib", "uuid.lib", "winmm.lib", "gdi32.lib", "comctl32.lib", "comdlg32.lib", "winspool.lib"}
-
-	osType := runtime.GOOS
+// System libraries that ship with the OS or toolchain and are never installed
+var (
+	systemLibs        = []string{"m", "pthread", "dl", "rt"}
+	windowsSystemLibs = []string{"ws2_32.lib", "user32.lib", "kernel32.lib", "advapi32.lib", "shell32.lib", "ole32.lib", "oleaut32.lib", "uuid.lib", "winmm.lib", "gdi32.lib", "comctl32.lib", "comdlg32.lib", "winspool.lib"}
+)
 
-	// Check Unix/Linux system libraries
+// isSystemLibrary checks if a dependency is a system library on the current OS
+func isSystemLibrary(pkg string) bool {
 	for _, sysLib := range systemLibs {
 		if pkg == sysLib {
-			fmt.Printf("Skipping installation of system library: %s\n", pkg)
-			return nil
+			return true
 		}
 	}
+	return runtime.GOOS == "windows" && isWindowsSystemLibrary(pkg)
+}
 
-	// Check Windows system libraries
-	if osType == "windows" {
-		for _, sysLib := range windowsSystemLibs {
-			if pkg == sysLib || strings.EqualFold(pkg, sysLib) {
-				fmt.Printf("Skipping installation of Windows system library: %s\n", pkg)
-				return nil
-			}
+// isWindowsSystemLibrary checks if a dependency is a Windows SDK import
+// library, with or without the .lib extension
+func isWindowsSystemLibrary(pkg string) bool {
+	pkg = strings.TrimSuffix(strings.ToLower(pkg), ".lib")
+	for _, sysLib := range windowsSystemLibs {
+		if pkg == strings.TrimSuffix(sysLib, ".lib") {
+			return true
+		}
+	}
+	return headers.IsWindowsLibrary(pkg)
+}
+
+// installPackage installs a single package
+func installPackage(pkg, vcpkgTripletSetting string) error {
+	// Skip system libraries that don't need installation
+	if isSystemLibrary(pkg) {
+		if runtime.GOOS == "windows" && strings.HasSuffix(strings.ToLower(pkg), ".lib") {
+			log.Infof("Skipping installation of Windows system library: %s\n", pkg)
+		} else {
+			log.Infof("Skipping installation of system library: %s\n", pkg)
 		}
+		return nil
 	}
 
 	pkgManager := getPackageManager()
+	if err := ensureRepos(pkgManager, []string{pkg}, false); err != nil {
+		return err
+	}
 
-	switch pkgManager {
-	case "pacman":
-		// Arch Linux package names
-		archPkg := mapToArchPackage(pkg)
-		cmd = exec.Command("sudo", "pacman", "-S", "--noconfirm", archPkg)
-	case "apt":
-		debPkg := mapToDebianPackage(pkg)
-		cmd = exec.Command("sudo", "apt-get", "install", "-y", debPkg)
-	case "brew":
-		cmd = exec.Command("brew", "install", pkg)
-	case "yum":
-		cmd = exec.Command("sudo", "yum", "install", "-y", pkg)
-	case "dnf":
-		cmd = exec.Command("sudo", "dnf", "install", "-y", pkg)
-	case "zypper":
-		cmd = exec.Command("sudo", "zypper", "install", "-y", pkg)
-	case "choco":
-		// Chocolatey for Windows
-		winPkg := mapToWindowsPackage(pkg, "choco")
-		cmd = exec.Command("choco", "install", winPkg, "-y")
-	case "winget":
+	if pkgManager == "winget" {
 		// Check for Windows compatibility issues before installation
 		checkWindowsPackageCompatibility(pkg)
 
 		// Windows Package Manager - check if package should use MSYS2 pacman instead
 		if shouldUseMSYS2Pacman(pkg) {
-			if isMSYS2Installed() {
-				fmt.Printf("Installing %s via MSYS2 pacman...\n", pkg)
+			if platform.MSYS2Root() != "" {
+				log.Infof("Installing %s via MSYS2 pacman...\n", pkg)
 				return installViaMSYS2Pacman([]string{pkg})
 			} else {
-				fmt.Printf("Warning: %s requires MSYS2 but it's not installed\n", pkg)
-				fmt.Printf("Please install MSYS2 from https://www.msys2.org/ and run: pacman -S %s\n", mapToMSYS2Package(pkg))
+				log.Warnf("%s requires MSYS2 but it's not installed\n", pkg)
+				log.Infof("Please install MSYS2 from https://www.msys2.org/ and run: pacman -S %s\n", mapToMSYS2Package(pkg))
 				return nil // Don't fail, just warn
 			}
 		}
 
 		// For winget packages
 		winPkg := mapToWindowsPackage(pkg, "winget")
-		fmt.Printf("Installing %s with %s...\n", pkg, pkgManager)
-		err := runWingetInstall(winPkg)
+		log.Infof("Installing %s with %s...\n", pkg, pkgManager)
+		err := runWingetInstall(winPkg, "")
 		if err != nil {
 			if isWingetNonCriticalError(err) {
-				fmt.Printf("  Note: %s may already be installed or unavailable via winget\n", winPkg)
+				log.Infof("  Note: %s may already be installed or unavailable via winget\n", winPkg)
 				return nil // Treat as success
 			}
 			return fmt.Errorf("failed installing %s with winget: %w", pkg, err)
 		}
 		return nil
-	case "scoop":
-		// Scoop for Windows
-		winPkg := mapToWindowsPackage(pkg, "scoop")
-		cmd = exec.Command("scoop", "install", winPkg)
-	default:
-		osType := runtime.GOOS
-		switch osType {
-		case "windows":
-			return fmt.Errorf("no Windows package manager found. Please install one of: winget (Windows Package Manager), chocolatey (https://chocolatey.org/install), or scoop (https://scoop.sh)")
-		case "darwin":
-			return fmt.Errorf("homebrew not found. Please install it from https://brew.sh/")
-		case "linux":
-			return fmt.Errorf("no supported Linux package manager found. Supported: apt-get, dnf, yum, pacman, zypper")
-		default:
-			return fmt.Errorf("unsupported operating system: %s", osType)
-		}
 	}
 
-	fmt.Printf("Installing %s with %s...\n", pkg, pkgManager)
-	output, err := cmd.CombinedOutput()
+	pm, ok := platform.LookupPackageManager(pkgManager)
+	if !ok {
+		return noPackageManagerError()
+	}
+	// Answered from the installed packages cache on most builds
+	if version, ok := pm.IsInstalled(mapPackageName(pkg, pkgManager)); ok {
+		log.Debugf("%s %s is already installed\n", pkg, version)
+		return nil
+	}
+	cmd, err := pm.Install(mapPackageName(pkg, pkgManager))
+	if err != nil {
+		return err
+	}
+	cmd.Args = append(cmd.Args, tripletArgs(pkgManager, vcpkgTripletSetting)...)
+
+	log.Infof("Installing %s with %s...\n", pkg, pkgManager)
+	output, err := runPackageCommand(cmd)
+	if err != nil && recoverInstall(pkgManager, pkg, string(output)) {
+		log.Infof("Retrying %s...\n", pkg)
+		output, err = runPackageCommand(cmd)
+	}
 	if err != nil {
 		return fmt.Errorf("failed installing with %s: %s\nOutput: %s", pkgManager, err, string(output))
 	}
 	return nil
 }
 
+// mapPackageName returns the name a package has for the package manager
+func mapPackageName(pkg, pkgManager string) string {
+	switch pkgManager {
+	case "apt":
+		return mapToDebianPackage(pkg)
+	case "pacman":
+		return mapToArchPackage(pkg)
+	case "choco", "scoop":
+		return mapToWindowsPackage(pkg, pkgManager)
+	case "msys2":
+		return mapToMSYS2Package(pkg)
+	default:
+		return pkg
+	}
+}
+
 func mapToDebianPackage(pkg string) string {
 	// Map common package names to Debian/Ubuntu equivalents
 	debianMap := map[string]string{
@@ -693,6 +922,8 @@ func mapToWindowsPackage(pkg string, pkgManager string) string {
 			"libomp":               "mingw",
 			"libgomp":              "mingw",
 			"libgomp-dev":          "mingw",
+			"flex":                 "winflexbison3",
+			"bison":                "winflexbison3",
 		}
 	case "winget":
 		pkgMap = map[string]string{
@@ -712,6 +943,8 @@ func mapToWindowsPackage(pkg string, pkgManager string) string {
 			"libomp":               "MSYS2.MSYS2",
 			"libgomp":              "MSYS2.MSYS2",
 			"libgomp-dev":          "MSYS2.MSYS2",
+			"flex":                 "WinFlexBison.win_flex_bison",
+			"bison":                "WinFlexBison.win_flex_bison",
 		}
 	case "scoop":
 		pkgMap = map[string]string{
@@ -728,6 +961,8 @@ func mapToWindowsPackage(pkg string, pkgManager string) string {
 			"libomp":      "gcc",
 			"libgomp":     "gcc",
 			"libgomp-dev": "gcc",
+			"flex":        "winflexbison",
+			"bison":       "winflexbison",
 		}
 	default:
 		return pkg
@@ -798,21 +1033,21 @@ func checkWindowsPackageCompatibility(pkg string) {
 		}
 	}
 
-	fmt.Printf("\n⚠️  WARNING: Windows Compatibility Issue Detected\n")
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
+	log.Infof("\n⚠️  WARNING: Windows Compatibility Issue Detected\n")
+	log.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
 	if issue.DisplayName != "" {
-		fmt.Printf("Package: %s (%s)\n", issue.PackageName, issue.DisplayName)
+		log.Infof("Package: %s (%s)\n", issue.PackageName, issue.DisplayName)
 	} else {
-		fmt.Printf("Package: %s\n", issue.PackageName)
+		log.Infof("Package: %s\n", issue.PackageName)
 	}
-	fmt.Printf("Issue: %s\n\n", issue.Issue)
-	fmt.Printf("💡 Suggestion:\n")
-	fmt.Printf("   %s\n\n", issue.Alternative)
+	log.Infof("Issue: %s\n\n", issue.Issue)
+	log.Infof("💡 Suggestion:\n")
+	log.Infof("   %s\n\n", issue.Alternative)
 	if issue.WorkaroundURL != "" {
-		fmt.Printf("📖 More Info:\n")
-		fmt.Printf("   %s\n", issue.WorkaroundURL)
+		log.Infof("📖 More Info:\n")
+		log.Infof("   %s\n", issue.WorkaroundURL)
 	}
-	fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
+	log.Infof("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
 }
 
 // shouldUseMSYS2Pacman checks if a package should be installed via MSYS2 pacman instead of winget
@@ -839,106 +1074,87 @@ func shouldUseMSYS2Pacman(pkg string) bool {
 	return false
 }
 
-// isMSYS2Installed checks if MSYS2 is installed on the system
-func isMSYS2Installed() bool {
-	// Check common MSYS2 installation paths
-	commonPaths := []string{
-		"C:\\msys64\\usr\\bin\\bash.exe",
-		"C:\\msys32\\usr\\bin\\bash.exe",
-	}
-
-	for _, path := range commonPaths {
-		if _, err := os.Stat(path); err == nil {
-			return true
-		}
-	}
-
-	return false
-}
-
-// getMSYS2BashPath returns the path to MSYS2 bash executable
-func getMSYS2BashPath() (string, error) {
-	commonPaths := []string{
-		"C:\\msys64\\usr\\bin\\bash.exe",
-		"C:\\msys32\\usr\\bin\\bash.exe",
-	}
-
-	for _, path := range commonPaths {
-		if _, err := os.Stat(path); err == nil {
-			return path, nil
-		}
-	}
-
-	return "", errors.New("MSYS2 bash not found in common locations")
-}
-
-// mapToMSYS2Package maps a generic package name to MSYS2 UCRT64 package name
+// mapToMSYS2Package maps a generic package name to a package of the MSYS2
+// environment (mingw-w64-ucrt-x86_64-curl, mingw-w64-clang-aarch64-curl)
 func mapToMSYS2Package(pkg string) string {
-	// Map to mingw-w64-ucrt-x86_64-* packages for UCRT64 environment
 	msys2Map := map[string]string{
-		"jansson":              "mingw-w64-ucrt-x86_64-jansson",
-		"libjansson-dev":       "mingw-w64-ucrt-x86_64-jansson",
-		"curl":                 "mingw-w64-ucrt-x86_64-curl",
-		"libcurl4-openssl-dev": "mingw-w64-ucrt-x86_64-curl",
-		"sqlite3":              "mingw-w64-ucrt-x86_64-sqlite3",
-		"libsqlite3-dev":       "mingw-w64-ucrt-x86_64-sqlite3",
-		"openssl":              "mingw-w64-ucrt-x86_64-openssl",
-		"libssl-dev":           "mingw-w64-ucrt-x86_64-openssl",
-		"ncurses":              "mingw-w64-ucrt-x86_64-ncurses",
-		"libncurses-dev":       "mingw-w64-ucrt-x86_64-ncurses",
-		"openmp":               "mingw-w64-ucrt-x86_64-openmp",
-		"libomp":               "mingw-w64-ucrt-x86_64-openmp",
-		"libgomp":              "mingw-w64-ucrt-x86_64-openmp",
-		"libgomp-dev":          "mingw-w64-ucrt-x86_64-openmp",
+		"jansson":              "jansson",
+		"libjansson-dev":       "jansson",
+		"curl":                 "curl",
+		"libcurl4-openssl-dev": "curl",
+		"sqlite3":              "sqlite3",
+		"libsqlite3-dev":       "sqlite3",
+		"openssl":              "openssl",
+		"libssl-dev":           "openssl",
+		"ncurses":              "ncurses",
+		"libncurses-dev":       "ncurses",
+		"openmp":               "openmp",
+		"libomp":               "openmp",
+		"libgomp":              "openmp",
+		"libgomp-dev":          "openmp",
 	}
 
+	if strings.HasPrefix(pkg, "mingw-w64-") {
+		return pkg
+	}
 	if msys2Pkg, exists := msys2Map[pkg]; exists {
-		return msys2Pkg
+		pkg = msys2Pkg
 	}
-
-	// If not in map, try adding the prefix
-	return "mingw-w64-ucrt-x86_64-" + pkg
+	return platform.MSYS2PackagePrefixes[platform.MSYS2Environment()] + pkg
 }
 
 // installViaMSYS2Pacman installs packages using MSYS2's pacman
 func installViaMSYS2Pacman(packages []string) error {
-	bashPath, err := getMSYS2BashPath()
-	if err != nil {
-		return err
-	}
-
 	// Map packages to MSYS2 names
 	msys2Packages := []string{}
 	for _, pkg := range packages {
 		msys2Packages = append(msys2Packages, mapToMSYS2Package(pkg))
 	}
 
-	// Build pacman command
-	pacmanCmd := "pacman -S --noconfirm " + strings.Join(msys2Packages, " ")
-
-	fmt.Printf("\nRunning MSYS2 pacman: %s\n", pacmanCmd)
+	bashPath, args, err := platform.MSYS2Pacman(append([]string{"-S", "--noconfirm", "--needed"}, msys2Packages...)...)
+	if err != nil {
+		return err
+	}
+	log.Debugf("\nRunning MSYS2 pacman: %s\n", args[1])
 
-	// Execute via bash -lc to get proper environment
-	cmd := exec.Command(bashPath, "-lc", pacmanCmd)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd := util.SystemCommand(bashPath, args...)
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
 
 	return cmd.Run()
 }
 
-// runCommand executes a command with arguments
+// runCommand runs a command that changes the system, discarding its output
 func runCommand(command string, args ...string) error {
-	cmd := exec.Command(command, args...)
+	cmd := util.SystemCommand(command, args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	return cmd.Run()
 }
 
-// runWingetInstall runs winget install with better error handling
-func runWingetInstall(packageID string) error {
-	cmd := exec.Command("winget", "install", "--id", packageID, "--accept-package-agreements", "--accept-source-agreements")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// wingetArm64Packages are winget packages with native ARM64 installers
+var wingetArm64Packages = map[string]bool{
+	"Git.Git":            true,
+	"Kitware.CMake":      true,
+	"Python.Python.3.11": true,
+	"OpenJS.NodeJS":      true,
+}
+
+// runWingetInstall runs winget install with better error handling, pinned
+// to version unless it's empty
+func runWingetInstall(packageID, version string) error {
+	args := []string{"install", "--id", packageID, "--accept-package-agreements", "--accept-source-agreements"}
+	if version != "" {
+		args = append(args, "--version", version)
+	}
+	// winget falls back to emulated x64 installers on ARM64; ask for the
+	// native build of packages known to ship one
+	if platform.DetectArch() == "arm64" && wingetArm64Packages[packageID] {
+		args = append(args, "--architecture", "arm64")
+	}
+	cmd := util.SystemCommand("winget", args...)
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
 
 	err := cmd.Run()
 
@@ -989,94 +1205,249 @@ func isWingetNonCriticalError(err error) bool {
 
 // DownloadResource downloads a file from a URL to a local path
 func DownloadResource(url, localPath string) error {
-	// Normalize path separators for the current OS
-	normalizedPath := filepath.Clean(localPath)
+	_, err := downloadResource(config.Resource{URL: url, Path: localPath}, nil, "")
+	return err
+}
+
+// downloadResource downloads a resource, prefixing its messages with prefix
+// and counting its bytes towards batch if it is not nil. An existing file is
+// kept unless RefreshResources is set, in which case it is replaced only if
+// the server has a newer version; replaced reports whether it was.
+func downloadResource(resource config.Resource, batch *batchProgress, prefix string) (replaced bool, err error) {
+	url := resource.URL
+
+	// Normalize path separators for the current OS; archives that are
+	// extracted are downloaded next to their destination
+	normalizedPath := resource.DownloadPath()
 
 	// Create the directory if it doesn't exist
 	dir := filepath.Dir(normalizedPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		return false, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("invalid resource URL %s: %w", url, err)
 	}
 
 	// Check if file already exists
-	if _, err := os.Stat(normalizedPath); err == nil {
-		fmt.Printf("Resource already exists: %s (skipping download)\n", normalizedPath)
-		return nil
+	_, statErr := os.Stat(normalizedPath)
+	exists := statErr == nil
+	if exists {
+		if !RefreshResources {
+			log.Infof("%sResource already exists: %s (skipping download)\n", prefix, normalizedPath)
+			return false, nil
+		}
+		if v, ok := loadValidators(normalizedPath, url); ok {
+			setConditionalHeaders(req, v)
+			log.Infof("%sChecking %s for changes\n", prefix, url)
+		} else {
+			log.Infof("%sRe-downloading %s -> %s (no cache validators saved)\n", prefix, url, normalizedPath)
+		}
+	} else {
+		log.Infof("%sDownloading %s -> %s\n", prefix, url, normalizedPath)
 	}
 
-	fmt.Printf("Downloading %s -> %s\n", url, normalizedPath)
+	// Time out waiting for the server, but not while a large file streams
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	client := &http.Client{Transport: transport}
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
+	// Retry network errors, server errors and interrupted transfers
+	var notModified bool
+	var progress *downloadProgress
+	err = Retry.Do(func() error {
+		resp, err := client.Do(req)
+		if err != nil {
+			return util.Retryable(fmt.Errorf("failed to download %s: %w", url, err))
+		}
+		defer resp.Body.Close()
 
-	// Make the HTTP request
-	resp, err := client.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to download %s: %w", url, err)
-	}
-	defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotModified && exists {
+			notModified = true
+			return nil
+		}
 
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download %s: HTTP %d %s", url, resp.StatusCode, resp.Status)
-	}
+		// Check response status
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("failed to download %s: HTTP %d %s", url, resp.StatusCode, resp.Status)
+			if Retry.RetryStatusCode(resp.StatusCode) {
+				return util.Retryable(err)
+			}
+			return err
+		}
 
-	// Create the output file
-	file, err := os.Create(normalizedPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", normalizedPath, err)
-	}
-	defer file.Close()
+		// Download into a temporary file so an interrupted download, or one
+		// that doesn't match the configured checksum, never replaces the
+		// resource
+		progress = newDownloadProgress(resp.ContentLength, batch)
+		err = util.WriteAtomic(normalizedPath, 0644, func(w io.Writer) error {
+			h := sha256.New()
+			if _, err := io.Copy(io.MultiWriter(w, h), io.TeeReader(resp.Body, progress)); err != nil {
+				return util.Retryable(err)
+			}
+			if expected := strings.ToLower(resource.SHA256); expected != "" && hex.EncodeToString(h.Sum(nil)) != expected {
+				return fmt.Errorf("sha256 mismatch: expected %s, got %x", expected, h.Sum(nil))
+			}
+			return nil
+		})
+		progress.finish()
+		if err != nil {
+			return fmt.Errorf("failed to write file %s: %w", normalizedPath, err)
+		}
 
-	// Copy the response body to file
-	_, err = io.Copy(file, resp.Body)
+		if err := saveValidators(normalizedPath, url, resp.Header); err != nil {
+			log.Warnf("Failed to save cache validators for %s: %v\n", normalizedPath, err)
+		}
+		return nil
+	})
 	if err != nil {
-		// Clean up partial file on error
-		os.Remove(normalizedPath)
-		return fmt.Errorf("failed to write file %s: %w", normalizedPath, err)
+		return false, err
+	}
+	if notModified {
+		log.Infof("%sResource up to date: %s\n", prefix, normalizedPath)
+		return false, nil
 	}
 
-	fmt.Printf("Successfully downloaded: %s\n", normalizedPath)
+	log.Infof("%sSuccessfully downloaded: %s (%s in %s)\n", prefix, normalizedPath, formatBytes(progress.done),
+		time.Since(progress.start).Round(time.Millisecond))
+	return exists, nil
+}
+
+// extractResource unpacks a verified archive into the resource's path if it
+// was just downloaded or hasn't been extracted yet
+func extractResource(resource config.Resource, downloaded bool) error {
+	if _, err := os.Stat(resource.Path); err == nil && !downloaded {
+		return nil
+	}
+	if err := extractArchive(resource.DownloadPath(), filepath.Clean(resource.Path), resource.StripComponents); err != nil {
+		return err
+	}
+	log.Infof("Extracted %s into %s\n", filepath.Base(resource.URL), resource.Path)
 	return nil
 }
 
 // InstallResources downloads external resources defined in the config
+// and verifies any configured checksums and signatures
 func InstallResources(cfg *config.Config) error {
+	return installResources(cfg, nil)
+}
+
+// InstallResourcesLocked downloads external resources like InstallResources and
+// additionally verifies them against, and records them in, the lockfile at lockPath
+func InstallResourcesLocked(cfg *config.Config, lockPath string) error {
+	lf, err := lock.Load(lockPath)
+	if err != nil {
+		return err
+	}
+
+	// Record the resources that were verified even if others failed
+	err = installResources(cfg, lf)
+	if len(lf.Resources) > 0 {
+		if saveErr := lf.Save(lockPath); err == nil {
+			err = saveErr
+		}
+	}
+	return err
+}
+
+// DownloadJobs is the number of resources downloaded at once; 0 uses
+// download_jobs from catalyst.yml, or defaultDownloadJobs
+var DownloadJobs int
+
+const defaultDownloadJobs = 4
+
+// installResources downloads resources in parallel and verifies them,
+// recording checksums in lf if it is not nil. Every resource is attempted;
+// the failures are reported together at the end.
+func installResources(cfg *config.Config, lf *lock.Lockfile) error {
 	osType := runtime.GOOS
 
 	// Get resources using the config method
 	resources := cfg.GetResources()
 
 	if len(resources) == 0 {
-		fmt.Println("No external resources to download.")
+		log.Info("No external resources to download.")
 		return nil
 	}
 
-	fmt.Printf("Downloading %d external resources for %s...\n", len(resources), osType)
-	fmt.Println()
+	jobs := DownloadJobs
+	if jobs <= 0 {
+		jobs = cfg.DownloadJobs
+	}
+	if jobs <= 0 {
+		jobs = defaultDownloadJobs
+	}
 
-	// Download each resource
+	log.Infof("Downloading %d external resources for %s...\n", len(resources), osType)
+	log.Info()
+
+	// Download with a bounded number of workers, remembering which files
+	// were already there so a failed verification only removes new ones
+	errs := make([]error, len(resources))
+	existed := make([]bool, len(resources))
+	replaced := make([]bool, len(resources))
+	batch := newBatchProgress(len(resources))
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
 	for i, resource := range resources {
-		fmt.Printf("[%d/%d] ", i+1, len(resources))
-
+		prefix := fmt.Sprintf("[%d/%d] ", i+1, len(resources))
 		if resource.URL == "" {
-			fmt.Printf("Skipping resource with empty URL\n")
+			log.Infof("%sSkipping resource with empty URL\n", prefix)
 			continue
 		}
-
 		if resource.Path == "" {
-			fmt.Printf("Skipping resource %s with empty path\n", resource.URL)
+			log.Infof("%sSkipping resource %s with empty path\n", prefix, resource.URL)
 			continue
 		}
 
-		if err := DownloadResource(resource.URL, resource.Path); err != nil {
-			return fmt.Errorf("failed to download resource %s: %w", resource.URL, err)
+		_, statErr := os.Stat(resource.DownloadPath())
+		existed[i] = statErr == nil
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			var err error
+			if replaced[i], err = downloadResource(resource, batch, prefix); err != nil {
+				errs[i] = fmt.Errorf("failed to download: %w", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Verify in config order so the lockfile doesn't depend on which
+	// download finished first
+	var failures []string
+	for i, resource := range resources {
+		if resource.URL == "" || resource.Path == "" {
+			continue
+		}
+		if errs[i] == nil {
+			// A refresh replaces a resource on purpose, so its new checksum
+			// replaces the locked one
+			if err := verifyResource(resource, lf, replaced[i]); err != nil {
+				// Don't leave an unverified download behind
+				if !existed[i] {
+					os.Remove(resource.DownloadPath())
+				}
+				errs[i] = fmt.Errorf("failed to verify: %w", err)
+			} else if resource.Extract {
+				errs[i] = extractResource(resource, replaced[i] || !existed[i])
+			}
+		}
+		if errs[i] != nil {
+			failures = append(failures, fmt.Sprintf("  %s: %v", resource.URL, errs[i]))
 		}
 	}
 
-	fmt.Println()
-	fmt.Println("External resources downloaded successfully!")
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d resources failed:\n%s", len(failures), len(resources), strings.Join(failures, "\n"))
+	}
+
+	log.Info()
+	log.Info("External resources downloaded successfully!")
 	return nil
 }
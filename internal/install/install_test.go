@@ -1,20 +1,67 @@
 package install
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/lock"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 )
 
+// TestMain keeps the tests from seeing the package database that catalyst
+// pkgdb update cached in the user's home directory
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "catalyst-home-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Setenv("HOME", home)
+	os.Setenv("USERPROFILE", home)
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
+// resourceServer serves small files to download, so tests don't need the network
+func resourceServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/uuid":
+			fmt.Fprintln(w, `{"uuid": "8a4e1c1e-2f6b-4f57-9d3e-5b0f2c7a9e11"}`)
+		case "/text":
+			fmt.Fprint(w, "catalyst is awesome")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
 func TestDownloadResource(t *testing.T) {
 	// Create a temporary directory for testing
 	tempDir := t.TempDir()
 	testFile := filepath.Join(tempDir, "test", "file.txt")
 
-	// Test downloading a simple text file (using a reliable public URL)
-	url := "https://httpbin.org/uuid"
+	// Test downloading a simple text file
+	url := resourceServer(t).URL + "/uuid"
 
 	err := DownloadResource(url, testFile)
 	if err != nil {
@@ -57,7 +104,7 @@ func TestDownloadResourceAlreadyExists(t *testing.T) {
 	}
 
 	// Try to download to the same location
-	url := "https://httpbin.org/uuid"
+	url := resourceServer(t).URL + "/uuid"
 	err = DownloadResource(url, testFile)
 	if err != nil {
 		t.Fatalf("Failed to handle existing file: %v", err)
@@ -81,14 +128,15 @@ func TestInstallResources(t *testing.T) {
 	tempDir := t.TempDir()
 
 	// Create a test config with resources
+	server := resourceServer(t)
 	cfg := &config.Config{
 		Resources: []config.Resource{
 			{
-				URL:  "https://httpbin.org/uuid",
+				URL:  server.URL + "/uuid",
 				Path: filepath.Join(tempDir, "resource1.json"),
 			},
 			{
-				URL:  "https://httpbin.org/base64/SFRUUEJJTiBpcyBhd2Vzb21l",
+				URL:  server.URL + "/text",
 				Path: filepath.Join(tempDir, "data", "resource2.txt"),
 			},
 		},
@@ -116,7 +164,7 @@ func TestDownloadResourceWindowsPaths(t *testing.T) {
 	testPath := filepath.Join(tempDir, "windows\\mixed/path\\test.json")
 
 	// Test downloading with mixed separators
-	url := "https://httpbin.org/uuid"
+	url := resourceServer(t).URL + "/uuid"
 	err := DownloadResource(url, testPath)
 	if err != nil {
 		t.Fatalf("Failed to download resource with Windows path: %v", err)
@@ -130,3 +178,556 @@ func TestDownloadResourceWindowsPaths(t *testing.T) {
 
 	t.Logf("Successfully downloaded file with Windows-style path: %s", normalizedPath)
 }
+
+func TestInstallResourcesChecksum(t *testing.T) {
+	content := "catalyst resource"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	lockPath := filepath.Join(tempDir, "catalyst.lock")
+	sum := sha256.Sum256([]byte(content))
+	expected := hex.EncodeToString(sum[:])
+
+	// Matching checksum is accepted and recorded in the lockfile
+	cfg := &config.Config{
+		Resources: []config.Resource{
+			{URL: server.URL + "/good", Path: filepath.Join(tempDir, "good.txt"), SHA256: expected},
+		},
+	}
+	if err := InstallResourcesLocked(cfg, lockPath); err != nil {
+		t.Fatalf("Expected matching checksum to pass: %v", err)
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("Expected lockfile to be written: %v", err)
+	}
+
+	// Mismatching checksum fails and the download is removed
+	badPath := filepath.Join(tempDir, "bad.txt")
+	cfg.Resources = []config.Resource{
+		{URL: server.URL + "/bad", Path: badPath, SHA256: "0000"},
+	}
+	if err := InstallResourcesLocked(cfg, lockPath); err == nil {
+		t.Fatal("Expected checksum mismatch to fail")
+	}
+	if _, err := os.Stat(badPath); !os.IsNotExist(err) {
+		t.Fatal("Expected unverified download to be removed")
+	}
+
+	// A locked resource whose content changed is rejected
+	if err := os.WriteFile(filepath.Join(tempDir, "good.txt"), []byte("tampered"), 0644); err != nil {
+		t.Fatalf("Failed to modify resource: %v", err)
+	}
+	cfg.Resources = []config.Resource{
+		{URL: server.URL + "/good", Path: filepath.Join(tempDir, "good.txt")},
+	}
+	if err := InstallResourcesLocked(cfg, lockPath); err == nil {
+		t.Fatal("Expected changed content to be rejected by the lockfile")
+	}
+}
+
+func TestInstallResourcesReportsAllFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/missing") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{DownloadJobs: 2}
+	for _, name := range []string{"a", "missing1", "b", "missing2", "c"} {
+		cfg.Resources = append(cfg.Resources, config.Resource{URL: server.URL + "/" + name, Path: filepath.Join(tempDir, name)})
+	}
+
+	err := InstallResources(cfg)
+	if err == nil {
+		t.Fatal("Expected the missing resources to fail")
+	}
+	if !strings.Contains(err.Error(), "2 of 5 resources failed") || !strings.Contains(err.Error(), "/missing1") || !strings.Contains(err.Error(), "/missing2") {
+		t.Errorf("Expected both failures to be reported, got: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if data, err := os.ReadFile(filepath.Join(tempDir, name)); err != nil || string(data) != "/"+name {
+			t.Errorf("Expected %s to be downloaded despite the failures: %q, %v", name, data, err)
+		}
+	}
+}
+
+func TestDownloadResourceRetries(t *testing.T) {
+	previous := Retry
+	Retry.Backoff = 0
+	defer func() { Retry = previous }()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "flaky.txt")
+	if err := DownloadResource(server.URL, path); err != nil {
+		t.Fatalf("Expected the download to succeed on the third attempt: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+}
+
+func TestRefreshResources(t *testing.T) {
+	content, etag := "v1", `"1"`
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "data.txt")
+	lockPath := filepath.Join(tempDir, "catalyst.lock")
+	cfg := &config.Config{Resources: []config.Resource{{URL: server.URL + "/data", Path: path}}}
+	if err := InstallResourcesLocked(cfg, lockPath); err != nil {
+		t.Fatal(err)
+	}
+
+	RefreshResources = true
+	defer func() { RefreshResources = false }()
+
+	// Unchanged on the server: a conditional request and no download
+	if err := InstallResourcesLocked(cfg, lockPath); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected one request per install, got %d", requests)
+	}
+
+	// Changed on the server: replaced, and the lockfile follows
+	content, etag = "v2", `"2"`
+	if err := InstallResourcesLocked(cfg, lockPath); err != nil {
+		t.Fatalf("Expected a refreshed resource to update the lockfile: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "v2" {
+		t.Errorf("Expected the resource to be re-downloaded, got %q", data)
+	}
+}
+
+func TestInstallResourcesExtract(t *testing.T) {
+	files := map[string]string{"sdk-1.0/include/sdk.h": "#define SDK 1\n", "sdk-1.0/lib/libsdk.a": "!<arch>\n"}
+
+	var tgz bytes.Buffer
+	gz := gzip.NewWriter(&tgz)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(body)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(body))
+	}
+	tw.Close()
+	gz.Close()
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	for name, body := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(body))
+	}
+	zw.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sdk.tar.gz":
+			w.Write(tgz.Bytes())
+		case "/sdk.zip":
+			w.Write(zipped.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	cfg := &config.Config{Resources: []config.Resource{
+		{URL: server.URL + "/sdk.tar.gz", Path: filepath.Join(tempDir, "vendor/sdk"), Extract: true, StripComponents: 1},
+		{URL: server.URL + "/sdk.zip", Path: filepath.Join(tempDir, "vendor/sdk-zip"), Extract: true},
+	}}
+	if err := InstallResourcesLocked(cfg, filepath.Join(tempDir, "catalyst.lock")); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		"vendor/sdk/include/sdk.h":             "#define SDK 1\n",
+		"vendor/sdk/lib/libsdk.a":              "!<arch>\n",
+		"vendor/sdk-zip/sdk-1.0/include/sdk.h": "#define SDK 1\n",
+	} {
+		if data, err := os.ReadFile(filepath.Join(tempDir, path)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", path, data, err, want)
+		}
+	}
+
+	// A second install keeps the extracted tree and re-extracts a deleted one
+	os.RemoveAll(filepath.Join(tempDir, "vendor/sdk"))
+	if err := InstallResourcesLocked(cfg, filepath.Join(tempDir, "catalyst.lock")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "vendor/sdk/include/sdk.h")); err != nil {
+		t.Errorf("Expected the archive to be extracted again: %v", err)
+	}
+}
+
+func TestEntryPathRejectsEscapes(t *testing.T) {
+	for _, name := range []string{"../evil", "a/../../evil", "/etc/passwd"} {
+		if path, ok := entryPath("/tmp/out", name, 0); ok && !strings.HasPrefix(path, "/tmp/out/") {
+			t.Errorf("entryPath(%q) = %q escapes the destination", name, path)
+		}
+	}
+	if _, ok := entryPath("/tmp/out", "sdk-1.0/", 1); ok {
+		t.Error("Expected the stripped top-level directory to be skipped")
+	}
+}
+
+func TestRepoListed(t *testing.T) {
+	tests := []struct {
+		manager string
+		repo    pkgdb.Repo
+		output  string
+		want    bool
+	}{
+		{"apt", pkgdb.Repo{Name: "ppa:ubuntu-toolchain-r/test"}, " 500 https://ppa.launchpadcontent.net/ubuntu-toolchain-r/test/ubuntu noble/main amd64 Packages\n", true},
+		{"apt", pkgdb.Repo{Name: "universe"}, " 500 http://archive.ubuntu.com/ubuntu noble/universe amd64 Packages\n", true},
+		{"apt", pkgdb.Repo{Name: "universe"}, " 500 http://archive.ubuntu.com/ubuntu noble/main amd64 Packages\n", false},
+		{"dnf", pkgdb.Repo{Name: "epel"}, "repo id     repo name\nappstream   AlmaLinux 9 - AppStream\nepel        Extra Packages for Enterprise Linux 9\n", true},
+		{"dnf", pkgdb.Repo{Name: "epel"}, "repo id     repo name\nappstream   AlmaLinux 9 - AppStream\n", false},
+		{"brew", pkgdb.Repo{Name: "osx-cross/avr"}, "homebrew/cask\nosx-cross/avr\n", true},
+		{"scoop", pkgdb.Repo{Name: "extras"}, "Name   Source\n----   ------\nmain   https://github.com/ScoopInstaller/Main\n", false},
+		{"choco", pkgdb.Repo{Name: "chocolatey"}, "chocolatey|https://community.chocolatey.org/api/v2/|True|||0|False|False|False\n", false},
+		{"choco", pkgdb.Repo{Name: "chocolatey"}, "chocolatey|https://community.chocolatey.org/api/v2/|False|||0|False|False|False\n", true},
+	}
+	for _, tt := range tests {
+		if got := repoListed(tt.manager, tt.repo, tt.output); got != tt.want {
+			t.Errorf("repoListed(%s, %s) = %v, want %v", tt.manager, tt.repo.Name, got, tt.want)
+		}
+	}
+}
+
+func TestPinnedPackageSpecs(t *testing.T) {
+	lf := &lock.Lockfile{}
+	lf.SetPackage(lock.LockedPackage{Name: "zlib1g-dev", Platform: "linux", Version: "1:1.3.dfsg-3.1"})
+	lf.SetPackage(lock.LockedPackage{Name: "sqlite", Platform: "windows", Version: "3.45.0"})
+	lf.SetPackage(lock.LockedPackage{Name: "m", Platform: "linux", Version: "2.39"})
+
+	tests := []struct {
+		osName, pkgManager string
+		deps, want         []string
+	}{
+		{"linux", "apt", []string{"zlib1g-dev", "libpng-dev", "m"}, []string{"zlib1g-dev=1:1.3.dfsg-3.1", "libpng-dev", "m"}},
+		{"windows", "choco", []string{"sqlite", "curl"}, []string{"sqlite@3.45.0", "curl"}},
+		{"linux", "pacman", []string{"zlib1g-dev"}, []string{"zlib1g-dev"}},
+		{"windows", "vcpkg", []string{"sqlite"}, []string{"sqlite"}},
+	}
+	for _, tt := range tests {
+		got := pinnedPackageSpecs(tt.deps, lf, tt.osName, tt.pkgManager)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: specs = %v, want %v", tt.pkgManager, got, tt.want)
+		}
+	}
+
+	formulae := map[string]bool{"openssl@3": true, "python@3.12": true, "python@3.11": true}
+	formulaTests := []struct {
+		name, version, want string
+		ok                  bool
+	}{
+		{"openssl", "3.3.2_1", "openssl@3", true},
+		{"python", "3.12.4", "python@3.12", true},
+		{"sqlite", "3.46.0", "", false},
+	}
+	for _, tt := range formulaTests {
+		if got, ok := versionedFormula(tt.name, tt.version, formulae); got != tt.want || ok != tt.ok {
+			t.Errorf("versionedFormula(%s, %s) = %q, %v, want %q", tt.name, tt.version, got, ok, tt.want)
+		}
+	}
+
+	if name, version := splitPin("Kitware.CMake@3.28.1"); name != "Kitware.CMake" || version != "3.28.1" {
+		t.Errorf("splitPin = %q, %q", name, version)
+	}
+}
+
+func TestConanfileFor(t *testing.T) {
+	content, unmapped := conanfileFor([]string{"libssl-dev", "openssl", "zlib1g-dev", "m", "libfoo-dev"})
+	want := "[requires]\nopenssl/3.3.2\nzlib/1.3.1\n\n[generators]\nPkgConfigDeps\n"
+	if !strings.HasSuffix(content, want) {
+		t.Errorf("conanfile =\n%s\nwant it to end with\n%s", content, want)
+	}
+	if len(unmapped) != 1 || unmapped[0] != "libfoo-dev" {
+		t.Errorf("unmapped = %v, want [libfoo-dev]", unmapped)
+	}
+
+	t.Chdir(t.TempDir())
+	if useConan, err := UsesConan(&config.Config{}); err != nil || useConan {
+		t.Errorf("UsesConan without a conanfile = %v, %v", useConan, err)
+	}
+	os.WriteFile("conanfile.txt", []byte("[requires]\nzlib/1.3.1\n"), 0644)
+	if useConan, err := UsesConan(&config.Config{}); err != nil || !useConan {
+		t.Errorf("UsesConan with a conanfile = %v, %v", useConan, err)
+	}
+	if useConan, _ := UsesConan(&config.Config{Provider: config.ProviderSystem}); useConan {
+		t.Error("provider: system should ignore the conanfile")
+	}
+	if _, err := UsesConan(&config.Config{Provider: "nix"}); err == nil {
+		t.Error("expected an unknown provider to be an error")
+	}
+}
+
+func TestDiagnoseInstallFailure(t *testing.T) {
+	tests := []struct {
+		manager, output string
+		problem         string // Substring of the diagnosed problem; empty if none matches
+	}{
+		{"apt", "E: Could not get lock /var/lib/dpkg/lock-frontend. It is held by process 1234 (unattended-upgr)", "another process"},
+		{"apt", "Err:1 http://ppa.launchpad.net/x/ubuntu jammy Release\n  404  Not Found [IP: 1.2.3.4 80]", "out of date"},
+		{"apt", "E: Unable to correct problems, you have held broken packages.", "held or broken"},
+		{"pacman", "error: failed to init transaction (unable to lock database)", "locked"},
+		{"brew", "Error: homebrew-core is a shallow clone.", "shallow"},
+		{"dnf", "Error: Unable to find a match: libfoo-devel", "libfoo-devel"},
+		{"pacman", "E: Could not get lock", ""}, // Signatures only apply to their own manager
+		{"apt", "E: Sub-process /usr/bin/dpkg returned an error code (1)", ""},
+	}
+	for _, tt := range tests {
+		r, ok := DiagnoseInstallFailure(tt.manager, "libfoo-devel", tt.output)
+		if tt.problem == "" {
+			if ok {
+				t.Errorf("%s %q: expected no diagnosis, got %q", tt.manager, tt.output, r.Problem)
+			}
+			continue
+		}
+		if !ok || !strings.Contains(r.Problem, tt.problem) || len(r.Steps) == 0 {
+			t.Errorf("%s %q: got %+v, want a problem containing %q", tt.manager, tt.output, r, tt.problem)
+		}
+	}
+}
+
+func TestInstallPackagesRecovers(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fakes apt with shell scripts")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	fixed := filepath.Join(t.TempDir(), "fixed")
+	scripts := map[string]string{
+		"sudo":    "exec \"$@\"\n",
+		"dpkg":    ": > " + fixed + "\n",
+		"apt-get": "[ -e " + fixed + " ] && exit 0\necho \"E: dpkg was interrupted, you must manually run 'sudo dpkg --configure -a' to correct the problem.\"\nexit 100\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var recorded bytes.Buffer
+	previous, confirmFix := util.Exec, ConfirmFix
+	util.Exec = util.NewRecordingExecutor(&recorded, util.SystemExecutor{})
+	defer func() { util.Exec, ConfirmFix = previous, confirmFix }()
+
+	// Declined, the failure is reported with the package manager's output
+	ConfirmFix = nil
+	err := installPackages([]string{"zlib1g-dev", "libssl-dev"}, "")
+	if err == nil || !strings.Contains(err.Error(), "dpkg was interrupted") {
+		t.Errorf("installPackages() = %v, want the apt-get output", err)
+	}
+
+	// Confirmed, the fix runs and the install is retried
+	recorded.Reset()
+	ConfirmFix = func(Recovery) bool { return true }
+	if err := installPackages([]string{"zlib1g-dev", "libssl-dev"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	var ran []string
+	for _, line := range strings.Split(strings.TrimSpace(recorded.String()), "\n") {
+		_, command, _ := strings.Cut(line, "$ ")
+		status := line[strings.Index(line, "(")+1 : strings.Index(line, ",")]
+		ran = append(ran, status+": "+command)
+	}
+	want := []string{
+		"exit 100: sudo apt-get install -y zlib1g-dev libssl-dev",
+		"exit 0: sudo dpkg --configure -a",
+		"exit 0: sudo apt-get install -y zlib1g-dev libssl-dev",
+	}
+	if strings.Join(ran, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ran:\n%s\nwant:\n%s", strings.Join(ran, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMapToMSYS2Package(t *testing.T) {
+	tests := []struct {
+		msystem string
+		pkg     string
+		want    string
+	}{
+		{"UCRT64", "libcurl4-openssl-dev", "mingw-w64-ucrt-x86_64-curl"},
+		{"CLANGARM64", "libcurl4-openssl-dev", "mingw-w64-clang-aarch64-curl"},
+		{"CLANGARM64", "libgomp", "mingw-w64-clang-aarch64-openmp"},
+		{"MINGW64", "zlib", "mingw-w64-x86_64-zlib"},
+		{"CLANG64", "mingw-w64-clang-x86_64-zlib", "mingw-w64-clang-x86_64-zlib"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("MSYSTEM", tt.msystem)
+		if got := mapToMSYS2Package(tt.pkg); got != tt.want {
+			t.Errorf("mapToMSYS2Package(%q) with MSYSTEM=%s = %q, want %q", tt.pkg, tt.msystem, got, tt.want)
+		}
+	}
+}
+
+func TestVcpkgTripletFor(t *testing.T) {
+	tests := []struct {
+		goos, goarch string
+		static       bool
+		want         string
+	}{
+		{"windows", "amd64", false, "x64-windows"},
+		{"windows", "arm64", false, "arm64-windows"},
+		{"windows", "amd64", true, "x64-windows-static"},
+		{"linux", "amd64", true, "x64-linux"},
+		{"darwin", "arm64", false, "arm64-osx-dynamic"},
+	}
+
+	for _, tt := range tests {
+		if got := VcpkgTripletFor(tt.goos, tt.goarch, tt.static); got != tt.want {
+			t.Errorf("VcpkgTripletFor(%s, %s, %v) = %q, want %q", tt.goos, tt.goarch, tt.static, got, tt.want)
+		}
+	}
+
+	// The vcpkg_triplet setting is passed to vcpkg only
+	if args := tripletArgs("vcpkg", "arm64-osx"); strings.Join(args, " ") != "--triplet arm64-osx" {
+		t.Errorf("tripletArgs(vcpkg, arm64-osx) = %q", args)
+	}
+	if args := tripletArgs("vcpkg", ""); args != nil {
+		t.Errorf("tripletArgs(vcpkg) without a setting = %q, want vcpkg's default", args)
+	}
+	if args := tripletArgs("brew", "arm64-osx"); args != nil {
+		t.Errorf("tripletArgs(brew) = %q", args)
+	}
+}
+
+func TestDownloadProgressLine(t *testing.T) {
+	p := &downloadProgress{total: 10 << 20, done: 5 << 20, batch: &batchProgress{count: 3, done: 1, bytes: 6 << 20}}
+	want := "[==========>         ]  50%  5.0 MiB / 10.0 MiB  1.0 MiB/s  ETA 5s  (1 of 3 resources done, 6.0 MiB in total)"
+	if got := p.line(5 * time.Second); got != want {
+		t.Errorf("line = %q, want %q", got, want)
+	}
+
+	// Without a Content-Length only the size and speed are known
+	p = &downloadProgress{total: -1, done: 512}
+	if got, want := p.line(time.Second), "512 B  512 B/s"; got != want {
+		t.Errorf("line = %q, want %q", got, want)
+	}
+}
+
+func TestMissingToolPackages(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tools are shell scripts")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	if err := os.WriteFile(filepath.Join(bin, "cmake"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tools := []string{"cmake", "ninja", "bison", "flex", "gperf"}
+	got := missingToolPackages(tools, "winget")
+	want := []string{"Ninja-build.Ninja", "WinFlexBison.win_flex_bison", "gperf"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("missingToolPackages(winget) = %q, want %q", got, want)
+	}
+	if got := missingToolPackages([]string{"ninja", "windres"}, "apt"); strings.Join(got, " ") != "ninja-build binutils-mingw-w64-x86-64" {
+		t.Errorf("missingToolPackages(apt) = %q", got)
+	}
+}
+
+func TestOfferCompiler(t *testing.T) {
+	bin := t.TempDir()
+	var installed []string
+	compilerOptions = func() []CompilerOption {
+		return []CompilerOption{
+			{Name: "A", install: func() error { installed = append(installed, "A"); return nil }},
+			{Name: "B", install: func() error { installed = append(installed, "B"); return nil }, bin: func() string { return bin }},
+		}
+	}
+	chooseCompiler := ChooseCompiler
+	defer func() {
+		compilerOptions, ChooseCompiler, compilerOffered = hostCompilerOptions, chooseCompiler, false
+	}()
+	t.Setenv("PATH", "/usr/bin")
+
+	// Declining installs nothing
+	ChooseCompiler = nil
+	if ok, err := OfferCompiler(); ok || err != nil || len(installed) != 0 {
+		t.Fatalf("declined: got %v, %v, installed %q", ok, err, installed)
+	}
+
+	// It's only offered once
+	ChooseCompiler = func([]CompilerOption) (int, bool) { return 1, true }
+	if ok, _ := OfferCompiler(); ok {
+		t.Fatal("offered twice")
+	}
+
+	compilerOffered = false
+	if ok, err := OfferCompiler(); !ok || err != nil {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	if strings.Join(installed, " ") != "B" {
+		t.Errorf("installed %q, want B", installed)
+	}
+	if want := bin + string(os.PathListSeparator) + "/usr/bin"; os.Getenv("PATH") != want {
+		t.Errorf("PATH = %q, want %q", os.Getenv("PATH"), want)
+	}
+}
+
+func TestInstallToVcpkgPrefix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as vcpkg")
+	}
+	bin, prefix := t.TempDir(), t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("HOME", t.TempDir())
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "vcpkg"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// apt names are installed as the vcpkg ports of the same libraries
+	if err := installToVcpkgPrefix([]string{"libssl-dev", "zlib1g-dev"}, "apt", "x64-linux", prefix, map[string]bool{}); err != nil {
+		t.Fatal(err)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if fields := strings.Fields(string(args)); len(fields) < 2 || strings.Join(fields[len(fields)-2:], " ") != "openssl zlib" {
+		t.Errorf("vcpkg ran with %q, want the ports openssl and zlib", args)
+	}
+	if installed := readPrefixPackages(prefix); !installed["libssl-dev"] || !installed["zlib1g-dev"] {
+		t.Errorf("prefix packages = %v, want the apt names", installed)
+	}
+
+	// Names without a known port aren't passed to vcpkg as they are
+	os.Remove(argsFile)
+	err := installToVcpkgPrefix([]string{"zlib1g-dev", "libfoo-dev"}, "apt", "x64-linux", prefix, map[string]bool{})
+	if err == nil || !strings.Contains(err.Error(), "libfoo-dev") {
+		t.Errorf("installToVcpkgPrefix() = %v, want an error naming libfoo-dev", err)
+	}
+	if _, statErr := os.Stat(argsFile); statErr == nil {
+		t.Error("vcpkg ran with names it has no ports for")
+	}
+}
@@ -2,10 +2,13 @@ package install
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/Sabique-Islam/catalyst/internal/events"
+	"github.com/Sabique-Islam/catalyst/internal/log"
 	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 )
 
 // InstallationResult represents the result of a dependency installation
@@ -53,14 +56,20 @@ func (d *DependencyInstaller) InstallDependencies(packages []string) ([]Installa
 	// Setup package manager if needed
 	if err := platform.SetupPackageManager(d.PkgManager); err != nil {
 		if d.Verbose {
-			fmt.Printf("Warning: Package manager setup: %v\n", err)
+			log.Warnf("Package manager setup: %v\n", err)
 		}
 	}
 
+	// Add the repositories the packages live in before refreshing the
+	// package database, so it includes them
+	if err := ensureRepos(d.PkgManager, packages, d.DryRun); err != nil {
+		return results, err
+	}
+
 	// Update package manager database first
 	if err := d.updatePackageDatabase(); err != nil {
 		if d.Verbose {
-			fmt.Printf("Warning: Failed to update package database: %v\n", err)
+			log.Warnf("Failed to update package database: %v\n", err)
 		}
 	}
 
@@ -75,43 +84,43 @@ func (d *DependencyInstaller) InstallDependencies(packages []string) ([]Installa
 
 // updatePackageDatabase updates the package manager's database
 func (d *DependencyInstaller) updatePackageDatabase() error {
-	var cmd *exec.Cmd
-
-	switch d.PkgManager {
-	case "apt":
-		cmd = exec.Command("sudo", "apt", "update")
-	case "dnf":
-		cmd = exec.Command("sudo", "dnf", "makecache")
-	case "pacman":
-		cmd = exec.Command("sudo", "pacman", "-Sy")
-	case "brew":
-		cmd = exec.Command("brew", "update")
-	case "vcpkg":
-		// vcpkg doesn't need database updates
-		return nil
-	case "choco":
-		// Chocolatey updates automatically
-		return nil
-	default:
+	pm, ok := platform.LookupPackageManager(d.PkgManager)
+	if !ok {
 		return fmt.Errorf("unsupported package manager: %s", d.PkgManager)
 	}
+	cmd, err := pm.Update()
+	if err != nil || cmd == nil {
+		// A nil command means the manager updates its database itself
+		return err
+	}
 
 	if d.DryRun {
 		if d.Verbose {
-			fmt.Printf("DRY RUN: Would execute: %s\n", strings.Join(cmd.Args, " "))
+			log.Infof("DRY RUN: Would execute: %s\n", strings.Join(cmd.Args, " "))
 		}
 		return nil
 	}
 
 	if d.Verbose {
-		fmt.Printf("Updating package database: %s\n", strings.Join(cmd.Args, " "))
+		log.Infof("Updating package database: %s\n", strings.Join(cmd.Args, " "))
 	}
 
-	return cmd.Run()
+	_, err = runPackageCommand(cmd)
+	return err
 }
 
 // installPackage installs a single package
 func (d *DependencyInstaller) installPackage(pkg string) InstallationResult {
+	pkg = d.packageName(pkg)
+	events.Emit(events.Event{Kind: events.PackageInstallStarted, Name: pkg})
+	start := time.Now()
+	result := d.runInstall(pkg)
+	events.Emit(events.Event{Kind: events.PackageInstallFinished, Name: pkg, Duration: time.Since(start), Error: events.Err(result.Error)})
+	return result
+}
+
+// runInstall installs pkg unless it needs no installing
+func (d *DependencyInstaller) runInstall(pkg string) InstallationResult {
 	result := InstallationResult{
 		Package: pkg,
 	}
@@ -142,7 +151,7 @@ func (d *DependencyInstaller) installPackage(pkg string) InstallationResult {
 	// Execute or simulate
 	if d.DryRun {
 		if d.Verbose {
-			fmt.Printf("DRY RUN: Would execute: %s\n", strings.Join(cmd.Args, " "))
+			log.Infof("DRY RUN: Would execute: %s\n", strings.Join(cmd.Args, " "))
 		}
 		result.Success = true
 		result.Reason = "Dry run - would install"
@@ -151,11 +160,14 @@ func (d *DependencyInstaller) installPackage(pkg string) InstallationResult {
 
 	// Execute installation
 	if d.Verbose {
-		fmt.Printf("Installing %s: %s\n", pkg, strings.Join(cmd.Args, " "))
+		log.Infof("Installing %s: %s\n", pkg, strings.Join(cmd.Args, " "))
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := runPackageCommand(cmd)
 	if err != nil {
+		if r, ok := DiagnoseInstallFailure(d.PkgManager, pkg, string(output)); ok {
+			printRecovery(r)
+		}
 		result.Error = fmt.Errorf("installation failed: %w\nOutput: %s", err, string(output))
 		return result
 	}
@@ -171,24 +183,22 @@ func (d *DependencyInstaller) installPackage(pkg string) InstallationResult {
 	return result
 }
 
+// packageName returns the name pkg has for the package manager: MSYS2
+// packages carry the prefix of the environment they're installed into
+func (d *DependencyInstaller) packageName(pkg string) string {
+	if d.PkgManager == "msys2" && pkg != "" {
+		return mapToMSYS2Package(pkg)
+	}
+	return pkg
+}
+
 // getInstallCommand generates the appropriate install command for the package
-func (d *DependencyInstaller) getInstallCommand(pkg string) (*exec.Cmd, error) {
-	switch d.PkgManager {
-	case "apt":
-		return exec.Command("sudo", "apt", "install", "-y", pkg), nil
-	case "dnf":
-		return exec.Command("sudo", "dnf", "install", "-y", pkg), nil
-	case "pacman":
-		return exec.Command("sudo", "pacman", "-S", "--noconfirm", pkg), nil
-	case "brew":
-		return exec.Command("brew", "install", pkg), nil
-	case "vcpkg":
-		return exec.Command("vcpkg", "install", pkg), nil
-	case "choco":
-		return exec.Command("choco", "install", pkg, "-y"), nil
-	default:
+func (d *DependencyInstaller) getInstallCommand(pkg string) (*util.Cmd, error) {
+	pm, ok := platform.LookupPackageManager(d.PkgManager)
+	if !ok {
 		return nil, fmt.Errorf("unsupported package manager: %s", d.PkgManager)
 	}
+	return pm.Install(pkg)
 }
 
 // InstallBatch installs dependencies in batches for better performance
@@ -202,6 +212,7 @@ func (d *DependencyInstaller) InstallBatch(packages []string, batchSize int) ([]
 	// Filter out empty packages and already installed ones
 	var toInstall []string
 	for _, pkg := range packages {
+		pkg = d.packageName(pkg)
 		if pkg == "" {
 			allResults = append(allResults, InstallationResult{
 				Package: pkg,
@@ -259,16 +270,10 @@ func (d *DependencyInstaller) installBatch(packages []string) ([]InstallationRes
 	return results, nil
 }
 
-// supportsBatchInstall checks if the package manager supports batch installation
+// supportsBatchInstall checks if the package manager supports batch
+// installation; the others install one by one for better error handling
 func (d *DependencyInstaller) supportsBatchInstall() bool {
-	switch d.PkgManager {
-	case "apt", "dnf", "pacman", "brew":
-		return true
-	case "vcpkg", "choco":
-		return false // Install one by one for better error handling
-	default:
-		return false
-	}
+	return platform.SupportsBatchInstall(d.PkgManager)
 }
 
 // installMultiplePackages installs multiple packages in a single command
@@ -276,28 +281,19 @@ func (d *DependencyInstaller) installMultiplePackages(packages []string) ([]Inst
 	var results []InstallationResult
 
 	// Generate batch install command
-	var cmd *exec.Cmd
-	switch d.PkgManager {
-	case "apt":
-		args := append([]string{"apt", "install", "-y"}, packages...)
-		cmd = exec.Command("sudo", args...)
-	case "dnf":
-		args := append([]string{"dnf", "install", "-y"}, packages...)
-		cmd = exec.Command("sudo", args...)
-	case "pacman":
-		args := append([]string{"pacman", "-S", "--noconfirm"}, packages...)
-		cmd = exec.Command("sudo", args...)
-	case "brew":
-		args := append([]string{"install"}, packages...)
-		cmd = exec.Command("brew", args...)
-	default:
+	pm, ok := platform.LookupPackageManager(d.PkgManager)
+	if !ok {
 		return nil, fmt.Errorf("batch installation not supported for %s", d.PkgManager)
 	}
+	cmd, err := pm.Install(packages...)
+	if err != nil {
+		return nil, err
+	}
 
 	// Execute or simulate
 	if d.DryRun {
 		if d.Verbose {
-			fmt.Printf("DRY RUN: Would execute: %s\n", strings.Join(cmd.Args, " "))
+			log.Infof("DRY RUN: Would execute: %s\n", strings.Join(cmd.Args, " "))
 		}
 		for _, pkg := range packages {
 			results = append(results, InstallationResult{
@@ -311,10 +307,14 @@ func (d *DependencyInstaller) installMultiplePackages(packages []string) ([]Inst
 	}
 
 	if d.Verbose {
-		fmt.Printf("Installing packages: %s\n", strings.Join(cmd.Args, " "))
+		log.Infof("Installing packages: %s\n", strings.Join(cmd.Args, " "))
 	}
 
-	output, err := cmd.CombinedOutput()
+	for _, pkg := range packages {
+		events.Emit(events.Event{Kind: events.PackageInstallStarted, Name: pkg})
+	}
+	start := time.Now()
+	output, err := runPackageCommand(cmd)
 
 	// Check results for each package
 	for _, pkg := range packages {
@@ -331,6 +331,7 @@ func (d *DependencyInstaller) installMultiplePackages(packages []string) ([]Inst
 		} else {
 			result.Error = fmt.Errorf("batch installation reported success but package not found")
 		}
+		events.Emit(events.Event{Kind: events.PackageInstallFinished, Name: pkg, Duration: time.Since(start), Error: events.Err(result.Error)})
 
 		results = append(results, result)
 	}
@@ -341,7 +342,7 @@ func (d *DependencyInstaller) installMultiplePackages(packages []string) ([]Inst
 // PrintResults prints installation results in a user-friendly format
 func PrintResults(results []InstallationResult, verbose bool) {
 	if len(results) == 0 {
-		fmt.Println("No packages to install.")
+		log.Info("No packages to install.")
 		return
 	}
 
@@ -349,26 +350,26 @@ func PrintResults(results []InstallationResult, verbose bool) {
 	skipCount := 0
 	errorCount := 0
 
-	fmt.Printf("\nInstallation Results:\n")
-	fmt.Printf("====================\n")
+	log.Infof("\nInstallation Results:\n")
+	log.Infof("====================\n")
 
 	for _, result := range results {
 		if result.Success {
-			fmt.Printf("SUCCESS: %s - %s\n", result.Package, result.Reason)
+			log.Infof("SUCCESS: %s - %s\n", result.Package, result.Reason)
 			successCount++
 		} else if result.Skipped {
 			if verbose {
-				fmt.Printf("SKIPPED: %s - %s\n", result.Package, result.Reason)
+				log.Infof("SKIPPED: %s - %s\n", result.Package, result.Reason)
 			}
 			skipCount++
 		} else {
-			fmt.Printf("FAILED:  %s - %v\n", result.Package, result.Error)
+			log.Errorf("FAILED:  %s - %v\n", result.Package, result.Error)
 			if verbose && len(result.Commands) > 0 {
-				fmt.Printf("         Command: %s\n", strings.Join(result.Commands, " "))
+				log.Errorf("         Command: %s\n", strings.Join(result.Commands, " "))
 			}
 			errorCount++
 		}
 	}
 
-	fmt.Printf("\nSummary: %d succeeded, %d skipped, %d failed\n", successCount, skipCount, errorCount)
+	log.Infof("\nSummary: %d succeeded, %d skipped, %d failed\n", successCount, skipCount, errorCount)
 }
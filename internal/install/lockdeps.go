@@ -0,0 +1,225 @@
+package install
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Sabique-Islam/catalyst/internal/lock"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// checkFrozenPackages fails if the dependencies for a platform differ from the
+// packages recorded in the lockfile
+func checkFrozenPackages(deps []string, lf *lock.Lockfile, osName string) error {
+	locked := lf.PlatformPackages(osName)
+	inLock := make(map[string]bool)
+	for _, p := range locked {
+		inLock[p.Name] = true
+	}
+	inConfig := make(map[string]bool)
+	var added, removed []string
+	for _, dep := range deps {
+		inConfig[dep] = true
+		if !inLock[dep] {
+			added = append(added, dep)
+		}
+	}
+	for _, p := range locked {
+		if !inConfig[p.Name] {
+			removed = append(removed, p.Name)
+		}
+	}
+
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	var diff []string
+	if len(added) > 0 {
+		diff = append(diff, "not locked: "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		diff = append(diff, "no longer in catalyst.yml: "+strings.Join(removed, ", "))
+	}
+	return fmt.Errorf("--frozen: dependencies differ from %s (%s)", lock.DefaultPath, strings.Join(diff, "; "))
+}
+
+// pinnedPackageSpecs returns the install arguments for the dependencies,
+// pinned to their locked versions where the package manager supports it.
+// Locked dependencies it can't pin are reported.
+func pinnedPackageSpecs(deps []string, lf *lock.Lockfile, osName, pkgManager string) []string {
+	specs := make([]string, 0, len(deps))
+	var unpinned []string
+	for _, dep := range deps {
+		locked, ok := lf.Package(osName, dep)
+		if !ok || locked.Version == "" || isSystemLibrary(dep) {
+			specs = append(specs, dep)
+			continue
+		}
+		switch pkgManager {
+		case "apt", "zypper":
+			specs = append(specs, dep+"="+locked.Version)
+		case "dnf", "yum":
+			specs = append(specs, dep+"-"+locked.Version)
+		case "brew":
+			specs = append(specs, brewPinnedFormula(dep, locked.Version))
+		case "choco", "winget", "scoop":
+			// Install passes the version to choco and winget; scoop takes
+			// name@version itself
+			specs = append(specs, dep+"@"+locked.Version)
+		default:
+			// pacman, MacPorts and MSYS2 install the current version, and
+			// vcpkg the version of the baseline of its checkout
+			specs = append(specs, dep)
+			unpinned = append(unpinned, dep+" "+locked.Version)
+		}
+	}
+	if len(unpinned) > 0 {
+		log.Warnf("%s can't install the versions locked in %s; installing its current versions of: %s\n", pkgManager, lock.DefaultPath, strings.Join(unpinned, ", "))
+	}
+	return specs
+}
+
+// brewFormulae are the names of the formulae Homebrew has, listed once per
+// run with brew formulae rather than asking about each locked dependency
+var brewFormulae = sync.OnceValue(func() map[string]bool {
+	formulae := make(map[string]bool)
+	output, err := util.ParsedCommand("brew", "formulae").Output()
+	if err != nil {
+		log.Debugf("brew formulae failed: %v\n", err)
+		return formulae
+	}
+	for _, name := range strings.Fields(string(output)) {
+		formulae[name] = true
+	}
+	return formulae
+})
+
+// brewKegOnly reports whether Homebrew keeps a formula out of its prefix,
+// where builds wouldn't find it. Like other versioned formulae, most
+// name@version formulae are.
+func brewKegOnly(formula string) bool {
+	output, err := util.ParsedCommand("brew", "info", "--json=v2", "--formula", formula).Output()
+	if err != nil {
+		return true
+	}
+	var info struct {
+		Formulae []struct {
+			KegOnly bool `json:"keg_only"`
+		} `json:"formulae"`
+	}
+	if err := json.Unmarshal(output, &info); err != nil || len(info.Formulae) == 0 {
+		return true
+	}
+	return info.Formulae[0].KegOnly
+}
+
+// brewPinnedFormula returns the versioned formula (name@major.minor or
+// name@major) that provides a locked Homebrew version, or name when the
+// locked version is already installed, or Homebrew has no versioned formula
+// for it or only a keg-only one, and the current version is installed
+// instead
+func brewPinnedFormula(name, version string) string {
+	// Already a versioned formula such as openssl@3
+	if strings.Contains(name, "@") {
+		return name
+	}
+	if installed, ok := platform.InstalledVersion(name, "brew"); ok && installed == version {
+		return name
+	}
+	formula, ok := versionedFormula(name, version, brewFormulae())
+	switch {
+	case !ok:
+		log.Warnf("Homebrew has no versioned formula for %s %s; installing the current version\n", name, version)
+	case brewKegOnly(formula):
+		log.Warnf("%s is keg-only, so builds wouldn't find it; installing the current version of %s instead of %s\n", formula, name, version)
+	default:
+		return formula
+	}
+	return name
+}
+
+// versionedFormula returns the formula of formulae providing version of
+// name: name@major.minor, or name@major
+func versionedFormula(name, version string, formulae map[string]bool) (string, bool) {
+	version, _, _ = strings.Cut(version, "_") // Bottle revision
+	parts := strings.Split(version, ".")
+	for n := min(len(parts), 2); n >= 1; n-- {
+		if formula := name + "@" + strings.Join(parts[:n], "."); formulae[formula] {
+			return formula, true
+		}
+	}
+	return "", false
+}
+
+// splitPin splits a name@version spec from pinnedPackageSpecs for managers
+// that take the version as a separate argument
+func splitPin(spec string) (name, version string) {
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		return spec[:i], spec[i+1:]
+	}
+	return spec, ""
+}
+
+// unavailablePins lists the locked versions of deps that the package manager
+// can no longer install, with the versions it has instead. Managers that
+// can't be asked report nothing.
+func unavailablePins(deps []string, lf *lock.Lockfile, osName, pkgManager string) []string {
+	var missing []string
+	for _, dep := range deps {
+		locked, ok := lf.Package(osName, dep)
+		if !ok || locked.Version == "" || isSystemLibrary(dep) {
+			continue
+		}
+		available, ok := platform.AvailableVersions(dep, pkgManager)
+		if !ok || slices.Contains(available, locked.Version) {
+			continue
+		}
+		if len(available) == 0 {
+			missing = append(missing, fmt.Sprintf("%s %s (no versions available)", dep, locked.Version))
+		} else {
+			missing = append(missing, fmt.Sprintf("%s %s (available: %s)", dep, locked.Version, strings.Join(available, ", ")))
+		}
+	}
+	return missing
+}
+
+// lockInstalledPackages records the installed dependencies and their versions.
+// With frozen set the lock is only checked: a version different from the locked
+// one is an error.
+func lockInstalledPackages(deps []string, lf *lock.Lockfile, osName, pkgManager string, withVersions, frozen bool) error {
+	var pkgs []lock.LockedPackage
+	var mismatched []string
+
+	for _, dep := range deps {
+		entry, _ := lf.Package(osName, dep)
+		entry.Name, entry.Platform = dep, osName
+
+		if withVersions && !isSystemLibrary(dep) {
+			if version, ok := platform.InstalledVersion(dep, pkgManager); ok {
+				if frozen && entry.Version != "" && version != entry.Version {
+					mismatched = append(mismatched, fmt.Sprintf("%s (locked %s, installed %s)", dep, entry.Version, version))
+				}
+				entry.Version = version
+			}
+		}
+		pkgs = append(pkgs, entry)
+	}
+
+	if frozen {
+		if len(mismatched) > 0 {
+			return fmt.Errorf("--frozen: installed versions differ from %s: %s", lock.DefaultPath, strings.Join(mismatched, ", "))
+		}
+		return nil
+	}
+
+	lf.SetPlatformPackages(osName, pkgs)
+	return lf.Save(lock.DefaultPath)
+}
@@ -0,0 +1,287 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// standardDirs are searched by the compiler and linker by default
+var standardDirs = []string{
+	"/usr/include", "/usr/local/include",
+	"/lib", "/lib64", "/usr/lib", "/usr/lib64", "/usr/local/lib",
+}
+
+// DiscoverPackageFlags finds where installed packages put their headers and
+// libraries and returns -I/-L flags for directories the compiler does not
+// search by default. Uses the package manager's file list (dpkg, rpm, pacman,
+// MacPorts), brew --prefix, the vcpkg installed tree of the vcpkg_triplet
+// setting or the MSYS2 prefix.
+func DiscoverPackageFlags(dependencies []string, vcpkgTripletSetting string) []string {
+	var includeDirs, libDirs []string
+	addDir := func(dirs *[]string, dir string) {
+		if dir == "" || isStandardDir(dir) || containsDir(*dirs, dir) {
+			return
+		}
+		*dirs = append(*dirs, dir)
+	}
+
+	triplet := vcpkgTriplet(vcpkgTripletSetting)
+	for _, pkg := range dependencies {
+		inc, lib := discoverPackageDirs(pkg, triplet)
+		for _, dir := range inc {
+			addDir(&includeDirs, dir)
+		}
+		for _, dir := range lib {
+			addDir(&libDirs, dir)
+		}
+	}
+
+	// MSYS2 packages all land in the active environment prefix
+	if runtime.GOOS == "windows" {
+		for _, pkg := range dependencies {
+			if shouldUseMSYS2Pacman(pkg) {
+				if prefix := platform.MSYS2Prefix(); prefix != "" {
+					addDir(&includeDirs, filepath.Join(prefix, "include"))
+					addDir(&libDirs, filepath.Join(prefix, "lib"))
+				}
+				break
+			}
+		}
+	}
+
+	var flags []string
+	for _, dir := range includeDirs {
+		flags = append(flags, "-I"+dir)
+	}
+	for _, dir := range libDirs {
+		flags = append(flags, "-L"+dir)
+	}
+	return flags
+}
+
+// discoverPackageDirs returns the include and library directories of one installed package
+func discoverPackageDirs(pkg, triplet string) ([]string, []string) {
+	// vcpkg installs are found on every platform when VCPKG_ROOT is set
+	if inc, lib := vcpkgPackageDirs(pkg, triplet); len(inc)+len(lib) > 0 {
+		return inc, lib
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		// MacPorts lists each port's files, like the Linux package managers
+		if platform.MacOSPackageManager() == "port" {
+			out, err := util.ParsedCommand("port", "-q", "contents", pkg).Output()
+			if err != nil {
+				return nil, nil
+			}
+			return dirsFromFileList(splitLines(string(out)))
+		}
+
+		prefix := platform.BrewFormulaPrefix(pkg)
+		if prefix == "" {
+			return nil, nil
+		}
+		var inc, lib []string
+		if isDir(filepath.Join(prefix, "include")) {
+			inc = append(inc, filepath.Join(prefix, "include"))
+		}
+		if isDir(filepath.Join(prefix, "lib")) {
+			lib = append(lib, filepath.Join(prefix, "lib"))
+		}
+		return inc, lib
+
+	case "linux":
+		files := packageFileList(pkg)
+		return dirsFromFileList(files)
+	}
+
+	return nil, nil
+}
+
+// packageFileList lists the files installed by a Linux package
+func packageFileList(pkg string) []string {
+	pkgMgr, err := platform.DetectPackageManager("linux")
+	if err != nil {
+		return nil
+	}
+
+	var cmd *util.Cmd
+	switch pkgMgr {
+	case "apt":
+		cmd = util.ParsedCommand("dpkg", "-L", pkg)
+	case "dnf", "yum", "zypper":
+		cmd = util.ParsedCommand("rpm", "-ql", pkg)
+	case "pacman":
+		cmd = util.ParsedCommand("pacman", "-Qlq", pkg)
+	default:
+		return nil
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+// dirsFromFileList derives include and library directories from a package file list.
+// Besides each include root, directories that headers are nested in are added when
+// the headers are meant to be included without the directory (e.g.
+// /usr/include/libxml2 for libxml/parser.h, /usr/include/glib-2.0 for glib.h).
+func dirsFromFileList(files []string) ([]string, []string) {
+	var inc, lib []string
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file))
+		switch ext {
+		case ".h", ".hpp", ".hh", ".hxx":
+			idx := strings.LastIndex(file, "/include/")
+			if idx < 0 {
+				continue
+			}
+			root := file[:idx+len("/include")]
+			inc = appendDir(inc, root)
+
+			parts := strings.Split(file[len(root)+1:], "/")
+			if len(parts) >= 3 || (len(parts) == 2 && strings.ContainsAny(parts[0], "0123456789")) {
+				inc = appendDir(inc, filepath.Join(root, parts[0]))
+			}
+		case ".so", ".a", ".dylib", ".lib":
+			lib = appendDir(lib, filepath.Dir(file))
+		}
+	}
+	return inc, lib
+}
+
+// vcpkgPackageDirs looks a package up in the vcpkg installed tree of triplet
+func vcpkgPackageDirs(pkg, triplet string) ([]string, []string) {
+	root := platform.VcpkgRoot()
+	if root == "" {
+		return nil, nil
+	}
+
+	installed := filepath.Join(root, "installed", triplet)
+	if !isDir(filepath.Join(installed, "share", pkg)) {
+		return nil, nil
+	}
+	return []string{filepath.Join(installed, "include")}, []string{filepath.Join(installed, "lib")}
+}
+
+// vcpkgTriplet returns the triplet vcpkg installs for with setting, the
+// vcpkg_triplet of catalyst.yml: a triplet, "static" or "dynamic" for the
+// host's, or "" for VCPKG_DEFAULT_TRIPLET, then the host's default triplet
+func vcpkgTriplet(setting string) string {
+	switch setting {
+	case "":
+	case "static", "dynamic":
+		return VcpkgTripletFor(runtime.GOOS, platform.DetectArch(), setting == "static")
+	default:
+		return setting
+	}
+	if triplet := os.Getenv("VCPKG_DEFAULT_TRIPLET"); triplet != "" {
+		return triplet
+	}
+	return VcpkgTripletFor(runtime.GOOS, platform.DetectArch(), runtime.GOOS != "windows")
+}
+
+// VcpkgTripletFor returns vcpkg's built-in triplet for an OS and GOARCH
+// architecture (arm64-windows on ARM laptops, x64-windows otherwise). vcpkg
+// links statically by default except on Windows.
+func VcpkgTripletFor(goos, goarch string, static bool) string {
+	var arch string
+	switch goarch {
+	case "arm64":
+		arch = "arm64"
+	case "386":
+		arch = "x86"
+	case "arm":
+		arch = "arm"
+	default:
+		arch = "x64"
+	}
+
+	var triplet string
+	switch goos {
+	case "darwin":
+		triplet = arch + "-osx"
+	case "windows":
+		triplet = arch + "-windows"
+	default:
+		triplet = arch + "-linux"
+	}
+
+	if goos == "windows" && static {
+		return triplet + "-static"
+	}
+	if goos != "windows" && !static {
+		return triplet + "-dynamic"
+	}
+	return triplet
+}
+
+// isStandardDir reports whether dir is searched by the toolchain by default
+func isStandardDir(dir string) bool {
+	dir = filepath.Clean(dir)
+	for _, std := range standardDirs {
+		if dir == std {
+			return true
+		}
+	}
+	// Debian multiarch directories (/usr/lib/x86_64-linux-gnu)
+	parent := filepath.Dir(dir)
+	return (parent == "/usr/lib" || parent == "/lib") && strings.Contains(filepath.Base(dir), "-linux-")
+}
+
+// appendDir appends dir if it is not already present
+func appendDir(dirs []string, dir string) []string {
+	if containsDir(dirs, dir) {
+		return dirs
+	}
+	return append(dirs, dir)
+}
+
+// containsDir checks if a directory list contains dir
+func containsDir(dirs []string, dir string) bool {
+	for _, d := range dirs {
+		if d == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolchainPath returns the directories that hold the programs and DLLs of
+// the project's dependencies, for adding to PATH: the bin directories of
+// the isolated prefix, the vcpkg installed tree and the MSYS2 environment
+func ToolchainPath(cfg *config.Config) []string {
+	var candidates []string
+	if cfg.Isolated {
+		if prefix, err := filepath.Abs(PrefixDir()); err == nil {
+			for _, root := range []string{"", "usr", "usr/local"} {
+				candidates = append(candidates, filepath.Join(prefix, root, "bin"))
+			}
+		}
+	}
+	if root := platform.VcpkgRoot(); root != "" {
+		candidates = append(candidates, root, filepath.Join(root, "installed", vcpkgTriplet(cfg.VcpkgTriplet), "bin"))
+	}
+	if runtime.GOOS == "windows" {
+		if prefix := platform.MSYS2Prefix(); prefix != "" {
+			candidates = append(candidates, filepath.Join(prefix, "bin"))
+		}
+	}
+
+	var dirs []string
+	for _, dir := range candidates {
+		if isDir(dir) && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
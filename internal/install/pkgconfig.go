@@ -0,0 +1,56 @@
+package install
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// pkgConfigFlags asks pkg-config for the compiler and linker flags of each
+// dependency it knows. Its paths follow the real install prefix (e.g.
+// /opt/homebrew on Apple Silicon), so they are preferred over the hardcoded
+// link map. Dependencies pkg-config doesn't know are returned in rest.
+func pkgConfigFlags(dependencies []string) (flags []string, rest []string) {
+	if runtime.GOOS == "windows" {
+		return nil, dependencies
+	}
+	if _, err := exec.LookPath("pkg-config"); err != nil {
+		return nil, dependencies
+	}
+
+	for _, dep := range dependencies {
+		module := pkgConfigModule(dep)
+		if module == "" {
+			rest = append(rest, dep)
+			continue
+		}
+
+		output, err := util.Command("pkg-config", "--cflags", "--libs", module).Output()
+		if err != nil {
+			rest = append(rest, dep)
+			continue
+		}
+		flags = appendLinkFlags(flags, strings.Fields(string(output)))
+	}
+	return flags, rest
+}
+
+// pkgConfigModule returns the pkg-config module installed for a dependency:
+// the module of the known library the package provides, or a module named
+// after the package itself (sqlite3, zlib, glib-2.0)
+func pkgConfigModule(dep string) string {
+	candidates := []string{dep}
+	if module := analyzer.PkgConfigForPackage(dep); module != "" {
+		candidates = append([]string{module}, candidates...)
+	}
+
+	for _, module := range candidates {
+		if util.Command("pkg-config", "--exists", module).Run() == nil {
+			return module
+		}
+	}
+	return ""
+}
@@ -0,0 +1,274 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// PrefixDir returns the directory isolated dependencies are installed into:
+// .catalyst/prefix in the project or the build root
+func PrefixDir() string {
+	return util.StateDir("prefix")
+}
+
+// InstallToPrefix installs dependencies into a project-local prefix instead of
+// system-wide. On Linux the distro's packages are downloaded and extracted
+// into the prefix without touching the system; elsewhere vcpkg installs them.
+func InstallToPrefix(dependencies []string, prefix, vcpkgTripletSetting string) error {
+	if len(dependencies) == 0 {
+		log.Info("No dependencies to install.")
+		return nil
+	}
+
+	absPrefix, err := filepath.Abs(prefix)
+	if err != nil {
+		return fmt.Errorf("failed to resolve prefix %s: %w", prefix, err)
+	}
+	if err := os.MkdirAll(absPrefix, 0755); err != nil {
+		return fmt.Errorf("failed to create prefix directory: %w", err)
+	}
+
+	// Skip packages already extracted into the prefix
+	installed := readPrefixPackages(absPrefix)
+	var missing []string
+	for _, dep := range dependencies {
+		if !installed[dep] {
+			missing = append(missing, dep)
+		}
+	}
+	if len(missing) == 0 {
+		log.Infof("Dependencies already installed in %s\n", prefix)
+		return nil
+	}
+	dependencies = missing
+
+	log.Infof("Installing into project prefix: %s\n", prefix)
+
+	// The dependencies are named for the package manager of this machine,
+	// so its packages are extracted where catalyst knows how; elsewhere
+	// vcpkg installs them, under the names of its ports
+	pkgMgr, detectErr := platform.DetectPackageManager(runtime.GOOS)
+	if runtime.GOOS != "linux" || !slices.Contains(extractableManagers, pkgMgr) {
+		if _, err := exec.LookPath("vcpkg"); err == nil {
+			return installToVcpkgPrefix(dependencies, pkgMgr, vcpkgTriplet(vcpkgTripletSetting), absPrefix, installed)
+		}
+		if detectErr != nil {
+			return detectErr
+		}
+		return fmt.Errorf("isolated installs are not supported with %s - install vcpkg to use a project prefix", pkgMgr)
+	}
+
+	// Packages are downloaded into a scratch directory and unpacked into the prefix
+	downloadDir, err := util.MkdirTemp("prefix-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(downloadDir)
+
+	log.Infof("Extracting %s packages into the prefix\n", pkgMgr)
+
+	var pattern string
+	switch pkgMgr {
+	case "apt":
+		pattern = "*.deb"
+		cmd := util.Command("apt-get", append([]string{"download"}, dependencies...)...)
+		cmd.Dir = downloadDir
+		cmd.Stdout = log.Stdout()
+		cmd.Stderr = log.Stderr()
+		err = cmd.Run()
+	case "dnf", "yum":
+		pattern = "*.rpm"
+		if pkgMgr == "yum" {
+			err = runCommandVerbose("yumdownloader", append([]string{"--destdir", downloadDir}, dependencies...)...)
+		} else {
+			err = runCommandVerbose("dnf", append([]string{"download", "--destdir", downloadDir}, dependencies...)...)
+		}
+	case "pacman":
+		pattern = "*.pkg.tar.*"
+		args := append([]string{"pacman", "-Sw", "--noconfirm", "--cachedir", downloadDir}, dependencies...)
+		err = runCommandVerbose("sudo", args...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed downloading packages with %s: %w", pkgMgr, err)
+	}
+
+	archives, _ := filepath.Glob(filepath.Join(downloadDir, pattern))
+	if len(archives) == 0 {
+		return errors.New("no packages were downloaded")
+	}
+
+	for _, archive := range archives {
+		if strings.HasSuffix(archive, ".sig") {
+			continue
+		}
+		log.Infof("  Extracting %s\n", filepath.Base(archive))
+		if err := extractPackage(archive, absPrefix); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", filepath.Base(archive), err)
+		}
+	}
+
+	log.Info("Note: only the listed packages are extracted - list their library dependencies too if they are not installed system-wide")
+	return writePrefixPackages(absPrefix, installed, dependencies)
+}
+
+// extractableManagers are the Linux package managers whose packages are
+// downloaded and extracted into the prefix
+var extractableManagers = []string{"apt", "dnf", "yum", "pacman"}
+
+// installToVcpkgPrefix installs dependencies named for pkgMgr into the
+// prefix with vcpkg for triplet, translating the names to vcpkg ports
+func installToVcpkgPrefix(dependencies []string, pkgMgr, triplet, absPrefix string, installed map[string]bool) error {
+	var ports, unknown []string
+	for _, dep := range dependencies {
+		port := dep
+		// Without a package manager the names can only be vcpkg's
+		if pkgMgr != "vcpkg" && pkgMgr != "" {
+			var ok bool
+			if port, ok = pkgdb.TranslateBetween(dep, pkgMgr, "vcpkg"); !ok || port == "" {
+				unknown = append(unknown, dep)
+				continue
+			}
+		}
+		if !slices.Contains(ports, port) {
+			ports = append(ports, port)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("no vcpkg port is known for %s (%s packages) - list the vcpkg ports under platforms.%s.dependencies for isolated installs",
+			strings.Join(unknown, ", "), pkgMgr, runtime.GOOS)
+	}
+
+	log.Info("Using package manager: vcpkg")
+	args := append([]string{"install", "--triplet", triplet, "--x-install-root=" + filepath.Join(absPrefix, "vcpkg")}, ports...)
+	if err := runCommandVerbose("vcpkg", args...); err != nil {
+		return fmt.Errorf("vcpkg install failed: %w", err)
+	}
+	return writePrefixPackages(absPrefix, installed, dependencies)
+}
+
+// prefixPackagesFile lists the packages installed into a prefix, one per line
+const prefixPackagesFile = ".packages"
+
+// readPrefixPackages returns the set of packages already installed into the prefix
+func readPrefixPackages(prefix string) map[string]bool {
+	installed := make(map[string]bool)
+	data, err := os.ReadFile(filepath.Join(prefix, prefixPackagesFile))
+	if err != nil {
+		return installed
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			installed[line] = true
+		}
+	}
+	return installed
+}
+
+// writePrefixPackages records newly installed packages in the prefix
+func writePrefixPackages(prefix string, installed map[string]bool, added []string) error {
+	for _, pkg := range added {
+		installed[pkg] = true
+	}
+	var pkgs []string
+	for pkg := range installed {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+
+	data := strings.Join(pkgs, "\n") + "\n"
+	if err := util.WriteFileAtomic(filepath.Join(prefix, prefixPackagesFile), []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to record installed packages: %w", err)
+	}
+	return nil
+}
+
+// extractPackage unpacks a .deb, .rpm or pacman archive into the prefix
+func extractPackage(archive, prefix string) error {
+	var cmd *util.Cmd
+	switch {
+	case strings.HasSuffix(archive, ".deb"):
+		cmd = util.Command("dpkg-deb", "-x", archive, prefix)
+	case strings.HasSuffix(archive, ".rpm"):
+		cmd = util.Command("sh", "-c", `rpm2cpio "$1" | cpio -idm --quiet`, "sh", archive)
+		cmd.Dir = prefix
+	default:
+		cmd = util.Command("tar", "-xf", archive, "-C", prefix, "--exclude=.PKGINFO", "--exclude=.BUILDINFO", "--exclude=.MTREE", "--exclude=.INSTALL")
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// PrefixFlags returns include, library and rpath flags for the directories
+// that exist in the prefix
+func PrefixFlags(prefix string) []string {
+	absPrefix, err := filepath.Abs(prefix)
+	if err != nil {
+		return nil
+	}
+
+	var roots []string
+	for _, root := range []string{"", "usr", "usr/local"} {
+		roots = append(roots, filepath.Join(absPrefix, root))
+	}
+	// vcpkg installs into <prefix>/vcpkg/<triplet>
+	triplets, _ := filepath.Glob(filepath.Join(absPrefix, "vcpkg", "*"))
+	for _, triplet := range triplets {
+		if filepath.Base(triplet) != "vcpkg" {
+			roots = append(roots, triplet)
+		}
+	}
+
+	var flags []string
+	for _, root := range roots {
+		if isDir(filepath.Join(root, "include")) {
+			flags = append(flags, "-I"+filepath.Join(root, "include"))
+		}
+
+		libDirs := []string{filepath.Join(root, "lib"), filepath.Join(root, "lib64")}
+		// Debian multiarch directories (lib/x86_64-linux-gnu)
+		multiarch, _ := filepath.Glob(filepath.Join(root, "lib", "*-linux-gnu*"))
+		libDirs = append(libDirs, multiarch...)
+
+		for _, dir := range libDirs {
+			if !isDir(dir) {
+				continue
+			}
+			flags = append(flags, "-L"+dir)
+			if runtime.GOOS != "windows" {
+				// Let the binary find shared libraries from the prefix at runtime
+				flags = append(flags, "-Wl,-rpath,"+dir)
+			}
+		}
+	}
+	return flags
+}
+
+// isDir reports whether path exists and is a directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// runCommandVerbose runs a command with its output attached to the terminal
+func runCommandVerbose(command string, args ...string) error {
+	cmd := util.Command(command, args...)
+	cmd.Stdout = log.Stdout()
+	cmd.Stderr = log.Stderr()
+	return cmd.Run()
+}
@@ -0,0 +1,163 @@
+package install
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+)
+
+// progressInterval limits how often the progress bar is redrawn
+const progressInterval = 100 * time.Millisecond
+
+// batchProgress tracks the resources queued by one install. While a single
+// download runs its own progress bar is shown; while several run in parallel
+// one line sums them up.
+type batchProgress struct {
+	mu     sync.Mutex
+	count  int // Number of resources queued
+	done   int // Number of resources finished
+	active []*downloadProgress
+	bytes  int64 // Bytes downloaded by every resource so far
+	start  time.Time
+	drawn  time.Time
+}
+
+func newBatchProgress(count int) *batchProgress {
+	return &batchProgress{count: count, start: time.Now()}
+}
+
+// downloadProgress is an io.Writer that counts the bytes of a download
+// streamed through an io.TeeReader and draws a progress bar for them
+type downloadProgress struct {
+	total int64 // Content-Length, or -1 if the server didn't send one
+	done  int64
+	start time.Time
+	drawn time.Time
+	batch *batchProgress
+}
+
+func newDownloadProgress(total int64, batch *batchProgress) *downloadProgress {
+	p := &downloadProgress{total: total, start: time.Now(), batch: batch}
+	if batch != nil {
+		batch.mu.Lock()
+		batch.active = append(batch.active, p)
+		batch.mu.Unlock()
+	}
+	return p
+}
+
+// Write counts b and redraws the progress at most every progressInterval
+func (p *downloadProgress) Write(b []byte) (int, error) {
+	if p.batch != nil {
+		p.batch.add(p, len(b))
+		return len(b), nil
+	}
+
+	p.done += int64(len(b))
+	if now := time.Now(); now.Sub(p.drawn) >= progressInterval {
+		p.drawn = now
+		log.Progress(p.line(now.Sub(p.start)))
+	}
+	return len(b), nil
+}
+
+// finish clears the progress bar and marks the download as done in its batch
+func (p *downloadProgress) finish() {
+	if p.batch == nil {
+		log.Progress("")
+		return
+	}
+
+	b := p.batch
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.done++
+	b.active = slices.DeleteFunc(b.active, func(q *downloadProgress) bool { return q == p })
+	log.Progress("")
+}
+
+// add counts n bytes of p and redraws the batch's progress
+func (b *batchProgress) add(p *downloadProgress, n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p.done += int64(n)
+	b.bytes += int64(n)
+
+	now := time.Now()
+	if now.Sub(b.drawn) < progressInterval {
+		return
+	}
+	b.drawn = now
+	if len(b.active) == 1 {
+		log.Progress(p.line(now.Sub(p.start)))
+	} else {
+		log.Progress(b.line(now.Sub(b.start)))
+	}
+}
+
+// line sums up the batch's parallel downloads after elapsed time, e.g.
+// "3 downloading  14.0 MiB  2.8 MiB/s  (1 of 5 resources done)"
+func (b *batchProgress) line(elapsed time.Duration) string {
+	var speed float64
+	if elapsed > 0 {
+		speed = float64(b.bytes) / elapsed.Seconds()
+	}
+	return fmt.Sprintf("%d downloading  %s  %s/s  (%d of %d resources done)",
+		len(b.active), formatBytes(b.bytes), formatBytes(int64(speed)), b.done, b.count)
+}
+
+// line formats the progress bar after elapsed time, e.g.
+// "[=====>    ] 52%  5.2 MiB / 10.0 MiB  1.3 MiB/s  ETA 4s"
+func (p *downloadProgress) line(elapsed time.Duration) string {
+	var speed float64
+	if elapsed > 0 {
+		speed = float64(p.done) / elapsed.Seconds()
+	}
+
+	var parts []string
+	if p.total > 0 {
+		fraction := min(float64(p.done)/float64(p.total), 1)
+		parts = append(parts,
+			fmt.Sprintf("%s %3.0f%%", progressBar(fraction, 20), fraction*100),
+			fmt.Sprintf("%s / %s", formatBytes(p.done), formatBytes(p.total)))
+	} else {
+		parts = append(parts, formatBytes(p.done))
+	}
+	parts = append(parts, formatBytes(int64(speed))+"/s")
+	if p.total > 0 && speed > 0 && p.done < p.total {
+		eta := time.Duration(float64(p.total-p.done) / speed * float64(time.Second))
+		parts = append(parts, "ETA "+eta.Round(time.Second).String())
+	}
+	if p.batch != nil && p.batch.count > 1 {
+		parts = append(parts, fmt.Sprintf("(%d of %d resources done, %s in total)",
+			p.batch.done, p.batch.count, formatBytes(p.batch.bytes)))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// progressBar draws fraction (0 to 1) as a bar width characters wide
+func progressBar(fraction float64, width int) string {
+	filled := int(fraction * float64(width))
+	if filled >= width {
+		return "[" + strings.Repeat("=", width) + "]"
+	}
+	return "[" + strings.Repeat("=", filled) + ">" + strings.Repeat(" ", width-filled-1) + "]"
+}
+
+// formatBytes formats n with a binary unit, e.g. "1.5 MiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,295 @@
+package install
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Recovery explains a known cause of a failed package install
+type Recovery struct {
+	Problem string     // What went wrong
+	Steps   []string   // What the user can do about it
+	Fix     [][]string // Safe commands catalyst can run, after confirmation, before retrying; nil if none
+}
+
+// failureSignature recognizes a failure in a package manager's output
+type failureSignature struct {
+	managers []string
+	pattern  *regexp.Regexp
+	recovery func(pkg string) Recovery
+}
+
+var failureSignatures = []failureSignature{
+	{
+		managers: []string{"apt"},
+		pattern:  regexp.MustCompile(`Could not get lock|Unable to acquire the dpkg frontend lock|Unable to lock the administration directory`),
+		recovery: func(string) Recovery {
+			return Recovery{
+				Problem: "another process (apt, unattended-upgrades or a software center) is using the package database",
+				Steps:   []string{"Wait for it to finish and run catalyst install again", "Check what holds the lock: sudo lsof /var/lib/dpkg/lock-frontend"},
+			}
+		},
+	},
+	{
+		managers: []string{"apt"},
+		pattern:  regexp.MustCompile(`dpkg was interrupted`),
+		recovery: func(string) Recovery {
+			return Recovery{
+				Problem: "an earlier dpkg run was interrupted",
+				Steps:   []string{"sudo dpkg --configure -a"},
+				Fix:     [][]string{{"sudo", "dpkg", "--configure", "-a"}},
+			}
+		},
+	},
+	{
+		managers: []string{"apt"},
+		pattern:  regexp.MustCompile(`404\s+Not Found|Failed to fetch|does not have a Release file`),
+		recovery: func(string) Recovery {
+			return Recovery{
+				Problem: "the package lists are out of date or a configured repository no longer exists",
+				Steps: []string{"sudo apt-get update",
+					"If a repository still fails, remove or fix its entry in /etc/apt/sources.list.d/"},
+				Fix: [][]string{{"sudo", "apt-get", "update"}},
+			}
+		},
+	},
+	{
+		managers: []string{"apt"},
+		pattern:  regexp.MustCompile(`held broken packages|Held packages were changed`),
+		recovery: func(pkg string) Recovery {
+			return Recovery{
+				Problem: "held or broken packages conflict with " + pkg,
+				Steps: []string{"List held packages: apt-mark showhold",
+					"Unhold the ones blocking the install: sudo apt-mark unhold <package>",
+					"Repair broken dependencies: sudo apt-get install -f"},
+			}
+		},
+	},
+	{
+		managers: []string{"apt"},
+		pattern:  regexp.MustCompile(`Unable to locate package`),
+		recovery: func(pkg string) Recovery {
+			if !isDistro("ubuntu") {
+				return Recovery{
+					Problem: pkg + " is not in the configured repositories",
+					Steps:   []string{"sudo apt-get update", "Search for the right name: apt-cache search " + pkg},
+					Fix:     [][]string{{"sudo", "apt-get", "update"}},
+				}
+			}
+			return Recovery{
+				Problem: pkg + " was not found; on Ubuntu many libraries are in the universe repository",
+				Steps:   []string{"sudo add-apt-repository universe", "sudo apt-get update"},
+				Fix:     [][]string{{"sudo", "add-apt-repository", "-y", "universe"}, {"sudo", "apt-get", "update"}},
+			}
+		},
+	},
+	{
+		managers: []string{"dnf", "yum"},
+		pattern:  regexp.MustCompile(`No match for argument|Unable to find a match|No package .* available`),
+		recovery: func(pkg string) Recovery {
+			if !isDistro("rhel", "centos", "rocky", "almalinux", "ol") {
+				return Recovery{
+					Problem: pkg + " is not in the enabled repositories",
+					Steps:   []string{"Search for the right name: dnf search " + pkg},
+				}
+			}
+			return Recovery{
+				Problem: pkg + " was not found; on RHEL-compatible systems many libraries are in EPEL",
+				Steps:   []string{"sudo dnf install epel-release", "On RHEL, also enable CodeReady Builder: sudo dnf config-manager --set-enabled crb"},
+				Fix:     [][]string{{"sudo", "dnf", "install", "-y", "epel-release"}},
+			}
+		},
+	},
+	{
+		managers: []string{"dnf", "yum"},
+		pattern:  regexp.MustCompile(`Waiting for process with pid|Another app is currently holding the yum lock`),
+		recovery: func(string) Recovery {
+			return Recovery{
+				Problem: "another dnf/yum process is running",
+				Steps:   []string{"Wait for it to finish (often PackageKit or dnf-makecache) and run catalyst install again"},
+			}
+		},
+	},
+	{
+		managers: []string{"pacman"},
+		pattern:  regexp.MustCompile(`unable to lock database`),
+		recovery: func(string) Recovery {
+			return Recovery{
+				Problem: "the pacman database is locked",
+				Steps: []string{"Make sure no other pacman is running",
+					"If none is, remove the stale lock: sudo rm /var/lib/pacman/db.lck"},
+			}
+		},
+	},
+	{
+		managers: []string{"pacman"},
+		pattern:  regexp.MustCompile(`failed retrieving file|target not found`),
+		recovery: func(string) Recovery {
+			return Recovery{
+				Problem: "the package database is out of date",
+				Steps:   []string{"sudo pacman -Sy"},
+				Fix:     [][]string{{"sudo", "pacman", "-Sy", "--noconfirm"}},
+			}
+		},
+	},
+	{
+		managers: []string{"zypper"},
+		pattern:  regexp.MustCompile(`System management is locked`),
+		recovery: func(string) Recovery {
+			return Recovery{
+				Problem: "another zypper or YaST process is running",
+				Steps:   []string{"Wait for it to finish and run catalyst install again"},
+			}
+		},
+	},
+	{
+		managers: []string{"brew"},
+		pattern:  regexp.MustCompile(`shallow clone`),
+		recovery: func(string) Recovery {
+			return Recovery{
+				Problem: "a Homebrew tap is a shallow git clone",
+				Steps: []string{"brew untap homebrew/core homebrew/cask (Homebrew now installs from its API)",
+					"Or unshallow it: git -C \"$(brew --repo homebrew/core)\" fetch --unshallow"},
+			}
+		},
+	},
+	{
+		managers: []string{"brew"},
+		pattern:  regexp.MustCompile(`No available formula|No formulae found`),
+		recovery: func(pkg string) Recovery {
+			return Recovery{
+				Problem: "Homebrew doesn't know " + pkg,
+				Steps:   []string{"brew update", "Search for the right name: brew search " + pkg},
+				Fix:     [][]string{{"brew", "update"}},
+			}
+		},
+	},
+}
+
+// DiagnoseInstallFailure matches the output of a failed install of pkg
+// against known failure signatures
+func DiagnoseInstallFailure(pkgManager, pkg, output string) (Recovery, bool) {
+	for _, sig := range failureSignatures {
+		if slices.Contains(sig.managers, pkgManager) && sig.pattern.MatchString(output) {
+			return sig.recovery(pkg), true
+		}
+	}
+	return Recovery{}, false
+}
+
+// ConfirmFix asks whether to run a recovery's fix; nil never runs fixes.
+// By default it asks on the terminal and declines when stdin isn't one.
+var ConfirmFix = confirmOnTerminal
+
+func confirmOnTerminal(r Recovery) bool {
+	return askOnTerminal(fmt.Sprintf("Run %s and retry?", formatFix(r.Fix)))
+}
+
+// askOnTerminal asks a yes/no question on the terminal, defaulting to no and
+// answering no when stdin isn't a terminal
+func askOnTerminal(question string) bool {
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	fmt.Printf("%s (y/N): ", question)
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	response = strings.TrimSpace(response)
+	return response == "y" || response == "Y"
+}
+
+// printRecovery explains a diagnosed failure
+func printRecovery(r Recovery) {
+	log.Warnf("Install failed because %s\n", r.Problem)
+	log.Info("To fix it:")
+	for _, step := range r.Steps {
+		log.Infof("  • %s\n", step)
+	}
+}
+
+// recoverInstall explains a failed install of pkg and, if the failure has a
+// safe fix the user confirms, runs it. It reports whether the install
+// should be retried.
+func recoverInstall(pkgManager, pkg, output string) bool {
+	r, ok := DiagnoseInstallFailure(pkgManager, pkg, output)
+	if !ok {
+		return false
+	}
+	printRecovery(r)
+	if len(r.Fix) == 0 || ConfirmFix == nil || !ConfirmFix(r) {
+		return false
+	}
+
+	for _, args := range r.Fix {
+		cmd := util.SystemCommand(args[0], args[1:]...)
+		cmd.Stdout = log.Stdout()
+		cmd.Stderr = log.Stderr()
+		if err := cmd.Run(); err != nil {
+			log.Errorf("%s failed: %v\n", strings.Join(args, " "), err)
+			return false
+		}
+	}
+	return true
+}
+
+func formatFix(fix [][]string) string {
+	cmds := make([]string, len(fix))
+	for i, args := range fix {
+		cmds[i] = strings.Join(args, " ")
+	}
+	return strings.Join(cmds, " && ")
+}
+
+// isDistro reports whether /etc/os-release names one of ids, directly or
+// through ID_LIKE
+func isDistro(ids ...string) bool {
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || (key != "ID" && key != "ID_LIKE") {
+			continue
+		}
+		for _, id := range strings.Fields(strings.Trim(value, `"'`)) {
+			if slices.Contains(ids, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Retry is the retry policy for resource downloads and package manager
+// commands
+var Retry = util.DefaultRetry
+
+// transientFailure matches package manager output for failures that are
+// likely to pass on their own: network problems and a package database
+// briefly locked by another process
+var transientFailure = regexp.MustCompile(`Temporary failure resolving|Could not resolve|Connection timed out|Connection refused|Connection reset|Network is unreachable|Operation too slow|TLS handshake timeout|Curl error|Failed to download metadata|Could not get lock|Unable to acquire the dpkg frontend lock|unable to lock database|Waiting for process with pid|System management is locked`)
+
+// runPackageCommand runs a package manager command and returns its combined
+// output, running it again with backoff while it fails transiently
+func runPackageCommand(cmd *util.Cmd) ([]byte, error) {
+	var output []byte
+	err := Retry.Do(func() error {
+		// A command only runs once, so each attempt runs a copy
+		attempt := util.SystemCommand(cmd.Args[0], cmd.Args[1:]...)
+		attempt.Dir, attempt.Env, attempt.ChangesSystem = cmd.Dir, cmd.Env, cmd.ChangesSystem
+		var err error
+		output, err = attempt.CombinedOutput()
+		if err != nil && transientFailure.Match(output) {
+			return util.Retryable(fmt.Errorf("%s failed: %w", cmd.Args[0], err))
+		}
+		return err
+	})
+	return output, err
+}
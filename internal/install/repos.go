@@ -0,0 +1,173 @@
+package install
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// ConfirmRepo asks whether to add a repository with setup before installing
+// pkg from it; nil never adds repositories. By default it asks on the
+// terminal and declines when stdin isn't one.
+var ConfirmRepo = confirmRepoOnTerminal
+
+func confirmRepoOnTerminal(pkg string, repo pkgdb.Repo, setup [][]string) bool {
+	return askOnTerminal(fmt.Sprintf("Run %s?", formatFix(setup)))
+}
+
+// repoKind is what a package manager calls its repositories
+func repoKind(pkgManager string) string {
+	switch pkgManager {
+	case "brew":
+		return "tap"
+	case "scoop":
+		return "bucket"
+	case "choco":
+		return "source"
+	default:
+		return "repository"
+	}
+}
+
+// ensureRepos adds the repositories, taps, buckets and sources that pkgs
+// need and that aren't enabled yet, asking first. A declined repository is
+// an error naming the commands that add it. A dry run only prints them.
+func ensureRepos(pkgManager string, pkgs []string, dryRun bool) error {
+	added := make(map[string]bool)
+	for _, pkg := range pkgs {
+		repo, ok := pkgdb.RequiredRepo(pkgManager, pkg)
+		if !ok || added[repo.Name] || !repoNeeded(pkgManager, repo) || repoEnabled(pkgManager, repo) {
+			continue
+		}
+		added[repo.Name] = true
+
+		kind := repoKind(pkgManager)
+		setup := repoSetup(pkgManager, repo)
+		if setup == nil {
+			log.Warnf("%s is in the %s %s, which catalyst can't add for %s; add it before installing\n", pkg, repo.Name, kind, pkgManager)
+			continue
+		}
+		log.Infof("%s is in the %s %s, which isn't enabled.\n", pkg, repo.Name, kind)
+		if dryRun {
+			for _, args := range setup {
+				log.Infof("Would run: %s\n", strings.Join(args, " "))
+			}
+			continue
+		}
+		if ConfirmRepo == nil || !ConfirmRepo(pkg, repo, setup) {
+			return fmt.Errorf("%s needs the %s %s; add it with: %s", pkg, repo.Name, kind, formatFix(setup))
+		}
+
+		for _, args := range setup {
+			cmd := util.SystemCommand(args[0], args[1:]...)
+			if output, err := runPackageCommand(cmd); err != nil {
+				return fmt.Errorf("failed to add the %s %s: %s: %w\nOutput: %s", repo.Name, kind, strings.Join(args, " "), err, output)
+			}
+		}
+		log.Infof("Added the %s %s\n", repo.Name, kind)
+	}
+	return nil
+}
+
+// repoNeeded reports whether repo is missing from the system's defaults at
+// all: EPEL only exists for RHEL and its rebuilds, whose Fedora upstream
+// already ships the packages
+func repoNeeded(pkgManager string, repo pkgdb.Repo) bool {
+	if repo.Name == "epel" {
+		return isDistro("rhel", "centos", "rocky", "almalinux", "ol")
+	}
+	return true
+}
+
+// repoSetup returns the commands that add repo, or nil if pkgManager has no
+// repositories catalyst knows how to add
+func repoSetup(pkgManager string, repo pkgdb.Repo) [][]string {
+	switch pkgManager {
+	case "apt":
+		return [][]string{{"sudo", "add-apt-repository", "-y", repo.Name}, {"sudo", "apt-get", "update"}}
+	case "dnf", "yum":
+		if repo.Name == "epel" {
+			return [][]string{{"sudo", pkgManager, "install", "-y", "epel-release"}}
+		}
+		return [][]string{{"sudo", "dnf", "config-manager", "--set-enabled", repo.Name}}
+	case "brew":
+		if repo.URL != "" {
+			return [][]string{{"brew", "tap", repo.Name, repo.URL}}
+		}
+		return [][]string{{"brew", "tap", repo.Name}}
+	case "scoop":
+		if repo.URL != "" {
+			return [][]string{{"scoop", "bucket", "add", repo.Name, repo.URL}}
+		}
+		return [][]string{{"scoop", "bucket", "add", repo.Name}}
+	case "choco":
+		// A source that was removed is added back; one that was disabled
+		// is enabled
+		enable := []string{"choco", "source", "enable", "--name=" + repo.Name}
+		if repo.URL == "" {
+			return [][]string{enable}
+		}
+		return [][]string{{"choco", "source", "add", "--name=" + repo.Name, "--source=" + repo.URL}, enable}
+	}
+	return nil
+}
+
+// repoEnabled asks the package manager whether repo is already set up.
+// Failed queries count as not enabled, which at worst adds it again.
+func repoEnabled(pkgManager string, repo pkgdb.Repo) bool {
+	var cmd *util.Cmd
+	switch pkgManager {
+	case "apt":
+		cmd = util.ParsedCommand("apt-cache", "policy")
+	case "dnf", "yum":
+		cmd = util.ParsedCommand(pkgManager, "repolist", "--enabled")
+	case "brew":
+		cmd = util.ParsedCommand("brew", "tap")
+	case "scoop":
+		cmd = util.ParsedCommand("scoop", "bucket", "list")
+	case "choco":
+		cmd = util.ParsedCommand("choco", "source", "list", "--limit-output")
+	default:
+		return false
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return repoListed(pkgManager, repo, string(output))
+}
+
+// repoListed finds repo in the output of the query repoEnabled runs
+func repoListed(pkgManager string, repo pkgdb.Repo, output string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		switch pkgManager {
+		case "apt":
+			// Archives are listed by URL: ppa:owner/name is served from
+			// .../owner/name/ubuntu, components as "<suite>/<component>"
+			if ppa, ok := strings.CutPrefix(repo.Name, "ppa:"); ok {
+				if strings.Contains(line, "/"+ppa+"/") {
+					return true
+				}
+			} else if strings.Contains(line, "/"+repo.Name+" ") {
+				return true
+			}
+		case "choco":
+			// name|url|disabled|...
+			fields := strings.Split(line, "|")
+			if len(fields) > 2 && strings.EqualFold(fields[0], repo.Name) && fields[2] != "True" {
+				return true
+			}
+		default:
+			// The repository, tap or bucket name starts the line; EPEL's
+			// repository ids are epel, epel-next, ...
+			fields := strings.Fields(line)
+			if len(fields) > 0 && (fields[0] == repo.Name || (repo.Name == "epel" && strings.HasPrefix(fields[0], "epel"))) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package install
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+)
+
+// missingToolPackages returns the packages installing the tools that aren't
+// in PATH yet, without duplicates (flex and bison share a package on Windows)
+func missingToolPackages(tools []string, pkgManager string) []string {
+	var pkgs []string
+	for _, tool := range tools {
+		if pkgdb.FindTool(tool) != "" {
+			continue
+		}
+		if pkg := pkgdb.ToolPackage(tool, pkgManager); !slices.Contains(pkgs, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
+// InstallTools installs the tools missing from PATH with the package manager
+func InstallTools(tools []string) error {
+	pkgs := missingToolPackages(tools, getPackageManager())
+	if len(pkgs) == 0 {
+		return nil
+	}
+	log.Infof("Installing build tools: %v\n", pkgs)
+	if err := Install(pkgs); err != nil {
+		return fmt.Errorf("build tool installation failed: %w", err)
+	}
+	return nil
+}
+
+// EnsureTool returns the path of a tool, installing it first if it's not
+// in PATH
+func EnsureTool(name string) (string, error) {
+	if path := pkgdb.FindTool(name); path != "" {
+		return path, nil
+	}
+
+	log.Infof("%s not found, installing it...\n", name)
+	if err := Install([]string{pkgdb.ToolPackage(name, getPackageManager())}); err != nil {
+		return "", fmt.Errorf("%s is required but could not be installed: %w", name, err)
+	}
+
+	if path := pkgdb.FindTool(name); path != "" {
+		return path, nil
+	}
+	return "", fmt.Errorf("%s was installed but could not be found in PATH", name)
+}
@@ -0,0 +1,84 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/lock"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// verifyResource checks a downloaded resource against its configured sha256,
+// the checksum recorded in the lockfile (if lf is not nil and relock isn't
+// set) and its signature. New checksums are recorded in the lockfile.
+func verifyResource(resource config.Resource, lf *lock.Lockfile, relock bool) error {
+	path := resource.DownloadPath()
+
+	sum, err := util.FileSHA256(path)
+	if err != nil {
+		return fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+
+	if expected := strings.ToLower(resource.SHA256); expected != "" && sum != expected {
+		return fmt.Errorf("sha256 mismatch for %s: expected %s, got %s", path, expected, sum)
+	}
+
+	if lf != nil {
+		if locked, ok := lf.Resource(resource.URL); ok && !relock && locked.SHA256 != sum {
+			return fmt.Errorf("sha256 of %s changed since it was locked (locked %s, got %s) - the upstream may have been tampered with; if the change is expected, remove its entry from %s", path, locked.SHA256, sum, lock.DefaultPath)
+		}
+		lf.SetResource(lock.LockedResource{URL: resource.URL, Path: resource.Path, SHA256: sum})
+	}
+
+	if resource.Signature != "" {
+		if err := verifySignature(resource, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifySignature downloads a detached signature and verifies the resource with
+// minisign (.minisig) or GPG (.sig/.asc)
+func verifySignature(resource config.Resource, path string) error {
+	tempDir, err := util.MkdirTemp("sig-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	sigPath := filepath.Join(tempDir, filepath.Base(resource.Signature))
+	if err := DownloadResource(resource.Signature, sigPath); err != nil {
+		return fmt.Errorf("failed to download signature: %w", err)
+	}
+
+	var cmd *util.Cmd
+	if strings.HasSuffix(resource.Signature, ".minisig") {
+		if resource.PublicKey == "" {
+			return fmt.Errorf("minisign signature for %s requires public_key", path)
+		}
+		if _, err := exec.LookPath("minisign"); err != nil {
+			return fmt.Errorf("minisign not found - install it to verify %s", path)
+		}
+		cmd = util.Command("minisign", "-Vm", path, "-x", sigPath, "-P", resource.PublicKey)
+	} else {
+		if _, err := exec.LookPath("gpg"); err != nil {
+			return fmt.Errorf("gpg not found - install GnuPG to verify %s", path)
+		}
+		cmd = util.Command("gpg", "--verify", sigPath, path)
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("signature verification failed for %s: %w\nOutput: %s", path, err, string(output))
+	}
+
+	log.Infof("Signature verified: %s\n", path)
+	return nil
+}
@@ -0,0 +1,161 @@
+package lock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the lockfile location relative to the project root
+const DefaultPath = "catalyst.lock"
+
+// LockedResource records the verified checksum of a downloaded resource
+type LockedResource struct {
+	URL    string `yaml:"url"`
+	Path   string `yaml:"path"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// LockedPackage records the exact OS package a dependency resolved to
+type LockedPackage struct {
+	Name     string `yaml:"name"`
+	Platform string `yaml:"platform"`
+	Version  string `yaml:"version,omitempty"` // Installed version; empty until installed
+	Header   string `yaml:"header,omitempty"`  // Header the package was resolved from
+}
+
+// Lockfile pins the exact artifacts a project was built with
+type Lockfile struct {
+	Packages  []LockedPackage  `yaml:"packages,omitempty"`
+	Resources []LockedResource `yaml:"resources,omitempty"`
+}
+
+// stagedPath returns where the lockfile at path is written instead when
+// its directory is read-only and a build root is set, or ""
+func stagedPath(path string) string {
+	if util.BuildRoot == "" {
+		return ""
+	}
+	return util.StateDir(filepath.Base(path))
+}
+
+// Load reads a lockfile, or the update staged in the build root for a
+// read-only project. A missing lockfile is not an error and yields an empty
+// Lockfile.
+func Load(path string) (*Lockfile, error) {
+	if staged := stagedPath(path); staged != "" {
+		if _, err := os.Stat(staged); err == nil {
+			path = staged
+		}
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Lockfile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read lockfile: %w", err)
+	}
+
+	var lf Lockfile
+	if err := yaml.Unmarshal(data, &lf); err != nil {
+		return nil, fmt.Errorf("invalid lockfile %s: %w", path, err)
+	}
+	return &lf, nil
+}
+
+// Save writes the lockfile. When the project is read-only and a build root
+// is set, it is staged in the build root for Load to find; copy it into the
+// project to keep it.
+func (lf *Lockfile) Save(path string) error {
+	data, err := yaml.Marshal(lf)
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+
+	staged := stagedPath(path)
+	if staged != "" && !util.DirWritable(filepath.Dir(path)) {
+		if err := os.MkdirAll(filepath.Dir(staged), 0755); err != nil {
+			return fmt.Errorf("failed to write lockfile: %w", err)
+		}
+		log.Infof("%s is read-only; writing the lockfile to %s\n", filepath.Dir(path), staged)
+		path, staged = staged, ""
+	}
+
+	header := "# Generated by catalyst. Do not edit by hand.\n"
+	if err := util.WriteFileAtomic(path, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if staged != "" {
+		// The project's lockfile is current again
+		os.Remove(staged)
+	}
+	return nil
+}
+
+// Resource returns the locked entry for a resource URL, if any
+func (lf *Lockfile) Resource(url string) (LockedResource, bool) {
+	for _, r := range lf.Resources {
+		if r.URL == url {
+			return r, true
+		}
+	}
+	return LockedResource{}, false
+}
+
+// SetResource records or updates the locked entry for a resource
+func (lf *Lockfile) SetResource(entry LockedResource) {
+	for i, r := range lf.Resources {
+		if r.URL == entry.URL {
+			lf.Resources[i] = entry
+			return
+		}
+	}
+	lf.Resources = append(lf.Resources, entry)
+}
+
+// PlatformPackages returns the locked packages for a platform
+func (lf *Lockfile) PlatformPackages(platform string) []LockedPackage {
+	var pkgs []LockedPackage
+	for _, p := range lf.Packages {
+		if p.Platform == platform {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs
+}
+
+// Package returns the locked entry for a package on a platform, if any
+func (lf *Lockfile) Package(platform, name string) (LockedPackage, bool) {
+	for _, p := range lf.Packages {
+		if p.Platform == platform && p.Name == name {
+			return p, true
+		}
+	}
+	return LockedPackage{}, false
+}
+
+// SetPackage records or updates the locked entry for a package
+func (lf *Lockfile) SetPackage(entry LockedPackage) {
+	for i, p := range lf.Packages {
+		if p.Platform == entry.Platform && p.Name == entry.Name {
+			lf.Packages[i] = entry
+			return
+		}
+	}
+	lf.Packages = append(lf.Packages, entry)
+}
+
+// SetPlatformPackages replaces the locked packages of a platform
+func (lf *Lockfile) SetPlatformPackages(platform string, pkgs []LockedPackage) {
+	kept := lf.Packages[:0]
+	for _, p := range lf.Packages {
+		if p.Platform != platform {
+			kept = append(kept, p)
+		}
+	}
+	lf.Packages = append(kept, pkgs...)
+}
@@ -0,0 +1,87 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// ProjectLockPath returns the file locked while catalyst builds, installs or
+// rewrites catalyst.yml: .catalyst/lock in the project or the build root
+func ProjectLockPath() string {
+	return util.StateDir("lock")
+}
+
+// errWouldBlock is returned by tryLockFile when another process holds the lock
+var errWouldBlock = errors.New("lock is held by another process")
+
+// ProjectLock keeps other catalyst processes out of the project until released
+type ProjectLock struct {
+	file *os.File
+}
+
+// AcquireProject locks the project in the current directory. If another
+// catalyst process holds the lock, it fails with a message naming that
+// process, or waits for it to finish when wait is set. The operating system
+// drops the lock if the process dies, so a stale lock file never blocks.
+func AcquireProject(wait bool) (*ProjectLock, error) {
+	path := ProjectLockPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		if util.BuildRoot == "" && !util.DirWritable(".") {
+			return nil, fmt.Errorf("cannot create %s: %w\nThe project is read-only; pass --build-root or set CATALYST_BUILD_ROOT to build it elsewhere", filepath.Dir(path), err)
+		}
+		return nil, fmt.Errorf("cannot create %s: %w", filepath.Dir(path), err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open project lock: %w", err)
+	}
+
+	err = tryLockFile(file)
+	if errors.Is(err, errWouldBlock) {
+		holder := lockHolder(file)
+		if !wait {
+			file.Close()
+			return nil, fmt.Errorf("another catalyst process is running in this project%s\nWait for it to finish or rerun with --wait", holder)
+		}
+		log.Infof("Waiting for another catalyst process in this project%s...\n", holder)
+		err = lockFile(file)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot lock project: %w", err)
+	}
+
+	// Record who holds the lock for the message other processes print
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &ProjectLock{file: file}, nil
+}
+
+// Release unlocks the project
+func (l *ProjectLock) Release() {
+	if l == nil || l.file == nil {
+		return
+	}
+	unlockFile(l.file)
+	l.file.Close()
+	l.file = nil
+}
+
+// lockHolder describes the process recorded in the lock file, e.g. " (pid 4242)"
+func lockHolder(file *os.File) string {
+	buf := make([]byte, 32)
+	n, _ := file.ReadAt(buf, 0)
+	pid := strings.TrimSpace(string(buf[:n]))
+	if pid == "" {
+		return ""
+	}
+	return " (pid " + pid + ")"
+}
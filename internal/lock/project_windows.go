@@ -0,0 +1,55 @@
+//go:build windows
+
+package lock
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	errorLockViolation      = syscall.Errno(33)
+)
+
+// tryLockFile takes an exclusive LockFileEx lock without blocking
+func tryLockFile(file *os.File) error {
+	err := lockFileEx(file, lockfileExclusiveLock|lockfileFailImmediately)
+	if err == errorLockViolation {
+		return errWouldBlock
+	}
+	return err
+}
+
+// lockFile waits for an exclusive LockFileEx lock
+func lockFile(file *os.File) error {
+	return lockFileEx(file, lockfileExclusiveLock)
+}
+
+// unlockFile releases the lock
+func unlockFile(file *os.File) error {
+	var overlapped syscall.Overlapped
+	r, _, err := procUnlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// lockFileEx locks the first byte of the file, which is enough for a mutex
+func lockFileEx(file *os.File, flags uintptr) error {
+	var overlapped syscall.Overlapped
+	r, _, err := procLockFileEx.Call(file.Fd(), flags, 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Package log is catalyst's leveled console logger. Messages go to stdout
+// (warnings and errors to stderr) filtered by the global --quiet and
+// --verbose flags, and every message is also written to the --log-file if
+// one is set. Programs embedding catalyst receive them through a Handler
+// instead.
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the verbosity of a message, or the most verbose level printed
+type Level int
+
+const (
+	LevelError Level = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+// String returns the level's name as written to the log file
+func (l Level) String() string {
+	switch l {
+	case LevelError:
+		return "ERROR"
+	case LevelWarn:
+		return "WARN"
+	case LevelDebug:
+		return "DEBUG"
+	default:
+		return "INFO"
+	}
+}
+
+// ANSI colors for levels that stand out on a terminal
+var levelColors = map[Level]string{
+	LevelError: "\033[31m",
+	LevelWarn:  "\033[33m",
+	LevelDebug: "\033[2m",
+}
+
+var (
+	mu     sync.Mutex
+	level  = LevelInfo
+	color  = false
+	stdout io.Writer // nil means os.Stdout at the time of writing
+	stderr io.Writer // nil means os.Stderr at the time of writing
+	file   io.Writer
+	// handler receives messages instead of the console when set
+	handler Handler
+
+	progressShown bool // A Progress line is on the terminal
+)
+
+// SetLevel sets the most verbose level printed to the console
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// Enabled reports whether messages at l are printed to the console
+func Enabled(l Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return l <= level
+}
+
+// SetColor turns colored warnings, errors and debug messages on or off
+func SetColor(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	color = enabled
+}
+
+// Colored reports whether console messages are colored
+func Colored() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return color && handler == nil
+}
+
+// SetOutput redirects console output; nil restores os.Stdout or os.Stderr
+func SetOutput(out, errOut io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	stdout, stderr = out, errOut
+}
+
+// Handler receives a message, or a chunk of the output of a command catalyst
+// runs, instead of the console
+type Handler func(l Level, msg string)
+
+// SetHandler sends messages and the output of commands to h instead of the
+// console, at every level; nil restores the console. The log file still gets
+// every message.
+func SetHandler(h Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	handler = h
+}
+
+// Stdout returns where commands catalyst runs, such as compilers, write their
+// output: os.Stdout itself unless output is redirected, so they can tell it
+// is a terminal
+func Stdout() io.Writer { return commandOutput(LevelInfo) }
+
+// Stderr returns where commands catalyst runs write their errors, like Stdout
+func Stderr() io.Writer { return commandOutput(LevelWarn) }
+
+func commandOutput(l Level) io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	if handler != nil {
+		return handlerWriter(l)
+	}
+	if l <= LevelWarn {
+		if stderr != nil {
+			return stderr
+		}
+		return os.Stderr
+	}
+	if stdout != nil {
+		return stdout
+	}
+	return os.Stdout
+}
+
+// handlerWriter passes command output to the handler at its level
+type handlerWriter Level
+
+func (w handlerWriter) Write(p []byte) (int, error) {
+	mu.Lock()
+	h := handler
+	mu.Unlock()
+	if h != nil {
+		h(Level(w), string(p))
+	}
+	return len(p), nil
+}
+
+// SetFile appends every message, whatever the console level, to the file at
+// path. The returned file should be closed when the program exits.
+func SetFile(path string) (io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open log file: %w", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	file = f
+	return f, nil
+}
+
+// ColorSupported reports whether stdout is a terminal and NO_COLOR is unset
+func ColorSupported() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Progress redraws a transient status line, such as a download progress bar,
+// in place; an empty line clears it. It is only shown at info level when
+// stdout is a terminal, and never written to the log file.
+func Progress(line string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if level < LevelInfo || stdout != nil || handler != nil || os.Getenv("TERM") == "dumb" || !isTerminal(os.Stdout) {
+		return
+	}
+	io.WriteString(os.Stdout, "\r\033[K"+line)
+	progressShown = line != ""
+}
+
+// Debug prints details only shown with --verbose, formatted like fmt.Println
+func Debug(args ...any) { write(LevelDebug, fmt.Sprintln(args...)) }
+
+// Debugf prints details only shown with --verbose, formatted like fmt.Printf
+func Debugf(format string, args ...any) { write(LevelDebug, fmt.Sprintf(format, args...)) }
+
+// Info prints progress, formatted like fmt.Println
+func Info(args ...any) { write(LevelInfo, fmt.Sprintln(args...)) }
+
+// Infof prints progress, formatted like fmt.Printf
+func Infof(format string, args ...any) { write(LevelInfo, fmt.Sprintf(format, args...)) }
+
+// Warn prints a warning, formatted like fmt.Println
+func Warn(args ...any) { write(LevelWarn, fmt.Sprintln(args...)) }
+
+// Warnf prints a warning, formatted like fmt.Printf
+func Warnf(format string, args ...any) { write(LevelWarn, fmt.Sprintf(format, args...)) }
+
+// Error prints an error that doesn't stop the command, formatted like
+// fmt.Println
+func Error(args ...any) { write(LevelError, fmt.Sprintln(args...)) }
+
+// Errorf prints an error that doesn't stop the command, formatted like
+// fmt.Printf
+func Errorf(format string, args ...any) { write(LevelError, fmt.Sprintf(format, args...)) }
+
+func write(l Level, msg string) {
+	mu.Lock()
+	if file != nil {
+		writeFile(l, msg)
+	}
+	// The handler is called unlocked so it may log itself
+	if h := handler; h != nil {
+		mu.Unlock()
+		h(l, msg)
+		return
+	}
+	defer mu.Unlock()
+	if l > level {
+		return
+	}
+
+	out := stdout
+	if out == nil {
+		out = os.Stdout
+	}
+	if l <= LevelWarn {
+		out = stderr
+		if out == nil {
+			out = os.Stderr
+		}
+	}
+
+	// Messages replace the progress line; the next Progress call redraws it
+	if progressShown {
+		io.WriteString(os.Stdout, "\r\033[K")
+		progressShown = false
+	}
+
+	if l == LevelWarn {
+		msg = prefixMessage(msg, "Warning: ")
+	}
+	if code, ok := levelColors[l]; ok && color {
+		msg = colorize(msg, code)
+	}
+	io.WriteString(out, msg)
+}
+
+// writeFile writes each non-empty line of msg with a timestamp and level
+func writeFile(l Level, msg string) {
+	stamp := time.Now().Format(time.RFC3339)
+	for _, line := range strings.Split(msg, "\n") {
+		if strings.TrimSpace(line) != "" {
+			fmt.Fprintf(file, "%s %-5s %s\n", stamp, l, line)
+		}
+	}
+}
+
+// prefixMessage puts prefix after any leading blank lines of msg
+func prefixMessage(msg, prefix string) string {
+	body := strings.TrimLeft(msg, "\n")
+	return msg[:len(msg)-len(body)] + prefix + body
+}
+
+// colorize colors msg, leaving surrounding newlines uncolored
+func colorize(msg, code string) string {
+	body := strings.Trim(msg, "\n")
+	if body == "" {
+		return msg
+	}
+	start := strings.Index(msg, body)
+	return msg[:start] + code + body + "\033[0m" + msg[start+len(body):]
+}
@@ -0,0 +1,97 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func capture(t *testing.T, l Level) (*bytes.Buffer, *bytes.Buffer) {
+	var out, errOut bytes.Buffer
+	SetOutput(&out, &errOut)
+	SetLevel(l)
+	t.Cleanup(func() {
+		SetOutput(nil, nil)
+		SetLevel(LevelInfo)
+		SetColor(false)
+	})
+	return &out, &errOut
+}
+
+func TestLevels(t *testing.T) {
+	out, errOut := capture(t, LevelWarn)
+
+	Debugf("gcc -o build/app main.c\n")
+	Info("Building app")
+	Warnf("\nFailed to remove %s\n", "app.exe")
+	Error("build failed")
+
+	if out.Len() != 0 {
+		t.Errorf("expected --quiet to hide info and debug output, got %q", out.String())
+	}
+	if want := "\nWarning: Failed to remove app.exe\nbuild failed\n"; errOut.String() != want {
+		t.Errorf("stderr = %q, want %q", errOut.String(), want)
+	}
+}
+
+func TestColor(t *testing.T) {
+	_, errOut := capture(t, LevelInfo)
+	SetColor(true)
+
+	Error("build failed")
+	if want := "\033[31mbuild failed\033[0m\n"; errOut.String() != want {
+		t.Errorf("stderr = %q, want %q", errOut.String(), want)
+	}
+}
+
+func TestFileGetsEveryLevel(t *testing.T) {
+	out, _ := capture(t, LevelInfo)
+	path := filepath.Join(t.TempDir(), "catalyst.log")
+	f, err := SetFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		f.Close()
+		mu.Lock()
+		file = nil
+		mu.Unlock()
+	}()
+
+	Debug("Linking with: gcc")
+	Info()
+	Info("Build complete!")
+
+	if strings.Contains(out.String(), "Linking") {
+		t.Errorf("debug output printed at info level: %q", out.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "DEBUG Linking with: gcc") || !strings.HasSuffix(lines[1], "INFO  Build complete!") {
+		t.Errorf("unexpected log file contents:\n%s", data)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	out, _ := capture(t, LevelWarn)
+	var got []string
+	SetHandler(func(l Level, msg string) { got = append(got, l.String()+" "+msg) })
+	t.Cleanup(func() { SetHandler(nil) })
+
+	Debugf("gcc -o build/app main.c\n")
+	Warn("no tests")
+	Stderr().Write([]byte("main.c:1: error\n"))
+
+	want := []string{"DEBUG gcc -o build/app main.c\n", "WARN no tests\n", "WARN main.c:1: error\n"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("handler got %q, want %q", got, want)
+	}
+	if out.Len() != 0 {
+		t.Errorf("console got %q with a handler set", out.String())
+	}
+}
@@ -0,0 +1,60 @@
+package pkgdb
+
+import "sort"
+
+// ConanRefs maps abstract package names to Conan Center references.
+// Names without an entry (pthread, omp) come with the compiler or OS.
+//
+// Format: [AbstractName] -> "name/version"
+var ConanRefs = map[string]string{
+	"openssl":  "openssl/3.3.2",
+	"ssl":      "openssl/3.3.2",
+	"crypto":   "openssl/3.3.2",
+	"curl":     "libcurl/8.10.1",
+	"png":      "libpng/1.6.44",
+	"zlib":     "zlib/1.3.1",
+	"sqlite3":  "sqlite3/3.46.1",
+	"sqlite":   "sqlite3/3.46.1",
+	"jansson":  "jansson/2.14",
+	"json":     "jansson/2.14",
+	"readline": "readline/8.2",
+	"ncurses":  "ncurses/6.5",
+	"pcre":     "pcre/8.45",
+	"SDL2":     "sdl/2.30.8",
+	"GLFW":     "glfw/3.4",
+	"GL":       "opengl/system",
+	"OpenGL":   "opengl/system",
+	"vulkan":   "vulkan-loader/1.3.290.0",
+}
+
+// ConanReference returns the Conan reference for a dependency, given either
+// its abstract name or a system package name PackageDB translates one to
+// (libssl-dev, openssl-devel, ...). The cached remote database's "conan"
+// entries are consulted before the built-in references.
+func ConanReference(dep string) (string, bool) {
+	if ref, ok := lookupRemote(dep, "conan"); ok && ref != "" {
+		return ref, true
+	}
+	if ref, ok := ConanRefs[dep]; ok {
+		return ref, true
+	}
+
+	// Sorted so a package several abstract names share always resolves the same way
+	names := make([]string, 0, len(PackageDB))
+	for name := range PackageDB {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ref, ok := ConanRefs[name]
+		if !ok {
+			continue
+		}
+		for _, realName := range PackageDB[name] {
+			if realName == dep {
+				return ref, true
+			}
+		}
+	}
+	return "", false
+}
@@ -1,5 +1,12 @@
 package pkgdb
 
+import (
+	"maps"
+	"slices"
+
+	"github.com/Sabique-Islam/catalyst/internal/headers"
+)
+
 // PackageDB is a translation database that maps abstract package names
 // (as found by the dependency scanner) to real, installable package names
 // for different system package managers.
@@ -126,121 +133,55 @@ var PackageDB = map[string]map[string]string{
 		"vcpkg":  "jansson",
 		"choco":  "jansson",
 	},
-	// Standard library headers - no package needed
-	"stdio": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"stdlib": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"string": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"math": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"time": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"ctype": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"assert": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"errno": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"signal": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
+	// Graphics libraries
+	"SDL2": {
+		"apt":    "libsdl2-dev",
+		"dnf":    "SDL2-devel",
+		"pacman": "sdl2",
+		"brew":   "sdl2",
+		"vcpkg":  "sdl2",
+		"choco":  "sdl2",
 	},
-	"stdarg": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
+	"GLFW": {
+		"apt":    "libglfw3-dev",
+		"dnf":    "glfw-devel",
+		"pacman": "glfw",
+		"brew":   "glfw",
+		"vcpkg":  "glfw3",
+		"choco":  "glfw3",
 	},
-	"stdbool": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
+	"GL": {
+		"apt":    "libgl1-mesa-dev",
+		"dnf":    "mesa-libGL-devel",
+		"pacman": "mesa",
+		"brew":   "", // OpenGL framework ships with macOS
+		"vcpkg":  "", // opengl32 ships with Windows
+		"choco":  "", // opengl32 ships with Windows
 	},
-	"stdint": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
+	"OpenGL": {
+		"apt":    "libgl1-mesa-dev",
+		"dnf":    "mesa-libGL-devel",
+		"pacman": "mesa",
+		"brew":   "", // OpenGL framework ships with macOS
 		"vcpkg":  "",
 		"choco":  "",
 	},
-	"unistd": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
-	},
-	"fcntl": {
-		"apt":    "",
-		"dnf":    "",
-		"pacman": "",
-		"brew":   "",
-		"vcpkg":  "",
-		"choco":  "",
+	"vulkan": {
+		"apt":    "libvulkan-dev",
+		"dnf":    "vulkan-loader-devel",
+		"pacman": "vulkan-icd-loader",
+		"brew":   "vulkan-loader",
+		"vcpkg":  "vulkan",
+		"choco":  "vulkan-sdk",
 	},
 }
 
+// fallbackManagers use another manager's database entries when they have none
+var fallbackManagers = map[string]string{
+	"port": "brew",
+	"yum":  "dnf",
+}
+
 // Translate converts an abstract package name to the real package name
 // for a specific package manager.
 //
@@ -252,25 +193,53 @@ var PackageDB = map[string]map[string]string{
 //   - string: The real package name for the given package manager
 //   - bool: true if a translation was found, false otherwise
 //
-// If the abstract name is not in the database, or if the package manager
+// The cached remote database is consulted before the built-in one.
+// If the abstract name is not in either database, or if the package manager
 // is not supported for that package, it returns ("", false).
 // An empty string with true means the package is part of the standard library.
 func Translate(abstractName, pkgManager string) (string, bool) {
+	// The downloaded database (catalyst pkgdb update) is newer than the built-in one
+	if realName, found := lookupRemote(abstractName, pkgManager); found {
+		return realName, true
+	}
+
 	// Check if the abstract name exists in the database
 	pkgMap, exists := PackageDB[abstractName]
 	if !exists {
+		// Standard, POSIX, Windows SDK and compiler headers need no package
+		if headers.IsStandard(abstractName) {
+			return "", true
+		}
 		return "", false
 	}
 
 	// Check if the package manager is supported for this package
 	realName, exists := pkgMap[pkgManager]
 	if !exists {
-		return "", false
+		// MacPorts mostly uses the Homebrew names
+		if fallback, ok := fallbackManagers[pkgManager]; ok {
+			realName, exists = pkgMap[fallback]
+		}
+		if !exists {
+			return "", false
+		}
 	}
 
 	return realName, true
 }
 
+// TranslateBetween converts a package name of one package manager to the
+// name of the same package for another, through the abstract name with
+// that package (e.g. libssl-dev for apt to openssl for vcpkg)
+func TranslateBetween(pkg, from, to string) (string, bool) {
+	for _, abstractName := range slices.Sorted(maps.Keys(PackageDB)) {
+		if name, ok := Translate(abstractName, from); ok && name == pkg {
+			return Translate(abstractName, to)
+		}
+	}
+	return "", false
+}
+
 // TranslateWithSearch attempts static translation first, then falls back to dynamic search
 func TranslateWithSearch(abstractName, pkgManager string) (string, bool) {
 	// First try static translation
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
 )
 
 // InteractiveSearch performs a dynamic search and lets the user choose from results
@@ -27,6 +29,11 @@ func InteractiveSearch(headerName, pkgManager string) (string, bool) {
 		return results[0].PackageName, true
 	}
 
+	return chooseResult(headerName, results)
+}
+
+// chooseResult lets the user pick one of the search results
+func chooseResult(headerName string, results []SearchResult) (string, bool) {
 	// Show options to user
 	fmt.Printf("Found %d potential packages for '%s':\n\n", len(results), headerName)
 
@@ -79,33 +86,30 @@ func BatchSearch(dependencies []string, pkgManager string, interactive bool) map
 
 	fmt.Printf("Resolving %d dependencies for %s...\n\n", len(dependencies), pkgManager)
 
+	mode := ModeAuto
+	if interactive {
+		mode = ModeInteractive
+	}
+	resolver, err := NewResolver(pkgManager, config.Resolution{Mode: mode})
+	if err != nil {
+		fmt.Printf("Resolution failed: %v\n", err)
+		return results
+	}
+
 	for i, dep := range dependencies {
 		fmt.Printf("[%d/%d] Processing '%s'...\n", i+1, len(dependencies), dep)
 
-		// Try static translation first
-		if pkg, found := Translate(dep, pkgManager); found {
-			if pkg != "" { // Skip empty (standard library) packages
-				results[dep] = pkg
-				fmt.Printf("  ✓ Found in database: %s\n", pkg)
-			} else {
-				fmt.Printf("  ✓ Standard library header (no package needed)\n")
-			}
-			fmt.Println()
-			continue
-		}
-
-		// Try dynamic search
-		if interactive {
-			if pkg, found := InteractiveSearch(dep, pkgManager); found {
-				results[dep] = pkg
-			}
-		} else {
-			if pkg, found := TranslateWithSearch(dep, pkgManager); found {
-				results[dep] = pkg
-				fmt.Printf("  ✓ Found via search: %s\n", pkg)
-			} else {
-				fmt.Printf("  ✗ Not found - likely a local header\n")
-			}
+		res, _ := resolver.Resolve(dep)
+		switch {
+		case res.Standard:
+			fmt.Printf("  ✓ Standard library header (no package needed)\n")
+		case res.Satisfied:
+			fmt.Printf("  ✓ Provided by the compiler: %s (no package needed)\n", res.Path)
+		case res.Found:
+			results[dep] = res.Package
+			fmt.Printf("  ✓ Found via %s: %s\n", res.Strategy, res.Package)
+		default:
+			fmt.Printf("  ✗ Not found - likely a local header\n")
 		}
 		fmt.Println()
 	}
@@ -0,0 +1,117 @@
+package pkgdb
+
+import (
+	"bufio"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// StrategyToolchain marks headers the host compiler already provides
+const StrategyToolchain = "toolchain"
+
+var (
+	probeOnce     sync.Once
+	probeCompiler string   // Host C compiler, empty if none is installed
+	toolchainDirs []string // The compiler's own include directories
+	probeMu       sync.Mutex
+	probeCache    = make(map[string]string) // header -> path found ("" if not provided)
+)
+
+// toolchainHeader reports whether the host compiler finds a header in its own
+// include directories (omp.h, stdatomic.h) and returns where. Headers found in
+// /usr/include or a package prefix are not reported: they come from installed
+// packages, and other machines would still need those packages.
+func toolchainHeader(name string) (string, bool) {
+	probeOnce.Do(findProbeCompiler)
+	if probeCompiler == "" {
+		return "", false
+	}
+
+	header := name
+	if filepath.Ext(header) == "" {
+		header += ".h"
+	}
+
+	probeMu.Lock()
+	defer probeMu.Unlock()
+	path, cached := probeCache[header]
+	if !cached {
+		path = probeHeader(header)
+		probeCache[header] = path
+	}
+	return path, path != ""
+}
+
+// findProbeCompiler finds the host compiler and its built-in include directories
+func findProbeCompiler() {
+	for _, cc := range []string{"cc", "gcc", "clang"} {
+		if _, err := exec.LookPath(cc); err == nil {
+			probeCompiler = cc
+			break
+		}
+	}
+	if probeCompiler == "" {
+		return
+	}
+
+	// GCC's internal headers (omp.h, stdatomic.h, intrinsics)
+	if out, err := util.Command(probeCompiler, "-print-file-name=include").Output(); err == nil {
+		if dir := strings.TrimSpace(string(out)); filepath.IsAbs(dir) {
+			toolchainDirs = append(toolchainDirs, dir)
+		}
+	}
+	// Clang's resource directory
+	if out, err := util.Command(probeCompiler, "-print-resource-dir").Output(); err == nil {
+		if dir := strings.TrimSpace(string(out)); filepath.IsAbs(dir) {
+			toolchainDirs = append(toolchainDirs, filepath.Join(dir, "include"))
+		}
+	}
+}
+
+// probeHeader preprocesses '#include <header>' and returns the path of the
+// header if it came from one of the toolchain's include directories
+func probeHeader(header string) string {
+	cmd := util.Command(probeCompiler, "-E", "-x", "c", "-")
+	cmd.Stdin = strings.NewReader("#include <" + header + ">\n")
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	// Line markers look like: # 1 "/usr/lib/gcc/x86_64-linux-gnu/13/include/omp.h" 1
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "# ") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		path := strings.Trim(fields[2], `"`)
+		if !strings.HasSuffix(filepath.ToSlash(path), "/"+header) {
+			continue
+		}
+		if isToolchainPath(path) {
+			return path
+		}
+		return ""
+	}
+	return ""
+}
+
+// isToolchainPath reports whether a header path belongs to the compiler itself
+// or, on macOS, to the Xcode SDK
+func isToolchainPath(path string) bool {
+	for _, dir := range toolchainDirs {
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return strings.Contains(path, "/Library/Developer/CommandLineTools/") || strings.Contains(path, ".sdk/")
+}
@@ -0,0 +1,125 @@
+package pkgdb
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+)
+
+// PruneCandidate is a dependency whose headers are no longer included anywhere
+type PruneCandidate struct {
+	Platform string
+	Package  string
+	Headers  []string // Headers the package provides
+}
+
+// FindUnusedDependencies cross-checks the dependencies in the config against
+// the headers the project includes (as returned by fetch.ScanDependencies).
+// Returns packages whose headers are no longer included, and packages that
+// cannot be checked because no header is known for them (tools, system libraries).
+func FindUnusedDependencies(cfg *config.Config, includes []string) ([]PruneCandidate, []string) {
+	used := make(map[string]bool)
+	for _, inc := range includes {
+		used[headerKey(inc)] = true
+	}
+
+	var unused []PruneCandidate
+	unknown := make(map[string]bool)
+
+	check := func(platform string, deps []string) {
+		for _, pkg := range deps {
+			headers := HeadersForPackage(cfg, pkg)
+			if len(headers) == 0 {
+				unknown[pkg] = true
+				continue
+			}
+
+			inUse := false
+			for _, header := range headers {
+				if used[headerKey(header)] {
+					inUse = true
+					break
+				}
+			}
+			if !inUse {
+				unused = append(unused, PruneCandidate{Platform: platform, Package: pkg, Headers: headers})
+			}
+		}
+	}
+
+	platforms := make([]string, 0, len(cfg.Dependencies))
+	for platform := range cfg.Dependencies {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	for _, platform := range platforms {
+		check(platform, cfg.Dependencies[platform])
+		if override, ok := cfg.Platforms[platform]; ok {
+			check(platform, override.Dependencies)
+		}
+	}
+
+	var unknownList []string
+	for pkg := range unknown {
+		unknownList = append(unknownList, pkg)
+	}
+	sort.Strings(unknownList)
+	return unused, unknownList
+}
+
+// HeadersForPackage returns the headers known to come from a package, using
+// the recorded provenance, the static database and the known library database
+func HeadersForPackage(cfg *config.Config, pkg string) []string {
+	seen := make(map[string]bool)
+	var headers []string
+	add := func(header string) {
+		if !seen[header] {
+			seen[header] = true
+			headers = append(headers, header)
+		}
+	}
+
+	if cfg != nil {
+		for _, src := range cfg.Provenance {
+			if src.Package == pkg && src.Header != "" {
+				add(src.Header)
+			}
+		}
+		for header, byTarget := range cfg.PackageOverrides {
+			for _, realName := range byTarget {
+				if realName != "" && realName == pkg {
+					add(header)
+					break
+				}
+			}
+		}
+	}
+
+	for abstractName, managers := range PackageDB {
+		for _, realName := range managers {
+			if realName != "" && realName == pkg {
+				add(abstractName)
+				break
+			}
+		}
+	}
+
+	for _, header := range analyzer.HeadersForPackage(pkg) {
+		add(header)
+	}
+
+	sort.Strings(headers)
+	return headers
+}
+
+// headerKey reduces a header to the first path component that
+// fetch.ScanDependencies reports (e.g. "curl" for curl/curl.h)
+func headerKey(header string) string {
+	header = strings.ToLower(header)
+	if idx := strings.IndexAny(header, "/."); idx >= 0 {
+		header = header[:idx]
+	}
+	return header
+}
@@ -0,0 +1,147 @@
+package pkgdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRemoteURL is where catalyst pkgdb update downloads the curated
+// translation database from
+const DefaultRemoteURL = "https://raw.githubusercontent.com/Sabique-Islam/catalyst/main/pkgdb.json"
+
+// RemoteDB is a downloaded translation database. Packages has the same
+// [AbstractName][PackageManager] -> RealPackageName layout as PackageDB, and
+// Repos the [PackageManager][RealPackageName] -> Repo layout of PackageRepos.
+type RemoteDB struct {
+	Version  int                          `yaml:"version" json:"version"`
+	Updated  string                       `yaml:"updated,omitempty" json:"updated,omitempty"`
+	URL      string                       `yaml:"url,omitempty" json:"url,omitempty"` // Where the cached copy came from
+	Packages map[string]map[string]string `yaml:"packages" json:"packages"`
+	Repos    map[string]map[string]Repo   `yaml:"repos,omitempty" json:"repos,omitempty"`
+}
+
+var (
+	remoteOnce sync.Once
+	remoteDB   *RemoteDB
+)
+
+// RemoteDBPath returns where the downloaded database is cached (~/.catalyst/pkgdb.json)
+func RemoteDBPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find home directory: %w", err)
+	}
+	return filepath.Join(home, ".catalyst", "pkgdb.json"), nil
+}
+
+// LoadRemoteDB returns the cached remote database, or nil if
+// catalyst pkgdb update has never run or the cache is unreadable
+func LoadRemoteDB() *RemoteDB {
+	remoteOnce.Do(func() {
+		path, err := RemoteDBPath()
+		if err != nil {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		db, err := parseRemoteDB(data)
+		if err != nil {
+			log.Warnf("Ignoring %s: %v\n", path, err)
+			return
+		}
+		remoteDB = db
+	})
+	return remoteDB
+}
+
+// UpdateRemoteDB downloads the database from url, validates it and replaces
+// the cached copy. JSON and YAML are both accepted.
+func UpdateRemoteDB(url string) (*RemoteDB, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: HTTP %s", url, resp.Status)
+	}
+
+	// The database is small; anything much larger is not one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	db, err := parseRemoteDB(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid package database at %s: %w", url, err)
+	}
+	db.URL = url
+
+	path, err := RemoteDBPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create %s: %w", filepath.Dir(path), err)
+	}
+	// Re-encoded as JSON so the cache records where it came from
+	out, err := json.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode package database: %w", err)
+	}
+	if err := util.WriteFileAtomic(path, out, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save package database: %w", err)
+	}
+
+	remoteDB = db
+	return db, nil
+}
+
+// parseRemoteDB decodes and validates a remote database
+func parseRemoteDB(data []byte) (*RemoteDB, error) {
+	var db RemoteDB
+	// JSON is valid YAML, so one decoder reads both formats
+	if err := yaml.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+	if db.Version < 1 {
+		return nil, fmt.Errorf("missing or invalid version")
+	}
+	if len(db.Packages) == 0 {
+		return nil, fmt.Errorf("no packages")
+	}
+	return &db, nil
+}
+
+// lookupRemote finds a package in the cached remote database
+func lookupRemote(abstractName, pkgManager string) (string, bool) {
+	db := LoadRemoteDB()
+	if db == nil {
+		return "", false
+	}
+	pkgMap, ok := db.Packages[abstractName]
+	if !ok {
+		return "", false
+	}
+	if realName, ok := pkgMap[pkgManager]; ok {
+		return realName, true
+	}
+	if fallback, ok := fallbackManagers[pkgManager]; ok {
+		realName, ok := pkgMap[fallback]
+		return realName, ok
+	}
+	return "", false
+}
@@ -0,0 +1,69 @@
+package pkgdb
+
+// Repo is a repository, Homebrew tap, Scoop bucket or Chocolatey source that
+// a package is installed from but that the package manager doesn't enable by
+// default
+type Repo struct {
+	Name string `yaml:"name" json:"name"`                   // e.g. "epel", "ppa:ubuntu-toolchain-r/test", "osx-cross/avr", "extras"
+	URL  string `yaml:"url,omitempty" json:"url,omitempty"` // Needed for Chocolatey sources and Scoop buckets outside Scoop's known list
+}
+
+// PackageRepos records the packages that live outside a package manager's
+// default repositories.
+//
+// Format: [PackageManager][RealPackageName] -> Repo
+var PackageRepos = map[string]map[string]Repo{
+	"apt": {
+		"gcc-13": {Name: "ppa:ubuntu-toolchain-r/test"},
+		"g++-13": {Name: "ppa:ubuntu-toolchain-r/test"},
+		"gcc-14": {Name: "ppa:ubuntu-toolchain-r/test"},
+		"g++-14": {Name: "ppa:ubuntu-toolchain-r/test"},
+	},
+	// EPEL is only needed on RHEL and its rebuilds; Fedora ships these
+	"dnf": {
+		"SDL2_image-devel": {Name: "epel"},
+		"SDL2_mixer-devel": {Name: "epel"},
+		"SDL2_ttf-devel":   {Name: "epel"},
+		"glfw-devel":       {Name: "epel"},
+		"libsodium-devel":  {Name: "epel"},
+		"cjson-devel":      {Name: "epel"},
+		"raylib-devel":     {Name: "epel"},
+	},
+	"yum": {
+		"SDL2_image-devel": {Name: "epel"},
+		"SDL2_mixer-devel": {Name: "epel"},
+		"SDL2_ttf-devel":   {Name: "epel"},
+		"glfw-devel":       {Name: "epel"},
+		"libsodium-devel":  {Name: "epel"},
+	},
+	"brew": {
+		"avr-gcc":             {Name: "osx-cross/avr"},
+		"arm-gcc-bin":         {Name: "osx-cross/arm"},
+		"riscv-gnu-toolchain": {Name: "riscv-software-src/riscv"},
+	},
+	"scoop": {
+		"sdl2":         {Name: "extras"},
+		"sdl2_image":   {Name: "extras"},
+		"sdl2_ttf":     {Name: "extras"},
+		"vcredist2022": {Name: "extras"},
+	},
+	// Organizations often remove the community feed in favour of an
+	// internal one
+	"choco": {
+		"mingw": {Name: "chocolatey", URL: "https://community.chocolatey.org/api/v2/"},
+		"msys2": {Name: "chocolatey", URL: "https://community.chocolatey.org/api/v2/"},
+	},
+}
+
+// RequiredRepo returns the repository a package must be installed from when
+// the package manager doesn't enable it by default. The cached remote
+// database is consulted before the built-in one.
+func RequiredRepo(pkgManager, realName string) (Repo, bool) {
+	if db := LoadRemoteDB(); db != nil {
+		if repo, ok := db.Repos[pkgManager][realName]; ok {
+			return repo, true
+		}
+	}
+	repo, ok := PackageRepos[pkgManager][realName]
+	return repo, ok
+}
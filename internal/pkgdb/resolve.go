@@ -0,0 +1,422 @@
+package pkgdb
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/events"
+	"github.com/Sabique-Islam/catalyst/internal/headers"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Resolution strategies, run in the configured order
+const (
+	StrategyStatic     = "static"      // Built-in package database
+	StrategyLibrary    = "library"     // Known library database (analyzer)
+	StrategyPkgConfig  = "pkg-config"  // Installed pkg-config module and its owning package
+	StrategyFileSearch = "file-search" // Package manager file index (apt-file, dnf provides, pacman -F)
+	StrategyNameSearch = "name-search" // Package name search
+)
+
+// StrategyOverride marks packages forced by package_overrides in catalyst.yml
+const StrategyOverride = "override"
+
+// Resolution modes
+const (
+	ModeAuto        = "auto"        // Accept the first result that meets its step's threshold
+	ModeInteractive = "interactive" // Ask when no result meets its threshold
+	ModeStrict      = "strict"      // Fail when no result meets its threshold
+)
+
+// DefaultStrategies is the resolution pipeline used when catalyst.yml does not set one
+var DefaultStrategies = []string{StrategyStatic, StrategyLibrary, StrategyPkgConfig, StrategyFileSearch, StrategyNameSearch}
+
+// ReviewConfidence is the confidence below which an accepted mapping is a
+// guess worth reviewing
+const ReviewConfidence = 90
+
+// DefaultThresholds are the minimum confidences each step needs to be accepted
+var DefaultThresholds = map[string]int{
+	StrategyStatic:     0,
+	StrategyLibrary:    0,
+	StrategyPkgConfig:  80,
+	StrategyFileSearch: 80,
+	StrategyNameSearch: 90,
+}
+
+// hostOnlyStrategies query the local system and only work for its package manager
+var hostOnlyStrategies = map[string]bool{
+	StrategyPkgConfig:  true,
+	StrategyFileSearch: true,
+	StrategyNameSearch: true,
+}
+
+// ResolveResult is the outcome of resolving one dependency
+type ResolveResult struct {
+	Name       string
+	Package    string
+	Strategy   string // Step that produced the package
+	Confidence int
+	Found      bool
+	Standard   bool   // Part of the standard library, no package needed
+	Satisfied  bool   // Already provided by the host compiler, no package needed on this machine
+	Path       string // Where the compiler found a satisfied header
+}
+
+// Resolver resolves abstract dependency names to packages through a pipeline of strategies
+type Resolver struct {
+	PackageManager string
+	Mode           string
+	Strategies     []string
+	Thresholds     map[string]int
+	Explain        io.Writer // When set, every step and candidate considered is written here
+	// Packages forced by package_overrides in catalyst.yml, checked before any step
+	Overrides config.PackageOverrides
+}
+
+// ExplainOutput is the Explain writer given to new resolvers
+// (set by --explain-resolution)
+var ExplainOutput io.Writer
+
+// NewResolver creates a resolver for the host package manager from the
+// resolution block of catalyst.yml. Unset fields use the defaults.
+func NewResolver(pkgManager string, cfg config.Resolution) (*Resolver, error) {
+	r := &Resolver{
+		PackageManager: pkgManager,
+		Mode:           cfg.Mode,
+		Strategies:     cfg.Strategies,
+		Thresholds:     make(map[string]int),
+		Explain:        ExplainOutput,
+	}
+
+	switch r.Mode {
+	case "":
+		r.Mode = ModeAuto
+	case ModeAuto, ModeInteractive, ModeStrict:
+	default:
+		return nil, fmt.Errorf("invalid resolution mode %q (expected auto, interactive or strict)", cfg.Mode)
+	}
+
+	if len(r.Strategies) == 0 {
+		r.Strategies = DefaultStrategies
+	}
+	for _, strategy := range r.Strategies {
+		if _, ok := DefaultThresholds[strategy]; !ok {
+			return nil, fmt.Errorf("unknown resolution strategy %q (expected one of %s)", strategy, strings.Join(DefaultStrategies, ", "))
+		}
+	}
+
+	for strategy, threshold := range DefaultThresholds {
+		r.Thresholds[strategy] = threshold
+	}
+	for strategy, threshold := range cfg.Thresholds {
+		if _, ok := DefaultThresholds[strategy]; !ok {
+			return nil, fmt.Errorf("threshold set for unknown resolution strategy %q", strategy)
+		}
+		if threshold < 0 || threshold > 100 {
+			return nil, fmt.Errorf("threshold for %s must be between 0 and 100", strategy)
+		}
+		r.Thresholds[strategy] = threshold
+	}
+
+	return r, nil
+}
+
+// Resolve resolves a dependency for the host package manager
+func (r *Resolver) Resolve(name string) (ResolveResult, error) {
+	return r.ResolveFor(name, r.PackageManager)
+}
+
+// ResolveFor resolves a dependency for the given package manager. Strategies
+// that query the local system are skipped for other package managers.
+// In strict mode an error is returned when no step meets its threshold.
+func (r *Resolver) ResolveFor(name, pkgManager string) (ResolveResult, error) {
+	events.Emit(events.Event{Kind: events.ResolutionStarted, Name: name})
+	start := time.Now()
+	res, err := r.resolveFor(name, pkgManager)
+	events.Emit(events.Event{Kind: events.ResolutionFinished, Name: name, Package: res.Package, Strategy: res.Strategy,
+		Duration: time.Since(start), Error: events.Err(err)})
+	return res, err
+}
+
+func (r *Resolver) resolveFor(name, pkgManager string) (ResolveResult, error) {
+	host := pkgManager == r.PackageManager
+	var candidates []SearchResult
+	r.explainf("Resolving %s for %s (mode %s):\n", name, pkgManager, r.Mode)
+
+	if pkg, ok := r.Overrides.Lookup(name, pkgManager, platform.PackageManagerOS(pkgManager)); ok {
+		r.explainf("  %-12s %s accepted: package_overrides in catalyst.yml\n", StrategyOverride, pkg)
+		r.explainSkipped(r.Strategies)
+		return ResolveResult{Name: name, Package: pkg, Strategy: StrategyOverride, Confidence: 100, Found: true, Standard: pkg == ""}, nil
+	}
+
+	// Headers the host compiler already provides need no package on this machine
+	if host && !headers.IsStandard(name) {
+		if path, ok := toolchainHeader(name); ok {
+			r.explainf("  %-12s found by the compiler at %s, no package needed\n", StrategyToolchain, path)
+			r.explainSkipped(r.Strategies)
+			return ResolveResult{Name: name, Strategy: StrategyToolchain, Confidence: 100, Found: true, Satisfied: true, Path: path}, nil
+		}
+	}
+
+	for i, strategy := range r.Strategies {
+		if hostOnlyStrategies[strategy] && !host {
+			r.explainf("  %-12s skipped: only runs for the host package manager (%s)\n", strategy, r.PackageManager)
+			continue
+		}
+
+		if strategy == StrategyStatic {
+			// The static database also knows which headers need no package
+			if pkg, found := Translate(name, pkgManager); found {
+				if pkg == "" {
+					r.explainf("  %-12s standard library header, no package needed\n", strategy)
+				} else {
+					r.explainf("  %-12s %s (100%%) accepted: database entry\n", strategy, pkg)
+				}
+				r.explainSkipped(r.Strategies[i+1:])
+				return ResolveResult{Name: name, Package: pkg, Strategy: strategy, Confidence: 100, Found: true, Standard: pkg == ""}, nil
+			}
+			r.explainf("  %-12s no entry\n", strategy)
+			continue
+		}
+
+		results := r.runStrategy(strategy, name, pkgManager)
+		if len(results) == 0 {
+			r.explainf("  %-12s no candidates\n", strategy)
+			continue
+		}
+
+		threshold := r.Thresholds[strategy]
+		best := results[0]
+		if best.Confidence >= threshold {
+			r.explainf("  %-12s %s (%d%%) accepted: meets threshold %d%%%s\n", strategy, best.PackageName, best.Confidence, threshold, describe(best))
+			for _, result := range results[1:] {
+				r.explainf("  %-12s %s (%d%%) not chosen: lower ranked%s\n", "", result.PackageName, result.Confidence, describe(result))
+			}
+			r.explainSkipped(r.Strategies[i+1:])
+			return ResolveResult{Name: name, Package: best.PackageName, Strategy: strategy, Confidence: best.Confidence, Found: true}, nil
+		}
+		for _, result := range results {
+			r.explainf("  %-12s %s (%d%%) rejected: below threshold %d%%%s\n", strategy, result.PackageName, result.Confidence, threshold, describe(result))
+			result.Description = strings.TrimSpace(strategy + ": " + result.Description)
+			candidates = append(candidates, result)
+		}
+	}
+
+	unresolved := ResolveResult{Name: name}
+	if len(candidates) == 0 {
+		r.explainf("  → unresolved: no step found a candidate\n")
+		if r.Mode == ModeStrict {
+			return unresolved, fmt.Errorf("could not resolve %s for %s", name, pkgManager)
+		}
+		return unresolved, nil
+	}
+
+	candidates = platform.DeduplicateResults(candidates)
+	switch r.Mode {
+	case ModeInteractive:
+		if host {
+			r.explainf("  → no candidate met its threshold, asking\n")
+			if pkg, ok := chooseResult(name, candidates); ok {
+				return ResolveResult{Name: name, Package: pkg, Strategy: "user", Confidence: 100, Found: true}, nil
+			}
+		}
+	case ModeStrict:
+		best := candidates[0]
+		r.explainf("  → failed: no candidate met its threshold in strict mode\n")
+		return unresolved, fmt.Errorf("could not resolve %s with enough confidence: best candidate %s (%d%%, %s)",
+			name, best.PackageName, best.Confidence, best.Description)
+	}
+
+	r.explainf("  → unresolved: no candidate met its threshold\n")
+	return unresolved, nil
+}
+
+// explainf writes a line of the resolution trace, if enabled
+func (r *Resolver) explainf(format string, args ...interface{}) {
+	if r.Explain != nil {
+		fmt.Fprintf(r.Explain, format, args...)
+	}
+}
+
+// explainSkipped notes the steps that were not run because an earlier one succeeded
+func (r *Resolver) explainSkipped(strategies []string) {
+	if len(strategies) > 0 {
+		r.explainf("  %-12s not run: %s\n", "", strings.Join(strategies, ", "))
+	}
+}
+
+// describe formats a candidate's description for the resolution trace
+func describe(result SearchResult) string {
+	if result.Description == "" {
+		return ""
+	}
+	return " - " + result.Description
+}
+
+// runStrategy runs one non-static resolution step, best results first
+func (r *Resolver) runStrategy(strategy, name, pkgManager string) []SearchResult {
+	switch strategy {
+	case StrategyLibrary:
+		return searchLibraryDB(name, pkgManager)
+	case StrategyPkgConfig:
+		return searchPkgConfig(name, pkgManager)
+	case StrategyFileSearch:
+		return platform.DeduplicateResults(searchFiles(name, pkgManager))
+	case StrategyNameSearch:
+		results, err := DynamicSearch(name, pkgManager)
+		if err != nil {
+			return nil
+		}
+		return results
+	}
+	return nil
+}
+
+// libraryPlatforms maps package managers to the platform names used by the library database
+var libraryPlatforms = map[string]string{
+	"apt":   "linux",
+	"brew":  "darwin",
+	"port":  "darwin",
+	"vcpkg": "windows",
+}
+
+// searchLibraryDB looks the dependency up in the known library database
+func searchLibraryDB(name, pkgManager string) []SearchResult {
+	platform, ok := libraryPlatforms[pkgManager]
+	if !ok {
+		return nil
+	}
+	lib, ok := analyzer.LookupLibrary(name)
+	if !ok {
+		return nil
+	}
+	pkg, ok := lib.Platforms[platform]
+	if !ok || pkg.PackageName == "" {
+		return nil
+	}
+	return []SearchResult{{PackageName: pkg.PackageName, Description: lib.Name, Confidence: 95}}
+}
+
+// searchPkgConfig finds an installed pkg-config module for the dependency
+// and asks the package manager which package owns its .pc file
+func searchPkgConfig(name, pkgManager string) []SearchResult {
+	if _, err := exec.LookPath("pkg-config"); err != nil {
+		return nil
+	}
+
+	for _, module := range []string{name, "lib" + name} {
+		if util.ParsedCommand("pkg-config", "--exists", module).Run() != nil {
+			continue
+		}
+		output, err := util.ParsedCommand("pkg-config", "--variable=pcfiledir", module).Output()
+		if err != nil {
+			continue
+		}
+		pcFile := filepath.Join(strings.TrimSpace(string(output)), module+".pc")
+		if pkg := packageOwningFile(pcFile, pkgManager); pkg != "" {
+			return []SearchResult{{PackageName: pkg, Description: "pkg-config module " + module, Confidence: 90}}
+		}
+	}
+	return nil
+}
+
+// packageOwningFile returns the installed package that owns a file
+func packageOwningFile(path, pkgManager string) string {
+	var cmd *util.Cmd
+	switch pkgManager {
+	case "apt":
+		cmd = util.ParsedCommand("dpkg", "-S", path)
+	case "dnf":
+		cmd = util.ParsedCommand("rpm", "-qf", "--qf", "%{NAME}", path)
+	case "pacman":
+		cmd = util.ParsedCommand("pacman", "-Qoq", path)
+	case "port":
+		// port provides prints "<path> is provided by: <port>"
+		output, err := util.ParsedCommand("port", "-q", "provides", path).Output()
+		if err != nil {
+			return ""
+		}
+		if _, owner, found := strings.Cut(string(output), "is provided by:"); found {
+			return strings.TrimSpace(owner)
+		}
+		return ""
+	case "brew":
+		// Homebrew paths look like <prefix>/Cellar/<formula>/<version>/lib/pkgconfig
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return ""
+		}
+		parts := strings.Split(filepath.ToSlash(resolved), "/")
+		for i, part := range parts {
+			if part == "Cellar" && i+1 < len(parts) {
+				return parts[i+1]
+			}
+		}
+		return ""
+	default:
+		return ""
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	owner := strings.TrimSpace(string(output))
+	// dpkg -S prints "package:arch: /path"
+	if pkgManager == "apt" {
+		owner = strings.Split(strings.SplitN(owner, ": ", 2)[0], ":")[0]
+	}
+	return owner
+}
+
+// parsePacmanFilesOutput parses pacman -F --machinereadable output, one
+// "repo\0package\0version\0path" line per matching file
+func parsePacmanFilesOutput(output, name string) []SearchResult {
+	var results []SearchResult
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		path := "/" + fields[3]
+		if confidence := platform.PathConfidence(path, name); confidence > 0 {
+			results = append(results, SearchResult{PackageName: fields[1], Description: "Provides " + path, Confidence: confidence})
+		}
+	}
+	return results
+}
+
+// searchFiles finds packages that ship the header using the package manager's file index
+func searchFiles(name, pkgManager string) []SearchResult {
+	switch pkgManager {
+	case "apt":
+		return platform.SearchAptFile(name)
+	case "dnf":
+		output, err := util.ParsedCommand("dnf", "repoquery", "-q", "--whatprovides", "*/include/"+name+".h", "--qf", "%{name}").Output()
+		if err != nil {
+			return nil
+		}
+		var results []SearchResult
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				results = append(results, SearchResult{PackageName: line, Description: "Provides " + name + ".h", Confidence: 95})
+			}
+		}
+		return results
+	case "pacman":
+		output, err := util.ParsedCommand("pacman", "-F", "--machinereadable", name+".h").Output()
+		if err != nil {
+			return nil
+		}
+		return parsePacmanFilesOutput(string(output), name)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package pkgdb
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Tool is a program run on the build machine during the build, such as a
+// build system or code generator. Tools are installed but never linked.
+type Tool struct {
+	Executables []string          // Names to look for in PATH, in order of preference
+	Packages    map[string]string // Package providing the tool, by package manager
+}
+
+// ToolDB maps the tool names listed under tools: in catalyst.yml to how
+// they are found and installed
+var ToolDB = map[string]Tool{
+	"cmake": {
+		Executables: []string{"cmake"},
+		Packages:    map[string]string{"winget": "Kitware.CMake"},
+	},
+	"ninja": {
+		Executables: []string{"ninja"},
+		Packages: map[string]string{
+			"apt":    "ninja-build",
+			"dnf":    "ninja-build",
+			"yum":    "ninja-build",
+			"winget": "Ninja-build.Ninja",
+		},
+	},
+	"make": {
+		Executables: []string{"make", "mingw32-make"},
+		Packages:    map[string]string{"winget": "GnuWin32.Make"},
+	},
+	"pkg-config": {
+		Executables: []string{"pkg-config", "pkgconf"},
+		Packages: map[string]string{
+			"dnf":    "pkgconf-pkg-config",
+			"yum":    "pkgconfig",
+			"pacman": "pkgconf",
+			"msys2":  "pkgconf",
+			"brew":   "pkgconf",
+			"port":   "pkgconfig",
+			"choco":  "pkgconfiglite",
+			"winget": "bloodrock.pkg-config-lite",
+		},
+	},
+	"bison": {
+		Executables: []string{"bison", "win_bison"},
+		Packages: map[string]string{
+			"choco":  "winflexbison3",
+			"winget": "WinFlexBison.win_flex_bison",
+			"scoop":  "winflexbison",
+		},
+	},
+	"flex": {
+		Executables: []string{"flex", "win_flex"},
+		Packages: map[string]string{
+			"choco":  "winflexbison3",
+			"winget": "WinFlexBison.win_flex_bison",
+			"scoop":  "winflexbison",
+		},
+	},
+	// Coverage reports of GCC builds: genhtml comes with lcov
+	"genhtml": {
+		Executables: []string{"genhtml"},
+		Packages: map[string]string{
+			"apt":    "lcov",
+			"dnf":    "lcov",
+			"yum":    "lcov",
+			"pacman": "lcov",
+			"zypper": "lcov",
+			"brew":   "lcov",
+			"port":   "lcov",
+		},
+	},
+	// Coverage reports of Clang builds
+	"llvm-cov": {
+		Executables: []string{"llvm-cov"},
+		Packages:    llvmPackages,
+	},
+	"llvm-profdata": {
+		Executables: []string{"llvm-profdata"},
+		Packages:    llvmPackages,
+	},
+	// Outside Windows windres comes with the MinGW cross binutils
+	"windres": {
+		Executables: []string{"windres", "x86_64-w64-mingw32-windres"},
+		Packages: map[string]string{
+			"apt":    "binutils-mingw-w64-x86-64",
+			"dnf":    "mingw64-binutils",
+			"yum":    "mingw64-binutils",
+			"pacman": "mingw-w64-binutils",
+			"zypper": "mingw64-cross-binutils",
+			"msys2":  "binutils",
+			"brew":   "mingw-w64",
+			"port":   "x86_64-w64-mingw32-binutils",
+			"choco":  "mingw",
+			"winget": "BrechtSanders.WinLibs.POSIX.UCRT",
+			"scoop":  "mingw",
+		},
+	},
+}
+
+// llvmPackages install the LLVM tools. Homebrew's llvm isn't linked into
+// PATH, and macOS has them in Xcode's toolchain anyway.
+var llvmPackages = map[string]string{
+	"apt":    "llvm",
+	"dnf":    "llvm",
+	"yum":    "llvm",
+	"pacman": "llvm",
+	"zypper": "llvm",
+	"msys2":  "llvm",
+	"choco":  "llvm",
+	"winget": "LLVM.LLVM",
+	"scoop":  "llvm",
+}
+
+// ToolPackage returns the package installing a tool with the package
+// manager. Tools without an entry are packaged under their own name.
+func ToolPackage(name, pkgManager string) string {
+	if pkg, ok := ToolDB[name].Packages[pkgManager]; ok {
+		return pkg
+	}
+	return name
+}
+
+// FindTool returns the path of the tool's executable, or "" if it's not in PATH
+func FindTool(name string) string {
+	executables := []string{name}
+	if tool, ok := ToolDB[name]; ok {
+		executables = tool.Executables
+	}
+	for _, executable := range executables {
+		if path, err := exec.LookPath(executable); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// GrammarTools are the lexer and parser generators, by grammar extension
+var GrammarTools = map[string]string{".l": "flex", ".y": "bison"}
+
+// DetectTools returns the known tools a project runs: the generators of its
+// flex and bison sources, and the programs its generator commands start
+func DetectTools(sources, commands []string) []string {
+	var tools []string
+	add := func(name string) {
+		if !slices.Contains(tools, name) {
+			tools = append(tools, name)
+		}
+	}
+	for _, src := range sources {
+		if tool, ok := GrammarTools[strings.ToLower(filepath.Ext(src))]; ok {
+			add(tool)
+		}
+	}
+	for _, command := range commands {
+		fields := strings.Fields(command)
+		if len(fields) == 0 {
+			continue
+		}
+		program := strings.TrimSuffix(filepath.Base(strings.Trim(fields[0], `"'`)), ".exe")
+		for name, tool := range ToolDB {
+			if slices.Contains(tool.Executables, program) {
+				add(name)
+			}
+		}
+	}
+	slices.Sort(tools)
+	return tools
+}
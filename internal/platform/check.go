@@ -1,104 +1,55 @@
 package platform
 
-import (
-	"bytes"
-	"io"
-	"os/exec"
-	"strings"
-)
-
 // IsPackageInstalled checks if a package is installed using the specified package manager
 // Returns true if the package is installed, false otherwise
 func IsPackageInstalled(pkgName string, pkgManager string) bool {
-	switch pkgManager {
-	case "apt":
-		return isInstalledApt(pkgName)
-	case "dnf":
-		return isInstalledDnf(pkgName)
-	case "pacman":
-		return isInstalledPacman(pkgName)
-	case "brew":
-		return isInstalledBrew(pkgName)
-	case "vcpkg":
-		return isInstalledVcpkg(pkgName)
-	case "choco":
-		return isInstalledChoco(pkgName)
-	default:
-		return false
-	}
-}
-
-// isInstalledApt checks if a package is installed using apt (Debian/Ubuntu)
-// Uses: dpkg -s <pkgName>
-func isInstalledApt(pkgName string) bool {
-	cmd := exec.Command("dpkg", "-s", pkgName)
-	cmd.Stdout = io.Discard
-	cmd.Stderr = io.Discard
-	return cmd.Run() == nil
+	_, ok := InstalledVersion(pkgName, pkgManager)
+	return ok
 }
 
-// isInstalledDnf checks if a package is installed using dnf (Fedora/RHEL)
-// Uses: dnf list installed <pkgName>
-func isInstalledDnf(pkgName string) bool {
-	cmd := exec.Command("dnf", "list", "installed", pkgName)
-	cmd.Stdout = io.Discard
-	cmd.Stderr = io.Discard
-	return cmd.Run() == nil
+// InstalledVersion returns the installed version of a package, if it is installed.
+// Packages only match by their exact name or ID, never by substring.
+func InstalledVersion(pkgName string, pkgManager string) (string, bool) {
+	pm, ok := LookupPackageManager(pkgManager)
+	if !ok {
+		return "", false
+	}
+	return pm.IsInstalled(pkgName)
 }
 
-// isInstalledPacman checks if a package is installed using pacman (Arch Linux)
-// Uses: pacman -Q <pkgName>
-func isInstalledPacman(pkgName string) bool {
-	cmd := exec.Command("pacman", "-Q", pkgName)
-	cmd.Stdout = io.Discard
-	cmd.Stderr = io.Discard
-	return cmd.Run() == nil
+// parseInstalledVersion finds the version of pkgName in the output of the
+// package manager's installed query
+func parseInstalledVersion(pkgName string, pkgManager string, output string) (string, bool) {
+	pm, ok := LookupPackageManager(pkgManager)
+	if !ok {
+		return "", false
+	}
+	version := pm.(*manager).parseInstalled(pkgName, output)
+	return version, version != ""
 }
 
-// isInstalledBrew checks if a package is installed using brew (darwin Homebrew)
-// Uses: brew list --formula | grep -q <pkgName>
-func isInstalledBrew(pkgName string) bool {
-	// First get the list of installed formulas
-	listCmd := exec.Command("brew", "list", "--formula")
-	var out bytes.Buffer
-	listCmd.Stdout = &out
-	listCmd.Stderr = io.Discard
-
-	if err := listCmd.Run(); err != nil {
-		return false
+// AvailableVersions lists the versions of a package the package manager can
+// install. ok is false when the manager can't be asked or the query failed.
+func AvailableVersions(pkgName string, pkgManager string) (versions []string, ok bool) {
+	pm, ok := LookupPackageManager(pkgManager)
+	if !ok {
+		return nil, false
 	}
-
-	// Check if the package name is in the output
-	installedPackages := strings.Split(out.String(), "\n")
-	for _, pkg := range installedPackages {
-		if strings.TrimSpace(pkg) == pkgName {
-			return true
-		}
+	lister, ok := pm.(interface {
+		AvailableVersions(pkg string) ([]string, bool)
+	})
+	if !ok {
+		return nil, false
 	}
-	return false
+	return lister.AvailableVersions(pkgName)
 }
 
-// isInstalledVcpkg checks if a package is installed using vcpkg (Windows)
-// Uses: vcpkg list <pkgName>
-func isInstalledVcpkg(pkgName string) bool {
-	cmd := exec.Command("vcpkg", "list", pkgName)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = io.Discard
-
-	if err := cmd.Run(); err != nil {
-		return false
+// parseAvailableVersions finds the versions of pkgName in the output of the
+// query AvailableVersions runs
+func parseAvailableVersions(pkgName string, pkgManager string, output string) []string {
+	pm, ok := LookupPackageManager(pkgManager)
+	if !ok || pm.(*manager).parseVersions == nil {
+		return nil
 	}
-
-	// Check if the output contains the package name
-	return strings.Contains(out.String(), pkgName)
-}
-
-// isInstalledChoco checks if a package is installed using choco (Windows Chocolatey)
-// Uses: choco list --local-only <pkgName>
-func isInstalledChoco(pkgName string) bool {
-	cmd := exec.Command("choco", "list", "--local-only", pkgName)
-	cmd.Stdout = io.Discard
-	cmd.Stderr = io.Discard
-	return cmd.Run() == nil
+	return pm.(*manager).parseVersions(pkgName, output)
 }
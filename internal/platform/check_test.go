@@ -0,0 +1,413 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+func TestParseInstalledVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		pkgManager string
+		pkg        string
+		output     string
+		want       string
+	}{
+		{"apt installed", "apt", "zlib1g-dev", "ii |1:1.2.13.dfsg-1ubuntu5", "1:1.2.13.dfsg-1ubuntu5"},
+		{"apt config files only", "apt", "libssl-dev", "rc |3.0.13-0ubuntu3", ""},
+		{"apt not installed", "apt", "libcurl4-openssl-dev", "un |", ""},
+		{"rpm installed", "dnf", "libcurl-devel", "libcurl-devel|8.2.1-4.fc39\n", "8.2.1-4.fc39"},
+		{"rpm missing", "dnf", "sqlite-devel", "package sqlite-devel is not installed\n", ""},
+		{"pacman", "pacman", "curl", "curl 8.5.0-1\n", "8.5.0-1"},
+		{"brew newest version", "brew", "openssl@3", "openssl@3 3.1.4 3.2.0\n", "3.2.0"},
+		{"port active", "port", "curl", "  curl @8.4.0_0+ssl\n  curl @8.5.0_0+ssl (active)\n", "8.5.0_0+ssl"},
+		{"vcpkg exact", "vcpkg", "zlib", "zlib-ng:x64-windows    2.1.5    zlib replacement\nzlib:x64-windows       1.3.1    A compression library\n", "1.3.1"},
+		{"vcpkg prefix only", "vcpkg", "zlib", "zlib-ng:x64-windows    2.1.5    zlib replacement\n", ""},
+		{"vcpkg features", "vcpkg", "curl", "curl[ssl]:arm64-windows    8.5.0    A library for transferring data\n", "8.5.0"},
+		{"choco", "choco", "sqlite", "sqlite|3.45.0\n", "3.45.0"},
+		{"choco other package", "choco", "sqlite", "sqlite.shell|3.45.0\n", ""},
+		{
+			"winget exact id", "winget", "Git.Git",
+			"Name Id      Version  Available Source\n-------------------------------------\nGit  Git.Git 2.43.0   2.44.0    winget\n",
+			"2.43.0",
+		},
+		{
+			"winget name with spaces", "winget", "Kitware.CMake",
+			"Name  Id            Version Source\n-----------------------------------\nCMake Kitware.CMake 3.28.1  winget\n",
+			"3.28.1",
+		},
+		{
+			"winget no substring match", "winget", "Git.Git",
+			"Name       Id             Version Source\n---------------------------------------\nGitHub CLI GitHub.cli     2.42.1  winget\nGit LFS    GitHub.GitLFS  3.4.1   winget\n",
+			"",
+		},
+		{"winget not installed", "winget", "SQLite.SQLite", "No installed package found matching input criteria.\n", ""},
+		{"scoop", "scoop", "gcc", "Installed apps matching 'gcc':\n\nName Version Source Updated             Info\n---- ------- ------ -------             ----\ngcc  13.2.0  main   2024-01-10 10:00:00\n", "13.2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseInstalledVersion(tt.pkg, tt.pkgManager, tt.output)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("parseInstalledVersion(%q, %q) = %q, %v, want %q", tt.pkg, tt.pkgManager, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAvailableVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		pkgManager string
+		pkg        string
+		output     string
+		want       []string
+	}{
+		{
+			"apt", "apt", "zlib1g-dev",
+			" zlib1g-dev | 1:1.3.dfsg-3.1ubuntu2 | http://archive.ubuntu.com/ubuntu noble/main amd64 Packages\n zlib1g-dev | 1:1.3.dfsg-3.1ubuntu2 | http://archive.ubuntu.com/ubuntu noble/main Sources\n",
+			[]string{"1:1.3.dfsg-3.1ubuntu2"},
+		},
+		{
+			"dnf drops epoch", "dnf", "openssl-devel",
+			"Available Packages\nopenssl-devel.x86_64  1:3.1.1-4.fc39  fedora\nopenssl-devel.x86_64  1:3.1.4-2.fc39  updates\n",
+			[]string{"3.1.1-4.fc39", "3.1.4-2.fc39"},
+		},
+		{
+			"zypper", "zypper", "libcurl-devel",
+			"S | Name          | Type       | Version    | Arch   | Repository\n--+---------------+------------+------------+--------+-----------\n  | libcurl-devel | package    | 8.6.0-1.1  | x86_64 | repo-oss\n  | libcurl-devel | srcpackage | 8.6.0-1.1  | noarch | repo-source\n",
+			[]string{"8.6.0-1.1"},
+		},
+		{"choco", "choco", "sqlite", "sqlite|3.46.0\nsqlite|3.45.0\nsqlite.shell|3.45.0\n", []string{"3.46.0", "3.45.0"}},
+		{
+			"winget", "winget", "Kitware.CMake",
+			"Found CMake [Kitware.CMake]\nVersion\n-------\n3.29.2\n3.28.1\n",
+			[]string{"3.29.2", "3.28.1"},
+		},
+	}
+	for _, tt := range tests {
+		got := parseAvailableVersions(tt.pkg, tt.pkgManager, tt.output)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: versions = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSupportReport(t *testing.T) {
+	s := Support{OS: "linux", Distro: "alpine", Libc: "musl", NativeManager: "apk"}
+	if s.FullySupported() {
+		t.Error("a host without a supported package manager is not fully supported")
+	}
+	report := s.Report([]string{"zlib1g-dev"})
+	for _, want := range []string{"alpine (linux, musl): apk is not supported", "Needed:       zlib1g-dev", "apk add <packages>", "docker run"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report is missing %q:\n%s", want, report)
+		}
+	}
+
+	if host := (Support{OS: "openbsd"}).Host(); host != "openbsd" {
+		t.Errorf("Host() = %q, want openbsd", host)
+	}
+
+	tests := []struct {
+		support       Support
+		full, missing bool
+		advice        string
+	}{
+		{Support{OS: "linux", PackageManager: "apt"}, true, false, ""},
+		{Support{OS: "darwin", PackageManager: "brew"}, true, false, ""},
+		{Support{OS: "darwin"}, false, true, "Install Homebrew"},
+		{Support{OS: "windows"}, false, true, "winget"},
+		{Support{OS: "linux", NativeManager: "apk"}, false, false, "docker run"},
+		{Support{OS: "openbsd", NativeManager: "pkg_add"}, false, false, "docker run"},
+		{Support{OS: "plan9", PackageManager: "apt"}, false, false, "docker run"},
+	}
+	for _, tt := range tests {
+		if got := tt.support.FullySupported(); got != tt.full {
+			t.Errorf("%+v: FullySupported() = %v, want %v", tt.support, got, tt.full)
+		}
+		if got := tt.support.MissingPackageManager(); got != tt.missing {
+			t.Errorf("%+v: MissingPackageManager() = %v, want %v", tt.support, got, tt.missing)
+		}
+		if report := tt.support.Report(nil); tt.advice != "" && !strings.Contains(report, tt.advice) {
+			t.Errorf("%+v: report is missing %q:\n%s", tt.support, tt.advice, report)
+		}
+	}
+	if report := (Support{OS: "darwin"}).Report(nil); strings.Contains(report, "docker") {
+		t.Errorf("a Mac without Homebrew is told to use a Linux container:\n%s", report)
+	}
+}
+
+func TestMSYS2(t *testing.T) {
+	output := "\r\nHKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Uninstall\\{7e8c2f5a}\r\n" +
+		"    InstallLocation    REG_SZ    C:\\msys64\r\n\r\n" +
+		"HKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Uninstall\\Git_is1\r\n" +
+		"    InstallLocation    REG_SZ    C:\\Program Files\\Git\\\r\n\r\nEnd of search: 2 match(es) found.\r\n"
+	got := parseRegistryInstallLocations(output)
+	if want := []string{`C:\msys64`, `C:\Program Files\Git\`}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("install locations = %q, want %q", got, want)
+	}
+
+	// Package names reach bash -lc quoted, so they can't run commands
+	command := pacmanCommand([]string{"-S", "--noconfirm", "zlib; rm -rf ~", "it's"})
+	if want := `pacman '-S' '--noconfirm' 'zlib; rm -rf ~' 'it'\''s'`; command != want {
+		t.Errorf("pacmanCommand() = %s, want %s", command, want)
+	}
+
+	t.Setenv("MSYSTEM", "")
+	PreferredMSYS2Environment = "CLANG64"
+	defer func() { PreferredMSYS2Environment = "" }()
+	if env := MSYS2Environment(); env != "clang64" {
+		t.Errorf("MSYS2Environment() = %q, want clang64", env)
+	}
+	t.Setenv("MSYSTEM", "MINGW64")
+	if env := MSYS2Environment(); env != "mingw64" {
+		t.Errorf("MSYS2Environment() with MSYSTEM=MINGW64 = %q, want mingw64", env)
+	}
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake commands are shell scripts")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	if _, err := DetectPackageManager("linux"); err == nil || !strings.Contains(err.Error(), "apt, dnf, yum, pacman, zypper") {
+		t.Errorf("DetectPackageManager() without managers = %v, want the checked list", err)
+	}
+
+	for _, c := range []struct{ command, want string }{
+		{"zypper", "zypper"},
+		{"yum", "yum"},
+		{"apt-get", "apt"},
+	} {
+		if err := os.WriteFile(filepath.Join(bin, c.command), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := DetectPackageManager("linux"); err != nil || got != c.want {
+			t.Errorf("DetectPackageManager() with %s = %q, %v, want %s", c.command, got, err, c.want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	saved := registry
+	defer func() { registry = saved }()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake commands are shell scripts")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+
+	Register(&manager{
+		name: "apk", os: "linux",
+		install: run("sudo", "apk", "add"),
+		batch:   true,
+	})
+	if pm, ok := LookupPackageManager("apk"); !ok || pm.OS() != "linux" || PackageManagerOS("apk") != "linux" {
+		t.Fatalf("LookupPackageManager(apk) = %v, %v", pm, ok)
+	}
+	if names := PackageManagers("linux"); names[len(names)-1].Name() != "apk" {
+		t.Errorf("apk isn't last in the linux preference order")
+	}
+	if !SupportsBatchInstall("apk") || SupportsBatchInstall("winget") {
+		t.Error("SupportsBatchInstall() doesn't follow the registrations")
+	}
+
+	if err := os.WriteFile(filepath.Join(bin, "apk"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DetectPackageManager("linux"); err != nil || got != "apk" {
+		t.Errorf("DetectPackageManager() with apk = %q, %v, want apk", got, err)
+	}
+	pm, _ := LookupPackageManager("apk")
+	if cmd, err := pm.Install("zlib-dev", "curl-dev"); err != nil || strings.Join(cmd.Args, " ") != "sudo apk add zlib-dev curl-dev" {
+		t.Errorf("Install() = %v, %v", cmd, err)
+	}
+	if _, err := pm.Search("zlib"); err == nil {
+		t.Error("Search() without a search command should fail")
+	}
+}
+
+func TestBrewPrefix(t *testing.T) {
+	prefix := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+
+	tests := []struct{ flag, want string }{
+		{"-I" + prefix + "/include", "-I${HOMEBREW_PREFIX}/include"},
+		{"-L" + prefix + "/Cellar/openssl@3/3.2.0/lib", "-L${HOMEBREW_PREFIX}/opt/openssl@3/lib"},
+		{"-Wl,-rpath," + prefix + "/opt/libomp/lib", "-Wl,-rpath,${HOMEBREW_PREFIX}/opt/libomp/lib"},
+		{"-I" + prefix + "x/include", "-I" + prefix + "x/include"},
+		{"-lssl", "-lssl"},
+	}
+	for _, tt := range tests {
+		got := AbstractBrewPrefix(tt.flag)
+		if got != tt.want {
+			t.Errorf("AbstractBrewPrefix(%q) = %q, want %q", tt.flag, got, tt.want)
+		}
+		if tt.flag != tt.want && !strings.Contains(tt.flag, "/Cellar/") && ExpandBrewPrefix(got) != tt.flag {
+			t.Errorf("ExpandBrewPrefix(%q) = %q, want %q", got, ExpandBrewPrefix(got), tt.flag)
+		}
+	}
+}
+
+func TestInstalledCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake commands are shell scripts")
+	}
+	t.Setenv("HOME", t.TempDir())
+	installedState = nil
+	defer func() { installedState = nil }()
+
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho x >> " + calls + "\necho \"$1 1.3.1-1\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "fakepm"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	db := filepath.Join(dir, "status")
+	if err := os.WriteFile(db, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := &manager{
+		name: "fakepm", os: "linux",
+		installed: func(pkg string) *util.Cmd {
+			return util.ParsedCommand(filepath.Join(dir, "fakepm"), pkg)
+		},
+		parseInstalled: parsePacmanQuery,
+		database:       files(db),
+	}
+	queries := func() int {
+		data, _ := os.ReadFile(calls)
+		return strings.Count(string(data), "x")
+	}
+
+	for i := 0; i < 2; i++ {
+		if version, ok := m.IsInstalled("zlib"); !ok || version != "1.3.1-1" {
+			t.Fatalf("IsInstalled(zlib) = %q, %v", version, ok)
+		}
+	}
+	if n := queries(); n != 1 {
+		t.Errorf("package manager asked %d times, want once while the database is unchanged", n)
+	}
+
+	// A package installed outside catalyst changes the database
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(db, later, later); err != nil {
+		t.Fatal(err)
+	}
+	m.IsInstalled("zlib")
+	if n := queries(); n != 2 {
+		t.Errorf("package manager asked %d times, want again after the database changed", n)
+	}
+
+	// The cache is kept across runs
+	installedState = nil
+	m.IsInstalled("zlib")
+	if n := queries(); n != 2 {
+		t.Errorf("package manager asked %d times, want the saved answer", n)
+	}
+}
+
+func TestParseSearchOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		parse  func(output, headerName string) []SearchResult
+		header string
+		output string
+		want   []SearchResult
+	}{
+		{
+			"apt-cache search", parseAptSearchOutput, "png",
+			"libpng-dev - PNG library - development (version 1.6)\n" +
+				"libpng-tools - PNG library - tools (version 1.6)\n" +
+				"libpng16-16t64 - PNG library - runtime (version 1.6)\n" +
+				"\n",
+			[]SearchResult{
+				{"libpng-dev", "PNG library - development (version 1.6)", 80},
+				{"libpng-tools", "PNG library - tools (version 1.6)", 80},
+				{"libpng16-16t64", "PNG library - runtime (version 1.6)", 80},
+			},
+		},
+		{
+			"apt-file search", parseAptFileOutput, "png",
+			"libpng-dev: /usr/include/libpng16/png.h\n" +
+				"libpng-dev: /usr/include/png.h\n",
+			[]SearchResult{
+				{"libpng-dev", "Provides /usr/include/libpng16/png.h", PathConfidence("/usr/include/libpng16/png.h", "png")},
+				{"libpng-dev", "Provides /usr/include/png.h", PathConfidence("/usr/include/png.h", "png")},
+			},
+		},
+		{
+			"dnf repoquery", parseDnfOutput, "sqlite",
+			"sqlite-devel\tDevelopment tools for the sqlite3 embeddable SQL database engine\n" +
+				"sqlite\tLibrary that implements an embeddable SQL database engine\n",
+			[]SearchResult{
+				{"sqlite-devel", "Development tools for the sqlite3 embeddable SQL database engine", 80},
+				{"sqlite", "Library that implements an embeddable SQL database engine", 100},
+			},
+		},
+		{
+			"pacman -Ssq", parsePacmanOutput, "curl",
+			"curl\nlibcurl-compat\nlibcurl-gnutls\n",
+			[]SearchResult{
+				{"curl", "pacman package", 100},
+				{"libcurl-compat", "pacman package", 80},
+				{"libcurl-gnutls", "pacman package", 80},
+			},
+		},
+		{
+			"brew search", parseBrewOutput, "png",
+			"==> Formulae\nlibpng\npngcheck\npngquant\n",
+			[]SearchResult{
+				{"libpng", "Homebrew formula", 80},
+				{"pngcheck", "Homebrew formula", 80},
+				{"pngquant", "Homebrew formula", 80},
+			},
+		},
+		{
+			"port search", parsePortOutput, "jpeg",
+			"jpeg      9f   graphics     Library for manipulating JPEG images\n" +
+				"libjpeg-turbo 3.0.3 graphics SIMD-accelerated libjpeg-compatible JPEG codec library\n",
+			[]SearchResult{
+				{"jpeg", "Library for manipulating JPEG images", 100},
+				{"libjpeg-turbo", "SIMD-accelerated libjpeg-compatible JPEG codec library", 80},
+			},
+		},
+		{
+			"vcpkg search", parseVcpkgOutput, "zlib",
+			"zlib                     1.3.1            A compression library\n" +
+				"zlib-ng                  2.2.1            zlib replacement with optimizations for \"next generation\" systems.\n",
+			[]SearchResult{
+				{"zlib", "1.3.1 A compression library", 100},
+				{"zlib-ng", "2.2.1 zlib replacement with optimizations for \"next generation\" systems.", 80},
+			},
+		},
+		{
+			"choco search", parseChocoOutput, "openssl",
+			"openssl|3.3.1\nopenssl.light|3.1.4\n",
+			[]SearchResult{
+				{"openssl", "Chocolatey package 3.3.1", 100},
+				{"openssl.light", "Chocolatey package 3.1.4", 80},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := tt.parse(tt.output, tt.header)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: results = %+v, want %+v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: result %d = %+v, want %+v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -2,8 +2,10 @@ package platform
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 )
 
 // DetectOS detects the host operating system and returns a normalized string
@@ -21,44 +23,79 @@ func DetectOS() string {
 	}
 }
 
-// DetectPackageManager detects the available package manager for the given OS
-// It checks for package managers in order of preference and returns the first one found
-func DetectPackageManager(os string) (string, error) {
-	switch os {
-	case "linux":
-		// Check for apt (Debian/Ubuntu)
-		if _, err := exec.LookPath("apt"); err == nil {
-			return "apt", nil
+// DetectArch returns the host CPU architecture in GOARCH form ("amd64",
+// "arm64", ...). On Windows the processor environment variables are checked
+// too, so an x64 build of catalyst running under emulation on an ARM64
+// laptop still reports arm64.
+func DetectArch() string {
+	if runtime.GOOS == "windows" {
+		for _, env := range []string{"PROCESSOR_ARCHITEW6432", "PROCESSOR_ARCHITECTURE"} {
+			if strings.EqualFold(os.Getenv(env), "ARM64") {
+				return "arm64"
+			}
 		}
-		// Check for dnf (Fedora/RHEL)
-		if _, err := exec.LookPath("dnf"); err == nil {
-			return "dnf", nil
+		// Emulated x64 processes see AMD64 above, but the identifier still
+		// names the real CPU (e.g. "ARMv8 (64-bit) Family 8 ...")
+		if strings.HasPrefix(strings.ToUpper(os.Getenv("PROCESSOR_IDENTIFIER")), "ARM") {
+			return "arm64"
 		}
-		// Check for pacman (Arch Linux)
-		if _, err := exec.LookPath("pacman"); err == nil {
-			return "pacman", nil
-		}
-		return "", fmt.Errorf("no supported package manager found on Linux (checked: apt, dnf, pacman)")
+	}
+	return runtime.GOARCH
+}
 
-	case "darwin":
-		// Check for brew (Homebrew)
-		if _, err := exec.LookPath("brew"); err == nil {
-			return "brew", nil
+// PackageManagerOS returns the OS a package manager installs packages for
+// ("linux", "darwin" or "windows"), or "" for unknown managers
+func PackageManagerOS(pkgManager string) string {
+	if pm, ok := LookupPackageManager(pkgManager); ok {
+		return pm.OS()
+	}
+	return ""
+}
+
+// PreferredMacOSManager is the macOS package manager to use when both
+// Homebrew and MacPorts are installed ("brew" or "port"; empty prefers brew)
+var PreferredMacOSManager string
+
+// MacOSPackageManager returns the installed macOS package manager, "brew" or
+// "port", honoring PreferredMacOSManager. Returns "" if neither is installed.
+func MacOSPackageManager() string {
+	candidates := []string{"brew", "port"}
+	if PreferredMacOSManager == "port" {
+		candidates = []string{"port", "brew"}
+	}
+	for _, pkgManager := range candidates {
+		if _, err := exec.LookPath(pkgManager); err == nil {
+			return pkgManager
 		}
-		return "", fmt.Errorf("homebrew not found on darwin")
+	}
+	return ""
+}
 
-	case "windows":
-		// Check for vcpkg
-		if _, err := exec.LookPath("vcpkg"); err == nil {
-			return "vcpkg", nil
+// DetectPackageManager detects the available package manager for the given OS
+// It checks for package managers in order of preference and returns the first one found
+func DetectPackageManager(osName string) (string, error) {
+	switch osName {
+	case "linux", "windows":
+		// Inside an MSYS2 shell its pacman is the natural choice
+		if osName == "windows" && os.Getenv("MSYSTEM") != "" && MSYS2Root() != "" {
+			return "msys2", nil
+		}
+		var checked []string
+		for _, pm := range PackageManagers(osName) {
+			if pm.Detect() {
+				return pm.Name(), nil
+			}
+			checked = append(checked, pm.Name())
 		}
-		// Check for choco (Chocolatey)
-		if _, err := exec.LookPath("choco"); err == nil {
-			return "choco", nil
+		return "", fmt.Errorf("no supported package manager found on %s (checked: %s)", osName, strings.Join(checked, ", "))
+
+	case "darwin":
+		if pkgManager := MacOSPackageManager(); pkgManager != "" {
+			return pkgManager, nil
 		}
-		return "", fmt.Errorf("no supported package manager found on Windows (checked: vcpkg, choco)")
+		return "", fmt.Errorf("no supported package manager found on darwin (checked: brew, port)")
 
 	default:
-		return "", fmt.Errorf("unsupported operating system: %s", os)
+		return "", fmt.Errorf("unsupported operating system: %s", osName)
 	}
 }
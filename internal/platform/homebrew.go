@@ -0,0 +1,113 @@
+package platform
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// BrewPrefixVariable stands for the Homebrew prefix in catalyst.yml flags,
+// which is /opt/homebrew on Apple Silicon, /usr/local on Intel Macs and
+// anywhere for custom installs
+const BrewPrefixVariable = "${HOMEBREW_PREFIX}"
+
+var (
+	brewMu       sync.Mutex
+	brewAsked    bool
+	brewPrefix   string
+	brewFormulas = make(map[string]string)
+)
+
+// BrewPrefix returns the Homebrew prefix, or "" if Homebrew isn't installed.
+// HOMEBREW_PREFIX, which brew shellenv sets, is checked first, then brew
+// --prefix is asked once per run.
+func BrewPrefix() string {
+	if prefix := os.Getenv("HOMEBREW_PREFIX"); prefix != "" && isDir(prefix) {
+		return prefix
+	}
+	brewMu.Lock()
+	defer brewMu.Unlock()
+	if !brewAsked {
+		brewPrefix, brewAsked = askBrewPrefix(), true
+	}
+	return brewPrefix
+}
+
+// BrewFormulaPrefix returns the opt prefix of an installed formula (e.g.
+// /opt/homebrew/opt/libomp), or "" if it isn't installed. Found prefixes
+// are remembered for the run.
+func BrewFormulaPrefix(formula string) string {
+	brewMu.Lock()
+	defer brewMu.Unlock()
+	if prefix, ok := brewFormulas[formula]; ok {
+		return prefix
+	}
+	prefix := askBrewPrefix(formula)
+	if prefix != "" {
+		brewFormulas[formula] = prefix
+	}
+	return prefix
+}
+
+// askBrewPrefix runs brew --prefix, returning "" unless it names a directory
+func askBrewPrefix(args ...string) string {
+	output, err := util.ParsedCommand("brew", append([]string{"--prefix"}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	prefix := strings.TrimSpace(string(output))
+	if !isDir(prefix) {
+		return ""
+	}
+	return prefix
+}
+
+// ExpandBrewPrefix replaces BrewPrefixVariable in a flag with the Homebrew
+// prefix of this machine. Flags are returned unchanged when Homebrew isn't
+// installed.
+func ExpandBrewPrefix(flag string) string {
+	if !strings.Contains(flag, BrewPrefixVariable) {
+		return flag
+	}
+	prefix := BrewPrefix()
+	if prefix == "" {
+		return flag
+	}
+	return strings.ReplaceAll(flag, BrewPrefixVariable, prefix)
+}
+
+// AbstractBrewPrefix rewrites a path under this machine's Homebrew prefix in
+// a flag to use BrewPrefixVariable, so configs written here work on other
+// Macs. Versioned Cellar paths become the formula's opt link
+// (${HOMEBREW_PREFIX}/opt/openssl@3/include), which survives upgrades.
+func AbstractBrewPrefix(flag string) string {
+	prefix := BrewPrefix()
+	if prefix == "" {
+		return flag
+	}
+	i := strings.Index(flag, prefix)
+	if i < 0 {
+		return flag
+	}
+	rest := flag[i+len(prefix):]
+	if rest != "" && rest[0] != '/' {
+		return flag // e.g. /usr/localfoo
+	}
+	// Cellar/<formula>/<version>/...
+	if cellar, ok := strings.CutPrefix(rest, "/Cellar/"); ok {
+		if parts := strings.SplitN(cellar, "/", 3); len(parts) >= 2 {
+			rest = "/opt/" + parts[0]
+			if len(parts) == 3 {
+				rest += "/" + parts[2]
+			}
+		}
+	}
+	return flag[:i] + BrewPrefixVariable + rest
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,248 @@
+package platform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Redetect ignores the cached installed packages and asks the package
+// managers again
+var Redetect bool
+
+// installedCache remembers which packages are installed and their versions,
+// so builds don't ask the package manager about every dependency on every
+// run. A manager's entries are only valid for the state of its package
+// database they were recorded with: the fingerprint covers the modification
+// times of the files the manager changes when packages are installed or
+// removed, by catalyst or anything else.
+type installedCache struct {
+	Managers map[string]*managerCache `json:"managers"`
+}
+
+// managerCache is the installed packages of one package manager
+type managerCache struct {
+	Fingerprint string            `json:"fingerprint"`
+	Packages    map[string]string `json:"packages"` // Installed version; "" if not installed
+}
+
+var (
+	installedMu    sync.Mutex
+	installedState *installedCache
+)
+
+// installedCachePath returns where the cache is kept (~/.catalyst/packages.json)
+func installedCachePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".catalyst", "packages.json"), nil
+}
+
+// databaseFingerprint identifies the state of a manager's package database,
+// or is "" if the manager has none catalyst can watch
+func databaseFingerprint(m *manager) string {
+	if m.database == nil {
+		return ""
+	}
+	h := sha256.New()
+	found := false
+	for _, path := range m.database() {
+		if info, err := os.Stat(path); err == nil {
+			found = true
+			h.Write([]byte(path + " " + info.ModTime().UTC().Format(time.RFC3339Nano) + "\n"))
+		}
+	}
+	if !found {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PackagesFingerprint identifies the state of the package database of the
+// named package manager, so caches of what's installed can tell when it
+// changed. It is "" if the manager has none catalyst can watch.
+func PackagesFingerprint(name string) string {
+	pm, ok := LookupPackageManager(name)
+	if !ok {
+		return ""
+	}
+	m, ok := pm.(*manager)
+	if !ok {
+		return ""
+	}
+	return databaseFingerprint(m)
+}
+
+// loadInstalledCache returns the cache, reading it on first use. Callers
+// hold installedMu.
+func loadInstalledCache() *installedCache {
+	if installedState != nil {
+		return installedState
+	}
+	installedState = &installedCache{Managers: make(map[string]*managerCache)}
+	if Redetect {
+		return installedState
+	}
+	path, err := installedCachePath()
+	if err != nil {
+		return installedState
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return installedState
+	}
+	var cached installedCache
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Managers == nil {
+		return installedState
+	}
+	installedState = &cached
+	return installedState
+}
+
+// cachedInstalled returns the recorded installed version of a package ("" if
+// it wasn't installed). ok is false if nothing is recorded or the package
+// database changed since.
+func cachedInstalled(m *manager, pkg string) (version string, ok bool) {
+	fingerprint := databaseFingerprint(m)
+	if fingerprint == "" {
+		return "", false
+	}
+	installedMu.Lock()
+	defer installedMu.Unlock()
+	entry := loadInstalledCache().Managers[m.name]
+	if entry == nil || entry.Fingerprint != fingerprint {
+		return "", false
+	}
+	version, ok = entry.Packages[pkg]
+	return version, ok
+}
+
+// cacheInstalled records the installed version of a package. Entries of an
+// older package database are dropped. Failing to save the cache is not an
+// error, the next run just asks again.
+func cacheInstalled(m *manager, pkg, version string) {
+	fingerprint := databaseFingerprint(m)
+	if fingerprint == "" {
+		return
+	}
+	installedMu.Lock()
+	defer installedMu.Unlock()
+	cache := loadInstalledCache()
+	entry := cache.Managers[m.name]
+	if entry == nil || entry.Fingerprint != fingerprint {
+		if entry != nil {
+			log.Debugf("%s packages changed since they were cached, asking %s again\n", m.name, m.name)
+		}
+		entry = &managerCache{Fingerprint: fingerprint, Packages: make(map[string]string)}
+		cache.Managers[m.name] = entry
+	}
+	entry.Packages[pkg] = version
+
+	path, err := installedCachePath()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := util.WriteFileAtomic(path, data, 0644); err != nil {
+		log.Debugf("Failed to save the installed packages cache: %v\n", err)
+	}
+}
+
+// files returns a database function for fixed paths
+func files(paths ...string) func() []string {
+	return func() []string { return paths }
+}
+
+// rpmDatabase is the rpm database of dnf, yum and zypper: the directory,
+// which older rpm versions keep in /var/lib and newer ones in
+// /usr/lib/sysimage, and the SQLite or Berkeley DB file in it
+func rpmDatabase() []string {
+	var paths []string
+	for _, dir := range []string{"/var/lib/rpm", "/usr/lib/sysimage/rpm"} {
+		paths = append(paths, dir, filepath.Join(dir, "rpmdb.sqlite"), filepath.Join(dir, "Packages"))
+	}
+	return paths
+}
+
+// brewDatabase is the Cellar, which gets a directory per installed formula,
+// and opt, whose links are replaced when a formula is upgraded
+func brewDatabase() []string {
+	prefix := BrewPrefix()
+	if prefix == "" {
+		return nil
+	}
+	return []string{filepath.Join(prefix, "Cellar"), filepath.Join(prefix, "opt")}
+}
+
+// chocoDatabase is the directory Chocolatey installs packages into
+func chocoDatabase() []string {
+	root := os.Getenv("ChocolateyInstall")
+	if root == "" {
+		root = filepath.Join(os.Getenv("ProgramData"), "chocolatey")
+	}
+	return []string{filepath.Join(root, "lib")}
+}
+
+// scoopDatabase is the directory Scoop installs apps into
+func scoopDatabase() []string {
+	root := os.Getenv("SCOOP")
+	if root == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		root = filepath.Join(home, "scoop")
+	}
+	return []string{filepath.Join(root, "apps")}
+}
+
+// msys2Database is the local database of MSYS2's pacman
+func msys2Database() []string {
+	root := MSYS2Root()
+	if root == "" {
+		return nil
+	}
+	return []string{filepath.Join(root, "var", "lib", "pacman", "local")}
+}
+
+// vcpkgDatabase is the status file of the vcpkg installed tree and the
+// updates vcpkg appends before folding them into it
+func vcpkgDatabase() []string {
+	root := VcpkgRoot()
+	if root == "" {
+		return nil
+	}
+	status := filepath.Join(root, "installed", "vcpkg")
+	return []string{filepath.Join(status, "status"), filepath.Join(status, "updates")}
+}
+
+// VcpkgRoot returns the vcpkg installation: VCPKG_ROOT, or the directory of
+// the vcpkg executable in PATH
+func VcpkgRoot() string {
+	if root := os.Getenv("VCPKG_ROOT"); root != "" {
+		return root
+	}
+	if path, err := exec.LookPath("vcpkg"); err == nil {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		return filepath.Dir(path)
+	}
+	return ""
+}
@@ -0,0 +1,203 @@
+package platform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// PackageManager is a system package manager catalyst installs packages with
+type PackageManager interface {
+	Name() string // Name used in catalyst.yml and the package database, e.g. "apt"
+	OS() string   // OS it installs packages for ("linux", "darwin" or "windows")
+	// Detect reports whether the package manager is installed
+	Detect() bool
+	// Install returns the command installing the packages
+	Install(pkgs ...string) (*util.Cmd, error)
+	// IsInstalled returns the installed version of a package. Packages only
+	// match by their exact name or ID, never by substring.
+	IsInstalled(pkg string) (version string, ok bool)
+	// Search returns the packages whose names match a library name
+	Search(name string) ([]SearchResult, error)
+	// Update returns the command refreshing the package database, or nil if
+	// the package manager refreshes it itself
+	Update() (*util.Cmd, error)
+}
+
+// registry holds the registered package managers in order of preference
+var registry []PackageManager
+
+// Register adds a package manager. Managers for the same OS are detected in
+// the order they're registered.
+func Register(pm PackageManager) {
+	registry = slices.DeleteFunc(registry, func(existing PackageManager) bool {
+		return existing.Name() == pm.Name()
+	})
+	registry = append(registry, pm)
+}
+
+// LookupPackageManager returns the registered package manager with the name
+func LookupPackageManager(name string) (PackageManager, bool) {
+	for _, pm := range registry {
+		if pm.Name() == name {
+			return pm, true
+		}
+	}
+	return nil, false
+}
+
+// PackageManagers returns the package managers registered for an OS, in
+// order of preference
+func PackageManagers(osName string) []PackageManager {
+	var managers []PackageManager
+	for _, pm := range registry {
+		if pm.OS() == osName {
+			managers = append(managers, pm)
+		}
+	}
+	return managers
+}
+
+// SupportsBatchInstall reports whether the package manager installs several
+// packages with one command reliably enough to do so
+func SupportsBatchInstall(name string) bool {
+	pm, ok := LookupPackageManager(name)
+	if !ok {
+		return false
+	}
+	batch, ok := pm.(interface{ Batch() bool })
+	return ok && batch.Batch()
+}
+
+// manager is a PackageManager described by the commands it runs
+type manager struct {
+	name, os string
+	// command is the executable Detect looks for in PATH; the name if empty
+	command string
+	// detect replaces looking up command
+	detect func() bool
+	// install and update return the command installing pkgs and refreshing
+	// the package database (nil if it refreshes itself)
+	install func(pkgs []string) (*util.Cmd, error)
+	update  func() (*util.Cmd, error)
+	batch   bool
+	// installed returns the command listing an installed package, and
+	// parseInstalled finds its version in the output
+	installed      func(pkg string) *util.Cmd
+	parseInstalled func(pkg, output string) string
+	// database returns the files and directories the manager changes when
+	// packages are installed or removed; installed queries are cached
+	// until one of them changes. nil if there are none to watch.
+	database func() []string
+	// versions returns the command listing the versions of a package that
+	// can be installed, and parseVersions finds them in the output
+	versions      func(pkg string) *util.Cmd
+	parseVersions func(pkg, output string) []string
+	search        func(name string) ([]SearchResult, error)
+	setup         func() error
+}
+
+func (m *manager) Name() string { return m.name }
+func (m *manager) OS() string   { return m.os }
+func (m *manager) Batch() bool  { return m.batch }
+
+func (m *manager) Detect() bool {
+	if m.detect != nil {
+		return m.detect()
+	}
+	command := m.command
+	if command == "" {
+		command = m.name
+	}
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
+func (m *manager) Install(pkgs ...string) (*util.Cmd, error) {
+	return m.install(pkgs)
+}
+
+func (m *manager) Update() (*util.Cmd, error) {
+	if m.update == nil {
+		return nil, nil
+	}
+	return m.update()
+}
+
+func (m *manager) IsInstalled(pkg string) (string, bool) {
+	if m.installed == nil {
+		return "", false
+	}
+	if version, ok := cachedInstalled(m, pkg); ok {
+		return version, version != ""
+	}
+	version := m.queryInstalled(pkg)
+	cacheInstalled(m, pkg, version)
+	return version, version != ""
+}
+
+// queryInstalled asks the package manager for the installed version of a
+// package, "" if it isn't installed
+func (m *manager) queryInstalled(pkg string) string {
+	cmd := m.installed(pkg)
+	if cmd == nil {
+		return ""
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = io.Discard
+	// Most managers exit non-zero for missing packages, but winget and choco
+	// don't always, so the output decides
+	_ = cmd.Run()
+
+	return m.parseInstalled(pkg, out.String())
+}
+
+func (m *manager) Search(name string) ([]SearchResult, error) {
+	if m.search == nil {
+		return nil, fmt.Errorf("searching is not supported with %s", m.name)
+	}
+	return m.search(name)
+}
+
+// AvailableVersions lists the versions of a package that can be installed.
+// ok is false when the manager can't be asked or the query failed.
+func (m *manager) AvailableVersions(pkg string) ([]string, bool) {
+	if m.versions == nil {
+		return nil, false
+	}
+	output, err := m.versions(pkg).Output()
+	if err != nil && len(output) == 0 {
+		return nil, false
+	}
+	return m.parseVersions(pkg, string(output)), true
+}
+
+func (m *manager) Setup() error {
+	if m.setup != nil {
+		return m.setup()
+	}
+	if !m.Detect() {
+		return fmt.Errorf("%s not found", m.name)
+	}
+	return nil
+}
+
+// run returns an install or update function running argv with the packages
+// appended
+func run(argv ...string) func(pkgs []string) (*util.Cmd, error) {
+	return func(pkgs []string) (*util.Cmd, error) {
+		args := append(slices.Clone(argv[1:]), pkgs...)
+		return util.SystemCommand(argv[0], args...), nil
+	}
+}
+
+// noPackages adapts an install function to an update function
+func noPackages(fn func(pkgs []string) (*util.Cmd, error)) func() (*util.Cmd, error) {
+	return func() (*util.Cmd, error) { return fn(nil) }
+}
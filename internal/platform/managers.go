@@ -0,0 +1,435 @@
+package platform
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// The supported package managers, in order of preference on each OS. An
+// entry here covers detecting, installing, version queries and search; the
+// rest still switches on the manager's name, so a new package manager may
+// also need a case in:
+//   - install: mapPackageName and mapToWindowsPackage (package names),
+//     pinnedPackageSpecs (catalyst.lock pins), repos.go (taps, PPAs and
+//     buckets), packageFileList and InstallToPrefix (isolated installs) and
+//     deptree.go (dependency trees)
+//   - pkgdb: packageOwningFile and searchFiles (header resolution)
+func init() {
+	// Linux
+	Register(&manager{
+		name: "apt", os: "linux",
+		// apt is found through apt-get, which every apt system has
+		command: "apt-get",
+		// apt-get rather than apt, whose output isn't meant for scripts
+		install: run("sudo", "apt-get", "install", "-y"),
+		update:  noPackages(run("sudo", "apt-get", "update")),
+		batch:   true,
+		installed: func(pkg string) *util.Cmd {
+			// dpkg -s also succeeds for removed packages whose config files remain
+			return util.ParsedCommand("dpkg-query", "-W", "-f=${db:Status-Abbrev}|${Version}", pkg)
+		},
+		parseInstalled: parseDpkgQuery,
+		database:       files("/var/lib/dpkg/status"),
+		versions: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("apt-cache", "madison", pkg)
+		},
+		parseVersions: parseAptMadison,
+		search:        searchApt,
+		setup:         setupApt,
+	})
+	Register(&manager{
+		name: "dnf", os: "linux",
+		install:        run("sudo", "dnf", "install", "-y"),
+		update:         noPackages(run("sudo", "dnf", "makecache")),
+		batch:          true,
+		installed:      rpmQuery,
+		parseInstalled: parseNameVersion,
+		database:       rpmDatabase,
+		versions:       dnfVersions("dnf"),
+		parseVersions:  parseDnfVersions,
+		search:         searchDnf,
+		setup:          setupDnf,
+	})
+	Register(&manager{
+		name: "yum", os: "linux",
+		install:        run("sudo", "yum", "install", "-y"),
+		update:         noPackages(run("sudo", "yum", "makecache")),
+		batch:          true,
+		installed:      rpmQuery,
+		parseInstalled: parseNameVersion,
+		database:       rpmDatabase,
+		versions:       dnfVersions("yum"),
+		parseVersions:  parseDnfVersions,
+	})
+	Register(&manager{
+		name: "pacman", os: "linux",
+		install: run("sudo", "pacman", "-S", "--noconfirm"),
+		update:  noPackages(run("sudo", "pacman", "-Sy")),
+		batch:   true,
+		installed: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("pacman", "-Q", pkg)
+		},
+		parseInstalled: parsePacmanQuery,
+		search:         searchPacman,
+		database:       files("/var/lib/pacman/local"),
+	})
+	Register(&manager{
+		name: "zypper", os: "linux",
+		install:        run("sudo", "zypper", "install", "-y"),
+		update:         noPackages(run("sudo", "zypper", "--non-interactive", "refresh")),
+		batch:          true,
+		installed:      rpmQuery,
+		parseInstalled: parseNameVersion,
+		database:       rpmDatabase,
+		versions: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("zypper", "--quiet", "search", "--details", "--match-exact", pkg)
+		},
+		parseVersions: parseZypperVersions,
+	})
+
+	// macOS; MacOSPackageManager picks between them
+	Register(&manager{
+		name: "brew", os: "darwin",
+		install: run("brew", "install"),
+		update:  noPackages(run("brew", "update")),
+		batch:   true,
+		installed: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("brew", "list", "--versions", pkg)
+		},
+		parseInstalled: parseBrewVersions,
+		database:       brewDatabase,
+		search:         searchBrew,
+		setup:          setupBrew,
+	})
+	Register(&manager{
+		name: "port", os: "darwin",
+		install: run("sudo", "port", "install"),
+		update:  noPackages(run("sudo", "port", "selfupdate")),
+		batch:   true,
+		installed: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("port", "-q", "installed", pkg)
+		},
+		parseInstalled: parsePortInstalled,
+		database:       files("/opt/local/var/macports/registry/registry.db"),
+		search:         searchPort,
+		setup:          setupPort,
+	})
+
+	// Windows. MSYS2's pacman is looked up by install location instead of in
+	// PATH, after the managers installed on PATH.
+	Register(&manager{
+		name: "winget", os: "windows",
+		// One package at a time, by its exact ID
+		install: func(pkgs []string) (*util.Cmd, error) {
+			if len(pkgs) != 1 {
+				return nil, fmt.Errorf("winget installs one package at a time")
+			}
+			return util.SystemCommand("winget", "install", "--id", pkgs[0], "--exact", "--accept-package-agreements", "--accept-source-agreements"), nil
+		},
+		// winget updates its sources itself
+		installed: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("winget", "list", "--id", pkg, "--exact", "--accept-source-agreements", "--disable-interactivity")
+		},
+		parseInstalled: parseTableVersion,
+		versions: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("winget", "show", "--id", pkg, "--exact", "--versions", "--accept-source-agreements", "--disable-interactivity")
+		},
+		parseVersions: parseWingetVersions,
+		setup: func() error {
+			return requireCommand("winget", "winget not found. Install App Installer from the Microsoft Store")
+		},
+	})
+	Register(&manager{
+		name: "choco", os: "windows",
+		install: run("choco", "install", "-y"),
+		// Chocolatey updates its sources itself
+		installed: func(pkg string) *util.Cmd {
+			// Chocolatey 2 only lists local packages; --local-only was removed
+			return util.ParsedCommand("choco", "list", "--limit-output", "--exact", pkg)
+		},
+		parseInstalled: parseNameVersion,
+		database:       chocoDatabase,
+		versions: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("choco", "search", pkg, "--exact", "--all-versions", "--limit-output")
+		},
+		parseVersions: parseChocoVersions,
+		search:        searchChoco,
+		setup: func() error {
+			return requireCommand("choco", "Chocolatey not found. Install from: https://chocolatey.org/install")
+		},
+	})
+	Register(&manager{
+		name: "scoop", os: "windows",
+		install: run("scoop", "install"),
+		update:  noPackages(run("scoop", "update")),
+		batch:   true,
+		installed: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("scoop", "list", pkg)
+		},
+		parseInstalled: parseTableVersion,
+		database:       scoopDatabase,
+		setup: func() error {
+			return requireCommand("scoop", "Scoop not found. Install from: https://scoop.sh/")
+		},
+	})
+	Register(&manager{
+		name: "msys2", os: "windows",
+		detect:  func() bool { return MSYS2Root() != "" },
+		install: msys2Pacman("-S", "--noconfirm", "--needed"),
+		update:  noPackages(msys2Pacman("-Sy")),
+		batch:   true,
+		installed: func(pkg string) *util.Cmd {
+			bash, args, err := MSYS2Pacman("-Q", pkg)
+			if err != nil {
+				return nil
+			}
+			return util.ParsedCommand(bash, args...)
+		},
+		parseInstalled: parsePacmanQuery,
+		database:       msys2Database,
+		setup: func() error {
+			if MSYS2Root() == "" {
+				return errMSYS2NotFound
+			}
+			return nil
+		},
+	})
+	Register(&manager{
+		name: "vcpkg", os: "windows",
+		// Installs for vcpkg's default triplet; install adds --triplet for
+		// the vcpkg_triplet of catalyst.yml
+		install: func(pkgs []string) (*util.Cmd, error) {
+			return util.SystemCommand("vcpkg", append([]string{"install"}, pkgs...)...), nil
+		},
+		// vcpkg doesn't need database updates
+		installed: func(pkg string) *util.Cmd {
+			return util.ParsedCommand("vcpkg", "list", pkg)
+		},
+		parseInstalled: parseVcpkgList,
+		database:       vcpkgDatabase,
+		search:         searchVcpkg,
+		setup:          setupVcpkg,
+	})
+}
+
+// msys2Pacman returns an install function running MSYS2's pacman
+func msys2Pacman(argv ...string) func(pkgs []string) (*util.Cmd, error) {
+	return func(pkgs []string) (*util.Cmd, error) {
+		bash, args, err := MSYS2Pacman(append(slices.Clone(argv), pkgs...)...)
+		if err != nil {
+			return nil, err
+		}
+		return util.SystemCommand(bash, args...), nil
+	}
+}
+
+// rpmQuery lists an installed package on rpm-based systems
+func rpmQuery(pkg string) *util.Cmd {
+	return util.ParsedCommand("rpm", "-q", "--qf", "%{NAME}|%{VERSION}-%{RELEASE}\n", pkg)
+}
+
+// dnfVersions lists the versions of a package dnf or yum can install
+func dnfVersions(command string) func(pkg string) *util.Cmd {
+	return func(pkg string) *util.Cmd {
+		return util.ParsedCommand(command, "list", "--showduplicates", "--available", "--quiet", pkg)
+	}
+}
+
+// lastVersion returns the last version match finds in the non-empty lines
+// of output
+func lastVersion(output string, match func(line string, fields []string) string) string {
+	var version string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if v := match(line, strings.Fields(line)); v != "" {
+			version = v
+		}
+	}
+	return version
+}
+
+// parseDpkgQuery parses "ii |1.2.13.dfsg-1"; the second status letter is
+// the current state
+func parseDpkgQuery(pkg, output string) string {
+	return lastVersion(output, func(line string, _ []string) string {
+		status, v, ok := strings.Cut(line, "|")
+		if ok && len(status) >= 2 && status[1] == 'i' {
+			return v
+		}
+		return ""
+	})
+}
+
+// parseNameVersion parses "<name>|<version>" lines; rpm prints a sentence
+// for missing packages
+func parseNameVersion(pkg, output string) string {
+	return lastVersion(output, func(line string, _ []string) string {
+		name, v, ok := strings.Cut(line, "|")
+		if ok && strings.EqualFold(name, pkg) {
+			return strings.TrimSpace(v)
+		}
+		return ""
+	})
+}
+
+// parsePacmanQuery parses "<name> <version>"
+func parsePacmanQuery(pkg, output string) string {
+	return lastVersion(output, func(_ string, fields []string) string {
+		if len(fields) == 2 && fields[0] == pkg {
+			return fields[1]
+		}
+		return ""
+	})
+}
+
+// parseBrewVersions parses "<name> <version> [<version>...]", newest last
+func parseBrewVersions(pkg, output string) string {
+	return lastVersion(output, func(_ string, fields []string) string {
+		if len(fields) >= 2 && fields[0] == pkg {
+			return fields[len(fields)-1]
+		}
+		return ""
+	})
+}
+
+// parsePortInstalled parses "<name> @<version>_<revision>+<variants> (active)"
+func parsePortInstalled(pkg, output string) string {
+	var version string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == pkg && strings.HasPrefix(fields[1], "@") {
+			version = strings.TrimPrefix(fields[1], "@")
+			// Prefer the active version when several are installed
+			if strings.Contains(line, "(active)") {
+				return version
+			}
+		}
+	}
+	return version
+}
+
+// parseVcpkgList parses "<name>[features]:<triplet>  <version>  <description>";
+// vcpkg list matches by prefix, so zlib also lists zlib-ng
+func parseVcpkgList(pkg, output string) string {
+	return lastVersion(output, func(_ string, fields []string) string {
+		name, _, _ := strings.Cut(fields[0], ":")
+		name, _, _ = strings.Cut(name, "[")
+		if len(fields) >= 2 && name == pkg {
+			return fields[1]
+		}
+		return ""
+	})
+}
+
+// parseTableVersion parses winget and scoop table rows: "<name> <id>
+// <version> [<available>] [<source>]". Names can contain spaces, so the
+// exact ID is found and the column after it taken.
+func parseTableVersion(pkg, output string) string {
+	return lastVersion(output, func(_ string, fields []string) string {
+		for i := 0; i+1 < len(fields); i++ {
+			if !strings.EqualFold(fields[i], pkg) {
+				continue
+			}
+			v := fields[i+1]
+			// winget prints "< 2.0" for unversioned upgrades
+			if v == "<" && i+2 < len(fields) {
+				v = fields[i+2]
+			}
+			if isVersion(v) {
+				return v
+			}
+		}
+		return ""
+	})
+}
+
+// isVersion reports whether a table cell looks like a version number
+func isVersion(s string) bool {
+	return s != "" && (s[0] >= '0' && s[0] <= '9' || s == "Unknown")
+}
+
+// collectVersions returns the versions match finds in the non-empty lines
+// of output, without duplicates
+func collectVersions(output string, match func(line string, fields []string) string) []string {
+	var versions []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if v := match(line, strings.Fields(line)); v != "" && !seen[v] {
+			seen[v] = true
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// parseAptMadison parses "<name> | <version> | <archive>"
+func parseAptMadison(pkg, output string) []string {
+	return collectVersions(output, func(line string, _ []string) string {
+		cells := strings.Split(line, "|")
+		if len(cells) >= 3 && strings.TrimSpace(cells[0]) == pkg {
+			return strings.TrimSpace(cells[1])
+		}
+		return ""
+	})
+}
+
+// parseDnfVersions parses "<name>.<arch>  [<epoch>:]<version>-<release>
+// <repo>"; rpm -q omits the epoch, so it's dropped here too
+func parseDnfVersions(pkg, output string) []string {
+	return collectVersions(output, func(_ string, fields []string) string {
+		if len(fields) < 3 || !strings.HasPrefix(fields[0], pkg+".") {
+			return ""
+		}
+		if _, v, ok := strings.Cut(fields[1], ":"); ok {
+			return v
+		}
+		return fields[1]
+	})
+}
+
+// parseZypperVersions parses "<status> | <name> | <type> | <version> |
+// <arch> | <repo>"
+func parseZypperVersions(pkg, output string) []string {
+	return collectVersions(output, func(line string, _ []string) string {
+		cells := strings.Split(line, "|")
+		if len(cells) >= 4 && strings.TrimSpace(cells[1]) == pkg && strings.TrimSpace(cells[2]) == "package" {
+			return strings.TrimSpace(cells[3])
+		}
+		return ""
+	})
+}
+
+// parseChocoVersions parses "<name>|<version>"
+func parseChocoVersions(pkg, output string) []string {
+	return collectVersions(output, func(line string, _ []string) string {
+		name, v, ok := strings.Cut(line, "|")
+		if ok && strings.EqualFold(name, pkg) {
+			return strings.TrimSpace(v)
+		}
+		return ""
+	})
+}
+
+// parseWingetVersions parses a "Version" heading and a dashed rule, then one
+// version per line
+func parseWingetVersions(pkg, output string) []string {
+	inVersions := false
+	return collectVersions(output, func(line string, fields []string) string {
+		if strings.HasPrefix(line, "---") {
+			inVersions = true
+		} else if inVersions && isVersion(fields[0]) {
+			return fields[0]
+		}
+		return ""
+	})
+}
@@ -0,0 +1,167 @@
+package platform
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// errMSYS2NotFound is returned when no MSYS2 installation is found
+var errMSYS2NotFound = errors.New("MSYS2 not found - install it from https://www.msys2.org/ or set MSYS2_ROOT")
+
+// MSYS2PackagePrefixes maps MSYS2 environments to their package name prefixes
+var MSYS2PackagePrefixes = map[string]string{
+	"ucrt64":     "mingw-w64-ucrt-x86_64-",
+	"mingw64":    "mingw-w64-x86_64-",
+	"clang64":    "mingw-w64-clang-x86_64-",
+	"clangarm64": "mingw-w64-clang-aarch64-",
+	"mingw32":    "mingw-w64-i686-",
+}
+
+// PreferredMSYS2Environment is the MSYS2 environment to install packages
+// into when MSYSTEM doesn't name one (e.g. "clang64")
+var PreferredMSYS2Environment string
+
+// MSYS2Environment returns the MSYS2 environment to install packages into:
+// the one named by MSYSTEM, then PreferredMSYS2Environment, otherwise UCRT64
+// on x64 hosts and CLANGARM64 on ARM64 hosts, the only environment with
+// native ARM64 packages
+func MSYS2Environment() string {
+	for _, env := range []string{os.Getenv("MSYSTEM"), PreferredMSYS2Environment} {
+		env = strings.ToLower(env)
+		if _, ok := MSYS2PackagePrefixes[env]; ok {
+			return env
+		}
+	}
+	return DefaultMSYS2Environment()
+}
+
+// DefaultMSYS2Environment returns the MSYS2 environment used when neither
+// MSYSTEM nor msys2_environment chooses one
+func DefaultMSYS2Environment() string {
+	if DetectArch() == "arm64" {
+		return "clangarm64"
+	}
+	return "ucrt64"
+}
+
+var (
+	msys2Mu   sync.Mutex
+	msys2Root string
+)
+
+// MSYS2Root returns the directory MSYS2 is installed in, or "" if it isn't.
+// MSYS2_ROOT is checked first, then the pacman in PATH, the installer's
+// registry entry and the default locations. Only a found installation is
+// remembered, so MSYS2 installed by winget during the run is picked up.
+func MSYS2Root() string {
+	msys2Mu.Lock()
+	defer msys2Mu.Unlock()
+	if msys2Root != "" || runtime.GOOS != "windows" {
+		return msys2Root
+	}
+	for _, root := range msys2RootCandidates() {
+		if root != "" && isMSYS2Root(root) {
+			msys2Root = root
+			break
+		}
+	}
+	return msys2Root
+}
+
+func msys2RootCandidates() []string {
+	candidates := []string{os.Getenv("MSYS2_ROOT")}
+	// pacman lives in <root>\usr\bin
+	if pacman, err := exec.LookPath("pacman"); err == nil {
+		candidates = append(candidates, filepath.Dir(filepath.Dir(filepath.Dir(pacman))))
+	}
+	candidates = append(candidates, msys2RegistryRoots()...)
+	candidates = append(candidates, `C:\msys64`, `C:\msys32`)
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, "scoop", "apps", "msys2", "current"))
+	}
+	return candidates
+}
+
+// msys2RegistryRoots returns the install locations recorded in the uninstall
+// keys of the registry, where the MSYS2 installer records its own. Other
+// programs' locations are filtered out by isMSYS2Root. The registry is only
+// queried once, so a machine without MSYS2 doesn't pay for reg on each lookup.
+var msys2RegistryRoots = sync.OnceValue(func() []string {
+	var roots []string
+	for _, key := range []string{
+		`HKCU\Software\Microsoft\Windows\CurrentVersion\Uninstall`,
+		`HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall`,
+	} {
+		output, err := util.ParsedCommand("reg", "query", key, "/s", "/v", "InstallLocation").Output()
+		if err != nil {
+			continue
+		}
+		roots = append(roots, parseRegistryInstallLocations(string(output))...)
+	}
+	return roots
+})
+
+// parseRegistryInstallLocations finds the InstallLocation values in reg
+// query output ("    InstallLocation    REG_SZ    C:\msys64")
+func parseRegistryInstallLocations(output string) []string {
+	var locations []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "    ", 3)
+		if len(fields) == 3 && fields[0] == "InstallLocation" && fields[1] == "REG_SZ" {
+			locations = append(locations, strings.TrimSpace(fields[2]))
+		}
+	}
+	return locations
+}
+
+// isMSYS2Root reports whether dir is an MSYS2 installation. Git for Windows
+// also has usr\bin\bash.exe, but no pacman.
+func isMSYS2Root(dir string) bool {
+	for _, tool := range []string{"bash.exe", "pacman.exe"} {
+		if _, err := os.Stat(filepath.Join(dir, "usr", "bin", tool)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// MSYS2Prefix returns the prefix of the environment packages are installed
+// into (e.g. C:\msys64\ucrt64), or "" if it doesn't exist
+func MSYS2Prefix() string {
+	root := MSYS2Root()
+	if root == "" {
+		return ""
+	}
+	prefix := filepath.Join(root, MSYS2Environment())
+	if info, err := os.Stat(prefix); err != nil || !info.IsDir() {
+		return ""
+	}
+	return prefix
+}
+
+// MSYS2Pacman returns MSYS2's bash and the arguments that run pacman with
+// args in a login shell, which sets up the MSYS2 environment
+func MSYS2Pacman(args ...string) (string, []string, error) {
+	root := MSYS2Root()
+	if root == "" {
+		return "", nil, errMSYS2NotFound
+	}
+	return filepath.Join(root, "usr", "bin", "bash.exe"), []string{"-lc", pacmanCommand(args)}, nil
+}
+
+// pacmanCommand returns the bash command line running pacman with args,
+// each quoted so bash passes it on as it is
+func pacmanCommand(args []string) string {
+	quoted := []string{"pacman"}
+	for _, arg := range args {
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
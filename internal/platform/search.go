@@ -0,0 +1,477 @@
+package platform
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// SearchResult is a package a package manager search found
+type SearchResult struct {
+	PackageName string
+	Description string
+	Confidence  int // 0-100, higher is better match
+}
+
+// searchApt searches for packages using apt (Debian/Ubuntu)
+func searchApt(headerName string) ([]SearchResult, error) {
+	var results []SearchResult
+
+	// First try apt-file to find which package provides the header
+	results = append(results, SearchAptFile(headerName)...)
+
+	// Also search package names with common variations. apt-cache has a
+	// stable output format, unlike apt search.
+	searchTerms := []string{
+		headerName,
+		"lib" + headerName,
+		headerName + "-dev",
+		"lib" + headerName + "-dev",
+	}
+
+	for _, term := range searchTerms {
+		if output, err := util.ParsedCommand("apt-cache", "search", "--names-only", term).Output(); err == nil {
+			results = append(results, parseAptSearchOutput(string(output), headerName)...)
+		}
+	}
+
+	return DeduplicateResults(results), nil
+}
+
+// SearchAptFile finds packages shipping the header using apt-file
+func SearchAptFile(headerName string) []SearchResult {
+	output, err := util.ParsedCommand("apt-file", "search", headerName+".h").Output()
+	if err != nil {
+		return nil
+	}
+	return parseAptFileOutput(string(output), headerName)
+}
+
+// searchDnf searches for packages using dnf (Fedora/RHEL)
+func searchDnf(headerName string) ([]SearchResult, error) {
+	var results []SearchResult
+
+	searchTerms := []string{
+		headerName,
+		headerName + "-devel",
+		"lib" + headerName + "-devel",
+	}
+
+	for _, term := range searchTerms {
+		// repoquery prints one "name<TAB>summary" line per package, where
+		// dnf search adds localized section headers
+		if output, err := util.ParsedCommand("dnf", "repoquery", "-q", "--latest-limit=1", "--qf", "%{name}\t%{summary}\n", "*"+term+"*").Output(); err == nil {
+			results = append(results, parseDnfOutput(string(output), headerName)...)
+		}
+	}
+
+	return DeduplicateResults(results), nil
+}
+
+// searchPacman searches for packages using pacman (Arch Linux)
+func searchPacman(headerName string) ([]SearchResult, error) {
+	var results []SearchResult
+
+	searchTerms := []string{
+		headerName,
+		"lib" + headerName,
+	}
+
+	for _, term := range searchTerms {
+		if output, err := util.ParsedCommand("pacman", "-Ssq", term).Output(); err == nil {
+			results = append(results, parsePacmanOutput(string(output), headerName)...)
+		}
+	}
+
+	return DeduplicateResults(results), nil
+}
+
+// searchBrew searches for packages using brew (macOS Homebrew)
+func searchBrew(headerName string) ([]SearchResult, error) {
+	var results []SearchResult
+
+	searchTerms := []string{
+		headerName,
+		"lib" + headerName,
+	}
+
+	for _, term := range searchTerms {
+		if output, err := util.ParsedCommand("brew", "search", "--formula", term).Output(); err == nil {
+			results = append(results, parseBrewOutput(string(output), headerName)...)
+		}
+	}
+
+	return DeduplicateResults(results), nil
+}
+
+// searchPort searches for packages using MacPorts
+func searchPort(headerName string) ([]SearchResult, error) {
+	var results []SearchResult
+
+	searchTerms := []string{
+		headerName,
+		"lib" + headerName,
+	}
+
+	for _, term := range searchTerms {
+		if output, err := util.ParsedCommand("port", "-q", "search", "--name", "--line", term).Output(); err == nil {
+			results = append(results, parsePortOutput(string(output), headerName)...)
+		}
+	}
+
+	return DeduplicateResults(results), nil
+}
+
+// searchVcpkg searches for packages using vcpkg (Windows)
+func searchVcpkg(headerName string) ([]SearchResult, error) {
+	var results []SearchResult
+
+	if output, err := util.ParsedCommand("vcpkg", "search", headerName).Output(); err == nil {
+		results = parseVcpkgOutput(string(output), headerName)
+	}
+
+	return DeduplicateResults(results), nil
+}
+
+// searchChoco searches for packages using chocolatey (Windows)
+func searchChoco(headerName string) ([]SearchResult, error) {
+	var results []SearchResult
+
+	if output, err := util.ParsedCommand("choco", "search", headerName, "--limit-output").Output(); err == nil {
+		results = parseChocoOutput(string(output), headerName)
+	}
+
+	return DeduplicateResults(results), nil
+}
+
+// parseAptFileOutput parses apt-file output to find package names
+func parseAptFileOutput(output, headerName string) []SearchResult {
+	var results []SearchResult
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// apt-file output format: package: /path/to/file
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 {
+			pkgName := strings.TrimSpace(parts[0])
+			filePath := strings.TrimSpace(parts[1])
+
+			// Calculate confidence based on file path match
+			confidence := PathConfidence(filePath, headerName)
+			if confidence > 0 {
+				results = append(results, SearchResult{
+					PackageName: pkgName,
+					Description: fmt.Sprintf("Provides %s", filePath),
+					Confidence:  confidence,
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+// parseAptSearchOutput parses apt-cache search output, one
+// "<package> - <description>" line per package
+func parseAptSearchOutput(output, headerName string) []SearchResult {
+	var results []SearchResult
+
+	for _, line := range strings.Split(output, "\n") {
+		pkgName, description, found := strings.Cut(strings.TrimSpace(line), " - ")
+		if !found {
+			continue
+		}
+		confidence := calculateNameConfidence(pkgName, headerName)
+		if confidence > 20 { // Only include reasonable matches
+			results = append(results, SearchResult{
+				PackageName: pkgName,
+				Description: description,
+				Confidence:  confidence,
+			})
+		}
+	}
+
+	return results
+}
+
+// parseDnfOutput parses dnf repoquery output, one "<name>\t<summary>" line
+// per package
+func parseDnfOutput(output, headerName string) []SearchResult {
+	var results []SearchResult
+
+	for _, line := range strings.Split(output, "\n") {
+		pkgName, summary, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if !found || pkgName == "" {
+			continue
+		}
+		confidence := calculateNameConfidence(pkgName, headerName)
+		if confidence > 20 {
+			results = append(results, SearchResult{
+				PackageName: pkgName,
+				Description: summary,
+				Confidence:  confidence,
+			})
+		}
+	}
+
+	return results
+}
+
+// parsePacmanOutput parses pacman -Ssq output, one package name per line
+func parsePacmanOutput(output, headerName string) []SearchResult {
+	var results []SearchResult
+
+	for _, line := range strings.Split(output, "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		confidence := calculateNameConfidence(name, headerName)
+		if confidence > 20 {
+			results = append(results, SearchResult{
+				PackageName: name,
+				Description: "pacman package",
+				Confidence:  confidence,
+			})
+		}
+	}
+
+	return results
+}
+
+// parseBrewOutput parses brew search --formula output, one formula per line
+func parseBrewOutput(output, headerName string) []SearchResult {
+	var results []SearchResult
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		// Skip "==> Formulae" headers printed by older Homebrew versions
+		if line == "" || strings.HasPrefix(line, "==>") {
+			continue
+		}
+
+		confidence := calculateNameConfidence(line, headerName)
+		if confidence > 20 {
+			results = append(results, SearchResult{
+				PackageName: line,
+				Description: "Homebrew formula",
+				Confidence:  confidence,
+			})
+		}
+	}
+
+	return results
+}
+
+// parsePortOutput parses port search --line output, one
+// "<name> <version> <categories> <description>" row per port
+func parsePortOutput(output, headerName string) []SearchResult {
+	var results []SearchResult
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		description := "MacPorts port"
+		if len(fields) > 3 {
+			description = strings.Join(fields[3:], " ")
+		}
+		confidence := calculateNameConfidence(fields[0], headerName)
+		if confidence > 20 {
+			results = append(results, SearchResult{
+				PackageName: fields[0],
+				Description: description,
+				Confidence:  confidence,
+			})
+		}
+	}
+
+	return results
+}
+
+// parseVcpkgOutput parses vcpkg search output
+func parseVcpkgOutput(output, headerName string) []SearchResult {
+	var results []SearchResult
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) > 0 {
+			confidence := calculateNameConfidence(parts[0], headerName)
+			if confidence > 20 {
+				results = append(results, SearchResult{
+					PackageName: parts[0],
+					Description: strings.Join(parts[1:], " "),
+					Confidence:  confidence,
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+// parseChocoOutput parses choco search --limit-output output, one
+// "<package>|<version>" line per package
+func parseChocoOutput(output, headerName string) []SearchResult {
+	var results []SearchResult
+
+	for _, line := range strings.Split(output, "\n") {
+		pkgName, version, found := strings.Cut(strings.TrimSpace(line), "|")
+		if !found || pkgName == "" {
+			continue
+		}
+		confidence := calculateNameConfidence(pkgName, headerName)
+		if confidence > 20 {
+			results = append(results, SearchResult{
+				PackageName: pkgName,
+				Description: "Chocolatey package " + version,
+				Confidence:  confidence,
+			})
+		}
+	}
+
+	return results
+}
+
+// calculateNameConfidence calculates how well a package name matches the header name
+func calculateNameConfidence(pkgName, headerName string) int {
+	pkgLower := strings.ToLower(pkgName)
+	headerLower := strings.ToLower(headerName)
+
+	// Exact match
+	if pkgLower == headerLower {
+		return 100
+	}
+
+	// Contains header name
+	if strings.Contains(pkgLower, headerLower) {
+		return 80
+	}
+
+	// Header name contains package name
+	if strings.Contains(headerLower, pkgLower) {
+		return 70
+	}
+
+	// Common library naming patterns
+	patterns := []string{
+		"lib" + headerLower,
+		headerLower + "-dev",
+		headerLower + "-devel",
+		"lib" + headerLower + "-dev",
+		"lib" + headerLower + "-devel",
+	}
+
+	for _, pattern := range patterns {
+		if pkgLower == pattern {
+			return 90
+		}
+		if strings.Contains(pkgLower, pattern) {
+			return 60
+		}
+	}
+
+	// Fuzzy matching (simple edit distance approximation)
+	if len(pkgLower) > 0 && len(headerLower) > 0 {
+		minLen := len(pkgLower)
+		if len(headerLower) < minLen {
+			minLen = len(headerLower)
+		}
+
+		matches := 0
+		for i := 0; i < minLen; i++ {
+			if pkgLower[i] == headerLower[i] {
+				matches++
+			}
+		}
+
+		similarity := (matches * 100) / minLen
+		if similarity > 60 {
+			return similarity / 2 // Reduce confidence for fuzzy matches
+		}
+	}
+
+	return 0
+}
+
+// PathConfidence calculates confidence based on file path matching
+func PathConfidence(filePath, headerName string) int {
+	pathLower := strings.ToLower(filePath)
+	headerLower := strings.ToLower(headerName)
+
+	// Check if the file is actually a header file
+	if !strings.HasSuffix(pathLower, ".h") && !strings.HasSuffix(pathLower, ".hpp") {
+		return 0
+	}
+
+	// Extract filename from path
+	fileName := strings.ToLower(filepath.Base(filePath))
+
+	// Exact match
+	if fileName == headerLower+".h" || fileName == headerLower+".hpp" {
+		return 95
+	}
+
+	// Check if it's in a reasonable include path
+	includePatterns := []string{"/usr/include/", "/usr/local/include/", "/opt/include/"}
+	for _, pattern := range includePatterns {
+		if strings.Contains(pathLower, pattern) {
+			if strings.Contains(fileName, headerLower) {
+				return 80
+			}
+		}
+	}
+
+	// General path contains header name
+	if strings.Contains(fileName, headerLower) {
+		return 60
+	}
+
+	return 0
+}
+
+// DeduplicateResults removes duplicate search results and sorts by confidence
+func DeduplicateResults(results []SearchResult) []SearchResult {
+	seen := make(map[string]SearchResult)
+
+	// Keep the result with highest confidence for each package
+	for _, result := range results {
+		existing, exists := seen[result.PackageName]
+		if !exists || result.Confidence > existing.Confidence {
+			seen[result.PackageName] = result
+		}
+	}
+
+	// Convert back to slice and sort by confidence (highest first)
+	var deduplicated []SearchResult
+	for _, result := range seen {
+		deduplicated = append(deduplicated, result)
+	}
+
+	// Simple sort by confidence (bubble sort for simplicity)
+	for i := 0; i < len(deduplicated)-1; i++ {
+		for j := 0; j < len(deduplicated)-i-1; j++ {
+			if deduplicated[j].Confidence < deduplicated[j+1].Confidence {
+				deduplicated[j], deduplicated[j+1] = deduplicated[j+1], deduplicated[j]
+			}
+		}
+	}
+
+	return deduplicated
+}
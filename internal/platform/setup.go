@@ -1,47 +1,42 @@
 package platform
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/util"
 )
 
 // SetupPackageManager ensures the package manager and required tools are available
 func SetupPackageManager(pkgManager string) error {
-	switch pkgManager {
-	case "apt":
-		return setupApt()
-	case "dnf":
-		return setupDnf()
-	case "pacman":
-		return setupPacman()
-	case "brew":
-		return setupBrew()
-	case "vcpkg":
-		return setupVcpkg()
-	case "choco":
-		return setupChoco()
-	default:
+	pm, ok := LookupPackageManager(pkgManager)
+	if !ok {
 		return fmt.Errorf("unsupported package manager: %s", pkgManager)
 	}
+	if setup, ok := pm.(interface{ Setup() error }); ok {
+		return setup.Setup()
+	}
+	return nil
 }
 
 // setupApt ensures apt and apt-file are available
 func setupApt() error {
 	// Check if apt-file is available for better header searching
 	if _, err := exec.LookPath("apt-file"); err != nil {
-		fmt.Println("Note: apt-file not found. Install it for better header file resolution:")
-		fmt.Println("  sudo apt update && sudo apt install apt-file")
-		fmt.Println("  sudo apt-file update")
+		log.Info("Note: apt-file not found. Install it for better header file resolution")
+		log.Info("  with catalyst setup, or: sudo apt install apt-file && sudo apt-file update")
 		return nil // Not a critical error
 	}
 
 	// Check if apt-file database is up to date
-	output, err := exec.Command("apt-file", "search", "stdio.h").Output()
+	output, err := util.ParsedCommand("apt-file", "search", "stdio.h").Output()
 	if err != nil || len(output) == 0 {
-		fmt.Println("Note: apt-file database may be outdated. Update it with:")
-		fmt.Println("  sudo apt-file update")
+		log.Info("Note: apt-file database may be outdated. Update it with:")
+		log.Info("  sudo apt-file update")
 	}
 
 	return nil
@@ -56,11 +51,10 @@ func setupDnf() error {
 	return nil
 }
 
-// setupPacman ensures pacman is properly configured
-func setupPacman() error {
-	// Check if pacman is available
-	if _, err := exec.LookPath("pacman"); err != nil {
-		return fmt.Errorf("pacman not found")
+// requireCommand fails with msg if the package manager's command is missing
+func requireCommand(command, msg string) error {
+	if _, err := exec.LookPath(command); err != nil {
+		return errors.New(msg)
 	}
 	return nil
 }
@@ -73,15 +67,25 @@ func setupBrew() error {
 	}
 
 	// Check if brew needs updating (optional)
-	output, err := exec.Command("brew", "--version").Output()
+	output, err := util.ParsedCommand("brew", "--version").Output()
 	if err != nil {
 		return fmt.Errorf("brew command failed: %v", err)
 	}
 
 	if strings.Contains(string(output), "Homebrew") {
-		fmt.Println("Homebrew detected. Consider running 'brew update' for latest package info.")
+		log.Info("Homebrew detected. Consider running 'brew update' for latest package info.")
+	}
+
+	return nil
+}
+
+// setupPort checks if MacPorts is available
+func setupPort() error {
+	if _, err := exec.LookPath("port"); err != nil {
+		return fmt.Errorf("MacPorts not found. Install from: https://www.macports.org/install.php")
 	}
 
+	log.Info("MacPorts detected. Consider running 'sudo port selfupdate' for latest port info.")
 	return nil
 }
 
@@ -92,48 +96,54 @@ func setupVcpkg() error {
 	}
 
 	// Check if vcpkg is integrated
-	output, err := exec.Command("vcpkg", "list").Output()
+	output, err := util.ParsedCommand("vcpkg", "list").Output()
 	if err != nil {
-		fmt.Println("Note: vcpkg may need integration. Run: vcpkg integrate install")
+		log.Info("Note: vcpkg may need integration. Run: vcpkg integrate install")
 	}
 
 	if len(output) == 0 {
-		fmt.Println("Note: No vcpkg packages installed yet.")
+		log.Info("Note: No vcpkg packages installed yet.")
 	}
 
 	return nil
 }
 
-// setupChoco checks if Chocolatey is available
-func setupChoco() error {
-	if _, err := exec.LookPath("choco"); err != nil {
-		return fmt.Errorf("Chocolatey not found. Install from: https://chocolatey.org/install")
-	}
-	return nil
-}
-
 // GetPackageManagerSetupAdvice returns setup advice for the current platform
 func GetPackageManagerSetupAdvice() string {
-	osName := runtime.GOOS
+	return packageManagerSetupAdvice(runtime.GOOS)
+}
 
+// packageManagerSetupAdvice explains how to install the package managers
+// catalyst drives on osName
+func packageManagerSetupAdvice(osName string) string {
 	switch osName {
 	case "linux":
 		return `
 Package Manager Setup (Linux):
   Ubuntu/Debian: apt is pre-installed
     • Install apt-file: sudo apt install apt-file && sudo apt-file update
-  Fedora/RHEL: dnf is pre-installed  
+  Fedora/RHEL: dnf is pre-installed (yum on older releases)
   Arch Linux: pacman is pre-installed
+  openSUSE: zypper is pre-installed
 `
 	case "darwin":
 		return `
 Package Manager Setup (macOS):
   Install Homebrew: /bin/bash -c "$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)"
   Update packages: brew update
+
+  Or MacPorts: https://www.macports.org/install.php
+    • Update ports: sudo port selfupdate
+    • Prefer it when Homebrew is also installed: macos_package_manager: port in ~/.catalyst.yaml
 `
 	case "windows":
 		return `
 Package Manager Setup (Windows):
+  winget: ships with Windows 10 and 11 as App Installer (Microsoft Store)
+
+  Scoop:
+    • PowerShell: irm get.scoop.sh | iex
+
   vcpkg: 
     • Clone: git clone https://github.com/Microsoft/vcpkg.git
     • Build: .\vcpkg\bootstrap-vcpkg.bat
@@ -0,0 +1,139 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Support describes which parts of catalyst work on the host. Scanning,
+// analysis and compiling only need a C compiler; installing system packages
+// needs a package manager catalyst knows how to drive.
+type Support struct {
+	OS             string // runtime.GOOS
+	Distro         string // ID from /etc/os-release, if there is one
+	Libc           string // "musl" or "glibc" on Linux
+	PackageManager string // The manager catalyst installs with; empty if none
+	NativeManager  string // The host's own package manager when catalyst can't drive it
+}
+
+// nativeManagers are package managers catalyst recognizes but can't drive,
+// with the command that installs packages
+var nativeManagers = []struct{ name, install string }{
+	{"apk", "apk add"},
+	{"xbps-install", "xbps-install"},
+	{"emerge", "emerge"},
+	{"pkg_add", "pkg_add"},
+	{"pkgin", "pkgin install"},
+	{"pkg", "pkg install"},
+}
+
+// HostPackageManager returns the package manager catalyst installs system
+// packages with on this host, or "" if there is none it supports
+func HostPackageManager() string {
+	pkgManager, _ := DetectPackageManager(runtime.GOOS)
+	return pkgManager
+}
+
+// DetectSupport checks how well catalyst supports the host
+func DetectSupport() Support {
+	s := Support{OS: runtime.GOOS, Distro: osReleaseID(), PackageManager: HostPackageManager()}
+	if s.OS == "linux" {
+		s.Libc = "glibc"
+		if musl, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(musl) > 0 {
+			s.Libc = "musl"
+		}
+	}
+	if s.PackageManager == "" {
+		for _, m := range nativeManagers {
+			if _, err := exec.LookPath(m.name); err == nil {
+				s.NativeManager = m.name
+				break
+			}
+		}
+	}
+	return s
+}
+
+// managedOSes are the OSes catalyst drives package managers on
+var managedOSes = []string{"linux", "darwin", "windows"}
+
+// FullySupported reports whether catalyst can install packages on the host:
+// an OS it drives package managers on, with one of them installed
+func (s Support) FullySupported() bool {
+	return slices.Contains(managedOSes, s.OS) && s.PackageManager != ""
+}
+
+// MissingPackageManager reports whether the host only lacks a package
+// manager: installing one catalyst drives (Homebrew, winget, ...) makes it
+// fully supported
+func (s Support) MissingPackageManager() bool {
+	return slices.Contains(managedOSes, s.OS) && s.PackageManager == "" && s.NativeManager == ""
+}
+
+// Host names the host for messages, e.g. "alpine (linux, musl)" or "openbsd"
+func (s Support) Host() string {
+	var details []string
+	if s.Distro != "" && s.Distro != s.OS {
+		details = append(details, s.OS)
+	}
+	if s.Libc == "musl" {
+		details = append(details, s.Libc)
+	}
+	name := s.OS
+	if s.Distro != "" {
+		name = s.Distro
+	}
+	if len(details) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(details, ", "))
+}
+
+// Report explains what catalyst skips on a host it can't install packages
+// on, what still works, and how to get the packages deps some other way
+func (s Support) Report(deps []string) string {
+	var b strings.Builder
+	reason := "no supported package manager was found"
+	if s.MissingPackageManager() {
+		reason = "no supported package manager is installed"
+	} else if s.NativeManager != "" {
+		reason = s.NativeManager + " is not supported"
+	}
+	fmt.Fprintf(&b, "catalyst can't install system packages on %s: %s.\n", s.Host(), reason)
+	fmt.Fprintln(&b, "  Still works:  scan, analyze, build, run, test, watch, external resources")
+	fmt.Fprintln(&b, "  Skipped:      installing system dependencies")
+	if len(deps) > 0 {
+		fmt.Fprintf(&b, "  Needed:       %s\n", strings.Join(deps, " "))
+		for _, m := range nativeManagers {
+			if m.name == s.NativeManager {
+				fmt.Fprintf(&b, "  Install them yourself (names may differ): %s <packages>\n", m.install)
+			}
+		}
+	}
+	if s.MissingPackageManager() {
+		fmt.Fprint(&b, strings.TrimPrefix(packageManagerSetupAdvice(s.OS), "\n"))
+		return b.String()
+	}
+	fmt.Fprintln(&b, "  Or build in a container of a supported Linux distribution:")
+	fmt.Fprintln(&b, `    docker run --rm -it -v "$PWD":/src -w /src debian:stable`)
+	return b.String()
+}
+
+// osReleaseID returns the ID from /etc/os-release, or "" if there is none
+func osReleaseID() string {
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "ID="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
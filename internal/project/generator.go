@@ -2,14 +2,18 @@ package project
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	core "github.com/Sabique-Islam/catalyst/internal/config"
 	"github.com/Sabique-Islam/catalyst/internal/fetch"
+	"github.com/Sabique-Islam/catalyst/internal/headers"
 	"github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/lock"
 	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
 	"github.com/Sabique-Islam/catalyst/internal/platform"
 	"github.com/Sabique-Islam/catalyst/internal/tui"
@@ -129,16 +133,69 @@ func InitializeProject() error {
 
 // InitializeProjectWithOptions runs the project initialization with additional options
 func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
+	printInitBanner()
+
+	// Run the interactive wizard
+	wizard, err := tui.RunInitWizard()
+	if err != nil {
+		return fmt.Errorf("initialization wizard failed: %w", err)
+	}
+	return initializeFromWizard(wizard, withAnalysis, installDeps)
+}
+
+// InitializeProjectFromAnswers runs the project initialization with the wizard
+// answers read from a file instead of prompts
+func InitializeProjectFromAnswers(answersPath string, withAnalysis, installDeps bool) error {
+	printInitBanner()
+
+	answers, err := tui.LoadAnswers(answersPath)
+	if err != nil {
+		return err
+	}
+	wizard, err := answers.Result()
+	if err != nil {
+		return fmt.Errorf("invalid answers in %s: %w", answersPath, err)
+	}
+	fmt.Printf("Using answers from %s\n", answersPath)
+	return initializeFromWizard(wizard, withAnalysis, installDeps)
+}
+
+// InitializeProjectNonInteractive runs the project initialization with
+// answers given up front (catalyst init --name ... --yes), without prompts
+func InitializeProjectNonInteractive(answers *tui.Answers, withAnalysis, installDeps bool) error {
+	printInitBanner()
+
+	wizard, err := answers.Result()
+	if err != nil {
+		return err
+	}
+	return initializeFromWizard(wizard, withAnalysis, installDeps)
+}
+
+// printInitBanner prints the init header
+func printInitBanner() {
 	fmt.Println("==============================================")
 	fmt.Println("     Catalyst Project Initialization          ")
 	fmt.Println("==============================================")
 	fmt.Println()
+}
 
-	// Run the interactive wizard
-	config, automate, err := tui.RunInitWizard()
-	if err != nil {
-		return fmt.Errorf("initialization wizard failed: %w", err)
+// initConfigFile returns the config init writes: the one the current
+// directory already has, catalyst.yml or catalyst.yaml, or catalyst.yml
+func initConfigFile() string {
+	root, name, ok := core.FindProject(".")
+	if cwd, err := os.Getwd(); ok && err == nil && root == cwd {
+		return name
 	}
+	return core.ConfigNames[0]
+}
+
+// initializeFromWizard scans, resolves and writes catalyst.yml from the wizard answers
+func initializeFromWizard(wizard *tui.WizardResult, withAnalysis, installDeps bool) error {
+	config, automate := wizard.Config, wizard.Automate
+	installDeps = installDeps || wizard.InstallDeps
+	configFile := initConfigFile()
+	core.ProjectFile = configFile
 
 	// Set metadata
 	config.CreatedAt = time.Now().Format(time.RFC3339)
@@ -148,7 +205,8 @@ func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
 		fmt.Println("Scanning project for dependencies...")
 
 		// If an entry point/source was provided by the wizard, respect it.
-		if len(config.Sources) == 0 {
+		explicitSources := len(config.Sources) > 0
+		if !explicitSources {
 			// Scan for source files
 			sources, err := scanSourceFiles(".")
 			if err != nil {
@@ -170,8 +228,14 @@ func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
 			config.Output = config.ProjectName
 		}
 
-		// Scan for dependencies
-		abstractDeps, err := fetch.ScanDependencies(".")
+		// Scan for dependencies, limited to what an explicit entry point actually includes
+		var abstractDeps []string
+		var err error
+		if explicitSources {
+			abstractDeps, err = fetch.ScanSourceDependencies(".", config.Sources, config.Includes)
+		} else {
+			abstractDeps, err = fetch.ScanDependencies(".")
+		}
 		if err != nil {
 			return fmt.Errorf("dependency scan failed: %w", err)
 		}
@@ -243,9 +307,26 @@ func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
 		}
 		includes := []string{}
 
-		for _, abstractName := range abstractDeps {
-			realPkgName, found := pkgdb.Translate(abstractName, pkgManager)
+		// Resolve with the method chosen in the wizard; it is saved so later
+		// commands (doctor, sync) resolve the same way
+		config.Resolution = resolutionForMethod(wizard.ResolutionMode)
+		resolver, err := pkgdb.NewResolver(pkgManager, config.Resolution)
+		if err != nil {
+			return err
+		}
+
+		// Keep the overrides of the config being replaced
+		if len(config.PackageOverrides) == 0 {
+			if existing, err := core.LoadConfig(configFile); err == nil {
+				config.PackageOverrides = existing.PackageOverrides
+			}
+		}
+		resolver.Overrides = config.PackageOverrides
+		localHeaders := fetch.LocalHeaderNames(".")
+		var resolved []pkgdb.ResolveResult
+		var windowsLibs []string
 
+		for _, abstractName := range abstractDeps {
 			// Add to includes list - ALL headers (both standard and external)
 			// Check if it already ends with .h to avoid double extension
 			if strings.HasSuffix(abstractName, ".h") {
@@ -254,39 +335,116 @@ func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
 				includes = append(includes, abstractName+".h")
 			}
 
-			if !found {
-				// Not in package database - likely a project-local header
+			if localHeaders[abstractName] {
 				fmt.Printf("%s is a local/project header\n", abstractName)
 				continue
 			}
 
-			// Skip empty package names (standard library headers)
-			if realPkgName == "" {
-				fmt.Printf("%s is a standard library header (no package needed)\n", abstractName)
+			// Windows SDK headers need no package, only their import libraries
+			if libs := headers.WindowsLibraries(abstractName); len(libs) > 0 {
+				fmt.Printf("%s is a Windows SDK header (links %s on Windows)\n", abstractName, strings.Join(libs, ", "))
+				windowsLibs = append(windowsLibs, libs...)
 				continue
 			}
 
-			// Get package names for all major OSes
-			darwinPkg, _ := pkgdb.Translate(abstractName, "brew")
-			linuxPkg, _ := pkgdb.Translate(abstractName, "apt")
-			windowsPkg, _ := pkgdb.Translate(abstractName, "vcpkg")
+			res, err := resolver.Resolve(abstractName)
+			if err != nil {
+				return err
+			}
+
+			if !res.Found {
+				// Nothing resolved it - likely a project-local header
+				fmt.Printf("%s is a local/project header\n", abstractName)
+				continue
+			}
 
-			if darwinPkg != "" {
-				allOsDeps["darwin"] = append(allOsDeps["darwin"], darwinPkg)
+			// Skip empty package names (standard library headers)
+			if res.Standard {
+				fmt.Printf("%s is a standard library header (no package needed)\n", abstractName)
+				continue
 			}
-			if linuxPkg != "" {
-				allOsDeps["linux"] = append(allOsDeps["linux"], linuxPkg)
+			// The toolchain provides it here; other platforms may still need a package
+			if res.Satisfied {
+				fmt.Printf("%s is provided by the compiler (%s), no package needed on this machine\n", abstractName, res.Path)
+			} else {
+				resolved = append(resolved, res)
 			}
-			if windowsPkg != "" {
-				allOsDeps["windows"] = append(allOsDeps["windows"], windowsPkg)
+			realPkgName := res.Package
+
+			// Get package names for all major OSes
+			for _, target := range []struct{ os, pkgManager string }{{"darwin", "brew"}, {"linux", "apt"}, {"windows", "vcpkg"}} {
+				// Use the host's own package manager for its platform (e.g. MacPorts)
+				if platform.PackageManagerOS(pkgManager) == target.os {
+					target.pkgManager = pkgManager
+				}
+				osRes := res
+				if target.pkgManager != pkgManager {
+					osRes, _ = resolver.ResolveFor(abstractName, target.pkgManager)
+				}
+				if osRes.Package != "" {
+					allOsDeps[target.os] = append(allOsDeps[target.os], osRes.Package)
+					// Record where the mapping came from so guesses can be re-verified later
+					config.AddProvenance(core.DependencySource{
+						Package:    osRes.Package,
+						Platform:   target.os,
+						Header:     abstractName,
+						Source:     osRes.Strategy,
+						Confidence: osRes.Confidence,
+					})
+				}
 			}
 
 			// Check if already installed on current system
+			if realPkgName == "" {
+				continue
+			}
 			if platform.IsPackageInstalled(realPkgName, pkgManager) {
-				fmt.Printf("%s is already installed\n", realPkgName)
+				fmt.Printf("%s is already installed (resolved via %s)\n", realPkgName, res.Strategy)
 			} else {
-				fmt.Printf("%s needs to be installed\n", realPkgName)
+				fmt.Printf("%s needs to be installed (resolved via %s)\n", realPkgName, res.Strategy)
+			}
+		}
+
+		// Automatic resolution accepts guesses without asking; review them all at once
+		if wizard.ReviewGuesses {
+			allOsDeps, config.Provenance, err = reviewGuesses(resolved, allOsDeps, config.Provenance, osName)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Leave out the dependencies the answers file drops
+		if len(wizard.DropDeps) > 0 {
+			dropped := make(map[string]bool)
+			for _, header := range wizard.DropDeps {
+				dropped[strings.TrimSuffix(header, ".h")] = true
+			}
+			allOsDeps, config.Provenance = dropHeaderDependencies(allOsDeps, config.Provenance, dropped)
+			fmt.Printf("Dropped dependencies for: %s\n", strings.Join(wizard.DropDeps, ", "))
+		}
+
+		// Let the user drop dependencies they don't want, on every platform
+		if wizard.ReviewDeps && len(resolved) > 0 {
+			fmt.Println()
+			labels := make([]string, len(resolved))
+			for i, res := range resolved {
+				labels[i] = fmt.Sprintf("%s → %s", res.Name, res.Package)
+			}
+			kept, err := tui.SelectDependencies(tui.NewPrompter(), labels)
+			if err != nil {
+				return err
+			}
+			keep := make(map[string]bool)
+			for _, label := range kept {
+				keep[label] = true
+			}
+			dropped := make(map[string]bool)
+			for i, label := range labels {
+				if !keep[label] {
+					dropped[resolved[i].Name] = true
+				}
 			}
+			allOsDeps, config.Provenance = dropHeaderDependencies(allOsDeps, config.Provenance, dropped)
 		}
 
 		// Remove duplicates from each OS dependency list
@@ -302,13 +460,27 @@ func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
 			allOsDeps[os] = uniqueList
 		}
 
-		// Clear the temporary resolution preference from Author field
-		config.Author = ""
+		// Pin the resolved packages so installs are reproducible
+		if err := lockResolvedPackages(allOsDeps, config.Provenance, osName, pkgManager); err != nil {
+			fmt.Printf("Warning: could not write %s: %v\n", lock.DefaultPath, err)
+		}
 
 		// Populate config with dependencies for all OSes
 		// allOsDeps is always initialized with all platforms
 		config.Dependencies = allOsDeps
 
+		// Link the Windows SDK libraries with MinGW
+		for _, flag := range headers.WindowsLinkFlags(windowsLibs, false) {
+			if config.Platforms == nil {
+				config.Platforms = make(map[string]core.PlatformConfig)
+			}
+			windows := config.Platforms["windows"]
+			if !containsFlag(windows.Flags, flag) {
+				windows.Flags = append(windows.Flags, flag)
+			}
+			config.Platforms["windows"] = windows
+		}
+
 		// Add includes to config
 		if len(includes) > 0 {
 			config.Includes = includes
@@ -337,14 +509,14 @@ func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
 		}
 
 		// Save config using standard method (now includes has its own field)
-		if err := saveConfig(config, "catalyst.yml"); err != nil {
+		if err := core.SaveConfig(config, configFile); err != nil {
 			return fmt.Errorf("failed to save configuration: %w", err)
 		}
 
 	} else {
 		// Manual mode - just save basic config
 		fmt.Println()
-		fmt.Println("Creating basic catalyst.yml template...")
+		fmt.Printf("Creating basic %s template...\n", configFile)
 		fmt.Println("   You'll need to manually add dependencies and includes.")
 
 		// Initialize empty dependency structure for all major platforms
@@ -355,19 +527,19 @@ func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
 			"windows": {},
 		}
 
-		if err := saveConfig(config, "catalyst.yml"); err != nil {
+		if err := core.SaveConfig(config, configFile); err != nil {
 			return fmt.Errorf("failed to save configuration: %w", err)
 		}
 	}
 
 	fmt.Println()
 	fmt.Println("Project initialized successfully!")
-	fmt.Printf("Configuration saved to: catalyst.yml\n")
+	fmt.Printf("Configuration saved to: %s\n", configFile)
 
 	if automate {
 		fmt.Println()
 		fmt.Println("Next steps:")
-		fmt.Println("  1. Review catalyst.yml")
+		fmt.Printf("  1. Review %s\n", configFile)
 		fmt.Println("  2. Run 'catalyst install' to install dependencies")
 		fmt.Println("  3. Run 'catalyst build' to compile your project")
 	}
@@ -375,16 +547,140 @@ func InitializeProjectWithOptions(withAnalysis, installDeps bool) error {
 	return nil
 }
 
-// saveConfig writes the config to a YAML file
-func saveConfig(cfg *core.Config, filename string) error {
-	data, err := yaml.Marshal(cfg)
+// reviewGuesses shows the host mappings accepted below pkgdb.ReviewConfidence
+// on one screen and applies the user's edits to the host dependencies.
+// Edited entries of resolved are updated in place.
+func reviewGuesses(resolved []pkgdb.ResolveResult, deps map[string][]string, provenance []core.DependencySource, hostOS string) (map[string][]string, []core.DependencySource, error) {
+	var guesses []tui.Mapping
+	var indexes []int
+	for i, res := range resolved {
+		if res.Confidence < pkgdb.ReviewConfidence {
+			guesses = append(guesses, tui.Mapping{Header: res.Name, Package: res.Package, Source: res.Strategy, Confidence: res.Confidence})
+			indexes = append(indexes, i)
+		}
+	}
+	if len(guesses) == 0 {
+		return deps, provenance, nil
+	}
+
+	reviewed, err := tui.ReviewMappings(tui.NewPrompter(), guesses)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, m := range reviewed {
+		old := guesses[i].Package
+		if m.Package == old {
+			continue
+		}
+		resolved[indexes[i]].Package = m.Package
+
+		var kept []string
+		for _, dep := range deps[hostOS] {
+			if dep != old {
+				kept = append(kept, dep)
+			} else if m.Package != "" {
+				kept = append(kept, m.Package)
+			}
+		}
+		deps[hostOS] = kept
+
+		var keptProvenance []core.DependencySource
+		for _, src := range provenance {
+			if src.Platform == hostOS && src.Header == m.Header && src.Package == old {
+				if m.Package == "" {
+					continue
+				}
+				src.Package, src.Source, src.Confidence = m.Package, m.Source, m.Confidence
+			}
+			keptProvenance = append(keptProvenance, src)
+		}
+		provenance = keptProvenance
+	}
+	return deps, provenance, nil
+}
+
+// containsFlag reports whether flags contains flag
+func containsFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// dropHeaderDependencies removes the packages recorded as resolving the given
+// headers from every platform's dependency list
+func dropHeaderDependencies(deps map[string][]string, provenance []core.DependencySource, headers map[string]bool) (map[string][]string, []core.DependencySource) {
+	drop := make(map[string]map[string]bool)
+	var keptProvenance []core.DependencySource
+	for _, src := range provenance {
+		if !headers[src.Header] {
+			keptProvenance = append(keptProvenance, src)
+			continue
+		}
+		if drop[src.Platform] == nil {
+			drop[src.Platform] = make(map[string]bool)
+		}
+		drop[src.Platform][src.Package] = true
+	}
+
+	// Packages still needed by a kept header stay
+	for _, src := range keptProvenance {
+		delete(drop[src.Platform], src.Package)
+	}
+
+	kept := make(map[string][]string)
+	for platform, pkgs := range deps {
+		kept[platform] = []string{}
+		for _, pkg := range pkgs {
+			if !drop[platform][pkg] {
+				kept[platform] = append(kept[platform], pkg)
+			}
+		}
+	}
+	return kept, keptProvenance
+}
+
+// lockResolvedPackages records the resolved packages of every platform in
+// catalyst.lock, with installed versions for the host platform
+func lockResolvedPackages(deps map[string][]string, provenance []core.DependencySource, hostOS, pkgManager string) error {
+	lf, err := lock.Load(lock.DefaultPath)
 	if err != nil {
-		return fmt.Errorf("failed to marshal YAML: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(filename, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	for _, osName := range slices.Sorted(maps.Keys(deps)) {
+		pkgs := deps[osName]
+		var locked []lock.LockedPackage
+		for _, pkg := range pkgs {
+			entry := lock.LockedPackage{Name: pkg, Platform: osName}
+			for _, src := range provenance {
+				if src.Platform == osName && src.Package == pkg {
+					entry.Header = src.Header
+					break
+				}
+			}
+			if osName == hostOS {
+				entry.Version, _ = platform.InstalledVersion(pkg, pkgManager)
+			}
+			locked = append(locked, entry)
+		}
+		lf.SetPlatformPackages(osName, locked)
 	}
 
-	return nil
+	return lf.Save(lock.DefaultPath)
+}
+
+// resolutionForMethod converts a wizard resolution method to the resolution
+// block of catalyst.yml. The automatic method uses the defaults.
+func resolutionForMethod(method string) core.Resolution {
+	switch method {
+	case tui.ResolutionInteractive:
+		return core.Resolution{Mode: pkgdb.ModeInteractive}
+	case tui.ResolutionDatabase:
+		return core.Resolution{Strategies: []string{pkgdb.StrategyStatic, pkgdb.StrategyLibrary}}
+	}
+	return core.Resolution{}
 }
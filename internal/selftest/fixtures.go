@@ -0,0 +1,54 @@
+package selftest
+
+// Fixture projects generated for the self-test
+
+const helloSource = `#include <stdio.h>
+
+int main(void) {
+    printf("catalyst selftest ok\n");
+    return 0;
+}
+`
+
+const multiConfig = `project_name: multi
+sources:
+  - src/main.c
+  - src/geometry.c
+flags:
+  - -Iinclude
+dependencies: {}
+`
+
+const multiMainSource = `#include <stdio.h>
+#include "geometry.h"
+
+int main(void) {
+    printf("hypot = %.1f\n", hypotenuse(3.0, 4.0));
+    return 0;
+}
+`
+
+const geometrySource = `#include <math.h>
+#include "geometry.h"
+
+double hypotenuse(double a, double b) {
+    return sqrt(a * a + b * b);
+}
+`
+
+const geometryHeader = `#ifndef GEOMETRY_H
+#define GEOMETRY_H
+
+double hypotenuse(double a, double b);
+
+#endif
+`
+
+// depsConfig takes the OS and the fake package name
+const depsConfig = `project_name: deps
+sources:
+  - main.c
+dependencies:
+  %s:
+    - %s
+`
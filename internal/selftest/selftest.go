@@ -0,0 +1,270 @@
+package selftest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// fakePackage is the dependency the fake package manager is asked to install
+const fakePackage = "catalyst-selftest-fake-dev"
+
+// Options configures a self-test run
+type Options struct {
+	Binary  string // catalyst executable to test
+	WorkDir string // Directory for the fixtures; a temporary directory if empty
+	Keep    bool   // Keep the fixtures after the run
+}
+
+// Result is the outcome of one self-test step
+type Result struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// step runs catalyst against a fixture project and checks the outcome
+type step struct {
+	name string
+	run  func(h *harness) error
+}
+
+// harness runs catalyst commands inside generated fixture projects
+type harness struct {
+	binary string
+	root   string
+	env    []string // Extra environment for catalyst processes
+}
+
+// Run generates tiny C fixtures and runs scan, init, build, run and install
+// against them, stopping at the first failed step because later steps build
+// on earlier ones
+func Run(opts Options) ([]Result, error) {
+	binary, err := filepath.Abs(opts.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("invalid catalyst binary: %w", err)
+	}
+
+	root := opts.WorkDir
+	if root == "" {
+		root, err = util.MkdirTemp("selftest-")
+		if err != nil {
+			return nil, fmt.Errorf("cannot create fixture directory: %w", err)
+		}
+	}
+	if !opts.Keep {
+		defer os.RemoveAll(root)
+	}
+
+	h := &harness{binary: binary, root: root}
+	var results []Result
+	for _, s := range steps() {
+		start := time.Now()
+		err := s.run(h)
+		results = append(results, Result{Name: s.name, Err: err, Duration: time.Since(start)})
+		if err != nil {
+			break
+		}
+	}
+	return results, nil
+}
+
+// steps returns the self-test steps in the order they run
+func steps() []step {
+	all := []step{
+		{"C compiler available", checkCompiler},
+		{"scan finds only standard headers", scanHello},
+		{"init from an answers file", initHello},
+		{"build a single-file project", buildHello},
+		{"run the built binary", runHello},
+		{"build and run a multi-file project with libm", buildMultiFile},
+	}
+	// The fake package manager is a shell script
+	if runtime.GOOS != "windows" {
+		all = append(all, step{"install through a fake package manager", installWithShim})
+	}
+	return all
+}
+
+func checkCompiler(h *harness) error {
+	for _, cc := range []string{"gcc", "clang"} {
+		if _, err := exec.LookPath(cc); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("neither gcc nor clang is in PATH - run 'catalyst doctor' or install a C compiler")
+}
+
+func scanHello(h *harness) error {
+	dir, err := h.fixture("hello", map[string]string{"main.c": helloSource})
+	if err != nil {
+		return err
+	}
+	out, err := h.catalyst(dir, "scan")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, "No external dependencies found") {
+		return fmt.Errorf("expected stdio.h to be treated as a standard header, got:\n%s", out)
+	}
+	return nil
+}
+
+func initHello(h *harness) error {
+	dir := filepath.Join(h.root, "hello")
+	answers := "project_name: hello\nautomate: true\nresolution: database\ninstall: false\n"
+	if err := os.WriteFile(filepath.Join(dir, "answers.yml"), []byte(answers), 0644); err != nil {
+		return err
+	}
+	if _, err := h.catalyst(dir, "init", "--answers", "answers.yml"); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig(filepath.Join(dir, "catalyst.yml"))
+	if err != nil {
+		return fmt.Errorf("init wrote an unreadable catalyst.yml: %w", err)
+	}
+	if cfg.ProjectName != "hello" {
+		return fmt.Errorf("expected project_name hello in catalyst.yml, got %q", cfg.ProjectName)
+	}
+	if !slices.Contains(cfg.Sources, "main.c") {
+		return fmt.Errorf("expected main.c in the sources of catalyst.yml, got %v", cfg.Sources)
+	}
+	return nil
+}
+
+func buildHello(h *harness) error {
+	dir := filepath.Join(h.root, "hello")
+	if _, err := h.catalyst(dir, "build"); err != nil {
+		return err
+	}
+	return expectBinary(dir, "hello")
+}
+
+func runHello(h *harness) error {
+	out, err := h.catalyst(filepath.Join(h.root, "hello"), "run")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, "catalyst selftest ok") {
+		return fmt.Errorf("expected the program's output, got:\n%s", out)
+	}
+	return nil
+}
+
+func buildMultiFile(h *harness) error {
+	dir, err := h.fixture("multi", map[string]string{
+		"catalyst.yml":       multiConfig,
+		"src/main.c":         multiMainSource,
+		"src/geometry.c":     geometrySource,
+		"include/geometry.h": geometryHeader,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := h.catalyst(dir, "build"); err != nil {
+		return err
+	}
+	if err := expectBinary(dir, "multi"); err != nil {
+		return err
+	}
+
+	out, err := h.catalyst(dir, "run")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, "hypot = 5.0") {
+		return fmt.Errorf("expected the program to print hypot = 5.0, got:\n%s", out)
+	}
+	return nil
+}
+
+func installWithShim(h *harness) error {
+	dir, err := h.fixture("deps", map[string]string{
+		"catalyst.yml": fmt.Sprintf(depsConfig, runtime.GOOS, fakePackage),
+		"main.c":       helloSource,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Every package manager catalyst drives on Linux and macOS runs through
+	// sudo, brew or port, so shimming those records the install without
+	// touching the system
+	shimDir := filepath.Join(h.root, "shim")
+	logPath := filepath.Join(h.root, "shim.log")
+	if err := os.MkdirAll(shimDir, 0755); err != nil {
+		return err
+	}
+	shim := "#!/bin/sh\necho \"$(basename \"$0\") $*\" >> \"" + logPath + "\"\nexit 0\n"
+	for _, name := range []string{"sudo", "brew", "port"} {
+		if err := os.WriteFile(filepath.Join(shimDir, name), []byte(shim), 0755); err != nil {
+			return err
+		}
+	}
+
+	shimmed := &harness{
+		binary: h.binary,
+		root:   h.root,
+		env:    []string{"PATH=" + shimDir + string(os.PathListSeparator) + os.Getenv("PATH")},
+	}
+	if _, err := shimmed.catalyst(dir, "install", "--deps-only"); err != nil {
+		return err
+	}
+
+	log, err := os.ReadFile(logPath)
+	if err != nil {
+		return fmt.Errorf("the package manager was never called")
+	}
+	if !strings.Contains(string(log), fakePackage) {
+		return fmt.Errorf("expected %s to be installed, package manager calls were:\n%s", fakePackage, log)
+	}
+	return nil
+}
+
+// fixture writes a fixture project and returns its directory
+func (h *harness) fixture(name string, files map[string]string) (string, error) {
+	dir := filepath.Join(h.root, name)
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// catalyst runs a catalyst command in dir and returns its combined output
+func (h *harness) catalyst(dir string, args ...string) (string, error) {
+	cmd := exec.Command(h.binary, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), h.env...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("catalyst %s failed: %w\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out), nil
+}
+
+// expectBinary checks that a build produced build/<name>
+func expectBinary(dir, name string) error {
+	path := filepath.Join(dir, "build", name)
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("expected the build to produce %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	core "github.com/Sabique-Islam/catalyst/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Answers are predefined wizard responses, so the init wizard can be replayed
+// without prompts (catalyst init --answers answers.yml)
+type Answers struct {
+	ProjectName      string   `yaml:"project_name"`
+	Author           string   `yaml:"author,omitempty"`
+	Entry            string   `yaml:"entry,omitempty"`
+	Sources          []string `yaml:"sources,omitempty"` // Source files, added after the entry point
+	Output           string   `yaml:"output,omitempty"`
+	Automate         bool     `yaml:"automate"`
+	Resolution       string   `yaml:"resolution,omitempty"`
+	DropDependencies []string `yaml:"drop_dependencies,omitempty"` // Headers whose packages are left out
+	Install          bool     `yaml:"install,omitempty"`
+	// Written to catalyst.yml and used instead of resolving these headers
+	PackageOverrides core.PackageOverrides `yaml:"package_overrides,omitempty"`
+}
+
+// AnswersTemplate is a commented answers file covering every wizard question
+const AnswersTemplate = `# Answers for 'catalyst init --answers <file>'
+
+# Project name (required)
+project_name: myproject
+
+# Author written to catalyst.yml (optional)
+author: ""
+
+# Main source file; leave entry and sources empty to scan the project
+entry: ""
+
+# Further source files compiled with the entry point
+sources: []
+
+# Output binary name; defaults to the project name
+output: ""
+
+# Scan the sources and resolve dependencies (false writes an empty template)
+automate: true
+
+# Dependency resolution method: auto, interactive or database
+resolution: auto
+
+# Headers whose resolved packages should be left out, e.g. [png, zlib]
+drop_dependencies: []
+
+# Install the resolved dependencies after init
+install: false
+
+# Packages to use for headers instead of resolving them, by package
+# manager or platform, e.g.
+#   libpq-fe:
+#     apt: internal-libpq-dev
+#     darwin: libpq
+package_overrides: {}
+`
+
+// LoadAnswers reads a wizard answers file
+func LoadAnswers(path string) (*Answers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read answers file: %w", err)
+	}
+
+	var answers Answers
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&answers); err != nil {
+		return nil, fmt.Errorf("invalid answers file %s: %w", path, err)
+	}
+	return &answers, nil
+}
+
+// Result validates the answers and converts them to a wizard result
+func (a *Answers) Result() (*WizardResult, error) {
+	if err := validateProjectName(a.ProjectName); err != nil {
+		return nil, err
+	}
+	if err := validateEntry(a.Entry); err != nil {
+		return nil, fmt.Errorf("entry: %w", err)
+	}
+	for _, source := range a.Sources {
+		if err := validateEntry(source); err != nil {
+			return nil, fmt.Errorf("sources: %w", err)
+		}
+	}
+
+	cfg := &core.Config{
+		ProjectName: strings.TrimSpace(a.ProjectName),
+		Author:      strings.TrimSpace(a.Author),
+		Output:      strings.TrimSpace(a.Output),
+
+		PackageOverrides: a.PackageOverrides,
+	}
+	if cfg.Output == "" {
+		cfg.Output = cfg.ProjectName
+	}
+	for _, source := range append([]string{a.Entry}, a.Sources...) {
+		if source = strings.TrimSpace(source); source != "" && !slices.Contains(cfg.Sources, source) {
+			cfg.Sources = append(cfg.Sources, source)
+		}
+	}
+
+	result := &WizardResult{
+		Config:      cfg,
+		Automate:    a.Automate,
+		InstallDeps: a.Install,
+		DropDeps:    a.DropDependencies,
+	}
+	if a.Automate {
+		mode, err := ParseResolutionMethod(a.Resolution)
+		if err != nil {
+			return nil, fmt.Errorf("resolution: %w", err)
+		}
+		result.ResolutionMode = mode
+	}
+
+	return result, nil
+}
@@ -1 +1,88 @@
 package tui
+
+import (
+	"fmt"
+
+	"github.com/Sabique-Islam/catalyst/internal/util"
+)
+
+// Main menu actions returned by RunMainMenu
+const (
+	MenuSetup         = "Setup (Prepare this machine for catalyst)"
+	MenuSmartInit     = "Smart Init (Auto-detect & generate config)"
+	MenuInit          = "Init (Create catalyst.yml)"
+	MenuAnalyze       = "Analyze (Show project structure)"
+	MenuScan          = "Scan (Find dependencies)"
+	MenuInstall       = "Install (Install dependencies)"
+	MenuAddDependency = "Add Dependency"
+	MenuBuild         = "Build"
+	MenuRun           = "Run"
+	MenuTest          = "Test"
+	MenuClean         = "Clean"
+	MenuOpenMember    = "Open Member Project"
+	MenuBackToRoot    = "Back to Workspace"
+	MenuExit          = "Exit"
+)
+
+// MenuContext is what the main menu knows about the current directory
+type MenuContext struct {
+	HasConfig   bool     // The directory has a catalyst.yml or catalyst.yaml
+	Members     []string // Subdirectories with their own config (a workspace)
+	InWorkspace bool     // A member was opened from a workspace
+	NeedsSetup  bool     // catalyst setup hasn't run on this machine
+}
+
+// mainMenuItems returns the actions that apply in ctx
+func mainMenuItems(ctx MenuContext) []string {
+	var items []string
+	switch {
+	case ctx.HasConfig:
+		items = []string{MenuBuild, MenuRun, MenuTest, MenuAddDependency, MenuInstall, MenuClean, MenuAnalyze}
+		if ctx.InWorkspace {
+			items = append(items, MenuBackToRoot)
+		}
+	case len(ctx.Members) > 0:
+		items = []string{MenuOpenMember, MenuAnalyze, MenuSmartInit}
+	default:
+		items = []string{MenuSmartInit, MenuInit, MenuAnalyze, MenuScan}
+	}
+	if ctx.NeedsSetup {
+		items = append([]string{MenuSetup}, items...)
+	}
+	return append(items, MenuExit)
+}
+
+// RunMainMenu displays the actions that apply in ctx and returns the
+// selected one
+func RunMainMenu(ctx MenuContext) (string, error) {
+	items := mainMenuItems(ctx)
+	idx, err := NewPrompter().Select("Select an option", items)
+	if err != nil {
+		return "", promptError("menu", err)
+	}
+	return items[idx], nil
+}
+
+// SelectMember asks which workspace member to open
+func SelectMember(members []string) (string, error) {
+	idx, err := NewPrompter().Select("Select a project", members)
+	if err != nil {
+		return "", promptError("member", err)
+	}
+	return members[idx], nil
+}
+
+// AskArguments asks for the flags and arguments to run a menu action with,
+// showing its command line usage. Words are split like a shell does, with
+// single or double quotes grouping words; an empty answer uses the defaults.
+func AskArguments(usage string) ([]string, error) {
+	validate := func(input string) error {
+		_, err := util.SplitArguments(input)
+		return err
+	}
+	input, err := NewPrompter().Input(fmt.Sprintf("Options for %s (Enter for defaults, --help to list them)", usage), "", validate)
+	if err != nil {
+		return nil, promptError("options", err)
+	}
+	return util.SplitArguments(input)
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mapping is a header resolved to a package, shown for review
+type Mapping struct {
+	Header     string
+	Package    string // Empty when the user dropped the mapping
+	Source     string // Resolution step that produced it
+	Confidence int
+}
+
+// ReviewMappings lists low-confidence mappings on one screen and lets the user
+// accept them all or edit each one. Edited mappings get the "user" source;
+// clearing a package or entering "-" drops the mapping.
+func ReviewMappings(p Prompter, mappings []Mapping) ([]Mapping, error) {
+	if len(mappings) == 0 {
+		return mappings, nil
+	}
+
+	fmt.Println()
+	fmt.Printf("%d dependencies were resolved with low confidence:\n", len(mappings))
+	for i, m := range mappings {
+		fmt.Printf("  %d. %s → %s (%s, %d%%)\n", i+1, m.Header, m.Package, m.Source, m.Confidence)
+	}
+
+	idx, err := p.Select("Accept these mappings?", []string{"Accept all", "Edit individually"})
+	if err != nil {
+		return nil, promptError("mapping review", err)
+	}
+	if idx == 0 {
+		return mappings, nil
+	}
+
+	reviewed := make([]Mapping, len(mappings))
+	for i, m := range mappings {
+		answer, err := p.Input(fmt.Sprintf("Package for %s (- to drop)", m.Header), m.Package, nil)
+		if err != nil {
+			return nil, promptError("mapping review", err)
+		}
+
+		answer = strings.TrimSpace(answer)
+		switch answer {
+		case m.Package:
+		case "", "-":
+			m.Package = ""
+			m.Source, m.Confidence = "user", 100
+		default:
+			m.Package = answer
+			m.Source, m.Confidence = "user", 100
+		}
+		reviewed[i] = m
+	}
+	return reviewed, nil
+}
@@ -0,0 +1,455 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TeaPrompter asks wizard questions on the terminal using Bubble Tea. Besides
+// Input and Select it offers checkbox multi-select and list editing.
+type TeaPrompter struct{}
+
+// NewPrompter returns the prompter used for interactive terminal sessions
+func NewPrompter() Prompter {
+	return TeaPrompter{}
+}
+
+// Input asks for a line of text
+func (TeaPrompter) Input(label, defaultValue string, validate func(string) error) (string, error) {
+	m, err := runModel(&inputModel{label: label, defaultValue: defaultValue, validate: validate})
+	if err != nil {
+		return "", err
+	}
+	return m.(*inputModel).result(), nil
+}
+
+// Select asks the user to pick one of items
+func (TeaPrompter) Select(label string, items []string) (int, error) {
+	if len(items) == 0 {
+		return 0, fmt.Errorf("%s: nothing to choose from", label)
+	}
+	m, err := runModel(&selectModel{label: label, items: items})
+	if err != nil {
+		return 0, err
+	}
+	return m.(*selectModel).cursor, nil
+}
+
+// MultiSelect shows items as checkboxes and returns the checked indexes
+func (TeaPrompter) MultiSelect(label string, items []string, checked []bool) ([]int, error) {
+	m := &multiSelectModel{label: label, items: items, checked: append([]bool{}, checked...)}
+	for len(m.checked) < len(items) {
+		m.checked = append(m.checked, false)
+	}
+	if _, err := runModel(m); err != nil {
+		return nil, err
+	}
+
+	var indexes []int
+	for i, on := range m.checked {
+		if on {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+// EditList lets the user add, change and remove items of a list
+func (TeaPrompter) EditList(label string, items []string, validate func(string) error) ([]string, error) {
+	m, err := runModel(&listModel{label: label, items: append([]string{}, items...), validate: validate, editing: -1})
+	if err != nil {
+		return nil, err
+	}
+	return m.(*listModel).items, nil
+}
+
+// cancellable is a model that records whether the user cancelled it
+type cancellable interface {
+	tea.Model
+	cancelled() bool
+}
+
+// runModel runs a prompt until it quits, returning errCancelled on Ctrl+C or Esc
+func runModel(m cancellable) (tea.Model, error) {
+	final, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return nil, err
+	}
+	if final.(cancellable).cancelled() {
+		return nil, errCancelled
+	}
+	return final, nil
+}
+
+// isCancel reports whether a key cancels the prompt
+func isCancel(msg tea.KeyMsg) bool {
+	return msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc
+}
+
+// lineEditor is a single-line text field with a cursor
+type lineEditor struct {
+	value  []rune
+	cursor int
+}
+
+func (e *lineEditor) set(value string) {
+	e.value = []rune(value)
+	e.cursor = len(e.value)
+}
+
+// handle applies an editing key and reports whether it was one
+func (e *lineEditor) handle(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		runes := msg.Runes
+		if msg.Type == tea.KeySpace {
+			runes = []rune{' '}
+		}
+		e.value = append(e.value[:e.cursor], append(runes, e.value[e.cursor:]...)...)
+		e.cursor += len(runes)
+	case tea.KeyBackspace:
+		if e.cursor > 0 {
+			e.value = append(e.value[:e.cursor-1], e.value[e.cursor:]...)
+			e.cursor--
+		}
+	case tea.KeyDelete:
+		if e.cursor < len(e.value) {
+			e.value = append(e.value[:e.cursor], e.value[e.cursor+1:]...)
+		}
+	case tea.KeyLeft:
+		if e.cursor > 0 {
+			e.cursor--
+		}
+	case tea.KeyRight:
+		if e.cursor < len(e.value) {
+			e.cursor++
+		}
+	case tea.KeyHome, tea.KeyCtrlA:
+		e.cursor = 0
+	case tea.KeyEnd, tea.KeyCtrlE:
+		e.cursor = len(e.value)
+	default:
+		return false
+	}
+	return true
+}
+
+func (e *lineEditor) view() string {
+	if e.cursor == len(e.value) {
+		return string(e.value) + "█"
+	}
+	return string(e.value[:e.cursor]) + "\033[7m" + string(e.value[e.cursor]) + "\033[0m" + string(e.value[e.cursor+1:])
+}
+
+// inputModel asks for a line of text, falling back to a default when empty
+type inputModel struct {
+	label        string
+	defaultValue string
+	validate     func(string) error
+	editor       lineEditor
+	err          error
+	done, quit   bool
+}
+
+func (m *inputModel) Init() tea.Cmd   { return nil }
+func (m *inputModel) cancelled() bool { return m.quit }
+
+func (m *inputModel) result() string {
+	if value := string(m.editor.value); value != "" {
+		return value
+	}
+	return m.defaultValue
+}
+
+func (m *inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case isCancel(key):
+		m.quit = true
+		return m, tea.Quit
+	case key.Type == tea.KeyEnter:
+		if m.validate != nil {
+			if m.err = m.validate(m.result()); m.err != nil {
+				return m, nil
+			}
+		}
+		m.done = true
+		return m, tea.Quit
+	case m.editor.handle(key):
+		m.err = nil
+	}
+	return m, nil
+}
+
+func (m *inputModel) View() string {
+	if m.done {
+		return fmt.Sprintf("✔ %s: %s\n", m.label, m.result())
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "? %s", m.label)
+	if m.defaultValue != "" {
+		fmt.Fprintf(&sb, " (%s)", m.defaultValue)
+	}
+	fmt.Fprintf(&sb, ": %s\n", m.editor.view())
+	if m.err != nil {
+		fmt.Fprintf(&sb, "  ✗ %v\n", m.err)
+	}
+	return sb.String()
+}
+
+// selectModel picks one item from a list
+type selectModel struct {
+	label      string
+	items      []string
+	cursor     int
+	done, quit bool
+}
+
+func (m *selectModel) Init() tea.Cmd   { return nil }
+func (m *selectModel) cancelled() bool { return m.quit }
+
+func (m *selectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case isCancel(key):
+		m.quit = true
+		return m, tea.Quit
+	case key.Type == tea.KeyEnter && len(m.items) > 0:
+		m.done = true
+		return m, tea.Quit
+	default:
+		m.cursor = moveCursor(key, m.cursor, len(m.items))
+	}
+	return m, nil
+}
+
+func (m *selectModel) View() string {
+	if m.done {
+		return fmt.Sprintf("✔ %s %s\n", m.label, m.items[m.cursor])
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "? %s\n", m.label)
+	for i, item := range m.items {
+		fmt.Fprintf(&sb, "%s %s\n", pointer(i == m.cursor), item)
+	}
+	sb.WriteString("  ↑/↓ move • enter select • esc cancel\n")
+	return sb.String()
+}
+
+// multiSelectModel toggles any number of items with checkboxes
+type multiSelectModel struct {
+	label      string
+	items      []string
+	checked    []bool
+	cursor     int
+	done, quit bool
+}
+
+func (m *multiSelectModel) Init() tea.Cmd   { return nil }
+func (m *multiSelectModel) cancelled() bool { return m.quit }
+
+func (m *multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch {
+	case isCancel(key):
+		m.quit = true
+		return m, tea.Quit
+	case key.Type == tea.KeyEnter:
+		m.done = true
+		return m, tea.Quit
+	case key.Type == tea.KeySpace || key.String() == "x":
+		if len(m.items) > 0 {
+			m.checked[m.cursor] = !m.checked[m.cursor]
+		}
+	case key.String() == "a":
+		// Check everything, or uncheck everything if all are checked
+		all := true
+		for _, on := range m.checked {
+			all = all && on
+		}
+		for i := range m.checked {
+			m.checked[i] = !all
+		}
+	default:
+		m.cursor = moveCursor(key, m.cursor, len(m.items))
+	}
+	return m, nil
+}
+
+func (m *multiSelectModel) View() string {
+	var sb strings.Builder
+	if m.done {
+		count := 0
+		for _, on := range m.checked {
+			if on {
+				count++
+			}
+		}
+		fmt.Fprintf(&sb, "✔ %s %d of %d selected\n", m.label, count, len(m.items))
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "? %s\n", m.label)
+	for i, item := range m.items {
+		box := "[ ]"
+		if m.checked[i] {
+			box = "[x]"
+		}
+		fmt.Fprintf(&sb, "%s %s %s\n", pointer(i == m.cursor), box, item)
+	}
+	sb.WriteString("  ↑/↓ move • space toggle • a all/none • enter confirm • esc cancel\n")
+	return sb.String()
+}
+
+// listModel edits a list of strings: add, change and remove items
+type listModel struct {
+	label      string
+	items      []string
+	validate   func(string) error
+	cursor     int
+	editing    int // Index being edited, len(items) when adding, -1 when browsing
+	editor     lineEditor
+	err        error
+	done, quit bool
+}
+
+func (m *listModel) Init() tea.Cmd   { return nil }
+func (m *listModel) cancelled() bool { return m.quit }
+
+func (m *listModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if key.Type == tea.KeyCtrlC {
+		m.quit = true
+		return m, tea.Quit
+	}
+	if m.editing >= 0 {
+		m.updateEditing(key)
+		return m, nil
+	}
+
+	switch {
+	case key.Type == tea.KeyEsc:
+		m.quit = true
+		return m, tea.Quit
+	case key.Type == tea.KeyEnter:
+		m.done = true
+		return m, tea.Quit
+	case key.String() == "a":
+		m.editing = len(m.items)
+		m.editor.set("")
+	case key.String() == "e" && len(m.items) > 0:
+		m.editing = m.cursor
+		m.editor.set(m.items[m.cursor])
+	case (key.String() == "d" || key.Type == tea.KeyDelete || key.Type == tea.KeyBackspace) && len(m.items) > 0:
+		m.items = append(m.items[:m.cursor], m.items[m.cursor+1:]...)
+		if m.cursor >= len(m.items) && m.cursor > 0 {
+			m.cursor--
+		}
+	default:
+		m.cursor = moveCursor(key, m.cursor, len(m.items))
+	}
+	return m, nil
+}
+
+// updateEditing handles keys while an item is being added or changed
+func (m *listModel) updateEditing(key tea.KeyMsg) {
+	switch key.Type {
+	case tea.KeyEsc:
+		m.editing, m.err = -1, nil
+	case tea.KeyEnter:
+		value := strings.TrimSpace(string(m.editor.value))
+		if value == "" {
+			m.editing, m.err = -1, nil
+			return
+		}
+		if m.validate != nil {
+			if m.err = m.validate(value); m.err != nil {
+				return
+			}
+		}
+		if m.editing == len(m.items) {
+			m.items = append(m.items, value)
+		} else {
+			m.items[m.editing] = value
+		}
+		m.cursor, m.editing = m.editing, -1
+	default:
+		if m.editor.handle(key) {
+			m.err = nil
+		}
+	}
+}
+
+func (m *listModel) View() string {
+	var sb strings.Builder
+	if m.done {
+		value := strings.Join(m.items, ", ")
+		if value == "" {
+			value = "(none)"
+		}
+		fmt.Fprintf(&sb, "✔ %s: %s\n", m.label, value)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "? %s\n", m.label)
+	if len(m.items) == 0 && m.editing < 0 {
+		sb.WriteString("  (empty)\n")
+	}
+	for i, item := range m.items {
+		if i == m.editing {
+			item = m.editor.view()
+		}
+		fmt.Fprintf(&sb, "%s %s\n", pointer(i == m.cursor && m.editing < 0 || i == m.editing), item)
+	}
+	if m.editing == len(m.items) {
+		fmt.Fprintf(&sb, "%s %s\n", pointer(true), m.editor.view())
+	}
+	if m.err != nil {
+		fmt.Fprintf(&sb, "  ✗ %v\n", m.err)
+	}
+	if m.editing >= 0 {
+		sb.WriteString("  enter save • esc discard\n")
+	} else {
+		sb.WriteString("  ↑/↓ move • a add • e edit • d remove • enter done • esc cancel\n")
+	}
+	return sb.String()
+}
+
+// moveCursor moves a list cursor for arrow and vi keys, wrapping around
+func moveCursor(key tea.KeyMsg, cursor, count int) int {
+	if count == 0 {
+		return 0
+	}
+	switch key.String() {
+	case "up", "k", "shift+tab":
+		return (cursor - 1 + count) % count
+	case "down", "j", "tab":
+		return (cursor + 1) % count
+	case "home", "g":
+		return 0
+	case "end", "G":
+		return count - 1
+	}
+	return cursor
+}
+
+// pointer marks the current line of a list
+func pointer(current bool) string {
+	if current {
+		return "❯"
+	}
+	return " "
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press feeds keys to a model the way a Bubble Tea program would
+func press(m tea.Model, keys ...tea.KeyMsg) tea.Model {
+	for _, key := range keys {
+		m, _ = m.Update(key)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var (
+	keyUp    = tea.KeyMsg{Type: tea.KeyUp}
+	keyDown  = tea.KeyMsg{Type: tea.KeyDown}
+	keySpace = tea.KeyMsg{Type: tea.KeySpace}
+	keyEnter = tea.KeyMsg{Type: tea.KeyEnter}
+	keyEsc   = tea.KeyMsg{Type: tea.KeyEsc}
+)
+
+func TestMultiSelectModel(t *testing.T) {
+	m := &multiSelectModel{items: []string{"zlib", "png", "curl"}, checked: []bool{true, true, true}}
+
+	press(m, keyDown, keySpace, keyUp, keyUp, keySpace, keyEnter)
+	if want := []bool{true, false, false}; !reflect.DeepEqual(m.checked, want) {
+		t.Errorf("checked = %v, want %v", m.checked, want)
+	}
+	if !m.done || m.cancelled() {
+		t.Error("expected enter to confirm the selection")
+	}
+
+	press(m, runes("a"))
+	if want := []bool{true, true, true}; !reflect.DeepEqual(m.checked, want) {
+		t.Errorf("a should check everything when some are unchecked, got %v", m.checked)
+	}
+}
+
+func TestSelectModelEmpty(t *testing.T) {
+	m := &selectModel{label: "Compiler"}
+	press(m, keyDown, keyEnter)
+	if m.done {
+		t.Error("enter chose an item of an empty list")
+	}
+	m.View()
+
+	if _, err := (TeaPrompter{}).Select("Compiler", nil); err == nil {
+		t.Error("Select() with no items succeeded")
+	}
+}
+
+func TestListModel(t *testing.T) {
+	validate := func(path string) error {
+		if path == "missing.c" {
+			return errors.New("file does not exist")
+		}
+		return nil
+	}
+	m := &listModel{items: []string{"main.c"}, validate: validate, editing: -1}
+
+	// Adding a missing file is rejected and stays in the editor
+	press(m, runes("a"), runes("missing.c"), keyEnter)
+	if m.err == nil || m.editing != 1 || len(m.items) != 1 {
+		t.Fatalf("expected the invalid path to be rejected, got items %v, err %v", m.items, m.err)
+	}
+
+	// Fix the path, then rename main.c to main.cpp
+	for range "missing.c" {
+		press(m, tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	press(m, runes("net.c"), keyEnter)
+	press(m, keyUp, runes("e"), tea.KeyMsg{Type: tea.KeyBackspace}, runes("cpp"), keyEnter)
+	if want := []string{"main.cpp", "net.c"}; !reflect.DeepEqual(m.items, want) {
+		t.Errorf("items = %v, want %v", m.items, want)
+	}
+
+	// Esc discards an edit instead of cancelling the prompt
+	press(m, runes("a"), runes("util.c"), keyEsc)
+	press(m, keyDown, runes("d"), keyEnter)
+	if want := []string{"main.cpp"}; !reflect.DeepEqual(m.items, want) || !m.done || m.cancelled() {
+		t.Errorf("items = %v (done %v), want %v", m.items, m.done, want)
+	}
+}
+
+func TestInputModelDefault(t *testing.T) {
+	m := &inputModel{defaultValue: "demo"}
+	press(m, keyEnter)
+	if m.result() != "demo" {
+		t.Errorf("empty input should give the default, got %q", m.result())
+	}
+
+	m = &inputModel{defaultValue: "demo"}
+	press(m, runes("app"), keyEsc)
+	if !m.cancelled() {
+		t.Error("expected esc to cancel the prompt")
+	}
+}
+
+// checkboxPrompter answers dependency selection with a fixed checkbox state
+type checkboxPrompter struct {
+	scriptedPrompter
+	keep []int
+}
+
+func (p *checkboxPrompter) MultiSelect(label string, items []string, checked []bool) ([]int, error) {
+	return p.keep, nil
+}
+
+func TestSelectDependenciesMultiSelect(t *testing.T) {
+	kept, err := SelectDependencies(&checkboxPrompter{keep: []int{0, 2}}, []string{"zlib", "png", "curl"})
+	if err != nil || !reflect.DeepEqual(kept, []string{"zlib", "curl"}) {
+		t.Errorf("SelectDependencies = %v, %v", kept, err)
+	}
+}
+
+func TestMainMenuItems(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  MenuContext
+		want []string
+	}{
+		{"no config", MenuContext{}, []string{MenuSmartInit, MenuInit, MenuAnalyze, MenuScan, MenuExit}},
+		{"workspace", MenuContext{Members: []string{"client", "server"}}, []string{MenuOpenMember, MenuAnalyze, MenuSmartInit, MenuExit}},
+		{"member", MenuContext{HasConfig: true, InWorkspace: true}, []string{MenuBuild, MenuRun, MenuTest, MenuAddDependency, MenuInstall, MenuClean, MenuAnalyze, MenuBackToRoot, MenuExit}},
+		{"first run", MenuContext{HasConfig: true, NeedsSetup: true}, []string{MenuSetup, MenuBuild, MenuRun, MenuTest, MenuAddDependency, MenuInstall, MenuClean, MenuAnalyze, MenuExit}},
+	}
+	for _, tt := range tests {
+		if got := mainMenuItems(tt.ctx); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: items = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1 +1,380 @@
-package tui
\ No newline at end of file
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+	core "github.com/Sabique-Islam/catalyst/internal/config"
+)
+
+// Dependency resolution methods offered by the init wizard
+const (
+	ResolutionAuto        = "auto"        // Database + dynamic search without prompts
+	ResolutionInteractive = "interactive" // Ask when multiple packages are found
+	ResolutionDatabase    = "database"    // Only the built-in package databases
+)
+
+// resolutionMethods lists the methods in the order the wizard offers them
+var resolutionMethods = []string{ResolutionAuto, ResolutionInteractive, ResolutionDatabase}
+
+// errCancelled is returned when the user interrupts a prompt
+var errCancelled = errors.New("operation cancelled by user")
+
+// Prompter asks the user for input. The wizard only talks to the user through
+// it, so its logic can be driven by a scripted prompter in tests.
+type Prompter interface {
+	// Input asks for a line of text; an empty answer returns defaultValue
+	Input(label, defaultValue string, validate func(string) error) (string, error)
+	// Select asks the user to pick one of items and returns its index
+	Select(label string, items []string) (int, error)
+}
+
+// MultiSelector is implemented by prompters that can show a checkbox list
+type MultiSelector interface {
+	// MultiSelect shows items with checkboxes, initially checked as given,
+	// and returns the indexes checked when the user confirms
+	MultiSelect(label string, items []string, checked []bool) ([]int, error)
+}
+
+// ListEditor is implemented by prompters that can edit a list in place
+type ListEditor interface {
+	// EditList lets the user add, change and remove items, rejecting those
+	// validate fails
+	EditList(label string, items []string, validate func(string) error) ([]string, error)
+}
+
+// WizardResult holds the answers collected by the init wizard
+type WizardResult struct {
+	Config         *core.Config // Project name, author, sources and output
+	Automate       bool         // Scan the project and resolve dependencies
+	ResolutionMode string       // One of the Resolution* methods, set when automating
+	ReviewDeps     bool         // Let the user pick which resolved dependencies to keep
+	ReviewGuesses  bool         // Review low-confidence mappings on one screen after resolving
+	DropDeps       []string     // Headers whose resolved packages are left out without asking
+	InstallDeps    bool         // Install the resolved dependencies after init
+}
+
+// RunInitWizard guides the user through creating a new catalyst.yml configuration.
+// With CATALYST_BATCH=1 the answers are read from the environment instead.
+func RunInitWizard() (*WizardResult, error) {
+	if os.Getenv("CATALYST_BATCH") == "1" {
+		return WizardFromEnv(os.Getenv)
+	}
+	return RunWizard(NewPrompter())
+}
+
+// RunWizard asks the init wizard questions through p
+func RunWizard(p Prompter) (*WizardResult, error) {
+	cfg := &core.Config{}
+	result := &WizardResult{Config: cfg}
+
+	projectName, err := p.Input("Enter project name", "", validateProjectName)
+	if err != nil {
+		return nil, promptError("project name", err)
+	}
+	cfg.ProjectName = strings.TrimSpace(projectName)
+
+	author, err := p.Input("Author (optional)", "", nil)
+	if err != nil {
+		return nil, promptError("author", err)
+	}
+	cfg.Author = strings.TrimSpace(author)
+
+	// Explicit sources are used as is; the generator respects this
+	sources, err := askSources(p)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Sources = sources
+
+	output, err := p.Input("Output binary name", cfg.ProjectName, nil)
+	if err != nil {
+		return nil, promptError("output", err)
+	}
+	cfg.Output = strings.TrimSpace(output)
+
+	idx, err := p.Select("How do you want to handle dependencies?", []string{
+		"Automate (Recommended) - Scans the sources for #include statements",
+		"Manual - You add dependencies to catalyst.yml yourself",
+	})
+	if err != nil {
+		return nil, promptError("automation preference", err)
+	}
+	result.Automate = idx == 0
+
+	if !result.Automate {
+		return result, nil
+	}
+
+	resIdx, err := p.Select("Dependency resolution method:", []string{
+		"Automatic - Use database + dynamic search without prompts",
+		"Interactive - Let me choose when multiple packages are found",
+		"Database only - Only use built-in package database",
+	})
+	if err != nil {
+		return nil, promptError("resolution method", err)
+	}
+	result.ResolutionMode = resolutionMethods[resIdx]
+	// Automatic resolution doesn't stop for guesses, so they are reviewed at the end
+	result.ReviewGuesses = result.ResolutionMode == ResolutionAuto
+
+	reviewIdx, err := p.Select("Review the detected dependencies before saving?", []string{"No - keep everything found", "Yes"})
+	if err != nil {
+		return nil, promptError("dependency review", err)
+	}
+	result.ReviewDeps = reviewIdx == 1
+
+	installIdx, err := p.Select("Install dependencies after scanning?", []string{"No - I'll run 'catalyst install' later", "Yes"})
+	if err != nil {
+		return nil, promptError("install preference", err)
+	}
+	result.InstallDeps = installIdx == 1
+
+	return result, nil
+}
+
+// scanSources finds the sources the wizard offers to build
+var scanSources = func() ([]analyzer.SourceFile, error) { return analyzer.ScanSources(".") }
+
+// askSources asks which files to build. The sources found in the project are
+// offered as a checklist, entry points first; typing paths is the fallback
+// when none are found or p can't show a checklist. No sources leaves them to
+// the generator's scan.
+func askSources(p Prompter) ([]string, error) {
+	found, _ := scanSources()
+	selector, ok := p.(MultiSelector)
+	if !ok || len(found) == 0 {
+		return typeSources(p, nil)
+	}
+
+	items := make([]string, len(found), len(found)+1)
+	checked := make([]bool, len(found))
+	entryPoints := 0
+	for i, src := range found {
+		items[i] = src.Path
+		checked[i] = true
+		if src.EntryPoint {
+			items[i] += "  (entry point: defines main)"
+			// Several entry points are separate programs, one is checked
+			entryPoints++
+			checked[i] = entryPoints == 1
+		}
+	}
+	items = append(items, "Other files — type their paths next")
+
+	picked, err := selector.MultiSelect("Source files to build — uncheck all to auto-scan", items, checked)
+	if err != nil {
+		return nil, promptError("source files", err)
+	}
+	var sources []string
+	typeMore := false
+	for _, i := range picked {
+		if i == len(found) {
+			typeMore = true
+		} else {
+			sources = append(sources, found[i].Path)
+		}
+	}
+	if typeMore {
+		return typeSources(p, sources)
+	}
+	return sources, nil
+}
+
+// typeSources asks for the paths of source files, adding to sources
+func typeSources(p Prompter, sources []string) ([]string, error) {
+	if editor, ok := p.(ListEditor); ok {
+		typed, err := editor.EditList("Source files, main file first — leave empty to auto-scan", sources, validateEntry)
+		if err != nil {
+			return nil, promptError("source files", err)
+		}
+		return typed, nil
+	}
+
+	entry, err := p.Input("Entry point (path to main source file) — leave blank to auto-scan", "", validateEntry)
+	if err != nil {
+		return nil, promptError("entry point", err)
+	}
+	if entry = strings.TrimSpace(entry); entry != "" {
+		// The entry point comes first
+		sources = append([]string{entry}, sources...)
+	}
+	return sources, nil
+}
+
+// WizardFromEnv builds the wizard answers from CATALYST_* environment
+// variables, for automation and testing
+func WizardFromEnv(getenv func(string) string) (*WizardResult, error) {
+	envBool := func(name string) bool {
+		value := strings.ToLower(getenv(name))
+		return value == "1" || value == "true"
+	}
+
+	cfg := &core.Config{
+		ProjectName: getenv("CATALYST_PROJECT_NAME"),
+		Author:      getenv("CATALYST_AUTHOR"),
+		Output:      getenv("CATALYST_OUTPUT"),
+	}
+	if cfg.ProjectName == "" {
+		cfg.ProjectName = "project"
+	}
+	if entry := getenv("CATALYST_ENTRY"); entry != "" {
+		cfg.Sources = []string{entry}
+	}
+
+	result := &WizardResult{
+		Config:      cfg,
+		Automate:    envBool("CATALYST_AUTOMATE"),
+		InstallDeps: envBool("CATALYST_INSTALL"),
+	}
+	if result.Automate {
+		mode, err := ParseResolutionMethod(getenv("CATALYST_RESOLUTION"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CATALYST_RESOLUTION: %w", err)
+		}
+		result.ResolutionMode = mode
+	}
+
+	return result, nil
+}
+
+// ParseResolutionMethod validates a resolution method name; empty means auto
+func ParseResolutionMethod(method string) (string, error) {
+	method = strings.ToLower(strings.TrimSpace(method))
+	if method == "" {
+		return ResolutionAuto, nil
+	}
+	for _, known := range resolutionMethods {
+		if method == known {
+			return method, nil
+		}
+	}
+	return "", fmt.Errorf("unknown resolution method %q (expected %s)", method, strings.Join(resolutionMethods, ", "))
+}
+
+// SelectDependencies shows the detected dependencies and asks which to keep,
+// as a checkbox list if p supports it. Returns the kept items in their
+// original order.
+func SelectDependencies(p Prompter, deps []string) ([]string, error) {
+	if len(deps) == 0 {
+		return deps, nil
+	}
+
+	if selector, ok := p.(MultiSelector); ok {
+		checked := make([]bool, len(deps))
+		for i := range checked {
+			checked[i] = true
+		}
+		keep, err := selector.MultiSelect("Dependencies to keep", deps, checked)
+		if err != nil {
+			return nil, promptError("dependency selection", err)
+		}
+		kept := []string{}
+		for _, i := range keep {
+			kept = append(kept, deps[i])
+		}
+		return kept, nil
+	}
+
+	fmt.Println("Detected dependencies:")
+	for i, dep := range deps {
+		fmt.Printf("  %d. %s\n", i+1, dep)
+	}
+
+	answer, err := p.Input("Dependencies to drop (e.g. 1,3-4) — leave blank to keep all", "", func(input string) error {
+		_, err := ParseSelection(input, len(deps))
+		return err
+	})
+	if err != nil {
+		return nil, promptError("dependency selection", err)
+	}
+
+	drop, err := ParseSelection(answer, len(deps))
+	if err != nil {
+		return nil, err
+	}
+	dropped := make(map[int]bool)
+	for _, i := range drop {
+		dropped[i] = true
+	}
+
+	var kept []string
+	for i, dep := range deps {
+		if !dropped[i] {
+			kept = append(kept, dep)
+		}
+	}
+	return kept, nil
+}
+
+// ParseSelection parses a list of 1-based item numbers and ranges such as
+// "1, 3-4" into sorted, unique 0-based indexes below count
+func ParseSelection(input string, count int) ([]int, error) {
+	seen := make(map[int]bool)
+	var indexes []int
+
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		first, last := part, part
+		if dash := strings.Index(part, "-"); dash > 0 {
+			first, last = strings.TrimSpace(part[:dash]), strings.TrimSpace(part[dash+1:])
+		}
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", part)
+		}
+		to, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q", part)
+		}
+		if from < 1 || to > count || from > to {
+			return nil, fmt.Errorf("selection %q is out of range 1-%d", part, count)
+		}
+
+		for n := from; n <= to; n++ {
+			if !seen[n-1] {
+				seen[n-1] = true
+				indexes = append(indexes, n-1)
+			}
+		}
+	}
+
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+// validateProjectName requires a non-empty project name
+func validateProjectName(input string) error {
+	if strings.TrimSpace(input) == "" {
+		return fmt.Errorf("project name is required")
+	}
+	return nil
+}
+
+// validateEntry checks that an entry point, if given, exists
+func validateEntry(input string) error {
+	if input = strings.TrimSpace(input); input == "" {
+		return nil
+	}
+	if _, err := os.Stat(input); err != nil {
+		return fmt.Errorf("file does not exist: %v", err)
+	}
+	return nil
+}
+
+// promptError names the step that failed, passing cancellation through as is
+func promptError(step string, err error) error {
+	if errors.Is(err, errCancelled) {
+		return err
+	}
+	return fmt.Errorf("%s prompt failed: %v", step, err)
+}
@@ -0,0 +1,248 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Sabique-Islam/catalyst/internal/analyzer"
+)
+
+// scriptedPrompter answers wizard questions from fixed lists
+type scriptedPrompter struct {
+	inputs  []string
+	selects []int
+}
+
+func (p *scriptedPrompter) Input(label, defaultValue string, validate func(string) error) (string, error) {
+	answer := p.inputs[0]
+	p.inputs = p.inputs[1:]
+	if answer == "" {
+		answer = defaultValue
+	}
+	if validate != nil {
+		if err := validate(answer); err != nil {
+			return "", err
+		}
+	}
+	return answer, nil
+}
+
+func (p *scriptedPrompter) Select(label string, items []string) (int, error) {
+	idx := p.selects[0]
+	p.selects = p.selects[1:]
+	return idx, nil
+}
+
+func TestRunWizard(t *testing.T) {
+	p := &scriptedPrompter{
+		inputs:  []string{"demo", "Ada", "", ""},
+		selects: []int{0, 2, 1, 0},
+	}
+
+	result, err := RunWizard(p)
+	if err != nil {
+		t.Fatalf("RunWizard failed: %v", err)
+	}
+
+	cfg := result.Config
+	if cfg.ProjectName != "demo" || cfg.Author != "Ada" || cfg.Output != "demo" || len(cfg.Sources) != 0 {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if !result.Automate || result.ResolutionMode != ResolutionDatabase || !result.ReviewDeps || result.InstallDeps {
+		t.Errorf("Unexpected wizard result: %+v", result)
+	}
+}
+
+func TestRunWizardManual(t *testing.T) {
+	p := &scriptedPrompter{
+		inputs:  []string{"demo", "", "", "app"},
+		selects: []int{1},
+	}
+
+	result, err := RunWizard(p)
+	if err != nil {
+		t.Fatalf("RunWizard failed: %v", err)
+	}
+	if result.Automate || result.ResolutionMode != "" || result.Config.Output != "app" {
+		t.Errorf("Unexpected wizard result: %+v", result)
+	}
+}
+
+// checklistPrompter also answers checklists and list edits
+type checklistPrompter struct {
+	scriptedPrompter
+	picks   [][]int
+	shown   [][]string
+	checked [][]bool
+	edited  []string
+}
+
+func (p *checklistPrompter) MultiSelect(label string, items []string, checked []bool) ([]int, error) {
+	p.shown = append(p.shown, items)
+	p.checked = append(p.checked, checked)
+	picked := p.picks[0]
+	p.picks = p.picks[1:]
+	return picked, nil
+}
+
+func (p *checklistPrompter) EditList(label string, items []string, validate func(string) error) ([]string, error) {
+	return append(items, p.edited...), nil
+}
+
+func TestRunWizardSourcePicker(t *testing.T) {
+	scanSources = func() ([]analyzer.SourceFile, error) {
+		return []analyzer.SourceFile{{Path: "src/main.c", EntryPoint: true}, {Path: "tools/gen.c", EntryPoint: true}, {Path: "src/net.c"}}, nil
+	}
+	t.Cleanup(func() { scanSources = func() ([]analyzer.SourceFile, error) { return analyzer.ScanSources(".") } })
+
+	p := &checklistPrompter{
+		scriptedPrompter: scriptedPrompter{inputs: []string{"server", "", ""}, selects: []int{1}},
+		picks:            [][]int{{0, 2, 3}},
+		edited:           []string{"vendor/extra.c"},
+	}
+	result, err := RunWizard(p)
+	if err != nil {
+		t.Fatalf("RunWizard failed: %v", err)
+	}
+
+	if want := []bool{true, false, true}; !reflect.DeepEqual(p.checked[0], want) {
+		t.Errorf("initially checked %v, want %v (only the first entry point)", p.checked[0], want)
+	}
+	if len(p.shown[0]) != 4 || !strings.Contains(p.shown[0][0], "entry point") || strings.Contains(p.shown[0][2], "entry point") {
+		t.Errorf("unexpected checklist %q", p.shown[0])
+	}
+	if want := []string{"src/main.c", "src/net.c", "vendor/extra.c"}; !reflect.DeepEqual(result.Config.Sources, want) {
+		t.Errorf("Sources = %v, want %v", result.Config.Sources, want)
+	}
+}
+
+func TestWizardFromEnv(t *testing.T) {
+	env := map[string]string{
+		"CATALYST_PROJECT_NAME": "demo",
+		"CATALYST_AUTOMATE":     "true",
+		"CATALYST_RESOLUTION":   "Interactive",
+		"CATALYST_ENTRY":        "src/main.c",
+	}
+	getenv := func(name string) string { return env[name] }
+
+	result, err := WizardFromEnv(getenv)
+	if err != nil {
+		t.Fatalf("WizardFromEnv failed: %v", err)
+	}
+	if result.Config.ProjectName != "demo" || !reflect.DeepEqual(result.Config.Sources, []string{"src/main.c"}) {
+		t.Errorf("Unexpected config: %+v", result.Config)
+	}
+	if !result.Automate || result.ResolutionMode != ResolutionInteractive {
+		t.Errorf("Unexpected wizard result: %+v", result)
+	}
+
+	env["CATALYST_RESOLUTION"] = "guess"
+	if _, err := WizardFromEnv(getenv); err == nil {
+		t.Error("Expected an error for an unknown resolution method")
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"1", []int{0}, false},
+		{"3, 1-2, 2", []int{0, 1, 2}, false},
+		{"4", nil, true},
+		{"0", nil, true},
+		{"2-1", nil, true},
+		{"x", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSelection(tt.input, 3)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSelection(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSelection(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAnswersTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.yml")
+	if err := os.WriteFile(path, []byte(AnswersTemplate), 0644); err != nil {
+		t.Fatalf("Failed to write answers: %v", err)
+	}
+
+	answers, err := LoadAnswers(path)
+	if err != nil {
+		t.Fatalf("Template does not parse: %v", err)
+	}
+	result, err := answers.Result()
+	if err != nil {
+		t.Fatalf("Template answers are invalid: %v", err)
+	}
+	if result.Config.ProjectName != "myproject" || result.Config.Output != "myproject" || !result.Automate || result.ResolutionMode != ResolutionAuto {
+		t.Errorf("Unexpected wizard result: %+v", result)
+	}
+
+	answers.Resolution = "guess"
+	if _, err := answers.Result(); err == nil {
+		t.Error("Expected an error for an unknown resolution method")
+	}
+}
+
+func TestAnswersSources(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "main.c")
+	netPath := filepath.Join(dir, "net.c")
+	for _, path := range []string{mainPath, netPath} {
+		if err := os.WriteFile(path, []byte("int x;\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	answers := &Answers{ProjectName: "server", Entry: mainPath, Sources: []string{mainPath, netPath}}
+	result, err := answers.Result()
+	if err != nil {
+		t.Fatalf("Answers are invalid: %v", err)
+	}
+	if !reflect.DeepEqual(result.Config.Sources, []string{mainPath, netPath}) {
+		t.Errorf("Sources = %v, want the entry point then net.c once each", result.Config.Sources)
+	}
+
+	answers.Sources = append(answers.Sources, filepath.Join(dir, "missing.c"))
+	if _, err := answers.Result(); err == nil {
+		t.Error("Expected an error for a missing source file")
+	}
+}
+
+func TestReviewMappings(t *testing.T) {
+	mappings := []Mapping{
+		{Header: "foo", Package: "libfoo-dev", Source: "file-search", Confidence: 85},
+		{Header: "bar", Package: "bar-utils", Source: "name-search", Confidence: 70},
+		{Header: "baz", Package: "libbaz", Source: "file-search", Confidence: 80},
+	}
+
+	accepted, err := ReviewMappings(&scriptedPrompter{selects: []int{0}}, mappings)
+	if err != nil || !reflect.DeepEqual(accepted, mappings) {
+		t.Errorf("Accept all = %v, %v", accepted, err)
+	}
+
+	edited, err := ReviewMappings(&scriptedPrompter{selects: []int{1}, inputs: []string{"", "libbar-dev", "-"}}, mappings)
+	if err != nil {
+		t.Fatalf("ReviewMappings failed: %v", err)
+	}
+	want := []Mapping{
+		mappings[0],
+		{Header: "bar", Package: "libbar-dev", Source: "user", Confidence: 100},
+		{Header: "baz", Package: "", Source: "user", Confidence: 100},
+	}
+	if !reflect.DeepEqual(edited, want) {
+		t.Errorf("Edited = %v, want %v", edited, want)
+	}
+}
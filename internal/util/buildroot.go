@@ -0,0 +1,63 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+)
+
+// BuildRoot, when set, is an out-of-tree directory that receives everything
+// catalyst generates for the project: the build/ tree, the .catalyst/ state
+// (project lock, isolated prefix) and lockfile updates. Projects on
+// read-only mounts can be built with it, and IDEs can keep separate build
+// trees. It is an absolute path; empty means the project directory. Each
+// project gets a directory of its own in it, so one build root can be
+// shared.
+var BuildRoot string
+
+// ProjectRoot returns the directory of the project in the current directory
+// under BuildRoot: <name>-<hash of its path>, or "." without a build root
+func ProjectRoot() string {
+	if BuildRoot == "" {
+		return "."
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return filepath.Join(BuildRoot, "project")
+	}
+	sum := sha256.Sum256([]byte(dir))
+	return filepath.Join(BuildRoot, filepath.Base(dir)+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// BuildDir joins elem to the build directory: build/ in the project, or in
+// its directory under BuildRoot
+func BuildDir(elem ...string) string {
+	return filepath.Join(append([]string{ProjectRoot(), "build"}, elem...)...)
+}
+
+// StateDir joins elem to the directory of catalyst's project state:
+// .catalyst/ in the project, or in its directory under BuildRoot
+func StateDir(elem ...string) string {
+	return filepath.Join(append([]string{ProjectRoot(), ".catalyst"}, elem...)...)
+}
+
+// Runnable returns path in a form that runs it as a program rather than
+// looking it up in PATH
+func Runnable(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return "." + string(filepath.Separator) + path
+}
+
+// DirWritable reports whether files can be created in dir
+func DirWritable(dir string) bool {
+	f, err := os.CreateTemp(dir, ".catalyst-write-test-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
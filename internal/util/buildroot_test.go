@@ -0,0 +1,43 @@
+package util
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildRoot(t *testing.T) {
+	if got, want := BuildDir("tests", "t"), filepath.Join("build", "tests", "t"); got != want {
+		t.Errorf("BuildDir = %s, want %s", got, want)
+	}
+	if got, want := StateDir("lock"), filepath.Join(".catalyst", "lock"); got != want {
+		t.Errorf("StateDir = %s, want %s", got, want)
+	}
+
+	root := t.TempDir()
+	BuildRoot = root
+	defer func() { BuildRoot = "" }()
+	project := ProjectRoot()
+	if filepath.Dir(project) != root {
+		t.Fatalf("ProjectRoot = %s, want a directory of %s", project, root)
+	}
+	if got, want := BuildDir("gen"), filepath.Join(project, "build", "gen"); got != want {
+		t.Errorf("BuildDir with a build root = %s, want %s", got, want)
+	}
+	if got, want := StateDir("lock"), filepath.Join(project, ".catalyst", "lock"); got != want {
+		t.Errorf("StateDir with a build root = %s, want %s", got, want)
+	}
+	// Another project sharing the build root gets its own directory
+	t.Chdir(t.TempDir())
+	if ProjectRoot() == project {
+		t.Errorf("ProjectRoot is %s for two projects", project)
+	}
+	if got := Runnable(BuildDir("app")); got != filepath.Join(ProjectRoot(), "build", "app") {
+		t.Errorf("Runnable = %s", got)
+	}
+	if got, want := Runnable(filepath.Join("build", "app")), "."+string(filepath.Separator)+filepath.Join("build", "app"); got != want {
+		t.Errorf("Runnable = %s, want %s", got, want)
+	}
+	if !DirWritable(root) || DirWritable(filepath.Join(root, "missing")) {
+		t.Error("DirWritable misreported")
+	}
+}
@@ -1 +1,261 @@
 package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Executor starts the external commands catalyst runs. Swapping it lets
+// commands be recorded, printed instead of run, or faked in tests.
+type Executor interface {
+	// Start starts cmd, or reports started=false if the executor chose not
+	// to run it (for example in a dry run)
+	Start(cmd *Cmd) (started bool, err error)
+}
+
+// Exec is the executor every Cmd goes through
+var Exec Executor = SystemExecutor{}
+
+// Cmd is an external command started through Exec. It embeds *exec.Cmd, so
+// Dir, Env, Stdin, Stdout and Stderr are set the same way.
+type Cmd struct {
+	*exec.Cmd
+
+	// ChangesSystem marks commands that modify the machine (installing
+	// packages, updating package indexes), which a dry run skips
+	ChangesSystem bool
+
+	started bool
+	done    func(error)
+}
+
+// Command is exec.Command routed through Exec
+func Command(name string, args ...string) *Cmd {
+	return &Cmd{Cmd: exec.Command(name, args...)}
+}
+
+// CommandContext is exec.CommandContext routed through Exec
+func CommandContext(ctx context.Context, name string, args ...string) *Cmd {
+	return &Cmd{Cmd: exec.CommandContext(ctx, name, args...)}
+}
+
+// ParsedCommand is Command for commands whose output catalyst parses. It
+// runs them in the C locale so package managers don't translate messages
+// and headers the parsers match on.
+func ParsedCommand(name string, args ...string) *Cmd {
+	cmd := Command(name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C", "LANG=C")
+	return cmd
+}
+
+// SystemCommand is Command for commands that modify the machine
+func SystemCommand(name string, args ...string) *Cmd {
+	cmd := Command(name, args...)
+	cmd.ChangesSystem = true
+	return cmd
+}
+
+// String returns the command line, quoting arguments that need it
+func (c *Cmd) String() string {
+	parts := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$") {
+			arg = strconv.Quote(arg)
+		}
+		parts[i] = arg
+	}
+	return strings.Join(parts, " ")
+}
+
+// Start starts the command through Exec
+func (c *Cmd) Start() error {
+	started, err := Exec.Start(c)
+	c.started = started
+	return err
+}
+
+// Wait waits for a started command; a command the executor skipped
+// finishes immediately and successfully
+func (c *Cmd) Wait() error {
+	var err error
+	if c.started {
+		err = c.Cmd.Wait()
+	}
+	if c.done != nil {
+		c.done(err)
+	}
+	return err
+}
+
+// Run starts the command and waits for it
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// Output runs the command and returns its standard output
+func (c *Cmd) Output() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, fmt.Errorf("exec: Stdout already set")
+	}
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	if c.Stderr == nil {
+		c.Stderr = &stderr
+	}
+	err := c.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.Stderr == nil {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs the command and returns its standard output and
+// standard error together
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if c.Stdout != nil || c.Stderr != nil {
+		return nil, fmt.Errorf("exec: Stdout or Stderr already set")
+	}
+	var out bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &out
+	err := c.Run()
+	return out.Bytes(), err
+}
+
+// SystemExecutor runs every command
+type SystemExecutor struct{}
+
+// Start starts cmd
+func (SystemExecutor) Start(cmd *Cmd) (bool, error) {
+	return true, cmd.Cmd.Start()
+}
+
+// DryRunExecutor prints commands that change the system instead of running
+// them, and runs everything else (queries such as pkg-config or dpkg-query)
+// through Next
+type DryRunExecutor struct {
+	Out  io.Writer
+	Next Executor
+}
+
+// Start prints or starts cmd
+func (d DryRunExecutor) Start(cmd *Cmd) (bool, error) {
+	if cmd.ChangesSystem {
+		fmt.Fprintf(d.Out, "Would run: %s\n", cmd)
+		return false, nil
+	}
+	return d.Next.Start(cmd)
+}
+
+// RecordingExecutor logs every command, its directory, exit status and
+// duration to Out before handing it to Next, so a session can be attached
+// to a bug report
+type RecordingExecutor struct {
+	Out  io.Writer
+	Next Executor
+
+	mu sync.Mutex
+}
+
+// NewRecordingExecutor records commands run by next to out
+func NewRecordingExecutor(out io.Writer, next Executor) *RecordingExecutor {
+	return &RecordingExecutor{Out: out, Next: next}
+}
+
+// Start records and starts cmd
+func (r *RecordingExecutor) Start(cmd *Cmd) (bool, error) {
+	start := time.Now()
+	started, err := r.Next.Start(cmd)
+	if err != nil || !started {
+		status := "skipped"
+		if err != nil {
+			status = "failed to start: " + err.Error()
+		}
+		r.record(cmd, start, status)
+		return started, err
+	}
+
+	cmd.done = func(err error) {
+		status := "exit 0"
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			status = fmt.Sprintf("exit %d", exitErr.ExitCode())
+		} else if err != nil {
+			status = err.Error()
+		}
+		r.record(cmd, start, status)
+	}
+	return true, nil
+}
+
+func (r *RecordingExecutor) record(cmd *Cmd, start time.Time, status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dir := cmd.Dir
+	if dir == "" {
+		dir = "."
+	}
+	fmt.Fprintf(r.Out, "%s [%s] (%s, %s) $ %s\n", start.Format(time.RFC3339), dir, status,
+		time.Since(start).Round(time.Millisecond), cmd)
+}
+
+// SplitArguments splits a command line into words. Quotes group words and
+// a backslash escapes a quote, space or backslash outside single quotes;
+// other backslashes are kept, as in Windows paths.
+func SplitArguments(input string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range input {
+		switch {
+		case escaped:
+			if !strings.ContainsRune(`"' \`, r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		word.WriteRune('\\')
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -1 +1,95 @@
 package util
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeExecutor records commands without starting them
+type fakeExecutor struct {
+	ran []string
+}
+
+func (f *fakeExecutor) Start(cmd *Cmd) (bool, error) {
+	f.ran = append(f.ran, cmd.String())
+	return false, nil
+}
+
+func withExecutor(t *testing.T, e Executor) {
+	previous := Exec
+	Exec = e
+	t.Cleanup(func() { Exec = previous })
+}
+
+func TestDryRunExecutor(t *testing.T) {
+	fake := &fakeExecutor{}
+	var out bytes.Buffer
+	withExecutor(t, DryRunExecutor{Out: &out, Next: fake})
+
+	if err := SystemCommand("sudo", "apt-get", "install", "-y", "zlib1g-dev").Run(); err != nil {
+		t.Fatalf("skipped command returned error: %v", err)
+	}
+	if _, err := Command("dpkg-query", "-W", "zlib1g-dev").Output(); err != nil {
+		t.Fatalf("query returned error: %v", err)
+	}
+
+	if got := out.String(); got != "Would run: sudo apt-get install -y zlib1g-dev\n" {
+		t.Errorf("unexpected dry-run output %q", got)
+	}
+	if len(fake.ran) != 1 || fake.ran[0] != "dpkg-query -W zlib1g-dev" {
+		t.Errorf("expected only the query to reach the next executor, got %v", fake.ran)
+	}
+}
+
+func TestRecordingExecutor(t *testing.T) {
+	var out bytes.Buffer
+	withExecutor(t, NewRecordingExecutor(&out, &fakeExecutor{}))
+
+	cmd := Command("pkg-config", "--cflags", "--libs", "gtk+-3.0")
+	cmd.Dir = "/src/app"
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	line := out.String()
+	for _, want := range []string{"[/src/app]", "skipped", "$ pkg-config --cflags --libs gtk+-3.0\n"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in recorded line %q", want, line)
+		}
+	}
+}
+
+func TestCmdString(t *testing.T) {
+	cmd := Command("sh", "-c", `rpm2cpio "$1" | cpio -idm`, "")
+	want := `sh -c "rpm2cpio \"$1\" | cpio -idm" ""`
+	if got := cmd.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
+
+func TestSplitArguments(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"  --release  -j 4 ", []string{"--release", "-j", "4"}, false},
+		{`--flags "-O2 -g" 'it''s'`, []string{"--flags", "-O2 -g", "its"}, false},
+		{`a\ b \"c\" ""`, []string{"a b", `"c"`, ""}, false},
+		{`C:\src\main.c`, []string{`C:\src\main.c`}, false},
+		{`"open`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := SplitArguments(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SplitArguments(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitArguments(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
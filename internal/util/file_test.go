@@ -0,0 +1,44 @@
+package util
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "catalyst.yml")
+
+	if err := WriteFileAtomic(path, []byte("project_name: demo\n"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("project_name: renamed\n"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic over an existing file failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "project_name: renamed\n" {
+		t.Fatalf("Expected the new content, got %q (%v)", data, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the existing permissions to be kept, got %v", info.Mode().Perm())
+	}
+
+	// A failed write leaves the old file and no temporary files behind
+	err = WriteAtomic(path, 0644, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return errors.New("interrupted")
+	})
+	if err == nil {
+		t.Fatal("Expected the write error to be returned")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "project_name: renamed\n" {
+		t.Errorf("Expected the old content after a failed write, got %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+}
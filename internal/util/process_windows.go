@@ -0,0 +1,33 @@
+//go:build windows
+
+package util
+
+import (
+	"os"
+	"syscall"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for running processes
+const stillActive = 259
+
+// processAlive reports whether a process with pid is running
+func processAlive(pid int) bool {
+	handle, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied means it exists but belongs to another user
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(handle)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(handle, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
+
+// ownedByUser reports whether a file belongs to the current user. Windows
+// gives each user a temporary directory of their own, so every file in it
+// does.
+func ownedByUser(os.FileInfo) bool {
+	return true
+}
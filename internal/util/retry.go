@@ -0,0 +1,74 @@
+package util
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/Sabique-Islam/catalyst/internal/log"
+)
+
+// RetryPolicy retries operations that fail with transient errors, waiting
+// exponentially longer between attempts
+type RetryPolicy struct {
+	Attempts    int           // Total attempts including the first; 1 or less never retries
+	Backoff     time.Duration // Wait before the first retry, doubled before each next one
+	MaxBackoff  time.Duration // Longest wait between attempts; 0 means no limit
+	RetryStatus []int         // HTTP status codes worth retrying
+}
+
+// DefaultRetry is used for downloads and package manager commands
+var DefaultRetry = RetryPolicy{
+	Attempts:    3,
+	Backoff:     time.Second,
+	MaxBackoff:  30 * time.Second,
+	RetryStatus: []int{408, 425, 429, 500, 502, 503, 504},
+}
+
+// sleep is replaced in tests
+var sleep = time.Sleep
+
+// Do runs fn until it succeeds, fails with an error not marked Retryable,
+// or has been attempted p.Attempts times, and returns its last error
+func (p RetryPolicy) Do(fn func() error) error {
+	wait := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsRetryable(err) || attempt >= p.Attempts {
+			return err
+		}
+		log.Warnf("%v (retrying in %s, attempt %d of %d)\n", err, wait, attempt+1, p.Attempts)
+		sleep(wait)
+		wait *= 2
+		if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+	}
+}
+
+// RetryStatusCode reports whether an HTTP response status is worth retrying
+func (p RetryPolicy) RetryStatusCode(code int) bool {
+	return slices.Contains(p.RetryStatus, code)
+}
+
+// retryableError marks an error as transient
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// Retryable marks err as transient, so RetryPolicy.Do tries again
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryableError{err}
+}
+
+// IsRetryable reports whether err, or an error it wraps, was marked Retryable
+func IsRetryable(err error) bool {
+	var r retryableError
+	return errors.As(err, &r)
+}
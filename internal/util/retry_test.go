@@ -0,0 +1,37 @@
+package util
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	policy := RetryPolicy{Attempts: 4, Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	calls := 0
+	err := policy.Do(func() error {
+		calls++
+		return Retryable(errors.New("connection reset"))
+	})
+	if err == nil || calls != 4 {
+		t.Errorf("expected 4 failed attempts, got %d (err %v)", calls, err)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}; !reflect.DeepEqual(waits, want) {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+
+	// Errors that aren't transient fail at once
+	calls = 0
+	policy.Do(func() error {
+		calls++
+		return errors.New("404 not found")
+	})
+	if calls != 1 {
+		t.Errorf("expected a permanent error not to be retried, got %d calls", calls)
+	}
+}
@@ -0,0 +1,119 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Scratch space: temporary files and directories catalyst makes go under
+// one directory per process, os.TempDir()/catalyst-<pid>-<random>, created
+// with os.MkdirTemp so its name can't be guessed and taken first. It is
+// removed when catalyst exits, and directories left behind by runs that
+// crashed or were killed are removed by the next run of the same user once
+// their process is gone.
+
+const (
+	tempPrefix = "catalyst-"
+	// tempMarker is the file in a scratch directory holding the pid of the
+	// process it belongs to; only directories with one are ever removed
+	tempMarker = ".catalyst-pid"
+)
+
+var (
+	tempMu  sync.Mutex
+	tempDir string
+)
+
+// TempDir returns this process's scratch directory, creating it on first use
+func TempDir() (string, error) {
+	tempMu.Lock()
+	defer tempMu.Unlock()
+	if tempDir != "" {
+		return tempDir, nil
+	}
+
+	removeStaleTemp(os.TempDir())
+	pid := strconv.Itoa(os.Getpid())
+	// 0700: other users on a shared machine can't read or plant files in it
+	dir, err := os.MkdirTemp("", tempPrefix+pid+"-")
+	if err != nil {
+		return "", fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, tempMarker), []byte(pid), 0600); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	tempDir = dir
+	return dir, nil
+}
+
+// MkdirTemp is os.MkdirTemp in the scratch directory
+func MkdirTemp(pattern string) (string, error) {
+	dir, err := TempDir()
+	if err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(dir, pattern)
+}
+
+// TempPath returns a path for a file named name in the scratch directory
+// without creating it, for tools that write their own output (compilers,
+// linkers)
+func TempPath(name string) (string, error) {
+	dir, err := MkdirTemp("")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// CleanupTemp removes the scratch directory; catalyst calls it on exit
+func CleanupTemp() {
+	tempMu.Lock()
+	defer tempMu.Unlock()
+	if tempDir != "" {
+		os.RemoveAll(tempDir)
+		tempDir = ""
+	}
+}
+
+// removeStaleTemp removes the scratch directories in parent that belong to
+// the current user and whose process has exited. Directories without
+// catalyst's marker are left alone, whatever their name.
+func removeStaleTemp(parent string) {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), tempPrefix) {
+			continue
+		}
+		dir := filepath.Join(parent, entry.Name())
+		if info, err := os.Lstat(dir); err != nil || !info.IsDir() || !ownedByUser(info) {
+			continue
+		}
+		pid, ok := tempOwner(dir)
+		if ok && pid != os.Getpid() && !processAlive(pid) {
+			os.RemoveAll(dir)
+		}
+	}
+}
+
+// tempOwner returns the pid in the marker of a scratch directory
+func tempOwner(dir string) (int, bool) {
+	marker := filepath.Join(dir, tempMarker)
+	if info, err := os.Lstat(marker); err != nil || !info.Mode().IsRegular() {
+		return 0, false
+	}
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, err == nil
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTempDir(t *testing.T) {
+	parent := t.TempDir()
+	t.Setenv("TMPDIR", parent)
+	stale := filepath.Join(parent, "catalyst-999999999-1234")
+	other := filepath.Join(parent, "catalyst-selftest-123")
+	unmarked := filepath.Join(parent, "catalyst-999999998")
+	for _, dir := range []string{stale, other, unmarked} {
+		if err := os.Mkdir(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(stale, ".catalyst-pid"), []byte("999999999"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := MkdirTemp("sig-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scratch := filepath.Dir(dir)
+	if want := "catalyst-" + strconv.Itoa(os.Getpid()) + "-"; filepath.Dir(scratch) != parent || !strings.HasPrefix(filepath.Base(scratch), want) {
+		t.Errorf("MkdirTemp made %s, want it inside %s*", dir, filepath.Join(parent, want))
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("expected the directory of an exited process to be removed")
+	}
+	for _, dir := range []string{other, unmarked} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("%s has no marker and must be left alone", dir)
+		}
+	}
+
+	CleanupTemp()
+	if _, err := os.Stat(filepath.Dir(dir)); !os.IsNotExist(err) {
+		t.Error("expected CleanupTemp to remove the scratch directory")
+	}
+}
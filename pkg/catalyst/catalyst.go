@@ -0,0 +1,286 @@
+// Package catalyst runs catalyst's project operations from Go programs, such
+// as editor plugins, bots and CI systems, without starting the CLI.
+//
+// Nothing is printed: catalyst's messages and the output of the compilers and
+// package managers it runs go to Options.OnMessage, and progress is reported
+// as events to Options.OnEvent. Prompts are never shown;
+// interactive dependency resolution falls back to the automatic mode and
+// install failures are not fixed.
+//
+// catalyst works on the project in the current directory, so operations on a
+// project change the process's working directory while they run and restore
+// it afterwards. Operations are serialized.
+package catalyst
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Sabique-Islam/catalyst/internal/compile"
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/events"
+	"github.com/Sabique-Islam/catalyst/internal/fetch"
+	"github.com/Sabique-Islam/catalyst/internal/guard"
+	"github.com/Sabique-Islam/catalyst/internal/install"
+	"github.com/Sabique-Islam/catalyst/internal/lock"
+	"github.com/Sabique-Islam/catalyst/internal/log"
+	"github.com/Sabique-Islam/catalyst/internal/pkgdb"
+	"github.com/Sabique-Islam/catalyst/internal/platform"
+)
+
+// Config is the contents of catalyst.yml
+type Config = config.Config
+
+// BuildMetadata describes a build: its output, compiler, flags and inputs
+type BuildMetadata = compile.BuildMetadata
+
+// ResolveResult is the package a header dependency resolved to
+type ResolveResult = pkgdb.ResolveResult
+
+// Event is a step of an operation, such as a header resolved, a package
+// installed, a source compiled or a binary linked
+type Event = events.Event
+
+// EventKind is what an event reports
+type EventKind = events.Kind
+
+// Event kinds
+const (
+	ResolutionStarted      = events.ResolutionStarted
+	ResolutionFinished     = events.ResolutionFinished
+	PackageInstallStarted  = events.PackageInstallStarted
+	PackageInstallFinished = events.PackageInstallFinished
+	FileCompiled           = events.FileCompiled
+	LinkFinished           = events.LinkFinished
+)
+
+// Level is the severity of a message
+type Level = log.Level
+
+// Message levels
+const (
+	LevelError = log.LevelError
+	LevelWarn  = log.LevelWarn
+	LevelInfo  = log.LevelInfo
+	LevelDebug = log.LevelDebug
+)
+
+// Options selects the project an operation works on and receives its output
+type Options struct {
+	// Dir is the project directory, or a directory inside it; the current
+	// directory if empty
+	Dir string
+	// ConfigFile is the project config to use instead of the catalyst.yml
+	// found from Dir, relative to Dir
+	ConfigFile string
+	// OnMessage receives messages at every level and the output of the
+	// commands catalyst runs, in chunks as they write it. Nil discards them.
+	OnMessage func(level Level, msg string)
+	// OnEvent receives progress events as they happen
+	OnEvent func(Event)
+}
+
+// BuildOptions configures Build
+type BuildOptions struct {
+	Options
+	Sources           []string // Sources to build instead of those in catalyst.yml, relative to the project
+	Profile           string   // Build profile from the profiles: section
+	Target            string   // Target triple from the targets: section; empty builds for the host
+	Jobs              int      // Sources compiled in parallel; 0 uses jobs from catalyst.yml
+	Features          []string // Features to enable
+	NoDefaultFeatures bool     // Don't enable the default features
+	Sandbox           bool     // Restrict the compiler's writes to the build directory
+}
+
+// InstallOptions configures Install
+type InstallOptions struct {
+	Options
+	Isolated bool     // Install into the project-local prefix instead of system-wide
+	Frozen   bool     // Fail instead of updating catalyst.lock when resolution differs from it
+	DryRun   bool     // Report package manager commands instead of running them
+	Only     []string // Dependency categories to install (build, tools, runtime, dev); all if empty
+}
+
+// mu serializes operations, which share the working directory and
+// catalyst's global state
+var mu sync.Mutex
+
+// LoadConfig reads the project config at path
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadConfig(path)
+}
+
+// SaveConfig writes cfg to path as YAML
+func SaveConfig(cfg *Config, path string) error {
+	return config.SaveConfig(cfg, path)
+}
+
+// Scan returns the external headers the C and C++ sources of the project
+// include, leaving out the standard library. Without a project it scans Dir.
+func Scan(opts Options) ([]string, error) {
+	var deps []string
+	err := run(opts, false, func(*Config) error {
+		found, err := fetch.ScanDependencies(".")
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+		deps = fetch.ExternalDependencies(found)
+		return nil
+	})
+	return deps, err
+}
+
+// Resolve resolves headers to packages of the host package manager with the
+// resolution settings and package overrides of the project, if there is one
+func Resolve(headers []string, opts Options) ([]ResolveResult, error) {
+	var results []ResolveResult
+	err := run(opts, false, func(cfg *Config) error {
+		pkgManager, err := platform.DetectPackageManager(platform.DetectOS())
+		if err != nil {
+			log.Warnf("Could not detect package manager: %v\n", err)
+		}
+		var resolution config.Resolution
+		var overrides config.PackageOverrides
+		if cfg != nil {
+			resolution, overrides = cfg.Resolution, cfg.PackageOverrides
+		}
+		if resolution.Mode == pkgdb.ModeInteractive {
+			resolution.Mode = pkgdb.ModeAuto
+		}
+		resolver, err := pkgdb.NewResolver(pkgManager, resolution)
+		if err != nil {
+			return err
+		}
+		resolver.Overrides = overrides
+		resolver.Explain = nil
+
+		for _, header := range headers {
+			res, err := resolver.Resolve(header)
+			if err != nil {
+				return err
+			}
+			results = append(results, res)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// Install installs the project's dependencies and downloads its resources
+func Install(opts InstallOptions) error {
+	return run(opts.Options, true, func(cfg *Config) error {
+		if cfg == nil {
+			return fmt.Errorf("no %s found", config.ConfigNames[0])
+		}
+		return install.InstallDependenciesWithOptions(install.InstallOptions{
+			Isolated: opts.Isolated,
+			Frozen:   opts.Frozen,
+			DryRun:   opts.DryRun,
+			Only:     opts.Only,
+		})
+	})
+}
+
+// Build installs the project's dependencies and builds it, returning what
+// it built. Paths in the metadata are relative to the project directory.
+func Build(opts BuildOptions) (*BuildMetadata, error) {
+	var meta *BuildMetadata
+	err := run(opts.Options, true, func(cfg *Config) error {
+		if cfg == nil && len(opts.Sources) == 0 {
+			return fmt.Errorf("no %s found and no sources given", config.ConfigNames[0])
+		}
+		var err error
+		meta, err = compile.Build(opts.Sources, compile.CompileOptions{
+			Sandbox:           opts.Sandbox,
+			Jobs:              opts.Jobs,
+			Target:            opts.Target,
+			Profile:           opts.Profile,
+			Features:          opts.Features,
+			NoDefaultFeatures: opts.NoDefaultFeatures,
+		})
+		return err
+	})
+	return meta, err
+}
+
+// run runs fn from the root of the project opts selects, passing its config
+// (nil if there is none), with catalyst's output going to opts.OnMessage.
+// With locked, it holds the project lock like the CLI does.
+func run(opts Options, locked bool, fn func(cfg *Config) error) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	handler := opts.OnMessage
+	if handler == nil {
+		handler = func(Level, string) {}
+	}
+	log.SetHandler(handler)
+	defer log.SetHandler(nil)
+	// Messages match what the CLI prints
+	defer events.Subscribe(events.Console)()
+	if opts.OnEvent != nil {
+		defer events.Subscribe(opts.OnEvent)()
+	}
+
+	// Nobody is there to answer prompts
+	confirmFix := install.ConfirmFix
+	install.ConfirmFix = nil
+	defer func() { install.ConfirmFix = confirmFix }()
+	chooseCompiler := install.ChooseCompiler
+	install.ChooseCompiler = nil
+	defer func() { install.ChooseCompiler = chooseCompiler }()
+	confirmRepo := install.ConfirmRepo
+	install.ConfirmRepo = nil
+	defer func() { install.ConfirmRepo = confirmRepo }()
+	ask := guard.Ask
+	guard.Ask = nil
+	defer func() { guard.Ask = ask }()
+	confirmWrite := config.ConfirmWrite
+	config.ConfirmWrite = nil
+	defer func() { config.ConfirmWrite = confirmWrite }()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	projectFile := config.ProjectFile
+	defer func() {
+		os.Chdir(cwd)
+		config.ProjectFile = projectFile
+	}()
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = cwd
+	}
+	var path string
+	if opts.ConfigFile != "" {
+		path = filepath.Join(dir, opts.ConfigFile)
+	} else if root, name, ok := config.FindProject(dir); ok {
+		path = filepath.Join(root, name)
+	}
+	if path == "" {
+		if err := os.Chdir(dir); err != nil {
+			return fmt.Errorf("cannot change to %s: %w", dir, err)
+		}
+		return fn(nil)
+	}
+
+	if err := config.UseProjectFile(path); err != nil {
+		return err
+	}
+	cfg, err := config.LoadConfig(config.ProjectFile)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	if locked {
+		projectLock, err := lock.AcquireProject(true)
+		if err != nil {
+			return err
+		}
+		defer projectLock.Release()
+	}
+	return fn(cfg)
+}
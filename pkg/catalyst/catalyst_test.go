@@ -0,0 +1,111 @@
+package catalyst
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	config "github.com/Sabique-Islam/catalyst/internal/config"
+	"github.com/Sabique-Islam/catalyst/internal/guard"
+	"github.com/Sabique-Islam/catalyst/internal/install"
+)
+
+func writeProject(t *testing.T) string {
+	dir := t.TempDir()
+	cfg := &Config{ProjectName: "hello", Sources: []string{"main.c"}, Dependencies: map[string][]string{}}
+	if err := SaveConfig(cfg, filepath.Join(dir, "catalyst.yml")); err != nil {
+		t.Fatal(err)
+	}
+	src := "#include <stdio.h>\n#include <zlib.h>\nint main(void) { puts(\"hello\"); return 0; }\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.c"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestScan(t *testing.T) {
+	dir := writeProject(t)
+	cwd, _ := os.Getwd()
+
+	deps, err := Scan(Options{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deps, []string{"zlib"}) {
+		t.Errorf("Scan() = %q, want [zlib]", deps)
+	}
+	if now, _ := os.Getwd(); now != cwd {
+		t.Errorf("Scan left the working directory at %s", now)
+	}
+
+	cfg, err := LoadConfig(filepath.Join(dir, "catalyst.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ProjectName != "hello" {
+		t.Errorf("LoadConfig() project = %q, want hello", cfg.ProjectName)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("gcc not installed")
+	}
+	dir := writeProject(t)
+	os.WriteFile(filepath.Join(dir, "main.c"), []byte("int main(void) { return 0; }\n"), 0644)
+
+	var messages strings.Builder
+	var linked []string
+	meta, err := Build(BuildOptions{Options: Options{
+		Dir:       dir,
+		OnMessage: func(level Level, msg string) { messages.WriteString(msg) },
+		OnEvent: func(e Event) {
+			if e.Kind == LinkFinished {
+				linked = append(linked, e.Output)
+			}
+		},
+	}})
+	if err != nil {
+		t.Fatalf("Build() failed: %v\n%s", err, messages.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, meta.Output)); err != nil {
+		t.Errorf("built binary missing: %v", err)
+	}
+	if len(linked) != 1 || linked[0] != meta.Output {
+		t.Errorf("LinkFinished events for %q, want %s", linked, meta.Output)
+	}
+	if !strings.Contains(messages.String(), "Build complete!") {
+		t.Errorf("OnMessage didn't receive the build output:\n%s", messages.String())
+	}
+}
+
+func TestRunClearsPrompts(t *testing.T) {
+	// As the CLI sets them up
+	config.ConfirmWrite = guard.ConfirmReplace
+	defer func() { config.ConfirmWrite = nil }()
+
+	err := run(Options{Dir: t.TempDir()}, false, func(*Config) error {
+		hooks := map[string]bool{
+			"install.ConfirmFix":     install.ConfirmFix != nil,
+			"install.ChooseCompiler": install.ChooseCompiler != nil,
+			"install.ConfirmRepo":    install.ConfirmRepo != nil,
+			"guard.Ask":              guard.Ask != nil,
+			"config.ConfirmWrite":    config.ConfirmWrite != nil,
+		}
+		for name, set := range hooks {
+			if set {
+				t.Errorf("%s is set while a library call runs", name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if install.ConfirmFix == nil || guard.Ask == nil || config.ConfirmWrite == nil {
+		t.Error("run didn't restore the prompts")
+	}
+}
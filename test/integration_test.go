@@ -0,0 +1,35 @@
+//go:build integration
+
+package test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Sabique-Islam/catalyst/internal/selftest"
+)
+
+// TestEndToEnd builds catalyst and runs the self-test fixtures against it.
+// Run with: go test -tags integration ./test/
+func TestEndToEnd(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "catalyst")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", binary, "..")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build catalyst: %v\n%s", err, out)
+	}
+
+	results, err := selftest.Run(selftest.Options{Binary: binary, WorkDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Self-test could not run: %v", err)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Name, r.Err)
+		}
+	}
+}